@@ -37,7 +37,7 @@ pkg/sql/schemachanger/scexec/exec_backfill_test.go://go:generate mockgen -packag
 pkg/sql/schemachanger/scop/backfill.go://go:generate go run ./generate_visitor.go scop Backfill backfill.go backfill_visitor_generated.go
 pkg/sql/schemachanger/scop/mutation.go://go:generate go run ./generate_visitor.go scop Mutation mutation.go mutation_visitor_generated.go
 pkg/sql/schemachanger/scop/validation.go://go:generate go run ./generate_visitor.go scop Validation validation.go validation_visitor_generated.go
-pkg/sql/schemachanger/scpb/state.go://go:generate go run element_generator.go --in elements.proto --out elements_generated.go
+pkg/sql/schemachanger/scpb/state.go://go:generate go run ../../../gen/protooneof --in elements.proto --out elements_generated.go --message ElementProto --package scpb --iface Element --marker element --iterator ElementStatusIterator --for-each ForEachElementStatus --iterator-args "current Status, target TargetStatus"
 pkg/sql/schemachanger/scpb/state.go://go:generate go run element_uml_generator.go --out uml/table.puml
 pkg/sql/sem/tree/eval.go://go:generate go run ./evalgen *.go
 pkg/util/interval/generic/doc.go:  //go:generate ../../util/interval/generic/gen.sh *latch spanlatch
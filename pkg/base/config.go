@@ -520,6 +520,9 @@ type StorageConfig struct {
 	// BallastSize is the amount reserved by a ballast file for manual
 	// out-of-disk recovery.
 	BallastSize int64
+	// SnapshotScratchDir is the directory in which the incoming snapshots are
+	// staged. If empty, they are staged in the auxiliary directory.
+	SnapshotScratchDir string
 	// Settings instance for cluster-wide knobs.
 	Settings *cluster.Settings
 	// UseFileRegistry is true if the file registry is needed (eg: encryption-at-rest).
@@ -271,6 +271,9 @@ type StoreSpec struct {
 	EncryptionOptions []byte
 	// ProvisionedRateSpec is optional.
 	ProvisionedRateSpec ProvisionedRateSpec
+	// SnapshotScratchDir is the optional directory in which the incoming
+	// snapshots are staged, instead of the auxiliary directory of the store.
+	SnapshotScratchDir string
 }
 
 // String returns a fully parsable version of the store spec.
@@ -323,6 +326,9 @@ func (ss StoreSpec) String() string {
 			fmt.Fprintf(&buffer, ",")
 		}
 	}
+	if len(ss.SnapshotScratchDir) > 0 {
+		fmt.Fprintf(&buffer, "snapshot-scratch-dir=%s,", ss.SnapshotScratchDir)
+	}
 	// Trim the extra comma from the end if it exists.
 	if l := buffer.Len(); l > 0 {
 		buffer.Truncate(l - 1)
@@ -369,6 +375,9 @@ var fractionRegex = regexp.MustCompile(`^([-]?([0-9]+\.[0-9]*|[0-9]*\.[0-9]+|[0-
 //   provisioned-rate can be used for admission control for operations on the
 //   store. The bandwidth is optional, and if unspecified, a cluster setting
 //   (kv.store.admission.provisioned_bandwidth) will be used.
+// - snapshot-scratch-dir=xxx The optional directory in which the incoming
+//   snapshots are staged before being ingested, for example on a different
+//   disk than the store. It defaults to a directory in the store.
 // Note that commas are forbidden within any field name or value.
 func NewStoreSpec(value string) (StoreSpec, error) {
 	const pathField = "path"
@@ -501,6 +510,12 @@ func NewStoreSpec(value string) (StoreSpec, error) {
 				return StoreSpec{}, err
 			}
 			ss.ProvisionedRateSpec = rateSpec
+		case "snapshot-scratch-dir":
+			var err error
+			ss.SnapshotScratchDir, err = GetAbsoluteStorePath(field, value)
+			if err != nil {
+				return StoreSpec{}, err
+			}
 
 		default:
 			return StoreSpec{}, fmt.Errorf("%s is not a valid store field", field)
@@ -517,6 +532,9 @@ func NewStoreSpec(value string) (StoreSpec, error) {
 		if ss.BallastSize != nil {
 			return StoreSpec{}, fmt.Errorf("ballast-size specified for in memory store")
 		}
+		if ss.SnapshotScratchDir != "" {
+			return StoreSpec{}, fmt.Errorf("snapshot-scratch-dir specified for in memory store")
+		}
 	} else if ss.Path == "" {
 		return StoreSpec{}, fmt.Errorf("no path specified")
 	}
@@ -158,6 +158,11 @@ target_file_size=2097152`
 			Path: "/mnt/hda1", ProvisionedRateSpec: base.ProvisionedRateSpec{
 				DiskName: "sdb", ProvisionedBandwidth: 0}}},
 
+		// snapshot scratch directory
+		{"path=/mnt/hda1,snapshot-scratch-dir=/mnt/hdb1/scratch", "", StoreSpec{
+			Path: "/mnt/hda1", SnapshotScratchDir: "/mnt/hdb1/scratch"}},
+		{"type=mem,size=20GiB,snapshot-scratch-dir=/mnt/hdb1", "snapshot-scratch-dir specified for in memory store", StoreSpec{}},
+
 		// RocksDB
 		{"path=/,rocksdb=key1=val1;key2=val2", "", StoreSpec{Path: "/", RocksDBOptions: "key1=val1;key2=val2"}},
 
@@ -277,7 +277,7 @@ func backup(
 		return roachpb.RowCount{}, errors.Wrapf(err, "exporting %d ranges", errors.Safe(numTotalSpans))
 	}
 
-	backupID := uuid.MakeV4()
+	backupID := uuid.MakeV7()
 	backupManifest.ID = backupID
 	// Write additional partial descriptors to each node for partitioned backups.
 	if len(storageByLocalityKV) > 0 {
@@ -490,7 +490,7 @@ func (b *backupResumer) Resume(ctx context.Context, execCtx interface{}) error {
 		// UUID so that it can be released on job completion. The updated details
 		// are persisted in the job record further down.
 		{
-			protectedtsID := uuid.MakeV4()
+			protectedtsID := uuid.MakeV7()
 			details.ProtectedTimestampRecord = &protectedtsID
 
 			if details.ProtectedTimestampRecord != nil {
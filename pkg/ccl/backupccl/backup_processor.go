@@ -11,6 +11,7 @@ package backupccl
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
@@ -79,8 +80,22 @@ var (
 		"split backup data on timestamps when writing revision history",
 		true,
 	)
+
+	stageFilesLocally = settings.RegisterBoolSetting(
+		settings.TenantWritable,
+		"bulkio.backup.stage_files_locally.enabled",
+		"build the files produced during BACKUP in the node's temporary storage directory, "+
+			"counting against its quota, before uploading them to the destination",
+		false,
+	)
 )
 
+// backupStagingDirName is the directory, below the node's temporary storage
+// directory, in which the files produced during BACKUP are staged when
+// stageFilesLocally is set. Like the rest of the temporary storage, it is
+// cleaned up when the node restarts.
+const backupStagingDirName = "backup-staging"
+
 const backupProcessorName = "backupDataProcessor"
 
 // TODO(pbardea): It would be nice if we could add some DistSQL processor tests
@@ -544,8 +559,17 @@ func runBackupProcessor(
 			progCh:   progCh,
 			settings: &flowCtx.Cfg.Settings.SV,
 		}
+		if stageFilesLocally.Get(&flowCtx.Cfg.Settings.SV) && flowCtx.Cfg.TempFS != nil {
+			diskAcc := flowCtx.DiskMonitor.MakeBoundAccount()
+			defer diskAcc.Close(ctx)
+			sinkConf.staging = &sstSinkStaging{
+				fs:  flowCtx.Cfg.TempFS,
+				dir: filepath.Join(flowCtx.Cfg.TempStoragePath, backupStagingDirName),
+				acc: &diskAcc,
+			}
+		}
 
-		storage, err := flowCtx.Cfg.ExternalStorage(ctx, dest)
+		storage, err := flowCtx.Cfg.ExternalStorage(ctx, dest, cloud.WithIOConsumer(cloud.BackupIOConsumer))
 		if err != nil {
 			return err
 		}
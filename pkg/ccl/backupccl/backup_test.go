@@ -180,6 +180,25 @@ func TestBackupRestoreSingleNodeLocal(t *testing.T) {
 	backupAndRestore(ctx, t, tc, []string{localFoo}, []string{localFoo}, numAccounts)
 }
 
+func TestBackupRestoreStagedLocally(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 1000
+	ctx := context.Background()
+	tc, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.backup.stage_files_locally.enabled = true`)
+	backupAndRestore(ctx, t, tc, []string{localFoo}, []string{localFoo}, numAccounts)
+
+	// The staged files are removed once uploaded.
+	cfg := tc.Server(0).ExecutorConfig().(sql.ExecutorConfig).DistSQLSrv.ServerConfig
+	staged, err := cfg.TempFS.List(filepath.Join(cfg.TempStoragePath, backupStagingDirName))
+	require.NoError(t, err)
+	require.Empty(t, staged)
+}
+
 func TestBackupRestoreMultiNodeLocal(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -12,6 +12,8 @@ import (
 	"context"
 	"fmt"
 	io "io"
+	"path"
+	"path/filepath"
 	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
@@ -25,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
 	hlc "github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -38,6 +41,58 @@ type sstSinkConf struct {
 	enc      *roachpb.FileEncryptionOptions
 	id       base.SQLInstanceID
 	settings *settings.Values
+	// staging, if set, is where the SSTs are built before being uploaded to
+	// the destination. See stageFilesLocally.
+	staging *sstSinkStaging
+}
+
+// sstSinkStaging describes the local directory in which a fileSSTSink stages
+// the SSTs it builds.
+type sstSinkStaging struct {
+	fs  fs.FS
+	dir string
+	// acc accounts for the size of the staged SSTs, which counts against the
+	// temporary storage quota of the node.
+	acc *mon.BoundAccount
+}
+
+// stagedSST is an SST being built in, or uploaded from, the staging
+// directory of a fileSSTSink.
+type stagedSST struct {
+	ctx     context.Context
+	staging *sstSinkStaging
+	path    string
+	f       fs.File
+	size    int64
+}
+
+var _ io.WriteCloser = &stagedSST{}
+
+// Write implements the io.Writer interface.
+func (f *stagedSST) Write(p []byte) (int, error) {
+	if err := f.staging.acc.Grow(f.ctx, int64(len(p))); err != nil {
+		return 0, err
+	}
+	f.size += int64(len(p))
+	return f.f.Write(p)
+}
+
+// Close implements the io.Closer interface.
+func (f *stagedSST) Close() error {
+	if err := f.f.Sync(); err != nil {
+		_ = f.f.Close()
+		return err
+	}
+	return f.f.Close()
+}
+
+// remove removes the staged SST and releases its quota.
+func (f *stagedSST) remove() {
+	if err := f.staging.fs.Remove(f.path); err != nil {
+		log.Warningf(f.ctx, "failed to remove staged backup file %s: %v", f.path, err)
+	}
+	f.staging.acc.Shrink(f.ctx, f.size)
+	f.size = 0
 }
 
 type fileSSTSink struct {
@@ -55,6 +110,9 @@ type fileSSTSink struct {
 	cancel  func()
 	out     io.WriteCloser
 	outName string
+	// staged is set when out is an SST staged locally, which is uploaded to
+	// outName once complete.
+	staged *stagedSST
 
 	flushedFiles    []backuppb.BackupManifest_File
 	flushedSize     int64
@@ -122,10 +180,15 @@ func (s *fileSSTSink) Close() error {
 	// Release the memory reserved for the file buffer.
 	s.memAcc.ba.Shrink(s.ctx, s.memAcc.reservedBytes)
 	s.memAcc.reservedBytes = 0
+	var err error
 	if s.out != nil {
-		return s.out.Close()
+		err = s.out.Close()
 	}
-	return nil
+	if s.staged != nil {
+		s.staged.remove()
+		s.staged = nil
+	}
+	return err
 }
 
 func (s *fileSSTSink) sortQueue() {
@@ -189,6 +252,11 @@ func (s *fileSSTSink) flushFile(ctx context.Context) error {
 		log.Warningf(ctx, "failed to close write in fileSSTSink: % #v", pretty.Formatter(err))
 		return errors.Wrap(err, "writing SST")
 	}
+	if s.staged != nil {
+		if err := s.uploadStaged(); err != nil {
+			return errors.Wrap(err, "uploading staged SST")
+		}
+	}
 	s.outName = ""
 	s.out = nil
 
@@ -222,23 +290,82 @@ func (s *fileSSTSink) open(ctx context.Context) error {
 	if s.ctx == nil {
 		s.ctx, s.cancel = context.WithCancel(ctx)
 	}
+	if s.conf.staging != nil {
+		if err := s.openStaged(); err != nil {
+			return err
+		}
+	} else {
+		w, err := s.openDest()
+		if err != nil {
+			return err
+		}
+		s.out = w
+	}
+	s.sst = storage.MakeBackupSSTWriter(ctx, s.dest.Settings(), s.out)
+
+	return nil
+}
+
+// openDest opens the writer of the file outName in the destination.
+func (s *fileSSTSink) openDest() (io.WriteCloser, error) {
 	w, err := s.dest.Writer(s.ctx, s.outName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if s.conf.enc != nil {
 		var err error
 		w, err = storageccl.EncryptingWriter(w, s.conf.enc.Key)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
-	s.out = w
-	s.sst = storage.MakeBackupSSTWriter(ctx, s.dest.Settings(), s.out)
+	return w, nil
+}
 
+// openStaged creates the local file in which the SST destined to outName is
+// staged.
+func (s *fileSSTSink) openStaged() error {
+	staging := s.conf.staging
+	if err := staging.fs.MkdirAll(staging.dir); err != nil {
+		return err
+	}
+	p := filepath.Join(staging.dir, path.Base(s.outName))
+	f, err := staging.fs.Create(p)
+	if err != nil {
+		return err
+	}
+	s.staged = &stagedSST{ctx: s.ctx, staging: staging, path: p, f: f}
+	s.out = s.staged
 	return nil
 }
 
+// uploadStaged uploads the complete staged SST to outName in the destination,
+// then removes it.
+func (s *fileSSTSink) uploadStaged() error {
+	staged := s.staged
+	defer func() {
+		staged.remove()
+		s.staged = nil
+	}()
+	// NB: s.out is closed by Close, aborting the upload, if the copy fails.
+	s.out = nil
+	f, err := staged.staging.fs.Open(staged.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w, err := s.openDest()
+	if err != nil {
+		return err
+	}
+	s.out = w
+	if _, err := io.Copy(w, f); err != nil {
+		return err
+	}
+	s.out = nil
+	return w.Close()
+}
+
 func (s *fileSSTSink) write(ctx context.Context, resp exportedSpan) error {
 	s.stats.files++
 
@@ -122,6 +122,20 @@ func rewriteBackupSpanKey(
 	return newKey, nil
 }
 
+// The progress of a restore job is reported in stages. Most of the work
+// happens when restoring the data.
+const (
+	restoreProgressStageData       = "restoring data"
+	restoreProgressStageValidation = "validating indexes"
+	restoreProgressStagePublish    = "publishing descriptors"
+)
+
+var restoreProgressStages = []jobs.ProgressStage{
+	{Name: restoreProgressStageData, Weight: 0.9},
+	{Name: restoreProgressStageValidation, Weight: 0.05},
+	{Name: restoreProgressStagePublish, Weight: 0.05},
+}
+
 func restoreWithRetry(
 	restoreCtx context.Context,
 	execCtx sql.JobExecContext,
@@ -131,6 +145,7 @@ func restoreWithRetry(
 	endTime hlc.Timestamp,
 	dataToRestore restorationData,
 	job *jobs.Job,
+	progress *jobs.StagedProgress,
 	encryption *jobspb.BackupEncryptionOptions,
 	kmsEnv cloud.KMSEnv,
 ) (roachpb.RowCount, error) {
@@ -159,6 +174,7 @@ func restoreWithRetry(
 				endTime,
 				dataToRestore,
 				job,
+				progress,
 				encryption,
 				kmsEnv,
 			)
@@ -172,7 +188,7 @@ func restoreWithRetry(
 		}
 
 		if errors.HasType(err, &roachpb.InsufficientSpaceError{}) {
-			return roachpb.RowCount{}, jobs.MarkPauseRequestError(errors.UnwrapAll(err))
+			return roachpb.RowCount{}, jobs.MarkDiskPressurePauseError(restoreCtx, job.ID(), errors.UnwrapAll(err))
 		}
 
 		if joberror.IsPermanentBulkJobError(err) {
@@ -230,6 +246,7 @@ func restore(
 	endTime hlc.Timestamp,
 	dataToRestore restorationData,
 	job *jobs.Job,
+	progress *jobs.StagedProgress,
 	encryption *jobspb.BackupEncryptionOptions,
 	kmsEnv cloud.KMSEnv,
 ) (roachpb.RowCount, error) {
@@ -320,7 +337,7 @@ func restore(
 		// cluster restores) may be restored first. When restoring that data, we
 		// don't want to update the high-water mark key, so instead progress is just
 		// defined on the main data bundle (of which there should only be one).
-		progressLogger := jobs.NewChunkProgressLogger(job, len(importSpans), job.FractionCompleted(),
+		progressLogger := progress.NewChunkProgressLogger(restoreProgressStageData, len(importSpans),
 			func(progressedCtx context.Context, details jobspb.ProgressDetails) {
 				switch d := details.(type) {
 				case *jobspb.Progress_Restore:
@@ -346,10 +363,10 @@ func restore(
 		defer close(requestFinishedCh)
 		// When a processor is done importing a span, it will send a progress update
 		// to progCh.
-		for progress := range progCh {
+		for prog := range progCh {
 			mu.Lock()
 			var progDetails backuppb.RestoreProgress
-			if err := pbtypes.UnmarshalAny(&progress.ProgressDetails, &progDetails); err != nil {
+			if err := pbtypes.UnmarshalAny(&prog.ProgressDetails, &progDetails); err != nil {
 				log.Errorf(ctx, "unable to unmarshal restore progress details: %+v", err)
 			}
 
@@ -1510,6 +1527,7 @@ func (r *restoreResumer) doResume(ctx context.Context, execCtx interface{}) erro
 		numNodes = 1
 	}
 
+	progress := jobs.NewStagedProgress(r.job, restoreProgressStages...)
 	var resTotal roachpb.RowCount
 	if !preData.isEmpty() {
 		res, err := restoreWithRetry(
@@ -1521,6 +1539,7 @@ func (r *restoreResumer) doResume(ctx context.Context, execCtx interface{}) erro
 			details.EndTime,
 			preData,
 			r.job,
+			progress,
 			details.Encryption,
 			&kmsEnv,
 		)
@@ -1555,6 +1574,7 @@ func (r *restoreResumer) doResume(ctx context.Context, execCtx interface{}) erro
 			details.EndTime,
 			preValidateData,
 			r.job,
+			progress,
 			details.Encryption,
 			&kmsEnv,
 		)
@@ -1576,6 +1596,7 @@ func (r *restoreResumer) doResume(ctx context.Context, execCtx interface{}) erro
 			details.EndTime,
 			mainData,
 			r.job,
+			progress,
 			details.Encryption,
 			&kmsEnv,
 		)
@@ -1592,6 +1613,11 @@ func (r *restoreResumer) doResume(ctx context.Context, execCtx interface{}) erro
 
 	var devalidateIndexes map[descpb.ID][]descpb.IndexID
 	if toValidate := len(details.RevalidateIndexes); toValidate > 0 {
+		if err := progress.SetStageFraction(
+			ctx, restoreProgressStageValidation, 0, jobs.ProgressUpdateOnly,
+		); err != nil {
+			return errors.Wrapf(err, "failed to update progress of job %d", errors.Safe(r.job.ID()))
+		}
 		if err := r.job.RunningStatus(ctx, nil /* txn */, func(_ context.Context, _ jobspb.Details) (jobs.RunningStatus, error) {
 			return jobs.RunningStatus(fmt.Sprintf("re-validating %d indexes", toValidate)), nil
 		}); err != nil {
@@ -1604,6 +1630,12 @@ func (r *restoreResumer) doResume(ctx context.Context, execCtx interface{}) erro
 		devalidateIndexes = bad
 	}
 
+	if err := progress.SetStageFraction(
+		ctx, restoreProgressStagePublish, 0, jobs.ProgressUpdateOnly,
+	); err != nil {
+		return errors.Wrapf(err, "failed to update progress of job %d", errors.Safe(r.job.ID()))
+	}
+
 	publishDescriptors := func(ctx context.Context, txn *kv.Txn, descsCol *descs.Collection) (err error) {
 		err = r.publishDescriptors(ctx, txn, p.ExecCfg(), p.User(), descsCol, details, devalidateIndexes)
 		return err
@@ -307,7 +307,7 @@ func protectTimestampRecordForSchedule(
 	exec *sql.ExecutorConfig,
 	txn *kv.Txn,
 ) (uuid.UUID, error) {
-	protectedtsID := uuid.MakeV4()
+	protectedtsID := uuid.MakeV7()
 	rec := jobsprotectedts.MakeRecord(protectedtsID, scheduleID, tsToProtect, deprecatedSpansToProtect,
 		jobsprotectedts.Schedules, targetToProtect)
 	return protectedtsID, exec.ProtectedTimestampProvider.Protect(ctx, txn, rec)
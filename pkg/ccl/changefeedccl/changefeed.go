@@ -117,7 +117,7 @@ func createProtectedTimestampRecord(
 	resolved hlc.Timestamp,
 	progress *jobspb.ChangefeedProgress,
 ) *ptpb.Record {
-	progress.ProtectedTimestampRecord = uuid.MakeV4()
+	progress.ProtectedTimestampRecord = uuid.MakeV7()
 	deprecatedSpansToProtect := makeSpansToProtect(codec, targets)
 	targetToProtect := makeTargetToProtect(targets)
 
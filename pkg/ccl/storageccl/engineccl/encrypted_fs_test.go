@@ -435,3 +435,68 @@ func TestCanRegistryElide(t *testing.T) {
 	entry.EncryptionSettings = b
 	require.False(t, canRegistryElide(entry))
 }
+
+// TestPebbleCreateEncrypted checks that the files created with CreateEncrypted
+// follow the encryption-at-rest configuration of the store.
+func TestPebbleCreateEncrypted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	memFS := vfs.NewMem()
+	writeToFile(t, memFS, "16.key", []byte("111111111111111111111111111111111234567890123456"))
+
+	for _, tc := range []struct {
+		currentKey, oldKey string
+		encrypted          bool
+	}{
+		{currentKey: "16.key", oldKey: "plain", encrypted: true},
+		{currentKey: "plain", oldKey: "16.key", encrypted: false},
+	} {
+		t.Run(tc.currentKey, func(t *testing.T) {
+			encOptionsBytes, err := protoutil.Marshal(&baseccl.EncryptionOptions{
+				KeySource: baseccl.EncryptionKeySource_KeyFiles,
+				KeyFiles: &baseccl.EncryptionKeyFiles{
+					CurrentKey: tc.currentKey,
+					OldKey:     tc.oldKey,
+				},
+				DataKeyRotationPeriod: 1000,
+			})
+			require.NoError(t, err)
+
+			opts := storage.DefaultPebbleOptions()
+			opts.FS = memFS
+			opts.Cache = pebble.NewCache(1 << 20)
+			defer opts.Cache.Unref()
+			db, err := storage.NewPebble(
+				context.Background(),
+				storage.PebbleConfig{
+					StorageConfig: base.StorageConfig{
+						Attrs:             roachpb.Attributes{},
+						MaxSize:           512 << 20,
+						Settings:          cluster.MakeTestingClusterSettings(),
+						UseFileRegistry:   true,
+						EncryptionOptions: encOptionsBytes,
+					},
+					Opts: opts,
+				})
+			require.NoError(t, err)
+			defer db.Close()
+
+			f, err := db.CreateEncrypted("scratch.sst", 0 /* bytesPerSync */)
+			require.NoError(t, err)
+			_, err = f.Write([]byte("data"))
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			r, err := db.GetEncryptionRegistries()
+			require.NoError(t, err)
+			var fileRegistry enginepb.FileRegistry
+			require.NoError(t, protoutil.Unmarshal(r.FileRegistry, &fileRegistry))
+			_, ok := fileRegistry.Files["scratch.sst"]
+			require.Equal(t, tc.encrypted, ok)
+
+			contents, err := db.ReadFile("scratch.sst")
+			require.NoError(t, err)
+			require.Equal(t, "data", string(contents))
+		})
+	}
+}
@@ -949,6 +949,16 @@ For example:
   --store=provisioned-rate=disk-name=nvme1n1
   --store=provisioned-rate=disk-name=sdb:bandwidth=250MiB/s
 
+</PRE>
+Optionally, the incoming snapshots can be staged in a different directory
+than the store, for example on a separate disk, with the
+"snapshot-scratch-dir" field. The directory must not be shared with other
+stores or used for other purposes, as its contents are removed when the
+node starts. For example:
+<PRE>
+
+  --store=path=/mnt/ssd01,snapshot-scratch-dir=/mnt/ssd02/snapshots
+
 </PRE>
 Commas are forbidden in all values, since they are used to separate fields.
 Also, if you use equal signs in the file path to a store, you must use the
@@ -1098,6 +1108,14 @@ is the prefix for range local keys. The hex format takes an encoded MVCCKey.`}
 		Description: "Restrict scan to replicated data.",
 	}
 
+	SnapshotScratchDir = FlagInfo{
+		Name: "snapshot-scratch-dir",
+		Description: `
+The directory in which the store stages the incoming snapshots, if it was
+started with the "snapshot-scratch-dir" field of --store. Defaults to the
+auxiliary directory of the store.`,
+	}
+
 	GossipInputFile = FlagInfo{
 		Name:      "file",
 		Shorthand: "f",
@@ -1528,6 +1546,25 @@ Can be set to 1 to ensure only one node is polled for data at a time.
 `,
 	}
 
+	ZipLogNodeBudget = FlagInfo{
+		Name: "log-node-budget",
+		Description: `
+Maximum number of bytes of log entries to retrieve from each node.
+The budget is assigned to the most recent log files first; older files
+are skipped once it is exhausted. Zero means unlimited.
+`,
+	}
+
+	ZipLogFileBudget = FlagInfo{
+		Name: "log-file-budget",
+		Description: `
+Maximum number of bytes of log entries to retrieve from each log file.
+Files exceeding the budget are trimmed, retaining the entries surrounding
+panics and fatal errors, then the most recent entries. The omitted entries
+are replaced by a note in the output. Zero means unlimited.
+`,
+	}
+
 	StmtDiagDeleteAll = FlagInfo{
 		Name:        "all",
 		Description: `Delete all bundles.`,
@@ -1585,6 +1622,18 @@ This can be used to check schema and data correctness without running the entire
 For example, you can change the default logging directory with:
 --log='file-defaults: {dir: ...}'.
 See the documentation for more options and details.
+<PRE>
+
+</PRE>
+The flag can be specified multiple times, and combined with
+--log-config-file. The configuration is then built by merging,
+in this order: the default configuration, the content of the
+configuration file, and each --log snippet in the order they
+appear on the command line. Each input only overrides the fields
+it specifies; for example --log='sinks: {file-groups: {default:
+{format: json, buffering: {max-buffer-size: 10MiB}}}}' changes
+the format and buffer size of a file group defined in the
+configuration file, leaving its other fields intact.
 
 To preview how the log configuration is applied, or preview the
 default configuration, you can use the 'cockroach debug check-log-config' sub-command.
@@ -1594,8 +1643,8 @@ default configuration, you can use the 'cockroach debug check-log-config' sub-co
 	LogConfigFile = FlagInfo{
 		Name: "log-config-file",
 		Description: `File name to read the logging configuration from.
-This has the same effect as passing the content of the file via
-the --log flag.`,
+Fields specified via the --log flag take precedence over the
+content of the file.`,
 	}
 
 	LogConfigVars = FlagInfo{
@@ -176,8 +176,13 @@ type cliContext struct {
 	// TODO(knz): Relax this when SCRAM is implemented.
 	allowUnencryptedClientPassword bool
 
-	// logConfigInput is the YAML input for the logging configuration.
+	// logConfigInput is the YAML input for the logging configuration,
+	// read from --log-config-file.
 	logConfigInput settableString
+	// logConfigOverrides are the YAML snippets passed via --log, in the
+	// order they were specified on the command line. They are merged on
+	// top of logConfigInput.
+	logConfigOverrides []string
 	// logConfigVars is an array of environment variables used in the logging
 	// configuration that will be expanded by CRDB.
 	logConfigVars []string
@@ -227,6 +232,7 @@ func setCliContextDefaults() {
 	cliCtx.clientOpts.Database = ""
 	cliCtx.allowUnencryptedClientPassword = false
 	cliCtx.logConfigInput = settableString{s: ""}
+	cliCtx.logConfigOverrides = nil
 	cliCtx.logConfigVars = nil
 	cliCtx.logConfig = logconfig.Config{}
 	cliCtx.logShutdownFn = func() {}
@@ -351,6 +357,12 @@ type zipContext struct {
 
 	// The log/heap/etc files to include.
 	files fileSelection
+
+	// logNodeBudget and logFileBudget bound the number of bytes of log
+	// entries retrieved per node and per log file, respectively. Zero
+	// means unlimited.
+	logNodeBudget int64
+	logFileBudget int64
 }
 
 // setZipContextDefaults set the default values in zipCtx.  This
@@ -362,6 +374,8 @@ func setZipContextDefaults() {
 	zipCtx.redactLogs = false
 	zipCtx.cpuProfDuration = 5 * time.Second
 	zipCtx.concurrency = 15
+	zipCtx.logNodeBudget = 0
+	zipCtx.logFileBudget = 0
 
 	// File selection covers the last 48 hours by default.
 	// We add 24 hours to now for the end timestamp to ensure
@@ -412,17 +426,18 @@ func setAuthContextDefaults() {
 // debugCtx captures the command-line parameters of the `debug` command.
 // See below for defaults.
 var debugCtx struct {
-	startKey, endKey  storage.MVCCKey
-	values            bool
-	sizes             bool
-	replicated        bool
-	inputFile         string
-	ballastSize       base.SizeSpec
-	printSystemConfig bool
-	maxResults        int
-	decodeAsTableDesc string
-	verbose           bool
-	keyTypes          keyTypeFilter
+	startKey, endKey   storage.MVCCKey
+	values             bool
+	sizes              bool
+	replicated         bool
+	inputFile          string
+	ballastSize        base.SizeSpec
+	printSystemConfig  bool
+	maxResults         int
+	decodeAsTableDesc  string
+	verbose            bool
+	keyTypes           keyTypeFilter
+	snapshotScratchDir string
 }
 
 // setDebugContextDefaults set the default values in debugCtx.  This
@@ -441,6 +456,7 @@ func setDebugContextDefaults() {
 	debugCtx.decodeAsTableDesc = ""
 	debugCtx.verbose = false
 	debugCtx.keyTypes = showAll
+	debugCtx.snapshotScratchDir = ""
 }
 
 // startCtx captures the command-line arguments for the `start` command.
@@ -53,6 +53,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/flagutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -504,6 +505,56 @@ func runDebugRangeData(cmd *cobra.Command, args []string) error {
 		})
 }
 
+var debugRangeDataExportCmd = &cobra.Command{
+	Use:   "range-data-export <directory> <range id> <output directory>",
+	Short: "export the replicated data of a range to SSTs",
+	Long: `
+Writes the replicated data of a range, as found in a stopped store, to
+standalone SSTs in the given output directory. The SSTs are laid out like the
+ones staged for an incoming snapshot: one SST per replicated key span, each
+starting with a range deletion covering the span. They can be inspected
+offline or used for manual replica surgery.
+`,
+	Args: cobra.ExactArgs(3),
+	RunE: clierrorplus.MaybeDecorateError(runDebugRangeDataExport),
+}
+
+func runDebugRangeDataExport(cmd *cobra.Command, args []string) error {
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	db, err := OpenEngine(args[0], stopper, storage.ReadOnly, storage.MustExist)
+	if err != nil {
+		return err
+	}
+
+	rangeID, err := parseRangeID(args[1])
+	if err != nil {
+		return err
+	}
+
+	desc, err := loadRangeDescriptor(db, rangeID)
+	if err != nil {
+		return err
+	}
+
+	outDir, err := filepath.Abs(args[2])
+	if err != nil {
+		return err
+	}
+	ssts, dataSize, err := kvserver.ExportReplicaData(
+		context.Background(), serverCfg.Settings, db, &desc, fs.WrapVFS(vfs.Default), outDir)
+	if err != nil {
+		return err
+	}
+	for _, sst := range ssts {
+		fmt.Println(sst)
+	}
+	fmt.Printf("exported %s of data for r%d to %d SSTs\n",
+		humanizeutil.IBytes(dataSize), rangeID, len(ssts))
+	return nil
+}
+
 var debugRangeDescriptorsCmd = &cobra.Command{
 	Use:   "range-descriptors <directory>",
 	Short: "print all range descriptors in a store",
@@ -675,6 +726,7 @@ var debugDecodeProtoEmitDefaults bool
 var debugDecodeProtoSingleProto bool
 var debugDecodeProtoBinaryOutput bool
 var debugDecodeProtoOutputFile string
+var debugDecodeProtoSchemaChangerSummary bool
 var debugDecodeProtoCmd = &cobra.Command{
 	Use:   "decode-proto",
 	Short: "decode-proto <proto> --name=<fully qualified proto name>",
@@ -704,6 +756,14 @@ $ curl -X POST  'http://localhost:8080/ts/query' \
   -H 'Accept: application/json' \
   -H 'Content-Type: application/x-protobuf' \
   --data-binary @<file>
+
+The state of declarative schema changes can be decoded using --schema with
+'descriptor_state' or 'target_state', or as part of descriptors and job
+payloads. With --schema-changer-summary, such values are printed as a
+human-readable list of statements and targets, with each element rendered
+using its name and attributes instead of JSON. For example:
+
+$ cockroach debug decode-proto --schema=payload --schema-changer-summary
 `,
 	Args: cobra.ArbitraryArgs,
 	RunE: runDebugDecodeProto,
@@ -714,6 +774,22 @@ var debugRaftLogCmd = &cobra.Command{
 	Short: "print the raft log for a range",
 	Long: `
 Prints all log entries in a store for the given range.
+
+The entries can be restricted to a given type with --type: 'normal'
+entries, raft configuration changes ('confchange'), or entries that ingest
+an SST ('addsstable').
+
+With --summary, each entry is described by a short summary of the side
+effects of its command (SST ingestions and whether their payload is
+sideloaded, configuration changes, splits, merges and log truncations)
+instead of a full dump. The summary is preceded by the raft hard state,
+the truncated state and the metadata (applied index and term) of the
+snapshots the replica would send.
+
+With --check-sideloaded, the sideloaded entries are cross-referenced with
+the files present in the range's sideloaded directory, reporting missing
+and unreferenced files, and the snapshots staged for the range in the
+store's scratch directory are listed.
 `,
 	Args: cobra.ExactArgs(2),
 	RunE: clierrorplus.MaybeDecorateError(runDebugRaftLog),
@@ -740,12 +816,7 @@ func runDebugRaftLog(cmd *cobra.Command, args []string) error {
 		string(storage.EncodeMVCCKey(storage.MakeMVCCMetadataKey(start))),
 		string(storage.EncodeMVCCKey(storage.MakeMVCCMetadataKey(end))))
 
-	// NB: raft log does not have intents.
-	return db.MVCCIterate(start, end, storage.MVCCKeyIterKind, storage.IterKeyTypePointsOnly,
-		func(kv storage.MVCCKeyValue, _ storage.MVCCRangeKeyStack) error {
-			kvserver.PrintMVCCKeyValue(kv)
-			return nil
-		})
+	return printRaftLog(context.Background(), os.Stdout, db, rangeID)
 }
 
 var debugGCCmd = &cobra.Command{
@@ -885,6 +956,8 @@ Output environment variables that influence configuration.
 
 var debugCompactOpts = struct {
 	maxConcurrency int
+	rate           int64
+	resumable      bool
 }{maxConcurrency: runtime.GOMAXPROCS(0)}
 
 var debugCompactCmd = &cobra.Command{
@@ -892,6 +965,17 @@ var debugCompactCmd = &cobra.Command{
 	Short: "compact the sstables in a store",
 	Long: `
 Compact the sstables in a store.
+
+The progress of the compaction (bytes compacted, and per-level statistics of
+the LSM) is reported every minute.
+
+With --rate, or --resumable, the store is compacted one range at a time.
+--rate paces the compactions so that the average rate at which they write
+does not exceed the given number of bytes per second. --resumable records
+the progress of the compaction in the store's auxiliary directory, so that
+an interrupted compaction resumes where it left off when the command is run
+again with --resumable. When compacting one range at a time, the progress
+report also includes an estimate of the time remaining.
 `,
 	Args: cobra.ExactArgs(1),
 	RunE: clierrorplus.MaybeDecorateError(runDebugCompact),
@@ -923,27 +1007,35 @@ func runDebugCompact(cmd *cobra.Command, args []string) error {
 		fmt.Printf("approximate reported database size before compaction: %s\n", humanizeutil.IBytes(int64(approxBytesBefore)))
 	}
 
-	// Begin compacting the store in a separate goroutine.
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- errors.Wrap(db.Compact(), "while compacting")
-	}()
-
-	// Print the current LSM every minute.
-	ticker := time.NewTicker(time.Minute)
-	for done := false; !done; {
-		select {
-		case <-ticker.C:
-			fmt.Printf("%s\n", db.GetMetrics())
-		case err := <-errCh:
-			ticker.Stop()
-			if err != nil {
-				return err
+	if debugCompactOpts.rate > 0 || debugCompactOpts.resumable {
+		if err := compactInChunks(context.Background(), os.Stdout, db,
+			debugCompactOpts.rate, debugCompactOpts.resumable); err != nil {
+			return err
+		}
+	} else {
+		// Begin compacting the store in a separate goroutine.
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- errors.Wrap(db.Compact(), "while compacting")
+		}()
+
+		// Report the progress every minute.
+		p := newCompactProgress(os.Stdout, db)
+		ticker := time.NewTicker(debugCompactProgressInterval)
+		for done := false; !done; {
+			select {
+			case <-ticker.C:
+				p.report(db.GetMetrics())
+			case err := <-errCh:
+				ticker.Stop()
+				if err != nil {
+					return err
+				}
+				done = true
 			}
-			done = true
 		}
+		p.report(db.GetMetrics())
 	}
-	fmt.Printf("%s\n", db.GetMetrics())
 
 	{
 		approxBytesAfter, err := db.ApproximateDiskBytes(roachpb.KeyMin, roachpb.KeyMax)
@@ -1472,21 +1564,21 @@ var debugMergeLogsOpts = struct {
 	file           *regexp.Regexp
 	keepRedactable bool
 	prefix         string
-	redactInput    bool
+	redactInput    redactLogsMode
 	format         string
 	useColor       forceColor
 }{
 	program:        nil, // match everything
 	file:           regexp.MustCompile(logFilePattern),
 	keepRedactable: true,
-	redactInput:    false,
+	redactInput:    redactLogsOff,
 }
 
 func runDebugMergeLogs(cmd *cobra.Command, args []string) error {
 	o := debugMergeLogsOpts
 	p := newFilePrefixer(withTemplate(o.prefix))
 
-	inputEditMode := log.SelectEditMode(o.redactInput, o.keepRedactable)
+	inputEditMode := o.redactInput.editMode(o.keepRedactable)
 
 	s, err := newMergedStreamFromPatterns(context.Background(),
 		args, o.file, o.program, o.from, o.to, inputEditMode, o.format, p)
@@ -1634,10 +1726,13 @@ var DebugCommandsRequiringEncryption = []*cobra.Command{
 	debugKeysCmd,
 	debugRaftLogCmd,
 	debugRangeDataCmd,
+	debugRangeDataExportCmd,
 	debugRangeDescriptorsCmd,
 	debugUnsafeRemoveDeadReplicasCmd,
 	debugRecoverCollectInfoCmd,
 	debugRecoverExecuteCmd,
+	debugSnapshotScratchListCmd,
+	debugSnapshotScratchCleanCmd,
 }
 
 // Debug commands. All commands in this list to be added to root debug command.
@@ -1649,6 +1744,7 @@ var debugCmds = []*cobra.Command{
 	debugKeysCmd,
 	debugRaftLogCmd,
 	debugRangeDataCmd,
+	debugRangeDataExportCmd,
 	debugRangeDescriptorsCmd,
 	debugUnsafeRemoveDeadReplicasCmd,
 	debugBallastCmd,
@@ -1667,6 +1763,7 @@ var debugCmds = []*cobra.Command{
 	debugResetQuorumCmd,
 	debugSendKVBatchCmd,
 	debugRecoverCmd,
+	debugSnapshotScratchCmd,
 }
 
 // DebugCmd is the root of all debug commands. Exported to allow modification by CCL code.
@@ -1763,9 +1860,21 @@ func init() {
 	f.BoolVarP(&syncBenchOpts.LogOnly, "log-only", "l", syncBenchOpts.LogOnly,
 		"only write to the WAL, not to sstables")
 
+	f = debugRaftLogCmd.Flags()
+	f.Var(&debugRaftLogOpts.entryType, "type",
+		"only print the entries of the given type (all, normal, confchange, addsstable)")
+	f.BoolVar(&debugRaftLogOpts.summary, "summary", false,
+		"print a summary of each entry instead of its full contents")
+	f.BoolVar(&debugRaftLogOpts.checkSideloaded, "check-sideloaded", false,
+		"cross-reference the sideloaded entries with the files in the sideloaded and snapshot scratch directories")
+
 	f = debugCompactCmd.Flags()
 	f.IntVarP(&debugCompactOpts.maxConcurrency, "max-concurrency", "c", debugCompactOpts.maxConcurrency,
 		"maximum number of concurrent compactions")
+	f.Var(humanizeutil.NewBytesValue(&debugCompactOpts.rate), "rate",
+		"maximum average rate, in bytes per second, at which compactions write (0 for no limit)")
+	f.BoolVar(&debugCompactOpts.resumable, "resumable", debugCompactOpts.resumable,
+		"record the progress of the compaction so that it can be resumed if interrupted")
 
 	f = debugUnsafeRemoveDeadReplicasCmd.Flags()
 	f.IntSliceVar(&removeDeadReplicasOpts.deadStoreIDs, "dead-store-ids", nil,
@@ -1807,8 +1916,12 @@ func init() {
 		"expansion template (see regexp.Expand) used as prefix to merged log messages evaluated on file-pattern")
 	f.BoolVar(&debugMergeLogsOpts.keepRedactable, "redactable-output", debugMergeLogsOpts.keepRedactable,
 		"keep the output log file redactable")
-	f.BoolVar(&debugMergeLogsOpts.redactInput, "redact", debugMergeLogsOpts.redactInput,
-		"redact the input files to remove sensitive information")
+	f.Var(&debugMergeLogsOpts.redactInput, "redact",
+		"redact the input files to remove sensitive information; "+
+			"with --redact=hash, sensitive information is replaced by a deterministic hash "+
+			"of its value so that repeated values can still be correlated")
+	// Preserve the boolean flag behavior: --redact is equivalent to --redact=true.
+	f.Lookup("redact").NoOptDefVal = "true"
 	f.StringVar(&debugMergeLogsOpts.format, "format", "",
 		"log format of the input files")
 	f.Var(&debugMergeLogsOpts.useColor, "color",
@@ -1828,6 +1941,9 @@ func init() {
 		"output the protos as binary instead of JSON. If specified, --out also needs to be specified.")
 	f.StringVar(&debugDecodeProtoOutputFile, "out", "",
 		"path to output file. If not specified, output goes to stdout.")
+	f.BoolVar(&debugDecodeProtoSchemaChangerSummary, "schema-changer-summary", false,
+		"print declarative schema changer state (in descriptors, job payloads, or scpb "+
+			"protos) as a human-readable summary of its statements and targets instead of JSON")
 
 	f = debugCheckLogConfigCmd.Flags()
 	f.Var(&debugLogChanSel, "only-channels", "selection of channels to include in the output diagram.")
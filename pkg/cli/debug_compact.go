@@ -0,0 +1,250 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+)
+
+// debugCompactCheckpointFile is the name of the file, in the store's
+// auxiliary directory, recording how far a resumable `debug compact` got.
+// It contains the key at which the compaction should resume.
+const debugCompactCheckpointFile = "debug-compact-checkpoint"
+
+// debugCompactProgressInterval is the interval at which `debug compact`
+// reports its progress.
+const debugCompactProgressInterval = time.Minute
+
+// compactProgress tracks and reports the progress of `debug compact`.
+type compactProgress struct {
+	out   io.Writer
+	start time.Time
+	// compactedAtStart is the number of bytes compacted by the engine
+	// before `debug compact` started, e.g. by the compactions triggered
+	// when the store was opened.
+	compactedAtStart uint64
+	// totalBytes and doneBytes track the estimated size of the data to
+	// compact, and how much of it has been processed. They are only
+	// maintained when compacting in chunks.
+	totalBytes, doneBytes uint64
+	// skippedBytes is the part of doneBytes that was already processed by a
+	// previous, interrupted, run of the command. It is not taken into account
+	// when estimating the remaining time.
+	skippedBytes uint64
+	lastReport   time.Time
+}
+
+func newCompactProgress(out io.Writer, db storage.Engine) *compactProgress {
+	now := timeutil.Now()
+	return &compactProgress{
+		out:              out,
+		start:            now,
+		compactedAtStart: compactedBytes(db.GetMetrics()),
+		lastReport:       now,
+	}
+}
+
+// compactedBytes returns the total number of bytes written by compactions
+// across all the levels of the LSM.
+func compactedBytes(m storage.Metrics) uint64 {
+	var n uint64
+	for i := range m.Levels {
+		n += m.Levels[i].BytesCompacted
+	}
+	return n
+}
+
+// compacted returns the number of bytes compacted since the start of the
+// command.
+func (p *compactProgress) compacted(m storage.Metrics) uint64 {
+	return compactedBytes(m) - p.compactedAtStart
+}
+
+// report prints the progress of the compaction, followed by the per-level
+// statistics of the LSM.
+func (p *compactProgress) report(m storage.Metrics) {
+	p.lastReport = timeutil.Now()
+	elapsed := p.lastReport.Sub(p.start)
+	fmt.Fprintf(p.out, "compacted %s in %s", humanizeutil.IBytes(int64(p.compacted(m))),
+		elapsed.Round(time.Second))
+	if p.totalBytes > 0 {
+		fraction := float64(p.doneBytes) / float64(p.totalBytes)
+		fmt.Fprintf(p.out, ", processed %s of an estimated %s (%.1f%%)",
+			humanizeutil.IBytes(int64(p.doneBytes)), humanizeutil.IBytes(int64(p.totalBytes)),
+			100*fraction)
+		if fraction < 1 && p.doneBytes > p.skippedBytes {
+			// Extrapolate from the rate at which this run processed data.
+			runFraction := float64(p.doneBytes-p.skippedBytes) / float64(p.totalBytes-p.skippedBytes)
+			eta := time.Duration(float64(elapsed) * (1 - runFraction) / runFraction)
+			fmt.Fprintf(p.out, ", ETA %s", eta.Round(time.Second))
+		}
+	} else {
+		fmt.Fprintf(p.out, ", estimated compaction debt %s",
+			humanizeutil.IBytes(int64(m.Compact.EstimatedDebt)))
+	}
+	fmt.Fprintf(p.out, "\n%s\n", m)
+}
+
+// maybeReport reports the progress if the reporting interval has elapsed
+// since the last report.
+func (p *compactProgress) maybeReport(db storage.Engine) {
+	if timeutil.Since(p.lastReport) >= debugCompactProgressInterval {
+		p.report(db.GetMetrics())
+	}
+}
+
+// pace blocks until the average compaction rate since the start of the
+// command falls below the given rate, in bytes per second.
+func (p *compactProgress) pace(db storage.Engine, rate int64) {
+	if rate <= 0 {
+		return
+	}
+	target := time.Duration(float64(p.compacted(db.GetMetrics())) / float64(rate) * float64(time.Second))
+	if wait := target - timeutil.Since(p.start); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// compactionChunks returns the spans in which `debug compact` compacts the
+// store when pacing or resumability is requested: the local keyspace,
+// followed by the rest of the keyspace split at the start keys of the
+// ranges found on the store.
+func compactionChunks(ctx context.Context, db storage.Engine) ([]roachpb.Span, error) {
+	bounds := []roachpb.Key{roachpb.KeyMin, keys.LocalMax}
+	if err := kvserver.IterateRangeDescriptorsFromDisk(ctx, db,
+		func(desc roachpb.RangeDescriptor) error {
+			if k := desc.StartKey.AsRawKey(); k.Compare(keys.LocalMax) > 0 {
+				bounds = append(bounds, k)
+			}
+			return nil
+		}); err != nil {
+		return nil, err
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].Compare(bounds[j]) < 0 })
+	bounds = append(bounds, roachpb.KeyMax)
+	chunks := make([]roachpb.Span, 0, len(bounds)-1)
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i-1].Equal(bounds[i]) {
+			continue
+		}
+		chunks = append(chunks, roachpb.Span{Key: bounds[i-1], EndKey: bounds[i]})
+	}
+	return chunks, nil
+}
+
+// readCompactCheckpoint returns the key at which a previous, interrupted,
+// resumable compaction stopped, or nil if there is no such compaction.
+func readCompactCheckpoint(db storage.Engine, path string) (roachpb.Key, error) {
+	f, err := db.Open(path)
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return roachpb.Key(b), nil
+}
+
+// writeCompactCheckpoint durably records the key at which a resumable
+// compaction should resume.
+func writeCompactCheckpoint(db storage.Engine, path string, key roachpb.Key) error {
+	tmp := path + ".tmp"
+	if err := fs.WriteFile(db, tmp, key); err != nil {
+		return err
+	}
+	return db.Rename(tmp, path)
+}
+
+// compactInChunks compacts the store one chunk at a time (see
+// compactionChunks), pacing the compactions to the given rate, in bytes
+// per second, if positive. If resumable is set, the progress is recorded
+// in a checkpoint file after each chunk so that an interrupted compaction
+// can be resumed by running the command again.
+func compactInChunks(
+	ctx context.Context, out io.Writer, db storage.Engine, rate int64, resumable bool,
+) error {
+	chunks, err := compactionChunks(ctx, db)
+	if err != nil {
+		return errors.Wrap(err, "while computing the compaction chunks")
+	}
+	checkpoint := filepath.Join(db.GetAuxiliaryDir(), debugCompactCheckpointFile)
+	var resumeKey roachpb.Key
+	if resumable {
+		if resumeKey, err = readCompactCheckpoint(db, checkpoint); err != nil {
+			return errors.Wrap(err, "while reading the compaction checkpoint")
+		}
+		if resumeKey != nil {
+			fmt.Fprintf(out, "resuming compaction at key %s\n", resumeKey)
+		}
+	}
+
+	p := newCompactProgress(out, db)
+	sizes := make([]uint64, len(chunks))
+	for i, chunk := range chunks {
+		if sizes[i], err = db.ApproximateDiskBytes(chunk.Key, chunk.EndKey); err != nil {
+			return errors.Wrap(err, "while estimating the size of the data to compact")
+		}
+		p.totalBytes += sizes[i]
+	}
+
+	for i, chunk := range chunks {
+		if resumeKey != nil {
+			if chunk.EndKey.Compare(resumeKey) <= 0 {
+				// Already compacted by a previous run.
+				p.doneBytes += sizes[i]
+				p.skippedBytes += sizes[i]
+				continue
+			}
+			if chunk.Key.Compare(resumeKey) < 0 {
+				chunk.Key = resumeKey
+			}
+		}
+		if err := db.CompactRange(chunk.Key, chunk.EndKey); err != nil {
+			return errors.Wrapf(err, "while compacting %s", chunk)
+		}
+		p.doneBytes += sizes[i]
+		if resumable {
+			if err := writeCompactCheckpoint(db, checkpoint, chunk.EndKey); err != nil {
+				return errors.Wrap(err, "while writing the compaction checkpoint")
+			}
+		}
+		p.maybeReport(db)
+		p.pace(db, rate)
+	}
+	p.report(db.GetMetrics())
+
+	if resumable {
+		if err := db.Remove(checkpoint); err != nil && !oserror.IsNotExist(err) {
+			return errors.Wrap(err, "while removing the compaction checkpoint")
+		}
+	}
+	return nil
+}
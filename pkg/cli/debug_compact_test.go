@@ -0,0 +1,88 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugCompactInChunks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	db := storage.NewDefaultInMemForTesting()
+	defer db.Close()
+
+	for _, k := range []string{"a", "b", "c"} {
+		require.NoError(t, storage.MVCCPut(ctx, db, nil /* ms */, roachpb.Key(k),
+			hlc.Timestamp{WallTime: 1}, hlc.ClockTimestamp{}, roachpb.MakeValueFromString(k), nil /* txn */))
+	}
+	require.NoError(t, db.Flush())
+
+	// Without range descriptors, the store is compacted in two chunks: the
+	// local keyspace and the rest.
+	chunks, err := compactionChunks(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, []roachpb.Span{
+		{Key: roachpb.KeyMin, EndKey: keys.LocalMax},
+		{Key: keys.LocalMax, EndKey: roachpb.KeyMax},
+	}, chunks)
+
+	// Simulate an interrupted resumable compaction.
+	checkpoint := filepath.Join(db.GetAuxiliaryDir(), debugCompactCheckpointFile)
+	require.NoError(t, db.MkdirAll(db.GetAuxiliaryDir()))
+	require.NoError(t, writeCompactCheckpoint(db, checkpoint, roachpb.Key("b")))
+	resumeKey, err := readCompactCheckpoint(db, checkpoint)
+	require.NoError(t, err)
+	require.Equal(t, roachpb.Key("b"), resumeKey)
+
+	var out bytes.Buffer
+	require.NoError(t, compactInChunks(ctx, &out, db, 0 /* rate */, true /* resumable */))
+	require.Contains(t, out.String(), `resuming compaction at key "b"`)
+	require.Contains(t, out.String(), "(100.0%)")
+
+	// The checkpoint is removed once the compaction completes.
+	resumeKey, err = readCompactCheckpoint(db, checkpoint)
+	require.NoError(t, err)
+	require.Nil(t, resumeKey)
+}
+
+func TestDebugCompactProgressETA(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	db := storage.NewDefaultInMemForTesting()
+	defer db.Close()
+
+	// Half of the data was compacted by a previous run, and this run
+	// processed half of the rest in 10 minutes: the remaining quarter should
+	// take another 10 minutes.
+	var out bytes.Buffer
+	p := newCompactProgress(&out, db)
+	p.start = timeutil.Now().Add(-10 * time.Minute)
+	p.totalBytes, p.skippedBytes, p.doneBytes = 1000, 500, 750
+	p.report(db.GetMetrics())
+	require.Contains(t, out.String(), "(75.0%), ETA 10m0s")
+}
@@ -643,3 +643,59 @@ func (c *forceColor) Set(v string) error {
 	}
 	return nil
 }
+
+// redactLogsMode selects how merge-logs edits the sensitive data in its
+// input.
+type redactLogsMode int
+
+const (
+	redactLogsOff redactLogsMode = iota
+	redactLogsOn
+	// redactLogsHash replaces the sensitive data by a deterministic hash
+	// of its value, so that repeated identifiers can still be correlated
+	// in the merged output.
+	redactLogsHash
+)
+
+// editMode returns the log.EditSensitiveData value corresponding to
+// the redaction mode.
+func (m redactLogsMode) editMode(keepRedactable bool) log.EditSensitiveData {
+	switch m {
+	case redactLogsHash:
+		return log.SelectHashedEditMode(keepRedactable)
+	default:
+		return log.SelectEditMode(m == redactLogsOn, keepRedactable)
+	}
+}
+
+// Type implements the pflag.Value interface.
+func (m *redactLogsMode) Type() string { return "<true/false/hash>" }
+
+// String implements the pflag.Value interface.
+func (m *redactLogsMode) String() string {
+	switch *m {
+	case redactLogsOff:
+		return "false"
+	case redactLogsOn:
+		return "true"
+	case redactLogsHash:
+		return "hash"
+	default:
+		panic(errors.AssertionFailedf("unknown value: %v", int(*m)))
+	}
+}
+
+// Set implements the pflag.Value interface.
+func (m *redactLogsMode) Set(v string) error {
+	switch v {
+	case "on", "true":
+		*m = redactLogsOn
+	case "off", "false":
+		*m = redactLogsOff
+	case "hash":
+		*m = redactLogsHash
+	default:
+		return errors.Newf("unknown value: %v (supported: true/false/hash)", v)
+	}
+	return nil
+}
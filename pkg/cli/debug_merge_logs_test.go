@@ -168,6 +168,16 @@ func getCases(format string) []testCase {
 			dataPaths: []string{"/5/redactable.log"},
 			flags:     []string{"--redact=true", "--redactable-output=true", "--file-pattern", ".*"},
 		},
+		{
+			name:      "5.redact-hash-redactable-off",
+			dataPaths: []string{"/5/redactable.log"},
+			flags:     []string{"--redact=hash", "--redactable-output=false", "--file-pattern", ".*"},
+		},
+		{
+			name:      "5.redact-hash-redactable-on",
+			dataPaths: []string{"/5/redactable.log"},
+			flags:     []string{"--redact=hash", "--redactable-output=true", "--file-pattern", ".*"},
+		},
 	}
 	for i := range cases {
 		cases[i].format = format
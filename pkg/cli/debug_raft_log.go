@@ -0,0 +1,242 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/stateloader"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+)
+
+// raftLogEntryFilter selects the raft log entries printed by `debug
+// raft-log`.
+type raftLogEntryFilter int8
+
+const (
+	raftLogEntriesAll raftLogEntryFilter = iota
+	raftLogEntriesNormal
+	raftLogEntriesConfChange
+	raftLogEntriesAddSSTable
+)
+
+// String implements the pflag.Value interface.
+func (f *raftLogEntryFilter) String() string {
+	switch *f {
+	case raftLogEntriesNormal:
+		return "normal"
+	case raftLogEntriesConfChange:
+		return "confchange"
+	case raftLogEntriesAddSSTable:
+		return "addsstable"
+	}
+	return "all"
+}
+
+// Type implements the pflag.Value interface.
+func (f *raftLogEntryFilter) Type() string { return "<entry type>" }
+
+// Set implements the pflag.Value interface.
+func (f *raftLogEntryFilter) Set(v string) error {
+	switch v {
+	case "all":
+		*f = raftLogEntriesAll
+	case "normal":
+		*f = raftLogEntriesNormal
+	case "confchange":
+		*f = raftLogEntriesConfChange
+	case "addsstable":
+		*f = raftLogEntriesAddSSTable
+	default:
+		return errors.Newf("invalid entry type '%s' (supported: all/normal/confchange/addsstable)", v)
+	}
+	return nil
+}
+
+// matches returns true if the entry passes the filter.
+func (f raftLogEntryFilter) matches(s kvserver.RaftLogEntrySummary) bool {
+	switch f {
+	case raftLogEntriesNormal:
+		return !s.ConfChange
+	case raftLogEntriesConfChange:
+		return s.ConfChange
+	case raftLogEntriesAddSSTable:
+		return s.AddSSTable
+	}
+	return true
+}
+
+var debugRaftLogOpts = struct {
+	entryType       raftLogEntryFilter
+	summary         bool
+	checkSideloaded bool
+}{}
+
+// printRaftLog prints the entries of the raft log of the given range that
+// match the --type filter, either in full or, with --summary, as a short
+// description of their side effects preceded by the raft metadata from which
+// a snapshot of the range would be generated. With --check-sideloaded, the
+// sideloaded entries are cross-referenced with the files on disk.
+func printRaftLog(
+	ctx context.Context, out io.Writer, db storage.Engine, rangeID roachpb.RangeID,
+) error {
+	opts := debugRaftLogOpts
+	if opts.summary {
+		sl := stateloader.Make(rangeID)
+		hs, err := sl.LoadHardState(ctx, db)
+		if err != nil {
+			return err
+		}
+		ts, err := sl.LoadRaftTruncatedState(ctx, db)
+		if err != nil {
+			return err
+		}
+		as, err := sl.LoadRangeAppliedState(ctx, db)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "hard state: term %d, vote %d, commit %d\n", hs.Term, hs.Vote, hs.Commit)
+		fmt.Fprintf(out, "truncated state: index %d, term %d\n", ts.Index, ts.Term)
+		fmt.Fprintf(out, "snapshot metadata: index %d, term %d (lease applied index %d)\n",
+			as.RaftAppliedIndex, as.RaftAppliedIndexTerm, as.LeaseAppliedIndex)
+	}
+
+	// Sideloaded entries, by file name.
+	sideloaded := map[string]kvserver.RaftLogEntrySummary{}
+	start := keys.RaftLogPrefix(rangeID)
+	end := keys.RaftLogPrefix(rangeID).PrefixEnd()
+	// NB: raft log does not have intents.
+	if err := db.MVCCIterate(start, end, storage.MVCCKeyIterKind, storage.IterKeyTypePointsOnly,
+		func(kv storage.MVCCKeyValue, _ storage.MVCCRangeKeyStack) error {
+			if !opts.summary && !opts.checkSideloaded && opts.entryType == raftLogEntriesAll {
+				// Fast path: no need to decode the entry.
+				fmt.Fprintln(out, kvserver.SprintMVCCKeyValue(kv, true /* printKey */))
+				return nil
+			}
+			var s kvserver.RaftLogEntrySummary
+			ent, err := kvserver.DecodeRaftLogEntry(kv)
+			if err == nil {
+				s, err = kvserver.SummarizeRaftLogEntry(ent)
+			}
+			if err != nil {
+				// Let the regular printer deal with the undecodable entry.
+				fmt.Fprintln(out, kvserver.SprintMVCCKeyValue(kv, true /* printKey */))
+				return nil
+			}
+			if s.Sideloaded {
+				sideloaded[kvserver.SideloadedFilename(s.Index, s.Term)] = s
+			}
+			if !opts.entryType.matches(s) {
+				return nil
+			}
+			if opts.summary {
+				fmt.Fprintln(out, s)
+			} else {
+				fmt.Fprintln(out, kvserver.SprintMVCCKeyValue(kv, true /* printKey */))
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+
+	if !opts.checkSideloaded {
+		return nil
+	}
+	return checkSideloadedFiles(out, db, rangeID, sideloaded)
+}
+
+// checkSideloadedFiles cross-references the sideloaded entries of the raft
+// log with the files present in the range's sideloaded directory, and lists
+// the files staged for incoming snapshots of the range.
+func checkSideloadedFiles(
+	out io.Writer,
+	db storage.Engine,
+	rangeID roachpb.RangeID,
+	sideloaded map[string]kvserver.RaftLogEntrySummary,
+) error {
+	dir := kvserver.SideloadedDir(db.GetAuxiliaryDir(), rangeID)
+	fmt.Fprintf(out, "sideloaded directory: %s\n", dir)
+	files, err := listDir(db, dir)
+	if err != nil {
+		return err
+	}
+	present := map[string]bool{}
+	for _, f := range files {
+		present[f] = true
+		if _, ok := sideloaded[f]; !ok {
+			fmt.Fprintf(out, "  %s: not referenced by any raft log entry\n", f)
+		}
+	}
+	var missing []kvserver.RaftLogEntrySummary
+	for f, s := range sideloaded {
+		if !present[f] {
+			missing = append(missing, s)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Index < missing[j].Index })
+	for _, s := range missing {
+		fmt.Fprintf(out, "  %s: missing for entry at index %d, term %d\n",
+			kvserver.SideloadedFilename(s.Index, s.Term), s.Index, s.Term)
+	}
+	fmt.Fprintf(out, "  %d sideloaded entries, %d files, %d missing\n",
+		len(sideloaded), len(files), len(missing))
+
+	scratchDir := kvserver.SnapshotScratchDir(db.GetAuxiliaryDir(), rangeID)
+	fmt.Fprintf(out, "snapshot scratch directory: %s\n", scratchDir)
+	var staged int
+	for _, dir := range []string{
+		scratchDir, kvserver.LegacySnapshotScratchDir(db.GetAuxiliaryDir(), rangeID),
+	} {
+		snaps, err := listDir(db, dir)
+		if err != nil {
+			return err
+		}
+		for _, snap := range snaps {
+			ssts, err := listDir(db, filepath.Join(dir, snap))
+			if err != nil {
+				return err
+			}
+			if dir == scratchDir {
+				fmt.Fprintf(out, "  snapshot %s: %d staged SSTs\n", snap, len(ssts))
+			} else {
+				fmt.Fprintf(out, "  snapshot %s: %d staged SSTs (in %s)\n", snap, len(ssts), dir)
+			}
+		}
+		staged += len(snaps)
+	}
+	if staged == 0 {
+		fmt.Fprintln(out, "  no snapshot staged")
+	}
+	return nil
+}
+
+// listDir returns the sorted names of the files in the given directory, or
+// nothing if the directory does not exist.
+func listDir(db storage.Engine, dir string) ([]string, error) {
+	names, err := db.List(dir)
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/clierrorplus"
+	"github.com/cockroachdb/cockroach/pkg/cli/clisqlexec"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/spf13/cobra"
+)
+
+var debugSnapshotScratchCmd = &cobra.Command{
+	Use:   "snapshot-scratch [command]",
+	Short: "inspect and clean up the snapshot scratch space of a store",
+	Long: `
+Inspect and clean up the scratch space in which a store stages the incoming
+snapshots. The scratches of the snapshots in flight when a store stops are
+left behind, and only removed when it restarts. These commands operate on a
+stopped store, for example one which ran out of disk and won't start.
+`,
+	RunE: UsageAndErr,
+}
+
+var debugSnapshotScratchListCmd = &cobra.Command{
+	Use:   "list <directory>",
+	Short: "list the leftover snapshot scratches of a store",
+	Long: `
+List the scratch directories left behind in the snapshot scratch space of a
+store, with their size, the time elapsed since they were last written to, and
+whether their snapshot can be resumed.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: clierrorplus.MaybeDecorateError(runDebugSnapshotScratchList),
+}
+
+var debugSnapshotScratchCleanCmd = &cobra.Command{
+	Use:   "clean <directory>",
+	Short: "remove the leftover snapshot scratches of a store",
+	Long: `
+Remove the scratch directories left behind in the snapshot scratch space of a
+store, including the resumable ones, to reclaim their disk space. The
+snapshots are sent again once the store is restarted.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: clierrorplus.MaybeDecorateError(runDebugSnapshotScratchClean),
+}
+
+func init() {
+	debugSnapshotScratchCmd.AddCommand(
+		debugSnapshotScratchListCmd,
+		debugSnapshotScratchCleanCmd)
+}
+
+var snapshotScratchTableHeaders = []string{"path", "range", "snapshot", "size", "age", "resumable"}
+
+func runDebugSnapshotScratchList(cmd *cobra.Command, args []string) error {
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	db, err := OpenEngine(args[0], stopper, storage.MustExist, storage.ReadOnly)
+	if err != nil {
+		return err
+	}
+	rows, err := snapshotScratchRows(db, debugCtx.snapshotScratchDir, timeutil.Now())
+	if err != nil {
+		return err
+	}
+	return sqlExecCtx.PrintQueryOutput(os.Stdout, stderr, snapshotScratchTableHeaders,
+		clisqlexec.NewRowSliceIter(rows, "lllrrl"))
+}
+
+// snapshotScratchStorage returns the snapshot storage of the store with the
+// given engine, accessed through the given filesystem. The storage is in the
+// given scratch directory if one is specified, and otherwise in the auxiliary
+// directory of the store.
+func snapshotScratchStorage(
+	db storage.Engine, fs fs.FS, scratchDir string,
+) kvserver.SSTSnapshotStorage {
+	if scratchDir == "" {
+		s := kvserver.NewSSTSnapshotStorage(db, nil /* limiter */)
+		scratchDir = s.Dir()
+	}
+	return kvserver.NewSSTSnapshotStorageInDir(fs, scratchDir, nil /* limiter */)
+}
+
+// snapshotScratchRows returns a row per leftover scratch directory of the
+// snapshot storage of the given engine, with the age of the directories
+// computed as of now.
+func snapshotScratchRows(db storage.Engine, scratchDir string, now time.Time) ([][]string, error) {
+	sstSnapshotStorage := snapshotScratchStorage(db, db, scratchDir)
+	leftovers, err := sstSnapshotStorage.ScanLeftovers()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]string, 0, len(leftovers))
+	for _, l := range leftovers {
+		var rangeID string
+		if l.RangeID != 0 {
+			rangeID = fmt.Sprintf("r%d", l.RangeID)
+		}
+		var age string
+		if info, err := db.Stat(l.Dir); err == nil {
+			age = string(humanizeutil.LongDuration(now.Sub(info.ModTime())))
+		}
+		rows = append(rows, []string{
+			l.Dir, rangeID, l.SnapUUID, string(humanizeutil.IBytes(l.Bytes)), age, strconv.FormatBool(l.Resumable),
+		})
+	}
+	return rows, nil
+}
+
+func runDebugSnapshotScratchClean(cmd *cobra.Command, args []string) error {
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	// The engine is opened read-only, so that the scratches of a store which
+	// ran out of disk can be removed, and the scratches are removed through
+	// the filesystem of the OS.
+	db, err := OpenEngine(args[0], stopper, storage.MustExist, storage.ReadOnly)
+	if err != nil {
+		return err
+	}
+	return cleanSnapshotScratches(os.Stdout, db, fs.WrapVFS(vfs.Default), debugCtx.snapshotScratchDir)
+}
+
+// cleanSnapshotScratches removes, through the given filesystem, the leftover
+// scratch directories of the snapshot storage of the given engine, and reports
+// them to w.
+func cleanSnapshotScratches(
+	w io.Writer, db storage.Engine, removeFS fs.FS, scratchDir string,
+) error {
+	sstSnapshotStorage := snapshotScratchStorage(db, db, scratchDir)
+	leftovers, err := sstSnapshotStorage.ScanLeftovers()
+	if err != nil {
+		return err
+	}
+	var reclaimed int64
+	for _, l := range leftovers {
+		fmt.Fprintf(w, "removing %s (%s)\n", l.Dir, humanizeutil.IBytes(l.Bytes))
+		reclaimed += l.Bytes
+	}
+	removeStorage := snapshotScratchStorage(db, removeFS, scratchDir)
+	if err := removeStorage.Clear(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "removed %d snapshot scratches, reclaiming %s\n",
+		len(leftovers), humanizeutil.IBytes(reclaimed))
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugSnapshotScratch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	db := storage.NewDefaultInMemForTesting()
+	defer db.Close()
+
+	testutils.RunTrueAndFalse(t, "separate-dir", func(t *testing.T, separateDir bool) {
+		// Leave a scratch of r1 behind, as a crash would.
+		var scratchDir string
+		if separateDir {
+			scratchDir = "/mnt/scratch"
+		}
+		sstSnapshotStorage := snapshotScratchStorage(db, db, scratchDir)
+		dir := sstSnapshotStorage.Dir()
+		if separateDir {
+			require.Equal(t, scratchDir, dir)
+		}
+		snapDir := filepath.Join(dir, "s01", "1", "3a5e2f3c-9f7b-4c1e-8d2a-6b1f0e7c4d9a")
+		require.NoError(t, db.MkdirAll(snapDir))
+		require.NoError(t, fs.WriteFile(db, filepath.Join(snapDir, "0.sst"), make([]byte, 1<<10)))
+
+		rows, err := snapshotScratchRows(db, scratchDir, timeutil.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		require.Equal(t, []string{snapDir, "r1", filepath.Base(snapDir), "1.0 KiB"}, rows[0][:4])
+		require.Equal(t, "false", rows[0][5])
+		require.NotEmpty(t, rows[0][4])
+
+		var out bytes.Buffer
+		require.NoError(t, cleanSnapshotScratches(&out, db, db, scratchDir))
+		require.Contains(t, out.String(), "removed 1 snapshot scratches, reclaiming 1.0 KiB")
+
+		rows, err = snapshotScratchRows(db, scratchDir, timeutil.Now())
+		require.NoError(t, err)
+		require.Empty(t, rows)
+	})
+}
@@ -38,6 +38,9 @@ func runDebugDecodeProto(_ *cobra.Command, _ []string) error {
 		return errors.Errorf("--single is required when --binary is specified. " +
 			"Outputting binary data interspersed with text fields is not supported.")
 	}
+	if debugDecodeProtoBinaryOutput && debugDecodeProtoSchemaChangerSummary {
+		return errors.Errorf("--binary and --schema-changer-summary are mutually exclusive.")
+	}
 
 	if isatty.IsTerminal(os.Stdin.Fd()) {
 		fmt.Fprintln(stderr,
@@ -75,16 +78,22 @@ func runDebugDecodeProto(_ *cobra.Command, _ []string) error {
 			if err != nil {
 				return err
 			}
-		} else {
-			j, err := protoreflect.MessageToJSON(msg, protoreflect.FmtFlags{EmitDefaults: debugDecodeProtoEmitDefaults})
-			if err != nil {
-				// Unexpected error: the data was valid protobuf, but does not
-				// reflect back to JSON. We report the protobuf struct in the
-				// error message nonetheless.
-				return errors.Wrapf(err, "while JSON-encoding %#v", msg)
+			return nil
+		}
+		if debugDecodeProtoSchemaChangerSummary {
+			if lines, ok := formatSchemaChangerState(msg); ok {
+				fmt.Fprintln(out, strings.Join(lines, "\n"))
+				return nil
 			}
-			fmt.Fprint(out, j)
 		}
+		j, err := protoreflect.MessageToJSON(msg, protoreflect.FmtFlags{EmitDefaults: debugDecodeProtoEmitDefaults})
+		if err != nil {
+			// Unexpected error: the data was valid protobuf, but does not
+			// reflect back to JSON. We report the protobuf struct in the
+			// error message nonetheless.
+			return errors.Wrapf(err, "while JSON-encoding %#v", msg)
+		}
+		fmt.Fprint(out, j)
 		return nil
 	}
 
@@ -95,6 +104,11 @@ func runDebugDecodeProto(_ *cobra.Command, _ []string) error {
 			if msg == nil {
 				return false, "", nil
 			}
+			if debugDecodeProtoSchemaChangerSummary {
+				if lines, ok := formatSchemaChangerState(msg); ok {
+					return true, strings.Join(lines, "; "), nil
+				}
+			}
 
 			j, err := protoreflect.MessageToJSON(msg, protoreflect.FmtFlags{EmitDefaults: debugDecodeProtoEmitDefaults})
 			if err != nil {
@@ -0,0 +1,134 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/screl"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+// formatSchemaChangerState renders the declarative schema changer state
+// contained in msg as a list of human-readable lines. Elements are rendered
+// using their name and attributes, for instance:
+//
+//	target #1: [[Column:{DescID: 104, ColumnID: 2}, PUBLIC], DELETE_ONLY]
+//
+// The boolean return value is false if msg does not hold any declarative
+// schema changer state.
+func formatSchemaChangerState(msg protoutil.Message) (lines []string, ok bool) {
+	switch m := msg.(type) {
+	case *scpb.TargetState:
+		return formatTargetState(m), true
+	case *scpb.DescriptorState:
+		return formatDescriptorState(m), true
+	case *descpb.Descriptor:
+		state := declarativeSchemaChangerState(m)
+		if state == nil {
+			return nil, false
+		}
+		id, _, name, _, _, err := descpb.GetDescriptorMetadata(m)
+		if err != nil {
+			return nil, false
+		}
+		lines = append(lines, fmt.Sprintf("descriptor %d (%s)", id, name))
+		return append(lines, formatDescriptorState(state)...), true
+	case *jobspb.Payload:
+		details := m.GetNewSchemaChange()
+		if details == nil {
+			return nil, false
+		}
+		return formatNewSchemaChangePayload(m, details), true
+	}
+	return nil, false
+}
+
+func formatTargetState(s *scpb.TargetState) (lines []string) {
+	lines = append(lines, fmt.Sprintf("authorization: user=%s app=%s",
+		s.Authorization.UserName, s.Authorization.AppName))
+	for i, stmt := range s.Statements {
+		lines = append(lines, fmt.Sprintf("statement #%d: %s", i, stmt.Statement))
+	}
+	for i := range s.Targets {
+		t := &s.Targets[i]
+		lines = append(lines, fmt.Sprintf("target #%d: [%s, %s]",
+			i, screl.ElementString(t.Element()), t.TargetStatus))
+	}
+	return lines
+}
+
+func formatDescriptorState(s *scpb.DescriptorState) (lines []string) {
+	lines = append(lines, fmt.Sprintf("job %d: revertible=%t in_rollback=%t",
+		s.JobID, s.Revertible, s.InRollback))
+	lines = append(lines, fmt.Sprintf("authorization: user=%s app=%s",
+		s.Authorization.UserName, s.Authorization.AppName))
+	for _, stmt := range s.RelevantStatements {
+		lines = append(lines, fmt.Sprintf("statement #%d: %s",
+			stmt.StatementRank, stmt.Statement.Statement))
+	}
+	for i := range s.Targets {
+		n := screl.Node{Target: &s.Targets[i]}
+		if i < len(s.CurrentStatuses) {
+			n.CurrentStatus = s.CurrentStatuses[i]
+		}
+		rank := i
+		if i < len(s.TargetRanks) {
+			rank = int(s.TargetRanks[i])
+		}
+		lines = append(lines, fmt.Sprintf("target #%d: %s", rank, screl.NodeString(&n)))
+	}
+	return lines
+}
+
+func formatNewSchemaChangePayload(
+	p *jobspb.Payload, details *jobspb.NewSchemaChangeDetails,
+) (lines []string) {
+	lines = append(lines, fmt.Sprintf("schema change job: %s", p.Description))
+	for _, stmt := range p.Statement {
+		lines = append(lines, fmt.Sprintf("statement: %s", stmt))
+	}
+	lines = append(lines, fmt.Sprintf("descriptors: %v", p.DescriptorIDs))
+	for _, bp := range details.BackfillProgress {
+		lines = append(lines, fmt.Sprintf(
+			"backfill: table %d, index %d into %v, %d completed spans",
+			bp.TableID, bp.SourceIndexID, bp.DestIndexIDs, len(bp.CompletedSpans)))
+	}
+	for _, mp := range details.MergeProgress {
+		for _, pair := range mp.MergePairs {
+			lines = append(lines, fmt.Sprintf(
+				"merge: table %d, index %d into %d, %d completed spans",
+				mp.TableID, pair.SourceIndexID, pair.DestIndexID, len(pair.CompletedSpans)))
+		}
+	}
+	return lines
+}
+
+// declarativeSchemaChangerState returns the declarative schema changer state
+// of the descriptor, if any.
+func declarativeSchemaChangerState(desc *descpb.Descriptor) *scpb.DescriptorState {
+	switch t := desc.Union.(type) {
+	case *descpb.Descriptor_Table:
+		return t.Table.DeclarativeSchemaChangerState
+	case *descpb.Descriptor_Database:
+		return t.Database.DeclarativeSchemaChangerState
+	case *descpb.Descriptor_Schema:
+		return t.Schema.DeclarativeSchemaChangerState
+	case *descpb.Descriptor_Type:
+		return t.Type.DeclarativeSchemaChangerState
+	case *descpb.Descriptor_Function:
+		return t.Function.DeclarativeSchemaChangerState
+	}
+	return nil
+}
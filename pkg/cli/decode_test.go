@@ -19,6 +19,8 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/protoreflect"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/screl"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
@@ -149,3 +151,44 @@ func TestTryDecodeValue(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatSchemaChangerState(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	state := &scpb.DescriptorState{
+		JobID:      123,
+		Revertible: true,
+		Targets: []scpb.Target{
+			scpb.MakeTarget(scpb.ToPublic, &scpb.Column{TableID: 104, ColumnID: 2}, nil /* metadata */),
+		},
+		CurrentStatuses: []scpb.Status{scpb.Status_DELETE_ONLY},
+		TargetRanks:     []uint32{0},
+		RelevantStatements: []scpb.DescriptorState_Statement{{
+			Statement: scpb.Statement{Statement: "ALTER TABLE t ADD COLUMN j INT8"},
+		}},
+	}
+	buf, err := protoutil.Marshal(state)
+	require.NoError(t, err)
+
+	// Check that the state can be decoded through its shorthand.
+	msg := tryDecodeValue(gohex.EncodeToString(buf), "descriptor_state")
+	require.NotNil(t, msg)
+
+	lines, ok := formatSchemaChangerState(msg)
+	require.True(t, ok)
+	require.Equal(t, []string{
+		"job 123: revertible=true in_rollback=false",
+		"authorization: user= app=",
+		"statement #0: ALTER TABLE t ADD COLUMN j INT8",
+		"target #0: " + screl.NodeString(&screl.Node{
+			Target: &state.Targets[0], CurrentStatus: scpb.Status_DELETE_ONLY,
+		}),
+	}, lines)
+	require.Contains(t, lines[3], "[[Column:{DescID: 104")
+
+	// Descriptors without declarative schema changer state aren't summarized.
+	_, ok = formatSchemaChangerState(&descpb.Descriptor{
+		Union: &descpb.Descriptor_Table{Table: &descpb.TableDescriptor{ID: 104}},
+	})
+	require.False(t, ok)
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logflags"
 	"github.com/cockroachdb/cockroach/pkg/util/netutil/addr"
 	"github.com/cockroachdb/errors"
@@ -324,7 +325,7 @@ func init() {
 	// Logging flags common to all commands.
 	{
 		// Logging configuration.
-		cliflagcfg.VarFlag(pf, &stringValue{settableString: &cliCtx.logConfigInput}, cliflags.Log)
+		cliflagcfg.VarFlag(pf, &logConfigOverridesValue{snippets: &cliCtx.logConfigOverrides}, cliflags.Log)
 		cliflagcfg.VarFlag(pf, &fileContentsValue{settableString: &cliCtx.logConfigInput, fileName: "<unset>"}, cliflags.LogConfigFile)
 		cliflagcfg.StringSliceFlag(pf, &cliCtx.logConfigVars, cliflags.LogConfigVars)
 
@@ -651,6 +652,8 @@ func init() {
 		cliflagcfg.BoolFlag(f, &zipCtx.redactLogs, cliflags.ZipRedactLogs)
 		cliflagcfg.DurationFlag(f, &zipCtx.cpuProfDuration, cliflags.ZipCPUProfileDuration)
 		cliflagcfg.IntFlag(f, &zipCtx.concurrency, cliflags.ZipConcurrency)
+		cliflagcfg.VarFlag(f, humanizeutil.NewBytesValue(&zipCtx.logNodeBudget), cliflags.ZipLogNodeBudget)
+		cliflagcfg.VarFlag(f, humanizeutil.NewBytesValue(&zipCtx.logFileBudget), cliflags.ZipLogFileBudget)
 	}
 	// List-files + Zip commands.
 	for _, cmd := range []*cobra.Command{debugZipCmd, debugListFilesCmd} {
@@ -859,6 +862,10 @@ func init() {
 		f := debugBallastCmd.Flags()
 		cliflagcfg.VarFlag(f, &debugCtx.ballastSize, cliflags.Size)
 	}
+	{
+		f := debugSnapshotScratchCmd.PersistentFlags()
+		cliflagcfg.StringFlag(f, &debugCtx.snapshotScratchDir, cliflags.SnapshotScratchDir)
+	}
 	{
 		// TODO(ayang): clean up so dir isn't passed to both pebble and --store
 		f := DebugPebbleCmd.PersistentFlags()
@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
 	"github.com/cockroachdb/errors"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 )
 
 // setupLogging configures logging.
@@ -41,8 +42,8 @@ import (
 // and non-server commands (e.g. 'node ls').
 func setupLogging(ctx context.Context, cmd *cobra.Command, isServerCmd, applyConfig bool) error {
 	// Compatibility check for command-line usage.
-	if cliCtx.deprecatedLogOverrides.anySet() &&
-		cliCtx.logConfigInput.isSet {
+	explicitConfig := cliCtx.logConfigInput.isSet || len(cliCtx.logConfigOverrides) > 0
+	if cliCtx.deprecatedLogOverrides.anySet() && explicitConfig {
 		return errors.Newf("--%s is incompatible with legacy discrete logging flags", cliflags.Log.Name)
 	}
 
@@ -121,21 +122,35 @@ func setupLogging(ctx context.Context, cmd *cobra.Command, isServerCmd, applyCon
 	// TODO(knz): Remove this.
 	cliCtx.deprecatedLogOverrides.propagate(&h.Config, commandSpecificDefaultLegacyStderrOverride)
 
-	// If a configuration was specified via --log, load it.
-	if cliCtx.logConfigInput.isSet {
-		s := cliCtx.logConfigInput.s
+	// If a configuration was specified via --log-config-file and/or
+	// --log, load it.
+	var explicitConfigInput string
+	if explicitConfig {
+		var inputs []string
+		if cliCtx.logConfigInput.isSet {
+			inputs = append(inputs, cliCtx.logConfigInput.s)
+		}
+		inputs = append(inputs, cliCtx.logConfigOverrides...)
 
 		if len(cliCtx.logConfigVars) > 0 {
-			var err error
-			s, err = expandEnvironmentVariables(s, cliCtx.logConfigVars)
-			if err != nil {
-				return errors.Wrap(err, "unable to expand environment variables")
+			for i := range inputs {
+				var err error
+				inputs[i], err = expandEnvironmentVariables(inputs[i], cliCtx.logConfigVars)
+				if err != nil {
+					return errors.Wrap(err, "unable to expand environment variables")
+				}
 			}
 		}
 
-		if err := h.Set(s); err != nil {
+		s, err := mergeLogConfigInputs(inputs)
+		if err != nil {
 			return err
 		}
+		explicitConfigInput = s
+
+		if err := h.Set(s); err != nil {
+			return errors.WithDetailf(err, "effective logging configuration:\n%s", s)
+		}
 		if h.Config.FileDefaults.Dir != nil {
 			ambiguousLogDirs = false
 		}
@@ -152,6 +167,9 @@ func setupLogging(ctx context.Context, cmd *cobra.Command, isServerCmd, applyCon
 	// This ensures that all optional fields are populated and
 	// non-specified flags are inherited from defaults.
 	if err := h.Config.Validate(defaultLogDir); err != nil {
+		if explicitConfig {
+			err = errors.WithDetailf(err, "effective logging configuration:\n%s", h.String())
+		}
 		return err
 	}
 
@@ -196,8 +214,8 @@ func setupLogging(ctx context.Context, cmd *cobra.Command, isServerCmd, applyCon
 	cliCtx.logShutdownFn = logShutdownFn
 
 	// If using a custom config, report the configuration at the start of the logging stream.
-	if cliCtx.logConfigInput.isSet {
-		log.Ops.Infof(ctx, "using explicit logging configuration:\n%s", cliCtx.logConfigInput.s)
+	if explicitConfig {
+		log.Ops.Infof(ctx, "using explicit logging configuration:\n%s", explicitConfigInput)
 	}
 
 	if cliCtx.ambiguousLogDir {
@@ -392,6 +410,68 @@ func (l stringValue) Type() string { return "<string>" }
 // String implements the pflag.Value interface.
 func (l stringValue) String() string { return l.s }
 
+// logConfigOverridesValue accumulates the YAML snippets passed via
+// repeated uses of --log.
+type logConfigOverridesValue struct {
+	snippets *[]string
+}
+
+var _ flag.Value = (*logConfigOverridesValue)(nil)
+
+// Set implements the pflag.Value interface.
+func (l *logConfigOverridesValue) Set(s string) error {
+	*l.snippets = append(*l.snippets, s)
+	return nil
+}
+
+// Type implements the pflag.Value interface.
+func (l logConfigOverridesValue) Type() string { return "<string>" }
+
+// String implements the pflag.Value interface.
+func (l logConfigOverridesValue) String() string {
+	if l.snippets == nil {
+		return ""
+	}
+	return strings.Join(*l.snippets, "\n")
+}
+
+// mergeLogConfigInputs merges the given YAML logging configuration inputs,
+// later inputs taking precedence over earlier ones. Mappings are merged
+// recursively, so that an input only overrides the fields it specifies;
+// other values, including lists, are replaced wholesale.
+func mergeLogConfigInputs(inputs []string) (string, error) {
+	if len(inputs) == 1 {
+		// Nothing to merge. Preserve the input as-is.
+		return inputs[0], nil
+	}
+	merged := map[interface{}]interface{}{}
+	for _, input := range inputs {
+		var m map[interface{}]interface{}
+		if err := yaml.Unmarshal([]byte(input), &m); err != nil {
+			return "", errors.Wrapf(err, "invalid logging configuration %q", input)
+		}
+		mergeYAMLMappings(merged, m)
+	}
+	b, err := yaml.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// mergeYAMLMappings merges src into dst recursively.
+func mergeYAMLMappings(dst, src map[interface{}]interface{}) {
+	for k, v := range src {
+		if srcMap, ok := v.(map[interface{}]interface{}); ok {
+			if dstMap, ok := dst[k].(map[interface{}]interface{}); ok {
+				mergeYAMLMappings(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
 type fileContentsValue struct {
 	*settableString
 	fileName string
@@ -18,6 +18,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -299,7 +300,7 @@ func TestExpandEnvironmentVariables(t *testing.T) {
 }
 
 // TestLogFlagCombinations checks that --log and --log-config-file properly
-// override each other and that --log-config-vars stores the appropriate values
+// combine with each other and that --log-config-vars stores the appropriate values
 // in the cliContext struct.
 func TestLogFlagCombinations(t *testing.T) {
 	defer leaktest.AfterTest(t)()
@@ -330,33 +331,36 @@ func TestLogFlagCombinations(t *testing.T) {
 
 	f := startCmd.Flags()
 	testData := []struct {
-		args            []string
-		expectedLogCfg  string
-		expectedLogVars []string
+		args                 []string
+		expectedLogCfg       string
+		expectedLogOverrides []string
+		expectedLogVars      []string
 	}{
 		{
 			args:           []string{"start"},
 			expectedLogCfg: "",
 		},
 		{
-			args:           []string{"start", "--log=foo"},
-			expectedLogCfg: "foo",
+			args:                 []string{"start", "--log=foo"},
+			expectedLogOverrides: []string{"foo"},
 		},
 		{
 			args:           []string{"start", "--log-config-file=" + tmpfile.Name()},
 			expectedLogCfg: filecontents,
 		},
 		{
-			args:           []string{"start", "--log=foo", "--log=bar"},
-			expectedLogCfg: "bar",
+			args:                 []string{"start", "--log=foo", "--log=bar"},
+			expectedLogOverrides: []string{"foo", "bar"},
 		},
 		{
-			args:           []string{"start", "--log=foo", "--log-config-file=" + tmpfile.Name()},
-			expectedLogCfg: filecontents,
+			args:                 []string{"start", "--log=foo", "--log-config-file=" + tmpfile.Name()},
+			expectedLogCfg:       filecontents,
+			expectedLogOverrides: []string{"foo"},
 		},
 		{
-			args:           []string{"start", "--log-config-file=" + tmpfile.Name(), "--log=bar"},
-			expectedLogCfg: "bar",
+			args:                 []string{"start", "--log-config-file=" + tmpfile.Name(), "--log=bar"},
+			expectedLogCfg:       filecontents,
+			expectedLogOverrides: []string{"bar"},
 		},
 		{
 			args:            []string{"start", "--log-config-file=" + tmpfile.Name(), "--log-config-vars=HOST_IP"},
@@ -381,6 +385,11 @@ func TestLogFlagCombinations(t *testing.T) {
 				i, td.expectedLogCfg, cliCtx.logConfigInput.s, td.args)
 		}
 
+		if !reflect.DeepEqual(td.expectedLogOverrides, cliCtx.logConfigOverrides) {
+			t.Errorf("%d. cliCtx.logConfigOverrides expected '%s', but got '%s'. td.args was '%#v'.",
+				i, td.expectedLogOverrides, cliCtx.logConfigOverrides, td.args)
+		}
+
 		if !reflect.DeepEqual(td.expectedLogVars, cliCtx.logConfigVars) {
 			t.Errorf("%d. cliCtx.logConfigVars expected '%s', but got '%s'. td.args was '%#v'.",
 				i, td.expectedLogCfg, cliCtx.logConfigVars, td.args)
@@ -388,6 +397,47 @@ func TestLogFlagCombinations(t *testing.T) {
 	}
 }
 
+// TestMergeLogConfigInputs checks that --log snippets are merged on top of
+// the configuration file, in order.
+func TestMergeLogConfigInputs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const file = `
+sinks:
+  file-groups:
+    default:
+      channels: [DEV]
+      format: crdb-v2
+      buffering:
+        max-staleness: 5s
+        max-buffer-size: 1MiB
+`
+	s, err := mergeLogConfigInputs([]string{
+		file,
+		`sinks: {file-groups: {default: {format: json}}}`,
+		`sinks: {file-groups: {default: {buffering: {max-buffer-size: 10MiB}}}}`,
+		`sinks: {file-groups: {default: {format: crdb-v1}}}`,
+	})
+	require.NoError(t, err)
+
+	h := logconfig.Holder{Config: logconfig.DefaultConfig()}
+	require.NoError(t, h.Set(s))
+	fc := h.Config.Sinks.FileGroups["default"]
+	require.NotNil(t, fc)
+	require.Equal(t, "crdb-v1", *fc.Format)
+	require.Equal(t, 5*time.Second, *fc.Buffering.MaxStaleness)
+	require.Equal(t, logconfig.ByteSize(10<<20), *fc.Buffering.MaxBufferSize)
+
+	// A single input is passed through as-is.
+	s, err = mergeLogConfigInputs([]string{file})
+	require.NoError(t, err)
+	require.Equal(t, file, s)
+
+	_, err = mergeLogConfigInputs([]string{file, `sinks: {`})
+	require.Error(t, err)
+}
+
 func Example_logging() {
 	c := NewCLITest(TestCLIParams{})
 	defer c.Cleanup()
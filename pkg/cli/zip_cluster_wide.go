@@ -25,16 +25,17 @@ import (
 )
 
 const (
-	debugBase         = "debug"
-	eventsName        = debugBase + "/events"
-	livenessName      = debugBase + "/liveness"
-	nodesPrefix       = debugBase + "/nodes"
-	rangelogName      = debugBase + "/rangelog"
-	reportsPrefix     = debugBase + "/reports"
-	schemaPrefix      = debugBase + "/schema"
-	settingsName      = debugBase + "/settings"
-	problemRangesName = reportsPrefix + "/problemranges"
-	tenantRangesName  = debugBase + "/tenant_ranges"
+	debugBase           = "debug"
+	eventsName          = debugBase + "/events"
+	livenessName        = debugBase + "/liveness"
+	nodesPrefix         = debugBase + "/nodes"
+	rangelogName        = debugBase + "/rangelog"
+	reportsPrefix       = debugBase + "/reports"
+	schemaPrefix        = debugBase + "/schema"
+	schemaChangesPrefix = debugBase + "/schemachanges"
+	settingsName        = debugBase + "/settings"
+	problemRangesName   = reportsPrefix + "/problemranges"
+	tenantRangesName    = debugBase + "/tenant_ranges"
 )
 
 // makeClusterWideZipRequests defines the zipRequests that are to be
@@ -174,6 +175,10 @@ func (zc *debugZipContext) collectClusterData(
 		}
 	}
 
+	if err := zc.collectSchemaChangePlans(ctx); err != nil {
+		return nodesInfo{}, nil, errors.Wrap(err, "fetching schema change plans")
+	}
+
 	{
 		s := zc.clusterPrinter.start("requesting nodes")
 		err := zc.runZipFn(ctx, s, func(ctx context.Context) error {
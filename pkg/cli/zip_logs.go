@@ -0,0 +1,64 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+)
+
+// logFileBudgets computes, for each of the given log files, the number of
+// bytes that can be retrieved from it given the per-node and per-file
+// budgets (zero meaning unlimited). The per-node budget is assigned to the
+// most recently modified files first. A zero in the result means the file
+// can be retrieved in full; a negative value means the node budget is
+// exhausted and the file must be skipped.
+func logFileBudgets(files []logpb.FileInfo, nodeBudget, fileBudget int64) []int64 {
+	budgets := make([]int64, len(files))
+	if nodeBudget <= 0 && fileBudget <= 0 {
+		return budgets
+	}
+	order := make([]int, len(files))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return files[order[i]].ModTimeNanos > files[order[j]].ModTimeNanos
+	})
+	remaining := nodeBudget
+	for _, i := range order {
+		size := files[i].SizeBytes
+		budget := fileBudget
+		if nodeBudget > 0 {
+			if remaining <= 0 {
+				budgets[i] = -1
+				continue
+			}
+			if budget <= 0 || budget > remaining {
+				budget = remaining
+			}
+		}
+		if budget > 0 && size <= budget {
+			// The file fits entirely.
+			budget = 0
+		}
+		budgets[i] = budget
+		if nodeBudget > 0 {
+			if budget == 0 {
+				remaining -= size
+			} else {
+				remaining -= budget
+			}
+		}
+	}
+	return budgets
+}
@@ -0,0 +1,50 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFileBudgets(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	files := []logpb.FileInfo{
+		{Name: "old", SizeBytes: 100, ModTimeNanos: 1},
+		{Name: "new", SizeBytes: 100, ModTimeNanos: 3},
+		{Name: "mid", SizeBytes: 100, ModTimeNanos: 2},
+	}
+	testCases := []struct {
+		nodeBudget, fileBudget int64
+		expected               []int64
+	}{
+		// No budget: everything is retrieved in full.
+		{0, 0, []int64{0, 0, 0}},
+		// Per-file budget only.
+		{0, 50, []int64{50, 50, 50}},
+		{0, 100, []int64{0, 0, 0}},
+		// The node budget goes to the newest files first.
+		{150, 0, []int64{-1, 0, 50}},
+		{300, 0, []int64{0, 0, 0}},
+		// Both budgets.
+		{150, 60, []int64{30, 60, 60}},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("node=%d,file=%d", tc.nodeBudget, tc.fileBudget), func(t *testing.T) {
+			require.Equal(t, tc.expected, logFileBudgets(files, tc.nodeBudget, tc.fileBudget))
+		})
+	}
+}
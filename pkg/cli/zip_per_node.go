@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
@@ -477,6 +478,7 @@ func (zc *debugZipContext) collectPerNodeData(
 		// transfers somehow.
 
 		nodePrinter.info("%d log files found", len(logs.Files))
+		var selected []logpb.FileInfo
 		for _, file := range logs.Files {
 			ctime := extractTimeFromFileName(file.Name)
 			mtime := timeutil.Unix(0, file.ModTimeNanos)
@@ -484,6 +486,16 @@ func (zc *debugZipContext) collectPerNodeData(
 				nodePrinter.info("skipping excluded log file: %s", file.Name)
 				continue
 			}
+			selected = append(selected, file)
+		}
+
+		// Apportion the log size budgets, if any, to the selected files.
+		budgets := logFileBudgets(selected, zipCtx.logNodeBudget, zipCtx.logFileBudget)
+		for i, file := range selected {
+			if budgets[i] < 0 {
+				nodePrinter.info("skipping log file: %s (node log budget exhausted)", file.Name)
+				continue
+			}
 
 			logPrinter := nodePrinter.withPrefix("log file: %s", file.Name)
 			name := prefix + "/logs/" + file.Name
@@ -495,6 +507,7 @@ func (zc *debugZipContext) collectPerNodeData(
 					entries, err = zc.status.LogFile(
 						ctx, &serverpb.LogFileRequest{
 							NodeId: id, File: file.Name, Redact: zipCtx.redactLogs,
+							MaxBytes: budgets[i],
 						})
 					return err
 				}); requestErr != nil {
@@ -503,6 +516,15 @@ func (zc *debugZipContext) collectPerNodeData(
 				}
 				continue
 			}
+			if budgets[i] > 0 {
+				// The budget is enforced by the server, unless it predates
+				// LogFileRequest.MaxBytes.
+				var omitted int
+				entries.Entries, omitted = log.TrimEntries(entries.Entries, budgets[i])
+				if omitted > 0 {
+					sf.progress("omitting %d entries to fit the log budget", omitted)
+				}
+			}
 			sf.progress("writing output: %s", name)
 			warnRedactLeak := false
 			if err := func() error {
@@ -0,0 +1,137 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	gohex "encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/clisqlclient"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scop"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scplan"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+// activeSchemaChangeDescriptorsQuery retrieves the descriptors involved in
+// the declarative schema changer jobs that have not completed yet.
+const activeSchemaChangeDescriptorsQuery = `
+SELECT id, encode(descriptor, 'hex')
+FROM system.descriptor
+WHERE id IN (
+  SELECT unnest(descriptor_ids)
+  FROM crdb_internal.jobs
+  WHERE job_type = 'NEW SCHEMA CHANGE'
+    AND status NOT IN ('succeeded', 'failed', 'canceled')
+)
+`
+
+// collectSchemaChangePlans reconstructs the plans of the declarative schema
+// changes in progress from the state stored in their descriptors, and dumps
+// their dependency graph and stages, both as graphviz and JSON, under
+// debug/schemachanges/<job id>/. The descriptors that cannot be decoded are
+// reported in debug/schemachanges/descriptors/<descriptor id>.err.txt, and
+// ignored otherwise.
+func (zc *debugZipContext) collectSchemaChangePlans(ctx context.Context) error {
+	s := zc.clusterPrinter.start("retrieving active declarative schema changes")
+	_, rows, requestErr := sqlExecCtx.RunQuery(
+		ctx,
+		zc.firstNodeSQLConn,
+		clisqlclient.MakeQuery(activeSchemaChangeDescriptorsQuery),
+		true, /* showMoreChars */
+	)
+	if requestErr != nil {
+		return zc.z.createError(s, schemaChangesPrefix, requestErr)
+	}
+	s.done()
+
+	states := make(map[jobspb.JobID][]*scpb.DescriptorState)
+	for _, row := range rows {
+		var desc descpb.Descriptor
+		if err := func() error {
+			b, err := gohex.DecodeString(row[1])
+			if err != nil {
+				return err
+			}
+			return protoutil.Unmarshal(b, &desc)
+		}(); err != nil {
+			s := zc.clusterPrinter.start("decoding descriptor %s", row[0])
+			if cErr := zc.z.createError(
+				s, fmt.Sprintf("%s/descriptors/%s", schemaChangesPrefix, row[0]), err,
+			); cErr != nil {
+				return cErr
+			}
+			continue
+		}
+		if state := declarativeSchemaChangerState(&desc); state != nil && state.JobID != 0 {
+			states[state.JobID] = append(states[state.JobID], state)
+		}
+	}
+	zc.clusterPrinter.info("%d active declarative schema changes found", len(states))
+
+	jobIDs := make([]jobspb.JobID, 0, len(states))
+	for jobID := range states {
+		jobIDs = append(jobIDs, jobID)
+	}
+	sort.Slice(jobIDs, func(i, j int) bool { return jobIDs[i] < jobIDs[j] })
+	for _, jobID := range jobIDs {
+		prefix := fmt.Sprintf("%s/%d", schemaChangesPrefix, jobID)
+		s := zc.clusterPrinter.start("planning schema change job %d", jobID)
+		plan, err := makeSchemaChangePlan(jobID, states[jobID])
+		if err != nil {
+			if cErr := zc.z.createError(s, prefix+"/plan", err); cErr != nil {
+				return cErr
+			}
+			continue
+		}
+		s.done()
+
+		deps, err := plan.DependenciesDOT()
+		if cErr := zc.z.createRawOrError(
+			zc.clusterPrinter.start("writing dependency graph"), prefix+"/deps.dot", []byte(deps), err,
+		); cErr != nil {
+			return cErr
+		}
+		stages, err := plan.StagesDOT()
+		if cErr := zc.z.createRawOrError(
+			zc.clusterPrinter.start("writing stages"), prefix+"/stages.dot", []byte(stages), err,
+		); cErr != nil {
+			return cErr
+		}
+		j, err := plan.GraphJSON()
+		if cErr := zc.z.createRawOrError(
+			zc.clusterPrinter.start("writing plan"), prefix+"/plan.json", j, err,
+		); cErr != nil {
+			return cErr
+		}
+	}
+	return nil
+}
+
+// makeSchemaChangePlan builds the plan for the post-commit phase of the
+// given schema changer job, like the job itself does when resumed.
+func makeSchemaChangePlan(
+	jobID jobspb.JobID, states []*scpb.DescriptorState,
+) (scplan.Plan, error) {
+	cs, err := scpb.MakeCurrentStateFromDescriptors(states)
+	if err != nil {
+		return scplan.Plan{}, errors.Wrapf(err, "failed to construct state for job %d", jobID)
+	}
+	return scplan.MakePlan(cs, scplan.Params{
+		ExecutionPhase:             scop.PostCommitPhase,
+		SchemaChangerJobIDSupplier: func() jobspb.JobID { return jobID },
+	})
+}
@@ -83,6 +83,7 @@ table_name NOT IN (
 	'ranges',
 	'ranges_no_leases',
 	'predefined_comments',
+	'schema_change_stage_history', -- system.schema_change_stage_history is collected instead
 	'session_trace',
 	'session_variables',
 	'tables',
@@ -163,6 +163,7 @@ type ExternalStorageContext struct {
 // ExternalStorageOption.
 type ExternalStorageOptions struct {
 	ioAccountingInterceptor ReadWriterInterceptor
+	ioConsumer              IOConsumer
 }
 
 // ExternalStorageConstructor is a function registered to create instances
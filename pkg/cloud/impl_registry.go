@@ -237,9 +237,11 @@ func MakeExternalStorage(
 			return e, nil
 		}
 
+		lim := limiters[dest.Provider]
 		return &esWrapper{
 			ExternalStorage: e,
-			lim:             limiters[dest.Provider],
+			readLim:         lim.read,
+			writeLim:        lim.write[options.ioConsumer],
 			ioRecorder:      options.ioAccountingInterceptor,
 		}, nil
 	}
@@ -248,27 +250,35 @@ func MakeExternalStorage(
 }
 
 type rwLimiter struct {
-	read, write *quotapool.RateLimiter
+	read *quotapool.RateLimiter
+	// write holds the share of each IOConsumer of the write budget.
+	write [numIOConsumers]*quotapool.ChildRateLimiter
 }
 
 // Limiters represents a collection of rate limiters for a given server to use
 // when interacting with the providers in the collection.
 type Limiters map[cloudpb.ExternalStorageProvider]rwLimiter
 
+// getRateAndBurst returns the current rate and burst configured by the
+// given settings, where zero values mean no limit.
+func getRateAndBurst(sv *settings.Values, s rateAndBurstSettings) (quotapool.Limit, int64) {
+	rate := quotapool.Limit(s.rate.Get(sv))
+	if rate == 0 {
+		rate = quotapool.Limit(math.Inf(1))
+	}
+	burst := s.burst.Get(sv)
+	if burst == 0 {
+		burst = math.MaxInt64
+	}
+	return rate, burst
+}
+
 func makeLimiter(
 	ctx context.Context, sv *settings.Values, s rateAndBurstSettings,
 ) *quotapool.RateLimiter {
 	lim := quotapool.NewRateLimiter(s.rate.Key(), quotapool.Limit(0), 0)
 	fn := func(ctx context.Context) {
-		rate := quotapool.Limit(s.rate.Get(sv))
-		if rate == 0 {
-			rate = quotapool.Limit(math.Inf(1))
-		}
-		burst := s.burst.Get(sv)
-		if burst == 0 {
-			burst = math.MaxInt64
-		}
-		lim.UpdateLimit(rate, burst)
+		lim.UpdateLimit(getRateAndBurst(sv, s))
 	}
 	s.rate.SetOnChange(sv, fn)
 	s.burst.SetOnChange(sv, fn)
@@ -276,6 +286,24 @@ func makeLimiter(
 	return lim
 }
 
+// makeSharedLimiter makes a limiter whose budget is shared by all the
+// IOConsumers, and returns the share of each of them.
+func makeSharedLimiter(
+	ctx context.Context, sv *settings.Values, s rateAndBurstSettings,
+) (shares [numIOConsumers]*quotapool.ChildRateLimiter) {
+	rate, burst := getRateAndBurst(sv, s)
+	lim := quotapool.NewHierarchicalRateLimiter(s.rate.Key(), rate, burst)
+	fn := func(ctx context.Context) {
+		lim.UpdateLimit(getRateAndBurst(sv, s))
+	}
+	s.rate.SetOnChange(sv, fn)
+	s.burst.SetOnChange(sv, fn)
+	for c := range shares {
+		shares[c] = lim.NewChild(ioConsumerNames[c], 1)
+	}
+	return shares
+}
+
 // MakeLimiters makes limiters for all registered ExternalStorageProviders and
 // sets them up to be updated when settings change. It should be called only
 // once per server at creation.
@@ -283,7 +311,7 @@ func MakeLimiters(ctx context.Context, sv *settings.Values) Limiters {
 	m := make(Limiters, len(limiterSettings))
 	for k := range limiterSettings {
 		l := limiterSettings[k]
-		m[k] = rwLimiter{read: makeLimiter(ctx, sv, l.read), write: makeLimiter(ctx, sv, l.write)}
+		m[k] = rwLimiter{read: makeLimiter(ctx, sv, l.read), write: makeSharedLimiter(ctx, sv, l.write)}
 	}
 	return m
 }
@@ -291,13 +319,14 @@ func MakeLimiters(ctx context.Context, sv *settings.Values) Limiters {
 type esWrapper struct {
 	ExternalStorage
 
-	lim        rwLimiter
+	readLim    *quotapool.RateLimiter
+	writeLim   *quotapool.ChildRateLimiter
 	ioRecorder ReadWriterInterceptor
 }
 
 func (e *esWrapper) wrapReader(ctx context.Context, r ioctx.ReadCloserCtx) ioctx.ReadCloserCtx {
-	if e.lim.read != nil {
-		r = &limitedReader{r: r, lim: e.lim.read}
+	if e.readLim != nil {
+		r = &limitedReader{r: r, lim: e.readLim}
 	}
 	if e.ioRecorder != nil {
 		r = e.ioRecorder.Reader(ctx, e.ExternalStorage, r)
@@ -306,8 +335,8 @@ func (e *esWrapper) wrapReader(ctx context.Context, r ioctx.ReadCloserCtx) ioctx
 }
 
 func (e *esWrapper) wrapWriter(ctx context.Context, w io.WriteCloser) io.WriteCloser {
-	if e.lim.write != nil {
-		w = &limitedWriter{w: w, ctx: ctx, lim: e.lim.write}
+	if e.writeLim != nil {
+		w = &limitedWriter{w: w, ctx: ctx, lim: e.writeLim}
 	}
 	if e.ioRecorder != nil {
 		w = e.ioRecorder.Writer(ctx, e.ExternalStorage, w)
@@ -377,7 +406,7 @@ func (l *limitedReader) Close(ctx context.Context) error {
 type limitedWriter struct {
 	w    io.WriteCloser
 	ctx  context.Context
-	lim  *quotapool.RateLimiter
+	lim  *quotapool.ChildRateLimiter
 	pool int64 // used to pool small write calls into fewer bigger limiter calls.
 }
 
@@ -20,3 +20,36 @@ func WithIOAccountingInterceptor(i ReadWriterInterceptor) ExternalStorageOption
 		opts.ioAccountingInterceptor = i
 	}
 }
+
+// IOConsumer identifies the kind of operation writing to an external storage.
+// The writes to a provider share the node's write budget for that provider
+// (see the cloudstorage.<provider>.write.node_rate_limit settings): each kind
+// of operation is assured an equal share of the budget while it is writing,
+// and can use the share of the kinds which are idle.
+type IOConsumer int
+
+const (
+	// OtherIOConsumer is the consumer of the writes of operations which do
+	// not specify one.
+	OtherIOConsumer IOConsumer = iota
+	// BackupIOConsumer is the consumer of the writes of backups.
+	BackupIOConsumer
+	// ExportIOConsumer is the consumer of the writes of EXPORT statements.
+	ExportIOConsumer
+
+	numIOConsumers
+)
+
+var ioConsumerNames = [numIOConsumers]string{
+	OtherIOConsumer:  "other",
+	BackupIOConsumer: "backup",
+	ExportIOConsumer: "export",
+}
+
+// WithIOConsumer sets the consumer on behalf of which the external storage
+// writes.
+func WithIOConsumer(c IOConsumer) ExternalStorageOption {
+	return func(opts *ExternalStorageOptions) {
+		opts.ioConsumer = c
+	}
+}
@@ -282,6 +282,29 @@ const (
 	// version is enabled, the receiver will look at the priority of snapshots
 	// using the fields added in 22.2.
 	PrioritizeSnapshots
+	// SchemaChangeStageHistoryTable adds the system.schema_change_stage_history
+	// table, which records the stages executed by declarative schema change
+	// jobs.
+	SchemaChangeStageHistoryTable
+	// DeclarativeRowLevelTTL is the version where setting and resetting
+	// row-level TTL via ALTER TABLE is planned by the declarative schema
+	// changer.
+	DeclarativeRowLevelTTL
+	// RedactedStmtDiagReqs is the version where statement diagnostics requests
+	// can ask for redacted bundles.
+	RedactedStmtDiagReqs
+	// ConsistencyCheckJobs adds the system.consistency_check_results table and
+	// the consistency check job, which checks the consistency of ranges in the
+	// background and persists the per-range results in that table.
+	ConsistencyCheckJobs
+	// TableAuditPolicies is the version where the audit policies of tables can
+	// select the type of access to audit (ALTER TABLE ... EXPERIMENTAL_AUDIT SET
+	// READ or SET WRITE) and the roles to audit (... FOR ROLE <role>).
+	TableAuditPolicies
+	// EncryptedSnapshots is the version where the recipients of snapshots can
+	// decrypt the snapshots encrypted end-to-end by their sender (see
+	// kv.snapshot_sender.encrypt_payload.enabled).
+	EncryptedSnapshots
 
 	// *************************************************
 	// Step (1): Add new versions here.
@@ -461,6 +484,30 @@ var rawVersionsSingleton = keyedVersions{
 		Key:     PrioritizeSnapshots,
 		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 70},
 	},
+	{
+		Key:     SchemaChangeStageHistoryTable,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 72},
+	},
+	{
+		Key:     DeclarativeRowLevelTTL,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 74},
+	},
+	{
+		Key:     RedactedStmtDiagReqs,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 76},
+	},
+	{
+		Key:     ConsistencyCheckJobs,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 78},
+	},
+	{
+		Key:     TableAuditPolicies,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 80},
+	},
+	{
+		Key:     EncryptedSnapshots,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 82},
+	},
 
 	// *************************************************
 	// Step (2): Add new versions here.
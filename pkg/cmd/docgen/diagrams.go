@@ -882,8 +882,7 @@ var specs = []stmtSpec{
 			"relation_expr": "table_name",
 		},
 		regreplace: map[string]string{
-			`'READ' 'WRITE' .*`: `'READ' 'WRITE'`,
-			`'OFF' .*`:          `'OFF'`,
+			`('READ' 'WRITE'|'READ'|'WRITE'|'OFF'|role_spec)( \( .*)?$`: `$1`,
 		},
 	},
 	{
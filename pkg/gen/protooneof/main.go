@@ -0,0 +1,86 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// protooneof generates accessors for the members of a oneof in a proto
+// message: a marker method restricting an interface to the members,
+// ForEach and Find functions over an iterator, and a visitor. The
+// message either contains a oneof or is marked with the
+// (gogoproto.onlyone) option.
+//
+// For example, scpb generates the accessors of the members of
+// ElementProto with:
+//
+//	protooneof --in elements.proto --message ElementProto --package scpb \
+//	  --iface Element --marker element \
+//	  --iterator ElementStatusIterator --for-each ForEachElementStatus \
+//	  --iterator-args "current Status, target TargetStatus" \
+//	  --out elements_generated.go
+//
+// Only the oneofs of proto messages are supported: the visitors of the
+// scop operations, which are Go structs, are still generated from their
+// declarations by scop/generate_visitor.go, and the eventpb events are
+// not members of a oneof.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+)
+
+var (
+	in           = flag.String("in", "", "input proto file")
+	out          = flag.String("out", "", "output file for generated go code")
+	message      = flag.String("message", "", "name of the proto message containing the oneof")
+	pkg          = flag.String("package", "", "name of the package of the generated code")
+	iface        = flag.String("iface", "", "name of the interface implemented by the members")
+	marker       = flag.String("marker", "", "name of the marker method of the interface, if any")
+	iterator     = flag.String("iterator", "", "name of the iterator interface, if ForEach and Find functions are generated")
+	forEach      = flag.String("for-each", "", "name of the method of the iterator interface")
+	iteratorArgs = flag.String("iterator-args", "", "comma-separated list of the arguments passed to the callback of the iterator along with the member, e.g. \"current Status, target TargetStatus\"")
+	visitor      = flag.String("visitor", "", "name of the visitor interface, if generated")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		exit.WithCode(exit.FatalError())
+	}
+}
+
+func run() error {
+	if *in == "" || *out == "" || *message == "" || *pkg == "" {
+		return fmt.Errorf("--in, --out, --message and --package are required")
+	}
+	src, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	members, err := parseMembers(src, *message)
+	if err != nil {
+		return fmt.Errorf("%s: %v", *in, err)
+	}
+	code, err := generate(config{
+		pkg:          *pkg,
+		iface:        *iface,
+		marker:       *marker,
+		iterator:     *iterator,
+		forEach:      *forEach,
+		iteratorArgs: *iteratorArgs,
+		visitor:      *visitor,
+	}, members)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(*out, code, 0666)
+}
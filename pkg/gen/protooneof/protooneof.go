@@ -0,0 +1,239 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// config describes the code to generate for the members of a oneof.
+type config struct {
+	// pkg is the name of the package of the generated code.
+	pkg string
+	// iface is the name of the interface implemented by the members.
+	iface string
+	// marker, if set, is the name of an unexported method of iface which
+	// is implemented for every member, so that only the members of the
+	// oneof implement iface.
+	marker string
+	// iterator, if set, is the name of an interface with a single method,
+	// forEach, which iterates over values of type iface along with the
+	// arguments described by iteratorArgs (e.g. "current Status, target
+	// TargetStatus"). ForEach and Find functions are generated for
+	// every member.
+	iterator, forEach, iteratorArgs string
+	// visitor, if set, is the name of a generated interface with a Visit
+	// method for every member, along with a function dispatching values
+	// of type iface to it.
+	visitor string
+}
+
+var (
+	commentRegexp = regexp.MustCompile(`//[^\n]*\n|(?s)/\*.*?\*/`)
+	fieldRegexp   = regexp.MustCompile(`^\s*(?:repeated\s+|optional\s+)?([\w.]+)\s+(\w+)\s*=\s*\d+`)
+	onlyoneRegexp = regexp.MustCompile(`option\s+\(gogoproto\.onlyone\)\s*=\s*true\s*;`)
+	oneofRegexp   = regexp.MustCompile(`\boneof\s+\w+\s*{`)
+)
+
+// parseMembers returns the names of the types of the members of the
+// oneof of the given message in the proto source, sorted. The message
+// either contains a single oneof, or is marked with the
+// (gogoproto.onlyone) option, in which case its fields are the members.
+func parseMembers(src []byte, message string) ([]string, error) {
+	src = commentRegexp.ReplaceAll(src, []byte("\n"))
+	loc := regexp.MustCompile(`\bmessage\s+` + regexp.QuoteMeta(message) + `\s*{`).FindIndex(src)
+	if loc == nil {
+		return nil, fmt.Errorf("message %s not found", message)
+	}
+	body, err := block(src[loc[1]:])
+	if err != nil {
+		return nil, fmt.Errorf("message %s: %v", message, err)
+	}
+	if loc := oneofRegexp.FindIndex(body); loc != nil {
+		if body, err = block(body[loc[1]:]); err != nil {
+			return nil, fmt.Errorf("message %s: %v", message, err)
+		}
+	} else if !onlyoneRegexp.Match(body) {
+		return nil, fmt.Errorf("message %s has neither a oneof nor the onlyone option", message)
+	}
+
+	var names []string
+	for _, stmt := range strings.Split(string(body), ";") {
+		m := fieldRegexp.FindStringSubmatch(stmt)
+		if m == nil {
+			continue
+		}
+		typ := m[1]
+		if i := strings.LastIndexByte(typ, '.'); i >= 0 {
+			typ = typ[i+1:]
+		}
+		names = append(names, typ)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("message %s has no oneof members", message)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// block returns the contents of the block which src starts within,
+// i.e. up to the matching closing brace.
+func block(src []byte) ([]byte, error) {
+	depth := 1
+	for i, c := range src {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return src[:i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unbalanced braces")
+}
+
+// param is a parameter of the iterator callback.
+type param struct {
+	Name, Type string
+}
+
+// generate returns the formatted code for the members according to
+// the config.
+func generate(cfg config, members []string) ([]byte, error) {
+	if cfg.iterator != "" && (cfg.forEach == "" || cfg.iface == "") {
+		return nil, fmt.Errorf("an iterator requires its method and the member interface")
+	}
+	if (cfg.marker != "" || cfg.visitor != "") && cfg.iface == "" {
+		return nil, fmt.Errorf("a marker or a visitor requires the member interface")
+	}
+	var params []param
+	if cfg.iteratorArgs != "" {
+		for _, arg := range strings.Split(cfg.iteratorArgs, ",") {
+			f := strings.Fields(arg)
+			if len(f) != 2 {
+				return nil, fmt.Errorf("invalid iterator argument: %q", arg)
+			}
+			params = append(params, param{Name: f[0], Type: f[1]})
+		}
+	}
+	var buf bytes.Buffer
+	if err := codeTemplate.Execute(&buf, struct {
+		Pkg, Iface, Marker, Iterator, ForEach, Visitor string
+		Params                                         []param
+		Members                                        []string
+	}{
+		Pkg:      cfg.pkg,
+		Iface:    cfg.iface,
+		Marker:   cfg.marker,
+		Iterator: cfg.iterator,
+		ForEach:  cfg.forEach,
+		Visitor:  cfg.visitor,
+		Params:   params,
+		Members:  members,
+	}); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// The ForEach and Find functions name the parameters of the callbacks
+// they pass to the iterator p0, p1, etc. so that they don't shadow the
+// named results of Find.
+var codeTemplate = template.Must(template.New("protooneof").Parse(`// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Code generated by protooneof. DO NOT EDIT.
+
+package {{ .Pkg }}
+{{ if .Visitor }}
+import "github.com/cockroachdb/errors"
+{{ end -}}
+{{ if .Iterator }}
+// {{ .Iterator }} iterates over values of type {{ .Iface }}.
+type {{ .Iterator }} interface {
+	{{ .ForEach }}(fn func({{ range .Params }}{{ .Name }} {{ .Type }}, {{ end }}e {{ .Iface }}))
+}
+{{ end -}}
+{{ range $m := .Members }}
+{{- if $.Marker }}
+func (e {{ $m }}) {{ $.Marker }}() {}
+{{ end -}}
+{{ if $.Iterator }}
+// ForEach{{ $m }} iterates over elements of type {{ $m }}.
+func ForEach{{ $m }}(
+	b {{ $.Iterator }}, fn func({{ range $.Params }}{{ .Name }} {{ .Type }}, {{ end }}e *{{ $m }}),
+) {
+	if b == nil {
+		return
+	}
+	b.{{ $.ForEach }}(func({{ range $i, $p := $.Params }}p{{ $i }} {{ $p.Type }}, {{ end }}e {{ $.Iface }}) {
+		if elt, ok := e.(*{{ $m }}); ok {
+			fn({{ range $i, $p := $.Params }}p{{ $i }}, {{ end }}elt)
+		}
+	})
+}
+
+// Find{{ $m }} finds the first element of type {{ $m }}.
+func Find{{ $m }}(
+	b {{ $.Iterator }},
+) ({{ range $.Params }}{{ .Name }} {{ .Type }}, {{ end }}element *{{ $m }}) {
+	if b == nil {
+		return {{ range $.Params }}{{ .Name }}, {{ end }}element
+	}
+	b.{{ $.ForEach }}(func({{ range $i, $p := $.Params }}p{{ $i }} {{ $p.Type }}, {{ end }}e {{ $.Iface }}) {
+		if elt, ok := e.(*{{ $m }}); ok {
+			element = elt
+			{{- range $i, $p := $.Params }}
+			{{ $p.Name }} = p{{ $i }}
+			{{- end }}
+		}
+	})
+	return {{ range $.Params }}{{ .Name }}, {{ end }}element
+}
+{{ end -}}
+{{ end -}}
+{{ if .Visitor }}
+// {{ .Visitor }} is implemented by visitors of the members of {{ .Iface }}.
+type {{ .Visitor }} interface {
+{{- range .Members }}
+	Visit{{ . }}(*{{ . }}) error
+{{- end }}
+}
+
+// Visit{{ .Iface }} calls the method of the visitor corresponding to the
+// type of e.
+func Visit{{ .Iface }}(v {{ .Visitor }}, e {{ .Iface }}) error {
+	switch t := e.(type) {
+{{- range .Members }}
+	case *{{ . }}:
+		return v.Visit{{ . }}(t)
+{{- end }}
+	default:
+		return errors.AssertionFailedf("unknown {{ .Iface }} type %T", e)
+	}
+}
+{{ end -}}
+`))
@@ -0,0 +1,109 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testProto = `
+syntax = "proto3";
+
+message OnlyOne {
+  option (gogoproto.onlyone) = true;
+
+  // Comments are ignored, including ones with braces: {.
+  Foo foo = 1 [(gogoproto.customname) = "TheFoo"];
+  /* Bar bar = 2; */
+  cockroach.roachpb.Baz baz = 3;
+}
+
+message WithOneof {
+  int64 id = 1;
+  oneof value {
+    Foo foo = 2;
+    Bar bar = 3;
+  }
+  message Nested { int64 x = 1; }
+}
+
+message Neither {
+  Foo foo = 1;
+}
+`
+
+func TestParseMembers(t *testing.T) {
+	for _, tc := range []struct {
+		message  string
+		expected []string
+		err      string
+	}{
+		{message: "OnlyOne", expected: []string{"Baz", "Foo"}},
+		{message: "WithOneof", expected: []string{"Bar", "Foo"}},
+		{message: "Neither", err: "neither a oneof nor the onlyone option"},
+		{message: "Unknown", err: "message Unknown not found"},
+	} {
+		t.Run(tc.message, func(t *testing.T) {
+			members, err := parseMembers([]byte(testProto), tc.message)
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, members)
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	members := []string{"Bar", "Foo"}
+
+	code, err := generate(config{
+		pkg:          "testpb",
+		iface:        "Value",
+		marker:       "value",
+		iterator:     "ValueIterator",
+		forEach:      "ForEachValue",
+		iteratorArgs: "idx int, name string",
+		visitor:      "ValueVisitor",
+	}, members)
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"package testpb",
+		`import "github.com/cockroachdb/errors"`,
+		"ForEachValue(fn func(idx int, name string, e Value))",
+		"func (e Foo) value() {}",
+		"func ForEachBar(\n\tb ValueIterator, fn func(idx int, name string, e *Bar),\n)",
+		"func FindFoo(\n\tb ValueIterator,\n) (idx int, name string, element *Foo)",
+		"VisitBar(*Bar) error",
+		"func VisitValue(v ValueVisitor, e Value) error",
+		"case *Foo:\n\t\treturn v.VisitFoo(t)",
+	} {
+		require.Contains(t, string(code), expected)
+	}
+
+	// Only the requested accessors are generated.
+	code, err = generate(config{pkg: "testpb", iface: "Value", visitor: "ValueVisitor"}, members)
+	require.NoError(t, err)
+	require.NotContains(t, string(code), "ForEach")
+	require.NotContains(t, string(code), "value()")
+
+	_, err = generate(config{pkg: "testpb", iterator: "ValueIterator"}, members)
+	require.Error(t, err)
+	_, err = generate(config{
+		pkg: "testpb", iface: "Value", iterator: "ValueIterator", forEach: "ForEachValue",
+		iteratorArgs: "idx",
+	}, members)
+	require.Error(t, err)
+}
@@ -32,6 +32,10 @@ const (
 	executionErrorsMaxEntriesKey   = "jobs.execution_errors.max_entries"
 	executionErrorsMaxEntrySizeKey = "jobs.execution_errors.max_entry_size"
 	debugPausePointsSettingKey     = "jobs.debug.pausepoints"
+
+	diskPressureAutoResumeEnabledKey     = "jobs.disk_pressure.auto_resume.enabled"
+	diskPressureAutoResumeIntervalKey    = "jobs.disk_pressure.auto_resume.interval"
+	diskPressureAutoResumeMinCapacityKey = "jobs.disk_pressure.auto_resume.min_capacity_remaining_fraction"
 )
 
 const (
@@ -70,6 +74,18 @@ const (
 	// error. If this size is exceeded, the error will be formatted as a string
 	// and then truncated to fit the size.
 	defaultExecutionErrorsMaxEntrySize = 64 << 10 // 64 KiB
+
+	// defaultDiskPressureAutoResumeInterval is the default interval at which
+	// jobs paused due to disk pressure are considered for resumption.
+	defaultDiskPressureAutoResumeInterval = 1 * time.Minute
+
+	// defaultDiskPressureAutoResumeMinCapacity is the default fraction of
+	// remaining capacity all stores must have for jobs paused due to disk
+	// pressure to be resumed. It is higher than the default for
+	// kv.bulk_io_write.min_capacity_remaining_fraction, under which AddSSTable
+	// requests are rejected, so that resumed jobs don't immediately hit the
+	// same condition again.
+	defaultDiskPressureAutoResumeMinCapacity = 0.1
 )
 
 var (
@@ -166,6 +182,32 @@ var (
 		"the list, comma separated, of named pausepoints currently enabled for debugging",
 		"",
 	)
+
+	diskPressureAutoResumeEnabled = settings.RegisterBoolSetting(
+		settings.TenantWritable,
+		diskPressureAutoResumeEnabledKey,
+		"if enabled, bulk ingestion jobs paused because a store ran out of disk space "+
+			"are resumed once all stores have enough remaining capacity",
+		true,
+	)
+
+	diskPressureAutoResumeIntervalSetting = settings.RegisterDurationSetting(
+		settings.TenantWritable,
+		diskPressureAutoResumeIntervalKey,
+		"the interval at which a node checks whether the jobs paused due to disk "+
+			"pressure can be resumed",
+		defaultDiskPressureAutoResumeInterval,
+		settings.PositiveDuration,
+	)
+
+	diskPressureAutoResumeMinCapacity = settings.RegisterFloatSetting(
+		settings.TenantWritable,
+		diskPressureAutoResumeMinCapacityKey,
+		"remaining capacity fraction that all stores must have for the jobs paused "+
+			"due to disk pressure to be resumed",
+		defaultDiskPressureAutoResumeMinCapacity,
+		settings.NonNegativeFloat,
+	)
 )
 
 // jitter adds a small jitter in the given duration.
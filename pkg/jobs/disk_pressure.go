@@ -0,0 +1,120 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// diskPressurePauseMsg prefixes the pause reason of the jobs paused by
+// MarkDiskPressurePauseError.
+const diskPressurePauseMsg = "disk pressure"
+
+// errDiskPressurePauseSentinel marks the errors returned by
+// MarkDiskPressurePauseError, so that the registry can record in the payload
+// of the job that it was paused due to disk pressure.
+var errDiskPressurePauseSentinel = errors.New("job paused due to disk pressure")
+
+// MarkDiskPressurePauseError marks the given error, returned by a bulk
+// ingestion job because one of the stores it writes to ran out of disk space,
+// as a pause request. The job is resumed automatically once all the stores
+// have enough remaining capacity, unless jobs.disk_pressure.auto_resume.enabled
+// is disabled.
+func MarkDiskPressurePauseError(ctx context.Context, jobID jobspb.JobID, err error) error {
+	log.Ops.Warningf(ctx, "pausing job %d due to disk pressure: %v", jobID, err)
+	return errors.Mark(
+		MarkPauseRequestError(errors.Wrap(err, diskPressurePauseMsg)), errDiskPressurePauseSentinel)
+}
+
+// isDiskPressurePauseError returns true if the given error was marked by
+// MarkDiskPressurePauseError.
+func isDiskPressurePauseError(err error) bool {
+	return errors.Is(err, errDiskPressurePauseSentinel)
+}
+
+const pausedJobsQuery = `SELECT id, payload FROM system.jobs WHERE status = $1`
+
+// minStoreCapacityRemainingQuery retrieves the smallest fraction of remaining
+// capacity across all the stores of the cluster.
+const minStoreCapacityRemainingQuery = `
+SELECT min(available::FLOAT8 / capacity::FLOAT8)
+FROM crdb_internal.kv_store_status
+WHERE capacity > 0`
+
+// maybeResumeDiskPressurePausedJobs resumes the jobs paused due to disk
+// pressure if all the stores have enough remaining capacity. It only runs on
+// the meta1 leaseholder, so that a single node resumes the jobs at a time.
+func (r *Registry) maybeResumeDiskPressurePausedJobs(ctx context.Context) (retErr error) {
+	if !diskPressureAutoResumeEnabled.Get(&r.settings.SV) {
+		return nil
+	}
+	isLeaseholder, err := r.isMeta1Leaseholder(ctx, r.clock.NowAsClockTimestamp())
+	if err != nil {
+		return err
+	}
+	if !isLeaseholder {
+		return nil
+	}
+	override := sessiondata.InternalExecutorOverride{User: username.RootUserName()}
+
+	var toResume []jobspb.JobID
+	it, err := r.ex.QueryIteratorEx(ctx, "find-disk-pressure-paused-jobs", nil, /* txn */
+		override, pausedJobsQuery, StatusPaused)
+	if err != nil {
+		return err
+	}
+	defer func() { retErr = errors.CombineErrors(retErr, it.Close()) }()
+	var ok bool
+	for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+		row := it.Cur()
+		payload, err := UnmarshalPayload(row[1])
+		if err != nil {
+			return err
+		}
+		if payload.PausedOnDiskPressure {
+			toResume = append(toResume, jobspb.JobID(*row[0].(*tree.DInt)))
+		}
+	}
+	if err != nil || len(toResume) == 0 {
+		return err
+	}
+
+	row, err := r.ex.QueryRowEx(ctx, "disk-pressure-store-capacity", nil, /* txn */
+		override, minStoreCapacityRemainingQuery)
+	if err != nil {
+		return errors.Wrap(err, "retrieving store capacities")
+	}
+	if row == nil || row[0] == tree.DNull {
+		return nil
+	}
+	remaining := float64(*row[0].(*tree.DFloat))
+	if min := diskPressureAutoResumeMinCapacity.Get(&r.settings.SV); remaining < min {
+		log.VEventf(ctx, 2, "not resuming %d jobs paused due to disk pressure: "+
+			"remaining capacity %.1f%% is below %.1f%%", len(toResume), remaining*100, min*100)
+		return nil
+	}
+	for _, id := range toResume {
+		if err := r.Unpause(ctx, nil /* txn */, id); err != nil {
+			log.Warningf(ctx, "failed to resume job %d paused due to disk pressure: %v", id, err)
+			continue
+		}
+		log.Ops.Infof(ctx, "resumed job %d paused due to disk pressure: "+
+			"all stores have %.1f%% of their capacity remaining or more", id, remaining*100)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkDiskPressurePauseError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	err := &roachpb.InsufficientSpaceError{
+		StoreID:   1,
+		Op:        "ingest data",
+		Available: 1 << 20,
+		Capacity:  100 << 20,
+		Required:  0.05,
+	}
+
+	marked := MarkDiskPressurePauseError(ctx, 1, err)
+	require.True(t, IsPauseSelfError(marked))
+	require.True(t, errors.HasType(marked, &roachpb.InsufficientSpaceError{}))
+	require.True(t, isDiskPressurePauseError(marked))
+	require.Contains(t, marked.Error(), diskPressurePauseMsg)
+
+	// Other pause requests are not resumed automatically, even if their
+	// message looks alike.
+	require.False(t, isDiskPressurePauseError(MarkPauseRequestError(err)))
+	require.False(t, isDiskPressurePauseError(
+		MarkPauseRequestError(errors.Wrap(err, diskPressurePauseMsg))))
+}
@@ -417,6 +417,7 @@ func (j *Job) unpaused(ctx context.Context, txn *kv.Txn) error {
 		} else {
 			ju.UpdateStatus(StatusReverting)
 		}
+		md.Payload.PausedOnDiskPressure = false
 		ju.UpdatePayload(md.Payload)
 		return nil
 	})
@@ -468,6 +469,15 @@ type onPauseRequestFunc func(
 // and will move it to state StatusPaused.
 func (j *Job) PauseRequested(
 	ctx context.Context, txn *kv.Txn, fn onPauseRequestFunc, reason string,
+) error {
+	return j.pauseRequested(ctx, txn, fn, reason, false /* onDiskPressure */)
+}
+
+// pauseRequested is like PauseRequested. onDiskPressure records whether the
+// job paused itself due to disk pressure, in which case it is resumed
+// automatically once the space is reclaimed.
+func (j *Job) pauseRequested(
+	ctx context.Context, txn *kv.Txn, fn onPauseRequestFunc, reason string, onDiskPressure bool,
 ) error {
 	return j.Update(ctx, txn, func(txn *kv.Txn, md JobMetadata, ju *JobUpdater) error {
 		if md.Status == StatusPauseRequested || md.Status == StatusPaused {
@@ -486,6 +496,7 @@ func (j *Job) PauseRequested(
 		}
 		ju.UpdateStatus(StatusPauseRequested)
 		md.Payload.PauseReason = reason
+		md.Payload.PausedOnDiskPressure = onDiskPressure
 		ju.UpdatePayload(md.Payload)
 		log.Infof(ctx, "job %d: pause requested recorded with reason %s", j.ID(), reason)
 		return nil
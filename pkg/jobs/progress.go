@@ -15,8 +15,10 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
 )
 
 // For both backups and restores, we compute progress as the number of completed
@@ -170,3 +172,192 @@ func (p *ProgressUpdateBatcher) Done(ctx context.Context) error {
 	}
 	return nil
 }
+
+// ProgressStage describes one of the stages of a job which reports its
+// progress in stages. The weight of a stage is its contribution to the
+// fraction completed of the job, relative to the weights of the other stages.
+type ProgressStage struct {
+	Name   string
+	Weight float32
+}
+
+// StagedProgress reports the progress of a job which goes through a fixed
+// sequence of stages. The fraction completed of the job is the weighted
+// average of the fractions completed of its stages.
+//
+// The fraction completed of a stage never decreases, and making progress in a
+// stage marks all the previous stages as completed, so that the fraction
+// completed of the job never decreases either. The stages are persisted in
+// the job progress, so that they can be displayed, and so that a resumed job
+// picks up the fractions completed of the stages where they were left.
+type StagedProgress struct {
+	job *Job
+
+	mu struct {
+		syncutil.Mutex
+		stages []jobspb.StageProgress
+	}
+}
+
+// NewStagedProgress returns a StagedProgress reporting the progress of the
+// job through the given stages.
+func NewStagedProgress(j *Job, stages ...ProgressStage) *StagedProgress {
+	sp := &StagedProgress{job: j}
+	progress := j.Progress()
+	sp.mu.stages = initialStages(stages, progress.Stages, progress.GetFractionCompleted())
+	return sp
+}
+
+// initialStages returns the progress of the given stages, picking up the
+// persisted fractions completed of the stages. If no stages were persisted,
+// e.g. because the job was started by a node which did not report its
+// progress in stages, the stages are seeded from the persisted fraction
+// completed of the job, so that the reported progress does not go backwards.
+func initialStages(
+	stages []ProgressStage, persisted []jobspb.StageProgress, fractionCompleted float32,
+) []jobspb.StageProgress {
+	res := make([]jobspb.StageProgress, len(stages))
+	var total float32
+	for i, s := range stages {
+		res[i] = jobspb.StageProgress{Name: s.Name, Weight: s.Weight}
+		total += s.Weight
+		for _, p := range persisted {
+			if p.Name == s.Name {
+				res[i].FractionCompleted = p.FractionCompleted
+			}
+		}
+	}
+	if len(persisted) > 0 || fractionCompleted <= 0 {
+		return res
+	}
+	// Complete the stages in order until their weights add up to the fraction
+	// completed of the job.
+	remaining := fractionCompleted * total
+	for i := range res {
+		if remaining <= 0 || res[i].Weight <= 0 {
+			break
+		}
+		if remaining >= res[i].Weight {
+			res[i].FractionCompleted = 1
+		} else {
+			res[i].FractionCompleted = remaining / res[i].Weight
+		}
+		remaining -= res[i].Weight
+	}
+	return res
+}
+
+// StageFractionCompleted returns the fraction completed of the given stage.
+func (sp *StagedProgress) StageFractionCompleted(stage string) float32 {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	for _, s := range sp.mu.stages {
+		if s.Name == stage {
+			return s.FractionCompleted
+		}
+	}
+	return 0
+}
+
+// SetStageFraction records the fraction completed of the given stage, marks
+// the previous stages as completed, and persists the progress of the job. A
+// fraction lower than the one previously recorded for the stage is ignored.
+//
+// progressedFn, if non-nil, is called to update the job's progress details
+// along with the fraction completed.
+func (sp *StagedProgress) SetStageFraction(
+	ctx context.Context,
+	stage string,
+	fraction float32,
+	progressedFn func(context.Context, jobspb.ProgressDetails),
+) error {
+	stages, err := sp.updateStages(stage, fraction)
+	if err != nil {
+		return err
+	}
+	fractionCompleted := StagesFractionCompleted(stages)
+	return sp.job.Update(ctx, nil /* txn */, func(_ *kv.Txn, md JobMetadata, ju *JobUpdater) error {
+		if err := md.CheckRunningOrReverting(); err != nil {
+			return err
+		}
+		if progressedFn != nil {
+			progressedFn(ctx, md.Progress.Details)
+		}
+		md.Progress.Stages = stages
+		md.Progress.Progress = &jobspb.Progress_FractionCompleted{
+			FractionCompleted: fractionCompleted,
+		}
+		ju.UpdateProgress(md.Progress)
+		return nil
+	})
+}
+
+// updateStages records the fraction completed of the given stage and returns
+// a copy of the stages.
+func (sp *StagedProgress) updateStages(
+	stage string, fraction float32,
+) ([]jobspb.StageProgress, error) {
+	if fraction < 0 || fraction > 1 {
+		return nil, errors.AssertionFailedf(
+			"fraction completed %f of stage %q is outside allowable range [0.0, 1.0]", fraction, stage)
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	idx := -1
+	for i := range sp.mu.stages {
+		if sp.mu.stages[i].Name == stage {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, errors.AssertionFailedf("unknown progress stage %q", stage)
+	}
+	for i := 0; i < idx; i++ {
+		sp.mu.stages[i].FractionCompleted = 1
+	}
+	if s := &sp.mu.stages[idx]; fraction > s.FractionCompleted {
+		s.FractionCompleted = fraction
+	}
+	return append([]jobspb.StageProgress(nil), sp.mu.stages...), nil
+}
+
+// StagesFractionCompleted returns the fraction completed of a job given the
+// progress of its stages.
+func StagesFractionCompleted(stages []jobspb.StageProgress) float32 {
+	var total, completed float32
+	for _, s := range stages {
+		total += s.Weight
+		completed += s.Weight * s.FractionCompleted
+	}
+	if total == 0 {
+		return 0
+	}
+	if f := completed / total; f < 1 {
+		return f
+	}
+	return 1
+}
+
+// NewChunkProgressLogger returns a ChunkProgressLogger which reports the
+// progress of the given stage as chunks of its work are completed.
+func (sp *StagedProgress) NewChunkProgressLogger(
+	stage string, expectedChunks int, progressedFn func(context.Context, jobspb.ProgressDetails),
+) *ChunkProgressLogger {
+	startFraction := sp.StageFractionCompleted(stage)
+	return &ChunkProgressLogger{
+		expectedChunks:       expectedChunks,
+		perChunkContribution: (1.0 - startFraction) * 1.0 / float32(expectedChunks),
+		batcher: ProgressUpdateBatcher{
+			completed: startFraction,
+			reported:  startFraction,
+			Report: func(ctx context.Context, pct float32) error {
+				// Allow for slight floating-point rounding inaccuracies.
+				if pct > 1 {
+					pct = 1
+				}
+				return sp.SetStageFraction(ctx, stage, pct, progressedFn)
+			},
+		},
+	}
+}
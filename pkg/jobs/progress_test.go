@@ -0,0 +1,78 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStagedProgress(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	var sp StagedProgress
+	sp.mu.stages = []jobspb.StageProgress{
+		{Name: "a", Weight: 2},
+		{Name: "b", Weight: 1},
+		{Name: "c", Weight: 1},
+	}
+	update := func(stage string, fraction float32) float32 {
+		stages, err := sp.updateStages(stage, fraction)
+		require.NoError(t, err)
+		return StagesFractionCompleted(stages)
+	}
+
+	require.Equal(t, float32(0.25), update("a", 0.5))
+	// The fraction completed of a stage does not decrease.
+	require.Equal(t, float32(0.25), update("a", 0.1))
+	// Making progress in a stage completes the previous stages.
+	require.Equal(t, float32(0.75), update("c", 0))
+	require.Equal(t, float32(1), sp.StageFractionCompleted("b"))
+	require.Equal(t, float32(1), update("c", 1))
+
+	_, err := sp.updateStages("d", 0.5)
+	require.EqualError(t, err, `unknown progress stage "d"`)
+	_, err = sp.updateStages("a", 1.5)
+	require.Error(t, err)
+
+	require.Zero(t, StagesFractionCompleted(nil))
+}
+
+func TestInitialStages(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	stages := []ProgressStage{{Name: "a", Weight: 2}, {Name: "b", Weight: 1}, {Name: "c", Weight: 1}}
+	fractions := func(stages []jobspb.StageProgress) []float32 {
+		var res []float32
+		for _, s := range stages {
+			res = append(res, s.FractionCompleted)
+		}
+		return res
+	}
+
+	// The persisted stages are picked up.
+	persisted := []jobspb.StageProgress{{Name: "a", FractionCompleted: 1}, {Name: "b", FractionCompleted: 0.5}}
+	require.Equal(t, []float32{1, 0.5, 0}, fractions(initialStages(stages, persisted, 0.3)))
+
+	// Without persisted stages, the stages are seeded from the fraction
+	// completed of the job.
+	require.Equal(t, []float32{0, 0, 0}, fractions(initialStages(stages, nil, 0)))
+	require.Equal(t, []float32{0.5, 0, 0}, fractions(initialStages(stages, nil, 0.25)))
+	require.Equal(t, []float32{1, 0.5, 0}, fractions(initialStages(stages, nil, 0.625)))
+	require.Equal(t, []float32{1, 1, 1}, fractions(initialStages(stages, nil, 1)))
+	require.Equal(t, float32(0.625), StagesFractionCompleted(initialStages(stages, nil, 0.625)))
+}
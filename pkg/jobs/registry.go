@@ -132,6 +132,10 @@ type Registry struct {
 	// if non-empty, indicates path to file that prevents any job adoptions.
 	preventAdoptionFile string
 
+	// isMeta1Leaseholder is used to run the tasks that must only run on one
+	// node of the cluster at a time. It is nil for secondary tenants.
+	isMeta1Leaseholder func(context.Context, hlc.ClockTimestamp) (bool, error)
+
 	mu struct {
 		syncutil.Mutex
 
@@ -187,6 +191,7 @@ func MakeRegistry(
 	clusterID *base.ClusterIDContainer,
 	nodeID *base.SQLIDContainer,
 	sqlInstance sqlliveness.Instance,
+	isMeta1Leaseholder func(context.Context, hlc.ClockTimestamp) (bool, error),
 	settings *cluster.Settings,
 	histogramWindowInterval time.Duration,
 	execCtxFn jobExecCtxMaker,
@@ -204,6 +209,7 @@ func MakeRegistry(
 		clusterID:           clusterID,
 		nodeID:              nodeID,
 		sqlInstance:         sqlInstance,
+		isMeta1Leaseholder:  isMeta1Leaseholder,
 		settings:            settings,
 		execCtx:             execCtxFn,
 		preventAdoptionFile: preventAdoptionFile,
@@ -857,6 +863,34 @@ func (r *Registry) Start(ctx context.Context, stopper *stop.Stopper) error {
 	}); err != nil {
 		return err
 	}
+	// The capacity of the stores is only available to the system tenant, which
+	// resumes the jobs paused due to disk pressure on the meta1 leaseholder.
+	if r.isMeta1Leaseholder != nil {
+		if err := stopper.RunAsyncTask(ctx, "jobs/disk-pressure", func(ctx context.Context) {
+			ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+			defer cancel()
+
+			lc, cleanup := makeLoopController(r.settings, diskPressureAutoResumeIntervalSetting,
+				r.knobs.IntervalOverrides.DiskPressureAutoResume)
+			defer cleanup()
+			for {
+				select {
+				case <-lc.updated:
+					lc.onUpdate()
+				case <-stopper.ShouldQuiesce():
+					return
+				case <-lc.timer.C:
+					lc.timer.Read = true
+					if err := r.maybeResumeDiskPressurePausedJobs(ctx); err != nil {
+						log.Warningf(ctx, "error resuming jobs paused due to disk pressure: %v", err)
+					}
+					lc.onExecute()
+				}
+			}
+		}); err != nil {
+			return err
+		}
+	}
 	return stopper.RunAsyncTask(ctx, "jobs/adopt", func(ctx context.Context) {
 		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
 		defer cancel()
@@ -1023,6 +1057,14 @@ func (r *Registry) CancelRequested(ctx context.Context, txn *kv.Txn, id jobspb.J
 // PauseRequested marks the job with id as paused-requested using the specified txn (may be nil).
 func (r *Registry) PauseRequested(
 	ctx context.Context, txn *kv.Txn, id jobspb.JobID, reason string,
+) error {
+	return r.pauseRequested(ctx, txn, id, reason, false /* onDiskPressure */)
+}
+
+// pauseRequested is like PauseRequested. onDiskPressure records whether the
+// job paused itself due to disk pressure.
+func (r *Registry) pauseRequested(
+	ctx context.Context, txn *kv.Txn, id jobspb.JobID, reason string, onDiskPressure bool,
 ) error {
 	job, resumer, err := r.getJobFn(ctx, txn, id)
 	if err != nil {
@@ -1032,7 +1074,7 @@ func (r *Registry) PauseRequested(
 	if pr, ok := resumer.(PauseRequester); ok {
 		onPauseRequested = pr.OnPauseRequest
 	}
-	return job.PauseRequested(ctx, txn, onPauseRequested, reason)
+	return job.pauseRequested(ctx, txn, onPauseRequested, reason, onDiskPressure)
 }
 
 // Succeeded marks the job with id as succeeded.
@@ -1257,7 +1299,9 @@ func (r *Registry) stepThroughStateMachine(
 		}
 
 		if errors.Is(err, errPauseSelfSentinel) {
-			if err := r.PauseRequested(ctx, nil, job.ID(), err.Error()); err != nil {
+			if err := r.pauseRequested(
+				ctx, nil /* txn */, job.ID(), err.Error(), isDiskPressurePauseError(err),
+			); err != nil {
 				return err
 			}
 			return errors.Wrap(err, PauseRequestExplained)
@@ -107,6 +107,10 @@ type TestingIntervalOverrides struct {
 
 	// WaitForJobsMaxDelay
 	WaitForJobsMaxDelay *time.Duration
+
+	// DiskPressureAutoResume overrides the diskPressureAutoResumeIntervalSetting
+	// cluster setting.
+	DiskPressureAutoResume *time.Duration
 }
 
 // NewTestingKnobsWithShortIntervals return a TestingKnobs structure with
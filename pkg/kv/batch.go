@@ -754,7 +754,10 @@ func (b *Batch) adminTransferLease(key interface{}, target roachpb.StoreID) {
 // adminChangeReplicas is only exported on DB. It is here for symmetry with the
 // other operations.
 func (b *Batch) adminChangeReplicas(
-	key interface{}, expDesc roachpb.RangeDescriptor, chgs []roachpb.ReplicationChange,
+	key interface{},
+	expDesc roachpb.RangeDescriptor,
+	chgs []roachpb.ReplicationChange,
+	snapshotPriority roachpb.UserPriority,
 ) {
 	k, err := marshalKey(key)
 	if err != nil {
@@ -765,7 +768,8 @@ func (b *Batch) adminChangeReplicas(
 		RequestHeader: roachpb.RequestHeader{
 			Key: k,
 		},
-		ExpDesc: expDesc,
+		ExpDesc:          expDesc,
+		SnapshotPriority: snapshotPriority,
 	}
 	req.AddChanges(chgs...)
 
@@ -659,9 +659,22 @@ func (db *DB) AdminChangeReplicas(
 	key interface{},
 	expDesc roachpb.RangeDescriptor,
 	chgs []roachpb.ReplicationChange,
+) (*roachpb.RangeDescriptor, error) {
+	return db.AdminChangeReplicasWithPriority(ctx, key, expDesc, chgs, 0 /* snapshotPriority */)
+}
+
+// AdminChangeReplicasWithPriority is like AdminChangeReplicas, but also takes
+// a priority hint for the snapshots sent to initialize the added replicas. A
+// zero priority is the same as not specifying any.
+func (db *DB) AdminChangeReplicasWithPriority(
+	ctx context.Context,
+	key interface{},
+	expDesc roachpb.RangeDescriptor,
+	chgs []roachpb.ReplicationChange,
+	snapshotPriority roachpb.UserPriority,
 ) (*roachpb.RangeDescriptor, error) {
 	b := &Batch{}
-	b.adminChangeReplicas(key, expDesc, chgs)
+	b.adminChangeReplicas(key, expDesc, chgs, snapshotPriority)
 	if err := getOneErr(db.Run(ctx, b), b); err != nil {
 		return nil, err
 	}
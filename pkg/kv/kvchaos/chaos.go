@@ -0,0 +1,394 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package kvchaos exercises a multi-node cluster with concurrent declarative
+// schema changes, node restarts and snapshot-heavy rebalancing, and then
+// validates that the cluster is left in a consistent state.
+//
+// The operations are chosen at random, so that each run (seeded through
+// randutil) explores a different interleaving. Individual operations are
+// expected to fail from time to time, for example when a node they depend on
+// is being restarted; such failures are logged and tolerated. Only violations
+// of the invariants checked by the harness are reported as failures:
+//
+// - the catalog contains no invalid descriptors, both while the operations
+//   run and once they are done;
+// - all the schema change jobs eventually reach a terminal state;
+// - no snapshot scratch directory is left behind on any store.
+package kvchaos
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// Config configures a run of the harness.
+type Config struct {
+	// Duration is how long the operations run for.
+	Duration time.Duration
+	// NumTables is the number of tables the operations are spread over.
+	NumTables int
+	// RowsPerTable is the number of rows inserted in a table when it is
+	// created. More rows result in larger snapshots.
+	RowsPerTable int
+	// SchemaChangeWorkers is the number of concurrent schema change workers.
+	SchemaChangeWorkers int
+	// RebalanceWorkers is the number of concurrent rebalancing workers.
+	RebalanceWorkers int
+	// RestartInterval is the average time between two node restarts. Restarts
+	// are disabled if zero.
+	RestartInterval time.Duration
+	// OpTimeout bounds the duration of an individual operation.
+	OpTimeout time.Duration
+	// CheckInterval is the time between two checks of the catalog while the
+	// operations run.
+	CheckInterval time.Duration
+	// QuiesceTimeout bounds how long the harness waits, once the operations
+	// are done, for the cluster to settle before checking the invariants.
+	QuiesceTimeout time.Duration
+}
+
+// NewDefaultConfig returns a Config with reasonable defaults.
+func NewDefaultConfig() Config {
+	return Config{
+		Duration:            30 * time.Second,
+		NumTables:           4,
+		RowsPerTable:        1000,
+		SchemaChangeWorkers: 2,
+		RebalanceWorkers:    2,
+		RestartInterval:     5 * time.Second,
+		OpTimeout:           30 * time.Second,
+		CheckInterval:       time.Second,
+		QuiesceTimeout:      3 * time.Minute,
+	}
+}
+
+// Randomize perturbs the configuration, so that runs with different seeds
+// exercise different workloads.
+func (cfg *Config) Randomize(rng *rand.Rand) {
+	cfg.NumTables = 1 + rng.Intn(2*cfg.NumTables)
+	cfg.RowsPerTable = rng.Intn(2 * cfg.RowsPerTable)
+	cfg.SchemaChangeWorkers = 1 + rng.Intn(2*cfg.SchemaChangeWorkers)
+	cfg.RebalanceWorkers = 1 + rng.Intn(2*cfg.RebalanceWorkers)
+	if rng.Intn(4) == 0 {
+		cfg.RestartInterval = 0
+	} else {
+		cfg.RestartInterval = time.Duration(1 + rng.Int63n(int64(2*cfg.RestartInterval)))
+	}
+}
+
+// Cluster is the part of testcluster.TestCluster used by the harness.
+//
+// The first server is used to run the SQL operations and is never restarted.
+// Each server is expected to have a single store, whose ID is the index of the
+// server plus one.
+type Cluster interface {
+	NumServers() int
+	Server(idx int) serverutils.TestServerInterface
+	ServerConn(idx int) *gosql.DB
+	StopServer(idx int)
+	RestartServer(idx int) error
+}
+
+// metamorphicSettings are the cluster settings randomized at the start of a
+// run, along with the values they are chosen from.
+var metamorphicSettings = []struct {
+	name   string
+	values []string
+}{
+	{"kv.snapshot_rebalance.max_rate", []string{"'1 MiB'", "'32 MiB'"}},
+	{"kv.snapshot_sst.sync_size", []string{"'64 KiB'", "'2 MiB'"}},
+	{"kv.bulk_io_write.max_rate", []string{"'1 GiB'", "'4 MiB'"}},
+	{"sql.defaults.use_declarative_schema_changer", []string{"'on'", "'unsafe'"}},
+}
+
+// harness holds the state of a run.
+type harness struct {
+	c   Cluster
+	cfg Config
+	db  *gosql.DB
+
+	mu struct {
+		syncutil.Mutex
+		// down is set for the servers being restarted.
+		down map[int]bool
+		// failures are the invariant violations found so far.
+		failures []error
+		// invalidDescs are the IDs of the invalid descriptors reported in
+		// failures.
+		invalidDescs map[int64]bool
+		// ops counts the successful and failed operations of each kind.
+		ops map[string]*opStats
+	}
+}
+
+type opStats struct {
+	ok, failed int
+}
+
+// Run runs the operations configured by cfg against the given cluster, and
+// checks the invariants of the cluster once they are done. It returns the
+// invariant violations which were found, and an error if the harness itself
+// could not run.
+func Run(ctx context.Context, rng *rand.Rand, c Cluster, cfg Config) ([]error, error) {
+	h := &harness{c: c, cfg: cfg, db: c.ServerConn(0)}
+	h.mu.down = make(map[int]bool)
+	h.mu.ops = make(map[string]*opStats)
+	h.mu.invalidDescs = make(map[int64]bool)
+	log.Infof(ctx, "running chaos with config %+v", cfg)
+
+	if err := h.setup(ctx, rng); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+	g := ctxgroup.WithContext(ctx)
+	for i := 0; i < cfg.SchemaChangeWorkers; i++ {
+		wrng := rand.New(rand.NewSource(rng.Int63()))
+		g.GoCtx(func(context.Context) error {
+			h.loop(runCtx, func(ctx context.Context) { h.schemaChange(ctx, wrng) })
+			return nil
+		})
+	}
+	for i := 0; i < cfg.RebalanceWorkers; i++ {
+		wrng := rand.New(rand.NewSource(rng.Int63()))
+		g.GoCtx(func(context.Context) error {
+			h.loop(runCtx, func(ctx context.Context) { h.relocate(ctx, wrng) })
+			return nil
+		})
+	}
+	if cfg.RestartInterval > 0 && c.NumServers() > 1 {
+		wrng := rand.New(rand.NewSource(rng.Int63()))
+		g.GoCtx(func(ctx context.Context) error {
+			return h.restartLoop(ctx, runCtx, wrng)
+		})
+	}
+	g.GoCtx(func(context.Context) error {
+		h.checkLoop(runCtx)
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	for kind, s := range h.mu.ops {
+		log.Infof(ctx, "%s: %d succeeded, %d failed", kind, s.ok, s.failed)
+	}
+	if s := h.mu.ops[opSchemaChange]; s == nil || s.ok == 0 {
+		h.mu.failures = append(h.mu.failures, errors.New("no schema change succeeded"))
+	}
+	h.mu.Unlock()
+
+	h.checkInvariants(ctx)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.mu.failures, nil
+}
+
+// setup randomizes the metamorphic settings and creates the database the
+// operations work in.
+func (h *harness) setup(ctx context.Context, rng *rand.Rand) error {
+	for _, s := range metamorphicSettings {
+		v := s.values[rng.Intn(len(s.values))]
+		log.Infof(ctx, "setting %s = %s", s.name, v)
+		if _, err := h.db.ExecContext(ctx, fmt.Sprintf("SET CLUSTER SETTING %s = %s", s.name, v)); err != nil {
+			return errors.Wrapf(err, "setting %s", s.name)
+		}
+	}
+	_, err := h.db.ExecContext(ctx, "CREATE DATABASE IF NOT EXISTS chaos")
+	return err
+}
+
+// loop runs op repeatedly until the context is canceled. Each operation is
+// bounded by the configured timeout.
+func (h *harness) loop(ctx context.Context, op func(ctx context.Context)) {
+	for ctx.Err() == nil {
+		func() {
+			opCtx, cancel := context.WithTimeout(ctx, h.cfg.OpTimeout)
+			defer cancel()
+			op(opCtx)
+		}()
+	}
+}
+
+const (
+	opSchemaChange = "schema change"
+	opRelocate     = "relocate"
+	opRestart      = "restart"
+)
+
+// record accounts for the outcome of an operation.
+func (h *harness) record(ctx context.Context, kind, desc string, err error) {
+	if err != nil {
+		log.Infof(ctx, "%s %q failed: %v", kind, desc, err)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.mu.ops[kind]
+	if !ok {
+		s = &opStats{}
+		h.mu.ops[kind] = s
+	}
+	if err != nil {
+		s.failed++
+	} else {
+		s.ok++
+	}
+}
+
+// recordFailure records an invariant violation.
+func (h *harness) recordFailure(ctx context.Context, err error) {
+	log.Errorf(ctx, "invariant violated: %v", err)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mu.failures = append(h.mu.failures, err)
+}
+
+// exec runs the given statements in order, stopping at the first error, and
+// records the outcome as one operation.
+func (h *harness) exec(ctx context.Context, kind string, stmts ...string) {
+	var err error
+	for _, stmt := range stmts {
+		if _, err = h.db.ExecContext(ctx, stmt); err != nil {
+			break
+		}
+	}
+	h.record(ctx, kind, strings.Join(stmts, "; "), err)
+}
+
+// schemaChange runs a random schema change on a random table. The statements
+// are written so that they are valid regardless of the current schema, and
+// thus only fail because of the chaos.
+func (h *harness) schemaChange(ctx context.Context, rng *rand.Rand) {
+	t := fmt.Sprintf("chaos.t%d", rng.Intn(h.cfg.NumTables))
+	col := fmt.Sprintf("c%d", rng.Intn(3))
+	idx := fmt.Sprintf("i%d", rng.Intn(3))
+	switch rng.Intn(7) {
+	case 0:
+		h.exec(ctx, opSchemaChange,
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (k INT PRIMARY KEY, v INT, s STRING)", t),
+			fmt.Sprintf("INSERT INTO %s (k, v, s) SELECT i, i %% 100, repeat('x', 100) "+
+				"FROM generate_series(1, %d) AS g(i) ON CONFLICT (k) DO NOTHING", t, h.cfg.RowsPerTable))
+	case 1:
+		h.exec(ctx, opSchemaChange,
+			fmt.Sprintf("ALTER TABLE IF EXISTS %s ADD COLUMN IF NOT EXISTS %s INT NOT NULL DEFAULT %d", t, col, rng.Intn(100)))
+	case 2:
+		h.exec(ctx, opSchemaChange,
+			fmt.Sprintf("ALTER TABLE IF EXISTS %s DROP COLUMN IF EXISTS %s", t, col))
+	case 3:
+		h.exec(ctx, opSchemaChange,
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (v) STORING (s)", idx, t))
+	case 4:
+		h.exec(ctx, opSchemaChange,
+			fmt.Sprintf("DROP INDEX IF EXISTS %s@%s", t, idx))
+	case 5:
+		h.exec(ctx, opSchemaChange,
+			fmt.Sprintf("DROP TABLE IF EXISTS %s", t))
+	case 6:
+		// Splits give the relocations more ranges to move around.
+		h.exec(ctx, opSchemaChange,
+			fmt.Sprintf("ALTER TABLE %s SPLIT AT VALUES (%d)", t, rng.Intn(h.cfg.RowsPerTable+1)))
+	}
+}
+
+// relocate moves the replicas of the range containing a random row of a
+// random table to a random set of the running stores. Adding a replica to a
+// store sends it a snapshot.
+func (h *harness) relocate(ctx context.Context, rng *rand.Rand) {
+	var up []int
+	h.mu.Lock()
+	for i := 0; i < h.c.NumServers(); i++ {
+		if !h.mu.down[i] {
+			up = append(up, i)
+		}
+	}
+	h.mu.Unlock()
+
+	rng.Shuffle(len(up), func(i, j int) { up[i], up[j] = up[j], up[i] })
+	n := 3
+	if len(up) < n {
+		n = len(up)
+	}
+	n = 1 + rng.Intn(n)
+	storeIDs := make([]string, n)
+	for i := range storeIDs {
+		storeIDs[i] = fmt.Sprint(up[i] + 1)
+	}
+
+	t := fmt.Sprintf("chaos.t%d", rng.Intn(h.cfg.NumTables))
+	h.exec(ctx, opRelocate, fmt.Sprintf("ALTER TABLE %s EXPERIMENTAL_RELOCATE VALUES (ARRAY[%s], %d)",
+		t, strings.Join(storeIDs, ","), rng.Intn(h.cfg.RowsPerTable+1)))
+}
+
+// restartLoop restarts random servers until runCtx is canceled. A server
+// stopped when runCtx is canceled is restarted before returning, so the
+// restarts use the parent context.
+func (h *harness) restartLoop(ctx, runCtx context.Context, rng *rand.Rand) error {
+	randWait := func(mean time.Duration) <-chan time.Time {
+		if mean <= 0 {
+			return time.After(0)
+		}
+		return time.After(time.Duration(rng.Int63n(int64(2 * mean))))
+	}
+	for {
+		select {
+		case <-runCtx.Done():
+			return nil
+		case <-randWait(h.cfg.RestartInterval):
+		}
+
+		idx := 1 + rng.Intn(h.c.NumServers()-1)
+		h.mu.Lock()
+		h.mu.down[idx] = true
+		h.mu.Unlock()
+
+		log.Infof(ctx, "stopping server %d", idx)
+		h.c.StopServer(idx)
+		select {
+		case <-runCtx.Done():
+		case <-randWait(h.cfg.RestartInterval / 4):
+		}
+		log.Infof(ctx, "restarting server %d", idx)
+		if err := h.c.RestartServer(idx); err != nil {
+			return errors.Wrapf(err, "restarting server %d", idx)
+		}
+		h.record(ctx, opRestart, fmt.Sprintf("server %d", idx), nil)
+
+		h.mu.Lock()
+		delete(h.mu.down, idx)
+		h.mu.Unlock()
+	}
+}
+
+// checkLoop checks the catalog periodically until the context is canceled.
+func (h *harness) checkLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(h.cfg.CheckInterval):
+		}
+		if err := h.checkCatalog(ctx); err != nil && ctx.Err() == nil {
+			log.Infof(ctx, "checking the catalog failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvchaos
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/errors"
+)
+
+// checkInvariants checks the state of the cluster once the operations are
+// done. It records the violations as failures.
+func (h *harness) checkInvariants(ctx context.Context) {
+	if err := h.checkCatalog(ctx); err != nil {
+		h.recordFailure(ctx, errors.Wrap(err, "checking the catalog"))
+	}
+	if err := retry.ForDuration(h.cfg.QuiesceTimeout, func() error {
+		return h.checkSchemaChangeJobs(ctx)
+	}); err != nil {
+		h.recordFailure(ctx, err)
+	}
+	if err := retry.ForDuration(h.cfg.QuiesceTimeout, h.checkSnapshotScratches); err != nil {
+		h.recordFailure(ctx, err)
+	}
+}
+
+// checkCatalog records a failure for every invalid descriptor which was not
+// reported already. It returns an error if the catalog could not be checked,
+// which can happen because of the chaos and is thus not a failure by itself.
+func (h *harness) checkCatalog(ctx context.Context) error {
+	rows, err := h.db.QueryContext(ctx,
+		`SELECT id, database_name, schema_name, obj_name, error FROM crdb_internal.invalid_objects`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var db, schema, obj, desc gosql.NullString
+		if err := rows.Scan(&id, &db, &schema, &obj, &desc); err != nil {
+			return err
+		}
+		h.mu.Lock()
+		seen := h.mu.invalidDescs[id]
+		h.mu.invalidDescs[id] = true
+		h.mu.Unlock()
+		if !seen {
+			h.recordFailure(ctx, errors.Errorf("invalid descriptor %d (%s.%s.%s): %s",
+				id, db.String, schema.String, obj.String, desc.String))
+		}
+	}
+	return rows.Err()
+}
+
+// checkSchemaChangeJobs returns an error if a schema change job is not done.
+// The GC jobs are not checked, since they wait for the GC TTL to expire.
+func (h *harness) checkSchemaChangeJobs(ctx context.Context) error {
+	rows, err := h.db.QueryContext(ctx, `
+SELECT job_id, job_type, status
+  FROM [SHOW JOBS]
+ WHERE job_type IN ('SCHEMA CHANGE', 'NEW SCHEMA CHANGE')
+   AND status NOT IN ('succeeded', 'failed', 'canceled')`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var pending []string
+	for rows.Next() {
+		var id int64
+		var typ, status string
+		if err := rows.Scan(&id, &typ, &status); err != nil {
+			return err
+		}
+		pending = append(pending, fmt.Sprintf("%d (%s): %s", id, typ, status))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return errors.Errorf("schema change jobs not done: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// checkSnapshotScratches returns an error if the snapshot storage of a store
+// is not empty. All the snapshots have been applied or abandoned at this
+// point, and their scratches should have been removed.
+func (h *harness) checkSnapshotScratches() error {
+	for i := 0; i < h.c.NumServers(); i++ {
+		stores := h.c.Server(i).GetStores().(*kvserver.Stores)
+		if err := stores.VisitStores(func(s *kvserver.Store) error {
+			leftovers, err := s.SSTSnapshotStorage().ScanLeftovers()
+			if err != nil {
+				return err
+			}
+			if len(leftovers) > 0 {
+				dirs := make([]string, 0, len(leftovers))
+				for _, l := range leftovers {
+					dirs = append(dirs, l.Dir)
+				}
+				return errors.Errorf("snapshot storage of s%d not empty: %s", s.StoreID(), strings.Join(dirs, ", "))
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
+)
+
+// addSSTableWriteWeight is the weight of the share of the bulk IO write budget
+// of a store assured to the writes of the SSTs of AddSSTable commands.
+var addSSTableWriteWeight = settings.RegisterFloatSetting(
+	settings.SystemOnly,
+	"kv.bulk_io_write.addsstable_weight",
+	"weight of the share of the bulk IO write budget of a store assured to the writes "+
+		"of the SSTs of AddSSTable commands, relative to the staging of incoming snapshots",
+	1,
+	settings.PositiveFloat,
+)
+
+// bulkIOWriteSource is a kind of bulk write to the disk of a store, which is
+// assured its own share of the store's bulk IO write budget.
+type bulkIOWriteSource int
+
+const (
+	// bulkIOWriteSnapshotRebalance is the staging of incoming snapshots, other
+	// than recovery ones.
+	bulkIOWriteSnapshotRebalance bulkIOWriteSource = iota
+	// bulkIOWriteSnapshotRecovery is the staging of incoming recovery
+	// snapshots.
+	bulkIOWriteSnapshotRecovery
+	// bulkIOWriteAddSSTable is the writes of the SSTs of AddSSTable commands:
+	// their sideloaded copies, and the copies staged for their ingestion.
+	bulkIOWriteAddSSTable
+	numBulkIOWriteSources
+)
+
+func (src bulkIOWriteSource) String() string {
+	switch src {
+	case bulkIOWriteSnapshotRebalance:
+		return "snapshot-staging"
+	case bulkIOWriteSnapshotRecovery:
+		return "snapshot-staging-recovery"
+	case bulkIOWriteAddSSTable:
+		return "addsstable"
+	default:
+		return fmt.Sprintf("bulkIOWriteSource(%d)", int(src))
+	}
+}
+
+// weight returns the setting holding the weight of the share of the budget
+// assured to the source.
+func (src bulkIOWriteSource) weight() *settings.FloatSetting {
+	switch src {
+	case bulkIOWriteSnapshotRebalance:
+		return snapshotRebalanceWriteWeight
+	case bulkIOWriteSnapshotRecovery:
+		return snapshotRecoveryWriteWeight
+	default:
+		return addSSTableWriteWeight
+	}
+}
+
+// bulkIOWriteGovernor paces the bulk writes of a store, from all sources,
+// within the store's bulk IO write budget (kv.bulk_io_write.max_rate). A
+// source can use the whole budget while the others are idle, and is assured a
+// share of it, in proportion to its weight, otherwise. This way, a burst of
+// AddSSTable commands, e.g. from a RESTORE, cannot starve the staging of
+// incoming snapshots, nor the other way around.
+type bulkIOWriteGovernor struct {
+	limiter *quotapool.HierarchicalRateLimiter
+	sources [numBulkIOWriteSources]*quotapool.ChildRateLimiter
+}
+
+func newBulkIOWriteGovernor(st *cluster.Settings) *bulkIOWriteGovernor {
+	g := &bulkIOWriteGovernor{
+		limiter: quotapool.NewHierarchicalRateLimiter(
+			"bulk-io-write", quotapool.Limit(bulkIOWriteLimit.Get(&st.SV)), bulkIOWriteBurst),
+	}
+	bulkIOWriteLimit.SetOnChange(&st.SV, func(ctx context.Context) {
+		g.limiter.UpdateLimit(quotapool.Limit(bulkIOWriteLimit.Get(&st.SV)), bulkIOWriteBurst)
+	})
+	for i := range g.sources {
+		src := bulkIOWriteSource(i)
+		weight := src.weight()
+		g.sources[i] = g.limiter.NewChild(src.String(), weight.Get(&st.SV))
+		weight.SetOnChange(&st.SV, func(ctx context.Context) {
+			g.sources[src].UpdateWeight(weight.Get(&st.SV))
+		})
+	}
+	return g
+}
+
+// source returns the limiter pacing the writes of the given source, to be
+// waited on with limitBulkIOWrite.
+func (g *bulkIOWriteGovernor) source(src bulkIOWriteSource) *quotapool.ChildRateLimiter {
+	return g.sources[src]
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBulkIOWriteGovernor checks that the bulk IO write governor gives each
+// source its own limiter, and follows the changes of the store's budget.
+func TestBulkIOWriteGovernor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	bulkIOWriteLimit.Override(ctx, &st.SV, 10<<20)
+	g := newBulkIOWriteGovernor(st)
+
+	seen := map[*quotapool.ChildRateLimiter]bulkIOWriteSource{}
+	for src := bulkIOWriteSource(0); src < numBulkIOWriteSources; src++ {
+		l := g.source(src)
+		require.NotNil(t, l, "%s", src)
+		if other, ok := seen[l]; ok {
+			t.Fatalf("%s and %s share a limiter", src, other)
+		}
+		seen[l] = src
+		require.Equal(t, quotapool.Limit(10<<20), l.Limit(), "%s", src)
+	}
+
+	// Every source can use the whole budget while the others are idle, so
+	// they follow the changes of the budget.
+	bulkIOWriteLimit.Override(ctx, &st.SV, 20<<20)
+	for src := bulkIOWriteSource(0); src < numBulkIOWriteSources; src++ {
+		require.Equal(t, quotapool.Limit(20<<20), g.source(src).Limit(), "%s", src)
+	}
+
+	// The weights can be changed at any time.
+	addSSTableWriteWeight.Override(ctx, &st.SV, 2)
+	snapshotRecoveryWriteWeight.Override(ctx, &st.SV, 8)
+	require.NoError(t, g.source(bulkIOWriteAddSSTable).WaitN(ctx, 1))
+}
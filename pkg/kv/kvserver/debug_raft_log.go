@@ -0,0 +1,172 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// RaftLogEntrySummary is a condensed description of a raft log entry, as
+// printed by `cockroach debug raft-log --summary`.
+type RaftLogEntrySummary struct {
+	Index uint64
+	Term  uint64
+	Type  raftpb.EntryType
+	// Empty is set for entries that don't carry a command, such as the
+	// entries appended by a new raft leader.
+	Empty bool
+	// AddSSTable is set for entries that ingest an SST.
+	AddSSTable bool
+	// Sideloaded is set if the SST ingested by the entry is not inlined in
+	// the log entry but stored in the range's sideloaded storage.
+	Sideloaded bool
+	// ConfChange is set for entries that change the raft configuration.
+	ConfChange bool
+	// Details lists the human-readable descriptions of the notable
+	// side effects of the command, one per line.
+	Details []string
+}
+
+// String implements the fmt.Stringer interface.
+func (s RaftLogEntrySummary) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "index %d, term %d, %s", s.Index, s.Term, s.Type)
+	if s.Empty {
+		buf.WriteString(": empty")
+	}
+	for _, d := range s.Details {
+		fmt.Fprintf(&buf, "\n  %s", d)
+	}
+	return buf.String()
+}
+
+// DecodeRaftLogEntry decodes the raft log entry stored in the given
+// key-value pair of a range's raft log.
+func DecodeRaftLogEntry(kv storage.MVCCKeyValue) (raftpb.Entry, error) {
+	var ent raftpb.Entry
+	err := maybeUnmarshalInline(kv.Value, &ent)
+	return ent, err
+}
+
+// SummarizeRaftLogEntry decodes the given raft log entry and describes the
+// side effects of its command that are of interest when investigating
+// snapshots and log truncations: SST ingestions (and whether their payload
+// is sideloaded), configuration changes, splits, merges and truncations.
+func SummarizeRaftLogEntry(ent raftpb.Entry) (RaftLogEntrySummary, error) {
+	s := RaftLogEntrySummary{Index: ent.Index, Term: ent.Term, Type: ent.Type}
+	var cmd kvserverpb.RaftCommand
+	switch ent.Type {
+	case raftpb.EntryNormal:
+		if len(ent.Data) == 0 {
+			s.Empty = true
+			return s, nil
+		}
+		s.Sideloaded = sniffSideloadedRaftCommand(ent.Data)
+		_, cmdData := kvserverbase.DecodeRaftCommand(ent.Data)
+		if err := protoutil.Unmarshal(cmdData, &cmd); err != nil {
+			return s, err
+		}
+	case raftpb.EntryConfChange, raftpb.EntryConfChangeV2:
+		s.ConfChange = true
+		var c raftpb.ConfChangeI
+		if ent.Type == raftpb.EntryConfChange {
+			var cc raftpb.ConfChange
+			if err := protoutil.Unmarshal(ent.Data, &cc); err != nil {
+				return s, err
+			}
+			c = cc
+		} else {
+			var cc raftpb.ConfChangeV2
+			if err := protoutil.Unmarshal(ent.Data, &cc); err != nil {
+				return s, err
+			}
+			c = cc
+		}
+		cc := c.AsV2()
+		s.Details = append(s.Details, fmt.Sprintf("conf change: %s (transition: %s)",
+			raftpb.ConfChangesToString(cc.Changes), cc.Transition))
+		var ctx kvserverpb.ConfChangeContext
+		if err := protoutil.Unmarshal(cc.Context, &ctx); err != nil {
+			return s, err
+		}
+		if err := protoutil.Unmarshal(ctx.Payload, &cmd); err != nil {
+			return s, err
+		}
+	default:
+		return s, errors.Errorf("unknown log entry type: %s", ent.Type)
+	}
+
+	res := &cmd.ReplicatedEvalResult
+	if sst := res.AddSSTable; sst != nil {
+		s.AddSSTable = true
+		if s.Sideloaded && len(sst.Data) == 0 {
+			s.Details = append(s.Details, fmt.Sprintf("AddSSTable: span %s, crc32 %08x, payload sideloaded",
+				sst.Span, sst.CRC32))
+		} else {
+			s.Details = append(s.Details, fmt.Sprintf("AddSSTable: span %s, crc32 %08x, %s inlined",
+				sst.Span, sst.CRC32, humanizeutil.IBytes(int64(len(sst.Data)))))
+		}
+	}
+	if cr := res.ChangeReplicas; cr != nil {
+		s.Details = append(s.Details, fmt.Sprintf("change replicas: %s", cr))
+	}
+	if split := res.Split; split != nil {
+		s.Details = append(s.Details, fmt.Sprintf("split: %s and %s", &split.LeftDesc, &split.RightDesc))
+	}
+	if merge := res.Merge; merge != nil {
+		s.Details = append(s.Details, fmt.Sprintf("merge: %s subsumes %s", &merge.LeftDesc, &merge.RightDesc))
+	}
+	if state := res.State; state != nil && state.TruncatedState != nil {
+		s.Details = append(s.Details, fmt.Sprintf("log truncation: up to index %d (term %d)",
+			state.TruncatedState.Index, state.TruncatedState.Term))
+	}
+	if res.ComputeChecksum != nil {
+		s.Details = append(s.Details, "consistency check")
+	}
+	if res.IsLeaseRequest && res.State != nil && res.State.Lease != nil {
+		s.Details = append(s.Details, fmt.Sprintf("lease: %s", res.State.Lease))
+	}
+	if !s.ConfChange && len(s.Details) == 0 && cmd.WriteBatch != nil {
+		s.Details = append(s.Details, fmt.Sprintf("write batch: %s",
+			humanizeutil.IBytes(int64(len(cmd.WriteBatch.Data)))))
+	}
+	return s, nil
+}
+
+// SideloadedDir returns the directory holding the sideloaded payloads of
+// the given range, in a store whose auxiliary directory is auxDir.
+func SideloadedDir(auxDir string, rangeID roachpb.RangeID) string {
+	return sideloadedPath(auxDir, rangeID)
+}
+
+// SnapshotScratchDir returns the directory in which the incoming snapshots
+// for the given range are staged, in a store whose auxiliary directory is
+// auxDir.
+func SnapshotScratchDir(auxDir string, rangeID roachpb.RangeID) string {
+	return snapshotRangeDir(snapshotStorageDir(auxDir), rangeID)
+}
+
+// LegacySnapshotScratchDir is like SnapshotScratchDir, for the layout used
+// before the snapshot storage directory was sharded. Snapshots staged by
+// previous versions can still be found there.
+func LegacySnapshotScratchDir(auxDir string, rangeID roachpb.RangeID) string {
+	return legacySnapshotRangeDir(snapshotStorageDir(auxDir), rangeID)
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestSummarizeRaftLogEntry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	span := roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")}
+
+	// An empty entry, as appended by a new leader.
+	s, err := SummarizeRaftLogEntry(raftpb.Entry{Index: 10, Term: 2})
+	require.NoError(t, err)
+	require.True(t, s.Empty)
+	require.Equal(t, "index 10, term 2, EntryNormal: empty", s.String())
+
+	// A thin sideloaded entry.
+	ent := mkEnt(kvserverbase.RaftVersionSideloaded, 11, 2,
+		&kvserverpb.ReplicatedEvalResult_AddSSTable{CRC32: 0xdeadbeef, Span: span})
+	s, err = SummarizeRaftLogEntry(ent)
+	require.NoError(t, err)
+	require.True(t, s.AddSSTable)
+	require.True(t, s.Sideloaded)
+	require.False(t, s.ConfChange)
+	require.Len(t, s.Details, 1)
+	require.Contains(t, s.Details[0], "crc32 deadbeef, payload sideloaded")
+	require.Equal(t, "i11.t2", SideloadedFilename(s.Index, s.Term))
+
+	// An AddSSTable entry whose payload is inlined.
+	ent = mkEnt(kvserverbase.RaftVersionStandard, 12, 3,
+		&kvserverpb.ReplicatedEvalResult_AddSSTable{CRC32: 0xdeadbeef, Span: span, Data: []byte("foo")})
+	s, err = SummarizeRaftLogEntry(ent)
+	require.NoError(t, err)
+	require.True(t, s.AddSSTable)
+	require.False(t, s.Sideloaded)
+	require.Len(t, s.Details, 1)
+	require.Contains(t, s.Details[0], "crc32 deadbeef, 3 B inlined")
+}
@@ -197,6 +197,37 @@ func (s *Store) logChange(
 	})
 }
 
+// logSnapshotScratchEvent logs an event of the lifecycle of the scratch
+// staging an incoming snapshot of the given range into the event table, if
+// enabled. The event is logged asynchronously, in its own transaction, so as
+// not to hold up the snapshot.
+func (s *Store) logSnapshotScratchEvent(
+	eventType kvserverpb.RangeLogEventType,
+	rangeID roachpb.RangeID,
+	info kvserverpb.SnapshotScratchInfo,
+	details string,
+) {
+	if !snapshotScratchLogEvents.Get(&s.ClusterSettings().SV) {
+		return
+	}
+	event := kvserverpb.RangeLogEvent{
+		Timestamp: s.Clock().PhysicalTime(),
+		RangeID:   rangeID,
+		EventType: eventType,
+		StoreID:   s.StoreID(),
+		Info: &kvserverpb.RangeLogEvent_Info{
+			SnapshotScratch: &info,
+			Details:         details,
+		},
+	}
+	_ = s.stopper.RunAsyncTask(s.AnnotateCtx(context.Background()), "log-snapshot-scratch-event",
+		func(ctx context.Context) {
+			if err := s.insertRangeLogEvent(ctx, nil /* txn */, event); err != nil {
+				log.Warningf(ctx, "unable to log %s event of r%d: %v", eventType, rangeID, err)
+			}
+		})
+}
+
 // selectEventTimestamp selects a timestamp for this log message. If the
 // transaction this event is being written in has a non-zero timestamp, then that
 // timestamp should be used; otherwise, the store's physical clock is used.
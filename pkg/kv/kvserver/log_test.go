@@ -25,12 +25,16 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
 	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLogSplits(t *testing.T) {
@@ -397,3 +401,56 @@ func TestLogRebalances(t *testing.T) {
 		t.Errorf("expected %d RemoveReplica events logged, found %d", e, a)
 	}
 }
+
+func TestLogSnapshotScratchEvents(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 2, base.TestClusterArgs{
+		ReplicationMode: base.ReplicationManual,
+	})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.snapshot_receiver.scratch_events.enabled = true`)
+
+	key := tc.ScratchRange(t)
+	desc := tc.AddVotersOrFatal(t, key, tc.Target(1))
+
+	// The events are logged asynchronously, by the store receiving the
+	// snapshot.
+	infos := map[string]kvserverpb.SnapshotScratchInfo{}
+	testutils.SucceedsSoon(t, func() error {
+		rows := sqlDB.Query(t,
+			`SELECT "eventType", info FROM system.rangelog WHERE "rangeID" = $1 AND "storeID" = $2`,
+			desc.RangeID, tc.Target(1).StoreID)
+		defer rows.Close()
+		for rows.Next() {
+			var eventType, infoStr string
+			require.NoError(t, rows.Scan(&eventType, &infoStr))
+			var info kvserverpb.RangeLogEvent_Info
+			require.NoError(t, json.Unmarshal([]byte(infoStr), &info))
+			require.NotNil(t, info.SnapshotScratch, "%s", eventType)
+			infos[eventType] = *info.SnapshotScratch
+		}
+		require.NoError(t, rows.Err())
+		for _, eventType := range []kvserverpb.RangeLogEventType{
+			kvserverpb.RangeLogEventType_snapshot_scratch_created,
+			kvserverpb.RangeLogEventType_snapshot_scratch_completed,
+		} {
+			if _, ok := infos[eventType.String()]; !ok {
+				return errors.Errorf("no %s event logged", eventType)
+			}
+		}
+		return nil
+	})
+	require.NotContains(t, infos, kvserverpb.RangeLogEventType_snapshot_scratch_aborted.String())
+
+	created := infos[kvserverpb.RangeLogEventType_snapshot_scratch_created.String()]
+	completed := infos[kvserverpb.RangeLogEventType_snapshot_scratch_completed.String()]
+	require.Equal(t, created.SnapUUID, completed.SnapUUID)
+	require.Equal(t, tc.Target(0).StoreID, completed.SenderStoreID)
+	require.Zero(t, created.BytesWritten)
+	require.Positive(t, completed.BytesWritten)
+	require.GreaterOrEqual(t, completed.Duration, created.Duration)
+}
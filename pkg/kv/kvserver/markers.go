@@ -24,6 +24,57 @@ func isSnapshotError(err error) bool {
 	return errors.Is(err, errMarkSnapshotError)
 }
 
+// NB: don't change the string here; this will cause cross-version issues
+// since this singleton is used as a marker.
+var errMarkSnapshotReceiverOutOfDisk = errors.New("snapshot receiver out of disk")
+
+// isSnapshotReceiverOutOfDiskError returns true iff the error indicates that
+// the recipient of a snapshot ran out of disk while receiving it.
+func isSnapshotReceiverOutOfDiskError(err error) bool {
+	return errors.Is(err, errMarkSnapshotReceiverOutOfDisk)
+}
+
+// NB: don't change the string here; this will cause cross-version issues
+// since this singleton is used as a marker.
+var errMarkSnapshotReceiverBusy = errors.New("snapshot receiver busy")
+
+// isSnapshotReceiverBusyError returns true iff the error indicates that the
+// recipient of a snapshot had too many snapshots queued to accept it.
+func isSnapshotReceiverBusyError(err error) bool {
+	return errors.Is(err, errMarkSnapshotReceiverBusy)
+}
+
+// NB: don't change the string here; this will cause cross-version issues
+// since this singleton is used as a marker.
+var errMarkSnapshotScratchTooManyFiles = errors.New("too many snapshot scratch files")
+
+// isSnapshotScratchTooManyFilesError returns true iff the error indicates
+// that a snapshot scratch reached its maximum number of files.
+func isSnapshotScratchTooManyFilesError(err error) bool {
+	return errors.Is(err, errMarkSnapshotScratchTooManyFiles)
+}
+
+// NB: don't change the string here; this will cause cross-version issues
+// since this singleton is used as a marker.
+var errMarkSnapshotScratchSizeExceeded = errors.New("snapshot scratch size exceeded")
+
+// isSnapshotScratchSizeExceededError returns true iff the error indicates
+// that more was written to a snapshot scratch than its expected size allows.
+func isSnapshotScratchSizeExceededError(err error) bool {
+	return errors.Is(err, errMarkSnapshotScratchSizeExceeded)
+}
+
+// NB: don't change the string here; this will cause cross-version issues
+// since this singleton is used as a marker.
+var errMarkSnapshotChecksumMismatch = errors.New("snapshot checksum mismatch")
+
+// isSnapshotChecksumMismatchError returns true iff the error indicates that
+// the batches of a received snapshot do not match the checksum computed by
+// its sender.
+func isSnapshotChecksumMismatchError(err error) bool {
+	return errors.Is(err, errMarkSnapshotChecksumMismatch)
+}
+
 // NB: don't change the string here; this will cause cross-version issues
 // since this singleton is used as a marker.
 var errMarkCanRetryReplicationChangeWithUpdatedDesc = errors.New("should retry with updated descriptor")
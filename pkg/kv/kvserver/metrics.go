@@ -639,6 +639,54 @@ var (
 		Measurement: "Bytes",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRangeSnapshotChecksumMismatches = metric.Metadata{
+		Name:        "range.snapshots.checksum-mismatches",
+		Help:        "Number of received snapshots rejected because their data did not match the checksum computed by the sender",
+		Measurement: "Snapshots",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRangeSnapshotsAppliedAsBatch = metric.Metadata{
+		Name:        "range.snapshots.applied-as-batch",
+		Help:        "Number of snapshots small enough to be applied through a regular write rather than ingested",
+		Measurement: "Snapshots",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRangeSnapshotIngestLogicalBytes = metric.Metadata{
+		Name:        "range.snapshots.ingest.logical-bytes",
+		Help:        "Number of bytes of key-values of the applied snapshots",
+		Measurement: "Bytes",
+		Unit:        metric.Unit_BYTES,
+	}
+	metaRangeSnapshotIngestPhysicalBytes = metric.Metadata{
+		Name:        "range.snapshots.ingest.physical-bytes",
+		Help:        "Number of bytes written to the disk to apply snapshots: scratch writes, batch writes and estimated compaction rewrites",
+		Measurement: "Bytes",
+		Unit:        metric.Unit_BYTES,
+	}
+	metaRangeSnapshotIngestCompactionBytes = metric.Metadata{
+		Name:        "range.snapshots.ingest.compaction-bytes",
+		Help:        "Estimated number of bytes rewritten by compactions because of the applied snapshots, for the data which landed in L0",
+		Measurement: "Bytes",
+		Unit:        metric.Unit_BYTES,
+	}
+	metaRangeSnapshotIngestWriteAmplification = metric.Metadata{
+		Name:        "range.snapshots.ingest.write-amplification",
+		Help:        "Ratio of the physical bytes written to apply snapshots to their logical bytes, since the store started",
+		Measurement: "Ratio",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRangeSnapshotsAppliedVerified = metric.Metadata{
+		Name:        "range.snapshots.applied-verified",
+		Help:        "Number of applied snapshots whose data was verified against the checksum computed by their sender",
+		Measurement: "Snapshots",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRangeSnapshotsAppliedVerificationFailures = metric.Metadata{
+		Name:        "range.snapshots.applied-verification-failures",
+		Help:        "Number of applied snapshots whose data did not match the checksum computed by their sender",
+		Measurement: "Snapshots",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaRangeSnapshotUnknownRcvdBytes = metric.Metadata{
 		Name:        "range.snapshots.unknown.rcvd-bytes",
 		Help:        "Number of unknown snapshot bytes received",
@@ -711,6 +759,72 @@ var (
 		Measurement: "Snapshots",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaSnapshotScratchBytesWritten = metric.Metadata{
+		Name:        "range.snapshots.scratch.bytes-written",
+		Help:        "Number of bytes written to the scratch files of incoming snapshots",
+		Measurement: "Bytes",
+		Unit:        metric.Unit_BYTES,
+	}
+	metaSnapshotScratchFilesCreated = metric.Metadata{
+		Name:        "range.snapshots.scratch.files-created",
+		Help:        "Number of scratch files created for incoming snapshots",
+		Measurement: "Files",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaSnapshotScratchSyncs = metric.Metadata{
+		Name:        "range.snapshots.scratch.syncs",
+		Help:        "Number of syncs of the scratch files of incoming snapshots",
+		Measurement: "Syncs",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaSnapshotScratchWriteLatency = metric.Metadata{
+		Name:        "range.snapshots.scratch.write-latency",
+		Help:        "Latency of the writes to the scratch files of incoming snapshots, excluding the wait for the write budget",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaSnapshotScratchBulkIOWriteWait = metric.Metadata{
+		Name:        "range.snapshots.scratch.bulk-io-write-wait",
+		Help:        "Cumulative time the writes to the scratch files of incoming snapshots waited for the bulk IO write budget of the store",
+		Measurement: "Wait Time",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaSnapshotScratchProbeFailures = metric.Metadata{
+		Name:        "range.snapshots.scratch.probe-failures",
+		Help:        "Number of failed probes of the directory holding the scratch files of incoming snapshots",
+		Measurement: "Probes",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaSnapshotScratchSyncLatency = metric.Metadata{
+		Name:        "range.snapshots.scratch.sync-latency",
+		Help:        "Latency of the syncs of the scratch files of incoming snapshots",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaSnapshotScratchCreateLatency = metric.Metadata{
+		Name:        "range.snapshots.scratch.create-latency",
+		Help:        "Latency of the creation of the scratch files of incoming snapshots",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaSnapshotScratchActive = metric.Metadata{
+		Name:        "range.snapshots.scratch.active",
+		Help:        "Number of open scratches staging the SSTs of incoming snapshots",
+		Measurement: "Scratches",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaSnapshotScratchUsedBytes = metric.Metadata{
+		Name:        "range.snapshots.scratch.used-bytes",
+		Help:        "Disk space used by the open scratches staging the SSTs of incoming snapshots",
+		Measurement: "Bytes",
+		Unit:        metric.Unit_BYTES,
+	}
+	metaSnapshotScratchTenantUsedBytes = metric.Metadata{
+		Name:        "range.snapshots.scratch.tenant-used-bytes",
+		Help:        "Disk space used by the open scratches staging the SSTs of incoming snapshots, by tenant owning the range",
+		Measurement: "Bytes",
+		Unit:        metric.Unit_BYTES,
+	}
 	metaRangeRaftLeaderTransfers = metric.Metadata{
 		Name:        "range.raftleadertransfers",
 		Help:        "Number of raft leader transfers",
@@ -1733,6 +1847,14 @@ type StoreMetrics struct {
 	RangeSnapshotRecoverySentBytes               *metric.Counter
 	RangeSnapshotRebalancingRcvdBytes            *metric.Counter
 	RangeSnapshotRebalancingSentBytes            *metric.Counter
+	RangeSnapshotChecksumMismatches              *metric.Counter
+	RangeSnapshotsAppliedAsBatch                 *metric.Counter
+	RangeSnapshotsAppliedVerified                *metric.Counter
+	RangeSnapshotIngestLogicalBytes              *metric.Counter
+	RangeSnapshotIngestPhysicalBytes             *metric.Counter
+	RangeSnapshotIngestCompactionBytes           *metric.Counter
+	RangeSnapshotIngestWriteAmplification        *metric.GaugeFloat64
+	RangeSnapshotsAppliedVerificationFailures    *metric.Counter
 
 	// Range snapshot queue metrics.
 	RangeSnapshotSendQueueLength     *metric.Gauge
@@ -1742,6 +1864,18 @@ type StoreMetrics struct {
 	RangeSnapshotSendTotalInProgress *metric.Gauge
 	RangeSnapshotRecvTotalInProgress *metric.Gauge
 
+	// Snapshot scratch metrics.
+	SnapshotScratchBytesWritten    *metric.Counter
+	SnapshotScratchFilesCreated    *metric.Counter
+	SnapshotScratchSyncs           *metric.Counter
+	SnapshotScratchWriteLatency    *metric.Histogram
+	SnapshotScratchBulkIOWriteWait *metric.Counter
+	SnapshotScratchSyncLatency     *metric.Histogram
+	SnapshotScratchCreateLatency   *metric.Histogram
+	SnapshotScratchActive          *metric.Gauge
+	SnapshotScratchUsedBytes       *metric.Gauge
+	SnapshotScratchProbeFailures   *metric.Counter
+
 	// Raft processing metrics.
 	RaftTicks                 *metric.Counter
 	RaftQuotaPoolPercentUsed  *metric.Histogram
@@ -1933,6 +2067,9 @@ type TenantsStorageMetrics struct {
 	SysBytes       *aggmetric.AggGauge
 	SysCount       *aggmetric.AggGauge
 	AbortSpanBytes *aggmetric.AggGauge
+	// SnapshotScratchBytes is the disk space used by the snapshot scratches of
+	// the ranges of each tenant.
+	SnapshotScratchBytes *aggmetric.AggGauge
 
 	// This struct is invisible to the metric package.
 	//
@@ -2006,6 +2143,7 @@ func (sm *TenantsStorageMetrics) acquireTenant(tenantID roachpb.TenantID) *tenan
 			m.SysBytes = sm.SysBytes.AddChild(tenantIDStr)
 			m.SysCount = sm.SysCount.AddChild(tenantIDStr)
 			m.AbortSpanBytes = sm.AbortSpanBytes.AddChild(tenantIDStr)
+			m.SnapshotScratchBytes = sm.SnapshotScratchBytes.AddChild(tenantIDStr)
 			m.mu.Unlock()
 			return &tenantMetricsRef{
 				_tenantID: tenantID,
@@ -2056,6 +2194,7 @@ func (sm *TenantsStorageMetrics) releaseTenant(ctx context.Context, ref *tenantM
 	m.SysBytes.Destroy()
 	m.SysCount.Destroy()
 	m.AbortSpanBytes.Destroy()
+	m.SnapshotScratchBytes.Destroy()
 	sm.tenants.Delete(int64(ref._tenantID.ToUint64()))
 }
 
@@ -2097,6 +2236,8 @@ type tenantStorageMetrics struct {
 	SysBytes       *aggmetric.Gauge
 	SysCount       *aggmetric.Gauge
 	AbortSpanBytes *aggmetric.Gauge
+
+	SnapshotScratchBytes *aggmetric.Gauge
 }
 
 func newTenantsStorageMetrics() *TenantsStorageMetrics {
@@ -2120,6 +2261,8 @@ func newTenantsStorageMetrics() *TenantsStorageMetrics {
 		SysBytes:       b.Gauge(metaSysBytes),
 		SysCount:       b.Gauge(metaSysCount),
 		AbortSpanBytes: b.Gauge(metaAbortSpanBytes),
+
+		SnapshotScratchBytes: b.Gauge(metaSnapshotScratchTenantUsedBytes),
 	}
 	return sm
 }
@@ -2233,15 +2376,41 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		RangeSnapshotRecoverySentBytes:               metric.NewCounter(metaRangeSnapshotRecoverySentBytes),
 		RangeSnapshotRebalancingRcvdBytes:            metric.NewCounter(metaRangeSnapshotRebalancingRcvdBytes),
 		RangeSnapshotRebalancingSentBytes:            metric.NewCounter(metaRangeSnapshotRebalancingSentBytes),
+		RangeSnapshotChecksumMismatches:              metric.NewCounter(metaRangeSnapshotChecksumMismatches),
+		RangeSnapshotsAppliedAsBatch:                 metric.NewCounter(metaRangeSnapshotsAppliedAsBatch),
+		RangeSnapshotsAppliedVerified:                metric.NewCounter(metaRangeSnapshotsAppliedVerified),
+		RangeSnapshotIngestLogicalBytes:              metric.NewCounter(metaRangeSnapshotIngestLogicalBytes),
+		RangeSnapshotIngestPhysicalBytes:             metric.NewCounter(metaRangeSnapshotIngestPhysicalBytes),
+		RangeSnapshotIngestCompactionBytes:           metric.NewCounter(metaRangeSnapshotIngestCompactionBytes),
+		RangeSnapshotIngestWriteAmplification:        metric.NewGaugeFloat64(metaRangeSnapshotIngestWriteAmplification),
+		RangeSnapshotsAppliedVerificationFailures:    metric.NewCounter(metaRangeSnapshotsAppliedVerificationFailures),
 		RangeSnapshotSendQueueLength:                 metric.NewGauge(metaRangeSnapshotSendQueueLength),
 		RangeSnapshotRecvQueueLength:                 metric.NewGauge(metaRangeSnapshotRecvQueueLength),
 		RangeSnapshotSendInProgress:                  metric.NewGauge(metaRangeSnapshotSendInProgress),
 		RangeSnapshotRecvInProgress:                  metric.NewGauge(metaRangeSnapshotRecvInProgress),
 		RangeSnapshotSendTotalInProgress:             metric.NewGauge(metaRangeSnapshotSendTotalInProgress),
 		RangeSnapshotRecvTotalInProgress:             metric.NewGauge(metaRangeSnapshotRecvTotalInProgress),
+		SnapshotScratchBytesWritten:                  metric.NewCounter(metaSnapshotScratchBytesWritten),
+		SnapshotScratchFilesCreated:                  metric.NewCounter(metaSnapshotScratchFilesCreated),
+		SnapshotScratchSyncs:                         metric.NewCounter(metaSnapshotScratchSyncs),
+		SnapshotScratchActive:                        metric.NewGauge(metaSnapshotScratchActive),
+		SnapshotScratchUsedBytes:                     metric.NewGauge(metaSnapshotScratchUsedBytes),
+		SnapshotScratchProbeFailures:                 metric.NewCounter(metaSnapshotScratchProbeFailures),
 		RangeRaftLeaderTransfers:                     metric.NewCounter(metaRangeRaftLeaderTransfers),
 		RangeLossOfQuorumRecoveries:                  metric.NewCounter(metaRangeLossOfQuorumRecoveries),
 
+		// Snapshot scratch latency metrics.
+		SnapshotScratchWriteLatency: metric.NewHistogram(
+			metaSnapshotScratchWriteLatency, histogramWindow, metric.IOLatencyBuckets,
+		),
+		SnapshotScratchBulkIOWriteWait: metric.NewCounter(metaSnapshotScratchBulkIOWriteWait),
+		SnapshotScratchSyncLatency: metric.NewHistogram(
+			metaSnapshotScratchSyncLatency, histogramWindow, metric.IOLatencyBuckets,
+		),
+		SnapshotScratchCreateLatency: metric.NewHistogram(
+			metaSnapshotScratchCreateLatency, histogramWindow, metric.IOLatencyBuckets,
+		),
+
 		// Raft processing metrics.
 		RaftTicks: metric.NewCounter(metaRaftTicks),
 		RaftQuotaPoolPercentUsed: metric.NewHistogram(
@@ -25,6 +25,7 @@ import (
 // MultiQueue.Cancel can be called to release the permit without waiting for the
 // permit.
 type Task struct {
+	class     int
 	priority  float64
 	queueType int
 	heapIdx   int
@@ -38,7 +39,17 @@ func (t *Task) GetWaitChan() <-chan *Permit {
 }
 
 func (t *Task) String() string {
-	return redact.Sprintf("{Queue type : %d, Priority :%f}", t.queueType, t.priority).StripMarkers()
+	return redact.Sprintf("{Queue type : %d, Class : %d, Priority :%f}",
+		t.queueType, t.class, t.priority).StripMarkers()
+}
+
+// outranks returns true if the task runs before the other one when they are
+// in the same queue.
+func (t *Task) outranks(o *Task) bool {
+	if t.class != o.class {
+		return t.class > o.class
+	}
+	return t.priority > o.priority
 }
 
 // notifyHeap is a standard go heap over tasks.
@@ -49,7 +60,7 @@ func (h notifyHeap) Len() int {
 }
 
 func (h notifyHeap) Less(i, j int) bool {
-	return h[j].priority < h[i].priority
+	return h[i].outranks(h[j])
 }
 
 func (h notifyHeap) Swap(i, j int) {
@@ -88,7 +99,9 @@ func (h *notifyHeap) tryRemove(task *Task) bool {
 }
 
 // MultiQueue is a type that round-robins through a set of typed queues, each
-// independently prioritized. A MultiQueue is constructed with a concurrencySem
+// independently prioritized. Tasks can additionally be given a class: the
+// tasks of the highest class waiting in any queue run first, round-robin
+// through the queues holding them. A MultiQueue is constructed with a concurrencySem
 // which is the number of concurrent jobs this queue will allow to run. Tasks
 // are added to the queue using MultiQueue.Add. That will return a channel that
 // should be received from. It will be notified when the waiting job is ready to
@@ -128,11 +141,19 @@ func (m *MultiQueue) tryRunNextLocked() {
 		return
 	}
 
+	// Only the queues whose next task has the highest class are considered.
+	var class int
+	var found bool
+	for _, q := range m.outstanding {
+		if q.Len() > 0 && (!found || q[0].class > class) {
+			class, found = q[0].class, true
+		}
+	}
 	for i := 0; i < len(m.outstanding); i++ {
 		// Start with the next queue in order and iterate through all empty queues.
 		// If all queues are empty then return, as there is nothing to run.
 		index := (m.lastQueueIndex + i + 1) % len(m.outstanding)
-		if m.outstanding[index].Len() > 0 {
+		if m.outstanding[index].Len() > 0 && m.outstanding[index][0].class == class {
 			task := heap.Pop(&m.outstanding[index]).(*Task)
 			task.permitC <- &Permit{valid: true}
 			m.remainingRuns--
@@ -146,6 +167,13 @@ func (m *MultiQueue) tryRunNextLocked() {
 // release the Permit. The number of types is expected to
 // be relatively small and not be changing over time.
 func (m *MultiQueue) Add(queueType int, priority float64) *Task {
+	return m.AddWithClass(queueType, 0 /* class */, priority)
+}
+
+// AddWithClass is like Add, for a task of the given class. Tasks of a higher
+// class run before all the tasks of a lower class, whatever their queue and
+// priority.
+func (m *MultiQueue) AddWithClass(queueType int, class int, priority float64) *Task {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -159,6 +187,7 @@ func (m *MultiQueue) Add(queueType int, priority float64) *Task {
 		m.outstanding = append(m.outstanding, notifyHeap{})
 	}
 	newTask := Task{
+		class:     class,
 		priority:  priority,
 		permitC:   make(chan *Permit, 1),
 		heapIdx:   -1,
@@ -229,3 +258,59 @@ func (m *MultiQueue) Len() int {
 	defer m.mu.Unlock()
 	return m.remainingRuns
 }
+
+// Waiting returns the number of tasks waiting for a permit whose class is at
+// least the given one.
+func (m *MultiQueue) Waiting(class int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int
+	for _, q := range m.outstanding {
+		for _, t := range q {
+			if t.class >= class {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Position returns the number of waiting tasks which get a permit before the
+// given one, unless tasks of a higher class are added in the meantime, or -1
+// if the task is not waiting. Tasks of the same class in other queues are
+// interleaved with those of the task's queue, round-robin.
+func (m *MultiQueue) Position(task *Task) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if task.heapIdx < 0 {
+		return -1
+	}
+	own := m.mapping[task.queueType]
+	// The number of tasks of the same class which run before the task in its
+	// own queue.
+	var rank int
+	for _, t := range m.outstanding[own] {
+		if t != task && t.class == task.class && !task.outranks(t) {
+			rank++
+		}
+	}
+	var pos int
+	for i, q := range m.outstanding {
+		var sameClass int
+		for _, t := range q {
+			if t.class > task.class {
+				pos++
+			} else if t.class == task.class && t != task {
+				sameClass++
+			}
+		}
+		if i == own {
+			pos += rank
+		} else if sameClass > rank+1 {
+			pos += rank + 1
+		} else {
+			pos += sameClass
+		}
+	}
+	return pos
+}
@@ -94,6 +94,34 @@ func TestMultiQueueComplex(t *testing.T) {
 	verifyOrder(t, queue, a3, b3, c3, a2, b2, c2, b1)
 }
 
+// TestMultiQueueClasses checks that the tasks of a higher class run before
+// those of a lower class, whatever their queue and priority, and that the
+// waiting tasks and their positions are reported.
+func TestMultiQueueClasses(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	queue := NewMultiQueue(1)
+	blocker := queue.Add(0, 0)
+
+	a1 := queue.Add(1, 5.0)
+	b1 := queue.AddWithClass(2, 1, 1.0)
+	a2 := queue.AddWithClass(1, 1, 0.5)
+	c1 := queue.AddWithClass(3, 2, 0.0)
+
+	require.Equal(t, 4, queue.Waiting(0))
+	require.Equal(t, 3, queue.Waiting(1))
+	require.Equal(t, 1, queue.Waiting(2))
+	require.Equal(t, -1, queue.Position(blocker))
+	require.Equal(t, 0, queue.Position(c1))
+	require.Equal(t, 2, queue.Position(b1))
+	require.Equal(t, 3, queue.Position(a1))
+
+	permit := <-blocker.GetWaitChan()
+	queue.Release(permit)
+	verifyOrder(t, queue, c1, a2, b1, a1)
+}
+
 func TestMultiQueueRemove(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
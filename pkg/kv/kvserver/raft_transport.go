@@ -155,6 +155,8 @@ type RaftTransport struct {
 	tracer  *tracing.Tracer
 	stopper *stop.Stopper
 	metrics *RaftTransportMetrics
+	// snapshotLimiter paces the snapshots sent by the stores of the node.
+	snapshotLimiter *snapshotSendLimiter
 
 	queues   [rpc.NumConnectionClasses]syncutil.IntMap // map[roachpb.NodeID]*raftSendQueue
 	dialer   *nodedialer.Dialer
@@ -196,6 +198,7 @@ func NewRaftTransport(
 		stopper:        stopper,
 		dialer:         dialer,
 	}
+	t.snapshotLimiter = newSnapshotSendLimiter(st)
 	t.initMetrics()
 	if grpcServer != nil {
 		RegisterMultiRaftServer(grpcServer, t)
@@ -641,7 +644,9 @@ func (t *RaftTransport) SendSnapshot(
 			log.Warningf(ctx, "failed to close snapshot stream: %+v", err)
 		}
 	}()
-	return sendSnapshot(ctx, t.st, t.tracer, stream, storePool, header, snap, newBatch, sent, recordBytesSent)
+	return sendSnapshot(
+		ctx, t.st, t.tracer, stream, t.snapshotLimiter, storePool, header, snap, newBatch, sent, recordBytesSent,
+	)
 }
 
 // DelegateSnapshot creates a rpc stream between the leaseholder and the
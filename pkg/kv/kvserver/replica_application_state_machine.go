@@ -681,7 +681,8 @@ func (b *replicaAppBatch) runPreApplyTriggersAfterStagingWriteBatch(
 			cmd.ent.Term,
 			cmd.ent.Index,
 			*res.AddSSTable,
-			b.r.store.limiters.BulkIOWriteRate,
+			&b.r.store.sstSnapshotStorage,
+			b.r.RangeID,
 		)
 		b.r.store.metrics.AddSSTableApplications.Inc(1)
 		if copied {
@@ -968,6 +968,20 @@ func (r *Replica) ChangeReplicas(
 	reason kvserverpb.RangeLogEventReason,
 	details string,
 	chgs roachpb.ReplicationChanges,
+) (updatedDesc *roachpb.RangeDescriptor, _ error) {
+	return r.changeReplicasWithSenderPriority(ctx, desc, priority, 0.0, reason, details, chgs)
+}
+
+// changeReplicasWithSenderPriority is like ChangeReplicas, but also takes the
+// priority of the snapshots in the queue of the sending store.
+func (r *Replica) changeReplicasWithSenderPriority(
+	ctx context.Context,
+	desc *roachpb.RangeDescriptor,
+	priority kvserverpb.SnapshotRequest_Priority,
+	senderQueuePriority float64,
+	reason kvserverpb.RangeLogEventReason,
+	details string,
+	chgs roachpb.ReplicationChanges,
 ) (updatedDesc *roachpb.RangeDescriptor, _ error) {
 	if desc == nil {
 		// TODO(tbg): is this check just FUD?
@@ -988,7 +1002,23 @@ func (r *Replica) ChangeReplicas(
 			return nil, errors.New("must disable replicate queue to use ChangeReplicas manually")
 		}
 	}
-	return r.changeReplicasImpl(ctx, desc, priority, kvserverpb.SnapshotRequest_OTHER, 0.0, reason, details, chgs)
+	return r.changeReplicasImpl(
+		ctx, desc, priority, kvserverpb.SnapshotRequest_OTHER, senderQueuePriority, reason, details, chgs,
+	)
+}
+
+// adminSnapshotPriority translates the snapshot priority hint of an
+// AdminChangeReplicas request into the priority of the snapshots and their
+// priority in the queue of the sending store. Without a hint, the snapshots
+// are sent as rebalancing snapshots, without any particular priority. With
+// the maximum priority hint, they are sent at the recovery rate.
+func adminSnapshotPriority(
+	hint roachpb.UserPriority,
+) (kvserverpb.SnapshotRequest_Priority, float64) {
+	if hint >= roachpb.MaxUserPriority {
+		return kvserverpb.SnapshotRequest_RECOVERY, float64(hint)
+	}
+	return kvserverpb.SnapshotRequest_REBALANCE, float64(hint)
 }
 
 func (r *Replica) changeReplicasImpl(
@@ -2651,9 +2681,12 @@ func (r *Replica) sendSnapshot(
 		// paused implies that we have recently checked that it's not required for quorum, and that
 		// we wish to conserve I/O on that store, which sending a snapshot counteracts. So hold back on
 		// the snapshot as well.
-		return errors.Errorf(
-			"skipping snapshot; %s is overloaded: %s",
-			recipient, r.store.ioThresholds.Current().IOThreshold(recipient.StoreID),
+		return errors.WithHint(
+			errors.Errorf(
+				"skipping snapshot; %s is overloaded: %s",
+				recipient, r.store.ioThresholds.Current().IOThreshold(recipient.StoreID),
+			),
+			"retry once the recipient store is no longer overloaded, or pick another target",
 		)
 	}
 
@@ -2707,7 +2740,11 @@ func (r *Replica) sendSnapshot(
 	)
 
 	if err != nil {
-		return errors.Mark(err, errMarkSnapshotError)
+		return errors.WithHint(
+			errors.Mark(err, errMarkSnapshotError),
+			"the snapshot can be retried; crdb_internal.kv_store_snapshots "+
+				"shows the recent snapshots of the stores and their errors",
+		)
 	}
 	return nil
 }
@@ -2724,6 +2761,16 @@ var followerSnapshotsEnabled = func() *settings.BoolSetting {
 	return s
 }()
 
+// followerSnapshotStagingEnabled is used to stage the snapshots sent by
+// follower replicas in their snapshot storage before sending them.
+var followerSnapshotStagingEnabled = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.snapshot_delegation.staging.enabled",
+	"set to true to write the snapshots sent by follower replicas to local scratch "+
+		"storage before sending them, so that reading them is not paced by the network",
+	false,
+)
+
 // traceSnapshotThreshold is used to enable or disable snapshot tracing.
 var traceSnapshotThreshold = settings.RegisterDurationSetting(
 	settings.SystemOnly,
@@ -2791,6 +2838,10 @@ func (r *Replica) followerSendSnapshot(
 	defer snap.Close()
 	log.Event(ctx, "generated snapshot")
 
+	tracker := r.store.snapshotHistory.start(snap.SnapUUID, r.RangeID, SnapshotSent,
+		recipient.StoreID, req.Type, req.Priority, rangeSize)
+	defer func() { tracker.finish(retErr) }()
+
 	// Check that the snapshot we generated has a descriptor that includes the
 	// recipient. If it doesn't, the recipient will reject it, so it's better to
 	// not send it in the first place. It's possible to hit this case if we're not
@@ -2845,11 +2896,27 @@ func (r *Replica) followerSendSnapshot(
 	newBatchFn := func() storage.Batch {
 		return r.store.Engine().NewUnindexedBatch(true /* writeOnly */)
 	}
+	if followerSnapshotStagingEnabled.Get(&r.ClusterSettings().SV) {
+		scratch, err := r.store.sstSnapshotStorage.NewScratchSpace(ctx, r.RangeID, snap.SnapUUID)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := scratch.Close(); err != nil {
+				log.Warningf(ctx, "error closing snapshot staging scratch: %v", err)
+			}
+		}()
+		batchSize := snapshotSenderBatchSize.Get(&r.ClusterSettings().SV)
+		if snap.staged, err = stageOutgoingSnapshot(ctx, snap, scratch, newBatchFn, batchSize); err != nil {
+			return errors.Wrapf(err, "%s: failed to stage %s snapshot", r, snapType)
+		}
+	}
 	sent := func() {
 		r.store.metrics.RangeSnapshotsGenerated.Inc(1)
 	}
 
 	recordBytesSent := func(inc int64) {
+		tracker.recordBytes(inc)
 		r.store.metrics.RangeSnapshotSentBytes.Inc(inc)
 
 		switch header.Priority {
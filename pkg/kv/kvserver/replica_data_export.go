@@ -0,0 +1,94 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rditer"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// ExportReplicaData writes the replicated data of the given range, as found in
+// the given engine, to standalone SSTs below dir in the given filesystem. The
+// engine's own filesystem is not used, as the engine may be opened read-only
+// and dir need not be in the store directory. The SSTs are laid out and
+// formatted like the ones staged for an incoming snapshot: one SST per
+// replicated key span, each starting with a range deletion covering the span,
+// in <dir>/<shard>/<range id>/<uuid>/<n>.sst. They can thus be inspected offline, or
+// ingested into a store to replace the range's data.
+//
+// The paths of the SSTs and the total size of the exported data are returned.
+func ExportReplicaData(
+	ctx context.Context,
+	st *cluster.Settings,
+	eng storage.Engine,
+	desc *roachpb.RangeDescriptor,
+	outFS fs.FS,
+	dir string,
+) ([]string, int64, error) {
+	sss := NewSSTSnapshotStorageInDir(outFS, dir, unlimitedBulkIOWriteLimiter())
+	// NB: the scratch space is not closed, as this would remove the SSTs.
+	scratch, err := sss.NewScratchSpace(ctx, desc.RangeID, uuid.MakeV7())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	msstw, err := newMultiSSTWriter(ctx, st, scratch, rditer.MakeReplicatedKeySpans(desc),
+		snapshotSSTWriteSyncRate.Get(&st.SV))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer msstw.Close()
+
+	snap := eng.NewSnapshot()
+	defer snap.Close()
+	if err := rditer.IterateReplicaKeySpans(desc, snap, true, /* replicatedOnly */
+		func(iter storage.EngineIterator, _ roachpb.Span, keyType storage.IterKeyType) error {
+			var err error
+			for ok := true; ok && err == nil; ok, err = iter.NextEngineKey() {
+				switch keyType {
+				case storage.IterKeyTypePointsOnly:
+					key, err := iter.UnsafeEngineKey()
+					if err != nil {
+						return err
+					}
+					if err := msstw.Put(ctx, key, iter.UnsafeValue()); err != nil {
+						return err
+					}
+
+				case storage.IterKeyTypeRangesOnly:
+					bounds, err := iter.EngineRangeBounds()
+					if err != nil {
+						return err
+					}
+					for _, rkv := range iter.EngineRangeKeys() {
+						if err := msstw.PutRangeKey(ctx, bounds.Key, bounds.EndKey, rkv.Version, rkv.Value); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			return err
+		}); err != nil {
+		return nil, 0, err
+	}
+
+	dataSize, err := msstw.Finish(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return scratch.SSTs(), dataSize, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rditer"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportReplicaData(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	desc := roachpb.RangeDescriptor{
+		RangeID:  5,
+		StartKey: roachpb.RKey("d"),
+		EndKey:   roachpb.RKey("g"),
+	}
+	for _, k := range []string{"c", "d", "e", "f", "g"} {
+		require.NoError(t, storage.MVCCPut(ctx, eng, nil /* ms */, roachpb.Key(k),
+			hlc.Timestamp{WallTime: 1}, hlc.ClockTimestamp{}, roachpb.MakeValueFromString(k), nil /* txn */))
+	}
+
+	dir, dirCleanup := testutils.TempDir(t)
+	defer dirCleanup()
+	ssts, dataSize, err := ExportReplicaData(
+		ctx, cluster.MakeTestingClusterSettings(), eng, &desc, fs.WrapVFS(vfs.Default), dir)
+	require.NoError(t, err)
+	require.Positive(t, dataSize)
+
+	// There is one SST per replicated key span, in the snapshot scratch layout.
+	require.Len(t, ssts, len(rditer.MakeReplicatedKeySpans(&desc)))
+	for i, sst := range ssts {
+		require.Equal(t, snapshotRangeDir(dir, desc.RangeID), filepath.Dir(filepath.Dir(sst)))
+		require.Equal(t, strconv.Itoa(i)+".sst", filepath.Base(sst))
+	}
+
+	// The last SST covers the user keys, and only contains those in the range.
+	data, err := os.ReadFile(ssts[len(ssts)-1])
+	require.NoError(t, err)
+	iter, err := storage.NewPebbleMemSSTIterator(data, true /* verify */, storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypePointsOnly,
+		UpperBound: roachpb.KeyMax,
+	})
+	require.NoError(t, err)
+	defer iter.Close()
+	var keys []string
+	for iter.SeekGE(storage.MVCCKey{Key: roachpb.KeyMin}); ; iter.Next() {
+		ok, err := iter.Valid()
+		require.NoError(t, err)
+		if !ok {
+			break
+		}
+		keys = append(keys, string(iter.UnsafeKey().Key))
+	}
+	require.Equal(t, []string{"d", "e", "f"}, keys)
+}
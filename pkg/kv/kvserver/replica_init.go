@@ -227,7 +227,7 @@ func (r *Replica) loadRaftMuLockedReplicaMuLocked(desc *roachpb.RangeDescriptor)
 		desc.RangeID,
 		replicaID,
 		ssBase,
-		r.store.limiters.BulkIOWriteRate,
+		r.store.sideloadLimiter,
 		r.store.engine,
 	); err != nil {
 		return errors.Wrap(err, "while initializing sideloaded storage")
@@ -13,7 +13,6 @@ package kvserver
 import (
 	"context"
 	"fmt"
-	"path/filepath"
 	"time"
 	"unsafe"
 
@@ -35,9 +34,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/redact"
 	"github.com/kr/pretty"
-	"golang.org/x/time/rate"
 )
 
 // ProposalData is data about a command which allows it to be
@@ -445,6 +444,24 @@ var addSSTPreApplyWarn = struct {
 	log.EveryN
 }{500 * time.Millisecond, log.Every(time.Second)}
 
+// addSSTablePreApply ingests the SST of an AddSSTable command into the
+// engine, before the command's write batch is applied. It returns whether the
+// SST had to be copied, rather than linked from the sideloaded storage.
+//
+// The ingestion of bulk data, e.g. by RESTORE, is throttled at several
+// levels:
+//   - the AddSSTable requests are subject to admission control on the
+//     leaseholder, at bulk priority, and the bytes ingested on followers are
+//     reported to admission control (see followerStoreWriteBytes);
+//   - PreIngestDelay below delays the ingestion while L0 has too many files
+//     or sublevels, protecting the LSM from inverting;
+//   - the copies staged in the scratch space are paced by the bulk I/O write
+//     limiter.
+//
+// TODO(kvserver): unlike the SSTs of a snapshot, the SSTs of AddSSTable
+// commands are ingested one command at a time. Batching the ingestion of
+// consecutive commands would require deferring their application, and is
+// left out of the scope of the scratch space staging.
 func addSSTablePreApply(
 	ctx context.Context,
 	st *cluster.Settings,
@@ -452,7 +469,8 @@ func addSSTablePreApply(
 	sideloaded SideloadStorage,
 	term, index uint64,
 	sst kvserverpb.ReplicatedEvalResult_AddSSTable,
-	limiter *rate.Limiter,
+	sss *SSTSnapshotStorage,
+	rangeID roachpb.RangeID,
 ) bool {
 	checksum := util.CRC32(sst.Data)
 
@@ -483,7 +501,6 @@ func addSSTablePreApply(
 	eng.PreIngestDelay(ctx)
 	tEndDelayed = timeutil.Now()
 
-	copied := false
 	if eng.InMem() {
 		// Ingest a copy of the SST. Otherwise, Pebble will claim and mutate the
 		// sst.Data byte slice, which will also be used later by e.g. rangefeeds.
@@ -511,36 +528,68 @@ func addSSTablePreApply(
 			return false
 		}
 
-		path = ingestPath
-
-		log.Eventf(ctx, "copying SSTable for ingestion at index %d, term %d: %s", index, term, path)
-
-		// TODO(tschottdorf): remove this once sideloaded storage guarantees its
-		// existence.
-		if err := eng.MkdirAll(filepath.Dir(path)); err != nil {
-			panic(err)
+		// Otherwise, stage a copy of the SST in the store's scratch space, like
+		// the SSTs of an incoming snapshot: the copy is paced by the bulk I/O
+		// write limiter, within the share of AddSSTable commands, and is removed
+		// on startup if the node crashes before the ingestion completes.
+		log.Eventf(ctx, "copying SSTable for ingestion at index %d, term %d", index, term)
+		scratch, err := sss.NewScratchSpace(ctx, rangeID, uuid.MakeV7())
+		if err != nil {
+			log.Fatalf(ctx, "while staging SSTable at index %d, term %d: %+v", index, term, err)
 		}
-		if _, err := eng.Stat(path); err == nil {
-			// The file we want to ingest exists. This can happen since the
-			// ingestion may apply twice (we ingest before we mark the Raft
-			// command as committed). Just unlink the file (the storage engine
-			// created a hard link); after that we're free to write it again.
-			if err := eng.Remove(path); err != nil {
-				log.Fatalf(ctx, "while removing existing file during ingestion of %s: %+v", path, err)
-			}
+		defer func() {
+			// Nothing actionable if the scratch cannot be removed; orphaned
+			// scratches are removed on startup.
+			_ = scratch.Close()
+		}()
+		scratch.SetAddSSTable()
+		if err := stageSSTForIngestion(ctx, st, scratch, sst.Data); err != nil {
+			log.Fatalf(ctx, "while staging SSTable at index %d, term %d: %+v", index, term, err)
 		}
-
-		if err := writeFileSyncing(ctx, path, sst.Data, eng, 0600, st, limiter); err != nil {
-			log.Fatalf(ctx, "while ingesting %s: %+v", path, err)
+		if err := eng.IngestExternalFiles(ctx, scratch.SSTs()); err != nil {
+			log.Fatalf(ctx, "while ingesting %s: %+v", scratch.SSTs(), err)
 		}
-		copied = true
+		log.Eventf(ctx, "ingested SSTable at index %d, term %d: %s", index, term, scratch.SSTs())
+		return true
 	}
 
 	if err := eng.IngestExternalFiles(ctx, []string{path}); err != nil {
 		log.Fatalf(ctx, "while ingesting %s: %+v", path, err)
 	}
 	log.Eventf(ctx, "ingested SSTable at index %d, term %d: %s", index, term, path)
-	return copied
+	return false
+}
+
+// stageSSTForIngestion writes a copy of the given SST to a new file of the
+// given scratch space. The data is written in chunks, each of which is paced
+// by the limiter of the scratch space, and the file is synced periodically
+// according to kv.bulk_sst.sync_size.
+func stageSSTForIngestion(
+	ctx context.Context, st *cluster.Settings, scratch *SSTSnapshotStorageScratch, data []byte,
+) error {
+	bytesPerSync := sstWriteSyncRate.Get(&st.SV)
+	f, err := scratch.NewFile(ctx, bytesPerSync)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Closing an SSTSnapshotStorageFile multiple times is idempotent.
+		_ = f.Close()
+	}()
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > bulkIOWriteBurst {
+			chunk = chunk[:bulkIOWriteBurst]
+		}
+		if _, err := f.Write(chunk); err != nil {
+			return err
+		}
+		data = data[len(chunk):]
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	return f.Close()
 }
 
 func (r *Replica) handleReadWriteLocalEvalResult(ctx context.Context, lResult result.LocalResult) {
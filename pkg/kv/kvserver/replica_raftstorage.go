@@ -32,7 +32,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
-	"github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/redact"
 	"go.etcd.io/etcd/raft/v3"
 	"go.etcd.io/etcd/raft/v3/raftpb"
@@ -438,7 +437,7 @@ func (r *Replica) raftSnapshotLocked() (raftpb.Snapshot, error) {
 func (r *Replica) GetSnapshot(
 	ctx context.Context, snapType kvserverpb.SnapshotRequest_Type, recipientStore roachpb.StoreID,
 ) (_ *OutgoingSnapshot, err error) {
-	snapUUID := uuid.MakeV4()
+	snapUUID := uuid.MakeV7()
 	// Get a snapshot while holding raftMu to make sure we're not seeing "half
 	// an AddSSTable" (i.e. a state in which an SSTable has been linked in, but
 	// the corresponding Raft command not applied yet).
@@ -530,6 +529,9 @@ type OutgoingSnapshot struct {
 	RaftEntryCache *raftentry.Cache
 	snapType       kvserverpb.SnapshotRequest_Type
 	onClose        func()
+	// staged, if set, holds the batches of the snapshot, which are sent
+	// instead of iterating over EngineSnap.
+	staged *stagedOutgoingSnapshot
 }
 
 func (s OutgoingSnapshot) String() string {
@@ -562,6 +564,9 @@ type IncomingSnapshot struct {
 	snapType         kvserverpb.SnapshotRequest_Type
 	placeholder      *ReplicaPlaceholder
 	raftAppliedIndex uint64 // logging only
+	// dataChecksum is the checksum of the replicated key-values of the
+	// snapshot computed by the sender, if any (see replicaDataChecksum).
+	dataChecksum []byte
 }
 
 func (s IncomingSnapshot) String() string {
@@ -873,7 +878,11 @@ func (r *Replica) applySnapshot(
 		subsumedReplicas time.Time
 		// Time to ingest SSTs.
 		ingestion time.Time
+		// Bytes written to the disk for the snapshot.
+		writeBytes snapshotWriteBytes
 	}
+	// Set if the SSTs were applied through a batch rather than ingested.
+	var appliedAsBatch bool
 	log.KvDistribution.Infof(ctx, "applying %s", inSnap)
 	defer func(start time.Time) {
 		var logDetails redact.StringBuilder
@@ -883,8 +892,17 @@ func (r *Replica) applySnapshot(
 			logDetails.Printf(" subsumedReplicas=%d@%0.0fms",
 				len(subsumedRepls), stats.subsumedReplicas.Sub(start).Seconds()*1000)
 		}
-		logDetails.Printf(" ingestion=%d@%0.0fms", len(inSnap.SSTStorageScratch.SSTs()),
-			stats.ingestion.Sub(stats.subsumedReplicas).Seconds()*1000)
+		if appliedAsBatch {
+			logDetails.Printf(" batch=%d@%0.0fms", len(inSnap.SSTStorageScratch.SSTs()),
+				stats.ingestion.Sub(stats.subsumedReplicas).Seconds()*1000)
+		} else {
+			logDetails.Printf(" ingestion=%d@%0.0fms", len(inSnap.SSTStorageScratch.SSTs()),
+				stats.ingestion.Sub(stats.subsumedReplicas).Seconds()*1000)
+		}
+		logDetails.Printf(" staging=(%s)", inSnap.SSTStorageScratch.Stats())
+		if !stats.ingestion.IsZero() {
+			logDetails.Printf(" writeAmp=%.2f", stats.writeBytes.amplification())
+		}
 		log.Infof(ctx, "applied %s (%s)", inSnap, logDetails)
 	}(timeutil.Now())
 
@@ -954,21 +972,43 @@ func (r *Replica) applySnapshot(
 	}
 	stats.subsumedReplicas = timeutil.Now()
 
+	// Check that the SSTs only hold keys of the replica and of the subsumed
+	// replicas before applying them.
+	allowedSpans := rditer.MakeAllKeySpans(desc)
+	for _, sr := range subsumedRepls {
+		allowedSpans = append(allowedSpans, rditer.MakeAllKeySpans(sr.Desc())...)
+	}
+	if err := inSnap.SSTStorageScratch.ValidateBounds(allowedSpans); err != nil {
+		return err
+	}
+
 	// Ingest all SSTs atomically.
 	if fn := r.store.cfg.TestingKnobs.BeforeSnapshotSSTIngestion; fn != nil {
 		if err := fn(inSnap, inSnap.snapType, inSnap.SSTStorageScratch.SSTs()); err != nil {
 			return err
 		}
 	}
-	var ingestStats pebble.IngestOperationStats
-	if ingestStats, err =
-		r.store.engine.IngestExternalFilesWithStats(ctx, inSnap.SSTStorageScratch.SSTs()); err != nil {
-		return errors.Wrapf(err, "while ingesting %s", inSnap.SSTStorageScratch.SSTs())
+	// The SSTs of a snapshot subsuming replicas may clear keys outside of the
+	// spans of the replica, so they are always ingested.
+	var clearedSpans []roachpb.Span
+	if len(subsumedRepls) == 0 {
+		clearedSpans = append(rditer.MakeReplicatedKeySpans(desc),
+			roachpb.Span{Key: unreplicatedStart, EndKey: unreplicatedEnd})
+	}
+	ingested, ingestStats, err := inSnap.SSTStorageScratch.Ingest(ctx, r.store.engine, clearedSpans)
+	if err != nil {
+		return err
 	}
-	if r.store.cfg.KVAdmissionController != nil {
+	if !ingested {
+		appliedAsBatch = true
+		r.store.metrics.RangeSnapshotsAppliedAsBatch.Inc(1)
+	} else if r.store.cfg.KVAdmissionController != nil {
 		r.store.cfg.KVAdmissionController.SnapshotIngested(r.store.StoreID(), ingestStats)
 	}
 	stats.ingestion = timeutil.Now()
+	stats.writeBytes = makeSnapshotWriteBytes(inSnap, ingestStats)
+	r.store.metrics.recordSnapshotWriteBytes(stats.writeBytes)
+	r.maybeVerifyAppliedSnapshot(ctx, inSnap, desc)
 
 	state, err := stateloader.Make(desc.RangeID).Load(ctx, r.store.engine, desc)
 	if err != nil {
@@ -1101,9 +1141,64 @@ func (r *Replica) applySnapshot(
 		log.Fatalf(ctx, "unable to update range info while applying snapshot: %+v", err)
 	}
 
+	r.prewarmSnapshot(ctx, desc)
 	return nil
 }
 
+// prewarmSnapshot reads the replicated data of the range in the background,
+// up to kv.snapshot_receiver.block_cache_prewarm.max_bytes, so that the blocks
+// of the SSTs just ingested for a snapshot are loaded in the block cache and
+// the first reads of the replica don't all miss the cache.
+func (r *Replica) prewarmSnapshot(ctx context.Context, desc *roachpb.RangeDescriptor) {
+	maxBytes := snapshotPrewarmMaxBytes.Get(&r.ClusterSettings().SV)
+	if maxBytes <= 0 {
+		return
+	}
+	taskCtx := r.AnnotateCtx(context.Background())
+	if err := r.store.stopper.RunAsyncTask(taskCtx, "prewarm-snapshot", func(ctx context.Context) {
+		ctx, cancel := r.store.stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		start := timeutil.Now()
+		snap := r.store.engine.NewSnapshot()
+		defer snap.Close()
+		n, err := prewarmReplicaData(ctx, snap, desc, maxBytes)
+		if err != nil {
+			log.VEventf(ctx, 2, "prewarming the block cache for the snapshot of r%d: %v", desc.RangeID, err)
+			return
+		}
+		log.VEventf(ctx, 2, "prewarmed the block cache with %s of r%d in %s",
+			humanizeutil.IBytes(n), desc.RangeID, timeutil.Since(start))
+	}); err != nil {
+		log.VEventf(ctx, 2, "not prewarming the block cache for the snapshot: %v", err)
+	}
+}
+
+// prewarmReplicaData reads the replicated keys of the range, until at least
+// maxBytes of keys and values are read. It returns the number of bytes read.
+func prewarmReplicaData(
+	ctx context.Context, reader storage.Reader, desc *roachpb.RangeDescriptor, maxBytes int64,
+) (int64, error) {
+	var n int64
+	err := rditer.IterateReplicaKeySpans(desc, reader, true, /* replicatedOnly */
+		func(iter storage.EngineIterator, _ roachpb.Span, keyType storage.IterKeyType) error {
+			if keyType != storage.IterKeyTypePointsOnly {
+				return nil
+			}
+			var err error
+			for ok := true; ok && err == nil; ok, err = iter.NextEngineKey() {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				n += int64(len(iter.UnsafeRawEngineKey()) + len(iter.UnsafeValue()))
+				if n >= maxBytes {
+					return iterutil.StopIteration()
+				}
+			}
+			return err
+		})
+	return n, err
+}
+
 // clearSubsumedReplicaDiskData clears the on disk data of the subsumed
 // replicas by creating SSTs with range deletion tombstones. We have to be
 // careful here not to have overlapping ranges with the SSTs we have already
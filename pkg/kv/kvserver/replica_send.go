@@ -930,7 +930,10 @@ func (r *Replica) executeAdminBatch(
 
 	case *roachpb.AdminChangeReplicasRequest:
 		chgs := tArgs.Changes()
-		desc, err := r.ChangeReplicas(ctx, &tArgs.ExpDesc, kvserverpb.SnapshotRequest_REBALANCE, kvserverpb.ReasonAdminRequest, "", chgs)
+		priority, senderQueuePriority := adminSnapshotPriority(tArgs.SnapshotPriority)
+		desc, err := r.changeReplicasWithSenderPriority(
+			ctx, &tArgs.ExpDesc, priority, senderQueuePriority, kvserverpb.ReasonAdminRequest, "", chgs,
+		)
 		pErr = roachpb.NewError(err)
 		if pErr != nil {
 			resp = &roachpb.AdminChangeReplicasResponse{}
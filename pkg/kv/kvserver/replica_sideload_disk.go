@@ -21,16 +21,16 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/errors/oserror"
-	"golang.org/x/time/rate"
 )
 
 var _ SideloadStorage = &diskSideloadStorage{}
 
 type diskSideloadStorage struct {
 	st         *cluster.Settings
-	limiter    *rate.Limiter
+	limiter    *quotapool.ChildRateLimiter
 	dir        string
 	dirCreated bool
 	eng        storage.Engine
@@ -78,7 +78,7 @@ func newDiskSideloadStorage(
 	rangeID roachpb.RangeID,
 	replicaID roachpb.ReplicaID,
 	baseDir string,
-	limiter *rate.Limiter,
+	limiter *quotapool.ChildRateLimiter,
 	eng storage.Engine,
 ) (*diskSideloadStorage, error) {
 	path := deprecatedSideloadedPath(baseDir, rangeID, replicaID)
@@ -169,7 +169,13 @@ func (ss *diskSideloadStorage) Filename(ctx context.Context, index, term uint64)
 }
 
 func (ss *diskSideloadStorage) filename(ctx context.Context, index, term uint64) string {
-	return filepath.Join(ss.dir, fmt.Sprintf("i%d.t%d", index, term))
+	return filepath.Join(ss.dir, SideloadedFilename(index, term))
+}
+
+// SideloadedFilename returns the base name of the file holding the payload
+// of the sideloaded entry at the given index and term.
+func SideloadedFilename(index, term uint64) string {
+	return fmt.Sprintf("i%d.t%d", index, term)
 }
 
 // Purge implements SideloadStorage.
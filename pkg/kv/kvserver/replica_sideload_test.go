@@ -43,7 +43,6 @@ import (
 	"github.com/kr/pretty"
 	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/raft/v3/raftpb"
-	"golang.org/x/time/rate"
 )
 
 func entryEq(l, r raftpb.Entry) error {
@@ -104,7 +103,7 @@ func newTestingSideloadStorage(t *testing.T, eng storage.Engine) *diskSideloadSt
 	st := cluster.MakeTestingClusterSettings()
 	ss, err := newDiskSideloadStorage(
 		st, 1, 2, filepath.Join(eng.GetAuxiliaryDir(), "fake", "testing", "dir"),
-		rate.NewLimiter(rate.Inf, math.MaxInt64), eng,
+		unlimitedBulkIOWriteLimiter(), eng,
 	)
 	require.NoError(t, err)
 	return ss
@@ -12,164 +12,2080 @@ package kvserver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/sysutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
-	"golang.org/x/time/rate"
+	"github.com/cockroachdb/errors/oserror"
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/sstable"
+	"github.com/cockroachdb/redact"
 )
 
 // SSTSnapshotStorage provides an interface to create scratches and owns the
 // directory of scratches created. A scratch manages the SSTs created during a
 // specific snapshot.
 type SSTSnapshotStorage struct {
-	engine  storage.Engine
-	limiter *rate.Limiter
+	fs      fs.FS
+	limiter *quotapool.ChildRateLimiter
 	dir     string
-	mu      struct {
+	// recoveryLimiter, if set, paces the writes of the scratches of recovery
+	// snapshots (see SetRecovery) instead of limiter, so that they can be
+	// given a larger share of the store's bulk IO write budget.
+	recoveryLimiter *quotapool.ChildRateLimiter
+	// addSSTableLimiter, if set, paces the writes of the scratches staging the
+	// SSTs of AddSSTable commands (see SetAddSSTable) instead of limiter, so
+	// that they don't eat into the share of the snapshots.
+	addSSTableLimiter *quotapool.ChildRateLimiter
+	// maxBytes, if set, returns the maximum number of bytes the scratches can
+	// hold in total. A non-positive value means that there is no limit.
+	maxBytes func() int64
+	// maxFiles, if set, returns the maximum number of files of a scratch. A
+	// non-positive value means that there is no limit.
+	maxFiles func() int64
+	// maxOverrunFactor, if set, returns the factor by which the bytes written
+	// to a scratch can exceed its expected size (see SetExpectedBytes). A
+	// non-positive value means that there is no limit.
+	maxOverrunFactor func() float64
+	// applyAsBatchMaxBytes, if set, returns the number of bytes written to a
+	// scratch up to which its SSTs are applied through a write batch rather
+	// than ingested (see Ingest). A non-positive value means that the SSTs are
+	// always ingested.
+	applyAsBatchMaxBytes func() int64
+	// maxQuarantined, if set, returns the number of aborted scratches kept in
+	// the quarantine directory for inspection (see Abort). A non-positive value
+	// means that aborted scratches are removed, like closed ones.
+	maxQuarantined func() int64
+	// bytesPerSync, if set, returns the interval at which the dirty data of
+	// the files written by WriteSST and WriteSSTFrom is synced, to smooth out
+	// the writes. See defaultScratchBytesPerSync.
+	bytesPerSync func() int64
+	// syncPolicy, if set, returns the policy of syncing of the files. With
+	// scratchSyncFinal, the files are not synced periodically, whatever the
+	// interval passed to NewFile. The files are synced periodically if unset.
+	syncPolicy func() scratchSyncPolicy
+	// writeBufferSize, if set, returns the size of the buffer in which the
+	// writes to a file are coalesced (see SSTSnapshotStorageFile.Write). The
+	// writes are not buffered if unset or non-positive.
+	writeBufferSize func() int64
+	// dropPageCache, if set, returns whether the files are dropped from the OS
+	// page cache once synced (see SSTSnapshotStorageFile.Sync).
+	dropPageCache func() bool
+	// beforeFileOp, if set, is called before the filesystem operations of the
+	// scratches (see StoreTestingKnobs.BeforeSnapshotScratchFileOp).
+	beforeFileOp func(op ScratchFileOp, path string) error
+	// metrics, if set, are the store metrics updated by the storage.
+	metrics *StoreMetrics
+	// writeRate, if set, further limits the rate of the writes based on the
+	// health of the disk.
+	writeRate *snapshotWriteRateController
+	// admit, if set, subjects the writes to the admission control of the store.
+	// It returns whether the write was admitted by admission control, in which
+	// case neither the limiter nor writeRate apply.
+	admit func(ctx context.Context, n int64) (admitted bool, err error)
+	mu    sstSnapshotStorageMu
+	// budget, if set, bounds the space used by the scratches of all the stores
+	// of the node, in addition to maxBytes.
+	budget *SnapshotScratchBudget
+	// runAsync, if set, runs the given function in the background, and is
+	// used to empty the trash (see moveToTrash). The trash is emptied
+	// synchronously if unset, or if the function cannot be run.
+	runAsync func(taskName string, f func(context.Context)) error
+	// trash tracks the emptying of the trash.
+	trash struct {
 		syncutil.Mutex
-		rangeRefCount map[roachpb.RangeID]int
+		// emptying is set while the trash is being emptied in the background.
+		emptying bool
+		// again is set if directories were moved to the trash while it was
+		// being emptied, so that it is emptied once more.
+		again bool
 	}
+	// pause gates the writes of the scratches while they are paused (see
+	// SetPaused).
+	pause struct {
+		syncutil.Mutex
+		// byStore and bySetting are set while the writes are paused through
+		// SetPaused and the kv.snapshot_receiver.scratch_writes.paused cluster
+		// setting respectively.
+		byStore, bySetting bool
+		// resumed is closed once the writes are resumed.
+		resumed chan struct{}
+	}
+}
+
+// sstSnapshotStorageMu is the state of an SSTSnapshotStorage protected by its
+// mutex.
+type sstSnapshotStorageMu struct {
+	// The mutex is named by NewStore so that its contention shows up in
+	// /debug/mutexes.
+	syncutil.InstrumentedMutex
+	rangeRefCount map[roachpb.RangeID]int
+	// clearing contains the ranges whose orphaned directories are being
+	// removed by ClearOrphaned. The channels are closed once the removal is
+	// done, and no scratch can be created for these ranges until then.
+	clearing map[roachpb.RangeID]chan struct{}
+	// active contains the snapshots which have an open scratch.
+	active map[scratchKey]struct{}
+	// usedBytes is the number of bytes written to the open scratches.
+	usedBytes int64
+	// probeErr is the error of the last probe of the storage directory, if it
+	// failed (see Probe).
+	probeErr error
+}
+
+// scratchKey identifies the scratch of a snapshot.
+type scratchKey struct {
+	rangeID  roachpb.RangeID
+	snapUUID uuid.UUID
+}
+
+// ScratchFileOp is a filesystem operation of a snapshot scratch, into which
+// tests can inject faults with StoreTestingKnobs.BeforeSnapshotScratchFileOp.
+type ScratchFileOp int
+
+const (
+	// ScratchFileCreate is the creation of an SST file.
+	ScratchFileCreate ScratchFileOp = iota
+	// ScratchFileWrite is a write to an SST file.
+	ScratchFileWrite
+	// ScratchFileSync is a sync of an SST file.
+	ScratchFileSync
+	// ScratchRemoveAll is the removal of the directory of a scratch, or of the
+	// directory of its range once its last scratch is closed.
+	ScratchRemoveAll
+)
+
+func (op ScratchFileOp) String() string {
+	switch op {
+	case ScratchFileCreate:
+		return "create"
+	case ScratchFileWrite:
+		return "write"
+	case ScratchFileSync:
+		return "sync"
+	case ScratchRemoveAll:
+		return "remove-all"
+	default:
+		return fmt.Sprintf("ScratchFileOp(%d)", int(op))
+	}
+}
+
+// beforeOp calls the beforeFileOp hook of the storage, if set, and returns its
+// error.
+func (s *SSTSnapshotStorage) beforeOp(op ScratchFileOp, path string) error {
+	if s.beforeFileOp == nil {
+		return nil
+	}
+	return s.beforeFileOp(op, path)
+}
+
+// snapshotStorageDir returns the directory holding the snapshot scratches of
+// a store whose auxiliary directory is auxDir.
+func snapshotStorageDir(auxDir string) string {
+	return filepath.Join(auxDir, "sstsnapshot")
+}
+
+// snapshotStorageShards is the number of shards of the snapshot storage
+// directory, which bound the number of entries of each directory when a store
+// receives snapshots for many ranges.
+const snapshotStorageShards = 256
+
+// snapshotShardName returns the name of the shard of the snapshot storage
+// directory holding the scratches of the given range. The shards are named
+// "s" followed by two hexadecimal digits, which cannot be mistaken for the
+// range directories of the legacy layout.
+func snapshotShardName(rangeID roachpb.RangeID) string {
+	return fmt.Sprintf("s%02x", uint64(rangeID)%snapshotStorageShards)
+}
+
+// isSnapshotShardName returns whether name is the name of a shard of the
+// snapshot storage directory (see snapshotShardName).
+func isSnapshotShardName(name string) bool {
+	if len(name) != 3 || name[0] != 's' {
+		return false
+	}
+	_, err := strconv.ParseUint(name[1:], 16, 8)
+	return err == nil
+}
+
+// snapshotRangeDir returns the directory holding the snapshot scratches of the
+// given range, below the given snapshot storage directory. For example, the
+// scratches of r1828 are in <storageDir>/s24/1828.
+func snapshotRangeDir(storageDir string, rangeID roachpb.RangeID) string {
+	return filepath.Join(storageDir, snapshotShardName(rangeID), strconv.Itoa(int(rangeID)))
+}
+
+// legacySnapshotRangeDir returns the directory holding the snapshot scratches
+// of the given range in the layout used before the storage directory was
+// sharded, in which the range directories are right below it. The scratches
+// found in this layout are still cleaned up, and resumed (see
+// ResumeScratchSpace).
+func legacySnapshotRangeDir(storageDir string, rangeID roachpb.RangeID) string {
+	return filepath.Join(storageDir, strconv.Itoa(int(rangeID)))
+}
+
+// snapshotQuarantineDir returns the directory holding the aborted scratches
+// kept for inspection, next to the given snapshot storage directory. It is not
+// below it, so that the quarantined scratches are not mistaken for leftovers.
+func snapshotQuarantineDir(storageDir string) string {
+	return storageDir + "-quarantine"
+}
+
+// snapshotTrashDir returns the directory into which the directories of the
+// snapshot storage are moved before being removed, next to the given snapshot
+// storage directory (see SSTSnapshotStorage.moveToTrash). Anything found in it
+// is garbage, including after a crash in the middle of a removal.
+func snapshotTrashDir(storageDir string) string {
+	return storageDir + "-trash"
 }
 
-// NewSSTSnapshotStorage creates a new SST snapshot storage.
-func NewSSTSnapshotStorage(engine storage.Engine, limiter *rate.Limiter) SSTSnapshotStorage {
+// NewSSTSnapshotStorage creates a new SST snapshot storage. The scratches are
+// created in the snapshot scratch directory of the engine if it has one, and
+// in its auxiliary directory otherwise.
+func NewSSTSnapshotStorage(
+	engine storage.Engine, limiter *quotapool.ChildRateLimiter,
+) SSTSnapshotStorage {
+	dir := engine.GetSnapshotScratchDir()
+	if dir == "" {
+		dir = snapshotStorageDir(engine.GetAuxiliaryDir())
+	}
+	return NewSSTSnapshotStorageInDir(engine, dir, limiter)
+}
+
+// NewSSTSnapshotStorageInDir creates a new SST snapshot storage whose
+// scratches are created in the given directory of the given filesystem,
+// rather than in the auxiliary directory of a store.
+func NewSSTSnapshotStorageInDir(
+	fs fs.FS, dir string, limiter *quotapool.ChildRateLimiter,
+) SSTSnapshotStorage {
 	return SSTSnapshotStorage{
-		engine:  engine,
+		fs:      fs,
 		limiter: limiter,
-		dir:     filepath.Join(engine.GetAuxiliaryDir(), "sstsnapshot"),
-		mu: struct {
-			syncutil.Mutex
-			rangeRefCount map[roachpb.RangeID]int
-		}{rangeRefCount: make(map[roachpb.RangeID]int)},
+		dir:     dir,
+		mu: sstSnapshotStorageMu{
+			rangeRefCount: make(map[roachpb.RangeID]int),
+			clearing:      make(map[roachpb.RangeID]chan struct{}),
+			active:        make(map[scratchKey]struct{}),
+		},
 	}
 }
 
 // NewScratchSpace creates a new storage scratch space for SSTs for a specific
-// snapshot.
+// snapshot. Snapshot UUIDs are time-ordered (V7) UUIDs, so that the scratch
+// directories of a range sort in the order the snapshots were created.
+//
+// An error is returned if the snapshot already has an open scratch, since both
+// scratches would share the same directory.
+//
+// The lifecycle of the scratch and of its files is recorded in the trace of
+// the given context.
 func (s *SSTSnapshotStorage) NewScratchSpace(
-	rangeID roachpb.RangeID, snapUUID uuid.UUID,
-) *SSTSnapshotStorageScratch {
+	ctx context.Context, rangeID roachpb.RangeID, snapUUID uuid.UUID,
+) (*SSTSnapshotStorageScratch, error) {
 	s.mu.Lock()
+	for {
+		done, ok := s.mu.clearing[rangeID]
+		if !ok {
+			break
+		}
+		// Wait for the orphaned directory of the range to be removed, so that
+		// it doesn't take the new scratch with it.
+		s.mu.Unlock()
+		<-done
+		s.mu.Lock()
+	}
+	key := scratchKey{rangeID: rangeID, snapUUID: snapUUID}
+	if _, ok := s.mu.active[key]; ok {
+		s.mu.Unlock()
+		return nil, errors.Errorf("snapshot %s of r%d already has an open scratch", snapUUID, rangeID)
+	}
+	s.mu.active[key] = struct{}{}
 	s.mu.rangeRefCount[rangeID]++
 	s.mu.Unlock()
-	snapDir := filepath.Join(s.dir, strconv.Itoa(int(rangeID)), snapUUID.String())
+	if s.metrics != nil {
+		s.metrics.SnapshotScratchActive.Inc(1)
+	}
+	snapDir := filepath.Join(snapshotRangeDir(s.dir, rangeID), snapUUID.String())
+	log.Eventf(ctx, "created snapshot scratch %s", snapDir)
 	return &SSTSnapshotStorageScratch{
-		storage: s,
-		rangeID: rangeID,
-		snapDir: snapDir,
+		ctx:      ctx,
+		storage:  s,
+		rangeID:  rangeID,
+		snapUUID: snapUUID,
+		snapDir:  snapDir,
+		created:  timeutil.Now(),
+	}, nil
+}
+
+// ResumeScratchSpace is like NewScratchSpace, except that the scratch records
+// the SSTs which are complete, that is synced and closed, in a manifest. This
+// allows the reception of the snapshot to be resumed after a restart of the
+// store, by calling ResumeScratchSpace again with the same snapshot UUID: the
+// SSTs listed in the manifest of the previous incarnation are then kept in the
+// scratch and returned, in the order they were written, so that the sender
+// can resume the snapshot after them. The other files are removed.
+//
+// A manifest which cannot be read, or whose SSTs have gone missing, is not an
+// error: the scratch then starts over with the SSTs which are still intact.
+func (s *SSTSnapshotStorage) ResumeScratchSpace(
+	ctx context.Context, rangeID roachpb.RangeID, snapUUID uuid.UUID,
+) (*SSTSnapshotStorageScratch, []ScratchManifestEntry, error) {
+	scratch, err := s.NewScratchSpace(ctx, rangeID, snapUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	scratch.resumable = true
+	entries, err := scratch.recover(ctx)
+	if err != nil {
+		_ = scratch.Close()
+		return nil, nil, err
+	}
+	return scratch, entries, nil
+}
+
+// Dir returns the directory in which the scratches are created.
+func (s *SSTSnapshotStorage) Dir() string {
+	return s.dir
+}
+
+// Clear removes the storage directory if no scratch is open, and otherwise
+// removes the directories and SSTs of the ranges that have no scratch open,
+// like ClearOrphaned. It is thus safe to call while snapshots are in flight.
+// The storage directory is moved to the trash, and removed in the background.
+func (s *SSTSnapshotStorage) Clear() error {
+	// No scratch can be created while the lock is held, and the scratches only
+	// create their directory once they are open.
+	s.mu.Lock()
+	if len(s.mu.rangeRefCount) > 0 {
+		s.mu.Unlock()
+		_, err := s.ClearOrphaned()
+		return err
+	}
+	err := s.moveToTrash(s.dir)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	s.emptyTrash()
+	return nil
+}
+
+// moveToTrash moves the given directory to the trash, under a unique name
+// starting with its own, so that it can be removed without blocking the
+// caller. The directory is removed in place if it cannot be moved. Moving a
+// directory which does not exist is a no-op. The trash must then be emptied
+// with emptyTrash.
+func (s *SSTSnapshotStorage) moveToTrash(dir string) error {
+	if err := s.beforeOp(ScratchRemoveAll, dir); err != nil {
+		return err
+	}
+	trashDir := snapshotTrashDir(s.dir)
+	if err := s.fs.MkdirAll(trashDir); err != nil {
+		return s.fs.RemoveAll(dir)
+	}
+	name := fmt.Sprintf("%s.%s", filepath.Base(dir), uuid.MakeV4())
+	if err := s.fs.Rename(dir, filepath.Join(trashDir, name)); err != nil {
+		if oserror.IsNotExist(err) {
+			return nil
+		}
+		return s.fs.RemoveAll(dir)
+	}
+	return nil
+}
+
+// emptyTrash removes the contents of the trash in the background, or right
+// away if the storage cannot run tasks in the background. It is a no-op if the
+// trash is already being emptied, except that it is then emptied once more.
+func (s *SSTSnapshotStorage) emptyTrash() {
+	s.trash.Lock()
+	if s.trash.emptying {
+		s.trash.again = true
+		s.trash.Unlock()
+		return
+	}
+	s.trash.emptying = true
+	s.trash.Unlock()
+	run := func(ctx context.Context) {
+		for {
+			if _, err := s.removeTrash(); err != nil {
+				log.Warningf(ctx, "failed to empty the snapshot storage trash: %v", err)
+			}
+			s.trash.Lock()
+			if !s.trash.again {
+				s.trash.emptying = false
+				s.trash.Unlock()
+				return
+			}
+			s.trash.again = false
+			s.trash.Unlock()
+		}
+	}
+	if s.runAsync == nil || s.runAsync("snapshot-storage-empty-trash", run) != nil {
+		run(context.Background())
+	}
+}
+
+// removeTrash removes the contents of the trash, and returns the number of
+// bytes reclaimed. The trash directory itself is kept, so that directories can
+// be moved into it concurrently.
+func (s *SSTSnapshotStorage) removeTrash() (int64, error) {
+	trashDir := snapshotTrashDir(s.dir)
+	names, err := s.fs.List(trashDir)
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var reclaimed int64
+	for _, name := range names {
+		size, err := removeAllWithSize(s.fs, filepath.Join(trashDir, name))
+		reclaimed += size
+		if err != nil {
+			return reclaimed, err
+		}
+	}
+	return reclaimed, nil
+}
+
+// LeftoverScratch describes a snapshot scratch directory found by
+// ScanLeftovers.
+type LeftoverScratch struct {
+	// Dir is the path of the directory.
+	Dir string
+	// RangeID is the range the scratch was created for, or zero if the
+	// directory is not named after a range.
+	RangeID roachpb.RangeID
+	// SnapUUID is the name of the scratch directory of the snapshot, or empty
+	// if the range directory contains no scratch.
+	SnapUUID string
+	// Bytes is the size of the files in the directory.
+	Bytes int64
+	// Resumable is set if the scratch has a manifest, and can thus be resumed
+	// with ResumeScratchSpace.
+	Resumable bool
+	// Info describes the snapshot staged in the scratch, if recorded (see
+	// SSTSnapshotStorageScratch.RecordInfo).
+	Info *ScratchInfo
+}
+
+// snapshotRangeDirEntry is a range directory of the snapshot storage.
+type snapshotRangeDirEntry struct {
+	rangeID roachpb.RangeID
+	path    string
+}
+
+// listRangeDirs returns the range directories of the storage, in both the
+// sharded and the legacy layout, along with the paths of the unexpected
+// entries, in lexical order. A range can have a directory in each layout.
+func (s *SSTSnapshotStorage) listRangeDirs() (
+	rangeDirs []snapshotRangeDirEntry,
+	unexpected []string,
+	_ error,
+) {
+	names, err := s.fs.List(s.dir)
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(s.dir, name)
+		if rangeID, err := strconv.ParseInt(name, 10, 64); err == nil {
+			rangeDirs = append(rangeDirs, snapshotRangeDirEntry{rangeID: roachpb.RangeID(rangeID), path: path})
+			continue
+		}
+		if name == snapshotProbeFilename {
+			// The sentinel file of a probe in progress.
+			continue
+		}
+		if !isSnapshotShardName(name) {
+			unexpected = append(unexpected, path)
+			continue
+		}
+		shardNames, err := s.fs.List(path)
+		if err != nil {
+			if oserror.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+		sort.Strings(shardNames)
+		for _, shardName := range shardNames {
+			shardPath := filepath.Join(path, shardName)
+			if rangeID, err := strconv.ParseInt(shardName, 10, 64); err == nil {
+				rangeDirs = append(rangeDirs, snapshotRangeDirEntry{rangeID: roachpb.RangeID(rangeID), path: shardPath})
+			} else {
+				unexpected = append(unexpected, shardPath)
+			}
+		}
+	}
+	return rangeDirs, unexpected, nil
+}
+
+// ScanLeftovers lists the scratch directories present in the storage, one per
+// snapshot, along with the range directories without any scratch and the
+// unexpected entries of the storage directory, in lexical order. It is meant
+// to be called before any scratch is created, to find the scratches left
+// behind by a crash.
+func (s *SSTSnapshotStorage) ScanLeftovers() ([]LeftoverScratch, error) {
+	rangeDirs, unexpected, err := s.listRangeDirs()
+	if err != nil {
+		return nil, err
+	}
+	var leftovers []LeftoverScratch
+	for _, path := range unexpected {
+		size, err := dirSize(s.fs, path)
+		if err != nil {
+			return nil, err
+		}
+		leftovers = append(leftovers, LeftoverScratch{Dir: path, Bytes: size})
+	}
+	for _, rangeDir := range rangeDirs {
+		snaps, err := s.fs.List(rangeDir.path)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(snaps)
+		if len(snaps) == 0 {
+			leftovers = append(leftovers, LeftoverScratch{Dir: rangeDir.path, RangeID: rangeDir.rangeID})
+			continue
+		}
+		for _, snap := range snaps {
+			snapPath := filepath.Join(rangeDir.path, snap)
+			size, err := dirSize(s.fs, snapPath)
+			if err != nil {
+				return nil, err
+			}
+			_, err = s.fs.Stat(filepath.Join(snapPath, scratchManifestFilename))
+			if err != nil && !oserror.IsNotExist(err) {
+				return nil, err
+			}
+			resumable := err == nil
+			info, err := readScratchInfo(s.fs, snapPath)
+			if err != nil {
+				return nil, err
+			}
+			leftovers = append(leftovers, LeftoverScratch{
+				Dir:       snapPath,
+				RangeID:   rangeDir.rangeID,
+				SnapUUID:  snap,
+				Bytes:     size,
+				Resumable: resumable,
+				Info:      info,
+			})
+		}
+	}
+	sort.Slice(leftovers, func(i, j int) bool { return leftovers[i].Dir < leftovers[j].Dir })
+	return leftovers, nil
+}
+
+// ClearLeftovers logs and removes the scratches left behind by a previous
+// incarnation of the store, which crashed before it could clean them up. The
+// resumable scratches are kept, so that their snapshots can be resumed; they
+// are removed along with the other scratches of their range once these are
+// closed, or by ClearOrphaned. It must be called before any scratch is
+// created. It returns the number of bytes reclaimed.
+func (s *SSTSnapshotStorage) ClearLeftovers(ctx context.Context) (int64, error) {
+	leftovers, err := s.ScanLeftovers()
+	if err != nil {
+		return 0, err
+	}
+	var reclaimed int64
+	var removed int
+	var kept bool
+	for _, l := range leftovers {
+		if l.Resumable {
+			log.Infof(ctx, "keeping resumable snapshot scratch %s (r%d, snapshot %q, %s, %s)",
+				l.Dir, l.RangeID, l.SnapUUID, humanizeutil.IBytes(l.Bytes), l.Info.state())
+			kept = true
+			continue
+		}
+		log.Infof(ctx, "removing leftover snapshot scratch %s (r%d, snapshot %q, %s, %s)",
+			l.Dir, l.RangeID, l.SnapUUID, humanizeutil.IBytes(l.Bytes), l.Info.state())
+		reclaimed += l.Bytes
+		removed++
+	}
+	if !kept {
+		if err := s.Clear(); err != nil {
+			return 0, err
+		}
+	} else {
+		for _, l := range leftovers {
+			if !l.Resumable {
+				if err := s.fs.RemoveAll(l.Dir); err != nil {
+					return 0, err
+				}
+			}
+		}
+	}
+	if removed > 0 {
+		log.Infof(ctx, "removed %d leftover snapshot scratches, reclaiming %s",
+			removed, humanizeutil.IBytes(reclaimed))
+	}
+	return reclaimed, nil
+}
+
+// ClearOrphaned removes the directories and SSTs of the ranges that have no
+// scratch open. It can be called at any time without affecting the snapshots
+// in flight. It returns the number of bytes reclaimed.
+func (s *SSTSnapshotStorage) ClearOrphaned() (int64, error) {
+	rangeDirs, unexpected, err := s.listRangeDirs()
+	if err != nil {
+		return 0, err
+	}
+	// Collect the orphaned directories under the lock, and mark their ranges
+	// as being cleared so that no scratch is created for them while they are
+	// removed. The removal itself happens outside of the lock, so as to not
+	// block the snapshots of the other ranges.
+	orphaned := unexpected
+	done := make(chan struct{})
+	var clearing []roachpb.RangeID
+	s.mu.Lock()
+	for _, rangeDir := range rangeDirs {
+		if s.mu.rangeRefCount[rangeDir.rangeID] > 0 {
+			continue
+		}
+		if ch, ok := s.mu.clearing[rangeDir.rangeID]; !ok {
+			s.mu.clearing[rangeDir.rangeID] = done
+			clearing = append(clearing, rangeDir.rangeID)
+		} else if ch != done {
+			// Already being cleared by a concurrent call.
+			continue
+		}
+		orphaned = append(orphaned, rangeDir.path)
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		for _, rangeID := range clearing {
+			delete(s.mu.clearing, rangeID)
+		}
+		s.mu.Unlock()
+		close(done)
+	}()
+
+	var reclaimed int64
+	for _, path := range orphaned {
+		size, err := removeAllWithSize(s.fs, path)
+		reclaimed += size
+		if err != nil {
+			return reclaimed, err
+		}
+	}
+	return reclaimed, nil
+}
+
+// ClearExpired removes the scratch directories which have no scratch open and
+// were last written to more than ttl before now. Unlike ClearOrphaned, which
+// skips the ranges with a scratch open, it also removes the scratches left
+// behind by the other snapshots of these ranges, for example when a scratch
+// could not be removed when it was closed. It returns the number of bytes
+// reclaimed.
+func (s *SSTSnapshotStorage) ClearExpired(now time.Time, ttl time.Duration) (int64, error) {
+	// Unexpected entries are removed by ClearOrphaned.
+	rangeDirs, _, err := s.listRangeDirs()
+	if err != nil {
+		return 0, err
+	}
+	var reclaimed int64
+	for _, rangeDir := range rangeDirs {
+		n, err := s.clearExpiredRange(rangeDir.rangeID, rangeDir.path, now, ttl)
+		reclaimed += n
+		if err != nil {
+			return reclaimed, err
+		}
+	}
+	return reclaimed, nil
+}
+
+// clearExpiredRange is ClearExpired for the scratches of a single range, in
+// the given range directory.
+func (s *SSTSnapshotStorage) clearExpiredRange(
+	rangeID roachpb.RangeID, rangeDir string, now time.Time, ttl time.Duration,
+) (int64, error) {
+	names, err := s.fs.List(rangeDir)
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var expired []string
+	for _, name := range names {
+		modTime, err := lastModified(s.fs, filepath.Join(rangeDir, name))
+		if err != nil {
+			if oserror.IsNotExist(err) {
+				continue
+			}
+			return 0, err
+		}
+		if now.Sub(modTime) > ttl {
+			expired = append(expired, name)
+		}
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	// Mark the range as being cleared, so that no scratch is created for it
+	// while the expired scratches are removed, and skip those which are open.
+	done := make(chan struct{})
+	s.mu.Lock()
+	if _, ok := s.mu.clearing[rangeID]; ok {
+		// Being cleared by a concurrent call.
+		s.mu.Unlock()
+		return 0, nil
+	}
+	s.mu.clearing[rangeID] = done
+	filtered := expired[:0]
+	for _, name := range expired {
+		if snapUUID, err := uuid.FromString(name); err == nil {
+			if _, ok := s.mu.active[scratchKey{rangeID: rangeID, snapUUID: snapUUID}]; ok {
+				continue
+			}
+		}
+		filtered = append(filtered, name)
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.mu.clearing, rangeID)
+		s.mu.Unlock()
+		close(done)
+	}()
+
+	var reclaimed int64
+	for _, name := range filtered {
+		size, err := removeAllWithSize(s.fs, filepath.Join(rangeDir, name))
+		reclaimed += size
+		if err != nil {
+			return reclaimed, err
+		}
+	}
+	return reclaimed, nil
+}
+
+// lastModified returns the last modification time of the given path and, if
+// it is a directory, of its direct entries. Scratch directories are flat, so
+// this is when the scratch was last written to.
+func lastModified(fs fs.FS, path string) (time.Time, error) {
+	info, err := fs.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	modTime := info.ModTime()
+	if !info.IsDir() {
+		return modTime, nil
+	}
+	names, err := fs.List(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, name := range names {
+		info, err := fs.Stat(filepath.Join(path, name))
+		if err != nil {
+			if oserror.IsNotExist(err) {
+				continue
+			}
+			return time.Time{}, err
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return modTime, nil
+}
+
+// quarantine moves the directory of the given scratch to the quarantine
+// directory, and removes the oldest quarantined scratches so that at most
+// maxQuarantined are kept. The quarantined scratches are named after the time
+// they were aborted, so that they sort from the oldest.
+func (s *SSTSnapshotStorage) quarantine(
+	scratch *SSTSnapshotStorageScratch, maxQuarantined int64,
+) error {
+	dir := snapshotQuarantineDir(s.dir)
+	if err := s.fs.MkdirAll(dir); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s.r%d.%s",
+		timeutil.Now().UTC().Format("20060102T150405.000000000"), scratch.rangeID, scratch.snapUUID)
+	if err := s.fs.Rename(scratch.snapDir, filepath.Join(dir, name)); err != nil {
+		return err
+	}
+	names, err := s.fs.List(dir)
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+	for len(names) > int(maxQuarantined) {
+		if err := s.fs.RemoveAll(filepath.Join(dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// defaultScratchBytesPerSync is the interval at which the dirty data of the
+// files written by WriteSST and WriteSSTFrom is synced if the storage does
+// not configure it.
+const defaultScratchBytesPerSync = 512 << 10 // 512 KB
+
+// fileBytesPerSync returns the interval at which the dirty data of the files
+// written by WriteSST and WriteSSTFrom is synced.
+func (s *SSTSnapshotStorage) fileBytesPerSync() int64 {
+	if s.bytesPerSync == nil {
+		return defaultScratchBytesPerSync
+	}
+	return s.bytesPerSync()
+}
+
+// reserveBytes accounts for n more bytes written to the given scratch. It
+// returns an error, without accounting for them, if they would take the
+// scratches over the maximum size.
+func (s *SSTSnapshotStorage) reserveBytes(scratch *SSTSnapshotStorageScratch, n int64) error {
+	var maxBytes int64
+	if s.maxBytes != nil {
+		maxBytes = s.maxBytes()
+	}
+	var overrunFactor float64
+	if s.maxOverrunFactor != nil {
+		overrunFactor = s.maxOverrunFactor()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if limit := scratch.sizeLimitLocked(overrunFactor); limit > 0 && scratch.usedBytes+n > limit {
+		return errors.Mark(errors.Errorf(
+			"snapshot scratch %s exceeds its expected size: writing %s would take it over %s (%s expected)",
+			scratch.snapDir, humanizeutil.IBytes(n), humanizeutil.IBytes(limit),
+			humanizeutil.IBytes(scratch.expectedBytes)), errMarkSnapshotScratchSizeExceeded)
+	}
+	if maxBytes > 0 && s.mu.usedBytes+n > maxBytes {
+		return errors.Errorf(
+			"snapshot scratch space exhausted: writing %s would exceed the %s limit (%s in use)",
+			humanizeutil.IBytes(n), humanizeutil.IBytes(maxBytes), humanizeutil.IBytes(s.mu.usedBytes))
+	}
+	if s.budget != nil {
+		if err := s.budget.reserve(n); err != nil {
+			return err
+		}
+	}
+	s.mu.usedBytes += n
+	scratch.usedBytes += n
+	if s.metrics != nil {
+		s.metrics.SnapshotScratchUsedBytes.Inc(n)
+		if ref := scratch.tenantMetrics; ref != nil {
+			s.metrics.getTenant(context.TODO(), ref).SnapshotScratchBytes.Inc(n)
+		}
+	}
+	return nil
+}
+
+// SnapshotScratchBudget bounds the disk space used by the snapshot scratches
+// of all the stores of a node, so that simultaneous snapshots received by
+// stores sharing a physical disk don't jointly fill it up. It is shared by the
+// SSTSnapshotStorage of these stores, and is limited by the
+// kv.snapshot_receiver.node_scratch_space.max_bytes cluster setting.
+type SnapshotScratchBudget struct {
+	st *cluster.Settings
+	mu struct {
+		syncutil.Mutex
+		// usedBytes is the number of bytes written to the open scratches of
+		// the stores.
+		usedBytes int64
+	}
+}
+
+// NewSnapshotScratchBudget creates a budget for the snapshot scratches of the
+// stores of a node.
+func NewSnapshotScratchBudget(st *cluster.Settings) *SnapshotScratchBudget {
+	return &SnapshotScratchBudget{st: st}
+}
+
+// reserve accounts for n more bytes written to a scratch. It returns an error,
+// without accounting for them, if they would take the scratches of the node
+// over the maximum size.
+func (b *SnapshotScratchBudget) reserve(n int64) error {
+	maxBytes := snapshotNodeScratchSpaceMaxBytes.Get(&b.st.SV)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if maxBytes > 0 && b.mu.usedBytes+n > maxBytes {
+		return errors.Errorf(
+			"node snapshot scratch space exhausted: writing %s would exceed the %s limit (%s in use)",
+			humanizeutil.IBytes(n), humanizeutil.IBytes(maxBytes), humanizeutil.IBytes(b.mu.usedBytes))
+	}
+	b.mu.usedBytes += n
+	return nil
+}
+
+// release accounts for n bytes of a scratch which was closed.
+func (b *SnapshotScratchBudget) release(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mu.usedBytes -= n
+}
+
+// UsedBytes returns the number of bytes written to the open scratches of the
+// stores of the node.
+func (b *SnapshotScratchBudget) UsedBytes() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.mu.usedBytes
+}
+
+// SetRecovery marks the scratch as staging a recovery snapshot, which
+// restores the replication factor of its range, so that its writes get the
+// share of the write budget of recovery snapshots. It must be called before
+// the scratch is written to.
+func (s *SSTSnapshotStorageScratch) SetRecovery() {
+	s.recovery = true
+}
+
+// SetAddSSTable marks the scratch as staging the SST of an AddSSTable command
+// rather than a snapshot, so that its writes get the share of the write budget
+// of AddSSTable commands. It must be called before the scratch is written to.
+func (s *SSTSnapshotStorageScratch) SetAddSSTable() {
+	s.addSSTable = true
+}
+
+// scratchExpectedBytesSlack is the number of bytes by which a scratch can
+// always exceed its expected size, so that small snapshots, whose SSTs are
+// dominated by their fixed overhead, are not held to the overrun factor.
+const scratchExpectedBytesSlack = 32 << 20 // 32 MiB
+
+// SetExpectedBytes declares the size of the snapshot staged in the scratch.
+// Once set, writes which would take the scratch over this size, multiplied by
+// the kv.snapshot_receiver.scratch_size_overrun_factor cluster setting, fail
+// right away, so that a runaway or malicious sender cannot fill up the disk
+// of the store. A non-positive size means that the size is unknown.
+func (s *SSTSnapshotStorageScratch) SetExpectedBytes(n int64) {
+	s.storage.mu.Lock()
+	defer s.storage.mu.Unlock()
+	s.expectedBytes = n
+}
+
+// sizeLimitLocked returns the number of bytes the scratch can hold given the
+// overrun factor, or 0 if it is not limited. The mutex of the storage must be
+// held.
+func (s *SSTSnapshotStorageScratch) sizeLimitLocked(overrunFactor float64) int64 {
+	if s.expectedBytes <= 0 || overrunFactor <= 0 {
+		return 0
+	}
+	limit := int64(float64(s.expectedBytes) * overrunFactor)
+	if min := s.expectedBytes + scratchExpectedBytesSlack; limit < min {
+		limit = min
+	}
+	return limit
+}
+
+// limiter returns the limiter pacing the writes of the scratch.
+func (s *SSTSnapshotStorageScratch) limiter() *quotapool.ChildRateLimiter {
+	if s.addSSTable && s.storage.addSSTableLimiter != nil {
+		return s.storage.addSSTableLimiter
+	}
+	if s.recovery && s.storage.recoveryLimiter != nil {
+		return s.storage.recoveryLimiter
+	}
+	return s.storage.limiter
+}
+
+// SetTenantID attributes the disk space used by the scratch to the given
+// tenant, which owns the range of the snapshot, in the per-tenant metrics of
+// the store. It is a no-op if the storage has no metrics, or if the scratch was
+// already attributed to a tenant.
+func (s *SSTSnapshotStorageScratch) SetTenantID(tenantID roachpb.TenantID) {
+	m := s.storage.metrics
+	if m == nil {
+		return
+	}
+	s.storage.mu.Lock()
+	defer s.storage.mu.Unlock()
+	if s.tenantMetrics != nil || s.closed {
+		return
+	}
+	s.tenantMetrics = m.acquireTenant(tenantID)
+	m.getTenant(context.TODO(), s.tenantMetrics).SnapshotScratchBytes.Inc(s.usedBytes)
+}
+
+// SetPaused pauses or resumes the writes to the scratches of the storage, for
+// instance to keep incoming snapshots from filling up a disk which is nearly
+// full while space is freed up. While paused, the writes block until they are
+// resumed or their context is canceled. The writes are also paused while the
+// kv.snapshot_receiver.scratch_writes.paused cluster setting is set.
+func (s *SSTSnapshotStorage) SetPaused(paused bool) {
+	s.updatePause(func() { s.pause.byStore = paused })
+}
+
+// setPausedBySetting pauses or resumes the writes to the scratches of the
+// storage on behalf of the cluster setting.
+func (s *SSTSnapshotStorage) setPausedBySetting(paused bool) {
+	s.updatePause(func() { s.pause.bySetting = paused })
+}
+
+// Paused returns whether the writes to the scratches of the storage are paused.
+func (s *SSTSnapshotStorage) Paused() bool {
+	s.pause.Lock()
+	defer s.pause.Unlock()
+	return s.pausedLocked()
+}
+
+func (s *SSTSnapshotStorage) pausedLocked() bool {
+	return s.pause.byStore || s.pause.bySetting
+}
+
+// updatePause applies the given update to the pause state, and releases the
+// waiting writes if they are resumed.
+func (s *SSTSnapshotStorage) updatePause(update func()) {
+	s.pause.Lock()
+	defer s.pause.Unlock()
+	wasPaused := s.pausedLocked()
+	update()
+	switch paused := s.pausedLocked(); {
+	case paused && !wasPaused:
+		s.pause.resumed = make(chan struct{})
+	case !paused && wasPaused:
+		close(s.pause.resumed)
+	}
+}
+
+// waitUnpaused blocks while the writes to the scratches are paused.
+func (s *SSTSnapshotStorage) waitUnpaused(ctx context.Context) error {
+	for {
+		s.pause.Lock()
+		paused, resumed := s.pausedLocked(), s.pause.resumed
+		s.pause.Unlock()
+		if !paused {
+			return nil
+		}
+		log.Eventf(ctx, "waiting for snapshot scratch writes to be resumed")
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "waiting for snapshot scratch writes to be resumed")
+		}
+	}
+}
+
+// snapshotProbeFilename is the name of the sentinel file of the probes of the
+// storage directory (see Probe), right below it.
+const snapshotProbeFilename = "probe"
+
+// Probe checks that the storage directory is usable, by creating, writing,
+// syncing and removing a sentinel file, so that a read-only or failing volume
+// is detected before the snapshots start failing. The outcome is recorded,
+// and the error returned by ProbeError until the next probe succeeds.
+func (s *SSTSnapshotStorage) Probe() error {
+	err := s.probe()
+	if err != nil {
+		err = errors.Wrapf(err, "probing snapshot storage %s", s.dir)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.probeErr = err
+	return err
+}
+
+func (s *SSTSnapshotStorage) probe() error {
+	if err := s.fs.MkdirAll(s.dir); err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, snapshotProbeFilename)
+	if err := s.beforeOp(ScratchFileCreate, path); err != nil {
+		return err
+	}
+	f, err := s.fs.Create(path)
+	if err != nil {
+		return err
+	}
+	err = func() error {
+		if err := s.beforeOp(ScratchFileWrite, path); err != nil {
+			return err
+		}
+		if _, err := f.Write([]byte(snapshotProbeFilename)); err != nil {
+			return err
+		}
+		if err := s.beforeOp(ScratchFileSync, path); err != nil {
+			return err
+		}
+		return f.Sync()
+	}()
+	err = errors.CombineErrors(err, f.Close())
+	return errors.CombineErrors(err, s.fs.Remove(path))
+}
+
+// ProbeError returns the error of the last probe of the storage directory, or
+// nil if it succeeded or no probe ran yet.
+func (s *SSTSnapshotStorage) ProbeError() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.probeErr
+}
+
+// UsedBytes returns the number of bytes written to the open scratches.
+func (s *SSTSnapshotStorage) UsedBytes() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.usedBytes
+}
+
+// scratchClosed is called when an SSTSnapshotStorageScratch created by this
+// SSTSnapshotStorage is closed. This method handles any cleanup of range
+// directories if all SSTSnapshotStorageScratches corresponding to a range
+// have closed.
+func (s *SSTSnapshotStorage) scratchClosed(scratch *SSTSnapshotStorageScratch) {
+	rangeID := scratch.rangeID
+	var trashed bool
+	defer func() {
+		if trashed {
+			s.emptyTrash()
+		}
+	}()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.usedBytes -= scratch.usedBytes
+	if s.budget != nil {
+		s.budget.release(scratch.usedBytes)
+	}
+	if s.metrics != nil {
+		s.metrics.SnapshotScratchUsedBytes.Dec(scratch.usedBytes)
+		s.metrics.SnapshotScratchActive.Dec(1)
+		if ref := scratch.tenantMetrics; ref != nil {
+			s.metrics.getTenant(context.TODO(), ref).SnapshotScratchBytes.Dec(scratch.usedBytes)
+			s.metrics.releaseTenant(context.TODO(), ref)
+			scratch.tenantMetrics = nil
+		}
+	}
+	scratch.usedBytes = 0
+	delete(s.mu.active, scratchKey{rangeID: rangeID, snapUUID: scratch.snapUUID})
+	val := s.mu.rangeRefCount[rangeID]
+	if val <= 0 {
+		panic("inconsistent scratch ref count")
+	}
+	val--
+	s.mu.rangeRefCount[rangeID] = val
+	if val == 0 {
+		delete(s.mu.rangeRefCount, rangeID)
+		// Suppressing an error here is okay, as orphaned directories are at worst
+		// a performance issue when we later walk directories in pebble.Capacity()
+		// but not a correctness issue. The directory is moved to the trash under
+		// the lock, so that no scratch of the range can be created in it in the
+		// meantime, but removed after the lock is released.
+		trashed = s.moveToTrash(snapshotRangeDir(s.dir, rangeID)) == nil
+	}
+}
+
+// SSTSnapshotStorageScratch keeps track of the SST files incrementally created
+// when receiving a snapshot. Each scratch is associated with a specific
+// snapshot.
+//
+// Several files of a scratch can be created and written to concurrently, for
+// example one per key span of the snapshot. Each file must only be used by a
+// single goroutine, and all the files must be closed before the scratch is.
+type SSTSnapshotStorageScratch struct {
+	// ctx is the context in whose trace the closing of the scratch is
+	// recorded.
+	ctx      context.Context
+	storage  *SSTSnapshotStorage
+	rangeID  roachpb.RangeID
+	snapUUID uuid.UUID
+	snapDir  string
+	closed   bool
+	// created is the time at which the scratch was created.
+	created time.Time
+	// usedBytes is the number of bytes written to the scratch, which are
+	// accounted for in the storage's usedBytes. It is protected by the mutex of
+	// the storage.
+	usedBytes int64
+	// expectedBytes, if positive, is the size of the snapshot staged in the
+	// scratch, as declared by its sender (see SetExpectedBytes). It is
+	// protected by the mutex of the storage.
+	expectedBytes int64
+	// resumable is set if the scratch records its complete SSTs in manifest,
+	// and persists it in the scratch directory.
+	resumable bool
+	// tenantMetrics, if set, references the metrics of the tenant owning the
+	// range, to which usedBytes are attributed. It is set and read under the
+	// mutex of the storage.
+	tenantMetrics *tenantMetricsRef
+	// recovery is set if the scratch stages a recovery snapshot, whose writes
+	// are paced by the recovery limiter of the storage.
+	recovery bool
+	// addSSTable is set if the scratch stages the SST of an AddSSTable command,
+	// whose writes are paced by the AddSSTable limiter of the storage.
+	addSSTable bool
+	// appliedBatchBytes is the size of the batch through which the SSTs were
+	// applied, if they were applied rather than ingested (see Ingest).
+	appliedBatchBytes int64
+
+	// mu protects the fields below, which are updated as files are added to
+	// the scratch and written to.
+	mu         syncutil.Mutex
+	ssts       []string
+	dirCreated bool
+	manifest   []ScratchManifestEntry
+	// info, if set, describes the snapshot staged in the scratch (see
+	// RecordInfo).
+	info *ScratchInfo
+	// stats are the statistics of the writes to the files of the scratch.
+	stats WriteSSTStats
+	// progress, if set, is invoked with stats every progressEvery bytes (see
+	// SetProgressFunc). progressNext is the number of bytes written at which
+	// it is invoked next.
+	progress      func(WriteSSTStats)
+	progressEvery int64
+	progressNext  int64
+	// pendingSyncs are the files whose syncs are batched, and which were
+	// written to since they were last synced (see SyncPending).
+	pendingSyncs []*SSTSnapshotStorageFile
+}
+
+// WriteSSTStats are statistics about the writes of SSTs to a scratch, meant to
+// be reported in the traces and logs of snapshot application.
+type WriteSSTStats struct {
+	// BytesWritten is the number of bytes written.
+	BytesWritten int64
+	// LimiterWait is the time spent waiting for the writes to be allowed by the
+	// rate limiters of the storage, or by admission control.
+	LimiterWait time.Duration
+	// SyncLatency is the time spent syncing the files.
+	SyncLatency time.Duration
+}
+
+// SafeFormat implements the redact.SafeFormatter interface.
+func (s WriteSSTStats) SafeFormat(w redact.SafePrinter, _ rune) {
+	w.Printf("written=%s limiterWait=%0.0fms sync=%0.0fms",
+		humanizeutil.IBytes(s.BytesWritten), s.LimiterWait.Seconds()*1000, s.SyncLatency.Seconds()*1000)
+}
+
+// String implements the fmt.Stringer interface.
+func (s WriteSSTStats) String() string {
+	return redact.StringWithoutMarkers(s)
+}
+
+func (s *WriteSSTStats) add(o WriteSSTStats) {
+	s.BytesWritten += o.BytesWritten
+	s.LimiterWait += o.LimiterWait
+	s.SyncLatency += o.SyncLatency
+}
+
+// scratchManifestFilename is the name of the file, in the directory of a
+// resumable scratch, holding its manifest.
+const scratchManifestFilename = "MANIFEST"
+
+// ScratchManifestEntry describes a complete SST of a resumable scratch.
+type ScratchManifestEntry struct {
+	// Name is the name of the file, relative to the scratch directory.
+	Name string
+	// Size is the size of the file.
+	Size int64
+	// Checksum is the checksum of the contents of the file, as computed by
+	// util.CRC32.
+	Checksum uint32
+}
+
+// scratchInfoFilename is the name of the file, in the directory of a scratch,
+// holding the description of the snapshot it stages (see ScratchInfo).
+const scratchInfoFilename = "SNAPSHOT"
+
+// ScratchInfo describes the snapshot staged in a scratch. It is persisted in
+// the scratch directory, so that the scratches found after a crash can be told
+// apart: those of the snapshots which were fully received, and were only
+// waiting to be applied, from those of the snapshots cut short.
+type ScratchInfo struct {
+	// SnapUUID is the UUID of the snapshot.
+	SnapUUID uuid.UUID
+	// Desc is the descriptor of the range in the snapshot.
+	Desc roachpb.RangeDescriptor
+	// KeySpans is the number of key spans of the snapshot, each of which is
+	// staged in at least one SST.
+	KeySpans int
+	// RangeSize is the size of the range reported by the sender, if known.
+	RangeSize int64
+	// Created is when the reception of the snapshot started.
+	Created time.Time
+	// Complete is set once the snapshot is fully received, that is all its
+	// SSTs are written and synced.
+	Complete bool
+	// Completed is when the snapshot was fully received.
+	Completed time.Time
+	// SSTs are the SSTs of the snapshot, once fully received.
+	SSTs []ScratchInfoSST
+}
+
+// ScratchInfoSST describes an SST of a fully received snapshot.
+type ScratchInfoSST struct {
+	// Name is the name of the file, relative to the scratch directory.
+	Name string
+	// Size is the size of the file.
+	Size int64
+}
+
+// state describes the progress of the snapshot, for logging.
+func (i *ScratchInfo) state() redact.SafeString {
+	switch {
+	case i == nil:
+		return "unknown progress"
+	case i.Complete:
+		return "fully received"
+	default:
+		return "partially received"
+	}
+}
+
+// RecordInfo persists the description of the snapshot staged in the scratch,
+// for crash diagnostics (see ScratchInfo). keySpans is the number of key spans
+// of the snapshot, and rangeSize the size of the range if known.
+func (s *SSTSnapshotStorageScratch) RecordInfo(
+	desc roachpb.RangeDescriptor, keySpans int, rangeSize int64,
+) error {
+	if err := s.ensureDir(); err != nil {
+		return markOutOfDiskError(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info = &ScratchInfo{
+		SnapUUID:  s.snapUUID,
+		Desc:      desc,
+		KeySpans:  keySpans,
+		RangeSize: rangeSize,
+		Created:   timeutil.Now(),
+	}
+	return s.writeInfo()
+}
+
+// RecordReceived records in the description of the snapshot staged in the
+// scratch that the snapshot was fully received, along with its SSTs. All the
+// files of the scratch must have been synced and closed. It is a no-op if the
+// description was not recorded with RecordInfo.
+func (s *SSTSnapshotStorageScratch) RecordReceived() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.info == nil {
+		return nil
+	}
+	ssts := make([]ScratchInfoSST, 0, len(s.ssts))
+	for _, sst := range s.ssts {
+		info, err := s.storage.fs.Stat(sst)
+		if err != nil {
+			return err
+		}
+		ssts = append(ssts, ScratchInfoSST{Name: filepath.Base(sst), Size: info.Size()})
+	}
+	s.info.Complete = true
+	s.info.Completed = timeutil.Now()
+	s.info.SSTs = ssts
+	return s.writeInfo()
+}
+
+// writeInfo atomically replaces the description of the snapshot in the
+// scratch directory.
+func (s *SSTSnapshotStorageScratch) writeInfo() error {
+	data, err := json.Marshal(s.info)
+	if err != nil {
+		return err
+	}
+	return markOutOfDiskError(s.writeFileAtomically(scratchInfoFilename, data))
+}
+
+// readScratchInfo returns the description of the snapshot staged in the given
+// scratch directory, or nil if there is none or it cannot be parsed.
+func readScratchInfo(fs fs.FS, snapDir string) (*ScratchInfo, error) {
+	f, err := fs.Open(filepath.Join(snapDir, scratchInfoFilename))
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	_ = f.Close()
+	if err != nil {
+		return nil, err
+	}
+	var info ScratchInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, nil //nolint:returnerrcheck
+	}
+	return &info, nil
+}
+
+// recordComplete adds a complete SST to the manifest of the scratch, and
+// persists the manifest. When files are written concurrently, they are
+// recorded in the order they complete, so a resumed scratch only keeps those
+// which completed in the order they were created.
+func (s *SSTSnapshotStorageScratch) recordComplete(f *SSTSnapshotStorageFile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manifest = append(s.manifest, ScratchManifestEntry{
+		Name:     filepath.Base(f.filename),
+		Size:     f.written,
+		Checksum: f.checksum,
+	})
+	return s.writeManifest()
+}
+
+// writeManifest atomically replaces the manifest in the scratch directory.
+func (s *SSTSnapshotStorageScratch) writeManifest() error {
+	data, err := json.Marshal(s.manifest)
+	if err != nil {
+		return err
+	}
+	return s.writeFileAtomically(scratchManifestFilename, data)
+}
+
+// writeFileAtomically atomically replaces the file with the given name in the
+// scratch directory with the given contents.
+func (s *SSTSnapshotStorageScratch) writeFileAtomically(name string, data []byte) error {
+	path := filepath.Join(s.snapDir, name)
+	tmpPath := path + ".tmp"
+	f, err := s.storage.fs.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := s.storage.fs.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	return s.syncDir()
+}
+
+// syncDir syncs the scratch directory, which persists the entries of the
+// files created in it.
+func (s *SSTSnapshotStorageScratch) syncDir() error {
+	dir, err := s.storage.fs.OpenDir(s.snapDir)
+	if err != nil {
+		return err
+	}
+	if err := dir.Sync(); err != nil {
+		_ = dir.Close()
+		return err
+	}
+	return dir.Close()
+}
+
+// recover restores the complete SSTs recorded in the manifest found in the
+// scratch directory, if any, and removes the other files. It returns the
+// restored SSTs.
+func (s *SSTSnapshotStorageScratch) recover(ctx context.Context) ([]ScratchManifestEntry, error) {
+	if err := s.moveLegacyDir(); err != nil {
+		return nil, err
+	}
+	mf, err := s.storage.fs.Open(filepath.Join(s.snapDir, scratchManifestFilename))
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return nil, s.storage.fs.RemoveAll(s.snapDir)
+		}
+		return nil, err
+	}
+	data, err := io.ReadAll(mf)
+	_ = mf.Close()
+	if err != nil {
+		return nil, err
+	}
+	var entries []ScratchManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.Warningf(ctx, "discarding unreadable manifest of snapshot scratch %s: %v", s.snapDir, err)
+		entries = nil
+	}
+	// Keep the longest prefix of SSTs which are intact and named in the order
+	// NewFile names them, so that the next files get fresh names.
+	keep := make(map[string]bool)
+	var restored []ScratchManifestEntry
+	for i, e := range entries {
+		filename := s.filename(i)
+		if e.Name != filepath.Base(filename) {
+			break
+		}
+		info, err := s.storage.fs.Stat(filename)
+		if err != nil {
+			if oserror.IsNotExist(err) {
+				break
+			}
+			return nil, err
+		}
+		if info.Size() != e.Size {
+			break
+		}
+		keep[e.Name] = true
+		restored = append(restored, e)
+	}
+	if len(restored) == 0 {
+		return nil, s.storage.fs.RemoveAll(s.snapDir)
+	}
+	keep[scratchManifestFilename] = true
+	keep[scratchInfoFilename] = true
+	names, err := s.storage.fs.List(s.snapDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if !keep[name] {
+			if err := s.storage.fs.RemoveAll(filepath.Join(s.snapDir, name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var size int64
+	for _, e := range restored {
+		size += e.Size
+	}
+	if err := s.storage.reserveBytes(s, size); err != nil {
+		return nil, err
+	}
+	for i := range restored {
+		s.ssts = append(s.ssts, s.filename(i))
+	}
+	s.manifest = restored
+	s.dirCreated = true
+	if len(restored) < len(entries) {
+		if err := s.writeManifest(); err != nil {
+			return nil, err
+		}
+	}
+	log.Infof(ctx, "resuming snapshot scratch %s with %d SSTs (%s)",
+		s.snapDir, len(restored), humanizeutil.IBytes(size))
+	return restored, nil
+}
+
+// moveLegacyDir moves the directory of the scratch from the legacy layout of
+// the storage (see legacySnapshotRangeDir), where a previous incarnation of the
+// store may have left it, to its place in the sharded layout, so that it can
+// be resumed. It is a no-op if there is no such directory.
+func (s *SSTSnapshotStorageScratch) moveLegacyDir() error {
+	legacyDir := filepath.Join(legacySnapshotRangeDir(s.storage.dir, s.rangeID), s.snapUUID.String())
+	if _, err := s.storage.fs.Stat(legacyDir); err != nil {
+		if oserror.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := s.storage.fs.MkdirAll(filepath.Dir(s.snapDir)); err != nil {
+		return err
+	}
+	return s.storage.fs.Rename(legacyDir, s.snapDir)
+}
+
+func (s *SSTSnapshotStorageScratch) filename(id int) string {
+	return filepath.Join(s.snapDir, fmt.Sprintf("%d.sst", id))
+}
+
+// ensureDir creates the directory of the scratch, if it was not already.
+func (s *SSTSnapshotStorageScratch) ensureDir() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dirCreated {
+		return nil
+	}
+	err := s.storage.fs.MkdirAll(s.snapDir)
+	s.dirCreated = err == nil
+	return err
+}
+
+// NewFile adds another file to SSTSnapshotStorageScratch. This file is lazily
+// created when the file is written to the first time. An error marked with
+// errMarkSnapshotScratchTooManyFiles is returned if the scratch already has the
+// maximum number of files. A nonzero value for
+// bytesPerSync will sync dirty data periodically as it is written, unless the
+// storage only syncs the files once fully written (see scratchSyncFinal). The
+// syncing does not provide persistency guarantees, but is used to smooth out
+// disk writes. Sync() must be called for data persistence.
+func (s *SSTSnapshotStorageScratch) NewFile(
+	ctx context.Context, bytesPerSync int64,
+) (*SSTSnapshotStorageFile, error) {
+	if s.closed {
+		return nil, errors.AssertionFailedf("SSTSnapshotStorageScratch closed")
+	}
+	if syncPolicy := s.storage.syncPolicy; syncPolicy != nil && syncPolicy() == scratchSyncFinal {
+		bytesPerSync = 0
+	}
+	var maxFiles int64
+	if s.storage.maxFiles != nil {
+		maxFiles = s.storage.maxFiles()
+	}
+	// The ID of the file is allocated under the lock, so that concurrent
+	// callers get distinct files.
+	s.mu.Lock()
+	id := len(s.ssts)
+	if maxFiles > 0 && int64(id) >= maxFiles {
+		s.mu.Unlock()
+		return nil, errors.Mark(errors.Errorf(
+			"snapshot scratch %s has reached the limit of %d files", s.snapDir, maxFiles),
+			errMarkSnapshotScratchTooManyFiles)
+	}
+	filename := s.filename(id)
+	s.ssts = append(s.ssts, filename)
+	s.mu.Unlock()
+	log.Eventf(ctx, "added snapshot scratch file %s", filename)
+	f := &SSTSnapshotStorageFile{
+		scratch:      s,
+		filename:     filename,
+		ctx:          ctx,
+		bytesPerSync: bytesPerSync,
+	}
+	if writeBufferSize := s.storage.writeBufferSize; writeBufferSize != nil {
+		if size := writeBufferSize(); size > 0 {
+			f.buf = make([]byte, 0, size)
+		}
+	}
+	return f, nil
+}
+
+// WriteSST writes SST data to a file. The method closes
+// the provided SST when it is finished using it. If the provided SST is empty,
+// then no file will be created and nothing will be written.
+func (s *SSTSnapshotStorageScratch) WriteSST(ctx context.Context, data []byte) error {
+	_, err := s.WriteSSTWithStats(ctx, data)
+	return err
+}
+
+// WriteSSTWithStats is like WriteSST, but also returns the statistics of the
+// write.
+func (s *SSTSnapshotStorageScratch) WriteSSTWithStats(
+	ctx context.Context, data []byte,
+) (WriteSSTStats, error) {
+	if s.closed {
+		return WriteSSTStats{}, errors.AssertionFailedf("SSTSnapshotStorageScratch closed")
+	}
+	if len(data) == 0 {
+		return WriteSSTStats{}, nil
+	}
+	f, err := s.NewFile(ctx, s.storage.fileBytesPerSync())
+	if err != nil {
+		return WriteSSTStats{}, err
+	}
+	defer func() {
+		// Closing an SSTSnapshotStorageFile multiple times is idempotent. Nothing
+		// actionable if closing fails.
+		_ = f.Close()
+	}()
+	if _, err := f.Write(data); err != nil {
+		return f.Stats(), err
+	}
+	if err := f.Sync(); err != nil {
+		return f.Stats(), err
+	}
+	return f.Stats(), f.Close()
+}
+
+// WriteSSTFrom writes an SST of the given size read from r to a new file,
+// without buffering it in memory in its entirety. The data is read and
+// written in chunks, each of which is paced by the limiter of the storage. It
+// is an error for r to hold less than size bytes; the bytes past size are not
+// read. If size is zero, no file is created and nothing is written.
+func (s *SSTSnapshotStorageScratch) WriteSSTFrom(
+	ctx context.Context, r io.Reader, size int64,
+) error {
+	if s.closed {
+		return errors.AssertionFailedf("SSTSnapshotStorageScratch closed")
+	}
+	if size == 0 {
+		return nil
+	}
+	f, err := s.NewFile(ctx, s.storage.fileBytesPerSync())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Closing an SSTSnapshotStorageFile multiple times is idempotent. Nothing
+		// actionable if closing fails.
+		_ = f.Close()
+	}()
+	bufSize := int64(bulkIOWriteBurst)
+	if size < bufSize {
+		bufSize = size
+	}
+	buf := make([]byte, bufSize)
+	for remaining := size; remaining > 0; {
+		chunk := buf
+		if remaining < int64(len(chunk)) {
+			chunk = chunk[:remaining]
+		}
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return errors.Wrapf(err, "reading SST after %d of %d bytes", size-remaining, size)
+		}
+		if _, err := f.Write(chunk); err != nil {
+			return err
+		}
+		remaining -= int64(len(chunk))
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// SSTs returns the names of the files created.
+func (s *SSTSnapshotStorageScratch) SSTs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ssts
+}
+
+// OpenFile opens the given file of the scratch, as listed by SSTs(), for
+// reading. The file must be closed before the scratch is.
+func (s *SSTSnapshotStorageScratch) OpenFile(path string) (fs.File, error) {
+	if s.closed {
+		return nil, errors.AssertionFailedf("SSTSnapshotStorageScratch closed")
+	}
+	found := false
+	for _, sst := range s.SSTs() {
+		if sst == path {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.Errorf("%s is not a completed SST of snapshot scratch %s", path, s.snapDir)
+	}
+	return s.storage.fs.Open(path)
+}
+
+// NewSSTIterator returns an iterator over the given SST of the scratch, as
+// listed by SSTs(), so that its contents can be validated before it is
+// ingested. The iterator must be closed before the scratch is.
+func (s *SSTSnapshotStorageScratch) NewSSTIterator(
+	path string, opts storage.IterOptions,
+) (storage.MVCCIterator, error) {
+	f, err := s.openSST(path)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := storage.NewPebbleSSTIterator([][]sstable.ReadableFile{{f}}, opts, false /* forwardOnly */)
+	if err != nil {
+		_ = f.Close()
+		return nil, errors.Wrapf(err, "opening %s", path)
+	}
+	return iter, nil
+}
+
+// openSST opens the given SST of the scratch, as listed by SSTs(), for
+// reading by an iterator.
+func (s *SSTSnapshotStorageScratch) openSST(path string) (sstable.ReadableFile, error) {
+	f, err := s.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.storage.fs.Stat(path)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return scratchSSTFile{File: f, info: info}, nil
+}
+
+// ValidateBounds returns an assertion error if the keys of one of the SSTs of
+// the scratch don't all fall within one of the given spans, so that the bugs
+// of the senders are caught before the SSTs are ingested. Only the point and
+// range keys are checked: the range deletions are written by the receiver.
+func (s *SSTSnapshotStorageScratch) ValidateBounds(spans []roachpb.Span) error {
+	for _, path := range s.SSTs() {
+		bounds, ok, err := s.sstBounds(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading the bounds of %s", path)
+		}
+		if !ok {
+			continue
+		}
+		contained := false
+		for _, span := range spans {
+			if span.Contains(bounds) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return errors.AssertionFailedf(
+				"SST %s of the snapshot has keys in %s, outside of the spans %s", path, bounds, spans)
+		}
+	}
+	return nil
+}
+
+// sstBounds returns the span of the point and range keys of the given SST of
+// the scratch, or false if it has none.
+func (s *SSTSnapshotStorageScratch) sstBounds(path string) (roachpb.Span, bool, error) {
+	f, err := s.openSST(path)
+	if err != nil {
+		return roachpb.Span{}, false, err
+	}
+	iter, err := storage.NewSSTEngineIterator([][]sstable.ReadableFile{{f}}, storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypePointsAndRanges,
+		LowerBound: roachpb.KeyMin,
+		UpperBound: roachpb.KeyMax,
+	}, false /* forwardOnly */)
+	if err != nil {
+		_ = f.Close()
+		return roachpb.Span{}, false, err
+	}
+	defer iter.Close()
+
+	// keyBounds returns the span of the point and range keys at the current
+	// position. The span remains valid once the iterator is repositioned.
+	keyBounds := func() (roachpb.Span, error) {
+		var bounds roachpb.Span
+		hasPoint, hasRange := iter.HasPointAndRange()
+		if hasPoint {
+			key, err := iter.UnsafeEngineKey()
+			if err != nil {
+				return roachpb.Span{}, err
+			}
+			bounds = roachpb.Span{Key: key.Key, EndKey: key.Key.Next()}
+		}
+		if hasRange {
+			rangeBounds, err := iter.EngineRangeBounds()
+			if err != nil {
+				return roachpb.Span{}, err
+			}
+			if hasPoint {
+				rangeBounds = bounds.Combine(rangeBounds)
+			}
+			bounds = rangeBounds
+		}
+		return roachpb.Span{Key: bounds.Key.Clone(), EndKey: bounds.EndKey.Clone()}, nil
+	}
+	valid, err := iter.SeekEngineKeyGE(storage.EngineKey{Key: roachpb.KeyMin})
+	if err != nil || !valid {
+		return roachpb.Span{}, false, err
+	}
+	first, err := keyBounds()
+	if err != nil {
+		return roachpb.Span{}, false, err
+	}
+	valid, err = iter.SeekEngineKeyLT(storage.EngineKey{Key: roachpb.KeyMax})
+	if err != nil {
+		return roachpb.Span{}, false, err
 	}
-}
-
-// Clear removes all created directories and SSTs.
-func (s *SSTSnapshotStorage) Clear() error {
-	return s.engine.RemoveAll(s.dir)
-}
-
-// scratchClosed is called when an SSTSnapshotStorageScratch created by this
-// SSTSnapshotStorage is closed. This method handles any cleanup of range
-// directories if all SSTSnapshotStorageScratches corresponding to a range
-// have closed.
-func (s *SSTSnapshotStorage) scratchClosed(rangeID roachpb.RangeID) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	val := s.mu.rangeRefCount[rangeID]
-	if val <= 0 {
-		panic("inconsistent scratch ref count")
+	if !valid {
+		return roachpb.Span{}, false, errors.AssertionFailedf("no last key despite a first one")
 	}
-	val--
-	s.mu.rangeRefCount[rangeID] = val
-	if val == 0 {
-		delete(s.mu.rangeRefCount, rangeID)
-		// Suppressing an error here is okay, as orphaned directories are at worst
-		// a performance issue when we later walk directories in pebble.Capacity()
-		// but not a correctness issue.
-		_ = s.engine.RemoveAll(filepath.Join(s.dir, strconv.Itoa(int(rangeID))))
+	last, err := keyBounds()
+	if err != nil {
+		return roachpb.Span{}, false, err
 	}
+	return first.Combine(last), true, nil
 }
 
-// SSTSnapshotStorageScratch keeps track of the SST files incrementally created
-// when receiving a snapshot. Each scratch is associated with a specific
-// snapshot.
-type SSTSnapshotStorageScratch struct {
-	storage    *SSTSnapshotStorage
-	rangeID    roachpb.RangeID
-	ssts       []string
-	snapDir    string
-	dirCreated bool
-	closed     bool
+// scratchSSTFile is an SST of a scratch opened for reading.
+type scratchSSTFile struct {
+	fs.File
+	info os.FileInfo
 }
 
-func (s *SSTSnapshotStorageScratch) filename(id int) string {
-	return filepath.Join(s.snapDir, fmt.Sprintf("%d.sst", id))
-}
+var _ sstable.ReadableFile = scratchSSTFile{}
 
-func (s *SSTSnapshotStorageScratch) createDir() error {
-	err := s.storage.engine.MkdirAll(s.snapDir)
-	s.dirCreated = s.dirCreated || err == nil
-	return err
+// Stat implements the sstable.ReadableFile interface.
+func (f scratchSSTFile) Stat() (os.FileInfo, error) {
+	return f.info, nil
 }
 
-// NewFile adds another file to SSTSnapshotStorageScratch. This file is lazily
-// created when the file is written to the first time. A nonzero value for
-// bytesPerSync will sync dirty data periodically as it is written. The syncing
-// does not provide persistency guarantees, but is used to smooth out disk
-// writes. Sync() must be called for data persistence.
-func (s *SSTSnapshotStorageScratch) NewFile(
-	ctx context.Context, bytesPerSync int64,
-) (*SSTSnapshotStorageFile, error) {
-	if s.closed {
-		return nil, errors.AssertionFailedf("SSTSnapshotStorageScratch closed")
+// Ingest atomically applies the SSTs of the scratch to the given engine. The
+// SSTs are ingested, unless the scratch holds no more than the storage's
+// applyAsBatchMaxBytes and the spans cleared by the SSTs are given: their keys
+// are then written through a synced batch, which avoids the overhead of an
+// ingestion, such as flushing an overlapping memtable, for nearly empty
+// ranges. It returns whether the SSTs were ingested, along with the stats of
+// the ingestion.
+//
+// The SSTs may only delete keys through range deletions of the cleared spans:
+// their point deletions are not applied by the batch. Callers which can't
+// tell the cleared spans pass nil, and the SSTs are always ingested.
+func (s *SSTSnapshotStorageScratch) Ingest(
+	ctx context.Context, eng storage.Engine, clearedSpans []roachpb.Span,
+) (bool, pebble.IngestOperationStats, error) {
+	ssts := s.SSTs()
+	if clearedSpans == nil || !s.fitsBatch() {
+		stats, err := eng.IngestExternalFilesWithStats(ctx, ssts)
+		if err != nil {
+			return false, pebble.IngestOperationStats{}, errors.Wrapf(err, "while ingesting %s", ssts)
+		}
+		return true, stats, nil
 	}
-	id := len(s.ssts)
-	filename := s.filename(id)
-	s.ssts = append(s.ssts, filename)
-	f := &SSTSnapshotStorageFile{
-		scratch:      s,
-		filename:     filename,
-		ctx:          ctx,
-		bytesPerSync: bytesPerSync,
+	if err := s.applyAsBatch(eng, ssts, clearedSpans); err != nil {
+		return false, pebble.IngestOperationStats{}, errors.Wrapf(err, "while applying %s as a batch", ssts)
 	}
-	return f, nil
+	return false, pebble.IngestOperationStats{}, nil
 }
 
-// WriteSST writes SST data to a file. The method closes
-// the provided SST when it is finished using it. If the provided SST is empty,
-// then no file will be created and nothing will be written.
-func (s *SSTSnapshotStorageScratch) WriteSST(ctx context.Context, data []byte) error {
-	if s.closed {
-		return errors.AssertionFailedf("SSTSnapshotStorageScratch closed")
+// fitsBatch returns whether the SSTs of the scratch are small enough to be
+// applied through a batch.
+func (s *SSTSnapshotStorageScratch) fitsBatch() bool {
+	if s.storage.applyAsBatchMaxBytes == nil {
+		return false
 	}
-	if len(data) == 0 {
-		return nil
+	maxBytes := s.storage.applyAsBatchMaxBytes()
+	return maxBytes > 0 && s.Stats().BytesWritten <= maxBytes
+}
+
+// applyAsBatch writes the keys of the given SSTs of the scratch to the engine
+// through a synced batch, after clearing the given spans.
+func (s *SSTSnapshotStorageScratch) applyAsBatch(
+	eng storage.Engine, ssts []string, clearedSpans []roachpb.Span,
+) error {
+	// Each SST is given its own level. The SSTs don't overlap, but the range
+	// deletions of an SST may reach the bounds of the next one.
+	files := make([][]sstable.ReadableFile, 0, len(ssts))
+	closeFiles := func() {
+		for _, level := range files {
+			_ = level[0].Close()
+		}
+	}
+	for _, path := range ssts {
+		f, err := s.openSST(path)
+		if err != nil {
+			closeFiles()
+			return err
+		}
+		files = append(files, []sstable.ReadableFile{f})
 	}
-	f, err := s.NewFile(ctx, 512<<10 /* 512 KB */)
+	iter, err := storage.NewSSTEngineIterator(files, storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypePointsAndRanges,
+		LowerBound: roachpb.KeyMin,
+		UpperBound: roachpb.KeyMax,
+	}, true /* forwardOnly */)
 	if err != nil {
+		closeFiles()
 		return err
 	}
-	defer func() {
-		// Closing an SSTSnapshotStorageFile multiple times is idempotent. Nothing
-		// actionable if closing fails.
-		_ = f.Close()
-	}()
-	if _, err := f.Write(data); err != nil {
+	defer iter.Close()
+
+	batch := eng.NewUnindexedBatch(true /* writeOnly */)
+	defer batch.Close()
+	for _, span := range clearedSpans {
+		if err := batch.ClearRawRange(
+			span.Key, span.EndKey, true /* pointKeys */, true, /* rangeKeys */
+		); err != nil {
+			return err
+		}
+	}
+	valid, err := iter.SeekEngineKeyGE(storage.EngineKey{Key: roachpb.KeyMin})
+	for ; valid; valid, err = iter.NextEngineKey() {
+		hasPoint, hasRange := iter.HasPointAndRange()
+		if hasPoint {
+			key, err := iter.UnsafeEngineKey()
+			if err != nil {
+				return err
+			}
+			if err := batch.PutEngineKey(key, iter.UnsafeValue()); err != nil {
+				return err
+			}
+		}
+		if hasRange && iter.RangeKeyChanged() {
+			bounds, err := iter.EngineRangeBounds()
+			if err != nil {
+				return err
+			}
+			for _, rkv := range iter.EngineRangeKeys() {
+				if err := batch.PutEngineRangeKey(bounds.Key, bounds.EndKey, rkv.Version, rkv.Value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err != nil {
 		return err
 	}
-	if err := f.Sync(); err != nil {
+	if err := batch.Commit(true /* sync */); err != nil {
 		return err
 	}
-	return f.Close()
+	s.appliedBatchBytes = int64(batch.Len())
+	return nil
 }
 
-// SSTs returns the names of the files created.
-func (s *SSTSnapshotStorageScratch) SSTs() []string {
-	return s.ssts
+// Stats returns the statistics of the writes to all the files of the scratch.
+func (s *SSTSnapshotStorageScratch) Stats() WriteSSTStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// ETA estimates how long it will take to write the given number of remaining
+// bytes to the scratch. It is based on the write throughput of the scratch
+// since it was created, bounded by the current rate limits of the storage, or
+// on these limits alone until the first bytes are written. It returns false if
+// the time can't be estimated, that is if nothing was written yet and the
+// rate is not limited.
+func (s *SSTSnapshotStorageScratch) ETA(remaining int64) (time.Duration, bool) {
+	if remaining <= 0 {
+		return 0, true
+	}
+	rate := s.WriteRate()
+	if written, elapsed := s.Stats().BytesWritten, timeutil.Since(s.created); written > 0 && elapsed > 0 {
+		rate = math.Min(rate, float64(written)/elapsed.Seconds())
+	}
+	if math.IsInf(rate, 1) || rate <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(remaining) / rate * float64(time.Second)), true
+}
+
+// WriteRate returns the rate (bytes/sec) at which the scratch can currently be
+// written to, that is the lowest of the bulk IO write budget of the store and
+// of the limit put on the snapshot writes while the disk is saturated. It
+// returns +Inf if the writes are not limited.
+func (s *SSTSnapshotStorageScratch) WriteRate() float64 {
+	rate := math.Inf(1)
+	if l := s.limiter(); l != nil {
+		rate = math.Min(rate, float64(l.Limit()))
+	}
+	if c := s.storage.writeRate; c != nil {
+		rate = math.Min(rate, c.currentRate())
+	}
+	return rate
+}
+
+// SetProgressFunc registers a function invoked with the cumulative statistics
+// of the writes to the scratch every time another `every` bytes have been
+// written, so that the progress of the snapshot can be reported without
+// wrapping the writers. The function is invoked by the goroutine writing to
+// the scratch, without holding any lock, and must not block. A nil function
+// unregisters it.
+func (s *SSTSnapshotStorageScratch) SetProgressFunc(every int64, fn func(WriteSSTStats)) {
+	if every <= 0 {
+		every = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress = fn
+	s.progressEvery = every
+	s.progressNext = (s.stats.BytesWritten/every + 1) * every
 }
 
 // Close removes the directory and SSTs created for a particular snapshot.
@@ -178,8 +2094,50 @@ func (s *SSTSnapshotStorageScratch) Close() error {
 		return nil
 	}
 	s.closed = true
-	defer s.storage.scratchClosed(s.rangeID)
-	return s.storage.engine.RemoveAll(s.snapDir)
+	defer s.storage.scratchClosed(s)
+	s.closePendingFiles()
+	log.Eventf(s.ctx, "closing snapshot scratch %s with %d SSTs (%s)", s.snapDir, len(s.SSTs()), s.Stats())
+	return s.removeAll()
+}
+
+// removeAll removes the directory of the scratch, by moving it to the trash of
+// the storage.
+func (s *SSTSnapshotStorageScratch) removeAll() error {
+	if err := s.storage.moveToTrash(s.snapDir); err != nil {
+		return err
+	}
+	s.storage.emptyTrash()
+	return nil
+}
+
+// Abort is like Close, for a scratch whose snapshot failed. If keepFiles is
+// set, the SSTs written so far are moved to the quarantine directory of the
+// storage rather than removed, so that the failed snapshot can be inspected.
+// This is a no-op if the storage does not keep aborted scratches, in which
+// case the SSTs are removed. The scratch is closed in any case.
+func (s *SSTSnapshotStorageScratch) Abort(keepFiles bool) error {
+	if s.closed {
+		return nil
+	}
+	var maxQuarantined int64
+	if s.storage.maxQuarantined != nil {
+		maxQuarantined = s.storage.maxQuarantined()
+	}
+	s.mu.Lock()
+	dirCreated := s.dirCreated
+	s.mu.Unlock()
+	if !keepFiles || maxQuarantined <= 0 || !dirCreated {
+		return s.Close()
+	}
+	s.closed = true
+	defer s.storage.scratchClosed(s)
+	s.closePendingFiles()
+	log.Eventf(s.ctx, "quarantining snapshot scratch %s with %d SSTs (%s)", s.snapDir, len(s.SSTs()), s.Stats())
+	if err := s.storage.quarantine(s, maxQuarantined); err != nil {
+		// Don't leave the SSTs behind if they could not be quarantined.
+		return errors.CombineErrors(err, s.removeAll())
+	}
+	return nil
 }
 
 // SSTSnapshotStorageFile is an SST file managed by a
@@ -191,8 +2149,27 @@ type SSTSnapshotStorageFile struct {
 	filename     string
 	ctx          context.Context
 	bytesPerSync int64
+	// written is the number of bytes written to the file.
+	written int64
+	// checksum is the rolling CRC32 of the contents written to the file.
+	checksum uint32
+	// synced is set if the file was synced since it was last written to.
+	synced bool
+	// stats are the statistics of the writes to the file.
+	stats WriteSSTStats
+	// buf, if allocated, holds the contents written to the file which are yet
+	// to be written out to disk. Its capacity is the size of the buffer.
+	buf []byte
+	// batchSync is set if the syncs of the file are batched with those of the
+	// other files of the scratch (see SetBatchSync). syncPending is set while
+	// the file awaits such a sync, and closePending if it was closed meanwhile.
+	batchSync, syncPending, closePending bool
 }
 
+// sstChunkCRCTable is the table of the checksums verified by WriteChecked,
+// which are the Castagnoli CRC32 computed by util.CRC32.
+var sstChunkCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
 func (f *SSTSnapshotStorageFile) ensureFile() error {
 	if f.created {
 		if f.file == nil {
@@ -200,41 +2177,278 @@ func (f *SSTSnapshotStorageFile) ensureFile() error {
 		}
 		return nil
 	}
-	if !f.scratch.dirCreated {
-		if err := f.scratch.createDir(); err != nil {
-			return err
-		}
+	if err := f.scratch.ensureDir(); err != nil {
+		return err
 	}
 	if f.scratch.closed {
 		return errors.AssertionFailedf("SSTSnapshotStorageScratch closed")
 	}
-	var err error
-	if f.bytesPerSync > 0 {
-		f.file, err = f.scratch.storage.engine.CreateWithSync(f.filename, int(f.bytesPerSync))
-	} else {
-		f.file, err = f.scratch.storage.engine.Create(f.filename)
-	}
-	if err != nil {
+	start := timeutil.Now()
+	if err := f.retryTransient("creating", func() (err error) {
+		if err := f.scratch.storage.beforeOp(ScratchFileCreate, f.filename); err != nil {
+			return err
+		}
+		if efs, ok := f.scratch.storage.fs.(fs.EncryptedFS); ok {
+			// The SSTs hold user data, and must be encrypted if the store is. This
+			// fails rather than creating a plaintext file on an encrypted store.
+			f.file, err = efs.CreateEncrypted(f.filename, int(f.bytesPerSync))
+		} else if f.bytesPerSync > 0 {
+			f.file, err = f.scratch.storage.fs.CreateWithSync(f.filename, int(f.bytesPerSync))
+		} else {
+			f.file, err = f.scratch.storage.fs.Create(f.filename)
+		}
+		return err
+	}); err != nil {
 		return err
 	}
 	f.created = true
+	if m := f.scratch.storage.metrics; m != nil {
+		m.SnapshotScratchFilesCreated.Inc(1)
+		m.SnapshotScratchCreateLatency.RecordValue(timeutil.Since(start).Nanoseconds())
+	}
 	return nil
 }
 
+// scratchWriteChunkSize is the size of the chunks in which the contents passed
+// to SSTSnapshotStorageFile.Write are written, checking for the cancellation
+// of the snapshot in between.
+const scratchWriteChunkSize = 1 << 20 // 1 MiB
+
 // Write writes contents to the file while respecting the limiter passed into
 // SSTSnapshotStorageScratch. Writing empty contents is okay and is treated as
-// a noop. The file must have not been closed.
+// a noop. The file must have not been closed. The write fails if it would
+// take the scratches of the storage over their maximum size.
+//
+// The contents may be held in the write buffer of the file until it is full,
+// so that the many small writes of the stream of a snapshot are coalesced into
+// large sequential ones. The buffer is written out by Sync and Close, which
+// can thus fail with the error of a write.
+//
+// The write stops early if the context of the file is canceled, in which case
+// the file is closed and removed, since the snapshot was torn down.
 func (f *SSTSnapshotStorageFile) Write(contents []byte) (int, error) {
+	n, err := f.write(contents)
+	if err != nil && f.ctx.Err() != nil {
+		f.removePartial()
+	}
+	return n, err
+}
+
+func (f *SSTSnapshotStorageFile) write(contents []byte) (int, error) {
 	if len(contents) == 0 {
 		return 0, nil
 	}
+	if err := f.scratch.storage.waitUnpaused(f.ctx); err != nil {
+		return 0, err
+	}
 	if err := f.ensureFile(); err != nil {
 		return 0, err
 	}
-	if err := limitBulkIOWrite(f.ctx, f.scratch.storage.limiter, len(contents)); err != nil {
+	if err := f.scratch.storage.reserveBytes(f.scratch, int64(len(contents))); err != nil {
 		return 0, err
 	}
-	return f.file.Write(contents)
+	waitStart := timeutil.Now()
+	var admitted bool
+	if admit := f.scratch.storage.admit; admit != nil {
+		var err error
+		if admitted, err = admit(f.ctx, int64(len(contents))); err != nil {
+			return 0, errors.Wrapf(err, "error admitting snapshot write")
+		}
+	}
+	if !admitted {
+		wait, err := limitBulkIOWrite(f.ctx, f.scratch.limiter(), len(contents))
+		if m := f.scratch.storage.metrics; m != nil {
+			m.SnapshotScratchBulkIOWriteWait.Inc(wait.Nanoseconds())
+		}
+		if err != nil {
+			return 0, err
+		}
+		if c := f.scratch.storage.writeRate; c != nil {
+			if err := c.waitN(f.ctx, int64(len(contents))); err != nil {
+				return 0, errors.Wrapf(err, "error rate limiting snapshot write")
+			}
+		}
+	}
+	if err := f.retryTransient("writing", func() error {
+		return f.scratch.storage.beforeOp(ScratchFileWrite, f.filename)
+	}); err != nil {
+		return 0, markOutOfDiskError(err)
+	}
+	writeStart := timeutil.Now()
+	n, err := f.writeBuffered(contents)
+	writeLatency := timeutil.Since(writeStart)
+	err = markOutOfDiskError(err)
+	f.recordStats(WriteSSTStats{BytesWritten: int64(n), LimiterWait: timeutil.Since(waitStart)})
+	f.written += int64(n)
+	f.checksum = crc32.Update(f.checksum, sstChunkCRCTable, contents[:n])
+	f.synced = false
+	if m := f.scratch.storage.metrics; m != nil {
+		m.SnapshotScratchBytesWritten.Inc(int64(n))
+		m.SnapshotScratchWriteLatency.RecordValue(writeLatency.Nanoseconds())
+	}
+	return n, err
+}
+
+// writeChunks writes contents to the file in chunks of at most
+// scratchWriteChunkSize bytes, and stops with the error of the context of the
+// file as soon as it is canceled.
+func (f *SSTSnapshotStorageFile) writeChunks(contents []byte) (int, error) {
+	var n int
+	for n < len(contents) {
+		if err := f.ctx.Err(); err != nil {
+			return n, errors.Wrapf(err, "writing %s", f.filename)
+		}
+		chunk := contents[n:]
+		if len(chunk) > scratchWriteChunkSize {
+			chunk = chunk[:scratchWriteChunkSize]
+		}
+		// A retry writes what remains of the chunk after a partial write.
+		if err := f.retryTransient("writing", func() error {
+			m, err := f.file.Write(chunk)
+			n += m
+			chunk = chunk[m:]
+			return err
+		}); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// writeBuffered appends contents to the write buffer of the file, which is
+// written out to the file first if they don't fit in it. Contents as large as
+// the buffer, or all contents if the file has no buffer, are written directly.
+func (f *SSTSnapshotStorageFile) writeBuffered(contents []byte) (int, error) {
+	if len(f.buf)+len(contents) > cap(f.buf) {
+		if err := f.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if len(contents) >= cap(f.buf) {
+		return f.writeChunks(contents)
+	}
+	f.buf = append(f.buf, contents...)
+	return len(contents), nil
+}
+
+// flush writes out the contents of the write buffer of the file. On error, the
+// contents which were not written are kept in the buffer.
+func (f *SSTSnapshotStorageFile) flush() error {
+	if len(f.buf) == 0 {
+		return nil
+	}
+	n, err := f.writeChunks(f.buf)
+	f.buf = f.buf[:copy(f.buf, f.buf[n:])]
+	return markOutOfDiskError(err)
+}
+
+// scratchRetryOptions are the options of the retries of the operations on the
+// files of the scratches which fail with a transient error.
+var scratchRetryOptions = retry.Options{
+	InitialBackoff: 10 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Multiplier:     2,
+	MaxRetries:     5,
+}
+
+// isTransientFSError returns whether err is a filesystem error which is
+// expected to go away by itself, such as an interrupted system call, or a
+// timeout of a network filesystem hosting an external scratch directory.
+func isTransientFSError(err error) bool {
+	return errors.Is(err, syscall.EINTR) ||
+		errors.Is(err, syscall.EAGAIN) ||
+		errors.Is(err, syscall.ETIMEDOUT)
+}
+
+// retryTransient runs op, and runs it again with a backoff while it fails
+// with a transient filesystem error, so that a momentary blip doesn't fail
+// the whole snapshot. It gives up after scratchRetryOptions.MaxRetries
+// retries, or once the context of the file is canceled, and returns the last
+// error of op.
+//
+// Syncs are not retried: once a sync failed, the dirty pages it was flushing
+// may have been dropped by the kernel, so that a successful retry would not
+// mean that the data is durable.
+func (f *SSTSnapshotStorageFile) retryTransient(opName string, op func() error) error {
+	var err error
+	for r := retry.StartWithCtx(f.ctx, scratchRetryOptions); r.Next(); {
+		if err = op(); err == nil || !isTransientFSError(err) {
+			return err
+		}
+		log.Eventf(f.ctx, "retrying after a transient error %s %s: %v", opName, f.filename, err)
+	}
+	return err
+}
+
+// removePartial closes and removes the file, once the snapshot it belongs to
+// was torn down while it was being written. The file stays listed in SSTs(),
+// so the scratch must not be ingested. A failure is only traced, since the
+// file is removed along with the scratch anyway.
+func (f *SSTSnapshotStorageFile) removePartial() {
+	if !f.created {
+		return
+	}
+	f.buf = nil
+	if f.file != nil {
+		_ = f.file.Close()
+		f.file = nil
+	}
+	if err := f.scratch.storage.fs.Remove(f.filename); err != nil && !oserror.IsNotExist(err) {
+		log.Eventf(f.ctx, "error removing partially written %s: %v", f.filename, err)
+		return
+	}
+	log.Eventf(f.ctx, "removed partially written %s (%s written)",
+		f.filename, humanizeutil.IBytes(f.written))
+}
+
+// WriteChecked is like Write, but first verifies that contents match the
+// given checksum, as computed by util.CRC32 on the sender. Nothing is written
+// on a mismatch, so that data corrupted in transit fails the snapshot before
+// it is ingested.
+func (f *SSTSnapshotStorageFile) WriteChecked(contents []byte, checksum uint32) (int, error) {
+	if actual := crc32.Checksum(contents, sstChunkCRCTable); actual != checksum {
+		return 0, errors.Errorf(
+			"checksum mismatch for the %d bytes at offset %d of %s: expected %08x, computed %08x",
+			len(contents), f.written, f.filename, checksum, actual)
+	}
+	return f.Write(contents)
+}
+
+// preallocator is implemented by the files which can reserve disk space ahead
+// of the writes, such as the files of Pebble's default filesystem.
+type preallocator interface {
+	// Preallocate reserves the disk space for length bytes from the given
+	// offset, without changing the size of the file.
+	Preallocate(offset, length int64) error
+}
+
+// Preallocate reserves the disk space for the given number of bytes of the
+// file, creating it if needed, when the filesystem supports it (e.g. with
+// fallocate on Linux). This avoids fragmenting the file, and makes a lack of
+// disk space fail right away rather than halfway through the writes. The size
+// of the file is not changed, and the bytes already written are not
+// preallocated again. It is a no-op on filesystems without support for
+// preallocation.
+func (f *SSTSnapshotStorageFile) Preallocate(size int64) error {
+	if size <= f.written {
+		return nil
+	}
+	if err := f.ensureFile(); err != nil {
+		return err
+	}
+	p, ok := f.file.(preallocator)
+	if !ok {
+		return nil
+	}
+	// The buffered contents are not on disk yet.
+	offset := f.written - int64(len(f.buf))
+	return errors.Wrapf(p.Preallocate(offset, size-offset), "preallocating %s", f.filename)
+}
+
+// Checksum returns the checksum of the contents written to the file, as
+// computed by util.CRC32.
+func (f *SSTSnapshotStorageFile) Checksum() uint32 {
+	return f.checksum
 }
 
 // Close closes the file. Calling this function multiple times is idempotent.
@@ -248,14 +2462,192 @@ func (f *SSTSnapshotStorageFile) Close() error {
 	if f.file == nil {
 		return nil
 	}
+	if err := f.flush(); err != nil {
+		_ = f.file.Close()
+		f.file = nil
+		return err
+	}
+	if f.syncPending {
+		// The file is closed once synced by SyncPending.
+		f.closePending = true
+		return nil
+	}
+	return f.close()
+}
+
+// close closes the file, and records it in the manifest of the scratch if it
+// is complete.
+func (f *SSTSnapshotStorageFile) close() error {
 	if err := f.file.Close(); err != nil {
 		return err
 	}
 	f.file = nil
+	log.Eventf(f.ctx, "closed %s (%s)", f.filename, f.stats)
+	if f.scratch.resumable && f.synced {
+		return f.scratch.recordComplete(f)
+	}
 	return nil
 }
 
 // Sync syncs the file to disk. Implements writeCloseSyncer in engine.
+//
+// If the syncs of the file are batched, the file is only synced by the next
+// SyncPending call of its scratch, and is left open until then if closed.
 func (f *SSTSnapshotStorageFile) Sync() error {
-	return f.file.Sync()
+	if err := f.flush(); err != nil {
+		return err
+	}
+	if f.batchSync {
+		if !f.syncPending {
+			f.syncPending = true
+			s := f.scratch
+			s.mu.Lock()
+			s.pendingSyncs = append(s.pendingSyncs, f)
+			s.mu.Unlock()
+		}
+		return nil
+	}
+	return f.sync()
+}
+
+// SetBatchSync batches the syncs of the file with those of the other files of
+// its scratch, which are synced together by SyncPending once they are all
+// written, rather than one after the other as they are finished. This avoids
+// a storm of syncs on slow disks when a snapshot is staged in many files.
+func (f *SSTSnapshotStorageFile) SetBatchSync() {
+	f.batchSync = true
+}
+
+// sync syncs the file to disk.
+func (f *SSTSnapshotStorageFile) sync() error {
+	if err := f.scratch.storage.beforeOp(ScratchFileSync, f.filename); err != nil {
+		return err
+	}
+	start := timeutil.Now()
+	err := markOutOfDiskError(f.file.Sync())
+	syncLatency := timeutil.Since(start)
+	f.recordStats(WriteSSTStats{SyncLatency: syncLatency})
+	if err != nil {
+		return err
+	}
+	log.Eventf(f.ctx, "synced %s (%s written) in %0.0fms",
+		f.filename, humanizeutil.IBytes(f.written), syncLatency.Seconds()*1000)
+	f.synced = true
+	if m := f.scratch.storage.metrics; m != nil {
+		m.SnapshotScratchSyncs.Inc(1)
+		m.SnapshotScratchSyncLatency.RecordValue(syncLatency.Nanoseconds())
+	}
+	if dropPageCache := f.scratch.storage.dropPageCache; dropPageCache != nil && dropPageCache() {
+		f.dropPageCache()
+	}
+	return nil
+}
+
+// SyncPending syncs the files of the scratch whose syncs are batched and which
+// were written to since they were last synced (see
+// SSTSnapshotStorageFile.SetBatchSync). The files are synced concurrently,
+// then the scratch directory once, and those which were closed are closed.
+func (s *SSTSnapshotStorageScratch) SyncPending(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pendingSyncs
+	s.pendingSyncs = nil
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	start := timeutil.Now()
+	g := ctxgroup.WithContext(ctx)
+	for _, f := range pending {
+		f := f
+		g.GoCtx(func(ctx context.Context) error {
+			return f.sync()
+		})
+	}
+	err := g.Wait()
+	if err == nil {
+		err = s.syncDir()
+	}
+	if err != nil {
+		// The files are closed along with the scratch.
+		s.mu.Lock()
+		s.pendingSyncs = append(s.pendingSyncs, pending...)
+		s.mu.Unlock()
+		return err
+	}
+	log.Eventf(ctx, "synced %d files of snapshot scratch %s in %0.0fms",
+		len(pending), s.snapDir, timeutil.Since(start).Seconds()*1000)
+	for _, f := range pending {
+		f.syncPending = false
+		if f.closePending {
+			f.closePending = false
+			err = errors.CombineErrors(err, f.close())
+		}
+	}
+	return err
+}
+
+// closePendingFiles closes the files whose sync is pending, which are left
+// open by their Close, without syncing them.
+func (s *SSTSnapshotStorageScratch) closePendingFiles() {
+	s.mu.Lock()
+	pending := s.pendingSyncs
+	s.pendingSyncs = nil
+	s.mu.Unlock()
+	for _, f := range pending {
+		if f.file != nil {
+			_ = f.file.Close()
+			f.file = nil
+		}
+	}
+}
+
+// fdGetter is implemented by the files backed by a file descriptor, such as
+// the files of Pebble's default filesystem.
+type fdGetter interface {
+	Fd() uintptr
+}
+
+// dropPageCache advises the OS to evict the pages of the file from its page
+// cache. The file must have been synced, since only clean pages are evicted.
+// This is best effort: the pages stay cached if the file is not backed by a
+// file descriptor, and a failure is only traced.
+func (f *SSTSnapshotStorageFile) dropPageCache() {
+	d, ok := f.file.(fdGetter)
+	if !ok {
+		return
+	}
+	if err := sysutil.DropPageCache(d.Fd(), 0, 0); err != nil {
+		log.Eventf(f.ctx, "failed to drop %s from the page cache: %v", f.filename, err)
+	}
+}
+
+// markOutOfDiskError marks the error of a write to a scratch file as a
+// snapshot receiver out of disk error if it was caused by the disk of the
+// store being full, so that the snapshot is rejected with a retryable error.
+func markOutOfDiskError(err error) error {
+	if err == nil || !errors.Is(err, syscall.ENOSPC) {
+		return err
+	}
+	return errors.Mark(errors.Wrap(err, snapshotReceiverOutOfDiskMsg), errMarkSnapshotReceiverOutOfDisk)
+}
+
+// Stats returns the statistics of the writes to the file.
+func (f *SSTSnapshotStorageFile) Stats() WriteSSTStats {
+	return f.stats
+}
+
+// recordStats accounts for the given statistics in the file and its scratch.
+func (f *SSTSnapshotStorageFile) recordStats(stats WriteSSTStats) {
+	f.stats.add(stats)
+	s := f.scratch
+	s.mu.Lock()
+	s.stats.add(stats)
+	progress, total := s.progress, s.stats
+	if progress == nil || total.BytesWritten < s.progressNext {
+		s.mu.Unlock()
+		return
+	}
+	s.progressNext = (total.BytesWritten/s.progressEvery + 1) * s.progressEvery
+	s.mu.Unlock()
+	progress(total)
 }
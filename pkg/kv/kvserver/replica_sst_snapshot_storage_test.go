@@ -10,24 +10,44 @@
 package kvserver
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"hash/crc32"
 	io "io"
+	"math"
+	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rditer"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/errors/oserror"
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/time/rate"
 )
 
 func TestSSTSnapshotStorage(t *testing.T) {
@@ -37,17 +57,18 @@ func TestSSTSnapshotStorage(t *testing.T) {
 	ctx := context.Background()
 	testRangeID := roachpb.RangeID(1)
 	testSnapUUID := uuid.Must(uuid.FromBytes([]byte("foobar1234567890")))
-	testLimiter := rate.NewLimiter(rate.Inf, 0)
+	testLimiter := unlimitedBulkIOWriteLimiter()
 
 	cleanup, eng := newOnDiskEngine(ctx, t)
 	defer cleanup()
 	defer eng.Close()
 
 	sstSnapshotStorage := NewSSTSnapshotStorage(eng, testLimiter)
-	scratch := sstSnapshotStorage.NewScratchSpace(testRangeID, testSnapUUID)
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, testRangeID, testSnapUUID)
+	require.NoError(t, err)
 
 	// Check that the storage lazily creates the directories on first write.
-	_, err := eng.Stat(scratch.snapDir)
+	_, err = eng.Stat(scratch.snapDir)
 	if !oserror.IsNotExist(err) {
 		t.Fatalf("expected %s to not exist", scratch.snapDir)
 	}
@@ -104,7 +125,7 @@ func TestSSTSnapshotStorage(t *testing.T) {
 	if !oserror.IsNotExist(err) {
 		t.Fatalf("expected %s to not exist", scratch.snapDir)
 	}
-	rangeDir := filepath.Join(sstSnapshotStorage.dir, strconv.Itoa(int(scratch.rangeID)))
+	rangeDir := snapshotRangeDir(sstSnapshotStorage.dir, scratch.rangeID)
 	_, err = eng.Stat(rangeDir)
 	if !oserror.IsNotExist(err) {
 		t.Fatalf("expected %s to not exist", rangeDir)
@@ -124,7 +145,7 @@ func TestSSTSnapshotStorageConcurrentRange(t *testing.T) {
 	testRangeID := roachpb.RangeID(1)
 	testSnapUUID := uuid.Must(uuid.FromBytes([]byte("foobar1234567890")))
 	testSnapUUID2 := uuid.Must(uuid.FromBytes([]byte("foobar2345678910")))
-	testLimiter := rate.NewLimiter(rate.Inf, 0)
+	testLimiter := unlimitedBulkIOWriteLimiter()
 
 	cleanup, eng := newOnDiskEngine(ctx, t)
 	defer cleanup()
@@ -133,10 +154,11 @@ func TestSSTSnapshotStorageConcurrentRange(t *testing.T) {
 	sstSnapshotStorage := NewSSTSnapshotStorage(eng, testLimiter)
 
 	runForSnap := func(snapUUID uuid.UUID) error {
-		scratch := sstSnapshotStorage.NewScratchSpace(testRangeID, snapUUID)
+		scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, testRangeID, snapUUID)
+		require.NoError(t, err)
 
 		// Check that the storage lazily creates the directories on first write.
-		_, err := eng.Stat(scratch.snapDir)
+		_, err = eng.Stat(scratch.snapDir)
 		if !oserror.IsNotExist(err) {
 			return errors.Errorf("expected %s to not exist", scratch.snapDir)
 		}
@@ -215,7 +237,7 @@ func TestSSTSnapshotStorageConcurrentRange(t *testing.T) {
 	}
 	// Ensure that the range directory was deleted after the scratches were
 	// closed.
-	rangeDir := filepath.Join(sstSnapshotStorage.dir, strconv.Itoa(int(testRangeID)))
+	rangeDir := snapshotRangeDir(sstSnapshotStorage.dir, testRangeID)
 	_, err := eng.Stat(rangeDir)
 	if !oserror.IsNotExist(err) {
 		t.Fatalf("expected %s to not exist", rangeDir)
@@ -235,7 +257,7 @@ func TestSSTSnapshotStorageContextCancellation(t *testing.T) {
 
 	testRangeID := roachpb.RangeID(1)
 	testSnapUUID := uuid.Must(uuid.FromBytes([]byte("foobar1234567890")))
-	testLimiter := rate.NewLimiter(rate.Inf, 0)
+	testLimiter := unlimitedBulkIOWriteLimiter()
 
 	ctx := context.Background()
 	cleanup, eng := newOnDiskEngine(ctx, t)
@@ -243,7 +265,8 @@ func TestSSTSnapshotStorageContextCancellation(t *testing.T) {
 	defer eng.Close()
 
 	sstSnapshotStorage := NewSSTSnapshotStorage(eng, testLimiter)
-	scratch := sstSnapshotStorage.NewScratchSpace(testRangeID, testSnapUUID)
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, testRangeID, testSnapUUID)
+	require.NoError(t, err)
 
 	var cancel func()
 	ctx, cancel = context.WithCancel(ctx)
@@ -273,14 +296,15 @@ func TestMultiSSTWriterInitSST(t *testing.T) {
 	ctx := context.Background()
 	testRangeID := roachpb.RangeID(1)
 	testSnapUUID := uuid.Must(uuid.FromBytes([]byte("foobar1234567890")))
-	testLimiter := rate.NewLimiter(rate.Inf, 0)
+	testLimiter := unlimitedBulkIOWriteLimiter()
 
 	cleanup, eng := newOnDiskEngine(ctx, t)
 	defer cleanup()
 	defer eng.Close()
 
 	sstSnapshotStorage := NewSSTSnapshotStorage(eng, testLimiter)
-	scratch := sstSnapshotStorage.NewScratchSpace(testRangeID, testSnapUUID)
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, testRangeID, testSnapUUID)
+	require.NoError(t, err)
 	desc := roachpb.RangeDescriptor{
 		StartKey: roachpb.RKey("d"),
 		EndKey:   roachpb.RKeyMax,
@@ -323,3 +347,2168 @@ func TestMultiSSTWriterInitSST(t *testing.T) {
 		require.Equal(t, actualSSTs[i], expectedSSTs[i])
 	}
 }
+
+// TestStageSSTForIngestion checks that an AddSSTable payload staged for
+// ingestion is written in full to the scratch space, and removed along with
+// it.
+// TestMultiSSTWriterCompression checks that the SSTs of snapshots are written
+// with the configured compression.
+func TestMultiSSTWriterCompression(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	desc := roachpb.RangeDescriptor{
+		StartKey: roachpb.RKey("d"),
+		EndKey:   roachpb.RKeyMax,
+	}
+	keySpans := rditer.MakeReplicatedKeySpans(&desc)
+	// writeSSTs returns the total size of the SSTs of a snapshot of compressible
+	// data written with the given compression.
+	writeSSTs := func(compression pebble.Compression) int64 {
+		st := cluster.MakeTestingClusterSettings()
+		snapshotScratchCompression.Override(ctx, &st.SV, int64(compression))
+		scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+		require.NoError(t, err)
+		defer func() { require.NoError(t, scratch.Close()) }()
+		msstw, err := newMultiSSTWriter(ctx, st, scratch, keySpans, 0 /* sstChunkSize */)
+		require.NoError(t, err)
+		defer msstw.Close()
+		value := bytes.Repeat([]byte("a"), 1000)
+		for i := 0; i < 100; i++ {
+			key := storage.EngineKey{Key: roachpb.Key(fmt.Sprintf("d%03d", i))}
+			require.NoError(t, msstw.Put(ctx, key, value))
+		}
+		_, err = msstw.Finish(ctx)
+		require.NoError(t, err)
+		var size int64
+		for _, sst := range scratch.SSTs() {
+			info, err := eng.Stat(sst)
+			require.NoError(t, err)
+			size += info.Size()
+		}
+		return size
+	}
+
+	uncompressed := writeSSTs(pebble.NoCompression)
+	require.Less(t, writeSSTs(pebble.SnappyCompression), uncompressed)
+	require.Less(t, writeSSTs(pebble.ZstdCompression), uncompressed)
+}
+// TestMultiSSTWriterRolling checks that the keys of a key span are written to
+// several SSTs once the target size is reached, and that the SSTs can be
+// ingested together.
+func TestMultiSSTWriterRolling(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+	desc := roachpb.RangeDescriptor{
+		StartKey: roachpb.RKey("d"),
+		EndKey:   roachpb.RKeyMax,
+	}
+	keySpans := rditer.MakeReplicatedKeySpans(&desc)
+
+	st := cluster.MakeTestingClusterSettings()
+	snapshotSSTTargetSize.Override(ctx, &st.SV, 1<<10)
+	msstw, err := newMultiSSTWriter(ctx, st, scratch, keySpans, 0 /* sstChunkSize */)
+	require.NoError(t, err)
+	defer msstw.Close()
+	value := bytes.Repeat([]byte("a"), 100)
+	for i := 0; i < 100; i++ {
+		// Each key has several versions, which must not be split across SSTs.
+		for ts := 3; ts > 0; ts-- {
+			mvccKey := storage.MVCCKey{
+				Key:       roachpb.Key(fmt.Sprintf("d%03d", i)),
+				Timestamp: hlc.Timestamp{WallTime: int64(ts)},
+			}
+			key, ok := storage.DecodeEngineKey(storage.EncodeMVCCKey(mvccKey))
+			require.True(t, ok)
+			require.NoError(t, msstw.Put(ctx, key, value))
+		}
+	}
+	// The range keys come after the point keys of the span, and are split
+	// among its SSTs.
+	require.NoError(t, msstw.PutRangeKey(ctx, roachpb.Key("d010"), roachpb.Key("d090"),
+		storage.EncodeMVCCTimestampSuffix(hlc.Timestamp{WallTime: 10}), nil))
+	_, err = msstw.Finish(ctx)
+	require.NoError(t, err)
+	require.Greater(t, len(scratch.SSTs()), len(keySpans))
+
+	require.NoError(t, eng.IngestExternalFiles(ctx, scratch.SSTs()))
+	iter := eng.NewMVCCIterator(storage.MVCCKeyIterKind, storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypePointsAndRanges,
+		LowerBound: roachpb.Key("d"),
+		UpperBound: roachpb.KeyMax,
+	})
+	defer iter.Close()
+	var points int
+	var rangeKeys []roachpb.Span
+	for iter.SeekGE(storage.MVCCKey{Key: roachpb.Key("d")}); ; iter.Next() {
+		ok, err := iter.Valid()
+		require.NoError(t, err)
+		if !ok {
+			break
+		}
+		hasPoint, hasRange := iter.HasPointAndRange()
+		if hasPoint {
+			points++
+		}
+		if hasRange {
+			bounds := iter.RangeBounds()
+			if len(rangeKeys) == 0 || !rangeKeys[len(rangeKeys)-1].Equal(bounds) {
+				rangeKeys = append(rangeKeys, bounds.Clone())
+			}
+		}
+	}
+	require.Equal(t, 300, points)
+	require.Equal(t, []roachpb.Span{{Key: roachpb.Key("d010"), EndKey: roachpb.Key("d090")}}, rangeKeys)
+}
+
+
+func TestStageSSTForIngestion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sss := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sss.NewScratchSpace(ctx, roachpb.RangeID(1), uuid.MakeV4())
+	require.NoError(t, err)
+
+	// Use a payload spanning several write chunks.
+	data := make([]byte, 3*bulkIOWriteBurst+17)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	require.NoError(t, stageSSTForIngestion(ctx, cluster.MakeTestingClusterSettings(), scratch, data))
+	require.Len(t, scratch.SSTs(), 1)
+	staged, err := eng.ReadFile(scratch.SSTs()[0])
+	require.NoError(t, err)
+	require.Equal(t, data, staged)
+
+	require.NoError(t, scratch.Close())
+	_, err = eng.Stat(scratch.snapDir)
+	require.True(t, oserror.IsNotExist(err))
+}
+
+// TestSSTSnapshotStorageClearOrphaned checks that ClearOrphaned removes the
+// directories of the ranges without any scratch open, and only those.
+func TestSSTSnapshotStorageClearOrphaned(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	reclaimed, err := sstSnapshotStorage.ClearOrphaned()
+	require.NoError(t, err)
+	require.Zero(t, reclaimed)
+
+	// Range 1 has a snapshot in flight.
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+
+	// Range 2 was left behind, e.g. by a crash.
+	orphanedDir := filepath.Join(sstSnapshotStorage.dir, "2", uuid.MakeV4().String())
+	require.NoError(t, eng.MkdirAll(orphanedDir))
+	require.NoError(t, fs.WriteFile(eng, filepath.Join(orphanedDir, "0.sst"), []byte("foobar")))
+
+	reclaimed, err = sstSnapshotStorage.ClearOrphaned()
+	require.NoError(t, err)
+	require.Equal(t, int64(6), reclaimed)
+	_, err = eng.Stat(orphanedDir)
+	require.True(t, oserror.IsNotExist(err))
+	_, err = eng.Stat(scratch.SSTs()[0])
+	require.NoError(t, err)
+
+	require.NoError(t, scratch.Close())
+
+	// No scratch can be created for a range while its orphaned directory is
+	// being removed.
+	done := make(chan struct{})
+	sstSnapshotStorage.mu.Lock()
+	sstSnapshotStorage.mu.clearing[3] = done
+	sstSnapshotStorage.mu.Unlock()
+	created := make(chan *SSTSnapshotStorageScratch)
+	go func() {
+		scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 3, uuid.MakeV4())
+		if err != nil {
+			panic(err)
+		}
+		created <- scratch
+	}()
+	select {
+	case <-created:
+		t.Fatal("scratch created while the range was being cleared")
+	case <-time.After(10 * time.Millisecond):
+	}
+	sstSnapshotStorage.mu.Lock()
+	delete(sstSnapshotStorage.mu.clearing, 3)
+	sstSnapshotStorage.mu.Unlock()
+	close(done)
+	require.NoError(t, (<-created).Close())
+}
+
+// TestSSTSnapshotStorageClearExpired checks that ClearExpired removes the
+// scratches which were not written to for longer than the TTL, except those
+// which are open.
+func TestSSTSnapshotStorageClearExpired(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	reclaimed, err := sstSnapshotStorage.ClearExpired(timeutil.Now(), time.Hour)
+	require.NoError(t, err)
+	require.Zero(t, reclaimed)
+
+	// Range 1 has a snapshot in flight, and a scratch left behind by another
+	// snapshot.
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	leakedDir := filepath.Join(sstSnapshotStorage.dir, "1", uuid.MakeV4().String())
+	require.NoError(t, eng.MkdirAll(leakedDir))
+	require.NoError(t, fs.WriteFile(eng, filepath.Join(leakedDir, "0.sst"), []byte("foobar")))
+
+	// Nothing has expired yet.
+	reclaimed, err = sstSnapshotStorage.ClearExpired(timeutil.Now(), time.Hour)
+	require.NoError(t, err)
+	require.Zero(t, reclaimed)
+	_, err = eng.Stat(leakedDir)
+	require.NoError(t, err)
+
+	// Only the leaked scratch is removed once expired.
+	reclaimed, err = sstSnapshotStorage.ClearExpired(timeutil.Now().Add(2*time.Hour), time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, int64(len("foobar")), reclaimed)
+	_, err = eng.Stat(leakedDir)
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+	_, err = eng.Stat(scratch.SSTs()[0])
+	require.NoError(t, err)
+	require.Empty(t, sstSnapshotStorage.mu.clearing)
+	require.NoError(t, scratch.Close())
+}
+
+// TestSSTSnapshotStorageClearLiveScratch checks that Clear only removes the
+// directories of the ranges without an open scratch.
+func TestSSTSnapshotStorageClearLiveScratch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	orphanedDir := filepath.Join(sstSnapshotStorage.dir, "2", uuid.MakeV4().String())
+	require.NoError(t, eng.MkdirAll(orphanedDir))
+
+	require.NoError(t, sstSnapshotStorage.Clear())
+	_, err = eng.Stat(orphanedDir)
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+	_, err = eng.Stat(scratch.SSTs()[0])
+	require.NoError(t, err)
+	// The scratch can still be written to.
+	require.NoError(t, scratch.WriteSST(ctx, []byte("bar")))
+
+	// Once the scratch is closed, the whole storage is removed.
+	require.NoError(t, scratch.Close())
+	require.NoError(t, sstSnapshotStorage.Clear())
+	_, err = eng.Stat(sstSnapshotStorage.dir)
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+}
+
+// TestSSTSnapshotStorageDuplicateScratch checks that a snapshot cannot have
+// two open scratches, and that a new scratch can be created for it once the
+// previous one is closed.
+func TestSSTSnapshotStorageDuplicateScratch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	snapUUID := uuid.MakeV4()
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, snapUUID)
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+
+	_, err = sstSnapshotStorage.NewScratchSpace(ctx, 1, snapUUID)
+	require.True(t, testutils.IsError(err, "already has an open scratch"), "%v", err)
+	_, _, err = sstSnapshotStorage.ResumeScratchSpace(ctx, 1, snapUUID)
+	require.True(t, testutils.IsError(err, "already has an open scratch"), "%v", err)
+	// The failed attempts did not take a reference on the range, nor touched
+	// the SSTs of the open scratch.
+	require.Equal(t, 1, sstSnapshotStorage.mu.rangeRefCount[1])
+	_, err = eng.Stat(scratch.SSTs()[0])
+	require.NoError(t, err)
+
+	// Other snapshots of the range are not affected.
+	other, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, other.Close())
+
+	require.NoError(t, scratch.Close())
+	scratch, err = sstSnapshotStorage.NewScratchSpace(ctx, 1, snapUUID)
+	require.NoError(t, err)
+	require.NoError(t, scratch.Close())
+}
+// TestSSTSnapshotStorageOutOfDisk checks that a snapshot whose scratch fills
+// up the disk fails with a retryable error, and that its data is not kept.
+func TestSSTSnapshotStorageOutOfDisk(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	sstSnapshotStorage.maxQuarantined = func() int64 { return 1 }
+	diskFull := false
+	sstSnapshotStorage.beforeFileOp = func(op ScratchFileOp, path string) error {
+		if diskFull && op == ScratchFileWrite {
+			return &os.PathError{Op: "write", Path: path, Err: syscall.ENOSPC}
+		}
+		return nil
+	}
+
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	diskFull = true
+	writeErr := scratch.WriteSST(ctx, []byte("bar"))
+	require.True(t, isSnapshotReceiverOutOfDiskError(writeErr), "%v", writeErr)
+	require.True(t, testutils.IsError(writeErr, snapshotReceiverOutOfDiskMsg), "%v", writeErr)
+
+	// The scratch is removed rather than quarantined.
+	ss := &kvBatchSnapshotStrategy{scratch: scratch}
+	ss.Abort(ctx, writeErr)
+	_, err = eng.Stat(scratch.snapDir)
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+	_, err = eng.Stat(snapshotQuarantineDir(sstSnapshotStorage.dir))
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+	require.Zero(t, sstSnapshotStorage.mu.usedBytes)
+
+	// The sender sees the failure as retryable.
+	msg := writeErr.Error()
+	sendErr := markSnapshotResponseError(errors.Newf("remote failed: %s", msg), msg)
+	require.True(t, isSnapshotReceiverOutOfDiskError(sendErr))
+	require.True(t, IsRetriableReplicationChangeError(sendErr))
+	require.False(t, IsRetriableReplicationChangeError(
+		markSnapshotResponseError(errors.New("remote failed: boom"), "boom")))
+}
+
+
+// TestSSTSnapshotStorageAbort checks that aborted scratches are kept in the
+// quarantine directory, up to the configured number, when asked to.
+func TestSSTSnapshotStorageAbort(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	quarantineDir := snapshotQuarantineDir(sstSnapshotStorage.dir)
+	var maxQuarantined int64
+	sstSnapshotStorage.maxQuarantined = func() int64 { return maxQuarantined }
+	abort := func(rangeID roachpb.RangeID, keepFiles bool) *SSTSnapshotStorageScratch {
+		scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, rangeID, uuid.MakeV4())
+		require.NoError(t, err)
+		require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+		require.NoError(t, scratch.Abort(keepFiles))
+		_, err = eng.Stat(scratch.snapDir)
+		require.True(t, oserror.IsNotExist(err), "%v", err)
+		return scratch
+	}
+	quarantined := func() []string {
+		names, err := eng.List(quarantineDir)
+		if oserror.IsNotExist(err) {
+			return nil
+		}
+		require.NoError(t, err)
+		sort.Strings(names)
+		return names
+	}
+
+	// Nothing is kept unless the storage is configured to keep aborted
+	// scratches, and the caller asks for it.
+	abort(1, true /* keepFiles */)
+	require.Empty(t, quarantined())
+	maxQuarantined = 2
+	abort(1, false /* keepFiles */)
+	require.Empty(t, quarantined())
+
+	var scratches []*SSTSnapshotStorageScratch
+	for i := 1; i <= 3; i++ {
+		scratches = append(scratches, abort(roachpb.RangeID(i), true /* keepFiles */))
+	}
+	// Only the last two are kept, along with their SSTs.
+	names := quarantined()
+	require.Len(t, names, 2)
+	for i, name := range names {
+		scratch := scratches[i+1]
+		require.True(t, strings.HasSuffix(name, fmt.Sprintf(".r%d.%s", scratch.rangeID, scratch.snapUUID)), name)
+		f, err := eng.Open(filepath.Join(quarantineDir, name, "0.sst"))
+		require.NoError(t, err)
+		data, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		require.Equal(t, []byte("foo"), data)
+	}
+	// The aborted scratches are closed.
+	require.Zero(t, sstSnapshotStorage.mu.usedBytes)
+	require.Empty(t, sstSnapshotStorage.mu.rangeRefCount)
+	require.NoError(t, scratches[0].Close())
+}
+
+// TestSSTSnapshotStorageMaxBytes checks that the writes which would take the
+// scratches over the configured maximum size fail, and that the space is
+// released when the scratches are closed.
+func TestSSTSnapshotStorageMaxBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	maxBytes := int64(10)
+	sstSnapshotStorage.maxBytes = func() int64 { return maxBytes }
+
+	scratch1, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch1.WriteSST(ctx, []byte("foobar")))
+	require.Equal(t, int64(6), sstSnapshotStorage.UsedBytes())
+
+	// A second snapshot cannot go over the limit.
+	scratch2, err := sstSnapshotStorage.NewScratchSpace(ctx, 2, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch2.WriteSST(ctx, []byte("foo")))
+	err = scratch2.WriteSST(ctx, []byte("foo"))
+	require.True(t, testutils.IsError(err, "snapshot scratch space exhausted"), "%v", err)
+	require.Equal(t, int64(9), sstSnapshotStorage.UsedBytes())
+
+	// Closing a scratch releases its space.
+	require.NoError(t, scratch1.Close())
+	require.Equal(t, int64(3), sstSnapshotStorage.UsedBytes())
+	require.NoError(t, scratch2.WriteSST(ctx, []byte("foo")))
+	require.NoError(t, scratch2.Close())
+	require.Zero(t, sstSnapshotStorage.UsedBytes())
+
+	// Without a limit, any write goes through.
+	maxBytes = 0
+	scratch3, err := sstSnapshotStorage.NewScratchSpace(ctx, 3, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch3.WriteSST(ctx, make([]byte, 100)))
+	require.NoError(t, scratch3.Close())
+}
+
+// TestSSTSnapshotStorageClearLeftovers checks that the scratches left behind
+// by a crash are found and removed.
+func TestSSTSnapshotStorageClearLeftovers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	leftovers, err := sstSnapshotStorage.ScanLeftovers()
+	require.NoError(t, err)
+	require.Empty(t, leftovers)
+
+	snapUUID := uuid.MakeV4().String()
+	snapDir := filepath.Join(sstSnapshotStorage.dir, "1", snapUUID)
+	require.NoError(t, eng.MkdirAll(snapDir))
+	require.NoError(t, fs.WriteFile(eng, filepath.Join(snapDir, "0.sst"), []byte("foo")))
+	require.NoError(t, fs.WriteFile(eng, filepath.Join(snapDir, "1.sst"), []byte("bar")))
+	emptyRangeDir := filepath.Join(sstSnapshotStorage.dir, "2")
+	require.NoError(t, eng.MkdirAll(emptyRangeDir))
+
+	leftovers, err = sstSnapshotStorage.ScanLeftovers()
+	require.NoError(t, err)
+	require.Equal(t, []LeftoverScratch{
+		{Dir: snapDir, RangeID: 1, SnapUUID: snapUUID, Bytes: 6},
+		{Dir: emptyRangeDir, RangeID: 2},
+	}, leftovers)
+
+	reclaimed, err := sstSnapshotStorage.ClearLeftovers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(6), reclaimed)
+	_, err = eng.Stat(sstSnapshotStorage.dir)
+	require.True(t, oserror.IsNotExist(err))
+}
+
+// TestSSTSnapshotStorageMetrics checks that the storage keeps the store
+// metrics up to date.
+func TestSSTSnapshotStorageMetrics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	m := newStoreMetrics(time.Minute)
+	sstSnapshotStorage.metrics = m
+
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), m.SnapshotScratchActive.Value())
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foobar")))
+	require.Equal(t, int64(9), m.SnapshotScratchBytesWritten.Count())
+	require.Equal(t, int64(2), m.SnapshotScratchFilesCreated.Count())
+	require.Equal(t, int64(2), m.SnapshotScratchSyncs.Count())
+	require.Equal(t, int64(2), m.SnapshotScratchWriteLatency.TotalCount())
+	require.Equal(t, int64(2), m.SnapshotScratchSyncLatency.TotalCount())
+	require.Equal(t, int64(2), m.SnapshotScratchCreateLatency.TotalCount())
+	require.Equal(t, int64(9), m.SnapshotScratchUsedBytes.Value())
+
+	require.NoError(t, scratch.Close())
+	require.Zero(t, m.SnapshotScratchActive.Value())
+	require.Zero(t, m.SnapshotScratchUsedBytes.Value())
+	require.Equal(t, int64(9), m.SnapshotScratchBytesWritten.Count())
+
+	// The space used by a scratch is attributed to its tenant, including the
+	// bytes written before the tenant is set.
+	tenantID := roachpb.MakeTenantID(10)
+	scratch, err = sstSnapshotStorage.NewScratchSpace(ctx, 2, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	scratch.SetTenantID(tenantID)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foobar")))
+	require.Equal(t, int64(9), m.SnapshotScratchBytes.Value())
+	_, ok := m.tenants.Load(int64(tenantID.ToUint64()))
+	require.True(t, ok)
+	require.NoError(t, scratch.Close())
+	require.Zero(t, m.SnapshotScratchBytes.Value())
+	_, ok = m.tenants.Load(int64(tenantID.ToUint64()))
+	require.False(t, ok)
+}
+
+// TestSSTSnapshotStorageWriteSSTFrom checks that an SST can be streamed to a
+// scratch from a reader.
+func TestSSTSnapshotStorageWriteSSTFrom(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, scratch.Close())
+	}()
+
+	// Use a payload spanning several write chunks, followed by bytes which
+	// must not be consumed.
+	data := make([]byte, 3*bulkIOWriteBurst+17)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	r := bytes.NewReader(append(data, "trailer"...))
+	require.NoError(t, scratch.WriteSSTFrom(ctx, r, int64(len(data))))
+	require.Equal(t, int64(len("trailer")), int64(r.Len()))
+	require.Len(t, scratch.SSTs(), 1)
+	staged, err := eng.ReadFile(scratch.SSTs()[0])
+	require.NoError(t, err)
+	require.Equal(t, data, staged)
+
+	// An empty SST creates no file.
+	require.NoError(t, scratch.WriteSSTFrom(ctx, bytes.NewReader(nil), 0))
+	require.Len(t, scratch.SSTs(), 1)
+
+	// A short reader fails the write.
+	err = scratch.WriteSSTFrom(ctx, bytes.NewReader([]byte("foo")), 10)
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF), "%v", err)
+}
+
+// TestSSTSnapshotStorageWriteSSTWithStats checks that the statistics of the
+// writes are returned, and accumulated in the scratch.
+func TestSSTSnapshotStorageWriteSSTWithStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	stats, err := scratch.WriteSSTWithStats(ctx, []byte("foo"))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), stats.BytesWritten)
+	require.NotZero(t, stats.SyncLatency)
+	empty, err := scratch.WriteSSTWithStats(ctx, nil)
+	require.NoError(t, err)
+	require.Zero(t, empty)
+
+	f, err := scratch.NewFile(ctx, 0 /* bytesPerSync */)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("foobar"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.Equal(t, int64(6), f.Stats().BytesWritten)
+	require.Zero(t, f.Stats().SyncLatency)
+
+	total := scratch.Stats()
+	require.Equal(t, int64(9), total.BytesWritten)
+	require.Equal(t, stats.SyncLatency, total.SyncLatency)
+	require.Contains(t, total.String(), "written=9 B")
+}
+
+// TestSSTSnapshotStorageTracing checks that the lifecycle of a scratch is
+// traced.
+func TestSSTSnapshotStorageTracing(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	tr := tracing.NewTracer()
+	ctx, getRecording := tracing.ContextWithRecordingSpan(ctx, tr, "test")
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	require.NoError(t, scratch.Close())
+
+	rec := getRecording().String()
+	for _, msg := range []string{
+		"created snapshot scratch",
+		"added snapshot scratch file",
+		"synced",
+		"closing snapshot scratch",
+	} {
+		require.Contains(t, rec, msg)
+	}
+}
+
+// TestSSTSnapshotStorageNewSSTIterator checks that the completed SSTs of a
+// scratch can be read back.
+func TestSSTSnapshotStorageNewSSTIterator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	desc := roachpb.RangeDescriptor{
+		StartKey: roachpb.RKey("d"),
+		EndKey:   roachpb.RKeyMax,
+	}
+	keySpans := rditer.MakeReplicatedKeySpans(&desc)
+	st := cluster.MakeTestingClusterSettings()
+	msstw, err := newMultiSSTWriter(ctx, st, scratch, keySpans, 0 /* sstChunkSize */)
+	require.NoError(t, err)
+	defer msstw.Close()
+	for i := 0; i < 10; i++ {
+		key := storage.EngineKey{Key: roachpb.Key(fmt.Sprintf("d%03d", i))}
+		require.NoError(t, msstw.Put(ctx, key, []byte("foo")))
+	}
+	_, err = msstw.Finish(ctx)
+	require.NoError(t, err)
+
+	var keys []string
+	for _, sst := range scratch.SSTs() {
+		iter, err := scratch.NewSSTIterator(sst, storage.IterOptions{
+			LowerBound: roachpb.Key("d"),
+			UpperBound: roachpb.KeyMax,
+		})
+		require.NoError(t, err)
+		for iter.SeekGE(storage.MVCCKey{Key: roachpb.Key("d")}); ; iter.Next() {
+			ok, err := iter.Valid()
+			require.NoError(t, err)
+			if !ok {
+				break
+			}
+			keys = append(keys, string(iter.UnsafeKey().Key))
+		}
+		iter.Close()
+	}
+	require.Len(t, keys, 10)
+	require.Equal(t, "d000", keys[0])
+
+	// Only the completed SSTs can be read back.
+	_, err = scratch.NewSSTIterator(filepath.Join(scratch.snapDir, "foo.sst"), storage.IterOptions{})
+	require.True(t, testutils.IsError(err, "is not a completed SST"), "%v", err)
+}
+
+// TestSSTSnapshotStorageValidateBounds checks that the SSTs of a scratch
+// holding keys outside of the given spans are reported.
+func TestSSTSnapshotStorageValidateBounds(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	desc := roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKey("d"),
+		EndKey:   roachpb.RKey("f"),
+	}
+	st := cluster.MakeTestingClusterSettings()
+	msstw, err := newMultiSSTWriter(ctx, st, scratch, rditer.MakeReplicatedKeySpans(&desc), 0 /* sstChunkSize */)
+	require.NoError(t, err)
+	defer msstw.Close()
+	for _, k := range []string{"d", "e", "ezz"} {
+		require.NoError(t, msstw.Put(ctx, storage.EngineKey{Key: roachpb.Key(k)}, []byte("foo")))
+	}
+	_, err = msstw.Finish(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, scratch.ValidateBounds(rditer.MakeAllKeySpans(&desc)))
+
+	// With a narrower range, the SST of the user keys is reported. The range
+	// deletions of the SSTs of the other spans are not checked.
+	narrower := desc
+	narrower.StartKey = roachpb.RKey("e")
+	err = scratch.ValidateBounds(rditer.MakeAllKeySpans(&narrower))
+	require.True(t, errors.HasAssertionFailure(err), "%+v", err)
+	require.True(t, testutils.IsError(err, "has keys in .* outside of the spans"), "%v", err)
+}
+
+// fullDiskFS is a Pebble filesystem whose files fail the writes with ENOSPC
+// once they hold a total of capacity bytes, writing what still fits.
+type fullDiskFS struct {
+	vfs.FS
+	capacity, used int
+}
+
+func (d *fullDiskFS) Create(name string) (vfs.File, error) {
+	f, err := d.FS.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return fullDiskFile{File: f, disk: d}, nil
+}
+
+type fullDiskFile struct {
+	vfs.File
+	disk *fullDiskFS
+}
+
+func (f fullDiskFile) Write(p []byte) (int, error) {
+	var err error
+	if avail := f.disk.capacity - f.disk.used; len(p) > avail {
+		p, err = p[:avail], syscall.ENOSPC
+	}
+	n, writeErr := f.File.Write(p)
+	f.disk.used += n
+	if writeErr != nil {
+		return n, writeErr
+	}
+	return n, err
+}
+
+// TestSSTSnapshotStorageInMemory checks that the snapshot storage can operate
+// over a filesystem other than the one of the engine, here an in-memory one
+// failing the writes which don't fit on its "disk".
+func TestSSTSnapshotStorageInMemory(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	memFS := &fullDiskFS{FS: vfs.NewMem(), capacity: 8}
+	sstSnapshotStorage := NewSSTSnapshotStorageInDir(
+		fs.WrapVFS(memFS), "/scratch", unlimitedBulkIOWriteLimiter())
+
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	_, err = memFS.Stat(scratch.SSTs()[0])
+	require.NoError(t, err)
+
+	// A write which doesn't fit on the disk is partial, and fails the snapshot
+	// with a retryable error.
+	f, err := scratch.NewFile(ctx, 0)
+	require.NoError(t, err)
+	n, err := f.Write([]byte("barbaz"))
+	require.True(t, isSnapshotReceiverOutOfDiskError(err), "%v", err)
+	require.Equal(t, 5, n)
+
+	require.NoError(t, scratch.Close())
+	_, err = memFS.Stat(scratch.snapDir)
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+	require.Zero(t, sstSnapshotStorage.mu.usedBytes)
+}
+
+// TestSSTSnapshotStorageWriteBuffer checks that the small writes to a file are
+// held in its write buffer until it is full, synced or closed, and that the
+// writes at least as large as the buffer are written directly.
+func TestSSTSnapshotStorageWriteBuffer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	sstSnapshotStorage.writeBufferSize = func() int64 { return 8 }
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, scratch.Close())
+	}()
+	f, err := scratch.NewFile(ctx, 0)
+	require.NoError(t, err)
+
+	onDisk := func() string {
+		t.Helper()
+		file, err := eng.Open(scratch.SSTs()[0])
+		require.NoError(t, err)
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	for _, contents := range []string{"foo", "bar"} {
+		_, err = f.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.Equal(t, "", onDisk())
+	// The buffer is written out once the contents don't fit anymore.
+	_, err = f.Write([]byte("baz"))
+	require.NoError(t, err)
+	require.Equal(t, "foobar", onDisk())
+	// The contents as large as the buffer are written directly, after those of
+	// the buffer.
+	_, err = f.Write([]byte("0123456789"))
+	require.NoError(t, err)
+	require.Equal(t, "foobarbaz0123456789", onDisk())
+
+	_, err = f.Write([]byte("qux"))
+	require.NoError(t, err)
+	require.NoError(t, f.Sync())
+	require.Equal(t, "foobarbaz0123456789qux", onDisk())
+	_, err = f.Write([]byte("quux"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.Equal(t, "foobarbaz0123456789quxquux", onDisk())
+	require.Equal(t, crc32.Checksum([]byte("foobarbaz0123456789quxquux"), sstChunkCRCTable), f.Checksum())
+}
+
+// TestSSTSnapshotStorageBatchSync checks that the files whose syncs are batched
+// are only synced, and closed, by SyncPending.
+func TestSSTSnapshotStorageBatchSync(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	// The files are synced concurrently.
+	var mu syncutil.Mutex
+	var synced []string
+	sstSnapshotStorage.beforeFileOp = func(op ScratchFileOp, path string) error {
+		if op == ScratchFileSync {
+			mu.Lock()
+			defer mu.Unlock()
+			synced = append(synced, path)
+		}
+		return nil
+	}
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+
+	var files []*SSTSnapshotStorageFile
+	for i := 0; i < 3; i++ {
+		f, err := scratch.NewFile(ctx, 0)
+		require.NoError(t, err)
+		f.SetBatchSync()
+		_, err = f.Write([]byte("foo"))
+		require.NoError(t, err)
+		require.NoError(t, f.Sync())
+		require.NoError(t, f.Close())
+		files = append(files, f)
+	}
+	// The files are left open until synced.
+	require.Empty(t, synced)
+	for _, f := range files {
+		require.NotNil(t, f.file)
+	}
+
+	require.NoError(t, scratch.SyncPending(ctx))
+	require.ElementsMatch(t, scratch.SSTs(), synced)
+	for _, f := range files {
+		require.Nil(t, f.file)
+		require.True(t, f.synced)
+	}
+	// There is nothing left to sync.
+	synced = nil
+	require.NoError(t, scratch.SyncPending(ctx))
+	require.Empty(t, synced)
+
+	// The files whose sync is pending are closed along with the scratch.
+	f, err := scratch.NewFile(ctx, 0)
+	require.NoError(t, err)
+	f.SetBatchSync()
+	_, err = f.Write([]byte("bar"))
+	require.NoError(t, err)
+	require.NoError(t, f.Sync())
+	require.NoError(t, scratch.Close())
+	require.Nil(t, f.file)
+	require.Empty(t, synced)
+}
+
+// TestSSTSnapshotStorageRecoveryLimiter checks that the writes of recovery
+// snapshots, and those staging the SSTs of AddSSTable commands, are paced by
+// the dedicated limiters of the storage.
+func TestSSTSnapshotStorageRecoveryLimiter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	h := quotapool.NewHierarchicalRateLimiter("test", quotapool.Limit(math.Inf(1)), 0)
+	rebalanceLimiter, recoveryLimiter := h.NewChild("rebalance", 1), h.NewChild("recovery", 4)
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, rebalanceLimiter)
+
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.Same(t, rebalanceLimiter, scratch.limiter())
+	// Without a recovery limiter, recovery snapshots use the storage's limiter.
+	scratch.SetRecovery()
+	require.Same(t, rebalanceLimiter, scratch.limiter())
+	sstSnapshotStorage.recoveryLimiter = recoveryLimiter
+	require.Same(t, recoveryLimiter, scratch.limiter())
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	require.NoError(t, scratch.Close())
+
+	// The scratches staging the SSTs of AddSSTable commands are paced by the
+	// AddSSTable limiter of the storage, if set.
+	addSSTableLimiter := h.NewChild("addsstable", 1)
+	scratch, err = sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	scratch.SetAddSSTable()
+	require.Same(t, rebalanceLimiter, scratch.limiter())
+	sstSnapshotStorage.addSSTableLimiter = addSSTableLimiter
+	require.Same(t, addSSTableLimiter, scratch.limiter())
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	require.NoError(t, scratch.Close())
+}
+
+// TestSSTSnapshotStoragePause checks that the writes to the scratches block
+// while they are paused.
+func TestSSTSnapshotStoragePause(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	// A paused write fails once its context is canceled.
+	sstSnapshotStorage.SetPaused(true)
+	require.True(t, sstSnapshotStorage.Paused())
+	cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err = scratch.WriteSST(cancelCtx, []byte("foo"))
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "%v", err)
+
+	// The writes are paused as long as either the store or the setting pauses
+	// them.
+	sstSnapshotStorage.setPausedBySetting(true)
+	done := make(chan error, 1)
+	go func() { done <- scratch.WriteSST(ctx, []byte("foo")) }()
+	sstSnapshotStorage.SetPaused(false)
+	select {
+	case err := <-done:
+		t.Fatalf("expected the write to be blocked, got %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	sstSnapshotStorage.setPausedBySetting(false)
+	require.False(t, sstSnapshotStorage.Paused())
+	require.NoError(t, <-done)
+}
+
+// TestSSTSnapshotStorageConcurrentFiles checks that several files of a scratch
+// can be written concurrently.
+func TestSSTSnapshotStorageConcurrentFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+
+	const numFiles = 10
+	const numChunks = 10
+	var wg sync.WaitGroup
+	errCh := make(chan error, numFiles)
+	for i := 0; i < numFiles; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := func() error {
+				f, err := scratch.NewFile(ctx, 0 /* bytesPerSync */)
+				if err != nil {
+					return err
+				}
+				for j := 0; j < numChunks; j++ {
+					if _, err := f.Write([]byte(strconv.Itoa(i))); err != nil {
+						return err
+					}
+				}
+				if err := f.Sync(); err != nil {
+					return err
+				}
+				return f.Close()
+			}(); err != nil {
+				errCh <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		require.NoError(t, err)
+	}
+
+	// Every file got its own name, and holds the chunks of a single writer.
+	ssts := scratch.SSTs()
+	require.Len(t, ssts, numFiles)
+	seen := make(map[string]bool)
+	for _, sst := range ssts {
+		f, err := eng.Open(sst)
+		require.NoError(t, err)
+		data, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		require.Len(t, data, numChunks)
+		require.Equal(t, bytes.Repeat(data[:1], numChunks), data)
+		require.False(t, seen[string(data)])
+		seen[string(data)] = true
+	}
+	require.Equal(t, int64(numFiles*numChunks), scratch.Stats().BytesWritten)
+	require.Equal(t, int64(numFiles*numChunks), sstSnapshotStorage.mu.usedBytes)
+	require.NoError(t, scratch.Close())
+}
+
+// TestSSTSnapshotStorageFilePreallocate checks that preallocating a file does
+// not change its size or contents.
+func TestSSTSnapshotStorageFilePreallocate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	f, err := scratch.NewFile(ctx, 0 /* bytesPerSync */)
+	require.NoError(t, err)
+	require.NoError(t, f.Preallocate(1<<20))
+	info, err := eng.Stat(f.filename)
+	require.NoError(t, err)
+	require.Zero(t, info.Size())
+
+	_, err = f.Write([]byte("foo"))
+	require.NoError(t, err)
+	require.NoError(t, f.Preallocate(2))
+	require.NoError(t, f.Sync())
+	require.NoError(t, f.Close())
+	info, err = eng.Stat(f.filename)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), info.Size())
+}
+
+// TestSSTSnapshotStorageFaultInjection checks that the faults injected into
+// the filesystem operations of the scratches fail the operations.
+func TestSSTSnapshotStorageFaultInjection(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	var ops []string
+	failOp := ScratchFileOp(-1)
+	sstSnapshotStorage.beforeFileOp = func(op ScratchFileOp, path string) error {
+		ops = append(ops, fmt.Sprintf("%s %s", op, filepath.Base(path)))
+		if op == failOp {
+			return errors.Newf("injected %s fault", op)
+		}
+		return nil
+	}
+
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	require.Equal(t, []string{"create 0.sst", "write 0.sst", "sync 0.sst"}, ops)
+
+	for _, op := range []ScratchFileOp{ScratchFileCreate, ScratchFileWrite, ScratchFileSync} {
+		failOp = op
+		err := scratch.WriteSST(ctx, []byte("foo"))
+		require.True(t, testutils.IsError(err, fmt.Sprintf("injected %s fault", op)), "%v", err)
+	}
+
+	// The directory is left behind if it could not be removed.
+	failOp = ScratchRemoveAll
+	err = scratch.Close()
+	require.True(t, testutils.IsError(err, "injected remove-all fault"), "%v", err)
+	_, err = eng.Stat(scratch.snapDir)
+	require.NoError(t, err)
+	require.Empty(t, sstSnapshotStorage.mu.rangeRefCount)
+}
+
+// TestSSTSnapshotStorageProbe checks that the probes of the storage directory
+// leave nothing behind, and that their failures are recorded until a probe
+// succeeds.
+func TestSSTSnapshotStorageProbe(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	var ops []string
+	failOp := ScratchFileOp(-1)
+	sstSnapshotStorage.beforeFileOp = func(op ScratchFileOp, path string) error {
+		ops = append(ops, fmt.Sprintf("%s %s", op, filepath.Base(path)))
+		if op == failOp {
+			return errors.Newf("injected %s fault", op)
+		}
+		return nil
+	}
+
+	require.NoError(t, sstSnapshotStorage.Probe())
+	require.NoError(t, sstSnapshotStorage.ProbeError())
+	require.Equal(t, []string{"create probe", "write probe", "sync probe"}, ops)
+
+	for _, op := range []ScratchFileOp{ScratchFileCreate, ScratchFileWrite, ScratchFileSync} {
+		failOp = op
+		require.True(t, testutils.IsError(sstSnapshotStorage.Probe(), fmt.Sprintf("injected %s fault", op)))
+		require.True(t, testutils.IsError(sstSnapshotStorage.ProbeError(), fmt.Sprintf("injected %s fault", op)))
+		_, err := eng.Stat(filepath.Join(sstSnapshotStorage.Dir(), snapshotProbeFilename))
+		require.True(t, oserror.IsNotExist(err), "%v", err)
+	}
+
+	failOp = ScratchFileOp(-1)
+	require.NoError(t, sstSnapshotStorage.Probe())
+	require.NoError(t, sstSnapshotStorage.ProbeError())
+	leftovers, err := sstSnapshotStorage.ScanLeftovers()
+	require.NoError(t, err)
+	require.Empty(t, leftovers)
+}
+
+// TestSSTSnapshotStorageFileWriteChecked checks that chunks whose checksum
+// does not match are rejected before being written.
+func TestSSTSnapshotStorageFileWriteChecked(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, scratch.Close())
+	}()
+	f, err := scratch.NewFile(ctx, 0)
+	require.NoError(t, err)
+
+	_, err = f.WriteChecked([]byte("foo"), util.CRC32([]byte("foo")))
+	require.NoError(t, err)
+	_, err = f.WriteChecked([]byte("bar"), util.CRC32([]byte("baz")))
+	require.True(t, testutils.IsError(err, "checksum mismatch for the 3 bytes at offset 3"), "%v", err)
+	_, err = f.WriteChecked([]byte("bar"), util.CRC32([]byte("bar")))
+	require.NoError(t, err)
+	require.Equal(t, util.CRC32([]byte("foobar")), f.Checksum())
+	require.NoError(t, f.Close())
+
+	contents, err := eng.ReadFile(scratch.SSTs()[0])
+	require.NoError(t, err)
+	require.Equal(t, []byte("foobar"), contents)
+}
+
+// TestSSTSnapshotStorageResumeScratchSpace checks that a resumable scratch
+// survives a restart of the store with its complete SSTs.
+func TestSSTSnapshotStorageResumeScratchSpace(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	snapUUID := uuid.MakeV4()
+	scratch, entries, err := sstSnapshotStorage.ResumeScratchSpace(ctx, 1, snapUUID)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	require.NoError(t, scratch.WriteSST(ctx, []byte("barbaz")))
+	// The last SST is not synced, as if the store crashed while writing it.
+	f, err := scratch.NewFile(ctx, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("partial"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// The store restarts, without closing the scratch. The resumable scratch
+	// is kept.
+	sstSnapshotStorage = NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	leftovers, err := sstSnapshotStorage.ScanLeftovers()
+	require.NoError(t, err)
+	require.Len(t, leftovers, 1)
+	require.True(t, leftovers[0].Resumable)
+	reclaimed, err := sstSnapshotStorage.ClearLeftovers(ctx)
+	require.NoError(t, err)
+	require.Zero(t, reclaimed)
+
+	scratch, entries, err = sstSnapshotStorage.ResumeScratchSpace(ctx, 1, snapUUID)
+	require.NoError(t, err)
+	require.Equal(t, []ScratchManifestEntry{
+		{Name: "0.sst", Size: 3, Checksum: util.CRC32([]byte("foo"))},
+		{Name: "1.sst", Size: 6, Checksum: util.CRC32([]byte("barbaz"))},
+	}, entries)
+	require.Equal(t, int64(9), sstSnapshotStorage.UsedBytes())
+	_, err = eng.Stat(filepath.Join(scratch.snapDir, "2.sst"))
+	require.True(t, oserror.IsNotExist(err))
+
+	// The reception continues after the restored SSTs.
+	require.NoError(t, scratch.WriteSST(ctx, []byte("qux")))
+	require.Len(t, scratch.SSTs(), 3)
+	for i, expected := range []string{"foo", "barbaz", "qux"} {
+		contents, err := eng.ReadFile(scratch.SSTs()[i])
+		require.NoError(t, err)
+		require.Equal(t, expected, string(contents))
+	}
+	require.NoError(t, scratch.Close())
+
+	// Once closed, the scratch cannot be resumed anymore.
+	scratch, entries, err = sstSnapshotStorage.ResumeScratchSpace(ctx, 1, snapUUID)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+	require.NoError(t, scratch.Close())
+}
+
+// TestSSTSnapshotStorageAdmission checks that the writes admitted by admission
+// control are not subject to the storage's limiter.
+func TestSSTSnapshotStorageAdmission(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	// The limiter would block the writes for minutes.
+	limiter := quotapool.NewHierarchicalRateLimiter("test", 1, 1).NewChild("snapshot", 1)
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, limiter)
+	var admitted int64
+	sstSnapshotStorage.admit = func(ctx context.Context, n int64) (bool, error) {
+		if n > 1000 {
+			return false, errors.New("too large")
+		}
+		admitted += n
+		return true, nil
+	}
+
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, scratch.Close())
+	}()
+	require.NoError(t, scratch.WriteSST(ctx, make([]byte, 200)))
+	require.NoError(t, scratch.WriteSST(ctx, make([]byte, 300)))
+	require.Equal(t, int64(500), admitted)
+	err = scratch.WriteSST(ctx, make([]byte, 2000))
+	require.True(t, testutils.IsError(err, "error admitting snapshot write: too large"), "%v", err)
+}
+
+// TestSSTSnapshotStorageDropPageCache checks that the files of the scratches
+// can be dropped from the page cache once synced, without affecting their
+// contents.
+func TestSSTSnapshotStorageDropPageCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	var drops int
+	sstSnapshotStorage.dropPageCache = func() bool {
+		drops++
+		return true
+	}
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	f, err := scratch.NewFile(ctx, 16<<10)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("foo"))
+	require.NoError(t, err)
+	// The files of an on-disk engine expose their descriptor through the
+	// wrappers of the filesystem, so that they can be dropped.
+	_, ok := f.file.(fdGetter)
+	require.True(t, ok)
+	require.NoError(t, f.Sync())
+	require.NoError(t, f.Close())
+	require.Equal(t, 1, drops)
+
+	contents, err := eng.ReadFile(scratch.SSTs()[0])
+	require.NoError(t, err)
+	require.Equal(t, []byte("foo"), contents)
+}
+
+// TestSSTSnapshotStorageSyncPolicy checks that the files of the scratches are
+// not synced periodically when only their final sync is requested.
+func TestSSTSnapshotStorageSyncPolicy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	policy := scratchSyncPeriodic
+	sstSnapshotStorage.syncPolicy = func() scratchSyncPolicy { return policy }
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	f, err := scratch.NewFile(ctx, 16<<10)
+	require.NoError(t, err)
+	require.Equal(t, int64(16<<10), f.bytesPerSync)
+
+	policy = scratchSyncFinal
+	f, err = scratch.NewFile(ctx, 16<<10)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), f.bytesPerSync)
+	_, err = f.Write([]byte("foo"))
+	require.NoError(t, err)
+	require.NoError(t, f.Sync())
+	require.NoError(t, f.Close())
+}
+
+// TestSSTSnapshotStorageMaxFiles checks that a scratch refuses new files once
+// it reaches the limit.
+func TestSSTSnapshotStorageMaxFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	maxFiles := int64(2)
+	sstSnapshotStorage.maxFiles = func() int64 { return maxFiles }
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	for i := 0; i < 2; i++ {
+		_, err := scratch.NewFile(ctx, 0)
+		require.NoError(t, err)
+	}
+	_, err = scratch.NewFile(ctx, 0)
+	require.True(t, isSnapshotScratchTooManyFilesError(err), "%+v", err)
+	require.Len(t, scratch.SSTs(), 2)
+
+	// Lifting the limit allows more files.
+	maxFiles = 0
+	_, err = scratch.NewFile(ctx, 0)
+	require.NoError(t, err)
+}
+
+// TestSSTSnapshotStorageExpectedBytes checks that the writes to a scratch fail
+// once they exceed its expected size by more than the overrun factor.
+func TestSSTSnapshotStorageExpectedBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	overrunFactor := 1.5
+	sstSnapshotStorage.maxOverrunFactor = func() float64 { return overrunFactor }
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	scratch.SetExpectedBytes(128 << 20)
+	require.NoError(t, sstSnapshotStorage.reserveBytes(scratch, 192<<20))
+	err = sstSnapshotStorage.reserveBytes(scratch, 1)
+	require.True(t, isSnapshotScratchSizeExceededError(err), "%+v", err)
+	require.Equal(t, int64(192<<20), scratch.usedBytes)
+
+	// Without an expected size, the scratch is not limited.
+	scratch.SetExpectedBytes(0)
+	require.NoError(t, sstSnapshotStorage.reserveBytes(scratch, 1))
+
+	// Small snapshots get the fixed allowance.
+	scratch.SetExpectedBytes(1 << 10)
+	require.True(t, isSnapshotScratchSizeExceededError(sstSnapshotStorage.reserveBytes(scratch, 1)))
+	require.Equal(t, int64(1<<10+scratchExpectedBytesSlack), scratch.sizeLimitLocked(overrunFactor))
+
+	// Lifting the limit allows more writes.
+	overrunFactor = 0
+	require.NoError(t, sstSnapshotStorage.reserveBytes(scratch, 1))
+}
+
+// TestSSTSnapshotStorageBulkIOWriteWait checks that the time the writes to a
+// scratch wait for the bulk IO write budget is recorded in the store metrics.
+func TestSSTSnapshotStorageBulkIOWriteWait(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	// 1 MiB/s, with a 1 KiB burst: the third write waits for the debt of the
+	// second one, that is about 60ms.
+	limiter := quotapool.NewHierarchicalRateLimiter("test", 1<<20, 1<<10).NewChild("test", 1)
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, limiter)
+	m := newStoreMetrics(time.Minute)
+	sstSnapshotStorage.metrics = m
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	f, err := scratch.NewFile(ctx, 0)
+	require.NoError(t, err)
+	for _, n := range []int{1 << 10, 64 << 10, 1} {
+		_, err := f.Write(make([]byte, n))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+	require.GreaterOrEqual(t, m.SnapshotScratchBulkIOWriteWait.Count(), (10 * time.Millisecond).Nanoseconds())
+}
+
+// TestSSTSnapshotStorageIngestAsBatch checks that the SSTs of a scratch
+// holding little data are applied through a batch, with the same outcome as
+// an ingestion.
+func TestSSTSnapshotStorageIngestAsBatch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	maxBytes := int64(1 << 20)
+	sstSnapshotStorage.applyAsBatchMaxBytes = func() int64 { return maxBytes }
+	desc := roachpb.RangeDescriptor{
+		StartKey: roachpb.RKey("d"),
+		EndKey:   roachpb.RKeyMax,
+	}
+	keySpans := rditer.MakeReplicatedKeySpans(&desc)
+	st := cluster.MakeTestingClusterSettings()
+
+	// apply stages a snapshot of the given keys of the range, applies it along
+	// with the given cleared spans, and returns the user keys of the engine.
+	apply := func(keys []string, clearedSpans []roachpb.Span) (bool, []string) {
+		scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+		require.NoError(t, err)
+		defer func() { require.NoError(t, scratch.Close()) }()
+		msstw, err := newMultiSSTWriter(ctx, st, scratch, keySpans, 0 /* sstChunkSize */)
+		require.NoError(t, err)
+		defer msstw.Close()
+		for _, k := range keys {
+			require.NoError(t, msstw.Put(ctx, storage.EngineKey{Key: roachpb.Key(k)}, []byte("foo")))
+		}
+		_, err = msstw.Finish(ctx)
+		require.NoError(t, err)
+
+		ingested, _, err := scratch.Ingest(ctx, eng, clearedSpans)
+		require.NoError(t, err)
+		require.Equal(t, !ingested, scratch.appliedBatchBytes > 0)
+
+		iter := eng.NewEngineIterator(storage.IterOptions{
+			LowerBound: roachpb.Key("a"),
+			UpperBound: roachpb.KeyMax,
+		})
+		defer iter.Close()
+		var engKeys []string
+		valid, err := iter.SeekEngineKeyGE(storage.EngineKey{Key: roachpb.Key("a")})
+		for ; valid; valid, err = iter.NextEngineKey() {
+			key, err := iter.UnsafeEngineKey()
+			require.NoError(t, err)
+			engKeys = append(engKeys, string(key.Key))
+		}
+		require.NoError(t, err)
+		return ingested, engKeys
+	}
+
+	require.NoError(t, eng.PutEngineKey(storage.EngineKey{Key: roachpb.Key("c")}, []byte("bar")))
+	require.NoError(t, eng.PutEngineKey(storage.EngineKey{Key: roachpb.Key("d999")}, []byte("bar")))
+
+	// The keys of the range are replaced, the others are left alone.
+	ingested, engKeys := apply([]string{"d000", "d001"}, keySpans)
+	require.False(t, ingested)
+	require.Equal(t, []string{"c", "d000", "d001"}, engKeys)
+
+	// Without the cleared spans, the SSTs are ingested.
+	ingested, engKeys = apply([]string{"d002"}, nil)
+	require.True(t, ingested)
+	require.Equal(t, []string{"c", "d002"}, engKeys)
+
+	// As they are when the scratch holds too much data.
+	maxBytes = 1
+	ingested, engKeys = apply([]string{"d003"}, keySpans)
+	require.True(t, ingested)
+	require.Equal(t, []string{"c", "d003"}, engKeys)
+}
+
+// TestSSTSnapshotStorageProgress checks that the progress function of a
+// scratch is invoked every time the given number of bytes has been written.
+func TestSSTSnapshotStorageProgress(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	var reported []int64
+	scratch.SetProgressFunc(10, func(stats WriteSSTStats) {
+		reported = append(reported, stats.BytesWritten)
+	})
+	f, err := scratch.NewFile(ctx, 0)
+	require.NoError(t, err)
+	for _, n := range []int{4, 4, 4, 15, 1, 2} {
+		_, err := f.Write(make([]byte, n))
+		require.NoError(t, err)
+	}
+	require.Equal(t, []int64{12, 27, 30}, reported)
+
+	// Once unregistered, the function is not invoked anymore.
+	scratch.SetProgressFunc(10, nil)
+	_, err = f.Write(make([]byte, 20))
+	require.NoError(t, err)
+	require.Len(t, reported, 3)
+	require.NoError(t, f.Close())
+}
+
+// TestSSTSnapshotStorageFileWriteCanceled checks that a write to a scratch
+// file stops once the context of the file is canceled, and that the partially
+// written file is removed.
+func TestSSTSnapshotStorageFileWriteCanceled(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	fileCtx, cancel := context.WithCancel(ctx)
+	f, err := scratch.NewFile(fileCtx, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("foo"))
+	require.NoError(t, err)
+	_, err = eng.Stat(f.filename)
+	require.NoError(t, err)
+
+	cancel()
+	n, err := f.Write(make([]byte, 3*scratchWriteChunkSize))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, n)
+	_, err = eng.Stat(f.filename)
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+	require.NoError(t, f.Close())
+}
+
+// TestSSTSnapshotStorageScratchETA checks that the time to write the remaining
+// bytes of a scratch is estimated from the rate limit, which is the write rate
+// of the scratch, until bytes are written, and from the write throughput then.
+func TestSSTSnapshotStorageScratchETA(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	limiter := quotapool.NewHierarchicalRateLimiter("test", 1<<20, 1<<20)
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, limiter.NewChild("snapshot", 1))
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	require.Equal(t, float64(1<<20), scratch.WriteRate())
+	eta, ok := scratch.ETA(4 << 20)
+	require.True(t, ok)
+	require.Equal(t, 4*time.Second, eta)
+	eta, ok = scratch.ETA(0)
+	require.True(t, ok)
+	require.Zero(t, eta)
+
+	// Without a rate limit, the time can't be estimated until bytes are
+	// written.
+	limiter.UpdateLimit(quotapool.Limit(math.Inf(1)), 0)
+	require.True(t, math.IsInf(scratch.WriteRate(), 1))
+	_, ok = scratch.ETA(4 << 20)
+	require.False(t, ok)
+
+	scratch.created = timeutil.Now().Add(-time.Second)
+	require.NoError(t, scratch.WriteSST(ctx, make([]byte, 1<<10)))
+	eta, ok = scratch.ETA(4 << 10)
+	require.True(t, ok)
+	require.InDelta(t, 4*time.Second, eta, float64(time.Second))
+}
+
+// TestSSTSnapshotStorageTransientErrors checks that the creation of and the
+// writes to the scratch files are retried on transient filesystem errors, up
+// to a limit.
+func TestSSTSnapshotStorageTransientErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	defer func(opts retry.Options) { scratchRetryOptions = opts }(scratchRetryOptions)
+	scratchRetryOptions.InitialBackoff = time.Millisecond
+	scratchRetryOptions.MaxBackoff = time.Millisecond
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	failures := map[ScratchFileOp]int{}
+	var fsErr error = syscall.EAGAIN
+	sstSnapshotStorage.beforeFileOp = func(op ScratchFileOp, path string) error {
+		if failures[op] > 0 {
+			failures[op]--
+			return &os.PathError{Op: op.String(), Path: path, Err: fsErr}
+		}
+		return nil
+	}
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+
+	failures[ScratchFileCreate] = 2
+	failures[ScratchFileWrite] = 2
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	require.Zero(t, failures[ScratchFileCreate])
+	require.Zero(t, failures[ScratchFileWrite])
+
+	// The retries are bounded.
+	failures[ScratchFileWrite] = scratchRetryOptions.MaxRetries + 1
+	err = scratch.WriteSST(ctx, []byte("bar"))
+	require.ErrorIs(t, err, syscall.EAGAIN)
+	require.Zero(t, failures[ScratchFileWrite])
+
+	// Other errors are not retried.
+	fsErr = syscall.EACCES
+	failures[ScratchFileCreate] = 2
+	err = scratch.WriteSST(ctx, []byte("baz"))
+	require.ErrorIs(t, err, syscall.EACCES)
+	require.Equal(t, 1, failures[ScratchFileCreate])
+}
+
+// TestSnapshotScratchBudget checks that the scratches of the stores sharing a
+// budget can't jointly go over its limit.
+func TestSnapshotScratchBudget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	snapshotNodeScratchSpaceMaxBytes.Override(ctx, &st.SV, 10)
+	budget := NewSnapshotScratchBudget(st)
+
+	var scratches []*SSTSnapshotStorageScratch
+	for i := 0; i < 2; i++ {
+		cleanup, eng := newOnDiskEngine(ctx, t)
+		defer cleanup()
+		defer eng.Close()
+		sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+		sstSnapshotStorage.budget = budget
+		scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+		require.NoError(t, err)
+		scratches = append(scratches, scratch)
+	}
+
+	require.NoError(t, scratches[0].WriteSST(ctx, []byte("foobar")))
+	err := scratches[1].WriteSST(ctx, []byte("foobar"))
+	require.True(t, testutils.IsError(err, "node snapshot scratch space exhausted"), "%v", err)
+	require.Equal(t, int64(6), budget.UsedBytes())
+
+	// Closing a scratch frees up its space.
+	require.NoError(t, scratches[0].Close())
+	require.Zero(t, budget.UsedBytes())
+	require.NoError(t, scratches[1].WriteSST(ctx, []byte("foobar")))
+	require.Equal(t, int64(6), budget.UsedBytes())
+	require.NoError(t, scratches[1].Close())
+	require.Zero(t, budget.UsedBytes())
+}
+
+// TestSSTSnapshotStorageTrash checks that the removed directories are moved to
+// the trash, and removed from it in the background.
+func TestSSTSnapshotStorageTrash(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	trashDir := snapshotTrashDir(sstSnapshotStorage.dir)
+	var tasks []func(context.Context)
+	sstSnapshotStorage.runAsync = func(taskName string, f func(context.Context)) error {
+		tasks = append(tasks, f)
+		return nil
+	}
+	listTrash := func() []string {
+		names, err := eng.List(trashDir)
+		require.NoError(t, err)
+		sort.Strings(names)
+		return names
+	}
+
+	// The directory of a closed scratch, then that of its range, are moved to
+	// the trash. The trash is only emptied once in the meantime.
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	require.NoError(t, scratch.Close())
+	_, err = eng.Stat(scratch.snapDir)
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+	var prefixes []string
+	for _, name := range listTrash() {
+		prefixes = append(prefixes, name[:strings.LastIndex(name, ".")])
+	}
+	require.ElementsMatch(t, []string{"1", scratch.snapUUID.String()}, prefixes)
+	require.Len(t, tasks, 1)
+	tasks[0](ctx)
+	require.Empty(t, listTrash())
+
+	// Clearing the storage moves its whole directory to the trash.
+	scratch, err = sstSnapshotStorage.NewScratchSpace(ctx, 2, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	require.NoError(t, eng.MkdirAll(snapshotRangeDir(sstSnapshotStorage.dir, 3)))
+	require.NoError(t, sstSnapshotStorage.Clear())
+	_, err = eng.Stat(snapshotRangeDir(sstSnapshotStorage.dir, 3))
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+	require.NoError(t, scratch.Close())
+	require.Len(t, tasks, 2)
+	tasks[1](ctx)
+	require.NoError(t, sstSnapshotStorage.Clear())
+	_, err = eng.Stat(sstSnapshotStorage.dir)
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+	require.Len(t, listTrash(), 1)
+	require.Len(t, tasks, 3)
+	tasks[2](ctx)
+	require.Empty(t, listTrash())
+
+	// The trash is emptied synchronously if no task can be run.
+	sstSnapshotStorage.runAsync = func(string, func(context.Context)) error {
+		return errors.New("stopping")
+	}
+	scratch, err = sstSnapshotStorage.NewScratchSpace(ctx, 4, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	require.NoError(t, scratch.Close())
+	require.Empty(t, listTrash())
+}
+
+// TestSSTSnapshotStorageShardedLayout checks that the scratches are created in
+// the sharded layout of the storage, while those found in the legacy layout
+// are still cleaned up and resumed.
+func TestSSTSnapshotStorageShardedLayout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	require.Equal(t, "s00", snapshotShardName(256))
+	require.Equal(t, "s24", snapshotShardName(1828))
+	require.True(t, isSnapshotShardName("s24"))
+	require.False(t, isSnapshotShardName("24"))
+	require.False(t, isSnapshotShardName("s2g"))
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	snapUUID := uuid.MakeV4()
+	scratch, _, err := sstSnapshotStorage.ResumeScratchSpace(ctx, 1828, snapUUID)
+	require.NoError(t, err)
+	require.NoError(t, scratch.WriteSST(ctx, []byte("foo")))
+	require.Equal(t, filepath.Join(sstSnapshotStorage.dir, "s24", "1828", snapUUID.String()), scratch.snapDir)
+
+	// The store restarts with the scratch in the legacy layout, next to a
+	// scratch of another range and an unexpected file.
+	legacyDir := filepath.Join(legacySnapshotRangeDir(sstSnapshotStorage.dir, 1828), snapUUID.String())
+	require.NoError(t, eng.Rename(filepath.Dir(scratch.snapDir), filepath.Dir(legacyDir)))
+	otherDir := filepath.Join(snapshotRangeDir(sstSnapshotStorage.dir, 2), uuid.MakeV4().String())
+	require.NoError(t, eng.MkdirAll(otherDir))
+	require.NoError(t, fs.WriteFile(eng, filepath.Join(otherDir, "0.sst"), []byte("foobar")))
+	unexpected := filepath.Join(sstSnapshotStorage.dir, "s02", "foo")
+	require.NoError(t, fs.WriteFile(eng, unexpected, []byte("bar")))
+
+	sstSnapshotStorage = NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	leftovers, err := sstSnapshotStorage.ScanLeftovers()
+	require.NoError(t, err)
+	require.Len(t, leftovers, 3)
+	require.Equal(t, LeftoverScratch{
+		Dir: legacyDir, RangeID: 1828, SnapUUID: snapUUID.String(), Bytes: leftovers[0].Bytes, Resumable: true,
+	}, leftovers[0])
+	require.Equal(t, LeftoverScratch{
+		Dir: otherDir, RangeID: 2, SnapUUID: filepath.Base(otherDir), Bytes: 6,
+	}, leftovers[1])
+	require.Equal(t, LeftoverScratch{Dir: unexpected, Bytes: 3}, leftovers[2])
+	reclaimed, err := sstSnapshotStorage.ClearLeftovers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(9), reclaimed)
+
+	// The scratch is moved to the sharded layout when resumed.
+	scratch, entries, err := sstSnapshotStorage.ResumeScratchSpace(ctx, 1828, snapUUID)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	_, err = eng.Stat(legacyDir)
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+	contents, err := eng.ReadFile(scratch.SSTs()[0])
+	require.NoError(t, err)
+	require.Equal(t, []byte("foo"), contents)
+	require.NoError(t, scratch.Close())
+
+	// The legacy range directory left behind is removed along with the
+	// orphaned ones.
+	_, err = sstSnapshotStorage.ClearOrphaned()
+	require.NoError(t, err)
+	_, err = eng.Stat(filepath.Dir(legacyDir))
+	require.True(t, oserror.IsNotExist(err), "%v", err)
+}
+
+// TestSSTSnapshotStorageScratchInfo checks that the description of the
+// snapshot staged in a scratch tells a fully received snapshot apart from a
+// partial one after a crash.
+func TestSSTSnapshotStorageScratchInfo(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	desc := roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKey("a"),
+		EndKey:   roachpb.RKey("z"),
+	}
+	partial, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, partial.RecordInfo(desc, 3, 100))
+	require.NoError(t, partial.WriteSST(ctx, []byte("foo")))
+	complete, err := sstSnapshotStorage.NewScratchSpace(ctx, 1, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, complete.RecordInfo(desc, 3, 100))
+	require.NoError(t, complete.WriteSST(ctx, []byte("foo")))
+	require.NoError(t, complete.WriteSST(ctx, []byte("barbaz")))
+	require.NoError(t, complete.RecordReceived())
+	// RecordReceived is a no-op for a scratch without info.
+	other, err := sstSnapshotStorage.NewScratchSpace(ctx, 2, uuid.MakeV4())
+	require.NoError(t, err)
+	require.NoError(t, other.WriteSST(ctx, []byte("foo")))
+	require.NoError(t, other.RecordReceived())
+
+	// The store restarts without closing the scratches.
+	sstSnapshotStorage = NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	leftovers, err := sstSnapshotStorage.ScanLeftovers()
+	require.NoError(t, err)
+	infos := make(map[string]*ScratchInfo)
+	for _, l := range leftovers {
+		infos[l.Dir] = l.Info
+	}
+	require.Len(t, infos, 3)
+	require.Nil(t, infos[other.snapDir])
+
+	info := infos[partial.snapDir]
+	require.NotNil(t, info)
+	require.Equal(t, partial.snapUUID, info.SnapUUID)
+	require.Equal(t, desc, info.Desc)
+	require.Equal(t, 3, info.KeySpans)
+	require.Equal(t, int64(100), info.RangeSize)
+	require.False(t, info.Created.IsZero())
+	require.False(t, info.Complete)
+	require.Empty(t, info.SSTs)
+
+	info = infos[complete.snapDir]
+	require.NotNil(t, info)
+	require.Equal(t, complete.snapUUID, info.SnapUUID)
+	require.True(t, info.Complete)
+	require.False(t, info.Completed.Before(info.Created))
+	require.Equal(t, []ScratchInfoSST{{Name: "0.sst", Size: 3}, {Name: "1.sst", Size: 6}}, info.SSTs)
+}
+
+// TestStageOutgoingSnapshot checks that the batches of a staged outgoing
+// snapshot are the ones that would be sent from the engine snapshot.
+func TestStageOutgoingSnapshot(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	desc := roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKey("d"),
+		EndKey:   roachpb.RKeyMax,
+	}
+	for i := 0; i < 100; i++ {
+		require.NoError(t, eng.PutUnversioned(roachpb.Key(fmt.Sprintf("d%03d", i)), []byte("foo")))
+	}
+	require.NoError(t, eng.PutEngineRangeKey(roachpb.Key("e"), roachpb.Key("f"), []byte{1}, nil))
+	snap := &OutgoingSnapshot{
+		SnapUUID:   uuid.MakeV4(),
+		EngineSnap: eng.NewSnapshot(),
+		State:      kvserverpb.ReplicaState{Desc: &desc},
+	}
+	defer snap.Close()
+	newBatch := func() storage.Batch { return eng.NewUnindexedBatch(true /* writeOnly */) }
+
+	var expected [][]byte
+	kvs, rangeKVs, err := batchSnapshotKVs(snap, newBatch, 256, newSnapshotTimingTag(),
+		nil /* dataChecksum */, func(repr []byte) error {
+			expected = append(expected, append([]byte(nil), repr...))
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, 100, kvs)
+	require.Equal(t, 1, rangeKVs)
+	require.Greater(t, len(expected), 1)
+
+	sstSnapshotStorage := NewSSTSnapshotStorage(eng, unlimitedBulkIOWriteLimiter())
+	scratch, err := sstSnapshotStorage.NewScratchSpace(ctx, desc.RangeID, snap.SnapUUID)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, scratch.Close()) }()
+	staged, err := stageOutgoingSnapshot(ctx, snap, scratch, newBatch, 256)
+	require.NoError(t, err)
+	require.Equal(t, 100, staged.kvs)
+	require.Equal(t, 1, staged.rangeKVs)
+	var checksum uint32
+	for _, repr := range expected {
+		checksum = crc32.Update(checksum, sstChunkCRCTable, repr)
+	}
+	require.Equal(t, checksum, staged.checksum)
+
+	// The staged batches can be read several times.
+	for i := 0; i < 2; i++ {
+		var actual [][]byte
+		require.NoError(t, staged.readBatches(func(repr []byte) error {
+			actual = append(actual, repr)
+			return nil
+		}))
+		require.Equal(t, expected, actual)
+	}
+}
+
+// TestPrewarmReplicaData checks that the prewarming of the block cache for a
+// snapshot stops once enough data was read.
+func TestPrewarmReplicaData(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	desc := roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKey("d"),
+		EndKey:   roachpb.RKeyMax,
+	}
+	for i := 0; i < 100; i++ {
+		require.NoError(t, eng.PutUnversioned(roachpb.Key(fmt.Sprintf("d%03d", i)), make([]byte, 96)))
+	}
+	snap := eng.NewSnapshot()
+	defer snap.Close()
+
+	n, err := prewarmReplicaData(ctx, snap, &desc, 1<<20)
+	require.NoError(t, err)
+	require.Greater(t, n, int64(100*96))
+
+	n, err = prewarmReplicaData(ctx, snap, &desc, 1000)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, int64(1000))
+	require.Less(t, n, int64(1200))
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = prewarmReplicaData(canceledCtx, snap, &desc, 1<<20)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// TestSnapshotBatchesChecksum checks that the recipient of a snapshot rejects
+// batches which don't match the checksum computed by the sender.
+func TestSnapshotBatchesChecksum(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	batches := [][]byte{[]byte("foo"), []byte("bar"), []byte("baz")}
+	sender := &kvBatchSnapshotStrategy{}
+	for _, b := range batches {
+		sender.checksum = crc32.Update(sender.checksum, sstChunkCRCTable, b)
+	}
+	checksum := sender.batchesChecksum()
+
+	receive := func(batches ...[]byte) *kvBatchSnapshotStrategy {
+		recipient := &kvBatchSnapshotStrategy{}
+		for _, b := range batches {
+			recipient.checksum = crc32.Update(recipient.checksum, sstChunkCRCTable, b)
+		}
+		return recipient
+	}
+	require.NoError(t, receive(batches...).verifyChecksum(checksum))
+	// Senders which don't compute the checksum don't have it verified.
+	require.NoError(t, receive([]byte("foo")).verifyChecksum(nil))
+
+	for _, received := range [][][]byte{
+		{[]byte("foo"), []byte("bar")},
+		{[]byte("foo"), []byte("baz"), []byte("bar")},
+		{[]byte("foo"), []byte("bar"), []byte("bay")},
+	} {
+		err := receive(received...).verifyChecksum(checksum)
+		require.True(t, isSnapshotChecksumMismatchError(err), "%v", err)
+	}
+	err := receive(batches...).verifyChecksum(checksum[:2])
+	require.Error(t, err)
+	require.False(t, isSnapshotChecksumMismatchError(err))
+}
@@ -0,0 +1,196 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	crypto_rand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// snapshotSenderEncryptPayload enables the end-to-end encryption of the KV
+// batches of the snapshots sent by a store, for deployments requiring the
+// payload of snapshots to be encrypted independently of the connections they
+// are sent over, e.g. through proxies terminating TLS. The snapshots are only
+// encrypted once the cluster version EncryptedSnapshots is active, so that all
+// the recipients can decrypt them. The sender aborts a snapshot accepted by a
+// recipient which didn't set up its decryption, rather than sending it in the
+// clear.
+var snapshotSenderEncryptPayload = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.snapshot_sender.encrypt_payload.enabled",
+	"if enabled, the data of the snapshots sent is encrypted end-to-end between the "+
+		"sender and the recipient, independently of the encryption of the connection",
+	false,
+)
+
+// snapshotPayloadKeyInfo is the context of the derivation of the key of an
+// encrypted snapshot.
+const snapshotPayloadKeyInfo = "cockroach snapshot payload"
+
+// snapshotPayloadFinalData is the additional data of the message sealed after
+// the last batch of an encrypted snapshot, so that it cannot be mistaken for a
+// batch.
+var snapshotPayloadFinalData = []byte("final")
+
+// snapshotKeyExchange is the ephemeral X25519 key pair of the sender or of the
+// recipient of an encrypted snapshot. The key of the snapshot is derived from
+// the shared secret of the two key pairs, so that it is never sent.
+//
+// The public keys are exchanged through the header of the snapshot and the
+// response accepting it, over the authenticated connection between the two
+// stores: the encryption protects the payload from an observer of the
+// decrypted stream, not from a peer impersonating a store.
+type snapshotKeyExchange struct {
+	private, public [curve25519.ScalarSize]byte
+}
+
+func newSnapshotKeyExchange() (*snapshotKeyExchange, error) {
+	kx := &snapshotKeyExchange{}
+	if _, err := crypto_rand.Read(kx.private[:]); err != nil {
+		return nil, err
+	}
+	public, err := curve25519.X25519(kx.private[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	copy(kx.public[:], public)
+	return kx, nil
+}
+
+// publicKey returns the public key to send to the peer.
+func (kx *snapshotKeyExchange) publicKey() []byte {
+	return append([]byte(nil), kx.public[:]...)
+}
+
+// senderCipher returns the cipher sealing the batches of the given snapshot,
+// sent to the recipient with the given public key.
+func (kx *snapshotKeyExchange) senderCipher(
+	snapUUID uuid.UUID, recipientKey []byte,
+) (*snapshotPayloadCipher, error) {
+	return kx.cipher(snapUUID, recipientKey, kx.public[:], recipientKey)
+}
+
+// recipientCipher returns the cipher opening the batches of the given
+// snapshot, received from the sender with the given public key.
+func (kx *snapshotKeyExchange) recipientCipher(
+	snapUUID uuid.UUID, senderKey []byte,
+) (*snapshotPayloadCipher, error) {
+	return kx.cipher(snapUUID, senderKey, senderKey, kx.public[:])
+}
+
+func (kx *snapshotKeyExchange) cipher(
+	snapUUID uuid.UUID, peerKey, senderKey, recipientKey []byte,
+) (*snapshotPayloadCipher, error) {
+	if len(peerKey) != curve25519.PointSize {
+		return nil, errors.Errorf("invalid snapshot encryption public key of %d bytes", len(peerKey))
+	}
+	secret, err := curve25519.X25519(kx.private[:], peerKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid snapshot encryption public key")
+	}
+	// Bind the key to the snapshot, and to the public keys it was derived from.
+	info := make([]byte, 0, len(snapshotPayloadKeyInfo)+len(senderKey)+len(recipientKey))
+	info = append(append(append(info, snapshotPayloadKeyInfo...), senderKey...), recipientKey...)
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, snapUUID.GetBytes(), info), key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshotPayloadCipher{aead: aead}, nil
+}
+
+// newSnapshotRecipientCipher returns the cipher opening the batches of the
+// encrypted snapshot with the given header, and the public key of the
+// recipient to send to the sender.
+func newSnapshotRecipientCipher(
+	header *kvserverpb.SnapshotRequest_Header,
+) (*snapshotPayloadCipher, []byte, error) {
+	if header.Strategy != kvserverpb.SnapshotRequest_KV_BATCH {
+		return nil, nil, errors.Errorf("unsupported snapshot strategy %s", header.Strategy)
+	}
+	snapUUID, err := uuid.FromBytes(header.RaftMessageRequest.Message.Snapshot.Data)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid snapshot")
+	}
+	kx, err := newSnapshotKeyExchange()
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := kx.recipientCipher(snapUUID, header.EncryptionPublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, kx.publicKey(), nil
+}
+
+// snapshotPayloadCipher seals the batches of an encrypted snapshot on the
+// sender, and opens them on the recipient, with AES-256-GCM. The nonce of a
+// batch is its sequence number in the snapshot, which is unique since the key
+// is only used for one snapshot, and lets the recipient detect batches which
+// were dropped, reordered or replayed.
+type snapshotPayloadCipher struct {
+	aead cipher.AEAD
+	// seq is the sequence number of the next batch.
+	seq   uint64
+	nonce [12]byte
+}
+
+func (c *snapshotPayloadCipher) nextNonce() []byte {
+	binary.BigEndian.PutUint64(c.nonce[4:], c.seq)
+	c.seq++
+	return c.nonce[:]
+}
+
+// seal returns the next batch, encrypted. The batch is not modified.
+func (c *snapshotPayloadCipher) seal(repr []byte) []byte {
+	return c.aead.Seal(nil, c.nextNonce(), repr, nil /* additionalData */)
+}
+
+// open returns the next batch, decrypted.
+func (c *snapshotPayloadCipher) open(sealed []byte) ([]byte, error) {
+	repr, err := c.aead.Open(sealed[:0], c.nextNonce(), sealed, nil /* additionalData */)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypting batch %d", c.seq-1)
+	}
+	return repr, nil
+}
+
+// sealFinal returns the message to send after the last batch.
+func (c *snapshotPayloadCipher) sealFinal() []byte {
+	return c.aead.Seal(nil, c.nextNonce(), nil /* plaintext */, snapshotPayloadFinalData)
+}
+
+// openFinal verifies the message sent after the last batch, which shows that
+// all the batches were received.
+func (c *snapshotPayloadCipher) openFinal(sealed []byte) error {
+	if _, err := c.aead.Open(nil, c.nextNonce(), sealed, snapshotPayloadFinalData); err != nil {
+		return errors.Wrapf(err, "snapshot truncated after %d batches", c.seq-1)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSnapshotPayloadCipher checks that the recipient of an encrypted snapshot
+// decrypts the batches sealed by the sender, and detects batches which were
+// tampered with, reordered or dropped.
+func TestSnapshotPayloadCipher(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	snapUUID := uuid.MakeV4()
+	newCiphers := func(t *testing.T) (sender, recipient *snapshotPayloadCipher) {
+		senderKX, err := newSnapshotKeyExchange()
+		require.NoError(t, err)
+		recipientKX, err := newSnapshotKeyExchange()
+		require.NoError(t, err)
+		require.NotEqual(t, senderKX.publicKey(), recipientKX.publicKey())
+		recipient, err = recipientKX.recipientCipher(snapUUID, senderKX.publicKey())
+		require.NoError(t, err)
+		sender, err = senderKX.senderCipher(snapUUID, recipientKX.publicKey())
+		require.NoError(t, err)
+		return sender, recipient
+	}
+	batches := [][]byte{[]byte("foo"), []byte("bar"), {}, bytes.Repeat([]byte("baz"), 1000)}
+
+	t.Run("roundtrip", func(t *testing.T) {
+		sender, recipient := newCiphers(t)
+		for _, repr := range batches {
+			sealed := sender.seal(repr)
+			if len(repr) > 0 {
+				require.NotContains(t, string(sealed), string(repr))
+			}
+			opened, err := recipient.open(sealed)
+			require.NoError(t, err)
+			require.Equal(t, repr, opened)
+		}
+		require.NoError(t, recipient.openFinal(sender.sealFinal()))
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		sender, recipient := newCiphers(t)
+		sealed := sender.seal(batches[0])
+		sealed[0] ^= 1
+		_, err := recipient.open(sealed)
+		require.Error(t, err)
+	})
+
+	t.Run("reordered", func(t *testing.T) {
+		sender, recipient := newCiphers(t)
+		_ = sender.seal(batches[0])
+		_, err := recipient.open(sender.seal(batches[1]))
+		require.Error(t, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		sender, recipient := newCiphers(t)
+		sealed := sender.seal(batches[0])
+		_ = sender.seal(batches[1])
+		_, err := recipient.open(sealed)
+		require.NoError(t, err)
+		require.Error(t, recipient.openFinal(sender.sealFinal()))
+	})
+
+	t.Run("other snapshot", func(t *testing.T) {
+		senderKX, err := newSnapshotKeyExchange()
+		require.NoError(t, err)
+		recipientKX, err := newSnapshotKeyExchange()
+		require.NoError(t, err)
+		sender, err := senderKX.senderCipher(snapUUID, recipientKX.publicKey())
+		require.NoError(t, err)
+		recipient, err := recipientKX.recipientCipher(uuid.MakeV4(), senderKX.publicKey())
+		require.NoError(t, err)
+		_, err = recipient.open(sender.seal(batches[0]))
+		require.Error(t, err)
+	})
+
+	t.Run("header", func(t *testing.T) {
+		senderKX, err := newSnapshotKeyExchange()
+		require.NoError(t, err)
+		var header kvserverpb.SnapshotRequest_Header
+		header.Strategy = kvserverpb.SnapshotRequest_KV_BATCH
+		header.RaftMessageRequest.Message.Snapshot.Data = snapUUID.GetBytes()
+		header.EncryptionPublicKey = senderKX.publicKey()
+		recipient, recipientKey, err := newSnapshotRecipientCipher(&header)
+		require.NoError(t, err)
+		sender, err := senderKX.senderCipher(snapUUID, recipientKey)
+		require.NoError(t, err)
+		opened, err := recipient.open(sender.seal(batches[0]))
+		require.NoError(t, err)
+		require.Equal(t, batches[0], opened)
+
+		header.EncryptionPublicKey = []byte("short")
+		_, _, err = newSnapshotRecipientCipher(&header)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		kx, err := newSnapshotKeyExchange()
+		require.NoError(t, err)
+		_, err = kx.recipientCipher(snapUUID, []byte("short"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,151 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rditer"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// snapshotVerifyApplied enables the verification of the data of the replicas
+// applied from a snapshot against the checksum computed by the sender of the
+// snapshot. The verification runs asynchronously once the snapshot is
+// applied, so that a replica diverging from its snapshot is found right away
+// rather than by the next run of the consistency checker.
+var snapshotVerifyApplied = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.verify_applied.enabled",
+	"if enabled, the data of a replica applied from a snapshot is read back and verified "+
+		"against the checksum computed by the sender of the snapshot",
+	false,
+)
+
+// replicaDataChecksum is the CRC of the replicated key-values of a replica, in
+// the order in which rditer.IterateReplicaKeySpans iterates over them. It is
+// computed by the sender of a snapshot as the snapshot is read from its engine,
+// and verified by the recipient against its replica once the snapshot is
+// applied (see snapshotVerifyApplied).
+type replicaDataChecksum struct {
+	crc uint32
+}
+
+// add adds a key-value made of the given parts to the checksum. Each part is
+// prefixed by its length, so that the checksum depends on how the key-value is
+// split into parts, and on its kind.
+func (c *replicaDataChecksum) add(kind byte, parts ...[]byte) {
+	var buf [binary.MaxVarintLen64]byte
+	buf[0] = kind
+	c.crc = crc32.Update(c.crc, sstChunkCRCTable, buf[:1])
+	for _, p := range parts {
+		n := binary.PutUvarint(buf[:], uint64(len(p)))
+		c.crc = crc32.Update(c.crc, sstChunkCRCTable, buf[:n])
+		c.crc = crc32.Update(c.crc, sstChunkCRCTable, p)
+	}
+}
+
+// addPointKey adds a point key-value to the checksum.
+func (c *replicaDataChecksum) addPointKey(key storage.EngineKey, value []byte) {
+	c.add('p', key.Key, key.Version, value)
+}
+
+// addRangeKey adds a range key-value to the checksum.
+func (c *replicaDataChecksum) addRangeKey(start, end roachpb.Key, version, value []byte) {
+	c.add('r', start, end, version, value)
+}
+
+// bytes returns the checksum, big-endian encoded, as sent to the recipient of
+// a snapshot in its final request.
+func (c *replicaDataChecksum) bytes() []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], c.crc)
+	return b[:]
+}
+
+// computeReplicaDataChecksum computes the checksum of the replicated
+// key-values of the replica with the given descriptor in the given reader.
+func computeReplicaDataChecksum(
+	desc *roachpb.RangeDescriptor, reader storage.Reader,
+) (replicaDataChecksum, error) {
+	var c replicaDataChecksum
+	err := rditer.IterateReplicaKeySpans(desc, reader, true, /* replicatedOnly */
+		func(iter storage.EngineIterator, _ roachpb.Span, keyType storage.IterKeyType) error {
+			var err error
+			switch keyType {
+			case storage.IterKeyTypePointsOnly:
+				for ok := true; ok && err == nil; ok, err = iter.NextEngineKey() {
+					key, err := iter.UnsafeEngineKey()
+					if err != nil {
+						return err
+					}
+					c.addPointKey(key, iter.UnsafeValue())
+				}
+
+			case storage.IterKeyTypeRangesOnly:
+				for ok := true; ok && err == nil; ok, err = iter.NextEngineKey() {
+					bounds, err := iter.EngineRangeBounds()
+					if err != nil {
+						return err
+					}
+					for _, rkv := range iter.EngineRangeKeys() {
+						c.addRangeKey(bounds.Key, bounds.EndKey, rkv.Version, rkv.Value)
+					}
+				}
+
+			default:
+				return errors.AssertionFailedf("unexpected key type %v", keyType)
+			}
+			return err
+		})
+	return c, err
+}
+
+// maybeVerifyAppliedSnapshot verifies, if enabled and if the sender computed
+// it, the data of the replica against the checksum of the snapshot it was just
+// applied from. The data is read from an engine snapshot taken right away,
+// before the replica applies any other command, and verified asynchronously.
+// A mismatch is reported as a replica corruption.
+//
+// The caller must hold raftMu.
+func (r *Replica) maybeVerifyAppliedSnapshot(
+	ctx context.Context, inSnap IncomingSnapshot, desc *roachpb.RangeDescriptor,
+) {
+	if len(inSnap.dataChecksum) != 4 || !snapshotVerifyApplied.Get(&r.store.cfg.Settings.SV) {
+		return
+	}
+	engSnap := r.store.engine.NewSnapshot()
+	taskCtx := r.AnnotateCtx(context.Background())
+	if err := r.store.stopper.RunAsyncTask(taskCtx, "verify-applied-snapshot", func(ctx context.Context) {
+		defer engSnap.Close()
+		c, err := computeReplicaDataChecksum(desc, engSnap)
+		if err != nil {
+			log.Warningf(ctx, "unable to verify applied %s: %v", inSnap, err)
+			return
+		}
+		if expected := binary.BigEndian.Uint32(inSnap.dataChecksum); c.crc != expected {
+			r.store.metrics.RangeSnapshotsAppliedVerificationFailures.Inc(1)
+			log.Errorf(ctx, "replica corruption: data applied from %s has checksum %08x, "+
+				"but the sender computed %08x", inSnap, c.crc, expected)
+			return
+		}
+		r.store.metrics.RangeSnapshotsAppliedVerified.Inc(1)
+		log.VEventf(ctx, 2, "verified data applied from %s", inSnap)
+	}); err != nil {
+		engSnap.Close()
+	}
+}
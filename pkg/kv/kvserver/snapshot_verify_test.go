@@ -0,0 +1,75 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplicaDataChecksum checks that the checksum of the data of a replica
+// computed by the sender of a snapshot matches the one computed from the
+// replica, and that it catches a divergence of the data.
+func TestReplicaDataChecksum(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	eng := storage.NewDefaultInMemForTesting()
+	defer eng.Close()
+
+	desc := roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKey("d"),
+		EndKey:   roachpb.RKeyMax,
+	}
+	for i := 0; i < 100; i++ {
+		require.NoError(t, eng.PutUnversioned(roachpb.Key(fmt.Sprintf("d%03d", i)), []byte("foo")))
+	}
+	require.NoError(t, eng.PutEngineRangeKey(roachpb.Key("e"), roachpb.Key("f"), []byte{1}, nil))
+
+	snap := &OutgoingSnapshot{
+		SnapUUID:   uuid.MakeV4(),
+		EngineSnap: eng.NewSnapshot(),
+		State:      kvserverpb.ReplicaState{Desc: &desc},
+	}
+	defer snap.Close()
+	newBatch := func() storage.Batch { return eng.NewUnindexedBatch(true /* writeOnly */) }
+	var sent replicaDataChecksum
+	_, _, err := batchSnapshotKVs(snap, newBatch, 256, newSnapshotTimingTag(), &sent,
+		func([]byte) error { return nil })
+	require.NoError(t, err)
+	require.NotZero(t, sent.crc)
+	require.Len(t, sent.bytes(), 4)
+
+	applied, err := computeReplicaDataChecksum(&desc, eng)
+	require.NoError(t, err)
+	require.Equal(t, sent, applied)
+
+	// A changed value, or a missing range key, is caught.
+	require.NoError(t, eng.PutUnversioned(roachpb.Key("d042"), []byte("bar")))
+	diverged, err := computeReplicaDataChecksum(&desc, eng)
+	require.NoError(t, err)
+	require.NotEqual(t, sent, diverged)
+
+	require.NoError(t, eng.PutUnversioned(roachpb.Key("d042"), []byte("foo")))
+	require.NoError(t, eng.ClearEngineRangeKey(roachpb.Key("e"), roachpb.Key("f"), []byte{1}))
+	diverged, err = computeReplicaDataChecksum(&desc, eng)
+	require.NoError(t, err)
+	require.NotEqual(t, sent, diverged)
+}
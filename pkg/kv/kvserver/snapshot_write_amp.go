@@ -0,0 +1,79 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import "github.com/cockroachdb/pebble"
+
+// snapshotWriteBytes accounts for the bytes written to the disk of a store to
+// apply a snapshot, to quantify the write amplification of the snapshot path:
+// the ratio of the physical bytes written to the logical bytes of the
+// snapshot.
+type snapshotWriteBytes struct {
+	// logical is the size of the key-values of the snapshot.
+	logical int64
+	// scratch is the number of bytes written to the scratch staging the
+	// snapshot.
+	scratch int64
+	// apply is the number of bytes written to apply the staged SSTs. Ingested
+	// SSTs are linked into the engine rather than copied, so this is only the
+	// size of the batch applying the SSTs, if they were applied rather than
+	// ingested, which is written to the WAL and then flushed into L0.
+	apply int64
+	// compaction is the estimated number of bytes rewritten by compactions
+	// because of the snapshot: the data which lands in L0 is rewritten when L0
+	// is compacted into the base level. This is a lower bound, since the data
+	// may be rewritten again by the compactions of the lower levels, which
+	// can't be attributed to the snapshot.
+	compaction int64
+}
+
+func makeSnapshotWriteBytes(
+	inSnap IncomingSnapshot, ingestStats pebble.IngestOperationStats,
+) snapshotWriteBytes {
+	b := snapshotWriteBytes{
+		logical:    inSnap.DataSize,
+		scratch:    inSnap.SSTStorageScratch.Stats().BytesWritten,
+		compaction: int64(ingestStats.ApproxIngestedIntoL0Bytes),
+	}
+	if batchBytes := inSnap.SSTStorageScratch.appliedBatchBytes; batchBytes > 0 {
+		// The batch is written to the WAL, flushed into L0 with the memtable,
+		// and compacted out of L0.
+		b.apply = 2 * batchBytes
+		b.compaction = batchBytes
+	}
+	return b
+}
+
+// physical returns the number of bytes written to the disk for the snapshot.
+func (b snapshotWriteBytes) physical() int64 {
+	return b.scratch + b.apply + b.compaction
+}
+
+// amplification returns the ratio of the physical bytes written for the
+// snapshot to its logical bytes, or 0 if the snapshot is empty.
+func (b snapshotWriteBytes) amplification() float64 {
+	if b.logical <= 0 {
+		return 0
+	}
+	return float64(b.physical()) / float64(b.logical)
+}
+
+// recordSnapshotWriteBytes records the bytes written to apply a snapshot, and
+// updates the write amplification of the snapshots applied by the store.
+func (sm *StoreMetrics) recordSnapshotWriteBytes(b snapshotWriteBytes) {
+	sm.RangeSnapshotIngestLogicalBytes.Inc(b.logical)
+	sm.RangeSnapshotIngestPhysicalBytes.Inc(b.physical())
+	sm.RangeSnapshotIngestCompactionBytes.Inc(b.compaction)
+	if logical := sm.RangeSnapshotIngestLogicalBytes.Count(); logical > 0 {
+		sm.RangeSnapshotIngestWriteAmplification.Update(
+			float64(sm.RangeSnapshotIngestPhysicalBytes.Count()) / float64(logical))
+	}
+}
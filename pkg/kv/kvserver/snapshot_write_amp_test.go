@@ -0,0 +1,61 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/pebble"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSnapshotWriteBytes checks the accounting of the bytes written to apply
+// snapshots, whether they are ingested or applied through a batch.
+func TestSnapshotWriteBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	newSnap := func(dataSize, scratchBytes, batchBytes int64) IncomingSnapshot {
+		scratch := &SSTSnapshotStorageScratch{appliedBatchBytes: batchBytes}
+		scratch.stats.BytesWritten = scratchBytes
+		return IncomingSnapshot{DataSize: dataSize, SSTStorageScratch: scratch}
+	}
+	m := newStoreMetrics(time.Minute)
+
+	// An ingested snapshot, half of which landed in L0.
+	ingested := makeSnapshotWriteBytes(newSnap(100, 80, 0),
+		pebble.IngestOperationStats{Bytes: 80, ApproxIngestedIntoL0Bytes: 40})
+	require.Equal(t, snapshotWriteBytes{logical: 100, scratch: 80, compaction: 40}, ingested)
+	require.Equal(t, int64(120), ingested.physical())
+	require.InDelta(t, 1.2, ingested.amplification(), 1e-9)
+	m.recordSnapshotWriteBytes(ingested)
+	require.Equal(t, int64(100), m.RangeSnapshotIngestLogicalBytes.Count())
+	require.Equal(t, int64(120), m.RangeSnapshotIngestPhysicalBytes.Count())
+	require.Equal(t, int64(40), m.RangeSnapshotIngestCompactionBytes.Count())
+	require.InDelta(t, 1.2, m.RangeSnapshotIngestWriteAmplification.Value(), 1e-9)
+
+	// A snapshot applied through a batch, which is written to the WAL, flushed
+	// and compacted.
+	batch := makeSnapshotWriteBytes(newSnap(100, 80, 110), pebble.IngestOperationStats{})
+	require.Equal(t, snapshotWriteBytes{logical: 100, scratch: 80, apply: 220, compaction: 110}, batch)
+	require.InDelta(t, 4.1, batch.amplification(), 1e-9)
+	m.recordSnapshotWriteBytes(batch)
+	require.Equal(t, int64(200), m.RangeSnapshotIngestLogicalBytes.Count())
+	require.Equal(t, int64(530), m.RangeSnapshotIngestPhysicalBytes.Count())
+	require.InDelta(t, 2.65, m.RangeSnapshotIngestWriteAmplification.Value(), 1e-9)
+
+	// An empty snapshot doesn't divide by zero.
+	empty := makeSnapshotWriteBytes(newSnap(0, 0, 0), pebble.IngestOperationStats{})
+	require.Zero(t, empty.amplification())
+}
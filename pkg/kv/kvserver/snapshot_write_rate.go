@@ -0,0 +1,164 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// snapshotAdaptiveWriteRateEnabled enables the adaptive limit on the rate at
+// which the SSTs of incoming snapshots are written (see
+// snapshotWriteRateController).
+var snapshotAdaptiveWriteRateEnabled = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.adaptive_write_rate.enabled",
+	"if enabled, the rate at which the SSTs of incoming snapshots are written is "+
+		"lowered while the disk of the store is saturated",
+	true,
+)
+
+// snapshotAdaptiveWriteRateMin is the rate below which the adaptive limit
+// never goes, so that snapshots keep making progress.
+var snapshotAdaptiveWriteRateMin = settings.RegisterByteSizeSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.adaptive_write_rate.min",
+	"the minimum rate (bytes/sec) at which the SSTs of incoming snapshots are written "+
+		"while the disk of the store is saturated",
+	8<<20, // 8 MiB
+	settings.PositiveInt,
+)
+
+const (
+	// snapshotWriteRateL0SublevelThreshold is the number of L0 sublevels from
+	// which the disk is considered saturated: compactions are not keeping up
+	// with the writes.
+	snapshotWriteRateL0SublevelThreshold = 10
+	// snapshotWriteRateDebtThreshold is the estimated compaction debt from
+	// which the disk is considered saturated.
+	snapshotWriteRateDebtThreshold = 4 << 30 // 4 GiB
+	// snapshotWriteRateDecrease is the factor by which the rate is lowered
+	// every time the disk is found saturated.
+	snapshotWriteRateDecrease = 0.5
+	// snapshotWriteRateIncrease is the factor by which the rate is raised
+	// every time the disk is found to have headroom.
+	snapshotWriteRateIncrease = 1.25
+	// snapshotWriteRateMaxFactor is how far above the write throughput of the
+	// snapshots the rate can be raised before the limit is lifted.
+	snapshotWriteRateMaxFactor = 4
+)
+
+// snapshotWriteRateController limits the rate at which the SSTs of incoming
+// snapshots are written, on top of the store's bulk IO write budget, based on
+// the health of the disk. It is periodically fed with the engine metrics: as
+// long as the disk is saturated, that is Pebble stalls writes, reports a slow
+// disk, or accumulates L0 sublevels or compaction debt, it halves the rate;
+// once there is headroom, it ramps the rate back up, until the limit is lifted
+// altogether. The first limit is half the write throughput observed since the
+// previous adjustment.
+type snapshotWriteRateController struct {
+	st      *cluster.Settings
+	limiter *quotapool.RateLimiter
+
+	mu struct {
+		syncutil.Mutex
+		// rate is the current limit, or +Inf if the rate is not limited.
+		rate float64
+		// The following are the values observed at the previous adjustment.
+		lastAdjusted     time.Time
+		lastWriteStalls  int64
+		lastDiskSlows    int64
+		lastBytesWritten int64
+	}
+}
+
+func newSnapshotWriteRateController(st *cluster.Settings) *snapshotWriteRateController {
+	c := &snapshotWriteRateController{
+		st:      st,
+		limiter: quotapool.NewRateLimiter("snapshot-write-rate", quotapool.Limit(math.Inf(1)), bulkIOWriteBurst),
+	}
+	c.mu.rate = math.Inf(1)
+	return c
+}
+
+// waitN blocks until n bytes can be written.
+func (c *snapshotWriteRateController) waitN(ctx context.Context, n int64) error {
+	return c.limiter.WaitN(ctx, n)
+}
+
+// currentRate returns the current limit, or +Inf if the rate is not limited.
+func (c *snapshotWriteRateController) currentRate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mu.rate
+}
+
+// adjust updates the limit based on the engine metrics, and the total number
+// of bytes written to the snapshot scratches, observed at the given time.
+func (c *snapshotWriteRateController) adjust(
+	ctx context.Context, now time.Time, m storage.Metrics, bytesWritten int64,
+) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	saturated := m.WriteStallCount > c.mu.lastWriteStalls ||
+		m.DiskSlowCount > c.mu.lastDiskSlows ||
+		(m.Metrics != nil && (m.Levels[0].Sublevels >= snapshotWriteRateL0SublevelThreshold ||
+			m.Compact.EstimatedDebt >= snapshotWriteRateDebtThreshold))
+	var throughput float64
+	if elapsed := now.Sub(c.mu.lastAdjusted); !c.mu.lastAdjusted.IsZero() && elapsed > 0 {
+		throughput = float64(bytesWritten-c.mu.lastBytesWritten) / elapsed.Seconds()
+	}
+	c.mu.lastAdjusted = now
+	c.mu.lastWriteStalls = m.WriteStallCount
+	c.mu.lastDiskSlows = m.DiskSlowCount
+	c.mu.lastBytesWritten = bytesWritten
+
+	rate := c.mu.rate
+	minRate := float64(snapshotAdaptiveWriteRateMin.Get(&c.st.SV))
+	switch {
+	case !snapshotAdaptiveWriteRateEnabled.Get(&c.st.SV):
+		rate = math.Inf(1)
+	case saturated:
+		if math.IsInf(rate, 1) {
+			rate = throughput
+		}
+		rate *= snapshotWriteRateDecrease
+		if rate < minRate {
+			rate = minRate
+		}
+	case !math.IsInf(rate, 1):
+		rate *= snapshotWriteRateIncrease
+		// Lift the limit once it is well above what the snapshots need.
+		if rate > snapshotWriteRateMaxFactor*math.Max(throughput, minRate) {
+			rate = math.Inf(1)
+		}
+	}
+	if rate == c.mu.rate {
+		return
+	}
+	if math.IsInf(rate, 1) {
+		log.Infof(ctx, "lifting the snapshot write rate limit")
+	} else if saturated {
+		log.Infof(ctx, "disk saturated, limiting the snapshot write rate to %s/s",
+			humanizeutil.IBytes(int64(rate)))
+	}
+	c.mu.rate = rate
+	c.limiter.UpdateLimit(quotapool.Limit(rate), bulkIOWriteBurst)
+}
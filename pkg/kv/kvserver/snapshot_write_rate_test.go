@@ -0,0 +1,74 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotWriteRateController(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	c := newSnapshotWriteRateController(st)
+	now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var m storage.Metrics
+	var written int64
+	adjust := func(bytes int64) float64 {
+		now = now.Add(10 * time.Second)
+		written += bytes
+		c.adjust(ctx, now, m, written)
+		return c.currentRate()
+	}
+	const mib = 1 << 20
+
+	// The rate is not limited while the disk is healthy.
+	require.True(t, math.IsInf(adjust(0), 1))
+	require.True(t, math.IsInf(adjust(400*mib), 1))
+
+	// Once the disk is saturated, the rate is limited to half the observed
+	// throughput, and halved as long as the disk remains saturated, down to
+	// the minimum rate.
+	m.WriteStallCount++
+	require.Equal(t, float64(20*mib), adjust(400*mib))
+	m.DiskSlowCount++
+	require.Equal(t, float64(10*mib), adjust(100*mib))
+	m.WriteStallCount++
+	require.Equal(t, float64(8*mib), adjust(100*mib))
+
+	// With headroom, the rate ramps back up while the snapshots use it.
+	require.Equal(t, float64(10*mib), adjust(80*mib))
+	require.Equal(t, 12.5*mib, adjust(100*mib))
+
+	// The limit is lifted once well above the needs of the snapshots.
+	for i := 0; !math.IsInf(c.currentRate(), 1); i++ {
+		require.Less(t, i, 10)
+		adjust(0)
+	}
+
+	// Disabling the adaptive limit lifts it right away.
+	m.WriteStallCount++
+	require.Equal(t, float64(8*mib), adjust(0))
+	snapshotAdaptiveWriteRateEnabled.Override(ctx, &st.SV, false)
+	m.WriteStallCount++
+	require.True(t, math.IsInf(adjust(0), 1))
+}
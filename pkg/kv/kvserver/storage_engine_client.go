@@ -71,3 +71,23 @@ func (c *StorageEngineClient) SetCompactionConcurrency(
 	}
 	return nil
 }
+
+// CleanupStoreScratch is a tree.CleanupStoreScratchFunc.
+func (c *StorageEngineClient) CleanupStoreScratch(
+	ctx context.Context, nodeID int32,
+) (map[int32]int64, error) {
+	conn, err := c.nd.Dial(ctx, roachpb.NodeID(nodeID), rpc.DefaultClass)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not dial node ID %d", nodeID)
+	}
+	client := NewPerStoreClient(conn)
+	resp, err := client.CleanupScratch(ctx, &CleanupScratchRequest{})
+	if err != nil {
+		return nil, err
+	}
+	reclaimed := make(map[int32]int64, len(resp.Stores))
+	for _, s := range resp.Stores {
+		reclaimed[int32(s.StoreID)] = s.BytesReclaimed
+	}
+	return reclaimed, nil
+}
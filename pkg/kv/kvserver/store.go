@@ -87,7 +87,6 @@ import (
 	"github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/redact"
 	"go.etcd.io/etcd/raft/v3"
-	"golang.org/x/time/rate"
 )
 
 const (
@@ -742,6 +741,8 @@ type Store struct {
 	limiters           batcheval.Limiters
 	txnWaitMetrics     *txnwait.Metrics
 	sstSnapshotStorage SSTSnapshotStorage
+	sideloadLimiter    *quotapool.ChildRateLimiter // Rate limits sideloaded SST writes
+	snapshotHistory    snapshotHistory
 	protectedtsReader  spanconfig.ProtectedTSReader
 	ctSender           *sidetransport.Sender
 
@@ -886,7 +887,7 @@ type Store struct {
 	// modified by a concurrent HandleRaftRequest. (#4476)
 
 	mu struct {
-		syncutil.RWMutex
+		syncutil.InstrumentedRWMutex
 		// Map of replicas by Range ID (map[roachpb.RangeID]*Replica).
 		// May be read without holding Store.mu.
 		replicasByRangeID rangeIDReplicaMap
@@ -1076,6 +1077,10 @@ type StoreConfig struct {
 	KVMemoryMonitor        *mon.BytesMonitor
 	RangefeedBudgetFactory *rangefeed.BudgetFactory
 
+	// SnapshotScratchBudget, if set, bounds the disk space used to stage
+	// incoming snapshots by all the stores of the node.
+	SnapshotScratchBudget *SnapshotScratchBudget
+
 	// SpanConfigsDisabled determines whether we're able to use the span configs
 	// infrastructure or not.
 	// TODO(richardjcai): We can likely remove this.
@@ -1229,6 +1234,7 @@ func NewStore(
 	s.coalescedMu.heartbeatResponses = map[roachpb.StoreIdent][]kvserverpb.RaftHeartbeat{}
 	s.coalescedMu.Unlock()
 
+	s.mu.Init("kvserver.Store.mu")
 	s.mu.Lock()
 	s.mu.replicaPlaceholders = map[roachpb.RangeID]*ReplicaPlaceholder{}
 	s.mu.replicasByKey = newStoreReplicaBTree()
@@ -1256,10 +1262,13 @@ func NewStore(
 		s.renewableLeasesSignal = make(chan struct{}, 1)
 	}
 
-	s.limiters.BulkIOWriteRate = rate.NewLimiter(rate.Limit(bulkIOWriteLimit.Get(&cfg.Settings.SV)), bulkIOWriteBurst)
-	bulkIOWriteLimit.SetOnChange(&cfg.Settings.SV, func(ctx context.Context) {
-		s.limiters.BulkIOWriteRate.SetLimit(rate.Limit(bulkIOWriteLimit.Get(&cfg.Settings.SV)))
-	})
+	// The bulk IO write budget is shared by the staging of incoming snapshots
+	// and the writes of the SSTs of AddSSTable commands: any of them can use the
+	// whole budget while the others are idle, and each is assured a share of it
+	// otherwise (see bulkIOWriteGovernor).
+	bulkIOWrite := newBulkIOWriteGovernor(cfg.Settings)
+	s.limiters.BulkIOWriteRate = bulkIOWrite.limiter
+	s.sideloadLimiter = bulkIOWrite.source(bulkIOWriteAddSSTable)
 	s.limiters.ConcurrentExportRequests = limit.MakeConcurrentRequestLimiter(
 		"exportRequestLimiter", int(ExportRequestsLimit.Get(&cfg.Settings.SV)),
 	)
@@ -1271,10 +1280,55 @@ func NewStore(
 
 	// The snapshot storage is usually empty at this point since it is cleared
 	// after each snapshot application, except when the node crashed right before
-	// it can clean it up. If this fails it's not a correctness issue since the
-	// storage is also cleared before receiving a snapshot.
-	s.sstSnapshotStorage = NewSSTSnapshotStorage(s.engine, s.limiters.BulkIOWriteRate)
-	if err := s.sstSnapshotStorage.Clear(); err != nil {
+	// it can clean it up. The leftovers are removed before the store accepts any
+	// snapshot. If this fails it's not a correctness issue since the storage is
+	// also cleared before receiving a snapshot.
+	s.sstSnapshotStorage = NewSSTSnapshotStorage(
+		s.engine, bulkIOWrite.source(bulkIOWriteSnapshotRebalance))
+	s.sstSnapshotStorage.mu.Init("kvserver.SSTSnapshotStorage.mu")
+	s.sstSnapshotStorage.recoveryLimiter = bulkIOWrite.source(bulkIOWriteSnapshotRecovery)
+	s.sstSnapshotStorage.addSSTableLimiter = bulkIOWrite.source(bulkIOWriteAddSSTable)
+	s.sstSnapshotStorage.budget = cfg.SnapshotScratchBudget
+	s.sstSnapshotStorage.maxBytes = func() int64 {
+		return snapshotScratchSpaceMaxBytes.Get(&cfg.Settings.SV)
+	}
+	s.sstSnapshotStorage.maxFiles = func() int64 {
+		return snapshotScratchMaxFiles.Get(&cfg.Settings.SV)
+	}
+	s.sstSnapshotStorage.maxOverrunFactor = func() float64 {
+		return snapshotScratchSizeOverrunFactor.Get(&cfg.Settings.SV)
+	}
+	s.sstSnapshotStorage.applyAsBatchMaxBytes = func() int64 {
+		return snapshotApplyAsBatchMaxBytes.Get(&cfg.Settings.SV)
+	}
+	s.sstSnapshotStorage.maxQuarantined = func() int64 {
+		return snapshotQuarantineMaxScratches.Get(&cfg.Settings.SV)
+	}
+	s.sstSnapshotStorage.bytesPerSync = func() int64 {
+		return snapshotScratchBytesPerSync.Get(&cfg.Settings.SV)
+	}
+	s.sstSnapshotStorage.syncPolicy = func() scratchSyncPolicy {
+		return scratchSyncPolicy(snapshotScratchSyncPolicy.Get(&cfg.Settings.SV))
+	}
+	s.sstSnapshotStorage.writeBufferSize = func() int64 {
+		return snapshotScratchWriteBufferSize.Get(&cfg.Settings.SV)
+	}
+	s.sstSnapshotStorage.dropPageCache = func() bool {
+		return snapshotScratchDropPageCache.Get(&cfg.Settings.SV)
+	}
+	s.sstSnapshotStorage.setPausedBySetting(snapshotScratchWritesPaused.Get(&cfg.Settings.SV))
+	snapshotScratchWritesPaused.SetOnChange(&cfg.Settings.SV, func(ctx context.Context) {
+		s.sstSnapshotStorage.setPausedBySetting(snapshotScratchWritesPaused.Get(&cfg.Settings.SV))
+	})
+	s.sstSnapshotStorage.beforeFileOp = cfg.TestingKnobs.BeforeSnapshotScratchFileOp
+	s.sstSnapshotStorage.metrics = s.metrics
+	s.sstSnapshotStorage.writeRate = newSnapshotWriteRateController(cfg.Settings)
+	if ac := cfg.KVAdmissionController; ac != nil {
+		s.sstSnapshotStorage.admit = func(ctx context.Context, n int64) (bool, error) {
+			return ac.AdmitSnapshotWrite(ctx, s.StoreID(), n)
+		}
+	}
+	if _, err := s.sstSnapshotStorage.ClearLeftovers(ctx); err != nil {
 		log.Warningf(ctx, "failed to clear snapshot storage: %v", err)
 	}
 	s.protectedtsReader = cfg.ProtectedTimestampReader
@@ -2062,6 +2116,15 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 	// Connect rangefeeds to closed timestamp updates.
 	s.startRangefeedUpdater(ctx)
 
+	// From now on, the snapshot storage trash is emptied in the background,
+	// starting with what a crash may have left in it.
+	s.sstSnapshotStorage.runAsync = func(taskName string, f func(context.Context)) error {
+		return s.stopper.RunAsyncTask(ctx, taskName, f)
+	}
+	s.sstSnapshotStorage.emptyTrash()
+	s.startSnapshotScratchCleaner(ctx)
+	s.startSnapshotScratchProber(ctx)
+
 	if s.replicateQueue != nil {
 		s.storeRebalancer = NewStoreRebalancer(
 			s.cfg.AmbientCtx, s.cfg.Settings, s.replicateQueue, s.replRankings)
@@ -2903,6 +2966,9 @@ func (s *Store) Clock() *hlc.Clock { return s.cfg.Clock }
 // Engine accessor.
 func (s *Store) Engine() storage.Engine { return s.engine }
 
+// SSTSnapshotStorage accessor.
+func (s *Store) SSTSnapshotStorage() *SSTSnapshotStorage { return &s.sstSnapshotStorage }
+
 // DB accessor.
 func (s *Store) DB() *kv.DB { return s.cfg.DB }
 
@@ -2957,6 +3023,14 @@ func (s *Store) Capacity(ctx context.Context, useCached bool) (roachpb.StoreCapa
 	if err != nil {
 		return roachpb.StoreCapacity{}, err
 	}
+	// Count the SSTs staged for incoming snapshots as used, so that the
+	// allocator avoids stores whose disks are already strained by snapshots in
+	// flight. The engine only counts them if they are staged in its auxiliary
+	// directory.
+	auxDir := s.engine.GetAuxiliaryDir()
+	if scratchDir := s.sstSnapshotStorage.Dir(); !strings.HasPrefix(scratchDir, auxDir+string(filepath.Separator)) {
+		capacity.Used += s.sstSnapshotStorage.UsedBytes()
+	}
 
 	now := s.cfg.Clock.NowAsClockTimestamp()
 	var leaseCount int32
@@ -3307,6 +3381,9 @@ func (s *Store) ComputeMetrics(ctx context.Context, tick int) error {
 	// Get the latest engine metrics.
 	m := s.engine.GetMetrics()
 	s.metrics.updateEngineMetrics(m)
+	if c := s.sstSnapshotStorage.writeRate; c != nil {
+		c.adjust(ctx, timeutil.Now(), m, s.metrics.SnapshotScratchBytesWritten.Count())
+	}
 
 	// Get engine Env stats.
 	envStats, err := s.engine.GetEnvStats()
@@ -3725,6 +3802,11 @@ type KVAdmissionController interface {
 	// replicated to a raft follower, that have not been subject to admission
 	// control.
 	FollowerStoreWriteBytes(storeID roachpb.StoreID, followerWriteBytes followerStoreWriteBytes)
+	// AdmitSnapshotWrite must be called before writing n bytes to stage an
+	// incoming snapshot on the given store. It blocks until the write is
+	// admitted, and returns whether it was subject to admission control. If
+	// not, the caller is responsible for pacing the write.
+	AdmitSnapshotWrite(ctx context.Context, storeID roachpb.StoreID, n int64) (admitted bool, err error)
 }
 
 // TenantWeightProvider can be periodically asked to provide the tenant
@@ -3943,6 +4025,50 @@ func (n *KVAdmissionControllerImpl) FollowerStoreWriteBytes(
 		followerWriteBytes.numEntries, followerWriteBytes.StoreWorkDoneInfo)
 }
 
+// snapshotAdmissionControlEnabled subjects the writes staging incoming
+// snapshots to the admission control of the store.
+var snapshotAdmissionControlEnabled = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.admission_control.enabled",
+	"if enabled, the writes staging the SSTs of incoming snapshots are subject to "+
+		"the admission control of the store, as elastic work, instead of the bulk "+
+		"IO write rate limit",
+	false,
+)
+
+// AdmitSnapshotWrite implements the KVAdmissionController interface.
+//
+// The writes are admitted as elastic work, so that they are subject to the
+// disk bandwidth tokens of the store, and yield to foreground writes. Their
+// bytes are accounted for as ingested bytes, since the SSTs are ingested
+// once the snapshot is received; the stats of the ingestion itself are then
+// ignored (see SnapshotIngested).
+func (n *KVAdmissionControllerImpl) AdmitSnapshotWrite(
+	ctx context.Context, storeID roachpb.StoreID, bytes int64,
+) (admitted bool, err error) {
+	if n.storeGrantCoords == nil || !snapshotAdmissionControlEnabled.Get(&n.settings.SV) {
+		return false, nil
+	}
+	storeAdmissionQ := n.storeGrantCoords.TryGetQueueForStore(int32(storeID))
+	if storeAdmissionQ == nil {
+		return false, nil
+	}
+	h, err := storeAdmissionQ.Admit(ctx, admission.StoreWriteWorkInfo{
+		WorkInfo: admission.WorkInfo{
+			TenantID:   roachpb.SystemTenantID,
+			Priority:   admissionpb.BulkNormalPri,
+			CreateTime: timeutil.Now().UnixNano(),
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	if !h.AdmissionEnabled() {
+		return false, nil
+	}
+	return true, storeAdmissionQ.AdmittedWorkDone(h, admission.StoreWorkDoneInfo{IngestedBytes: bytes})
+}
+
 // ProvisionedBandwidthForAdmissionControl set a value of the provisioned
 // bandwidth for each store in the cluster.
 var ProvisionedBandwidthForAdmissionControl = settings.RegisterByteSizeSetting(
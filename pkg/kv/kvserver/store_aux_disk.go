@@ -0,0 +1,339 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+)
+
+// AuxiliaryDiskUsage returns the number of bytes used by the files in the
+// store's auxiliary directory. This includes the scratch space used to stage
+// incoming snapshots and sideloaded SSTs, but excludes the emergency ballast.
+//
+// The directory is walked on every call, so this should not be called from
+// performance critical code.
+func (s *Store) AuxiliaryDiskUsage() (int64, error) {
+	ballast := base.EmergencyBallastFile(filepath.Join, filepath.Dir(s.engine.GetAuxiliaryDir()))
+	return dirSize(s.engine, s.engine.GetAuxiliaryDir(), ballast)
+}
+
+// CheckAuxiliaryDiskHealth returns an error if the disk space consumed by the
+// store's auxiliary directory is what pushes the store below its free-space
+// target, that is, if the store would have enough headroom were it not for
+// the auxiliary files (e.g. the staging files of in-flight snapshots). The
+// free-space target is the headroom the allocator keeps free on every store
+// (see allocator.MaxFractionUsedThreshold).
+//
+// It also returns an error if the last probe of the snapshot storage directory
+// failed (see startSnapshotScratchProber).
+//
+// The check is based on the cached store capacity and is meant to be cheap
+// enough to be consulted by the node's readiness check.
+func (s *Store) CheckAuxiliaryDiskHealth(ctx context.Context) error {
+	if err := s.sstSnapshotStorage.ProbeError(); err != nil {
+		return errors.Wrapf(err, "store s%d", s.StoreID())
+	}
+	capacity, err := s.Capacity(ctx, true /* useCached */)
+	if err != nil {
+		return err
+	}
+	if capacity.Available >= freeSpaceTarget(capacity) {
+		// Fast path: nothing to worry about, don't walk the auxiliary directory.
+		return nil
+	}
+	auxBytes, err := s.AuxiliaryDiskUsage()
+	if err != nil {
+		return err
+	}
+	if !auxiliaryDiskDegraded(capacity, auxBytes) {
+		return nil
+	}
+	return errors.Errorf(
+		"store s%d: auxiliary files (%s) push available disk space (%s) below the free-space target (%s)",
+		s.StoreID(), humanizeutil.IBytes(auxBytes), humanizeutil.IBytes(capacity.Available),
+		humanizeutil.IBytes(freeSpaceTarget(capacity)))
+}
+
+// snapshotScratchCleanupInterval is the interval at which the expired snapshot
+// scratches are removed (see snapshotScratchTTL).
+const snapshotScratchCleanupInterval = 10 * time.Minute
+
+// startSnapshotScratchCleaner periodically removes the snapshot scratches
+// which are no longer in flight and have not been written to for longer than
+// kv.snapshot_receiver.scratch_ttl. The scratches leaked while the store runs
+// would otherwise only be removed when it restarts.
+func (s *Store) startSnapshotScratchCleaner(ctx context.Context) {
+	_ = s.stopper.RunAsyncTask(ctx, "snapshot-scratch-cleaner", func(ctx context.Context) {
+		ticker := time.NewTicker(snapshotScratchCleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ttl := snapshotScratchTTL.Get(&s.ClusterSettings().SV)
+				if ttl == 0 {
+					continue
+				}
+				reclaimed, err := s.sstSnapshotStorage.ClearExpired(timeutil.Now(), ttl)
+				if err != nil {
+					log.Warningf(ctx, "failed to clear expired snapshot scratches: %v", err)
+				}
+				if reclaimed > 0 {
+					log.Infof(ctx, "removed expired snapshot scratches, reclaiming %s",
+						humanizeutil.IBytes(reclaimed))
+				}
+			case <-s.stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// snapshotScratchProbeInterval is the interval at which the snapshot storage
+// directory is probed.
+const snapshotScratchProbeInterval = time.Minute
+
+// startSnapshotScratchProber periodically probes the snapshot storage
+// directory (see SSTSnapshotStorage.Probe). While the probes fail, the store
+// is reported as degraded by CheckAuxiliaryDiskHealth.
+func (s *Store) startSnapshotScratchProber(ctx context.Context) {
+	_ = s.stopper.RunAsyncTask(ctx, "snapshot-scratch-prober", func(ctx context.Context) {
+		ticker := time.NewTicker(snapshotScratchProbeInterval)
+		defer ticker.Stop()
+		var failing bool
+		for {
+			if err := s.sstSnapshotStorage.Probe(); err != nil {
+				s.metrics.SnapshotScratchProbeFailures.Inc(1)
+				if !failing {
+					log.Warningf(ctx, "snapshot storage is unhealthy: %v", err)
+				}
+				failing = true
+			} else if failing {
+				log.Infof(ctx, "snapshot storage is healthy again")
+				failing = false
+			}
+			select {
+			case <-ticker.C:
+			case <-s.stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// CleanupScratch removes the orphaned files of the store's auxiliary
+// directory, that is the staging files of snapshots that are no longer in
+// flight and the sideloaded SSTs of ranges that no longer have a replica on
+// the store. This reconciliation normally only happens when the store starts,
+// for example after a crash prevented the files from being cleaned up. It
+// returns the number of bytes reclaimed.
+func (s *Store) CleanupScratch(ctx context.Context) (int64, error) {
+	snapBytes, err := s.sstSnapshotStorage.ClearOrphaned()
+	if err != nil {
+		return snapBytes, errors.Wrap(err, "clearing snapshot storage")
+	}
+	sideloadedBytes, err := s.clearOrphanedSideloadedDirs()
+	if err != nil {
+		return snapBytes + sideloadedBytes, errors.Wrap(err, "clearing sideloaded storage")
+	}
+	log.Infof(ctx, "reclaimed %s of orphaned snapshot files and %s of orphaned sideloaded files",
+		humanizeutil.IBytes(snapBytes), humanizeutil.IBytes(sideloadedBytes))
+	return snapBytes + sideloadedBytes, nil
+}
+
+// orphanedSideloadedSuffix is appended to the names of the orphaned sideloaded
+// storage directories which are being removed.
+const orphanedSideloadedSuffix = ".orphaned"
+
+// clearOrphanedSideloadedDirs removes the sideloaded storage directories of
+// the ranges that don't have a replica on the store, and returns the number of
+// bytes reclaimed.
+func (s *Store) clearOrphanedSideloadedDirs() (int64, error) {
+	baseDir := filepath.Join(s.engine.GetAuxiliaryDir(), "sideloading")
+	shards, err := s.engine.List(baseDir)
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var reclaimed int64
+	for _, shard := range shards {
+		shardDir := filepath.Join(baseDir, shard)
+		names, err := s.engine.List(shardDir)
+		if err != nil {
+			if oserror.IsNotExist(err) {
+				continue
+			}
+			return reclaimed, err
+		}
+		for _, name := range names {
+			path := filepath.Join(shardDir, name)
+			// Directories left behind by a previous removal are removed as is.
+			if !strings.HasSuffix(name, orphanedSideloadedSuffix) {
+				rangeID, ok := parseSideloadedDirName(name)
+				if !ok {
+					continue
+				}
+				detached, orphaned, err := s.detachOrphanedSideloadedDir(rangeID, path)
+				if err != nil {
+					return reclaimed, err
+				}
+				if !orphaned {
+					continue
+				}
+				path = detached
+			}
+			size, err := removeAllWithSize(s.engine, path)
+			reclaimed += size
+			if err != nil {
+				return reclaimed, err
+			}
+		}
+	}
+	return reclaimed, nil
+}
+
+// detachOrphanedSideloadedDir renames the given sideloaded storage directory
+// of the given range out of the way if the range doesn't have a replica on the
+// store, and returns its new path. The check and the rename happen under the
+// store lock, which prevents a replica of the range from being created, and
+// its sideloaded storage from being populated, in the meantime. The contents
+// of the directory can then be removed without holding the lock.
+func (s *Store) detachOrphanedSideloadedDir(
+	rangeID roachpb.RangeID, path string,
+) (_ string, orphaned bool, _ error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.mu.replicasByRangeID.Load(rangeID); ok {
+		return "", false, nil
+	}
+	detached := path + orphanedSideloadedSuffix
+	if err := s.engine.Rename(path, detached); err != nil {
+		if oserror.IsNotExist(err) {
+			// The directory was removed in the meantime, e.g. by the removal
+			// of a replica of the range.
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return detached, true, nil
+}
+
+// parseSideloadedDirName returns the range ID of the sideloaded storage
+// directory with the given name, in either the current (see sideloadedPath)
+// or the deprecated (see deprecatedSideloadedPath) layout.
+func parseSideloadedDirName(name string) (roachpb.RangeID, bool) {
+	var rangeID roachpb.RangeID
+	var replicaID roachpb.ReplicaID
+	if n, err := fmt.Sscanf(name, "r%d", &rangeID); err == nil && n == 1 &&
+		name == fmt.Sprintf("r%d", rangeID) {
+		return rangeID, true
+	}
+	if n, err := fmt.Sscanf(name, "%d.%d", &rangeID, &replicaID); err == nil && n == 2 &&
+		name == fmt.Sprintf("%d.%d", rangeID, replicaID) {
+		return rangeID, true
+	}
+	return 0, false
+}
+
+// removeAllWithSize removes the given file or directory and returns the number
+// of bytes it held.
+func removeAllWithSize(fs fs.FS, path string) (int64, error) {
+	info, err := fs.Stat(path)
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	size := info.Size()
+	if info.IsDir() {
+		if size, err = dirSize(fs, path); err != nil {
+			return 0, err
+		}
+	}
+	if err := fs.RemoveAll(path); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// freeSpaceTarget returns the number of bytes the allocator aims to keep
+// available on a store with the given capacity.
+func freeSpaceTarget(capacity roachpb.StoreCapacity) int64 {
+	return int64(float64(capacity.Capacity) * (1 - allocator.MaxFractionUsedThreshold))
+}
+
+// auxiliaryDiskDegraded returns true if the store is below its free-space
+// target, but would not be if the given number of auxiliary bytes were
+// reclaimed.
+func auxiliaryDiskDegraded(capacity roachpb.StoreCapacity, auxBytes int64) bool {
+	if capacity.Capacity <= 0 || auxBytes <= 0 {
+		return false
+	}
+	target := freeSpaceTarget(capacity)
+	return capacity.Available < target && capacity.Available+auxBytes >= target
+}
+
+// dirSize returns the cumulative size of the files in the given directory and
+// its subdirectories, skipping the files in the provided exclusion list. A
+// directory that does not exist has size zero. Files that are removed while
+// the walk is in progress are ignored.
+func dirSize(fs fs.FS, dir string, exclude ...string) (int64, error) {
+	names, err := fs.List(dir)
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var total int64
+outer:
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		for _, e := range exclude {
+			if path == e {
+				continue outer
+			}
+		}
+		info, err := fs.Stat(path)
+		if err != nil {
+			if oserror.IsNotExist(err) {
+				continue
+			}
+			return 0, err
+		}
+		if !info.IsDir() {
+			total += info.Size()
+			continue
+		}
+		size, err := dirSize(fs, path, exclude...)
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	return total, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/errors/oserror"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuxiliaryDiskDegraded(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const capacity = 1000 // free-space target is 50 bytes
+	for _, tc := range []struct {
+		name      string
+		available int64
+		auxBytes  int64
+		expected  bool
+	}{
+		{name: "plenty of space", available: 500, auxBytes: 400, expected: false},
+		{name: "at target", available: 50, auxBytes: 10, expected: false},
+		{name: "aux pushes below target", available: 40, auxBytes: 10, expected: true},
+		{name: "aux pushes far below target", available: 0, auxBytes: 900, expected: true},
+		{name: "below target regardless of aux", available: 10, auxBytes: 20, expected: false},
+		{name: "no aux usage", available: 10, auxBytes: 0, expected: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := roachpb.StoreCapacity{Capacity: capacity, Available: tc.available}
+			require.Equal(t, tc.expected, auxiliaryDiskDegraded(c, tc.auxBytes))
+		})
+	}
+	// A store with unknown capacity is never degraded.
+	require.False(t, auxiliaryDiskDegraded(roachpb.StoreCapacity{}, 100))
+}
+
+func TestDirSize(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(ctx, t)
+	defer cleanup()
+	defer eng.Close()
+
+	dir := filepath.Join(eng.GetAuxiliaryDir(), "dirsize")
+	size, err := dirSize(eng, dir)
+	require.NoError(t, err)
+	require.Zero(t, size)
+
+	require.NoError(t, eng.MkdirAll(filepath.Join(dir, "a", "b")))
+	require.NoError(t, fs.WriteFile(eng, filepath.Join(dir, "1"), []byte("foo")))
+	require.NoError(t, fs.WriteFile(eng, filepath.Join(dir, "a", "2"), []byte("foobar")))
+	require.NoError(t, fs.WriteFile(eng, filepath.Join(dir, "a", "b", "3"), []byte("x")))
+
+	size, err = dirSize(eng, dir)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), size)
+
+	size, err = dirSize(eng, dir, filepath.Join(dir, "a", "2"))
+	require.NoError(t, err)
+	require.Equal(t, int64(4), size)
+}
+
+func TestParseSideloadedDirName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		name    string
+		rangeID roachpb.RangeID
+		ok      bool
+	}{
+		{name: filepath.Base(sideloadedPath("", 1828)), rangeID: 1828, ok: true},
+		{name: filepath.Base(deprecatedSideloadedPath("", 1828, 3)), rangeID: 1828, ok: true},
+		{name: "r0XXXX", ok: false},
+		{name: "r12x", ok: false},
+		{name: "12", ok: false},
+		{name: "foo", ok: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rangeID, ok := parseSideloadedDirName(tc.name)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.rangeID, rangeID)
+		})
+	}
+}
+
+// TestClearOrphanedSideloadedDirs checks that the sideloaded storage
+// directories of the ranges without a replica on the store are removed, and
+// only those.
+func TestClearOrphanedSideloadedDirs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	store, _ := createTestStore(ctx, t, testStoreOpts{createSystemRanges: false}, stopper)
+	eng := store.Engine()
+
+	// r1 has a replica on the store, r1000 doesn't, and the directory of r1001
+	// was left behind by an interrupted removal.
+	live := sideloadedPath(eng.GetAuxiliaryDir(), 1)
+	orphaned := sideloadedPath(eng.GetAuxiliaryDir(), 1000)
+	leftover := sideloadedPath(eng.GetAuxiliaryDir(), 1001) + orphanedSideloadedSuffix
+	for _, dir := range []string{live, orphaned, leftover} {
+		require.NoError(t, eng.MkdirAll(dir))
+		require.NoError(t, fs.WriteFile(eng, filepath.Join(dir, "orphaned.sst"), []byte("foo")))
+	}
+
+	reclaimed, err := store.clearOrphanedSideloadedDirs()
+	require.NoError(t, err)
+	require.Equal(t, int64(6), reclaimed)
+	_, err = eng.Stat(filepath.Join(live, "orphaned.sst"))
+	require.NoError(t, err)
+	for _, dir := range []string{orphaned, orphaned + orphanedSideloadedSuffix, leftover} {
+		_, err = eng.Stat(dir)
+		require.True(t, oserror.IsNotExist(err), "%s: %v", dir, err)
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/limit"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
 	"github.com/cockroachdb/errors"
 )
 
@@ -354,6 +356,12 @@ func (s *Store) maybeThrottleBatch(
 		waited, waitedEngine := after.Sub(before), after.Sub(beforeEngineDelay)
 		s.metrics.AddSSTableProposalTotalDelay.Inc(waited.Nanoseconds())
 		s.metrics.AddSSTableProposalEngineDelay.Inc(waitedEngine.Nanoseconds())
+		if sp := tracing.SpanFromContext(ctx); sp.RecordingType() != tracingpb.RecordingOff {
+			sp.RecordStructured(&roachpb.IngestWaitEvent{
+				WaitDuration:       waited,
+				EngineWaitDuration: waitedEngine,
+			})
+		}
 		if waited > time.Second {
 			log.Infof(ctx, "SST ingestion was delayed by %v (%v for storage engine back-pressure)",
 				waited, waitedEngine)
@@ -11,8 +11,13 @@
 package kvserver
 
 import (
+	"bufio"
 	"context"
+	"encoding/binary"
+	"hash/crc32"
 	"io"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
@@ -33,12 +38,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/redact"
 	"go.etcd.io/etcd/raft/v3/raftpb"
 	"go.opentelemetry.io/otel/attribute"
@@ -49,6 +56,14 @@ import (
 const (
 	// Messages that provide detail about why a snapshot was rejected.
 	storeDrainingMsg = "store is draining"
+	// snapshotReceiverOutOfDiskMsg is part of the error message returned when
+	// the disk of the recipient filled up while receiving the snapshot. The
+	// sender recognizes it to retry the snapshot later.
+	snapshotReceiverOutOfDiskMsg = "receiver out of disk"
+	// snapshotReceiverBusyMsg is part of the error message returned when the
+	// recipient has too many snapshots queued to accept another one. The
+	// sender recognizes it to retry the snapshot later.
+	snapshotReceiverBusyMsg = "receiver snapshot queue full"
 
 	// IntersectingSnapshotMsg is part of the error message returned from
 	// canAcceptSnapshotLocked and is exposed here so testing can rely on it.
@@ -65,6 +80,10 @@ const (
 	// DefaultSnapshotApplyLimit is the number of snapshots concurrently applied.
 	// See server.KVConfig for more info.
 	DefaultSnapshotApplyLimit = 1
+
+	// snapshotScratchProgressInterval is the number of bytes staged in the
+	// scratch of an incoming snapshot between progress events in its trace.
+	snapshotScratchProgressInterval = 64 << 20 // 64 MiB
 )
 
 // snapshotPrioritizationEnabled will allow the sender and receiver of snapshots
@@ -133,6 +152,10 @@ type snapshotStrategy interface {
 
 	// Close cleans up any resources associated with the snapshot strategy.
 	Close(context.Context)
+
+	// Abort is like Close, for a snapshot which could not be received because
+	// of the given error. The data received so far may be kept for inspection.
+	Abort(context.Context, error)
 }
 
 func assertStrategy(
@@ -153,11 +176,27 @@ type kvBatchSnapshotStrategy struct {
 	// The size of the batches of PUT operations to send to the receiver of the
 	// snapshot. Only used on the sender side.
 	batchSize int64
-	// Limiter for sending KV batches. Only used on the sender side.
-	limiter *rate.Limiter
+	// Limiter for sending KV batches, shared by the snapshots of the same
+	// priority sent by the node. Only used on the sender side.
+	limiter *quotapool.RateLimiter
+	// recipientLimiter, if set, limits the rate at which the KV batches are
+	// sent to the one advertised by the recipient. Only used on the sender
+	// side.
+	recipientLimiter *quotapool.RateLimiter
 	// Only used on the sender side.
 	newBatch func() storage.Batch
 
+	// checksum is the running CRC of the KV batches sent or received, verified
+	// by the recipient against the one computed by the sender.
+	checksum uint32
+	// dataChecksum is the checksum of the key-values sent, verified by the
+	// recipient against its replica once the snapshot is applied. Only used on
+	// the sender side.
+	dataChecksum replicaDataChecksum
+	// payloadCipher, if set, seals the KV batches sent or opens the KV batches
+	// received (see snapshotSenderEncryptPayload).
+	payloadCipher *snapshotPayloadCipher
+
 	// The approximate size of the SST chunk to buffer in memory on the receiver
 	// before flushing to disk. Only used on the receiver side.
 	sstChunkSize int64
@@ -168,17 +207,48 @@ type kvBatchSnapshotStrategy struct {
 
 // multiSSTWriter is a wrapper around an SSTWriter and SSTSnapshotStorageScratch
 // that handles chunking SSTs and persisting them to disk.
+//
+// The keys of each key span go to a separate SST, or to several SSTs covering
+// consecutive parts of the span if targetSSTSize is set. In the latter case,
+// the SSTs of the current key span are only finished once the span is done,
+// since its range keys are received after its point keys and are split among
+// its SSTs.
 type multiSSTWriter struct {
 	st       *cluster.Settings
 	scratch  *SSTSnapshotStorageScratch
-	currSST  storage.SSTWriter
 	keySpans []roachpb.Span
 	currSpan int
+	// The SSTs of the current key span, ordered by key. The last one is the
+	// one the point keys are written to.
+	currSSTs []spanSST
 	// The approximate size of the SST chunk to buffer in memory on the receiver
 	// before flushing to disk.
 	sstChunkSize int64
 	// The total size of SST data. Updated on SST finalization.
 	dataSize int64
+	// targetSSTSize, if positive, is the size of the data of an SST after
+	// which the following point keys of the key span go to a new SST.
+	targetSSTSize int64
+	// currKey is the last point key put, if targetSSTSize is set.
+	currKey roachpb.Key
+	// rangeKeysCleared is set once the range keys of the SSTs of the current
+	// key span have been cleared, which is done before the first range key of
+	// the span is put. The SSTs are no longer rolled over then.
+	rangeKeysCleared bool
+	// batchSyncs is set if the SSTs are synced together once all written,
+	// rather than one after the other as they are finished. The SSTs of a
+	// resumable scratch are not, so that they are recorded as complete as soon
+	// as they are finished.
+	batchSyncs bool
+}
+
+// spanSST is an SST covering a part of a key span.
+type spanSST struct {
+	w storage.SSTWriter
+	// start is the start of the part of the key span covered by the SST. The
+	// part ends at the start of the next SST of the span, or at the end of the
+	// span.
+	start roachpb.Key
 }
 
 func newMultiSSTWriter(
@@ -189,62 +259,114 @@ func newMultiSSTWriter(
 	sstChunkSize int64,
 ) (multiSSTWriter, error) {
 	msstw := multiSSTWriter{
-		st:           st,
-		scratch:      scratch,
-		keySpans:     keySpans,
-		sstChunkSize: sstChunkSize,
-	}
-	if err := msstw.initSST(ctx); err != nil {
+		st:            st,
+		scratch:       scratch,
+		keySpans:      keySpans,
+		sstChunkSize:  sstChunkSize,
+		targetSSTSize: snapshotSSTTargetSize.Get(&st.SV),
+		batchSyncs:    snapshotScratchBatchSyncs.Get(&st.SV) && !scratch.resumable,
+	}
+	if err := msstw.initSST(ctx, keySpans[0].Key); err != nil {
 		return msstw, err
 	}
 	return msstw, nil
 }
 
-func (msstw *multiSSTWriter) initSST(ctx context.Context) error {
+// initSST starts a new SST for the part of the current key span from the
+// given key on.
+func (msstw *multiSSTWriter) initSST(ctx context.Context, start roachpb.Key) error {
 	newSSTFile, err := msstw.scratch.NewFile(ctx, msstw.sstChunkSize)
 	if err != nil {
 		return errors.Wrap(err, "failed to create new sst file")
 	}
-	newSST := storage.MakeIngestionSSTWriter(ctx, msstw.st, newSSTFile)
-	msstw.currSST = newSST
-	if err := msstw.currSST.ClearRawRange(
-		msstw.keySpans[msstw.currSpan].Key, msstw.keySpans[msstw.currSpan].EndKey,
-		true /* pointKeys */, true, /* rangeKeys */
-	); err != nil {
-		msstw.currSST.Close()
-		return errors.Wrap(err, "failed to clear range on sst file writer")
+	if msstw.batchSyncs {
+		newSSTFile.SetBatchSync()
 	}
+	newSST := storage.MakeIngestionSSTWriterWithCompression(ctx, msstw.st, newSSTFile,
+		pebble.Compression(snapshotScratchCompression.Get(&msstw.st.SV)))
+	msstw.currSSTs = append(msstw.currSSTs, spanSST{w: newSST, start: start})
 	return nil
 }
 
-func (msstw *multiSSTWriter) finalizeSST(ctx context.Context) error {
-	err := msstw.currSST.Finish()
-	if err != nil {
-		return errors.Wrap(err, "failed to finish sst")
+// currSST returns the SST the point keys are written to.
+func (msstw *multiSSTWriter) currSST() *storage.SSTWriter {
+	return &msstw.currSSTs[len(msstw.currSSTs)-1].w
+}
+
+// sstEnd returns the end of the part of the current key span covered by its
+// i-th SST.
+func (msstw *multiSSTWriter) sstEnd(i int) roachpb.Key {
+	if i+1 < len(msstw.currSSTs) {
+		return msstw.currSSTs[i+1].start
 	}
-	msstw.dataSize += msstw.currSST.DataSize
-	msstw.currSpan++
-	msstw.currSST.Close()
+	return msstw.keySpans[msstw.currSpan].EndKey
+}
+
+// finalizeSSTs finishes the SSTs of the current key span. The existing data
+// of the span is cleared when they are ingested.
+func (msstw *multiSSTWriter) finalizeSSTs(ctx context.Context) error {
+	for i := range msstw.currSSTs {
+		sst := &msstw.currSSTs[i]
+		if err := sst.w.ClearRawRange(
+			sst.start, msstw.sstEnd(i), true /* pointKeys */, !msstw.rangeKeysCleared, /* rangeKeys */
+		); err != nil {
+			return errors.Wrap(err, "failed to clear range on sst file writer")
+		}
+		if err := sst.w.Finish(); err != nil {
+			return errors.Wrap(err, "failed to finish sst")
+		}
+		msstw.dataSize += sst.w.DataSize
+		sst.w.Close()
+	}
+	msstw.currSSTs = msstw.currSSTs[:0]
+	msstw.currKey = msstw.currKey[:0]
+	msstw.rangeKeysCleared = false
 	return nil
 }
 
-func (msstw *multiSSTWriter) Put(ctx context.Context, key storage.EngineKey, value []byte) error {
-	for msstw.keySpans[msstw.currSpan].EndKey.Compare(key.Key) <= 0 {
-		// Finish the current SST, write to the file, and move to the next key
+// nextSpan finishes the SSTs of the current key span and moves to the first
+// key span ending after the given key.
+func (msstw *multiSSTWriter) nextSpan(ctx context.Context, key roachpb.Key) error {
+	for msstw.keySpans[msstw.currSpan].EndKey.Compare(key) <= 0 {
+		// Finish the current SSTs, write to the files, and move to the next key
 		// range.
-		if err := msstw.finalizeSST(ctx); err != nil {
+		if err := msstw.finalizeSSTs(ctx); err != nil {
 			return err
 		}
-		if err := msstw.initSST(ctx); err != nil {
+		msstw.currSpan++
+		if err := msstw.initSST(ctx, msstw.keySpans[msstw.currSpan].Key); err != nil {
 			return err
 		}
 	}
+	return nil
+}
+
+// maybeRollSST starts a new SST from the given key if the current one reached
+// the target size. The versions of a key are never split across SSTs.
+func (msstw *multiSSTWriter) maybeRollSST(ctx context.Context, key roachpb.Key) error {
+	if msstw.targetSSTSize <= 0 || msstw.rangeKeysCleared ||
+		msstw.currSST().DataSize < msstw.targetSSTSize || key.Equal(msstw.currKey) {
+		return nil
+	}
+	return msstw.initSST(ctx, key)
+}
+
+func (msstw *multiSSTWriter) Put(ctx context.Context, key storage.EngineKey, value []byte) error {
+	if err := msstw.nextSpan(ctx, key.Key); err != nil {
+		return err
+	}
 	if msstw.keySpans[msstw.currSpan].Key.Compare(key.Key) > 0 {
 		return errors.AssertionFailedf("client error: expected %s to fall in one of %s", key.Key, msstw.keySpans)
 	}
-	if err := msstw.currSST.PutEngineKey(key, value); err != nil {
+	if err := msstw.maybeRollSST(ctx, key.Key); err != nil {
+		return err
+	}
+	if err := msstw.currSST().PutEngineKey(key, value); err != nil {
 		return errors.Wrap(err, "failed to put in sst")
 	}
+	if msstw.targetSSTSize > 0 {
+		msstw.currKey = append(msstw.currKey[:0], key.Key...)
+	}
 	return nil
 }
 
@@ -254,23 +376,43 @@ func (msstw *multiSSTWriter) PutRangeKey(
 	if start.Compare(end) >= 0 {
 		return errors.AssertionFailedf("start key %s must be before end key %s", end, start)
 	}
-	for msstw.keySpans[msstw.currSpan].EndKey.Compare(start) <= 0 {
-		// Finish the current SST, write to the file, and move to the next key
-		// range.
-		if err := msstw.finalizeSST(ctx); err != nil {
-			return err
-		}
-		if err := msstw.initSST(ctx); err != nil {
-			return err
-		}
+	if err := msstw.nextSpan(ctx, start); err != nil {
+		return err
 	}
 	if msstw.keySpans[msstw.currSpan].Key.Compare(start) > 0 ||
 		msstw.keySpans[msstw.currSpan].EndKey.Compare(end) < 0 {
 		return errors.AssertionFailedf("client error: expected %s to fall in one of %s",
 			roachpb.Span{Key: start, EndKey: end}, msstw.keySpans)
 	}
-	if err := msstw.currSST.PutEngineRangeKey(start, end, suffix, value); err != nil {
-		return errors.Wrap(err, "failed to put range key in sst")
+	if !msstw.rangeKeysCleared {
+		// The range keys of an SST must be added in order, so the clearing of
+		// the existing ones has to come first.
+		for i := range msstw.currSSTs {
+			sst := &msstw.currSSTs[i]
+			if err := sst.w.ClearRawRange(
+				sst.start, msstw.sstEnd(i), false /* pointKeys */, true, /* rangeKeys */
+			); err != nil {
+				return errors.Wrap(err, "failed to clear range keys on sst file writer")
+			}
+		}
+		msstw.rangeKeysCleared = true
+	}
+	// Split the range key among the SSTs it overlaps.
+	for i := range msstw.currSSTs {
+		sst := &msstw.currSSTs[i]
+		sstStart, sstEnd := sst.start, msstw.sstEnd(i)
+		if end.Compare(sstStart) <= 0 || start.Compare(sstEnd) >= 0 {
+			continue
+		}
+		if start.Compare(sstStart) > 0 {
+			sstStart = start
+		}
+		if end.Compare(sstEnd) < 0 {
+			sstEnd = end
+		}
+		if err := sst.w.PutEngineRangeKey(sstStart, sstEnd, suffix, value); err != nil {
+			return errors.Wrap(err, "failed to put range key in sst")
+		}
 	}
 	return nil
 }
@@ -278,22 +420,28 @@ func (msstw *multiSSTWriter) PutRangeKey(
 func (msstw *multiSSTWriter) Finish(ctx context.Context) (int64, error) {
 	if msstw.currSpan < len(msstw.keySpans) {
 		for {
-			if err := msstw.finalizeSST(ctx); err != nil {
+			if err := msstw.finalizeSSTs(ctx); err != nil {
 				return 0, err
 			}
+			msstw.currSpan++
 			if msstw.currSpan >= len(msstw.keySpans) {
 				break
 			}
-			if err := msstw.initSST(ctx); err != nil {
+			if err := msstw.initSST(ctx, msstw.keySpans[msstw.currSpan].Key); err != nil {
 				return 0, err
 			}
 		}
 	}
+	if err := msstw.scratch.SyncPending(ctx); err != nil {
+		return 0, errors.Wrap(err, "failed to sync sst files")
+	}
 	return msstw.dataSize, nil
 }
 
 func (msstw *multiSSTWriter) Close() {
-	msstw.currSST.Close()
+	for i := range msstw.currSSTs {
+		msstw.currSSTs[i].w.Close()
+	}
 }
 
 // snapshotTimingTag represents a lazy tracing span tag containing information
@@ -405,6 +553,9 @@ func (kvSS *kvBatchSnapshotStrategy) Receive(
 		return noSnap, err
 	}
 	defer msstw.Close()
+	if err := kvSS.scratch.RecordInfo(*header.State.Desc, len(keyRanges), header.RangeSize); err != nil {
+		return noSnap, errors.Wrap(err, "recording snapshot scratch info")
+	}
 
 	log.Event(ctx, "waiting for snapshot batches to begin")
 
@@ -420,8 +571,14 @@ func (kvSS *kvBatchSnapshotStrategy) Receive(
 			return noSnap, sendSnapshotError(stream, err)
 		}
 
+		if req.KVBatch != nil && kvSS.payloadCipher != nil {
+			if req.KVBatch, err = kvSS.payloadCipher.open(req.KVBatch); err != nil {
+				return noSnap, err
+			}
+		}
 		if req.KVBatch != nil {
 			recordBytesReceived(int64(len(req.KVBatch)))
+			kvSS.checksum = crc32.Update(kvSS.checksum, sstChunkCRCTable, req.KVBatch)
 			batchReader, err := storage.NewPebbleBatchReader(req.KVBatch)
 			if err != nil {
 				return noSnap, errors.Wrap(err, "failed to decode batch")
@@ -467,6 +624,14 @@ func (kvSS *kvBatchSnapshotStrategy) Receive(
 			timingTag.stop("sst")
 		}
 		if req.Final {
+			if kvSS.payloadCipher != nil {
+				if err := kvSS.payloadCipher.openFinal(req.EncryptedFinal); err != nil {
+					return noSnap, err
+				}
+			}
+			if err := kvSS.verifyChecksum(req.KVBatchesChecksum); err != nil {
+				return noSnap, err
+			}
 			// We finished receiving all batches and log entries. It's possible that
 			// we did not receive any key-value pairs for some of the key spans, but
 			// we must still construct SSTs with range deletion tombstones to remove
@@ -477,8 +642,12 @@ func (kvSS *kvBatchSnapshotStrategy) Receive(
 				return noSnap, errors.Wrapf(err, "finishing sst for raft snapshot")
 			}
 			msstw.Close()
+			if err := kvSS.scratch.RecordReceived(); err != nil {
+				return noSnap, errors.Wrap(err, "recording snapshot scratch info")
+			}
 			timingTag.stop("sst")
-			log.Eventf(ctx, "all data received from snapshot and all SSTs were finalized")
+			log.Eventf(ctx, "all data received from snapshot and all SSTs were finalized (%s)",
+				kvSS.scratch.Stats())
 
 			snapUUID, err := uuid.FromBytes(header.RaftMessageRequest.Message.Snapshot.Data)
 			if err != nil {
@@ -494,6 +663,7 @@ func (kvSS *kvBatchSnapshotStrategy) Receive(
 				DataSize:          dataSize,
 				snapType:          header.Type,
 				raftAppliedIndex:  header.State.RaftAppliedIndex,
+				dataChecksum:      req.KVDataChecksum,
 			}
 
 			timingTag.stop("totalTime")
@@ -504,6 +674,35 @@ func (kvSS *kvBatchSnapshotStrategy) Receive(
 	}
 }
 
+// batchesChecksum returns the checksum of the KV batches sent so far, as sent
+// to the recipient in the final request of the snapshot.
+func (kvSS *kvBatchSnapshotStrategy) batchesChecksum() []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], kvSS.checksum)
+	return b[:]
+}
+
+// verifyChecksum returns an error if the KV batches received do not match the
+// checksum computed by the sender. Senders which don't compute the checksum
+// send none, in which case the batches are not verified.
+//
+// The checksum covers the batches as streamed, rather than the SSTs written
+// from them, which the sender never sees.
+func (kvSS *kvBatchSnapshotStrategy) verifyChecksum(expected []byte) error {
+	if len(expected) == 0 {
+		return nil
+	}
+	if len(expected) != 4 {
+		return errors.Errorf("client error: invalid snapshot checksum of %d bytes", len(expected))
+	}
+	if e := binary.BigEndian.Uint32(expected); e != kvSS.checksum {
+		return errors.Mark(errors.Errorf(
+			"snapshot checksum mismatch: sender computed %08x, received batches have %08x",
+			e, kvSS.checksum), errMarkSnapshotChecksumMismatch)
+	}
+	return nil
+}
+
 // errMalformedSnapshot indicates that the snapshot in question is malformed,
 // for e.g. missing raft log entries.
 var errMalformedSnapshot = errors.New("malformed snapshot generated")
@@ -544,8 +743,54 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 	log.Event(ctx, "beginning to send batches of snapshot bytes")
 	timingTag.start("totalTime")
 
-	// Iterate over all keys (point keys and range keys) and stream out batches of
-	// key-values.
+	flush := func(repr []byte) error {
+		if err := kvSS.sendBatch(ctx, stream, repr, timingTag); err != nil {
+			return err
+		}
+		kvSS.checksum = crc32.Update(kvSS.checksum, sstChunkCRCTable, repr)
+		bytesSent += int64(len(repr))
+		recordBytesSent(int64(len(repr)))
+		return nil
+	}
+	var err error
+	if staged := snap.staged; staged != nil {
+		// The batches were staged in a local scratch: send them from there,
+		// rather than iterating over the engine snapshot at the pace of the
+		// network.
+		kvs, rangeKVs = staged.kvs, staged.rangeKVs
+		err = staged.readBatches(flush)
+		// Have the recipient verify the batches against the data read from the
+		// engine, so that a corruption of the staged batches is caught as well.
+		kvSS.checksum = staged.checksum
+		kvSS.dataChecksum = staged.dataChecksum
+	} else {
+		kvs, rangeKVs, err = batchSnapshotKVs(snap, kvSS.newBatch, kvSS.batchSize, timingTag,
+			&kvSS.dataChecksum, flush)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	timingTag.stop("totalTime")
+	log.Eventf(ctx, "finished sending snapshot batches, sent a total of %d bytes", bytesSent)
+
+	kvSS.status = redact.Sprintf("kvs=%d rangeKVs=%d", kvs, rangeKVs)
+	return bytesSent, nil
+}
+
+// batchSnapshotKVs iterates over all the replicated keys of the snapshot
+// (point keys and range keys), and passes them to flush as the representation
+// of batches of at least batchSize bytes, except for the last one. It returns
+// the number of point and range key-values. If dataChecksum is not nil, the
+// key-values are added to it.
+func batchSnapshotKVs(
+	snap *OutgoingSnapshot,
+	newBatch func() storage.Batch,
+	batchSize int64,
+	timingTag *snapshotTimingTag,
+	dataChecksum *replicaDataChecksum,
+	flush func(repr []byte) error,
+) (kvs, rangeKVs int, _ error) {
 	var b storage.Batch
 	defer func() {
 		if b != nil {
@@ -554,19 +799,16 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 	}()
 
 	flushBatch := func() error {
-		if err := kvSS.sendBatch(ctx, stream, b, timingTag); err != nil {
+		if err := flush(b.Repr()); err != nil {
 			return err
 		}
-		bLen := int64(b.Len())
-		bytesSent += bLen
-		recordBytesSent(bLen)
 		b.Close()
 		b = nil
 		return nil
 	}
 
 	maybeFlushBatch := func() error {
-		if int64(b.Len()) >= kvSS.batchSize {
+		if int64(b.Len()) >= batchSize {
 			return flushBatch()
 		}
 		return nil
@@ -583,7 +825,7 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 				for ok := true; ok && err == nil; ok, err = iter.NextEngineKey() {
 					kvs++
 					if b == nil {
-						b = kvSS.newBatch()
+						b = newBatch()
 					}
 					key, err := iter.UnsafeEngineKey()
 					if err != nil {
@@ -592,6 +834,9 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 					if err = b.PutEngineKey(key, iter.UnsafeValue()); err != nil {
 						return err
 					}
+					if dataChecksum != nil {
+						dataChecksum.addPointKey(key, iter.UnsafeValue())
+					}
 					if err = maybeFlushBatch(); err != nil {
 						return err
 					}
@@ -606,12 +851,15 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 					for _, rkv := range iter.EngineRangeKeys() {
 						rangeKVs++
 						if b == nil {
-							b = kvSS.newBatch()
+							b = newBatch()
 						}
 						err := b.PutEngineRangeKey(bounds.Key, bounds.EndKey, rkv.Version, rkv.Value)
 						if err != nil {
 							return err
 						}
+						if dataChecksum != nil {
+							dataChecksum.addRangeKey(bounds.Key, bounds.EndKey, rkv.Version, rkv.Value)
+						}
 						if err = maybeFlushBatch(); err != nil {
 							return err
 						}
@@ -624,35 +872,128 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 			return err
 		})
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	if b != nil {
 		if err = flushBatch(); err != nil {
-			return 0, err
+			return 0, 0, err
 		}
 	}
+	return kvs, rangeKVs, nil
+}
 
-	timingTag.stop("totalTime")
-	log.Eventf(ctx, "finished sending snapshot batches, sent a total of %d bytes", bytesSent)
+// stagedOutgoingSnapshot holds the KV batches of an outgoing snapshot, written
+// to a scratch of the snapshot storage of the sender before the snapshot is
+// sent (see stageOutgoingSnapshot). This decouples the pace at which the range
+// is read from the engine from the pace of the network, and lets the batches
+// be read again without iterating over the range again.
+type stagedOutgoingSnapshot struct {
+	scratch *SSTSnapshotStorageScratch
+	// path is the file holding the batches, each prefixed by its length as a
+	// uvarint. It is empty if the snapshot has no key.
+	path     string
+	kvs      int
+	rangeKVs int
+	// checksum is the CRC of the batches as read from the engine snapshot.
+	checksum uint32
+	// dataChecksum is the checksum of the key-values read from the engine
+	// snapshot.
+	dataChecksum replicaDataChecksum
+}
 
-	kvSS.status = redact.Sprintf("kvs=%d rangeKVs=%d", kvs, rangeKVs)
-	return bytesSent, nil
+// stageOutgoingSnapshot writes the KV batches of the snapshot to a new file of
+// the given scratch, which must stay open until the snapshot is sent.
+func stageOutgoingSnapshot(
+	ctx context.Context,
+	snap *OutgoingSnapshot,
+	scratch *SSTSnapshotStorageScratch,
+	newBatch func() storage.Batch,
+	batchSize int64,
+) (*stagedOutgoingSnapshot, error) {
+	f, err := scratch.NewFile(ctx, 0 /* bytesPerSync */)
+	if err != nil {
+		return nil, err
+	}
+	var buf []byte
+	var checksum uint32
+	var dataChecksum replicaDataChecksum
+	kvs, rangeKVs, err := batchSnapshotKVs(snap, newBatch, batchSize, newSnapshotTimingTag(),
+		&dataChecksum, func(repr []byte) error {
+			checksum = crc32.Update(checksum, sstChunkCRCTable, repr)
+			var lenBuf [binary.MaxVarintLen64]byte
+			n := binary.PutUvarint(lenBuf[:], uint64(len(repr)))
+			buf = append(append(buf[:0], lenBuf[:n]...), repr...)
+			_, err := f.Write(buf)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	staged := &stagedOutgoingSnapshot{
+		scratch: scratch, kvs: kvs, rangeKVs: rangeKVs, checksum: checksum,
+		dataChecksum: dataChecksum,
+	}
+	if f.written == 0 {
+		return staged, nil
+	}
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	staged.path = f.filename
+	log.Eventf(ctx, "staged snapshot batches in %s (%s)", f.filename, f.stats)
+	return staged, nil
+}
+
+// readBatches passes the staged batches to fn, in order. It can be called
+// several times.
+func (s *stagedOutgoingSnapshot) readBatches(fn func(repr []byte) error) error {
+	if s.path == "" {
+		return nil
+	}
+	f, err := s.scratch.OpenFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		n, err := binary.ReadUvarint(r)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "reading %s", s.path)
+		}
+		// The batch is not reused, since the stream may hold on to it.
+		repr := make([]byte, n)
+		if _, err := io.ReadFull(r, repr); err != nil {
+			return errors.Wrapf(err, "reading %s", s.path)
+		}
+		if err := fn(repr); err != nil {
+			return err
+		}
+	}
 }
 
 func (kvSS *kvBatchSnapshotStrategy) sendBatch(
-	ctx context.Context,
-	stream outgoingSnapshotStream,
-	batch storage.Batch,
-	timerTag *snapshotTimingTag,
+	ctx context.Context, stream outgoingSnapshotStream, repr []byte, timerTag *snapshotTimingTag,
 ) error {
 	timerTag.start("rateLimit")
-	err := kvSS.limiter.WaitN(ctx, 1)
+	err := kvSS.limiter.WaitN(ctx, int64(len(repr)))
+	if err == nil && kvSS.recipientLimiter != nil {
+		err = kvSS.recipientLimiter.WaitN(ctx, int64(len(repr)))
+	}
 	timerTag.stop("rateLimit")
 	if err != nil {
 		return err
 	}
+	if kvSS.payloadCipher != nil {
+		repr = kvSS.payloadCipher.seal(repr)
+	}
 	timerTag.start("send")
-	res := stream.Send(&kvserverpb.SnapshotRequest{KVBatch: batch.Repr()})
+	res := stream.Send(&kvserverpb.SnapshotRequest{KVBatch: repr})
 	timerTag.stop("send")
 	return res
 }
@@ -674,6 +1015,17 @@ func (kvSS *kvBatchSnapshotStrategy) Close(ctx context.Context) {
 	}
 }
 
+// Abort implements the snapshotStrategy interface.
+func (kvSS *kvBatchSnapshotStrategy) Abort(ctx context.Context, cause error) {
+	if kvSS.scratch != nil {
+		// Don't hold on to the data of a snapshot which filled up the disk.
+		keepFiles := !isSnapshotReceiverOutOfDiskError(cause)
+		if err := kvSS.scratch.Abort(keepFiles); err != nil {
+			log.Warningf(ctx, "error aborting kvBatchSnapshotStrategy: %v", err)
+		}
+	}
+}
+
 // reserveReceiveSnapshot throttles incoming snapshots.
 func (s *Store) reserveReceiveSnapshot(
 	ctx context.Context, header *kvserverpb.SnapshotRequest_Header,
@@ -681,8 +1033,17 @@ func (s *Store) reserveReceiveSnapshot(
 	ctx, sp := tracing.EnsureChildSpan(ctx, s.cfg.Tracer(), "reserveReceiveSnapshot")
 	defer sp.Finish()
 
+	class := snapshotRecvClass(header)
+	if header.RangeSize != 0 || s.cfg.TestingKnobs.ThrottleEmptySnapshots {
+		maxDepth := int(snapshotRecvQueueMaxDepth.Get(&s.ClusterSettings().SV))
+		if waiting := s.snapshotApplyQueue.Waiting(class); maxDepth > 0 && waiting >= maxDepth {
+			return nil, errors.Mark(errors.Errorf(
+				"%s: %d snapshots of equal or higher priority are waiting (limit %d), try again later",
+				snapshotReceiverBusyMsg, waiting, maxDepth), errMarkSnapshotReceiverBusy)
+		}
+	}
 	return s.throttleSnapshot(ctx, s.snapshotApplyQueue,
-		int(header.SenderQueueName), header.SenderQueuePriority,
+		int(header.SenderQueueName), class, header.SenderQueuePriority,
 		header.RangeSize,
 		header.RaftMessageRequest.RangeID, header.RaftMessageRequest.ToReplica.ReplicaID,
 		s.metrics.RangeSnapshotRecvQueueLength,
@@ -701,7 +1062,7 @@ func (s *Store) reserveSendSnapshot(
 	}
 
 	return s.throttleSnapshot(ctx, s.snapshotSendQueue,
-		int(req.SenderQueueName), req.SenderQueuePriority,
+		int(req.SenderQueueName), 0 /* requestClass */, req.SenderQueuePriority,
 		rangeSize,
 		req.RangeID, req.DelegatedSender.ReplicaID,
 		s.metrics.RangeSnapshotSendQueueLength,
@@ -709,6 +1070,26 @@ func (s *Store) reserveSendSnapshot(
 	)
 }
 
+// snapshotRecvClass returns the class of an incoming snapshot in the queue of
+// the recipient (see MultiQueue.AddWithClass): recovery snapshots, which
+// restore the replication factor of their range, come first, followed by the
+// rebalancing snapshots of voters, and then by the snapshots of non-voters.
+func snapshotRecvClass(header *kvserverpb.SnapshotRequest_Header) int {
+	const (
+		nonVoterClass = iota
+		rebalanceClass
+		recoveryClass
+	)
+	switch {
+	case header.Priority == kvserverpb.SnapshotRequest_RECOVERY:
+		return recoveryClass
+	case header.RaftMessageRequest.ToReplica.Type == roachpb.NON_VOTER:
+		return nonVoterClass
+	default:
+		return rebalanceClass
+	}
+}
+
 // throttleSnapshot is a helper function to throttle snapshot sending and
 // receiving. The returned closure is used to cleanup the reservation and
 // release its resources.
@@ -716,6 +1097,7 @@ func (s *Store) throttleSnapshot(
 	ctx context.Context,
 	snapshotQueue *multiqueue.MultiQueue,
 	requestSource int,
+	requestClass int,
 	requestPriority float64,
 	rangeSize int64,
 	rangeID roachpb.RangeID,
@@ -729,7 +1111,7 @@ func (s *Store) throttleSnapshot(
 	// RESTORE or manual SPLIT AT, since it prevents these empty snapshots from
 	// getting stuck behind large snapshots managed by the replicate queue.
 	if rangeSize != 0 || s.cfg.TestingKnobs.ThrottleEmptySnapshots {
-		task := snapshotQueue.Add(requestSource, requestPriority)
+		task := snapshotQueue.AddWithClass(requestSource, requestClass, requestPriority)
 		defer func() {
 			if err != nil {
 				snapshotQueue.Cancel(task)
@@ -764,7 +1146,8 @@ func (s *Store) throttleSnapshot(
 			}
 			return nil, errors.Wrapf(
 				queueCtx.Err(),
-				"giving up during snapshot reservation due to %q",
+				"giving up during snapshot reservation at queue position %d due to %q",
+				snapshotQueue.Position(task),
 				snapshotReservationQueueTimeoutFraction.Key(),
 			)
 		case <-s.stopper.ShouldQuiesce():
@@ -938,7 +1321,7 @@ func (s *Store) checkSnapshotOverlapLocked(
 // receiveSnapshot receives an incoming snapshot via a pre-opened GRPC stream.
 func (s *Store) receiveSnapshot(
 	ctx context.Context, header *kvserverpb.SnapshotRequest_Header, stream incomingSnapshotStream,
-) error {
+) (retErr error) {
 	sp := tracing.SpanFromContext(ctx)
 
 	// Draining nodes will generally not be rebalanced to (see the filtering that
@@ -976,8 +1359,25 @@ func (s *Store) receiveSnapshot(
 			header.Type, storeID, header.State.Desc.Replicas())
 	}
 
+	// Set up the decryption of an encrypted snapshot before reserving it, so
+	// that a snapshot which cannot be decrypted doesn't hold a reservation.
+	// encryptionKey is the public key of the recipient, sent to the sender.
+	var payloadCipher *snapshotPayloadCipher
+	var encryptionKey []byte
+	if len(header.EncryptionPublicKey) > 0 {
+		var err error
+		if payloadCipher, encryptionKey, err = newSnapshotRecipientCipher(header); err != nil {
+			return sendSnapshotError(stream, errors.Wrap(err, "client error: encrypted snapshot"))
+		}
+	}
+
 	cleanup, err := s.reserveReceiveSnapshot(ctx, header)
 	if err != nil {
+		if isSnapshotReceiverBusyError(err) {
+			// Let the sender know that it can retry the snapshot later, or send it
+			// to another store.
+			return sendSnapshotError(stream, err)
+		}
 		return err
 	}
 	defer cleanup()
@@ -1021,6 +1421,13 @@ func (s *Store) receiveSnapshot(
 	// snapshot. If we don't know how to handle the specified strategy, return
 	// an error.
 	var ss snapshotStrategy
+	var tracker *snapshotTracker
+	// writeRate is the rate at which the snapshot can be staged, advertised to
+	// the sender.
+	var writeRate int64
+	// finishScratch, if set, logs the completion or abortion of the staging of
+	// the snapshot in its scratch. It is unset once called.
+	var finishScratch func(err error)
 	switch header.Strategy {
 	case kvserverpb.SnapshotRequest_KV_BATCH:
 		snapUUID, err := uuid.FromBytes(header.RaftMessageRequest.Message.Snapshot.Data)
@@ -1028,11 +1435,67 @@ func (s *Store) receiveSnapshot(
 			err = errors.Wrap(err, "invalid snapshot")
 			return sendSnapshotError(stream, err)
 		}
+		tracker = s.snapshotHistory.start(snapUUID, header.State.Desc.RangeID, SnapshotReceived,
+			header.RaftMessageRequest.FromReplica.StoreID, header.Type, header.Priority, header.RangeSize)
+		defer func() { tracker.finish(retErr) }()
 
+		scratch, err := s.sstSnapshotStorage.NewScratchSpace(ctx, header.State.Desc.RangeID, snapUUID)
+		if err != nil {
+			return sendSnapshotError(stream, err)
+		}
+		scratchCreated := timeutil.Now()
+		scratchInfo := func() kvserverpb.SnapshotScratchInfo {
+			return kvserverpb.SnapshotScratchInfo{
+				SnapUUID:      snapUUID,
+				SenderStoreID: header.RaftMessageRequest.FromReplica.StoreID,
+				ExpectedBytes: header.RangeSize,
+				BytesWritten:  scratch.Stats().BytesWritten,
+				Duration:      timeutil.Since(scratchCreated),
+			}
+		}
+		s.logSnapshotScratchEvent(kvserverpb.RangeLogEventType_snapshot_scratch_created,
+			header.State.Desc.RangeID, scratchInfo(), "" /* details */)
+		finishScratch = func(err error) {
+			finishScratch = nil
+			eventType, details := kvserverpb.RangeLogEventType_snapshot_scratch_completed, ""
+			if err != nil {
+				eventType, details = kvserverpb.RangeLogEventType_snapshot_scratch_aborted, err.Error()
+			}
+			s.logSnapshotScratchEvent(eventType, header.State.Desc.RangeID, scratchInfo(), details)
+		}
+		defer func() {
+			if finishScratch != nil {
+				err := retErr
+				if err == nil {
+					err = errors.New("snapshot not received")
+				}
+				finishScratch(err)
+			}
+		}()
+		if _, tenantID, err := keys.DecodeTenantPrefix(header.State.Desc.StartKey.AsRawKey()); err == nil {
+			scratch.SetTenantID(tenantID)
+		}
+		if header.Priority == kvserverpb.SnapshotRequest_RECOVERY {
+			scratch.SetRecovery()
+		}
+		if limit := scratch.WriteRate(); !math.IsInf(limit, 1) {
+			writeRate = int64(limit)
+		}
+		scratch.SetExpectedBytes(header.RangeSize)
+		scratch.SetProgressFunc(snapshotScratchProgressInterval, func(stats WriteSSTStats) {
+			eta, _ := scratch.ETA(header.RangeSize - stats.BytesWritten)
+			log.VEventf(ctx, 2, "staged %s of %s, ETA %s",
+				humanizeutil.IBytes(stats.BytesWritten), humanizeutil.IBytes(header.RangeSize),
+				eta.Round(time.Second))
+		})
+		tracker.setETAFunc(func(info SnapshotInfo) (time.Duration, bool) {
+			return scratch.ETA(info.ExpectedBytes - info.Bytes)
+		})
 		ss = &kvBatchSnapshotStrategy{
-			scratch:      s.sstSnapshotStorage.NewScratchSpace(header.State.Desc.RangeID, snapUUID),
-			sstChunkSize: snapshotSSTWriteSyncRate.Get(&s.cfg.Settings.SV),
-			st:           s.ClusterSettings(),
+			scratch:       scratch,
+			sstChunkSize:  snapshotSSTWriteSyncRate.Get(&s.cfg.Settings.SV),
+			payloadCipher: payloadCipher,
+			st:            s.ClusterSettings(),
 		}
 		defer ss.Close(ctx)
 	default:
@@ -1042,7 +1505,11 @@ func (s *Store) receiveSnapshot(
 		)
 	}
 
-	if err := stream.Send(&kvserverpb.SnapshotResponse{Status: kvserverpb.SnapshotResponse_ACCEPTED}); err != nil {
+	if err := stream.Send(&kvserverpb.SnapshotResponse{
+		Status:              kvserverpb.SnapshotResponse_ACCEPTED,
+		WriteRate:           writeRate,
+		EncryptionPublicKey: encryptionKey,
+	}); err != nil {
 		return err
 	}
 	if log.V(2) {
@@ -1050,6 +1517,7 @@ func (s *Store) receiveSnapshot(
 	}
 
 	recordBytesReceived := func(inc int64) {
+		tracker.recordBytes(inc)
 		s.metrics.RangeSnapshotRcvdBytes.Inc(inc)
 
 		switch header.Priority {
@@ -1066,7 +1534,20 @@ func (s *Store) receiveSnapshot(
 	ctx, rSp := tracing.EnsureChildSpan(ctx, s.cfg.Tracer(), "receive snapshot data")
 	defer rSp.Finish() // Ensure that the tracing span is closed, even if ss.Receive errors
 	inSnap, err := ss.Receive(ctx, stream, *header, recordBytesReceived)
+	if finishScratch != nil {
+		finishScratch(err)
+	}
 	if err != nil {
+		ss.Abort(ctx, err)
+		if isSnapshotChecksumMismatchError(err) {
+			s.metrics.RangeSnapshotChecksumMismatches.Inc(1)
+		}
+		if isSnapshotReceiverOutOfDiskError(err) {
+			// Let the sender know that it can retry the snapshot later, or send it
+			// to another store.
+			tracker.finish(err)
+			return sendSnapshotError(stream, err)
+		}
 		return err
 	}
 	inSnap.placeholder = placeholder
@@ -1079,9 +1560,9 @@ func (s *Store) receiveSnapshot(
 	// abandoning application half-way through if the caller goes away.
 	applyCtx := s.AnnotateCtx(context.Background())
 	if err := s.processRaftSnapshotRequest(applyCtx, header, inSnap); err != nil {
-		return sendSnapshotErrorWithTrace(stream,
-			errors.Wrap(err.GoError(), "failed to apply snapshot"), rec,
-		)
+		applyErr := errors.Wrap(err.GoError(), "failed to apply snapshot")
+		tracker.finish(applyErr)
+		return sendSnapshotErrorWithTrace(stream, applyErr, rec)
 	}
 	return stream.Send(&kvserverpb.SnapshotResponse{
 		Status:         kvserverpb.SnapshotResponse_APPLIED,
@@ -1103,6 +1584,23 @@ func sendSnapshotErrorWithTrace(
 	})
 }
 
+// markSnapshotResponseError marks the error returned to the sender of a
+// snapshot which failed on the recipient with the given message. Failures
+// caused by the recipient running out of disk, or having too many snapshots
+// queued, are retryable: the snapshot can be sent again once space or the
+// queue has been freed up, or to another store.
+func markSnapshotResponseError(err error, msg string) error {
+	switch {
+	case strings.Contains(msg, snapshotReceiverOutOfDiskMsg):
+		err = errors.Mark(err, errMarkSnapshotReceiverOutOfDisk)
+	case strings.Contains(msg, snapshotReceiverBusyMsg):
+		err = errors.Mark(err, errMarkSnapshotReceiverBusy)
+	default:
+		return err
+	}
+	return errors.Mark(err, errMarkSnapshotError)
+}
+
 // SnapshotStorePool narrows StorePool to make sendSnapshot easier to test.
 type SnapshotStorePool interface {
 	Throttle(reason storepool.ThrottleReason, why string, toStoreID roachpb.StoreID)
@@ -1126,7 +1624,7 @@ const minSnapshotRate = 1 << 20 // 1mb/s
 var rebalanceSnapshotRate = settings.RegisterByteSizeSetting(
 	settings.SystemOnly,
 	"kv.snapshot_rebalance.max_rate",
-	"the rate limit (bytes/sec) to use for rebalance and upreplication snapshots",
+	"the rate limit (bytes/sec) to use for rebalance and upreplication snapshots, shared by all the stores of a node",
 	32<<20, // 32mb/s
 	func(v int64) error {
 		if v < minSnapshotRate {
@@ -1149,7 +1647,7 @@ var rebalanceSnapshotRate = settings.RegisterByteSizeSetting(
 var recoverySnapshotRate = settings.RegisterByteSizeSetting(
 	settings.SystemOnly,
 	"kv.snapshot_recovery.max_rate",
-	"the rate limit (bytes/sec) to use for recovery snapshots",
+	"the rate limit (bytes/sec) to use for recovery snapshots, shared by all the stores of a node",
 	32<<20, // 32mb/s
 	func(v int64) error {
 		if v < minSnapshotRate {
@@ -1172,6 +1670,16 @@ var snapshotSenderBatchSize = settings.RegisterByteSizeSetting(
 	settings.PositiveInt,
 )
 
+// snapshotSenderPaceToRecipient paces the snapshots sent to the rate at which
+// their recipient advertises it can stage them, on top of
+// kv.snapshot_rebalance.max_rate and kv.snapshot_recovery.max_rate.
+var snapshotSenderPaceToRecipient = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.snapshot_sender.pace_to_recipient.enabled",
+	"if enabled, snapshots are sent no faster than their recipient advertises it can write them",
+	true,
+)
+
 // snapshotReservationQueueTimeoutFraction is the maximum fraction of a Range
 // snapshot's total timeout that it is allowed to spend queued on the receiver
 // waiting for a reservation.
@@ -1287,6 +1795,271 @@ var snapshotSSTWriteSyncRate = settings.RegisterByteSizeSetting(
 	settings.PositiveInt,
 )
 
+// snapshotSSTTargetSize is the size of the data of the SSTs staged for
+// incoming snapshots after which the following keys go to a new SST, so that
+// large ranges are ingested as several moderately sized SSTs.
+var snapshotSSTTargetSize = settings.RegisterByteSizeSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.target_sst_size",
+	"size of the data of a staging file of incoming snapshots after which the "+
+		"following keys go to a new file; 0 disables the rolling over of the files",
+	128<<20, // 128 MiB
+	settings.NonNegativeInt,
+)
+
+// snapshotRecoveryWriteWeight and snapshotRebalanceWriteWeight weigh the share
+// of the store's bulk IO write budget assured to the staging of incoming
+// recovery and rebalancing snapshots respectively, relative to the writes of
+// the SSTs of AddSSTable commands (see addSSTableWriteWeight). Recovery
+// snapshots restore the replication factor of ranges which lost a replica, so
+// they are favored by default.
+var snapshotRecoveryWriteWeight = settings.RegisterFloatSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.recovery_write_weight",
+	"weight of the share of the bulk IO write budget of a store assured to the staging "+
+		"of incoming recovery snapshots, relative to the writes of the SSTs of AddSSTable commands",
+	4,
+	settings.PositiveFloat,
+)
+
+var snapshotRebalanceWriteWeight = settings.RegisterFloatSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.rebalance_write_weight",
+	"weight of the share of the bulk IO write budget of a store assured to the staging "+
+		"of incoming rebalancing snapshots, relative to the writes of the SSTs of AddSSTable commands",
+	1,
+	settings.PositiveFloat,
+)
+
+// snapshotScratchWritesPaused pauses the writes of the staging files of
+// incoming snapshots on all the stores, e.g. while the disks are nearly full.
+// The writes of a single store can be paused with the
+// SetSnapshotScratchWritesPaused RPC of the PerStore service.
+var snapshotScratchWritesPaused = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_writes.paused",
+	"if enabled, the writes of the staging files of incoming snapshots block until "+
+		"the setting is disabled",
+	false,
+)
+
+// snapshotScratchSpaceMaxBytes limits the disk space used to stage the SSTs
+// of the snapshots received by a store. Without a limit, a burst of concurrent
+// snapshots can fill up the disk. Snapshots whose SSTs would go over the limit
+// fail.
+var snapshotScratchSpaceMaxBytes = settings.RegisterByteSizeSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_space.max_bytes",
+	"maximum disk space used per store to stage the SSTs of incoming snapshots; "+
+		"0 means no limit",
+	0,
+	settings.NonNegativeInt,
+)
+
+// snapshotNodeScratchSpaceMaxBytes limits the disk space used to stage the SSTs
+// of the snapshots received by all the stores of a node (see
+// SnapshotScratchBudget), for nodes whose stores share a physical disk.
+var snapshotNodeScratchSpaceMaxBytes = settings.RegisterByteSizeSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.node_scratch_space.max_bytes",
+	"maximum disk space used by all the stores of a node to stage the SSTs of incoming "+
+		"snapshots; 0 means no limit",
+	0,
+	settings.NonNegativeInt,
+)
+
+// snapshotPrewarmMaxBytes is the number of bytes of the data of a freshly
+// applied snapshot which are read in the background to load the blocks of its
+// SSTs in the block cache (see Replica.prewarmSnapshot).
+var snapshotPrewarmMaxBytes = settings.RegisterByteSizeSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.block_cache_prewarm.max_bytes",
+	"the maximum number of bytes of an applied snapshot which are read in the background "+
+		"to load them in the block cache; 0 disables the prewarming",
+	0,
+	settings.NonNegativeInt,
+)
+
+// snapshotScratchMaxFiles limits the number of files of a snapshot scratch.
+// Snapshots have a handful of SSTs per key span, so that a sender streaming an
+// unbounded number of tiny SSTs, which would exhaust the inodes or the file
+// descriptors of the receiver, fails instead.
+var snapshotScratchMaxFiles = settings.RegisterIntSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_max_files",
+	"maximum number of files staged for an incoming snapshot; 0 means no limit",
+	1000,
+	settings.NonNegativeInt,
+)
+
+// snapshotRecvQueueMaxDepth limits the number of incoming snapshots waiting
+// for a reservation. Snapshots are rejected once as many snapshots of the same
+// or a higher class (see snapshotRecvClass) are waiting, so that the lower
+// priority ones are turned away first, and their senders can try again later
+// or send them elsewhere rather than time out in the queue.
+var snapshotRecvQueueMaxDepth = settings.RegisterIntSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.queue_max_depth",
+	"maximum number of incoming snapshots of equal or higher priority waiting for a "+
+		"reservation before a snapshot is rejected; 0 means no limit",
+	0,
+	settings.NonNegativeInt,
+)
+
+// snapshotScratchSizeOverrunFactor limits the size of a snapshot scratch to a
+// multiple of the size of the snapshot declared by its sender, so that a
+// sender streaming much more data than announced fails instead of filling up
+// the disk of the receiver.
+var snapshotScratchSizeOverrunFactor = settings.RegisterFloatSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_size_overrun_factor",
+	"factor by which the data staged for an incoming snapshot can exceed the size "+
+		"declared by its sender, on top of a fixed 32 MiB allowance; 0 means no limit",
+	4,
+	settings.NonNegativeFloat,
+)
+
+// snapshotApplyAsBatchMaxBytes is the size of the SSTs of an incoming
+// snapshot up to which they are applied through a regular write batch rather
+// than ingested. Ingesting a handful of tiny SSTs costs more than writing
+// their keys, since it can force a memtable flush and adds files to L0.
+var snapshotApplyAsBatchMaxBytes = settings.RegisterByteSizeSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.apply_as_batch.max_bytes",
+	"size of the data of an incoming snapshot up to which it is applied as a "+
+		"regular write rather than ingested; 0 disables applying snapshots as writes",
+	64<<10, // 64 KiB
+	settings.NonNegativeInt,
+)
+
+// snapshotScratchBytesPerSync is the interval at which the dirty data of the
+// files of the snapshot scratches is synced, to smooth out the writes.
+var snapshotScratchBytesPerSync = settings.RegisterByteSizeSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_bytes_per_sync",
+	"amount of data written to a snapshot staging file after which its dirty data is "+
+		"synced to disk, to smooth out the writes; 0 disables the periodic syncing",
+	defaultScratchBytesPerSync,
+	settings.NonNegativeInt,
+)
+
+// scratchSyncPolicy is the policy of syncing of the files of the snapshot
+// scratches.
+type scratchSyncPolicy int64
+
+const (
+	// scratchSyncPeriodic syncs the dirty data of the files periodically as it
+	// is written, to smooth out the writes, on top of the final sync.
+	scratchSyncPeriodic scratchSyncPolicy = iota
+	// scratchSyncFinal only syncs the files once they are fully written. The
+	// intermediate syncs can slow down the writes on disks with a
+	// battery-backed write cache, for which they bring no smoothing.
+	scratchSyncFinal
+)
+
+// snapshotScratchSyncPolicy is the policy of syncing of the files of the
+// snapshot scratches.
+var snapshotScratchSyncPolicy = settings.RegisterEnumSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_sync_policy",
+	"policy of syncing of the staging files of incoming snapshots; periodic syncs their "+
+		"dirty data as it is written to smooth out the writes, and final only syncs them "+
+		"once fully written, which suits disks with a battery-backed write cache",
+	"periodic",
+	map[int64]string{
+		int64(scratchSyncPeriodic): "periodic",
+		int64(scratchSyncFinal):    "final",
+	},
+)
+
+// snapshotScratchDropPageCache drops the pages of the files of the snapshot
+// scratches from the OS page cache once they are synced, so that staging large
+// snapshots does not evict the pages cached for the rest of the store. The SSTs
+// are read back by the ingestion, but only once, and mostly their index and
+// the boundaries of their blocks.
+var snapshotScratchDropPageCache = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_drop_page_cache.enabled",
+	"if enabled, the files staged for incoming snapshots are dropped from the OS page "+
+		"cache once synced to disk, to avoid evicting the pages cached for the rest of the store",
+	false,
+)
+
+// snapshotScratchWriteBufferSize is the size of the buffer in which the writes
+// to a staging file of incoming snapshots are coalesced. The stream of a
+// snapshot is decoded into many small writes, which are costly one by one on
+// disks with a high latency, such as network attached ones.
+var snapshotScratchWriteBufferSize = settings.RegisterByteSizeSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_write_buffer_size",
+	"size of the buffer in which the writes to a staging file of incoming snapshots are "+
+		"coalesced into large sequential writes; 0 disables the buffering",
+	256<<10, // 256 KiB
+	settings.NonNegativeInt,
+)
+
+// snapshotScratchBatchSyncs batches the final syncs of the staging files of an
+// incoming snapshot, which are synced together once the snapshot is fully
+// received rather than one after the other as they are finished. A snapshot
+// spanning many files otherwise causes a storm of syncs on slow disks.
+var snapshotScratchBatchSyncs = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_batch_syncs.enabled",
+	"if enabled, the staging files of an incoming snapshot are synced together once "+
+		"the snapshot is fully received, rather than each once written",
+	true,
+)
+
+// snapshotScratchCompression is the compression algorithm of the SSTs staged
+// for incoming snapshots. Since the SSTs are ingested as is, it is also the
+// compression of the data of the snapshots until it is compacted.
+var snapshotScratchCompression = settings.RegisterEnumSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_compression",
+	"compression algorithm of the staging files of incoming snapshots; zstd uses less "+
+		"disk space than snappy at the expense of CPU, and none more disk space for less CPU",
+	"snappy",
+	map[int64]string{
+		int64(pebble.SnappyCompression): "snappy",
+		int64(pebble.ZstdCompression):   "zstd",
+		int64(pebble.NoCompression):     "none",
+	},
+)
+
+// snapshotScratchTTL is the time after which the scratch directories of the
+// snapshots which are no longer in flight are removed, if they were not
+// removed when their scratch was closed.
+var snapshotScratchTTL = settings.RegisterDurationSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_ttl",
+	"time after which the staging files of snapshots that are no longer in flight, "+
+		"and were not removed, are removed; 0 disables the removal",
+	24*time.Hour,
+	settings.NonNegativeDuration,
+)
+
+// snapshotQuarantineMaxScratches is the number of failed snapshots whose
+// partially received SSTs are kept for inspection by a store.
+var snapshotQuarantineMaxScratches = settings.RegisterIntSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.quarantine.max_scratches",
+	"number of snapshots which could not be received whose partially written SSTs are "+
+		"kept per store for inspection, in the sstsnapshot-quarantine directory; "+
+		"0 means that they are removed",
+	0,
+	settings.NonNegativeInt,
+)
+
+// snapshotScratchLogEvents enables the logging of the lifecycle of the
+// scratches of incoming snapshots in the range log.
+var snapshotScratchLogEvents = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.snapshot_receiver.scratch_events.enabled",
+	"if enabled, the creation, completion and abortion of the staging of incoming "+
+		"snapshots are logged into system.rangelog",
+	false,
+)
+
 func snapshotRateLimit(
 	st *cluster.Settings, priority kvserverpb.SnapshotRequest_Priority,
 ) (rate.Limit, error) {
@@ -1300,6 +2073,72 @@ func snapshotRateLimit(
 	}
 }
 
+// snapshotSendRateBurst is the burst of the limiters of snapshotSendLimiter.
+// Batches larger than the burst are let through once the burst is available,
+// putting the limiter into debt.
+const snapshotSendRateBurst = 1 << 20 // 1 MiB
+
+// snapshotSendLimiter limits the rate at which the snapshots of each priority
+// are sent by a node, to kv.snapshot_recovery.max_rate and
+// kv.snapshot_rebalance.max_rate. It is shared by all the stores of the node,
+// so that they don't each get the whole budget, and follows the changes of the
+// settings, including for the snapshots being sent.
+type snapshotSendLimiter struct {
+	recovery  *quotapool.RateLimiter
+	rebalance *quotapool.RateLimiter
+}
+
+func newSnapshotSendLimiter(st *cluster.Settings) *snapshotSendLimiter {
+	l := newFixedSnapshotSendLimiter(st)
+	recoverySnapshotRate.SetOnChange(&st.SV, func(ctx context.Context) {
+		l.recovery.UpdateLimit(quotapool.Limit(recoverySnapshotRate.Get(&st.SV)), snapshotSendRateBurst)
+	})
+	rebalanceSnapshotRate.SetOnChange(&st.SV, func(ctx context.Context) {
+		l.rebalance.UpdateLimit(quotapool.Limit(rebalanceSnapshotRate.Get(&st.SV)), snapshotSendRateBurst)
+	})
+	return l
+}
+
+// newFixedSnapshotSendLimiter returns a limiter at the current rates of the
+// settings, which doesn't follow their changes. It is used to send one-off
+// snapshots, since the hooks registered on the settings are never removed.
+func newFixedSnapshotSendLimiter(st *cluster.Settings) *snapshotSendLimiter {
+	return &snapshotSendLimiter{
+		recovery: quotapool.NewRateLimiter("snapshot-send-recovery",
+			quotapool.Limit(recoverySnapshotRate.Get(&st.SV)), snapshotSendRateBurst),
+		rebalance: quotapool.NewRateLimiter("snapshot-send-rebalance",
+			quotapool.Limit(rebalanceSnapshotRate.Get(&st.SV)), snapshotSendRateBurst),
+	}
+}
+
+// forPriority returns the limiter of the snapshots of the given priority.
+func (l *snapshotSendLimiter) forPriority(
+	priority kvserverpb.SnapshotRequest_Priority,
+) (*quotapool.RateLimiter, error) {
+	switch priority {
+	case kvserverpb.SnapshotRequest_RECOVERY:
+		return l.recovery, nil
+	case kvserverpb.SnapshotRequest_REBALANCE:
+		return l.rebalance, nil
+	default:
+		return nil, errors.Errorf("unknown snapshot priority: %s", priority)
+	}
+}
+
+// recipientSendRate returns the rate (bytes/sec) at which a snapshot is sent
+// given the rate at which its recipient advertised it can stage it, or 0 if
+// the snapshot is not paced to the recipient. The rate is not lowered below
+// the minimum snapshot rate, under which large snapshots would time out.
+func recipientSendRate(st *cluster.Settings, writeRate int64) int64 {
+	if writeRate <= 0 || !snapshotSenderPaceToRecipient.Get(&st.SV) {
+		return 0
+	}
+	if writeRate < minSnapshotRate {
+		return minSnapshotRate
+	}
+	return writeRate
+}
+
 // SendEmptySnapshot creates an OutgoingSnapshot for the input range
 // descriptor and seeds it with an empty range. Then, it sends this
 // snapshot to the replica specified in the input.
@@ -1374,7 +2213,7 @@ func SendEmptySnapshot(
 		return err
 	}
 
-	snapUUID, err := uuid.NewV4()
+	snapUUID, err := uuid.NewV7()
 	if err != nil {
 		return err
 	}
@@ -1452,6 +2291,7 @@ func SendEmptySnapshot(
 		st,
 		tracer,
 		stream,
+		newFixedSnapshotSendLimiter(st),
 		noopStorePool{},
 		header,
 		&outgoingSnap,
@@ -1466,12 +2306,14 @@ type noopStorePool struct{}
 
 func (n noopStorePool) Throttle(storepool.ThrottleReason, string, roachpb.StoreID) {}
 
-// sendSnapshot sends an outgoing snapshot via a pre-opened GRPC stream.
+// sendSnapshot sends an outgoing snapshot via a pre-opened GRPC stream, at the
+// pace allowed by the given limiter.
 func sendSnapshot(
 	ctx context.Context,
 	st *cluster.Settings,
 	tracer *tracing.Tracer,
 	stream outgoingSnapshotStream,
+	limiters *snapshotSendLimiter,
 	storePool SnapshotStorePool,
 	header kvserverpb.SnapshotRequest_Header,
 	snap *OutgoingSnapshot,
@@ -1490,6 +2332,15 @@ func sendSnapshot(
 
 	start := timeutil.Now()
 	to := header.RaftMessageRequest.ToReplica
+	var kx *snapshotKeyExchange
+	if header.Strategy == kvserverpb.SnapshotRequest_KV_BATCH && snapshotSenderEncryptPayload.Get(&st.SV) &&
+		st.Version.IsActive(ctx, clusterversion.EncryptedSnapshots) {
+		var err error
+		if kx, err = newSnapshotKeyExchange(); err != nil {
+			return err
+		}
+		header.EncryptionPublicKey = kx.publicKey()
+	}
 	if err := stream.Send(&kvserverpb.SnapshotRequest{Header: &header}); err != nil {
 		return err
 	}
@@ -1507,8 +2358,8 @@ func sendSnapshot(
 	case kvserverpb.SnapshotResponse_ERROR:
 		sp.ImportRemoteRecording(resp.CollectedSpans)
 		storePool.Throttle(storepool.ThrottleFailed, resp.Message, to.StoreID)
-		return errors.Errorf("%s: remote couldn't accept %s with error: %s",
-			to, snap, resp.Message)
+		return markSnapshotResponseError(errors.Errorf("%s: remote couldn't accept %s with error: %s",
+			to, snap, resp.Message), resp.Message)
 	case kvserverpb.SnapshotResponse_ACCEPTED:
 		// This is the response we're expecting. Continue with snapshot sending.
 		log.Event(ctx, "received SnapshotResponse_ACCEPTED message from server")
@@ -1527,26 +2378,47 @@ func sendSnapshot(
 	if err != nil {
 		return errors.Wrapf(err, "%s", to)
 	}
+	limiter, err := limiters.forPriority(header.Priority)
+	if err != nil {
+		return errors.Wrapf(err, "%s", to)
+	}
+	// Don't send the snapshot faster than the recipient can stage it, so that
+	// the batches don't pile up in the buffers of the stream.
+	var recipientLimiter *quotapool.RateLimiter
+	if recipientRate := recipientSendRate(st, resp.WriteRate); recipientRate > 0 {
+		recipientLimiter = quotapool.NewRateLimiter("snapshot-send-recipient",
+			quotapool.Limit(recipientRate), snapshotSendRateBurst)
+		if rate.Limit(recipientRate) < targetRate {
+			targetRate = rate.Limit(recipientRate)
+			log.Eventf(ctx, "pacing the snapshot to the rate of the recipient: %s/s",
+				humanizeutil.IBytes(recipientRate))
+		}
+	}
+	var payloadCipher *snapshotPayloadCipher
+	if kx != nil {
+		// Don't send the snapshot in the clear to a recipient which cannot
+		// decrypt it. This is not expected once the cluster version gating the
+		// encryption is active.
+		if len(resp.EncryptionPublicKey) == 0 {
+			return errors.Errorf("%s: remote does not support encrypted snapshots", to)
+		}
+		if payloadCipher, err = kx.senderCipher(snap.SnapUUID, resp.EncryptionPublicKey); err != nil {
+			return errors.Wrapf(err, "%s", to)
+		}
+	}
 	batchSize := snapshotSenderBatchSize.Get(&st.SV)
 
-	// Convert the bytes/sec rate limit to batches/sec.
-	//
-	// TODO(peter): Using bytes/sec for rate limiting seems more natural but has
-	// practical difficulties. We either need to use a very large burst size
-	// which seems to disable the rate limiting, or call WaitN in smaller than
-	// burst size chunks which caused excessive slowness in testing. Would be
-	// nice to figure this out, but the batches/sec rate limit works for now.
-	limiter := rate.NewLimiter(targetRate/rate.Limit(batchSize), 1 /* burst size */)
-
 	// Create a snapshotStrategy based on the desired snapshot strategy.
 	var ss snapshotStrategy
 	switch header.Strategy {
 	case kvserverpb.SnapshotRequest_KV_BATCH:
 		ss = &kvBatchSnapshotStrategy{
-			batchSize: batchSize,
-			limiter:   limiter,
-			newBatch:  newBatch,
-			st:        st,
+			batchSize:        batchSize,
+			limiter:          limiter,
+			recipientLimiter: recipientLimiter,
+			newBatch:         newBatch,
+			payloadCipher:    payloadCipher,
+			st:               st,
 		}
 	default:
 		log.Fatalf(ctx, "unknown snapshot strategy: %s", header.Strategy)
@@ -1555,6 +2427,16 @@ func sendSnapshot(
 	// Record timings for snapshot send if kv.trace.snapshot.enable_threshold is enabled
 	numBytesSent, err := ss.Send(ctx, stream, header, snap, recordBytesSent)
 	if err != nil {
+		// If the recipient rejected the snapshot while receiving it, the stream
+		// is closed and its response tells why.
+		if errors.Is(err, io.EOF) {
+			if resp, recvErr := stream.Recv(); recvErr == nil && resp.Status == kvserverpb.SnapshotResponse_ERROR {
+				sp.ImportRemoteRecording(resp.CollectedSpans)
+				storePool.Throttle(storepool.ThrottleFailed, resp.Message, to.StoreID)
+				return markSnapshotResponseError(errors.Errorf("%s: remote failed to receive %s with error: %s",
+					to, snap, resp.Message), resp.Message)
+			}
+		}
 		return err
 	}
 	durSent := timeutil.Since(start)
@@ -1563,7 +2445,15 @@ func sendSnapshot(
 	// the snapshots generated metric gets incremented before the snapshot is
 	// applied.
 	sent()
-	if err := stream.Send(&kvserverpb.SnapshotRequest{Final: true}); err != nil {
+	final := &kvserverpb.SnapshotRequest{Final: true}
+	if kvSS, ok := ss.(*kvBatchSnapshotStrategy); ok {
+		final.KVBatchesChecksum = kvSS.batchesChecksum()
+		final.KVDataChecksum = kvSS.dataChecksum.bytes()
+		if kvSS.payloadCipher != nil {
+			final.EncryptedFinal = kvSS.payloadCipher.sealFinal()
+		}
+	}
+	if err := stream.Send(final); err != nil {
 		return err
 	}
 	log.KvDistribution.Infof(
@@ -1595,7 +2485,8 @@ func sendSnapshot(
 	}
 	switch resp.Status {
 	case kvserverpb.SnapshotResponse_ERROR:
-		return errors.Errorf("%s: remote failed to apply snapshot for reason %s", to, resp.Message)
+		return markSnapshotResponseError(
+			errors.Errorf("%s: remote failed to apply snapshot for reason %s", to, resp.Message), resp.Message)
 	case kvserverpb.SnapshotResponse_APPLIED:
 		return nil
 	default:
@@ -0,0 +1,194 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// snapshotHistorySize is the number of completed snapshots retained, per
+// store, by the snapshot history.
+const snapshotHistorySize = 100
+
+// SnapshotDirection indicates whether a snapshot was sent or received by a
+// store.
+type SnapshotDirection string
+
+const (
+	// SnapshotSent indicates a snapshot sent by the store.
+	SnapshotSent SnapshotDirection = "send"
+	// SnapshotReceived indicates a snapshot received by the store.
+	SnapshotReceived SnapshotDirection = "receive"
+)
+
+// SnapshotInfo describes a snapshot sent or received by a store, either in
+// flight or recently completed.
+type SnapshotInfo struct {
+	SnapshotID  uuid.UUID
+	RangeID     roachpb.RangeID
+	Direction   SnapshotDirection
+	PeerStoreID roachpb.StoreID
+	Type        kvserverpb.SnapshotRequest_Type
+	Priority    kvserverpb.SnapshotRequest_Priority
+	// Bytes is the number of bytes sent or received so far.
+	Bytes int64
+	// ExpectedBytes is the approximate size of the range at the time the
+	// snapshot was started, as an estimate of the total number of bytes to
+	// send or receive.
+	ExpectedBytes int64
+	StartedAt     time.Time
+	// Duration is the time the snapshot took, or has taken so far if it is in
+	// flight.
+	Duration time.Duration
+	InFlight bool
+	// Error is the error the snapshot failed with, if any.
+	Error string
+	// ETA is the estimated time until a snapshot in flight completes, or zero
+	// if it can't be estimated.
+	ETA time.Duration
+}
+
+// snapshotHistory tracks the snapshots in flight on a store, and retains the
+// last snapshotHistorySize completed ones. The zero value is ready to use.
+type snapshotHistory struct {
+	mu struct {
+		syncutil.Mutex
+		inFlight map[*snapshotTracker]struct{}
+		// completed is a ring buffer of the completed snapshots, next being the
+		// position of the next insertion.
+		completed []SnapshotInfo
+		next      int
+	}
+}
+
+// snapshotTracker tracks a snapshot in flight. It is registered in the
+// snapshot history until finish is called.
+type snapshotTracker struct {
+	h     *snapshotHistory
+	info  SnapshotInfo
+	bytes int64 // accessed atomically
+	done  int32 // accessed atomically
+	// eta, if set, estimates the time until the snapshot completes (see
+	// setETAFunc). It is protected by the mutex of the history.
+	eta func(SnapshotInfo) (time.Duration, bool)
+}
+
+// start registers a new snapshot in flight.
+func (h *snapshotHistory) start(
+	snapID uuid.UUID,
+	rangeID roachpb.RangeID,
+	dir SnapshotDirection,
+	peer roachpb.StoreID,
+	typ kvserverpb.SnapshotRequest_Type,
+	prio kvserverpb.SnapshotRequest_Priority,
+	expectedBytes int64,
+) *snapshotTracker {
+	t := &snapshotTracker{
+		h: h,
+		info: SnapshotInfo{
+			SnapshotID:    snapID,
+			RangeID:       rangeID,
+			Direction:     dir,
+			PeerStoreID:   peer,
+			Type:          typ,
+			Priority:      prio,
+			ExpectedBytes: expectedBytes,
+			StartedAt:     timeutil.Now(),
+			InFlight:      true,
+		},
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.mu.inFlight == nil {
+		h.mu.inFlight = make(map[*snapshotTracker]struct{})
+	}
+	h.mu.inFlight[t] = struct{}{}
+	return t
+}
+
+// recordBytes accounts for bytes sent or received for the snapshot.
+func (t *snapshotTracker) recordBytes(inc int64) {
+	atomic.AddInt64(&t.bytes, inc)
+}
+
+// snapshot returns the current state of the tracked snapshot.
+func (t *snapshotTracker) snapshot(now time.Time) SnapshotInfo {
+	info := t.info
+	info.Bytes = atomic.LoadInt64(&t.bytes)
+	info.Duration = now.Sub(info.StartedAt)
+	return info
+}
+
+// setETAFunc registers the function estimating the time until the snapshot
+// completes, given its current state, which is reported while the snapshot is
+// in flight.
+func (t *snapshotTracker) setETAFunc(eta func(SnapshotInfo) (time.Duration, bool)) {
+	t.h.mu.Lock()
+	defer t.h.mu.Unlock()
+	t.eta = eta
+}
+
+// finish moves the snapshot to the completed snapshots, recording the error it
+// failed with, if any. Only the first call has an effect.
+func (t *snapshotTracker) finish(err error) {
+	if !atomic.CompareAndSwapInt32(&t.done, 0, 1) {
+		return
+	}
+	info := t.snapshot(timeutil.Now())
+	info.InFlight = false
+	if err != nil {
+		info.Error = err.Error()
+	}
+
+	h := t.h
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.mu.inFlight, t)
+	if len(h.mu.completed) < snapshotHistorySize {
+		h.mu.completed = append(h.mu.completed, info)
+		return
+	}
+	h.mu.completed[h.mu.next] = info
+	h.mu.next = (h.mu.next + 1) % snapshotHistorySize
+}
+
+// list returns the snapshots in flight and the completed ones, ordered by
+// start time.
+func (h *snapshotHistory) list() []SnapshotInfo {
+	now := timeutil.Now()
+	h.mu.Lock()
+	res := make([]SnapshotInfo, 0, len(h.mu.inFlight)+len(h.mu.completed))
+	res = append(res, h.mu.completed...)
+	for t := range h.mu.inFlight {
+		info := t.snapshot(now)
+		if t.eta != nil {
+			info.ETA, _ = t.eta(info)
+		}
+		res = append(res, info)
+	}
+	h.mu.Unlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].StartedAt.Before(res[j].StartedAt) })
+	return res
+}
+
+// SnapshotHistory returns the snapshots in flight on the store, along with
+// the most recently completed ones, ordered by start time.
+func (s *Store) SnapshotHistory() []SnapshotInfo {
+	return s.snapshotHistory.list()
+}
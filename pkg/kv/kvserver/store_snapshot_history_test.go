@@ -0,0 +1,84 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotHistory(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	var h snapshotHistory
+	require.Empty(t, h.list())
+
+	start := func(rangeID roachpb.RangeID, dir SnapshotDirection) *snapshotTracker {
+		return h.start(uuid.MakeV4(), rangeID, dir, 2, /* peer */
+			kvserverpb.SnapshotRequest_INITIAL, kvserverpb.SnapshotRequest_REBALANCE, 100 /* expectedBytes */)
+	}
+
+	sent := start(1, SnapshotSent)
+	rcvd := start(2, SnapshotReceived)
+	sent.recordBytes(10)
+	sent.recordBytes(5)
+	rcvd.recordBytes(40)
+	rcvd.setETAFunc(func(info SnapshotInfo) (time.Duration, bool) {
+		return time.Duration(info.ExpectedBytes-info.Bytes) * time.Second, true
+	})
+
+	snaps := h.list()
+	require.Len(t, snaps, 2)
+	require.Equal(t, roachpb.RangeID(1), snaps[0].RangeID)
+	require.Equal(t, SnapshotSent, snaps[0].Direction)
+	require.Equal(t, int64(15), snaps[0].Bytes)
+	require.Equal(t, int64(100), snaps[0].ExpectedBytes)
+	require.True(t, snaps[0].InFlight)
+	require.Equal(t, roachpb.RangeID(2), snaps[1].RangeID)
+	require.Equal(t, SnapshotReceived, snaps[1].Direction)
+	require.Equal(t, roachpb.StoreID(2), snaps[1].PeerStoreID)
+	require.Equal(t, kvserverpb.SnapshotRequest_INITIAL, snaps[1].Type)
+	require.Zero(t, snaps[0].ETA)
+	require.Equal(t, 60*time.Second, snaps[1].ETA)
+
+	// Only the first call to finish is recorded.
+	rcvd.finish(errors.New("boom"))
+	rcvd.finish(nil)
+	sent.finish(nil)
+	snaps = h.list()
+	require.Len(t, snaps, 2)
+	require.False(t, snaps[0].InFlight)
+	require.Empty(t, snaps[0].Error)
+	require.Equal(t, int64(15), snaps[0].Bytes)
+	require.False(t, snaps[1].InFlight)
+	require.Equal(t, "boom", snaps[1].Error)
+	require.Zero(t, snaps[1].ETA)
+
+	// The oldest completed snapshots are evicted.
+	for i := 0; i < snapshotHistorySize; i++ {
+		start(roachpb.RangeID(100+i), SnapshotSent).finish(nil)
+	}
+	inFlight := start(1000, SnapshotReceived)
+	snaps = h.list()
+	require.Len(t, snaps, snapshotHistorySize+1)
+	require.Equal(t, roachpb.RangeID(100), snaps[0].RangeID)
+	require.Equal(t, inFlight.info.SnapshotID, snaps[len(snaps)-1].SnapshotID)
+	require.True(t, snaps[len(snaps)-1].InFlight)
+}
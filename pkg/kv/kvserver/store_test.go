@@ -2897,7 +2897,8 @@ func TestSendSnapshotThrottling(t *testing.T) {
 		expectedErr := errors.New("")
 		c := fakeSnapshotStream{nil, expectedErr}
 		err := sendSnapshot(
-			ctx, st, tr, c, sp, header, nil /* snap */, newBatch, nil /* sent */, nil, /* recordBytesSent */
+			ctx, st, tr, c, newSnapshotSendLimiter(st), sp, header, nil /* snap */, newBatch, nil, /* sent */
+			nil, /* recordBytesSent */
 		)
 		if sp.failedThrottles != 1 {
 			t.Fatalf("expected 1 failed throttle, but found %d", sp.failedThrottles)
@@ -2915,7 +2916,8 @@ func TestSendSnapshotThrottling(t *testing.T) {
 		}
 		c := fakeSnapshotStream{resp, nil}
 		err := sendSnapshot(
-			ctx, st, tr, c, sp, header, nil /* snap */, newBatch, nil /* sent */, nil, /* recordBytesSent */
+			ctx, st, tr, c, newSnapshotSendLimiter(st), sp, header, nil /* snap */, newBatch, nil, /* sent */
+			nil, /* recordBytesSent */
 		)
 		if sp.failedThrottles != 1 {
 			t.Fatalf("expected 1 failed throttle, but found %d", sp.failedThrottles)
@@ -3233,6 +3235,71 @@ func TestReserveSnapshotQueueTimeoutAvoidsStarvation(t *testing.T) {
 	require.GreaterOrEqual(t, int(atomic.LoadInt64(&successes)), assertSuccesses)
 }
 
+// TestReserveSnapshotQueueMaxDepth checks that incoming snapshots are rejected
+// with a retryable error once too many snapshots of their class or a higher
+// one are waiting, and that the snapshots of higher classes go first.
+func TestReserveSnapshotQueueMaxDepth(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	tsc := TestStoreConfig(nil)
+	tsc.SnapshotApplyLimit = 1
+	tc := testContext{}
+	tc.StartWithStoreConfig(ctx, t, stopper, tsc)
+	s := tc.store
+	snapshotRecvQueueMaxDepth.Override(ctx, &s.ClusterSettings().SV, 1)
+
+	rebalance := &kvserverpb.SnapshotRequest_Header{
+		RangeSize: 1,
+		Priority:  kvserverpb.SnapshotRequest_REBALANCE,
+	}
+	recovery := &kvserverpb.SnapshotRequest_Header{
+		RangeSize: 1,
+		Priority:  kvserverpb.SnapshotRequest_RECOVERY,
+	}
+	cleanup, err := s.reserveReceiveSnapshot(ctx, rebalance)
+	require.NoError(t, err)
+
+	reserved := make(chan string, 2)
+	reserve := func(name string, header *kvserverpb.SnapshotRequest_Header) {
+		go func() {
+			cleanup, err := s.reserveReceiveSnapshot(ctx, header)
+			if err != nil {
+				reserved <- err.Error()
+				return
+			}
+			reserved <- name
+			cleanup()
+		}()
+	}
+	waitQueued := func(n int) {
+		testutils.SucceedsSoon(t, func() error {
+			if l := s.Metrics().RangeSnapshotRecvQueueLength.Value(); l != int64(n) {
+				return errors.Errorf("%d snapshots queued, expected %d", l, n)
+			}
+			return nil
+		})
+	}
+	reserve("rebalance", rebalance)
+	waitQueued(1)
+
+	// The queue is full for rebalancing snapshots, but not for recovery ones.
+	_, err = s.reserveReceiveSnapshot(ctx, rebalance)
+	require.True(t, isSnapshotReceiverBusyError(err), "%v", err)
+	msg := err.Error()
+	sendErr := markSnapshotResponseError(errors.Newf("remote failed: %s", msg), msg)
+	require.True(t, IsRetriableReplicationChangeError(sendErr))
+	reserve("recovery", recovery)
+	waitQueued(2)
+
+	cleanup()
+	require.Equal(t, "recovery", <-reserved)
+	require.Equal(t, "rebalance", <-reserved)
+}
+
 func TestSnapshotRateLimit(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -3259,6 +3326,56 @@ func TestSnapshotRateLimit(t *testing.T) {
 	}
 }
 
+// TestSnapshotSendLimiter checks that the limiters of the snapshots sent by a
+// node follow the changes of the rate settings.
+func TestSnapshotSendLimiter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	rebalanceSnapshotRate.Override(ctx, &st.SV, minSnapshotRate)
+	l := newSnapshotSendLimiter(st)
+	_, err := l.forPriority(kvserverpb.SnapshotRequest_UNKNOWN)
+	require.True(t, testutils.IsError(err, "unknown snapshot priority"), "%v", err)
+	rebalance, err := l.forPriority(kvserverpb.SnapshotRequest_REBALANCE)
+	require.NoError(t, err)
+	recovery, err := l.forPriority(kvserverpb.SnapshotRequest_RECOVERY)
+	require.NoError(t, err)
+
+	// Once the burst is used, the rebalancing snapshots wait, unlike the
+	// recovery ones which have their own budget.
+	require.True(t, rebalance.AdmitN(snapshotSendRateBurst))
+	require.False(t, rebalance.AdmitN(256<<10))
+	require.True(t, recovery.AdmitN(256<<10))
+
+	// Raising the rate takes effect right away.
+	rebalanceSnapshotRate.Override(ctx, &st.SV, 1<<40)
+	testutils.SucceedsSoon(t, func() error {
+		if !rebalance.AdmitN(256 << 10) {
+			return errors.New("rebalancing snapshot still throttled")
+		}
+		return nil
+	})
+}
+
+// TestRecipientSendRate checks that the snapshots are paced to the rate
+// advertised by their recipient, if any, but no slower than the minimum
+// snapshot rate.
+func TestRecipientSendRate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	require.Zero(t, recipientSendRate(st, 0))
+	require.Equal(t, int64(64<<20), recipientSendRate(st, 64<<20))
+	require.Equal(t, int64(minSnapshotRate), recipientSendRate(st, 1))
+
+	snapshotSenderPaceToRecipient.Override(ctx, &st.SV, false)
+	require.Zero(t, recipientSendRate(st, 64<<20))
+}
+
 // TestManuallyEnqueueUninitializedReplica makes sure that uninitialized
 // replicas cannot be enqueued.
 func TestManuallyEnqueueUninitializedReplica(t *testing.T) {
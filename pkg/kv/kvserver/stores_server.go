@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/redact"
 )
 
@@ -187,3 +188,45 @@ func (is Server) SetCompactionConcurrency(
 		})
 	return resp, err
 }
+
+// CleanupScratch implements PerStoreServer. It removes the orphaned files of
+// the auxiliary directories of all the stores of the node.
+func (is Server) CleanupScratch(
+	ctx context.Context, req *CleanupScratchRequest,
+) (*CleanupScratchResponse, error) {
+	resp := &CleanupScratchResponse{}
+	err := is.stores.VisitStores(func(s *Store) error {
+		return s.stopper.RunTaskWithErr(ctx, "store command", func(ctx context.Context) error {
+			reclaimed, err := s.CleanupScratch(ctx)
+			if err != nil {
+				return errors.Wrapf(err, "store %d", s.StoreID())
+			}
+			resp.Stores = append(resp.Stores, CleanupScratchResponse_Store{
+				StoreID:        s.StoreID(),
+				BytesReclaimed: reclaimed,
+			})
+			return nil
+		})
+	})
+	return resp, err
+}
+
+// SetSnapshotScratchWritesPaused implements PerStoreServer. It pauses or
+// resumes the writes of the staging files of the snapshots received by a
+// store.
+func (is Server) SetSnapshotScratchWritesPaused(
+	ctx context.Context, req *SetSnapshotScratchWritesPausedRequest,
+) (*SetSnapshotScratchWritesPausedResponse, error) {
+	resp := &SetSnapshotScratchWritesPausedResponse{}
+	err := is.execStoreCommand(ctx, req.StoreRequestHeader,
+		func(ctx context.Context, s *Store) error {
+			s.sstSnapshotStorage.SetPaused(req.Paused)
+			if req.Paused {
+				log.Infof(ctx, "pausing the writes of incoming snapshots")
+			} else {
+				log.Infof(ctx, "resuming the writes of incoming snapshots")
+			}
+			return nil
+		})
+	return resp, err
+}
@@ -12,6 +12,7 @@ package kvserver
 
 import (
 	"context"
+	"math"
 	"os"
 	"runtime/debug"
 	"strings"
@@ -21,9 +22,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
-	"golang.org/x/time/rate"
 )
 
 // bulkIOWriteBurst is the burst for the BulkIOWriteLimiter. It is also used as
@@ -34,31 +35,32 @@ const bulkIOWriteBurst = 512 << 10 // 512 KB
 const bulkIOWriteLimiterLongWait = 500 * time.Millisecond
 
 // limitBulkIOWrite blocks until the provided limiter permits the specified cost
-// to happen. It returns an error if the Context is canceled or the expected
-// wait time exceeds the Context's Deadline.
-func limitBulkIOWrite(ctx context.Context, limiter *rate.Limiter, cost int) error {
-	// The limiter disallows anything greater than its burst (set to
-	// BulkIOWriteLimiterBurst), so cap the batch size if it would overflow.
-	//
-	// TODO(dan): This obviously means the limiter is no longer accounting for the
-	// full cost. I've tried calling WaitN in a loop to fully cover the cost, but
-	// that didn't seem to be as smooth in practice (NB [dt]: that was when this
-	// limit was done before writing the whole file, rather than on individual
-	// chunks).
-	if cost > bulkIOWriteBurst {
-		cost = bulkIOWriteBurst
-	}
-
+// to happen. It returns an error if the Context is canceled. A cost greater
+// than the limiter's burst is let through once the burst is available, putting
+// the limiter into debt, so that the full cost is accounted for. The time spent
+// waiting is returned, so that callers can attribute it.
+func limitBulkIOWrite(
+	ctx context.Context, limiter *quotapool.ChildRateLimiter, cost int,
+) (time.Duration, error) {
 	begin := timeutil.Now()
-	if err := limiter.WaitN(ctx, cost); err != nil {
-		return errors.Wrapf(err, "error rate limiting bulk io write")
+	err := limiter.WaitN(ctx, int64(cost))
+	d := timeutil.Since(begin)
+	if err != nil {
+		return d, errors.Wrapf(err, "error rate limiting bulk io write")
 	}
 
-	if d := timeutil.Since(begin); d > bulkIOWriteLimiterLongWait {
+	if d > bulkIOWriteLimiterLongWait {
 		log.Warningf(ctx, "bulk io write limiter took %s (>%s):\n%s",
 			d, bulkIOWriteLimiterLongWait, debug.Stack())
 	}
-	return nil
+	return d, nil
+}
+
+// unlimitedBulkIOWriteLimiter returns a limiter for bulk IO writes which are
+// not subject to the store's budget.
+func unlimitedBulkIOWriteLimiter() *quotapool.ChildRateLimiter {
+	return quotapool.NewHierarchicalRateLimiter(
+		"unlimited-bulk-io-write", quotapool.Limit(math.Inf(1)), 0).NewChild("unlimited", 1)
 }
 
 // sstWriteSyncRate wraps "kv.bulk_sst.sync_size". 0 disables syncing.
@@ -83,7 +85,7 @@ func writeFileSyncing(
 	eng storage.Engine,
 	perm os.FileMode,
 	settings *cluster.Settings,
-	limiter *rate.Limiter,
+	limiter *quotapool.ChildRateLimiter,
 ) error {
 	chunkSize := sstWriteSyncRate.Get(&settings.SV)
 	sync := true
@@ -107,7 +109,7 @@ func writeFileSyncing(
 		}
 		chunk := data[i:end]
 
-		if err = limitBulkIOWrite(ctx, limiter, len(chunk)); err != nil {
+		if _, err = limitBulkIOWrite(ctx, limiter, len(chunk)); err != nil {
 			break
 		}
 		if _, err = f.Write(chunk); err != nil {
@@ -315,6 +315,12 @@ type StoreTestingKnobs struct {
 	// BeforeSnapshotSSTIngestion is run just before the SSTs are ingested when
 	// applying a snapshot.
 	BeforeSnapshotSSTIngestion func(IncomingSnapshot, kvserverpb.SnapshotRequest_Type, []string) error
+	// BeforeSnapshotScratchFileOp is run before each creation of, write to and
+	// sync of an SST file of a snapshot scratch, and before the directory of a
+	// scratch, or of its range, is removed, with the path of the file or
+	// directory. If an error is returned, the operation fails with it. The
+	// hook can also block, to delay the operation.
+	BeforeSnapshotScratchFileOp func(op ScratchFileOp, path string) error
 	// OnRelocatedOne intercepts the return values of s.relocateOne after they
 	// have successfully been put into effect.
 	OnRelocatedOne func(_ []roachpb.ReplicationChange, leaseTarget *roachpb.ReplicationTarget)
@@ -122,6 +122,20 @@ type adminServer struct {
 // usage growth in the log.
 var noteworthyAdminMemoryUsageBytes = envutil.EnvOrDefaultInt64("COCKROACH_NOTEWORTHY_ADMIN_MEMORY_USAGE", 100*1024)
 
+// readinessCheckAuxiliaryDiskEnabled controls whether the readiness check
+// reports the node as unready when the auxiliary files of one of its stores
+// (e.g. the staging files of in-flight snapshots) push that store below its
+// free-space target.
+var readinessCheckAuxiliaryDiskEnabled = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"server.health_check.auxiliary_disk_usage.enabled",
+	"if enabled, a node reports itself as not ready when the auxiliary files "+
+		"(snapshot scratch space, sideloaded SSTs) of one of its stores push "+
+		"that store below its free-space target, or when the snapshot scratch "+
+		"directory of one of its stores fails its periodic probe",
+	false,
+)
+
 // newAdminServer allocates and returns a new REST server for
 // administrative APIs.
 func newAdminServer(
@@ -2016,6 +2030,14 @@ func (s *adminServer) checkReadinessForHealthCheck(ctx context.Context) error {
 		return status.Errorf(codes.Unavailable, "node is not accepting SQL clients")
 	}
 
+	if readinessCheckAuxiliaryDiskEnabled.Get(&s.server.st.SV) {
+		if err := s.server.node.stores.VisitStores(func(store *kvserver.Store) error {
+			return store.CheckAuxiliaryDiskHealth(ctx)
+		}); err != nil {
+			return status.Errorf(codes.Unavailable, "node is degraded: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -3645,6 +3667,38 @@ func (c *adminPrivilegeChecker) requireViewDebugPermission(ctx context.Context)
 	return nil
 }
 
+// requireViewLogsPermission requires the user have admin or either of the
+// VIEWLOGS or VIEWLOGSREDACTED system privileges. It reports whether the
+// user may see the unredacted log entries, which is the case for admin
+// users and users with the VIEWLOGS privilege. Users that have
+// VIEWLOGSREDACTED must be served redacted entries regardless of what the
+// client requested. Note that users can have both VIEWLOGSREDACTED and
+// VIEWLOGS, with the former taking precedence.
+// This function's error return is a gRPC error.
+func (c *adminPrivilegeChecker) requireViewLogsPermission(
+	ctx context.Context,
+) (canViewUnredacted bool, err error) {
+	userName, isAdmin, err := c.getUserAndRole(ctx)
+	if err != nil {
+		return false, serverError(ctx, err)
+	}
+	if isAdmin {
+		return true, nil
+	}
+	if !c.st.Version.IsActive(ctx, clusterversion.SystemPrivilegesTable) {
+		return false, status.Error(codes.PermissionDenied, "this operation requires admin privilege")
+	}
+	if c.checkHasGlobalPrivilege(ctx, userName, privilege.VIEWLOGSREDACTED) {
+		return false, nil
+	}
+	if c.checkHasGlobalPrivilege(ctx, userName, privilege.VIEWLOGS) {
+		return true, nil
+	}
+	return false, status.Errorf(
+		codes.PermissionDenied, "this operation requires the %s or %s system privileges",
+		privilege.VIEWLOGS, privilege.VIEWLOGSREDACTED)
+}
+
 // Note that the function returns plain errors, and it is the caller's
 // responsibility to convert them to serverErrors.
 func (c *adminPrivilegeChecker) getUserAndRole(
@@ -2898,6 +2898,45 @@ func TestAdminPrivilegeChecker(t *testing.T) {
 			})
 		}
 	}
+
+	// requireViewLogsPermission also reports whether unredacted log
+	// entries can be served to the user.
+	type viewLogsResult struct {
+		wantErr           bool
+		canViewUnredacted bool
+	}
+	viewLogsTests := map[username.SQLUsername]viewLogsResult{
+		withAdmin:    {canViewUnredacted: true},
+		withVa:       {wantErr: true},
+		withoutPrivs: {wantErr: true},
+	}
+	if s.ClusterSettings().Version.IsActive(ctx, clusterversion.SystemPrivilegesTable) {
+		sqlDB.Exec(t, "CREATE USER withviewlogs")
+		sqlDB.Exec(t, "GRANT SYSTEM VIEWLOGS TO withviewlogs")
+		sqlDB.Exec(t, "CREATE USER withviewlogsredacted")
+		sqlDB.Exec(t, "GRANT SYSTEM VIEWLOGSREDACTED TO withviewlogsredacted")
+		sqlDB.Exec(t, "CREATE USER withviewlogsandredacted")
+		sqlDB.Exec(t, "GRANT SYSTEM VIEWLOGS, VIEWLOGSREDACTED TO withviewlogsandredacted")
+
+		viewLogsTests[username.MakeSQLUsernameFromPreNormalizedString("withviewlogs")] =
+			viewLogsResult{canViewUnredacted: true}
+		viewLogsTests[username.MakeSQLUsernameFromPreNormalizedString("withviewlogsredacted")] =
+			viewLogsResult{canViewUnredacted: false}
+		viewLogsTests[username.MakeSQLUsernameFromPreNormalizedString("withviewlogsandredacted")] =
+			viewLogsResult{canViewUnredacted: false}
+	}
+	for userName, want := range viewLogsTests {
+		t.Run(fmt.Sprintf("requireViewLogsPermission-%s", userName), func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(ctx, metadata.New(map[string]string{"websessionuser": userName.SQLIdentifier()}))
+			canViewUnredacted, err := underTest.requireViewLogsPermission(ctx)
+			if want.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, want.canViewUnredacted, canViewUnredacted)
+		})
+	}
 }
 
 func TestDatabaseAndTableIndexRecommendations(t *testing.T) {
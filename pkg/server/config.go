@@ -591,6 +591,18 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 		tableCache = pebble.NewTableCache(pebbleCache, runtime.GOMAXPROCS(0), totalFileLimit)
 	}
 
+	scratchDirs := make(map[string]int)
+	for i, spec := range cfg.Stores.Specs {
+		if spec.SnapshotScratchDir == "" {
+			continue
+		}
+		if j, ok := scratchDirs[spec.SnapshotScratchDir]; ok {
+			return Engines{}, errors.Errorf("stores %d and %d use the same snapshot scratch directory %s",
+				j, i, spec.SnapshotScratchDir)
+		}
+		scratchDirs[spec.SnapshotScratchDir] = i
+	}
+
 	skipSizeCheck := cfg.TestingKnobs.Store != nil &&
 		cfg.TestingKnobs.Store.(*kvserver.StoreTestingKnobs).SkipMinSizeCheck
 	for i, spec := range cfg.Stores.Specs {
@@ -661,13 +673,14 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 				i, humanizeutil.IBytes(sizeInBytes), openFileLimitPerStore))
 
 			storageConfig := base.StorageConfig{
-				Attrs:             spec.Attributes,
-				Dir:               spec.Path,
-				MaxSize:           sizeInBytes,
-				BallastSize:       storage.BallastSizeBytes(spec, du),
-				Settings:          cfg.Settings,
-				UseFileRegistry:   spec.UseFileRegistry,
-				EncryptionOptions: spec.EncryptionOptions,
+				Attrs:              spec.Attributes,
+				Dir:                spec.Path,
+				MaxSize:            sizeInBytes,
+				BallastSize:        storage.BallastSizeBytes(spec, du),
+				SnapshotScratchDir: spec.SnapshotScratchDir,
+				Settings:           cfg.Settings,
+				UseFileRegistry:    spec.UseFileRegistry,
+				EncryptionOptions:  spec.EncryptionOptions,
 			}
 			pebbleConfig := storage.PebbleConfig{
 				StorageConfig: storageConfig,
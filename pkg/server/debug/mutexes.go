@@ -0,0 +1,74 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// mutexInstrumentationEnabled controls whether the instrumented mutexes
+// (syncutil.InstrumentedMutex and syncutil.InstrumentedRWMutex) record their
+// wait and hold durations.
+var mutexInstrumentationEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"server.debug.mutex_instrumentation.enabled",
+	"if set, instrumented mutexes record wait and hold durations, reported at /debug/mutexes",
+	false,
+)
+
+// defaultTopMutexes is the number of mutexes reported by /debug/mutexes when
+// the request does not specify one.
+const defaultTopMutexes = 20
+
+// setupMutexInstrumentation applies the current value of the mutex
+// instrumentation setting and keeps it up to date.
+func setupMutexInstrumentation(st *cluster.Settings) {
+	apply := func(context.Context) {
+		syncutil.EnableMutexInstrumentation(mutexInstrumentationEnabled.Get(&st.SV))
+	}
+	mutexInstrumentationEnabled.SetOnChange(&st.SV, apply)
+	apply(context.Background())
+}
+
+// handleMutexes lists the most contended instrumented mutexes, ordered by
+// total wait time. The number of mutexes listed can be set with the "top"
+// query parameter; a non-positive value lists all of them.
+func handleMutexes(w http.ResponseWriter, r *http.Request) {
+	n := defaultTopMutexes
+	if v := r.URL.Query().Get("top"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil {
+			http.Error(w, fmt.Sprintf("invalid top: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if !syncutil.MutexInstrumentationEnabled() {
+		fmt.Fprintf(w, "mutex instrumentation is disabled; "+
+			"enable it with SET CLUSTER SETTING %s = true\n\n", mutexInstrumentationEnabled.Key())
+	}
+	tw := tabwriter.NewWriter(w, 2, 1, 2, ' ', 0)
+	fmt.Fprintln(tw, "name\tacquisitions\tcontended\twait\tmax wait\thold\tmax hold")
+	for _, c := range syncutil.TopContendedMutexes(n) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			c.Name, c.Acquisitions, c.Contended, c.WaitTime, c.MaxWaitTime, c.HoldTime, c.MaxHoldTime)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package debug
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleMutexes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	defer syncutil.EnableMutexInstrumentation(syncutil.MutexInstrumentationEnabled())
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	setupMutexInstrumentation(st)
+	require.False(t, syncutil.MutexInstrumentationEnabled())
+
+	get := func(url string) (int, string) {
+		w := httptest.NewRecorder()
+		handleMutexes(w, httptest.NewRequest("GET", url, nil))
+		return w.Code, w.Body.String()
+	}
+
+	code, body := get("/debug/mutexes")
+	require.Equal(t, http.StatusOK, code)
+	require.Contains(t, body, "mutex instrumentation is disabled")
+
+	mutexInstrumentationEnabled.Override(ctx, &st.SV, true)
+	require.True(t, syncutil.MutexInstrumentationEnabled())
+
+	var mu syncutil.InstrumentedMutex
+	mu.Init("debug.test.mu")
+	mu.Lock()
+	mu.AssertHeld()
+	mu.Unlock()
+
+	code, body = get("/debug/mutexes?top=0")
+	require.Equal(t, http.StatusOK, code)
+	require.NotContains(t, body, "mutex instrumentation is disabled")
+	require.Contains(t, body, "debug.test.mu")
+
+	code, _ = get("/debug/mutexes?top=x")
+	require.Equal(t, http.StatusBadRequest, code)
+}
@@ -110,6 +110,11 @@ func NewServer(
 	// Register the stopper endpoint, which lists all active tasks.
 	mux.HandleFunc("/debug/stopper", stop.HandleDebug)
 
+	// Register the mutexes endpoint, which lists the most contended
+	// instrumented mutexes.
+	setupMutexInstrumentation(st)
+	mux.HandleFunc("/debug/mutexes", handleMutexes)
+
 	// Set up the vmodule endpoint.
 	vsrv := &vmoduleServer{}
 	mux.HandleFunc("/debug/vmodule", vsrv.vmoduleHandleDebug)
@@ -53,6 +53,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/status"
 	"github.com/cockroachdb/cockroach/pkg/server/systemconfigwatcher"
 	"github.com/cockroachdb/cockroach/pkg/server/tracedumper"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/spanconfig"
 	"github.com/cockroachdb/cockroach/pkg/spanconfig/spanconfiglimiter"
@@ -396,6 +397,16 @@ func newRootSQLMemoryMonitor(opts monitorAndMetricsOptions) monitorAndMetrics {
 	}
 }
 
+// recentLogMessagesEnabled controls whether the most recent log entries of
+// the process are retained in memory, for crdb_internal.node_recent_log_messages.
+var recentLogMessagesEnabled = settings.RegisterBoolSetting(
+	settings.TenantReadOnly,
+	"server.log.recent_messages.enabled",
+	"if enabled, the most recent log messages of each node are retained in memory "+
+		"and exposed in crdb_internal.node_recent_log_messages",
+	false,
+).WithPublic()
+
 func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 	// NB: ValidateAddrs also fills in defaults.
 	if err := cfg.Config.ValidateAddrs(ctx); err != nil {
@@ -409,6 +420,11 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 			codec = keys.MakeSQLCodec(override)
 		}
 	}
+	log.SetRecentEntriesRetention(recentLogMessagesEnabled.Get(&cfg.Settings.SV))
+	recentLogMessagesEnabled.SetOnChange(&cfg.Settings.SV, func(context.Context) {
+		log.SetRecentEntriesRetention(recentLogMessagesEnabled.Get(&cfg.Settings.SV))
+	})
+
 	// Create blob service for inter-node file sharing.
 	blobService, err := blobs.NewBlobService(cfg.Settings.ExternalIODir)
 	if err != nil {
@@ -465,6 +481,11 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 		}
 
 		td := tracedumper.NewTraceDumper(ctx, cfg.InflightTraceDirName, cfg.Settings)
+		// The meta1 leaseholder is only known to the system tenant.
+		var isMeta1Leaseholder func(context.Context, hlc.ClockTimestamp) (bool, error)
+		if codec.ForSystemTenant() {
+			isMeta1Leaseholder = cfg.isMeta1Leaseholder
+		}
 		*jobRegistry = *jobs.MakeRegistry(
 			ctx,
 			cfg.AmbientCtx,
@@ -475,6 +496,7 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 			cfg.rpcContext.LogicalClusterID,
 			cfg.nodeIDContainer,
 			cfg.sqlLivenessProvider,
+			isMeta1Leaseholder,
 			cfg.Settings,
 			cfg.HistogramWindowInterval(),
 			func(opName string, user username.SQLUsername) (interface{}, func()) {
@@ -788,6 +810,7 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 		TestingKnobs:              sqlExecutorTestingKnobs,
 		CompactEngineSpanFunc:     storageEngineClient.CompactEngineSpan,
 		CompactionConcurrencyFunc: storageEngineClient.SetCompactionConcurrency,
+		CleanupStoreScratchFunc:   storageEngineClient.CleanupStoreScratch,
 		TraceCollector:            traceCollector,
 		TenantUsageServer:         cfg.tenantUsageServer,
 		KVStoresIterator:          cfg.kvStoresIterator,
@@ -830,7 +853,7 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 		RangeFeedFactory:           cfg.rangeFeedFactory,
 		CollectionFactory:          collectionFactory,
 		SystemTableIDResolver:      descs.MakeSystemTableIDResolver(collectionFactory, cfg.db),
-		ConsistencyChecker:         consistencychecker.NewConsistencyChecker(cfg.db),
+		ConsistencyChecker:         consistencychecker.NewConsistencyChecker(cfg.db, cfg.circularInternalExecutor, jobRegistry, cfg.Settings),
 		RangeProber:                rangeprober.NewRangeProber(cfg.db),
 		DescIDGenerator:            descidgen.NewGenerator(codec, cfg.db),
 		RangeStatsFetcher:          rangeStatsFetcher,
@@ -15,14 +15,19 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
@@ -58,20 +63,55 @@ var (
 		),
 		90*24*time.Hour, // 90 days
 	).WithPublic()
+
+	// schemaChangeStageHistoryTTL is the TTL for rows in
+	// system.schema_change_stage_history.
+	schemaChangeStageHistoryTTL = settings.RegisterDurationSetting(
+		settings.TenantWritable,
+		"server.schema_change_stage_history.ttl",
+		fmt.Sprintf(
+			"if nonzero, entries in system.schema_change_stage_history older than this duration "+
+				"are deleted every %s",
+			systemLogGCPeriod,
+		),
+		30*24*time.Hour, // 30 days
+	)
+
+	// consistencyCheckResultsTTL is the TTL for rows in
+	// system.consistency_check_results.
+	consistencyCheckResultsTTL = settings.RegisterDurationSetting(
+		settings.SystemOnly,
+		"server.consistency_check.results_ttl",
+		fmt.Sprintf(
+			"if nonzero, entries in system.consistency_check_results older than this duration "+
+				"are deleted every %s",
+			systemLogGCPeriod,
+		),
+		30*24*time.Hour, // 30 days
+	)
 )
 
+// systemLogTimestampColumn returns the column of the given system log table
+// which records when its rows were written.
+func systemLogTimestampColumn(table string) string {
+	if table == "consistency_check_results" {
+		return "checked_at"
+	}
+	return "timestamp"
+}
+
 // gcSystemLog deletes entries in the given system log table between
 // timestampLowerBound and timestampUpperBound if the server is the lease holder
 // for range 1.
 // Leaseholder constraint is present so that only one node in the cluster
 // performs gc.
-// The system log table is expected to have a "timestamp" column.
+// The rows of the system log table are expected to be timestamped by the
+// column returned by systemLogTimestampColumn.
 // It returns the timestampLowerBound to be used in the next iteration, number
 // of rows affected and error (if any).
 func (s *Server) gcSystemLog(
 	ctx context.Context, table string, timestampLowerBound, timestampUpperBound time.Time,
 ) (time.Time, int64, error) {
-	var totalRowsAffected int64
 	repl, _, err := s.node.stores.GetReplicaForRangeID(ctx, roachpb.RangeID(1))
 	if roachpb.IsRangeNotFoundError(err) {
 		return timestampLowerBound, 0, nil
@@ -84,17 +124,57 @@ func (s *Server) gcSystemLog(
 		return timestampLowerBound, 0, nil
 	}
 
+	return gcSystemLogTable(
+		ctx, s.db, s.sqlServer.internalExecutor, table, timestampLowerBound, timestampUpperBound,
+	)
+}
+
+// gcSystemLog deletes entries in the given system log table between
+// timestampLowerBound and timestampUpperBound if the SQL instance has the
+// lowest ID among the live instances of the tenant. This constraint is present
+// so that only one instance of the tenant performs gc.
+func (s *SQLServer) gcSystemLog(
+	ctx context.Context, table string, timestampLowerBound, timestampUpperBound time.Time,
+) (time.Time, int64, error) {
+	instances, err := s.sqlInstanceProvider.GetAllInstances(ctx)
+	if err != nil {
+		return timestampLowerBound, 0, err
+	}
+	for _, instance := range instances {
+		if instance.InstanceID < s.SQLInstanceID() {
+			return timestampLowerBound, 0, nil
+		}
+	}
+	return gcSystemLogTable(
+		ctx, s.execCfg.DB, s.internalExecutor, table, timestampLowerBound, timestampUpperBound,
+	)
+}
+
+// gcSystemLogTable deletes entries in the given system log table between
+// timestampLowerBound and timestampUpperBound.
+// The rows of the system log table are expected to be timestamped by the
+// column returned by systemLogTimestampColumn.
+// It returns the timestampLowerBound to be used in the next iteration, number
+// of rows affected and error (if any).
+func gcSystemLogTable(
+	ctx context.Context,
+	db *kv.DB,
+	ie *sql.InternalExecutor,
+	table string,
+	timestampLowerBound, timestampUpperBound time.Time,
+) (time.Time, int64, error) {
+	var totalRowsAffected int64
 	deleteStmt := fmt.Sprintf(
-		`SELECT count(1), max(timestamp) FROM
-[DELETE FROM system.%s WHERE timestamp >= $1 AND timestamp <= $2 LIMIT 1000 RETURNING timestamp]`,
-		table,
+		`SELECT count(1), max(%[2]s) FROM
+[DELETE FROM system.%[1]s WHERE %[2]s >= $1 AND %[2]s <= $2 LIMIT 1000 RETURNING %[2]s]`,
+		table, systemLogTimestampColumn(table),
 	)
 
 	for {
 		var rowsAffected int64
-		err := s.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		err := db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
 			var err error
-			row, err := s.sqlServer.internalExecutor.QueryRowEx(
+			row, err := ie.QueryRowEx(
 				ctx,
 				table+"-gc",
 				txn,
@@ -149,10 +229,14 @@ type systemLogGCConfig struct {
 	// It is maintained to avoid hitting tombstones during gc and is updated
 	// after every gc run.
 	timestampLowerBound time.Time
+	// minVersion is the cluster version which introduced the table. The zero
+	// value is the minimum supported version, which is always active.
+	minVersion clusterversion.Key
 }
 
-// startSystemLogsGC starts a worker which periodically GCs system.rangelog
-// and system.eventlog.
+// startSystemLogsGC starts a worker which periodically GCs system.rangelog,
+// system.eventlog, system.schema_change_stage_history and
+// system.consistency_check_results.
 // The TTLs for each of these logs is retrieved from cluster settings.
 func (s *Server) startSystemLogsGC(ctx context.Context) {
 	systemLogsToGC := map[string]*systemLogGCConfig{
@@ -164,11 +248,55 @@ func (s *Server) startSystemLogsGC(ctx context.Context) {
 			ttl:                 eventLogTTL,
 			timestampLowerBound: timeutil.Unix(0, 0),
 		},
+		"schema_change_stage_history": {
+			ttl:                 schemaChangeStageHistoryTTL,
+			timestampLowerBound: timeutil.Unix(0, 0),
+			minVersion:          clusterversion.SchemaChangeStageHistoryTable,
+		},
+		"consistency_check_results": {
+			ttl:                 consistencyCheckResultsTTL,
+			timestampLowerBound: timeutil.Unix(0, 0),
+			minVersion:          clusterversion.ConsistencyCheckJobs,
+		},
+	}
+	storeKnobs, _ := s.cfg.TestingKnobs.Store.(*kvserver.StoreTestingKnobs)
+	runSystemLogsGC(ctx, s.stopper, s.cfg.Settings, s.clock, storeKnobs, systemLogsToGC, s.gcSystemLog)
+}
+
+// startTenantSystemLogsGC starts a worker which periodically GCs
+// system.schema_change_stage_history in a secondary tenant, whose system
+// tables are not reached by the worker of the KV nodes.
+// The TTL is retrieved from cluster settings.
+func (s *SQLServer) startTenantSystemLogsGC(ctx context.Context) {
+	systemLogsToGC := map[string]*systemLogGCConfig{
+		"schema_change_stage_history": {
+			ttl:                 schemaChangeStageHistoryTTL,
+			timestampLowerBound: timeutil.Unix(0, 0),
+			minVersion:          clusterversion.SchemaChangeStageHistoryTable,
+		},
 	}
+	storeKnobs, _ := s.cfg.TestingKnobs.Store.(*kvserver.StoreTestingKnobs)
+	runSystemLogsGC(
+		ctx, s.stopper, s.execCfg.Settings, s.execCfg.Clock, storeKnobs, systemLogsToGC, s.gcSystemLog,
+	)
+}
 
-	_ = s.stopper.RunAsyncTask(ctx, "system-log-gc", func(ctx context.Context) {
+// runSystemLogsGC starts a worker which periodically GCs the given system log
+// tables using gcFn.
+func runSystemLogsGC(
+	ctx context.Context,
+	stopper *stop.Stopper,
+	st *cluster.Settings,
+	clock *hlc.Clock,
+	storeKnobs *kvserver.StoreTestingKnobs,
+	systemLogsToGC map[string]*systemLogGCConfig,
+	gcFn func(
+		ctx context.Context, table string, timestampLowerBound, timestampUpperBound time.Time,
+	) (time.Time, int64, error),
+) {
+	_ = stopper.RunAsyncTask(ctx, "system-log-gc", func(ctx context.Context) {
 		period := systemLogGCPeriod
-		if storeKnobs, ok := s.cfg.TestingKnobs.Store.(*kvserver.StoreTestingKnobs); ok && storeKnobs.SystemLogsGCPeriod != 0 {
+		if storeKnobs != nil && storeKnobs.SystemLogsGCPeriod != 0 {
 			period = storeKnobs.SystemLogsGCPeriod
 		}
 
@@ -179,10 +307,13 @@ func (s *Server) startSystemLogsGC(ctx context.Context) {
 			select {
 			case <-t.C:
 				for table, gcConfig := range systemLogsToGC {
-					ttl := gcConfig.ttl.Get(&s.cfg.Settings.SV)
+					if !st.Version.IsActive(ctx, gcConfig.minVersion) {
+						continue
+					}
+					ttl := gcConfig.ttl.Get(&st.SV)
 					if ttl > 0 {
-						timestampUpperBound := timeutil.Unix(0, s.clock.PhysicalNow()-int64(ttl))
-						newTimestampLowerBound, rowsAffected, err := s.gcSystemLog(
+						timestampUpperBound := timeutil.Unix(0, clock.PhysicalNow()-int64(ttl))
+						newTimestampLowerBound, rowsAffected, err := gcFn(
 							ctx,
 							table,
 							gcConfig.timestampLowerBound,
@@ -204,15 +335,15 @@ func (s *Server) startSystemLogsGC(ctx context.Context) {
 					}
 				}
 
-				if storeKnobs, ok := s.cfg.TestingKnobs.Store.(*kvserver.StoreTestingKnobs); ok && storeKnobs.SystemLogsGCGCDone != nil {
+				if storeKnobs != nil && storeKnobs.SystemLogsGCGCDone != nil {
 					select {
 					case storeKnobs.SystemLogsGCGCDone <- struct{}{}:
-					case <-s.stopper.ShouldQuiesce():
+					case <-stopper.ShouldQuiesce():
 						// Test has finished.
 						return
 					}
 				}
-			case <-s.stopper.ShouldQuiesce():
+			case <-stopper.ShouldQuiesce():
 				return
 			}
 		}
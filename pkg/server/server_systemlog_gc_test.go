@@ -23,10 +23,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLogGC(t *testing.T) {
@@ -138,6 +140,38 @@ func TestLogGC(t *testing.T) {
 	a.Equal(5, rangeLogRowCount())
 }
 
+// TestConsistencyCheckResultsGC checks that the results of consistency check
+// jobs are garbage collected according to their checked_at column.
+func TestConsistencyCheckResultsGC(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		DisableDefaultTestTenant: true,
+	})
+	defer s.Stopper().Stop(ctx)
+	ts := s.(*TestServer)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	now := timeutil.Now()
+	for i, checkedAt := range []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Hour), now} {
+		tdb.Exec(t, `
+INSERT INTO system.consistency_check_results (job_id, range_id, start_key, status, detail, checked_at)
+VALUES (1, $1, '', 'RANGE_CONSISTENT', '', $2)`, i+1, checkedAt)
+	}
+
+	_, rowsGCd, err := ts.GCSystemLog(
+		ctx, "consistency_check_results", timeutil.Unix(0, 0), now.Add(-30*time.Minute),
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, rowsGCd)
+	tdb.CheckQueryResults(t,
+		`SELECT range_id FROM system.consistency_check_results WHERE job_id = 1`,
+		[][]string{{"3"}},
+	)
+}
+
 func TestLogGCTrigger(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -239,3 +273,50 @@ func TestLogGCTrigger(t *testing.T) {
 		})
 	}
 }
+
+// TestTenantLogGCTrigger checks that system.schema_change_stage_history is
+// garbage collected in secondary tenants.
+func TestTenantLogGCTrigger(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{
+		DisableDefaultTestTenant: true,
+	})
+	defer s.Stopper().Stop(ctx)
+
+	gcDone := make(chan struct{})
+	_, db := serverutils.StartTenant(t, s, base.TestTenantArgs{
+		TenantID: serverutils.TestTenantID(),
+		TestingKnobs: base.TestingKnobs{
+			Store: &kvserver.StoreTestingKnobs{
+				SystemLogsGCGCDone: gcDone,
+				SystemLogsGCPeriod: time.Nanosecond,
+			},
+		},
+	})
+	defer db.Close()
+
+	_, err := db.Exec(`INSERT INTO system.schema_change_stage_history (
+      timestamp, job_id, description, phase, stage_ordinal, stages_in_phase, started, attempts
+    ) VALUES (
+      now() - interval '10s', 1, 'test', 'PostCommitPhase', 1, 1, now() - interval '11s', 1
+    )`)
+	require.NoError(t, err)
+	rowCount := func() (count int) {
+		require.NoError(t, db.QueryRow(`SELECT count(*) FROM system.schema_change_stage_history WHERE job_id = 1`).Scan(&count))
+		return count
+	}
+
+	// Reading gcDone twice guarantees that a full gc run has completed.
+	<-gcDone
+	<-gcDone
+	require.Equal(t, 1, rowCount())
+
+	_, err = db.Exec(`SET CLUSTER SETTING server.schema_change_stage_history.ttl = '1us'`)
+	require.NoError(t, err)
+	<-gcDone
+	<-gcDone
+	require.Zero(t, rowCount())
+}
@@ -36,6 +36,8 @@ type stmtDiagnosticsRequest struct {
 	MinExecutionLatency time.Duration
 	// Zero value indicates that the request never expires.
 	ExpiresAt time.Time
+	// Redacted indicates that the bundle has its user data scrubbed.
+	Redacted bool
 }
 
 type stmtDiagnostics struct {
@@ -53,6 +55,7 @@ func (request *stmtDiagnosticsRequest) toProto() serverpb.StatementDiagnosticsRe
 		RequestedAt:            request.RequestedAt,
 		MinExecutionLatency:    request.MinExecutionLatency,
 		ExpiresAt:              request.ExpiresAt,
+		Redacted:               request.Redacted,
 	}
 	return resp
 }
@@ -89,12 +92,14 @@ func (s *statusServer) CreateStatementDiagnosticsReport(
 		req.SamplingProbability,
 		req.MinExecutionLatency,
 		req.ExpiresAfter,
+		req.Redacted,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	response.Report.StatementFingerprint = req.StatementFingerprint
+	response.Report.Redacted = req.Redacted
 	return response, nil
 }
 
@@ -143,6 +148,11 @@ func (s *statusServer) StatementDiagnosticsRequests(
 		extraColumns = `,
 			sampling_probability`
 	}
+	isRedactedSupported := s.admin.server.st.Version.IsActive(ctx, clusterversion.RedactedStmtDiagReqs)
+	if isRedactedSupported {
+		extraColumns += `,
+			redacted`
+	}
 	// TODO(davidh): Add pagination to this request.
 	it, err := s.internalExecutor.QueryIteratorEx(ctx, "stmt-diag-get-all", nil, /* txn */
 		sessiondata.InternalExecutorOverride{
@@ -186,6 +196,9 @@ func (s *statusServer) StatementDiagnosticsRequests(
 				req.SamplingProbability = float64(*samplingProbability)
 			}
 		}
+		if isRedactedSupported {
+			req.Redacted = bool(*row[8].(*tree.DBool))
+		}
 
 		if minExecutionLatency, ok := row[5].(*tree.DInterval); ok {
 			req.MinExecutionLatency = time.Duration(minExecutionLatency.Duration.Nanos())
@@ -527,12 +527,15 @@ type StmtDiagnosticsRequester interface {
 	//   and the bundle is not generated for them.
 	// - expiresAfter, if non-zero, indicates for how long the request should
 	//   stay active.
+	// - redacted, if set, indicates that literals, placeholder values and
+	//   sampled rows should be scrubbed from the collected bundle.
 	InsertRequest(
 		ctx context.Context,
 		stmtFingerprint string,
 		samplingProbability float64,
 		minExecutionLatency time.Duration,
 		expiresAfter time.Duration,
+		redacted bool,
 	) error
 	// CancelRequest updates an entry in system.statement_diagnostics_requests
 	// for tracing a query with the given fingerprint to be expired (thus,
@@ -1120,7 +1123,7 @@ func (s *statusServer) LogFilesList(
 	ctx = propagateGatewayMetadata(ctx)
 	ctx = s.AnnotateCtx(ctx)
 
-	if _, err := s.privilegeChecker.requireAdminUser(ctx); err != nil {
+	if _, err := s.privilegeChecker.requireViewLogsPermission(ctx); err != nil {
 		// NB: not using serverError() here since the priv checker
 		// already returns a proper gRPC error status.
 		return nil, err
@@ -1155,7 +1158,8 @@ func (s *statusServer) LogFile(
 	ctx = propagateGatewayMetadata(ctx)
 	ctx = s.AnnotateCtx(ctx)
 
-	if _, err := s.privilegeChecker.requireAdminUser(ctx); err != nil {
+	canViewUnredacted, err := s.privilegeChecker.requireViewLogsPermission(ctx)
+	if err != nil {
 		// NB: not using serverError() here since the priv checker
 		// already returns a proper gRPC error status.
 		return nil, err
@@ -1173,8 +1177,10 @@ func (s *statusServer) LogFile(
 		return status.LogFile(ctx, req)
 	}
 
-	// Determine how to redact.
-	inputEditMode := log.SelectEditMode(req.Redact, log.KeepRedactable)
+	// Determine how to redact. The client can request redaction, but
+	// cannot opt out of it if the user is only allowed to see redacted
+	// log entries.
+	inputEditMode := log.SelectEditMode(req.Redact || !canViewUnredacted, log.KeepRedactable)
 
 	// Ensure that the latest log entries are available in files.
 	log.Flush()
@@ -1201,6 +1207,9 @@ func (s *statusServer) LogFile(
 		}
 		resp.Entries = append(resp.Entries, entry)
 	}
+	if req.MaxBytes > 0 {
+		resp.Entries, _ = log.TrimEntries(resp.Entries, req.MaxBytes)
+	}
 
 	return &resp, nil
 }
@@ -1242,7 +1251,8 @@ func (s *statusServer) Logs(
 	ctx = propagateGatewayMetadata(ctx)
 	ctx = s.AnnotateCtx(ctx)
 
-	if _, err := s.privilegeChecker.requireAdminUser(ctx); err != nil {
+	canViewUnredacted, err := s.privilegeChecker.requireViewLogsPermission(ctx)
+	if err != nil {
 		// NB: not using serverError() here since the priv checker
 		// already returns a proper gRPC error status.
 		return nil, err
@@ -1260,8 +1270,10 @@ func (s *statusServer) Logs(
 		return status.Logs(ctx, req)
 	}
 
-	// Determine how to redact.
-	inputEditMode := log.SelectEditMode(req.Redact, log.KeepRedactable)
+	// Determine how to redact. The client can request redaction, but
+	// cannot opt out of it if the user is only allowed to see redacted
+	// log entries.
+	inputEditMode := log.SelectEditMode(req.Redact || !canViewUnredacted, log.KeepRedactable)
 
 	// Select the time interval.
 	startTimestamp, err := parseInt64WithDefault(
@@ -3186,6 +3198,97 @@ func (s *statusServer) ListExecutionInsights(
 	return &response, nil
 }
 
+// StoreSnapshots returns the snapshots in flight and the most recently
+// completed ones on the stores of the cluster, or of a single node.
+func (s *statusServer) StoreSnapshots(
+	ctx context.Context, req *serverpb.StoreSnapshotsRequest,
+) (*serverpb.StoreSnapshotsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	// Check permissions early to avoid fan-out to all nodes.
+	if _, err := s.privilegeChecker.requireAdminUser(ctx); err != nil {
+		// NB: not using serverError() here since the priv checker
+		// already returns a proper gRPC error status.
+		return nil, err
+	}
+
+	localRequest := serverpb.StoreSnapshotsRequest{NodeID: "local"}
+
+	if len(req.NodeID) > 0 {
+		requestedNodeID, local, err := s.parseNodeID(req.NodeID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		if local {
+			return s.localStoreSnapshots()
+		}
+		statusClient, err := s.dialNode(ctx, requestedNodeID)
+		if err != nil {
+			return nil, serverError(ctx, err)
+		}
+		return statusClient.StoreSnapshots(ctx, &localRequest)
+	}
+
+	var response serverpb.StoreSnapshotsResponse
+
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		return s.dialNode(ctx, nodeID)
+	}
+	nodeFn := func(ctx context.Context, client interface{}, nodeID roachpb.NodeID) (interface{}, error) {
+		statusClient := client.(serverpb.StatusClient)
+		resp, err := statusClient.StoreSnapshots(ctx, &localRequest)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+	responseFn := func(nodeID roachpb.NodeID, nodeResponse interface{}) {
+		if nodeResponse == nil {
+			return
+		}
+		snapshotsResponse := nodeResponse.(*serverpb.StoreSnapshotsResponse)
+		response.Snapshots = append(response.Snapshots, snapshotsResponse.Snapshots...)
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		response.Errors = append(response.Errors, errors.EncodeError(ctx, err))
+	}
+
+	if err := s.iterateNodes(ctx, "store snapshots", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		return nil, serverError(ctx, err)
+	}
+	return &response, nil
+}
+
+func (s *statusServer) localStoreSnapshots() (*serverpb.StoreSnapshotsResponse, error) {
+	var response serverpb.StoreSnapshotsResponse
+	if err := s.stores.VisitStores(func(store *kvserver.Store) error {
+		for _, snap := range store.SnapshotHistory() {
+			response.Snapshots = append(response.Snapshots, serverpb.StoreSnapshot{
+				NodeID:        store.NodeID(),
+				StoreID:       store.StoreID(),
+				SnapshotID:    snap.SnapshotID,
+				RangeID:       snap.RangeID,
+				Direction:     string(snap.Direction),
+				PeerStoreID:   snap.PeerStoreID,
+				Type:          snap.Type.String(),
+				Priority:      snap.Priority.String(),
+				Bytes:         snap.Bytes,
+				ExpectedBytes: snap.ExpectedBytes,
+				StartedAt:     snap.StartedAt,
+				Duration:      snap.Duration,
+				InFlight:      snap.InFlight,
+				Error:         snap.Error,
+				ETA:           snap.ETA,
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
 // SpanStats requests the total statistics stored on a node for a given key
 // span, which may include multiple ranges.
 func (s *statusServer) SpanStats(
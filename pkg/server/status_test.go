@@ -572,6 +572,25 @@ func TestStatusLocalLogs(t *testing.T) {
 		t.Errorf("expected to find test messages in %v", wrapper.Files)
 	}
 
+	// Log files exceeding the requested size are trimmed by the server.
+	{
+		var trimmed serverpb.LogEntriesResponse
+		if err := getStatusJSONProto(
+			ts, "logfiles/local/"+wrapper.Files[0].Name+"?max_bytes=1000", &trimmed,
+		); err != nil {
+			t.Fatal(err)
+		}
+		var foundOmitted bool
+		for _, entry := range trimmed.Entries {
+			if strings.HasSuffix(entry.Message, "log entries omitted to fit the size budget") {
+				foundOmitted = true
+			}
+		}
+		if !foundOmitted {
+			t.Errorf("expected omitted log entries, got %v", trimmed.Entries)
+		}
+	}
+
 	type levelPresence struct {
 		Error, Warning, Info bool
 	}
@@ -71,6 +71,11 @@ func StartTenant(
 	if err != nil {
 		return nil, err
 	}
+	// The SQL server runs in its own process, whose logging configuration the
+	// tenant can therefore override. The tenant servers started by
+	// startTenantInternal alone share the process of a host server, and its
+	// logging configuration.
+	startTenantLoggingOverrides(ctx, stopper, baseCfg.Settings)
 	return &SQLServerWrapper{
 		SQLServer:   sqlServer,
 		authServer:  authServer,
@@ -369,6 +374,8 @@ func startTenantInternal(
 		return nil, nil, nil, "", "", err
 	}
 
+	s.startTenantSystemLogsGC(ctx)
+
 	if err := s.startServeSQL(ctx,
 		args.stopper,
 		s.connManager,
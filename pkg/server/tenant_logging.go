@@ -0,0 +1,144 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// tenantLoggingOverrides lets a secondary tenant change the channel
+// routing, the severity thresholds and the redaction of the log sinks
+// of its SQL servers, within the limits set by the host cluster below.
+var tenantLoggingOverrides = settings.RegisterValidatedStringSetting(
+	settings.TenantWritable,
+	"server.logging.overrides",
+	"changes to the logging configuration of the SQL servers of a secondary tenant, "+
+		"in YAML (e.g. 'file-groups: {default: {channels: [DEV, OPS], redact: true}}'); "+
+		"the sinks must be defined in the startup configuration",
+	"",
+	func(sv *settings.Values, s string) error {
+		o, err := logconfig.ParseOverrides(s)
+		if err != nil {
+			return err
+		}
+		if sv == nil {
+			return nil
+		}
+		return o.CheckLimits(tenantLoggingLimits(sv))
+	},
+)
+
+// tenantLoggingAllowedSinkTypes and tenantLoggingMaxFileGroupSize are
+// set by the host cluster to bound server.logging.overrides.
+var tenantLoggingAllowedSinkTypes = settings.RegisterValidatedStringSetting(
+	settings.TenantReadOnly,
+	"server.tenant_logging.allowed_sink_types",
+	"comma-separated list of the types of log sinks (file, fluent, http, stderr) "+
+		"which secondary tenants can configure using server.logging.overrides",
+	"file,stderr",
+	func(_ *settings.Values, s string) error {
+		for _, t := range splitSinkTypes(s) {
+			switch t {
+			case logconfig.FileSinkType, logconfig.FluentSinkType,
+				logconfig.HTTPSinkType, logconfig.StderrSinkType:
+			default:
+				return errors.Newf("unknown sink type: %q", t)
+			}
+		}
+		return nil
+	},
+)
+
+var tenantLoggingMaxFileGroupSize = settings.RegisterByteSizeSetting(
+	settings.TenantReadOnly,
+	"server.tenant_logging.max_file_group_size",
+	"maximum combined size of the files of a log file group which secondary tenants "+
+		"can configure using server.logging.overrides (0 = no limit)",
+	100<<20, /* 100 MiB */
+)
+
+func splitSinkTypes(s string) []string {
+	var res []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func tenantLoggingLimits(sv *settings.Values) logconfig.OverrideLimits {
+	return logconfig.OverrideLimits{
+		AllowedSinkTypes: splitSinkTypes(tenantLoggingAllowedSinkTypes.Get(sv)),
+		MaxGroupSize:     logconfig.ByteSize(tenantLoggingMaxFileGroupSize.Get(sv)),
+	}
+}
+
+// startTenantLoggingOverrides applies server.logging.overrides to the
+// logging configuration of the process, and re-applies it every time
+// the overrides or the limits set by the host cluster change, until the
+// stopper stops.
+//
+// The logging configuration is global to the process, so this must
+// only be called for a SQL server running in its own process: a tenant
+// server sharing the process of the host would otherwise rewrite the
+// sinks of the host and of the other tenants.
+//
+// Overrides which exceed the current limits, e.g. because the host
+// cluster lowered them, are not applied and the startup configuration
+// is restored instead.
+func startTenantLoggingOverrides(ctx context.Context, stopper *stop.Stopper, st *cluster.Settings) {
+	var mu struct {
+		syncutil.Mutex
+		// applied is set once non-empty overrides have been applied.
+		applied bool
+	}
+	apply := func(ctx context.Context) {
+		mu.Lock()
+		defer mu.Unlock()
+		o, err := logconfig.ParseOverrides(tenantLoggingOverrides.Get(&st.SV))
+		if err == nil {
+			err = o.CheckLimits(tenantLoggingLimits(&st.SV))
+		}
+		if err != nil {
+			log.Ops.Warningf(ctx, "ignoring logging overrides: %v", err)
+			o = logconfig.Overrides{}
+		}
+		if o.IsEmpty() && !mu.applied {
+			// Nothing to restore.
+			return
+		}
+		mu.applied = !o.IsEmpty()
+		if err := log.ApplyOverrides(o); err != nil {
+			log.Ops.Warningf(ctx, "unable to apply logging overrides: %v", err)
+		}
+	}
+	removeHooks := []func(){
+		tenantLoggingOverrides.SetOnChangeRemovable(&st.SV, apply),
+		tenantLoggingAllowedSinkTypes.SetOnChangeRemovable(&st.SV, apply),
+		tenantLoggingMaxFileGroupSize.SetOnChangeRemovable(&st.SV, apply),
+	}
+	stopper.AddCloser(stop.CloserFn(func() {
+		for _, remove := range removeHooks {
+			remove()
+		}
+	}))
+	apply(ctx)
+}
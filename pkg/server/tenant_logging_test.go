@@ -0,0 +1,72 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+func TestTenantLoggingOverrides(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, hostDB, _ := serverutils.StartServer(t, base.TestServerArgs{
+		DisableDefaultTestTenant: true,
+	})
+	defer s.Stopper().Stop(ctx)
+	hostSQL := sqlutils.MakeSQLRunner(hostDB)
+
+	tenantID := serverutils.TestTenantID()
+	_, db := serverutils.StartTenant(t, s, base.TestTenantArgs{TenantID: tenantID})
+	defer db.Close()
+	tenantSQL := sqlutils.MakeSQLRunner(db)
+
+	// The tenant can configure the sinks allowed by the host cluster.
+	tenantSQL.Exec(t, `SET CLUSTER SETTING server.logging.overrides = 'stderr: {redact: true}'`)
+	tenantSQL.Exec(t, `SET CLUSTER SETTING server.logging.overrides = 'file-groups: {default: {max-group-size: 10MiB}}'`)
+	tenantSQL.ExpectErr(t, `http sinks cannot be configured`,
+		`SET CLUSTER SETTING server.logging.overrides = 'http-servers: {collector: {redact: true}}'`)
+	tenantSQL.ExpectErr(t, `max-group-size cannot exceed 100MiB`,
+		`SET CLUSTER SETTING server.logging.overrides = 'file-groups: {default: {max-group-size: 1GiB}}'`)
+	tenantSQL.ExpectErr(t, `only valid for file groups`,
+		`SET CLUSTER SETTING server.logging.overrides = 'stderr: {max-group-size: 1MiB}'`)
+
+	// The tenant cannot raise the limits itself.
+	tenantSQL.ExpectErr(t, `only settable by the operator`,
+		`SET CLUSTER SETTING server.tenant_logging.allowed_sink_types = 'file,stderr,http'`)
+
+	// The limits set by the host cluster apply to the tenant.
+	hostSQL.Exec(t, fmt.Sprintf(
+		`ALTER TENANT %d SET CLUSTER SETTING server.tenant_logging.allowed_sink_types = 'file'`,
+		tenantID.ToUint64()))
+	testutils.SucceedsSoon(t, func() error {
+		if _, err := db.Exec(
+			`SET CLUSTER SETTING server.logging.overrides = 'stderr: {redact: false}'`,
+		); err == nil {
+			return errors.New("stderr overrides are still allowed")
+		}
+		return nil
+	})
+	hostSQL.ExpectErr(t, `unknown sink type`, fmt.Sprintf(
+		`ALTER TENANT %d SET CLUSTER SETTING server.tenant_logging.allowed_sink_types = 'file,syslog'`,
+		tenantID.ToUint64()))
+}
@@ -1396,7 +1396,8 @@ func (ts *TestServer) Tracer() *tracing.Tracer {
 // for range 1.
 // Leaseholder constraint is present so that only one node in the cluster
 // performs gc.
-// The system log table is expected to have a "timestamp" column.
+// The rows of the system log table are expected to be timestamped by the
+// column returned by systemLogTimestampColumn.
 // It returns the timestampLowerBound to be used in the next iteration, number
 // of rows affected and error (if any).
 func (ts *TestServer) GCSystemLog(
@@ -107,6 +107,13 @@ func (c *common) SetOnChange(sv *Values, fn func(ctx context.Context)) {
 	sv.setOnChange(c.slot, fn)
 }
 
+// SetOnChangeRemovable is like SetOnChange, but returns a function which
+// removes the callback, for callers which don't live as long as sv.
+func (c *common) SetOnChangeRemovable(sv *Values, fn func(ctx context.Context)) (remove func()) {
+	cb := sv.setOnChange(c.slot, fn)
+	return func() { sv.removeOnChange(c.slot, cb) }
+}
+
 type internalSetting interface {
 	NonMaskedSetting
 
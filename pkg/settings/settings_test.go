@@ -724,6 +724,29 @@ func TestOnChangeWithMaxSettings(t *testing.T) {
 	}
 }
 
+func TestRemoveOnChange(t *testing.T) {
+	ctx := context.Background()
+	sv := &settings.Values{}
+	sv.Init(ctx, settings.TestOpaque)
+
+	var kept, removed int
+	strFooA.SetOnChange(sv, func(context.Context) { kept++ })
+	remove := strFooA.SetOnChangeRemovable(sv, func(context.Context) { removed++ })
+
+	u := settings.NewUpdater(sv)
+	if err := u.Set(ctx, "str.foo", v("a", "s")); err != nil {
+		t.Fatal(err)
+	}
+	remove()
+	if err := u.Set(ctx, "str.foo", v("b", "s")); err != nil {
+		t.Fatal(err)
+	}
+	if kept != 2 || removed != 1 {
+		t.Errorf("expected 2 calls of the kept callback and 1 of the removed one, got %d and %d",
+			kept, removed)
+	}
+}
+
 func TestMaxSettingsPanics(t *testing.T) {
 	defer settings.TestingSaveRegistry()()
 
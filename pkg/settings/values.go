@@ -43,8 +43,9 @@ type Values struct {
 	changeMu struct {
 		syncutil.Mutex
 		// NB: any in place modification to individual slices must also hold the
-		// lock, e.g. if we ever add RemoveOnChange or something.
-		onChange [MaxSettings][]func(ctx context.Context)
+		// lock. Callbacks are removed by copying the slice (see
+		// removeOnChange), since settingChanged iterates over it unlocked.
+		onChange [MaxSettings][]*onChangeCallback
 	}
 	// opaque is an arbitrary object that can be set by a higher layer to make it
 	// accessible from certain callbacks (like state machine transformers).
@@ -143,8 +144,8 @@ func (sv *Values) settingChanged(ctx context.Context, slot slotIdx) {
 	sv.changeMu.Lock()
 	funcs := sv.changeMu.onChange[slot]
 	sv.changeMu.Unlock()
-	for _, fn := range funcs {
-		fn(ctx)
+	for _, cb := range funcs {
+		cb.fn(ctx)
 	}
 }
 
@@ -189,8 +190,31 @@ func (sv *Values) getGeneric(slot slotIdx) interface{} {
 // setOnChange installs a callback to be called when a setting's value changes.
 // `fn` should avoid doing long-running or blocking work as it is called on the
 // goroutine which handles all settings updates.
-func (sv *Values) setOnChange(slot slotIdx, fn func(ctx context.Context)) {
+func (sv *Values) setOnChange(slot slotIdx, fn func(ctx context.Context)) *onChangeCallback {
+	cb := &onChangeCallback{fn: fn}
 	sv.changeMu.Lock()
-	sv.changeMu.onChange[slot] = append(sv.changeMu.onChange[slot], fn)
+	sv.changeMu.onChange[slot] = append(sv.changeMu.onChange[slot], cb)
 	sv.changeMu.Unlock()
+	return cb
+}
+
+// removeOnChange removes a callback installed by setOnChange.
+func (sv *Values) removeOnChange(slot slotIdx, cb *onChangeCallback) {
+	sv.changeMu.Lock()
+	defer sv.changeMu.Unlock()
+	funcs := sv.changeMu.onChange[slot]
+	for i := range funcs {
+		if funcs[i] == cb {
+			newFuncs := make([]*onChangeCallback, 0, len(funcs)-1)
+			newFuncs = append(append(newFuncs, funcs[:i]...), funcs[i+1:]...)
+			sv.changeMu.onChange[slot] = newFuncs
+			return
+		}
+	}
+}
+
+// onChangeCallback is a callback installed by setOnChange. It is referenced
+// through a pointer so that it can be removed.
+type onChangeCallback struct {
+	fn func(ctx context.Context)
 }
@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/decodeusername"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -50,9 +51,13 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/storageparam"
 	"github.com/cockroachdb/cockroach/pkg/sql/storageparam/tablestorageparam"
 	"github.com/cockroachdb/cockroach/pkg/sql/syntheticprivilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/ttl/ttlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
 )
@@ -715,7 +720,7 @@ func (n *alterTableNode) startExec(params runParams) error {
 			}
 
 		case *tree.AlterTableSetAudit:
-			changed, err := params.p.setAuditMode(params.ctx, n.tableDesc, t.Mode)
+			changed, err := params.p.setAuditMode(params.ctx, n.tableDesc, t.Mode, t.Role)
 			if err != nil {
 				return err
 			}
@@ -889,13 +894,13 @@ func (n *alterTableNode) startExec(params runParams) error {
 }
 
 func (p *planner) setAuditMode(
-	ctx context.Context, desc *tabledesc.Mutable, auditMode tree.AuditMode,
+	ctx context.Context, desc *tabledesc.Mutable, auditMode tree.AuditMode, roleSpec tree.RoleSpec,
 ) (bool, error) {
 	// An auditing config change is itself auditable!
 	// We record the event even if the permission check below fails:
 	// auditing wants to know who tried to change the settings.
 	p.curPlan.auditEvents = append(p.curPlan.auditEvents,
-		auditEvent{desc: desc, writing: true})
+		auditEvent{desc: desc, writing: true, mode: desc.GetAuditMode()})
 
 	// Requires admin or MODIFYCLUSTERSETTING as of 22.2
 	hasAdmin, err := p.HasAdminRole(ctx)
@@ -920,9 +925,52 @@ func (p *planner) setAuditMode(
 		}
 	}
 
+	// Nodes running older versions would not know how to interpret the
+	// audit modes which select the type of access, nor the per-role audit
+	// policies.
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.TableAuditPolicies) {
+		if auditMode == tree.AuditModeRead || auditMode == tree.AuditModeWrite {
+			return false, pgerror.Newf(pgcode.FeatureNotSupported,
+				"audit mode %s is not supported until upgrade to version %s is finalized",
+				auditMode, clusterversion.TableAuditPolicies.String())
+		}
+		if !roleSpec.Undefined() {
+			return false, pgerror.Newf(pgcode.FeatureNotSupported,
+				"per-role audit policies are not supported until upgrade to version %s is finalized",
+				clusterversion.TableAuditPolicies.String())
+		}
+	}
+
 	telemetry.Inc(sqltelemetry.SchemaSetAuditModeCounter(auditMode.TelemetryName()))
 
-	return desc.SetAuditMode(auditMode)
+	// Audit events are only guaranteed to be persisted if the
+	// SENSITIVE_ACCESS channel is connected to a durable sink. This
+	// only reflects the logging configuration of the current node.
+	if auditMode != tree.AuditModeDisable && !log.HasDurableSink(channel.SENSITIVE_ACCESS, severity.INFO) {
+		p.BufferClientNotice(ctx, errors.WithHint(
+			pgnotice.Newf("the SENSITIVE_ACCESS logging channel is not connected to a durable sink; "+
+				"audit events may be lost upon a node crash"),
+			"Configure an auditable file sink for the SENSITIVE_ACCESS channel; network sinks are not durable."))
+	}
+
+	if roleSpec.Undefined() {
+		return desc.SetAuditMode(auditMode)
+	}
+	role, err := decodeusername.FromRoleSpec(p.SessionData(), username.PurposeValidation, roleSpec)
+	if err != nil {
+		return false, err
+	}
+	// Audit policies can be removed for roles which no longer exist.
+	if auditMode != tree.AuditModeDisable {
+		exists, err := p.RoleExists(ctx, role)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			return false, pgerror.Newf(pgcode.UndefinedObject, "role/user %q does not exist", role)
+		}
+	}
+	return desc.SetRoleAuditMode(role, auditMode)
 }
 
 func (n *alterTableNode) Next(runParams) (bool, error) { return false, nil }
@@ -1883,7 +1931,7 @@ func handleTTLStorageParamChange(
 			if err != nil {
 				return errors.Wrapf(err, "unexpected expression for TTL duration")
 			}
-			newExpr := rowLevelTTLAutomaticColumnExpr(intervalExpr)
+			newExpr := ttlbase.AutomaticColumnExpr(intervalExpr)
 
 			if err := updateNonComputedColExpr(
 				params,
@@ -1921,7 +1969,7 @@ func handleTTLStorageParamChange(
 				colinfo.TTLDefaultExpirationColumnName,
 			)
 		}
-		col, err := rowLevelTTLAutomaticColumnDef(after)
+		col, err := ttlbase.AutomaticColumnDef(after)
 		if err != nil {
 			return err
 		}
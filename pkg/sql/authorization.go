@@ -154,7 +154,7 @@ func (p *planner) CheckPrivilegeForUser(
 	// it will not be forgotten if features are added that access
 	// descriptors (since every use of descriptors presumably need a
 	// permission check).
-	p.maybeAudit(privilegeObject, privilegeKind)
+	p.maybeAudit(ctx, privilegeObject, privilegeKind, user)
 
 	privs, err := privilegeObject.GetPrivilegeDescriptor(ctx, p)
 	if err != nil {
@@ -339,6 +339,8 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 	// Tables introduced in 22.2.
 	target.AddDescriptor(systemschema.SystemPrivilegeTable)
 	target.AddDescriptor(systemschema.SystemExternalConnectionsTable)
+	target.AddDescriptor(systemschema.SchemaChangeStageHistoryTable)
+	target.AddDescriptor(systemschema.ConsistencyCheckResultsTable)
 	target.AddDescriptor(systemschema.RoleIDSequence)
 
 	// Adding a new system table? It should be added here to the metadata schema,
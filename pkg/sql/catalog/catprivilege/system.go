@@ -64,6 +64,8 @@ var (
 		catconstants.SpanCountTableName,
 		catconstants.SystemPrivilegeTableName,
 		catconstants.SystemExternalConnectionsTableName,
+		catconstants.SchemaChangeStageHistoryTableName,
+		catconstants.ConsistencyCheckResultsTableName,
 	}
 
 	readWriteSystemSequences = []catconstants.SystemTableName{
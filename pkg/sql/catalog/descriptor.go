@@ -291,6 +291,9 @@ type TableDescriptor interface {
 	IsSequence() bool
 	// IsTemporary returns true if this is a temporary table.
 	IsTemporary() bool
+	// GetAuditPolicies returns the per-role audit policies of the table,
+	// which apply in addition to its audit mode.
+	GetAuditPolicies() []descpb.TableDescriptor_AuditPolicy
 	// IsVirtualTable returns true if the TableDescriptor describes a
 	// virtual Table (like the information_schema tables) and thus doesn't
 	// need to be physically stored.
@@ -407,10 +407,11 @@ CREATE TABLE system.statement_diagnostics_requests(
 	min_execution_latency INTERVAL NULL,
 	expires_at TIMESTAMPTZ NULL,
 	sampling_probability FLOAT NULL,
+	redacted BOOL NOT NULL DEFAULT FALSE,
 	CONSTRAINT "primary" PRIMARY KEY (id),
 	CONSTRAINT check_sampling_probability CHECK (sampling_probability BETWEEN 0.0 AND 1.0),
-	INDEX completed_idx (completed, id) STORING (statement_fingerprint, min_execution_latency, expires_at, sampling_probability),
-	FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at, min_execution_latency, expires_at, sampling_probability)
+	INDEX completed_idx (completed, id) STORING (statement_fingerprint, min_execution_latency, expires_at, sampling_probability, redacted),
+	FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at, min_execution_latency, expires_at, sampling_probability, redacted)
 );`
 
 	StatementDiagnosticsTableSchema = `
@@ -698,6 +699,41 @@ CREATE TABLE system.external_connections (
 	CONSTRAINT "primary" PRIMARY KEY (connection_name),
 	FAMILY "primary" (connection_name, created, updated, connection_type, connection_details, owner)
 );`
+
+	// SchemaChangeStageHistoryTableSchema stores, for each declarative
+	// schema change job, a row per executed stage. The primary key is keyed
+	// on the time at which the stage finished so that old rows can be garbage
+	// collected like the other system log tables.
+	SchemaChangeStageHistoryTableSchema = `
+CREATE TABLE system.schema_change_stage_history (
+	timestamp TIMESTAMP NOT NULL,
+	id INT8 NOT NULL DEFAULT unique_rowid(),
+	job_id INT8 NOT NULL,
+	description STRING NOT NULL,
+	phase STRING NOT NULL,
+	stage_ordinal INT8 NOT NULL,
+	stages_in_phase INT8 NOT NULL,
+	started TIMESTAMP NOT NULL,
+	attempts INT8 NOT NULL,
+	error STRING NULL,
+	CONSTRAINT "primary" PRIMARY KEY (timestamp, id),
+	INDEX job_id_idx (job_id),
+	FAMILY "primary" (timestamp, id, job_id, description, phase, stage_ordinal, stages_in_phase, started, attempts, error)
+);`
+
+	// ConsistencyCheckResultsTableSchema stores the per-range results of
+	// consistency check jobs.
+	ConsistencyCheckResultsTableSchema = `
+CREATE TABLE system.consistency_check_results (
+	job_id INT8 NOT NULL,
+	range_id INT8 NOT NULL,
+	start_key BYTES NOT NULL,
+	status STRING NOT NULL,
+	detail STRING NOT NULL,
+	checked_at TIMESTAMPTZ NOT NULL,
+	CONSTRAINT "primary" PRIMARY KEY (job_id, range_id),
+	FAMILY "primary" (job_id, range_id, start_key, status, detail, checked_at)
+);`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -1875,12 +1911,13 @@ var (
 				{Name: "min_execution_latency", ID: 6, Type: types.Interval, Nullable: true},
 				{Name: "expires_at", ID: 7, Type: types.TimestampTZ, Nullable: true},
 				{Name: "sampling_probability", ID: 8, Type: types.Float, Nullable: true},
+				{Name: "redacted", ID: 9, Type: types.Bool, Nullable: false, DefaultExpr: &falseBoolString},
 			},
 			[]descpb.ColumnFamilyDescriptor{
 				{
 					Name:        "primary",
-					ColumnNames: []string{"id", "completed", "statement_fingerprint", "statement_diagnostics_id", "requested_at", "min_execution_latency", "expires_at", "sampling_probability"},
-					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8},
+					ColumnNames: []string{"id", "completed", "statement_fingerprint", "statement_diagnostics_id", "requested_at", "min_execution_latency", "expires_at", "sampling_probability", "redacted"},
+					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9},
 				},
 			},
 			pk("id"),
@@ -1890,10 +1927,10 @@ var (
 				ID:                  2,
 				Unique:              false,
 				KeyColumnNames:      []string{"completed", "id"},
-				StoreColumnNames:    []string{"statement_fingerprint", "min_execution_latency", "expires_at", "sampling_probability"},
+				StoreColumnNames:    []string{"statement_fingerprint", "min_execution_latency", "expires_at", "sampling_probability", "redacted"},
 				KeyColumnIDs:        []descpb.ColumnID{2, 1},
 				KeyColumnDirections: []catpb.IndexColumn_Direction{catpb.IndexColumn_ASC, catpb.IndexColumn_ASC},
-				StoreColumnIDs:      []descpb.ColumnID{3, 6, 7, 8},
+				StoreColumnIDs:      []descpb.ColumnID{3, 6, 7, 8, 9},
 				Version:             descpb.StrictIndexColumnIDGuaranteesVersion,
 			},
 		),
@@ -2562,6 +2599,84 @@ var (
 			},
 		),
 	)
+
+	SchemaChangeStageHistoryTable = registerSystemTable(
+		SchemaChangeStageHistoryTableSchema,
+		systemTable(
+			catconstants.SchemaChangeStageHistoryTableName,
+			descpb.InvalidID, // dynamically assigned
+			[]descpb.ColumnDescriptor{
+				{Name: "timestamp", ID: 1, Type: types.Timestamp},
+				{Name: "id", ID: 2, Type: types.Int, DefaultExpr: &uniqueRowIDString},
+				{Name: "job_id", ID: 3, Type: types.Int},
+				{Name: "description", ID: 4, Type: types.String},
+				{Name: "phase", ID: 5, Type: types.String},
+				{Name: "stage_ordinal", ID: 6, Type: types.Int},
+				{Name: "stages_in_phase", ID: 7, Type: types.Int},
+				{Name: "started", ID: 8, Type: types.Timestamp},
+				{Name: "attempts", ID: 9, Type: types.Int},
+				{Name: "error", ID: 10, Type: types.String, Nullable: true},
+			},
+			[]descpb.ColumnFamilyDescriptor{
+				{
+					Name:        "primary",
+					ID:          0,
+					ColumnNames: []string{"timestamp", "id", "job_id", "description", "phase", "stage_ordinal", "stages_in_phase", "started", "attempts", "error"},
+					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+				},
+			},
+			descpb.IndexDescriptor{
+				Name:                "primary",
+				ID:                  1,
+				Unique:              true,
+				KeyColumnNames:      []string{"timestamp", "id"},
+				KeyColumnDirections: []catpb.IndexColumn_Direction{catpb.IndexColumn_ASC, catpb.IndexColumn_ASC},
+				KeyColumnIDs:        []descpb.ColumnID{1, 2},
+			},
+			descpb.IndexDescriptor{
+				Name:                "job_id_idx",
+				ID:                  2,
+				Unique:              false,
+				KeyColumnNames:      []string{"job_id"},
+				KeyColumnDirections: singleASC,
+				KeyColumnIDs:        []descpb.ColumnID{3},
+				KeySuffixColumnIDs:  []descpb.ColumnID{1, 2},
+				Version:             descpb.StrictIndexColumnIDGuaranteesVersion,
+			},
+		),
+	)
+
+	ConsistencyCheckResultsTable = registerSystemTable(
+		ConsistencyCheckResultsTableSchema,
+		systemTable(
+			catconstants.ConsistencyCheckResultsTableName,
+			descpb.InvalidID, // dynamically assigned
+			[]descpb.ColumnDescriptor{
+				{Name: "job_id", ID: 1, Type: types.Int},
+				{Name: "range_id", ID: 2, Type: types.Int},
+				{Name: "start_key", ID: 3, Type: types.Bytes},
+				{Name: "status", ID: 4, Type: types.String},
+				{Name: "detail", ID: 5, Type: types.String},
+				{Name: "checked_at", ID: 6, Type: types.TimestampTZ},
+			},
+			[]descpb.ColumnFamilyDescriptor{
+				{
+					Name:        "primary",
+					ID:          0,
+					ColumnNames: []string{"job_id", "range_id", "start_key", "status", "detail", "checked_at"},
+					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5, 6},
+				},
+			},
+			descpb.IndexDescriptor{
+				Name:                "primary",
+				ID:                  1,
+				Unique:              true,
+				KeyColumnNames:      []string{"job_id", "range_id"},
+				KeyColumnDirections: []catpb.IndexColumn_Direction{catpb.IndexColumn_ASC, catpb.IndexColumn_ASC},
+				KeyColumnIDs:        []descpb.ColumnID{1, 2},
+			},
+		),
+	)
 )
 
 type descRefByName struct {
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/docs"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
@@ -2444,16 +2445,59 @@ func (desc *wrapper) GetFamilyOfColumn(
 // SetAuditMode configures the audit mode on the descriptor.
 func (desc *Mutable) SetAuditMode(mode tree.AuditMode) (bool, error) {
 	prev := desc.AuditMode
+	m, err := auditModeToProto(mode)
+	if err != nil {
+		return false, err
+	}
+	desc.AuditMode = m
+	return prev != desc.AuditMode, nil
+}
+
+// SetRoleAuditMode configures the audit mode applied to the members of the
+// given role on the descriptor. Disabling it removes the audit policy of the
+// role.
+func (desc *Mutable) SetRoleAuditMode(role username.SQLUsername, mode tree.AuditMode) (bool, error) {
+	m, err := auditModeToProto(mode)
+	if err != nil {
+		return false, err
+	}
+	for i := range desc.AuditPolicies {
+		policy := &desc.AuditPolicies[i]
+		if policy.Role != role.Normalized() {
+			continue
+		}
+		if m == descpb.TableDescriptor_DISABLED {
+			desc.AuditPolicies = append(desc.AuditPolicies[:i], desc.AuditPolicies[i+1:]...)
+			return true, nil
+		}
+		prev := policy.Mode
+		policy.Mode = m
+		return prev != policy.Mode, nil
+	}
+	if m == descpb.TableDescriptor_DISABLED {
+		return false, nil
+	}
+	desc.AuditPolicies = append(desc.AuditPolicies, descpb.TableDescriptor_AuditPolicy{
+		Role: role.Normalized(),
+		Mode: m,
+	})
+	return true, nil
+}
+
+func auditModeToProto(mode tree.AuditMode) (descpb.TableDescriptor_AuditMode, error) {
 	switch mode {
 	case tree.AuditModeDisable:
-		desc.AuditMode = descpb.TableDescriptor_DISABLED
+		return descpb.TableDescriptor_DISABLED, nil
 	case tree.AuditModeReadWrite:
-		desc.AuditMode = descpb.TableDescriptor_READWRITE
+		return descpb.TableDescriptor_READWRITE, nil
+	case tree.AuditModeRead:
+		return descpb.TableDescriptor_READ, nil
+	case tree.AuditModeWrite:
+		return descpb.TableDescriptor_WRITE, nil
 	default:
-		return false, pgerror.Newf(pgcode.InvalidParameterValue,
+		return descpb.TableDescriptor_DISABLED, pgerror.Newf(pgcode.InvalidParameterValue,
 			"unknown audit mode: %s (%d)", mode, mode)
 	}
-	return prev != desc.AuditMode, nil
 }
 
 // FindAllReferences returns all the references from a table.
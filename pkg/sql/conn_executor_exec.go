@@ -385,6 +385,7 @@ func (ex *connExecutor) execStmtInOpenState(
 		case tree.ExplainDebug:
 			telemetry.Inc(sqltelemetry.ExplainAnalyzeDebugUseCounter)
 			ih.SetOutputMode(explainAnalyzeDebugOutput, explain.Flags{})
+			ih.redactBundle = e.Flags[tree.ExplainFlagRedact]
 
 		case tree.ExplainPlan:
 			telemetry.Inc(sqltelemetry.ExplainAnalyzeUseCounter)
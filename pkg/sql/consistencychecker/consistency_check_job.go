@@ -0,0 +1,255 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package consistencychecker
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// rangeDelay is the time a consistency check job waits after checking a range
+// before checking the next one, which limits the load it puts on the cluster.
+var rangeDelay = settings.RegisterDurationSetting(
+	settings.SystemOnly,
+	"server.consistency_check.job_range_delay",
+	"the time a consistency check job waits between the checks of two consecutive ranges",
+	100*time.Millisecond,
+	settings.NonNegativeDuration,
+)
+
+// consistencyCheckResumer implements the jobs.Resumer interface for
+// consistency check jobs. The job pages through the meta ranges and checks the
+// ranges of its span one at a time, in key order, and persists the result of each check along with its
+// progress, so that it can be paused and resumed from the first range which
+// hasn't been checked yet.
+type consistencyCheckResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = (*consistencyCheckResumer)(nil)
+
+// Resume is part of the jobs.Resumer interface.
+func (r *consistencyCheckResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	execCfg := execCtx.(sql.JobExecContext).ExecCfg()
+	details := r.job.Details().(jobspb.ConsistencyCheckDetails)
+	progress := r.job.Progress()
+	from := details.StartKey
+	if p := progress.GetConsistencyCheck(); p != nil && len(p.ResumeKey) > 0 {
+		from = p.ResumeKey
+	}
+	if from.Compare(details.EndKey) >= 0 {
+		return nil
+	}
+
+	// The ranges are counted up front so that the fraction completed can be
+	// reported as they are checked. The fraction completed by the previous
+	// executions of the job, if any, is preserved and the remainder is spread
+	// evenly over the ranges left to check.
+	var numRanges int
+	if err := forEachRangeDescriptor(ctx, execCfg.DB, from, details.EndKey,
+		func(roachpb.RangeDescriptor) error {
+			numRanges++
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+	startFraction := progress.GetFractionCompleted()
+
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	var checked int
+	return forEachRangeDescriptor(ctx, execCfg.DB, from, details.EndKey,
+		func(desc roachpb.RangeDescriptor) error {
+			// Only check the part of the range which overlaps the span left to
+			// check. If the range was split since the meta ranges were scanned,
+			// the check covers each of the resulting ranges.
+			span := roachpb.Span{Key: desc.StartKey.AsRawKey(), EndKey: desc.EndKey.AsRawKey()}
+			if span.Key.Compare(from) < 0 {
+				span.Key = from
+			}
+			if span.EndKey.Compare(details.EndKey) > 0 {
+				span.EndKey = details.EndKey
+			}
+			if span.Key.Compare(span.EndKey) >= 0 {
+				return nil
+			}
+
+			// Wait between the checks of consecutive ranges, unless the caller
+			// is waiting for the results.
+			if delay := rangeDelay.Get(&execCfg.Settings.SV); checked > 0 && delay > 0 &&
+				!details.SkipRangeDelay {
+				timer.Reset(delay)
+				select {
+				case <-timer.C:
+					timer.Read = true
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			var results []roachpb.CheckConsistencyResponse_Result
+			resp, err := execCfg.ConsistencyChecker.CheckConsistency(ctx, span.Key, span.EndKey, details.Mode)
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+				// The check of a single range failing, e.g. because the range is
+				// unavailable, shouldn't prevent the others from being checked.
+				log.Warningf(ctx, "consistency check of r%d failed: %v", desc.RangeID, err)
+				results = []roachpb.CheckConsistencyResponse_Result{{
+					RangeID:  desc.RangeID,
+					StartKey: span.Key,
+					Status:   roachpb.CheckConsistencyResponse_RANGE_INDETERMINATE,
+					Detail:   err.Error(),
+				}}
+			} else {
+				results = resp.Result
+			}
+
+			checked++
+			fraction := startFraction + (1-startFraction)*float32(checked)/float32(numRanges)
+			if fraction > 1 {
+				// The span was split into more ranges since they were counted.
+				fraction = 1
+			}
+			return r.persistResults(ctx, execCfg, results, span.EndKey, fraction)
+		},
+	)
+}
+
+// metaScanPageSize is the number of range descriptors read from the meta
+// ranges at a time by a consistency check job.
+var metaScanPageSize = util.ConstantWithMetamorphicTestRange(
+	"consistency-check-meta-scan-page-size", 1000 /* defaultValue */, 1 /* min */, 1000, /* max */
+)
+
+// forEachRangeDescriptor calls fn with the descriptors of the ranges
+// overlapping [from, to), in key order. The descriptors are read from the
+// meta2 records metaScanPageSize at a time, so that the memory used doesn't
+// depend on the size of the span.
+func forEachRangeDescriptor(
+	ctx context.Context, db *kv.DB, from, to roachpb.Key, fn func(roachpb.RangeDescriptor) error,
+) error {
+	// The meta2 records are addressed by the end key of their range, so the
+	// first range overlapping the span is the first record after from.
+	start := keys.RangeMetaKey(keys.MustAddr(from).Next()).AsRawKey()
+	if start.Compare(keys.Meta2Prefix) < 0 {
+		start = keys.Meta2Prefix
+	}
+	end := keys.Meta2Prefix.PrefixEnd()
+	for {
+		kvs, err := db.Scan(ctx, start, end, int64(metaScanPageSize))
+		if err != nil {
+			return errors.Wrap(err, "error scanning meta ranges")
+		}
+		for i := range kvs {
+			var desc roachpb.RangeDescriptor
+			if err := kvs[i].ValueProto(&desc); err != nil {
+				return err
+			}
+			if desc.StartKey.AsRawKey().Compare(to) >= 0 {
+				return nil
+			}
+			if err := fn(desc); err != nil {
+				return err
+			}
+		}
+		if len(kvs) < metaScanPageSize {
+			return nil
+		}
+		start = kvs[len(kvs)-1].Key.Next()
+	}
+}
+
+// persistResults writes the results of the check of a range into
+// system.consistency_check_results and, in the same transaction, records in
+// the job's progress that the check can resume from resumeKey.
+func (r *consistencyCheckResumer) persistResults(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	results []roachpb.CheckConsistencyResponse_Result,
+	resumeKey roachpb.Key,
+	fraction float32,
+) error {
+	var failed int64
+	for i := range results {
+		if !isConsistent(results[i].Status) {
+			failed++
+		}
+	}
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		for i := range results {
+			res := &results[i]
+			if _, err := execCfg.InternalExecutor.ExecEx(
+				ctx, "insert-consistency-check-result", txn,
+				sessiondata.NodeUserSessionDataOverride,
+				`UPSERT INTO system.consistency_check_results
+  (job_id, range_id, start_key, status, detail, checked_at)
+VALUES ($1, $2, $3, $4, $5, now())`,
+				r.job.ID(), res.RangeID, res.StartKey, res.Status.String(), res.Detail,
+			); err != nil {
+				return err
+			}
+		}
+		return r.job.FractionProgressed(ctx, txn,
+			func(ctx context.Context, details jobspb.ProgressDetails) float32 {
+				p := details.(*jobspb.Progress_ConsistencyCheck).ConsistencyCheck
+				p.ResumeKey = resumeKey
+				p.RangesChecked += int64(len(results))
+				p.RangesFailed += failed
+				return fraction
+			},
+		)
+	})
+}
+
+// isConsistent returns whether the status of a range's consistency check
+// indicates that the range is consistent.
+func isConsistent(status roachpb.CheckConsistencyResponse_Status) bool {
+	switch status {
+	case roachpb.CheckConsistencyResponse_RANGE_CONSISTENT,
+		roachpb.CheckConsistencyResponse_RANGE_CONSISTENT_STATS_ESTIMATED:
+		return true
+	default:
+		return false
+	}
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. The results of the
+// ranges checked before the job failed or was canceled are kept.
+func (r *consistencyCheckResumer) OnFailOrCancel(context.Context, interface{}, error) error {
+	return nil
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeConsistencyCheck,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &consistencyCheckResumer{job: job}
+		},
+		jobs.UsesTenantCostControl,
+	)
+}
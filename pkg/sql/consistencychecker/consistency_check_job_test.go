@@ -0,0 +1,127 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package consistencychecker_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/testutils/jobutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsistencyCheckJob(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		// Consistency checks are only available to the system tenant.
+		DisableDefaultTestTenant: true,
+	})
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY)`)
+	tdb.Exec(t, `INSERT INTO t SELECT generate_series(1, 40)`)
+	tdb.Exec(t, `ALTER TABLE t SPLIT AT VALUES (10), (20), (30)`)
+	var tableID uint32
+	tdb.QueryRow(t, `SELECT 't'::regclass::oid`).Scan(&tableID)
+	startKey := keys.SystemSQLCodec.TablePrefix(tableID)
+	endKey := startKey.PrefixEnd()
+
+	startJob := func() jobspb.JobID {
+		var jobID jobspb.JobID
+		tdb.QueryRow(t,
+			`SELECT crdb_internal.start_consistency_check_job(true, $1, $2)`,
+			[]byte(startKey), []byte(endKey),
+		).Scan(&jobID)
+		return jobID
+	}
+	// The table's span overlaps four ranges, which are all consistent.
+	checkResults := func(jobID jobspb.JobID) {
+		tdb.CheckQueryResults(t, fmt.Sprintf(`
+SELECT count(*), count(DISTINCT range_id)
+  FROM system.consistency_check_results
+ WHERE job_id = %d
+   AND status IN ('RANGE_CONSISTENT', 'RANGE_CONSISTENT_STATS_ESTIMATED')`, jobID),
+			[][]string{{"4", "4"}},
+		)
+	}
+
+	t.Run("basic", func(t *testing.T) {
+		tdb.Exec(t, `SET CLUSTER SETTING server.consistency_check.job_range_delay = '0s'`)
+		jobID := startJob()
+		jobutils.WaitForJobToSucceed(t, tdb, jobID)
+		checkResults(jobID)
+		tdb.CheckQueryResults(t,
+			fmt.Sprintf(`SELECT fraction_completed FROM [SHOW JOB %d]`, jobID),
+			[][]string{{"1"}},
+		)
+	})
+
+	t.Run("pause and resume", func(t *testing.T) {
+		// With a long delay between ranges, the job pauses after checking the
+		// first range.
+		tdb.Exec(t, `SET CLUSTER SETTING server.consistency_check.job_range_delay = '1h'`)
+		jobID := startJob()
+		tdb.CheckQueryResultsRetry(t, fmt.Sprintf(
+			`SELECT count(*) FROM system.consistency_check_results WHERE job_id = %d`, jobID,
+		), [][]string{{"1"}})
+		tdb.Exec(t, `PAUSE JOB $1`, jobID)
+		jobutils.WaitForJobToPause(t, tdb, jobID)
+		var firstRangeID int64
+		var firstCheckedAt time.Time
+		tdb.QueryRow(t, `
+SELECT range_id, checked_at FROM system.consistency_check_results WHERE job_id = $1`, jobID,
+		).Scan(&firstRangeID, &firstCheckedAt)
+
+		// Once resumed, the job doesn't check the first range again.
+		tdb.Exec(t, `SET CLUSTER SETTING server.consistency_check.job_range_delay = '0s'`)
+		tdb.Exec(t, `RESUME JOB $1`, jobID)
+		jobutils.WaitForJobToSucceed(t, tdb, jobID)
+		checkResults(jobID)
+		var checkedAt time.Time
+		tdb.QueryRow(t, `
+SELECT checked_at FROM system.consistency_check_results WHERE job_id = $1 AND range_id = $2`,
+			jobID, firstRangeID,
+		).Scan(&checkedAt)
+		require.Equal(t, firstCheckedAt, checkedAt)
+	})
+
+	t.Run("check_consistency", func(t *testing.T) {
+		// crdb_internal.check_consistency runs a job which isn't paced, and
+		// returns the results it persisted.
+		tdb.Exec(t, `SET CLUSTER SETTING server.consistency_check.job_range_delay = '1h'`)
+		require.Equal(t, [][]string{{"4", "4"}}, tdb.QueryStr(t, `
+SELECT count(*), count(DISTINCT range_id)
+  FROM crdb_internal.check_consistency(true, $1, $2)
+ WHERE status IN ('RANGE_CONSISTENT', 'RANGE_CONSISTENT_STATS_ESTIMATED')`,
+			[]byte(startKey), []byte(endKey),
+		))
+		var jobID jobspb.JobID
+		tdb.QueryRow(t, `
+SELECT job_id FROM [SHOW JOBS]
+ WHERE job_type = 'CONSISTENCY CHECK' AND status = 'succeeded'
+ ORDER BY created DESC LIMIT 1`,
+		).Scan(&jobID)
+		checkResults(jobID)
+	})
+}
@@ -12,21 +12,39 @@ package consistencychecker
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/errors"
 )
 
 // ConsistencyChecker implements ConsistencyCheckRunner.
 type ConsistencyChecker struct {
 	db *kv.DB
+	ie sqlutil.InternalExecutor
+	jr *jobs.Registry
+	st *cluster.Settings
 }
 
 // NewConsistencyChecker returns a new instance of
 // consistencychecker.ConsistencyChecker.
-func NewConsistencyChecker(db *kv.DB) *ConsistencyChecker {
+func NewConsistencyChecker(
+	db *kv.DB, ie sqlutil.InternalExecutor, jr *jobs.Registry, st *cluster.Settings,
+) *ConsistencyChecker {
 	return &ConsistencyChecker{
 		db: db,
+		ie: ie,
+		jr: jr,
+		st: st,
 	}
 }
 
@@ -51,3 +69,97 @@ func (s *ConsistencyChecker) CheckConsistency(
 	resp := b.RawResponse().Responses[0].GetInner().(*roachpb.CheckConsistencyResponse)
 	return resp, nil
 }
+
+// RunConsistencyCheck implements the eval.ConsistencyChecker interface.
+func (s *ConsistencyChecker) RunConsistencyCheck(
+	ctx context.Context, from, to roachpb.Key, mode roachpb.ChecksumMode, user username.SQLUsername,
+) (_ []roachpb.CheckConsistencyResponse_Result, retErr error) {
+	if !s.st.Version.IsActive(ctx, clusterversion.ConsistencyCheckJobs) {
+		resp, err := s.CheckConsistency(ctx, from, to, mode)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Result, nil
+	}
+	jobID, err := s.startJob(ctx, from, to, mode, user, true /* skipRangeDelay */)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.jr.WaitForJobs(ctx, s.ie, []jobspb.JobID{jobID}); err != nil {
+		return nil, err
+	}
+	it, err := s.ie.QueryIteratorEx(ctx, "read-consistency-check-results", nil, /* txn */
+		sessiondata.NodeUserSessionDataOverride, `
+SELECT range_id, start_key, status, detail
+  FROM system.consistency_check_results
+ WHERE job_id = $1
+ ORDER BY start_key`, jobID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { retErr = errors.CombineErrors(retErr, it.Close()) }()
+	var results []roachpb.CheckConsistencyResponse_Result
+	var ok bool
+	for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+		row := it.Cur()
+		status, found := roachpb.CheckConsistencyResponse_Status_value[string(tree.MustBeDString(row[2]))]
+		if !found {
+			return nil, errors.AssertionFailedf("unknown consistency check status %s", row[2])
+		}
+		results = append(results, roachpb.CheckConsistencyResponse_Result{
+			RangeID:  roachpb.RangeID(tree.MustBeDInt(row[0])),
+			StartKey: []byte(tree.MustBeDBytes(row[1])),
+			Status:   roachpb.CheckConsistencyResponse_Status(status),
+			Detail:   string(tree.MustBeDString(row[3])),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// StartConsistencyCheckJob implements the eval.ConsistencyChecker interface.
+func (s *ConsistencyChecker) StartConsistencyCheckJob(
+	ctx context.Context, from, to roachpb.Key, mode roachpb.ChecksumMode, user username.SQLUsername,
+) (int64, error) {
+	if !s.st.Version.IsActive(ctx, clusterversion.ConsistencyCheckJobs) {
+		return 0, errors.New(
+			"consistency check jobs only supported after 22.2 version migrations have completed",
+		)
+	}
+	jobID, err := s.startJob(ctx, from, to, mode, user, false /* skipRangeDelay */)
+	return int64(jobID), err
+}
+
+// startJob creates a consistency check job and notifies the registry to run
+// it.
+func (s *ConsistencyChecker) startJob(
+	ctx context.Context,
+	from, to roachpb.Key,
+	mode roachpb.ChecksumMode,
+	user username.SQLUsername,
+	skipRangeDelay bool,
+) (jobspb.JobID, error) {
+	record := jobs.Record{
+		Description: fmt.Sprintf("consistency check of %s", roachpb.Span{Key: from, EndKey: to}),
+		Username:    user,
+		Details: jobspb.ConsistencyCheckDetails{
+			StartKey:       from,
+			EndKey:         to,
+			Mode:           mode,
+			SkipRangeDelay: skipRangeDelay,
+		},
+		Progress: jobspb.ConsistencyCheckProgress{},
+	}
+	jobID := s.jr.MakeJobID()
+	if err := s.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		_, err := s.jr.CreateJobWithTxn(ctx, record, jobID, txn)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	s.jr.NotifyToResume(ctx, jobID)
+	return jobID, nil
+}
@@ -74,6 +74,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -82,6 +83,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/tracing/collector"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
 	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
 )
 
 // CrdbInternalName is the name of the crdb_internal schema.
@@ -135,8 +137,10 @@ var crdbInternal = virtualSchema{
 		catconstants.CrdbInternalInflightTraceSpanTableID:           crdbInternalInflightTraceSpanTable,
 		catconstants.CrdbInternalJobsTableID:                        crdbInternalJobsTable,
 		catconstants.CrdbInternalKVNodeStatusTableID:                crdbInternalKVNodeStatusTable,
+		catconstants.CrdbInternalKVStoreSnapshotsTableID:            crdbInternalKVStoreSnapshotsTable,
 		catconstants.CrdbInternalKVStoreStatusTableID:               crdbInternalKVStoreStatusTable,
 		catconstants.CrdbInternalLeasesTableID:                      crdbInternalLeasesTable,
+		catconstants.CrdbInternalLoggingConfigTableID:               crdbInternalLoggingConfigTable,
 		catconstants.CrdbInternalLocalContentionEventsTableID:       crdbInternalLocalContentionEventsTable,
 		catconstants.CrdbInternalLocalDistSQLFlowsTableID:           crdbInternalLocalDistSQLFlowsTable,
 		catconstants.CrdbInternalLocalQueriesTableID:                crdbInternalLocalQueriesTable,
@@ -146,11 +150,13 @@ var crdbInternal = virtualSchema{
 		catconstants.CrdbInternalNodeExecutionInsightsTableID:       crdbInternalNodeExecutionInsightsTable,
 		catconstants.CrdbInternalNodeStmtStatsTableID:               crdbInternalNodeStmtStatsTable,
 		catconstants.CrdbInternalNodeTxnStatsTableID:                crdbInternalNodeTxnStatsTable,
+		catconstants.CrdbInternalNodeRecentLogMessagesTableID:       crdbInternalNodeRecentLogMessagesTable,
 		catconstants.CrdbInternalPartitionsTableID:                  crdbInternalPartitionsTable,
 		catconstants.CrdbInternalPredefinedCommentsTableID:          crdbInternalPredefinedCommentsTable,
 		catconstants.CrdbInternalRangesNoLeasesTableID:              crdbInternalRangesNoLeasesTable,
 		catconstants.CrdbInternalRangesViewID:                       crdbInternalRangesView,
 		catconstants.CrdbInternalRuntimeInfoTableID:                 crdbInternalRuntimeInfoTable,
+		catconstants.CrdbInternalSchemaChangeStageHistoryTableID:    crdbInternalSchemaChangeStageHistoryTable,
 		catconstants.CrdbInternalSchemaChangesTableID:               crdbInternalSchemaChangesTable,
 		catconstants.CrdbInternalSessionTraceTableID:                crdbInternalSessionTraceTable,
 		catconstants.CrdbInternalSessionVariablesTableID:            crdbInternalSessionVariablesTable,
@@ -210,6 +216,177 @@ CREATE TABLE crdb_internal.node_build_info (
 	},
 }
 
+// crdbInternalNodeRecentLogMessagesTable exposes the log entries most
+// recently emitted by the local node, retained in memory when the
+// server.log.recent_messages.enabled cluster setting is set. Users with the
+// VIEWLOGSREDACTED privilege only see the redacted messages.
+var crdbInternalNodeRecentLogMessagesTable = virtualSchemaTable{
+	comment: `recent log messages across all channels (RAM; local node only)`,
+	schema: `
+CREATE TABLE crdb_internal.node_recent_log_messages (
+  node_id   INT NOT NULL,
+  time      TIMESTAMPTZ NOT NULL,
+  channel   STRING NOT NULL,
+  severity  STRING NOT NULL,
+  goroutine INT NOT NULL,
+  file      STRING NOT NULL,
+  line      INT NOT NULL,
+  tags      STRING NOT NULL,
+  message   STRING NOT NULL,
+  INDEX(channel),
+  INDEX(severity)
+)`,
+	indexes: []virtualIndex{
+		{populate: func(ctx context.Context, unwrappedConstraint tree.Datum, p *planner,
+			_ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) (bool, error) {
+			ch, ok := logpb.Channel_value[string(tree.MustBeDString(unwrappedConstraint))]
+			if !ok {
+				return false, nil
+			}
+			return true, populateRecentLogMessages(ctx, p, func(e *logpb.Entry) bool {
+				return e.Channel == logpb.Channel(ch)
+			}, addRow)
+		}},
+		{populate: func(ctx context.Context, unwrappedConstraint tree.Datum, p *planner,
+			_ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) (bool, error) {
+			sev, ok := logpb.Severity_value[string(tree.MustBeDString(unwrappedConstraint))]
+			if !ok {
+				return false, nil
+			}
+			return true, populateRecentLogMessages(ctx, p, func(e *logpb.Entry) bool {
+				return e.Severity == logpb.Severity(sev)
+			}, addRow)
+		}},
+	},
+	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		return populateRecentLogMessages(ctx, p, nil /* include */, addRow)
+	},
+}
+
+// populateRecentLogMessages populates crdb_internal.node_recent_log_messages
+// with the recent log entries for which include returns true, or all of them
+// if include is nil.
+func populateRecentLogMessages(
+	ctx context.Context, p *planner, include func(*logpb.Entry) bool, addRow func(...tree.Datum) error,
+) error {
+	canViewUnredacted, err := p.hasViewLogsPrivilege(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := log.RecentEntries(include, !canViewUnredacted /* redactSensitiveData */)
+	if err != nil {
+		return err
+	}
+	nodeID, _ := p.execCfg.NodeInfo.NodeID.OptionalNodeID() // zero if not available
+	nodeIDDatum := tree.NewDInt(tree.DInt(nodeID))
+	for i := range entries {
+		e := &entries[i]
+		ts, err := tree.MakeDTimestampTZ(timeutil.Unix(0, e.Time), time.Microsecond)
+		if err != nil {
+			return err
+		}
+		msg, tags := e.Message, e.Tags
+		if canViewUnredacted && e.Redactable {
+			msg = redact.RedactableString(msg).StripMarkers()
+			tags = redact.RedactableString(tags).StripMarkers()
+		}
+		if err := addRow(
+			nodeIDDatum,
+			ts,
+			tree.NewDString(e.Channel.String()),
+			tree.NewDString(e.Severity.String()),
+			tree.NewDInt(tree.DInt(e.Goroutine)),
+			tree.NewDString(e.File),
+			tree.NewDInt(tree.DInt(e.Line)),
+			tree.NewDString(tags),
+			tree.NewDString(msg),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasViewLogsPrivilege checks that the user can view the logs, and returns
+// whether they can view them unredacted: admin users and users with the
+// VIEWLOGS privilege can, users with the VIEWLOGSREDACTED privilege cannot.
+// VIEWLOGSREDACTED takes precedence over VIEWLOGS, so that a user can be
+// restricted to redacted logs even if they inherit VIEWLOGS from a role.
+func (p *planner) hasViewLogsPrivilege(ctx context.Context) (canViewUnredacted bool, _ error) {
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return false, err
+	}
+	if hasAdmin {
+		return true, nil
+	}
+	if p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.SystemPrivilegesTable) {
+		if p.CheckPrivilege(ctx, syntheticprivilege.GlobalPrivilegeObject, privilege.VIEWLOGSREDACTED) == nil {
+			return false, nil
+		}
+		if p.CheckPrivilege(ctx, syntheticprivilege.GlobalPrivilegeObject, privilege.VIEWLOGS) == nil {
+			return true, nil
+		}
+	}
+	return false, pgerror.Newf(pgcode.InsufficientPrivilege,
+		"user %s does not have %s or %s privilege", p.User(), privilege.VIEWLOGS, privilege.VIEWLOGSREDACTED)
+}
+
+// crdbInternalLoggingConfigTable exposes the logging configuration in
+// effect on the local node, including the overrides set through
+// server.logging.overrides, with one row per channel connected to each
+// sink.
+var crdbInternalLoggingConfigTable = virtualSchemaTable{
+	comment: `effective logging configuration (RAM; local node only)`,
+	schema: `
+CREATE TABLE crdb_internal.logging_config (
+  node_id       INT NOT NULL,
+  sink_type     STRING NOT NULL,
+  sink_name     STRING NOT NULL,
+  sink_address  STRING,
+  channel       STRING NOT NULL,
+  severity      STRING NOT NULL,
+  format        STRING NOT NULL,
+  redact        BOOL NOT NULL,
+  redactable    BOOL NOT NULL,
+  exit_on_error BOOL NOT NULL,
+  buffering     STRING NOT NULL,
+  active        BOOL NOT NULL,
+  output_errors INT NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if _, err := p.hasViewLogsPrivilege(ctx); err != nil {
+			return err
+		}
+		nodeID, _ := p.execCfg.NodeInfo.NodeID.OptionalNodeID() // zero if not available
+		nodeIDDatum := tree.NewDInt(tree.DInt(nodeID))
+		for _, d := range log.DescribeSinks() {
+			address := tree.DNull
+			if d.Address != "" {
+				address = tree.NewDString(d.Address)
+			}
+			if err := addRow(
+				nodeIDDatum,
+				tree.NewDString(d.SinkType),
+				tree.NewDString(d.Name),
+				address,
+				tree.NewDString(d.Channel.String()),
+				tree.NewDString(d.Threshold.String()),
+				tree.NewDString(d.Format),
+				tree.MakeDBool(tree.DBool(d.Redact)),
+				tree.MakeDBool(tree.DBool(d.Redactable)),
+				tree.MakeDBool(tree.DBool(d.ExitOnError)),
+				tree.NewDString(d.Buffering),
+				tree.MakeDBool(tree.DBool(d.Active)),
+				tree.NewDInt(tree.DInt(d.OutputErrors)),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 var crdbInternalRuntimeInfoTable = virtualSchemaTable{
 	comment: `server parameters, useful to construct connection URLs (RAM, local node only)`,
 	schema: `
@@ -726,6 +903,54 @@ CREATE TABLE crdb_internal.schema_changes (
 	},
 }
 
+var crdbInternalSchemaChangeStageHistoryTable = virtualSchemaTable{
+	comment: `stages executed by declarative schema change jobs (KV scan)`,
+	schema: `
+CREATE TABLE crdb_internal.schema_change_stage_history (
+  job_id          INT NOT NULL,
+  description     STRING NOT NULL,
+  phase           STRING NOT NULL,
+  stage_ordinal   INT NOT NULL,
+  stages_in_phase INT NOT NULL,
+  started         TIMESTAMP NOT NULL,
+  finished        TIMESTAMP NOT NULL,
+  duration        INTERVAL NOT NULL,
+  attempts        INT NOT NULL,
+  error           STRING
+)`,
+	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.schema_change_stage_history"); err != nil {
+			return err
+		}
+		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.SchemaChangeStageHistoryTable) {
+			return nil
+		}
+		it, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.QueryIteratorEx(
+			ctx, "crdb-internal-schema-change-stage-history-table", p.Txn(),
+			sessiondata.InternalExecutorOverride{User: username.RootUserName()},
+			`SELECT job_id, description, phase, stage_ordinal, stages_in_phase,
+       started, timestamp, timestamp - started, attempts, error
+  FROM system.schema_change_stage_history
+ ORDER BY job_id, started`)
+		if err != nil {
+			return err
+		}
+		for {
+			ok, err := it.Next(ctx)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+			if err := addRow(it.Cur()...); err != nil {
+				return err
+			}
+		}
+		return it.Close()
+	},
+}
+
 // TODO(tbg): prefix with node_.
 var crdbInternalLeasesTable = virtualSchemaTable{
 	comment: `acquired table leases (RAM; local node only)`,
@@ -4683,6 +4908,78 @@ CREATE TABLE crdb_internal.kv_store_status (
 	},
 }
 
+// crdbInternalKVStoreSnapshotsTable exposes the snapshots in flight and the
+// most recently completed ones on all the stores of the cluster.
+var crdbInternalKVStoreSnapshotsTable = virtualSchemaTable{
+	comment: "snapshots sent and received by stores, in flight and recent (cluster RPC; expensive!)",
+	schema: `
+CREATE TABLE crdb_internal.kv_store_snapshots (
+  node_id       INT NOT NULL,
+  store_id      INT NOT NULL,
+  range_id      INT NOT NULL,
+  snapshot_id   UUID NOT NULL,
+  direction     STRING NOT NULL,
+  peer_store_id INT NOT NULL,
+  type          STRING NOT NULL,
+  priority      STRING NOT NULL,
+  bytes         INT NOT NULL,
+  started_at    TIMESTAMP NOT NULL,
+  duration      INTERVAL NOT NULL,
+  in_flight     BOOL NOT NULL,
+  error         STRING
+)
+	`,
+	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.kv_store_snapshots"); err != nil {
+			return err
+		}
+		ss, err := p.ExecCfg().NodesStatusServer.OptionalNodesStatusServer(
+			errorutil.FeatureNotAvailableToNonSystemTenantsIssue)
+		if err != nil {
+			return err
+		}
+		response, err := ss.StoreSnapshots(ctx, &serverpb.StoreSnapshotsRequest{})
+		if err != nil {
+			return err
+		}
+		for _, rpcErr := range response.Errors {
+			log.Warningf(ctx, "%v", errors.DecodeError(ctx, rpcErr))
+		}
+
+		for _, s := range response.Snapshots {
+			startedAt, err := tree.MakeDTimestamp(s.StartedAt, time.Microsecond)
+			if err != nil {
+				return err
+			}
+			snapErr := tree.DNull
+			if s.Error != "" {
+				snapErr = tree.NewDString(s.Error)
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(s.NodeID)),
+				tree.NewDInt(tree.DInt(s.StoreID)),
+				tree.NewDInt(tree.DInt(s.RangeID)),
+				tree.NewDUuid(tree.DUuid{UUID: s.SnapshotID}),
+				tree.NewDString(s.Direction),
+				tree.NewDInt(tree.DInt(s.PeerStoreID)),
+				tree.NewDString(s.Type),
+				tree.NewDString(s.Priority),
+				tree.NewDInt(tree.DInt(s.Bytes)),
+				startedAt,
+				tree.NewDInterval(
+					duration.MakeDuration(s.Duration.Nanoseconds(), 0 /* days */, 0 /* months */),
+					types.DefaultIntervalTypeMetadata,
+				),
+				tree.MakeDBool(tree.DBool(s.InFlight)),
+				snapErr,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalPredefinedComments exposes the predefined
 // comments for virtual tables. This is used by SHOW TABLES WITH COMMENT
 // as fall-back when system.comments is silent.
@@ -21,9 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/docs"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
-	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
-	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -48,7 +46,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treebin"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
@@ -56,12 +53,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/storageparam"
 	"github.com/cockroachdb/cockroach/pkg/sql/storageparam/indexstorageparam"
 	"github.com/cockroachdb/cockroach/pkg/sql/storageparam/tablestorageparam"
+	"github.com/cockroachdb/cockroach/pkg/sql/ttl/ttlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/errors"
-	pbtypes "github.com/gogo/protobuf/types"
 	"github.com/lib/pq/oid"
 )
 
@@ -1489,7 +1486,7 @@ func NewTableDesc(
 			}
 		}
 		if !hasRowLevelTTLColumn {
-			col, err := rowLevelTTLAutomaticColumnDef(ttl)
+			col, err := ttlbase.AutomaticColumnDef(ttl)
 			if err != nil {
 				return nil, err
 			}
@@ -2369,40 +2366,6 @@ func newTableDesc(
 	return ret, nil
 }
 
-// newRowLevelTTLScheduledJob returns a *jobs.ScheduledJob for row level TTL
-// for a given table.
-func newRowLevelTTLScheduledJob(
-	env scheduledjobs.JobSchedulerEnv,
-	owner username.SQLUsername,
-	tblID descpb.ID,
-	ttl *catpb.RowLevelTTL,
-) (*jobs.ScheduledJob, error) {
-	sj := jobs.NewScheduledJob(env)
-	sj.SetScheduleLabel(fmt.Sprintf("row-level-ttl-%d", tblID))
-	sj.SetOwner(owner)
-	sj.SetScheduleDetails(jobspb.ScheduleDetails{
-		Wait: jobspb.ScheduleDetails_WAIT,
-		// If a job fails, try again at the allocated cron time.
-		OnError: jobspb.ScheduleDetails_RETRY_SCHED,
-	})
-
-	if err := sj.SetSchedule(ttl.DeletionCronOrDefault()); err != nil {
-		return nil, err
-	}
-	args := &catpb.ScheduledRowLevelTTLArgs{
-		TableID: tblID,
-	}
-	any, err := pbtypes.MarshalAny(args)
-	if err != nil {
-		return nil, err
-	}
-	sj.SetExecutionDetails(
-		tree.ScheduledRowLevelTTLExecutor.InternalName(),
-		jobspb.ExecutionArguments{Args: any},
-	)
-	return sj, nil
-}
-
 // CreateRowLevelTTLScheduledJob creates a new row-level TTL schedule.
 func CreateRowLevelTTLScheduledJob(
 	ctx context.Context,
@@ -2414,7 +2377,7 @@ func CreateRowLevelTTLScheduledJob(
 ) (*jobs.ScheduledJob, error) {
 	telemetry.Inc(sqltelemetry.RowLevelTTLCreated)
 	env := JobSchedulerEnv(execCfg)
-	j, err := newRowLevelTTLScheduledJob(env, owner, tblID, ttl)
+	j, err := ttlbase.NewScheduledJob(env, owner, tblID, ttl)
 	if err != nil {
 		return nil, err
 	}
@@ -2424,29 +2387,6 @@ func CreateRowLevelTTLScheduledJob(
 	return j, nil
 }
 
-func rowLevelTTLAutomaticColumnDef(ttl *catpb.RowLevelTTL) (*tree.ColumnTableDef, error) {
-	def := &tree.ColumnTableDef{
-		Name:   colinfo.TTLDefaultExpirationColumnName,
-		Type:   types.TimestampTZ,
-		Hidden: true,
-	}
-	intervalExpr, err := parser.ParseExpr(string(ttl.DurationExpr))
-	if err != nil {
-		return nil, errors.Wrapf(err, "unexpected expression for TTL duration")
-	}
-	def.DefaultExpr.Expr = rowLevelTTLAutomaticColumnExpr(intervalExpr)
-	def.OnUpdateExpr.Expr = rowLevelTTLAutomaticColumnExpr(intervalExpr)
-	return def, nil
-}
-
-func rowLevelTTLAutomaticColumnExpr(intervalExpr tree.Expr) tree.Expr {
-	return &tree.BinaryExpr{
-		Operator: treebin.MakeBinaryOperator(treebin.Plus),
-		Left:     &tree.FuncExpr{Func: tree.WrapFunction("current_timestamp")},
-		Right:    intervalExpr,
-	}
-}
-
 // replaceLikeTableOps processes the TableDefs in the input CreateTableNode,
 // searching for LikeTableDefs. If any are found, each LikeTableDef will be
 // replaced in the output tree.TableDefs (which will be a copy of the input
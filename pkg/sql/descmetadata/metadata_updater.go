@@ -18,9 +18,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -30,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessioninit"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/ttl/ttlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 )
 
@@ -215,6 +218,20 @@ func (mu metadataUpdater) DeleteSchedule(ctx context.Context, scheduleID int64)
 	return err
 }
 
+// CreateRowLevelTTLSchedule implements scexec.DescriptorMetadataUpdater.
+func (mu metadataUpdater) CreateRowLevelTTLSchedule(
+	ctx context.Context, owner username.SQLUsername, tableID descpb.ID, ttl *catpb.RowLevelTTL,
+) (int64, error) {
+	j, err := ttlbase.NewScheduledJob(scheduledjobs.ProdJobSchedulerEnv, owner, tableID, ttl)
+	if err != nil {
+		return 0, err
+	}
+	if err := j.Create(ctx, mu.ieFactory.NewInternalExecutor(mu.sessionData), mu.txn); err != nil {
+		return 0, err
+	}
+	return j.ScheduleID(), nil
+}
+
 // DeleteZoneConfig implements scexec.DescriptorMetadataUpdater.
 func (mu metadataUpdater) DeleteZoneConfig(
 	ctx context.Context, id descpb.ID,
@@ -1098,6 +1098,7 @@ func (e *distSQLSpecExecFactory) ConstructAlterRangeRelocate(
 	relocateSubject tree.RelocateSubject,
 	toStoreID tree.TypedExpr,
 	fromStoreID tree.TypedExpr,
+	priority tree.UserPriority,
 ) (exec.Node, error) {
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: alter range relocate")
 }
@@ -175,29 +175,46 @@ type redactionOptions struct {
 	omitSQLNameRedaction bool
 }
 
-func (ro *redactionOptions) toFlags() tree.FmtFlags {
+func (ro *redactionOptions) toFlags(stmtRedaction statementRedactionLevel) tree.FmtFlags {
+	flags := tree.FmtSimple
 	if ro.omitSQLNameRedaction {
-		return tree.FmtOmitNameRedaction
+		flags |= tree.FmtOmitNameRedaction
 	}
-	return tree.FmtSimple
+	if stmtRedaction == statementRedactionConstantsReplaced {
+		flags |= tree.FmtHideConstants
+	}
+	return flags
 }
 
 var defaultRedactionOptions = redactionOptions{
 	omitSQLNameRedaction: false,
 }
 
+// getCommonSQLEventDetails populates the details shared by the SQL events
+// about the current statement. The text of the statement, and the
+// placeholder values, are redacted according to the
+// sql.log.statement_redaction_level cluster setting.
 func (p *planner) getCommonSQLEventDetails(opt redactionOptions) eventpb.CommonSQLEventDetails {
-	redactableStmt := formatStmtKeyAsRedactableString(
-		p.extendedEvalCtx.VirtualSchemas, p.stmt.AST,
-		p.extendedEvalCtx.Context.Annotations, opt.toFlags(),
-	)
+	stmtRedaction := getStatementRedactionLevel(&p.execCfg.Settings.SV)
+	var redactableStmt redact.RedactableString
+	if stmtRedaction == statementRedactionRedacted {
+		redactableStmt = redact.RedactableString(redact.RedactedMarker())
+	} else {
+		redactableStmt = formatStmtKeyAsRedactableString(
+			p.extendedEvalCtx.VirtualSchemas, p.stmt.AST,
+			p.extendedEvalCtx.Context.Annotations, opt.toFlags(stmtRedaction),
+		)
+	}
 	commonSQLEventDetails := eventpb.CommonSQLEventDetails{
 		Statement:       redactableStmt,
 		Tag:             p.stmt.AST.StatementTag(),
 		User:            p.User().Normalized(),
 		ApplicationName: p.SessionData().ApplicationName,
 	}
-	if pls := p.extendedEvalCtx.Context.Placeholders.Values; len(pls) > 0 {
+	// The placeholder values are constants: they are omitted unless the
+	// statement is logged in full.
+	if pls := p.extendedEvalCtx.Context.Placeholders.Values; len(pls) > 0 &&
+		stmtRedaction == statementRedactionFull {
 		commonSQLEventDetails.PlaceholderValues = make([]string, len(pls))
 		for idx, val := range pls {
 			commonSQLEventDetails.PlaceholderValues[idx] = val.String()
@@ -206,6 +223,24 @@ func (p *planner) getCommonSQLEventDetails(opt redactionOptions) eventpb.CommonS
 	return commonSQLEventDetails
 }
 
+// redactCommonSQLEventDetails redacts the text of the statement, and the
+// placeholder values, in SQL event details that were populated from the
+// formatted statement rather than its syntax tree, according to the given
+// level. The constants of the statement cannot be told apart without the
+// syntax tree, so all its sensitive data is redacted instead.
+func redactCommonSQLEventDetails(
+	details *eventpb.CommonSQLEventDetails, stmtRedaction statementRedactionLevel,
+) {
+	switch stmtRedaction {
+	case statementRedactionConstantsReplaced:
+		details.Statement = details.Statement.Redact()
+		details.PlaceholderValues = nil
+	case statementRedactionRedacted:
+		details.Statement = redact.RedactableString(redact.RedactedMarker())
+		details.PlaceholderValues = nil
+	}
+}
+
 // logEventsWithOptions is like logEvent() but it gives control to the
 // caller as to where the event is written to.
 //
@@ -357,6 +392,7 @@ func NewSchemaChangerEventLogger(
 func (l schemaChangerEventLogger) LogEvent(
 	ctx context.Context, details eventpb.CommonSQLEventDetails, event logpb.EventPayload,
 ) error {
+	redactCommonSQLEventDetails(&details, getStatementRedactionLevel(&l.execCfg.Settings.SV))
 	return logEventInternalForSQLStatements(ctx,
 		l.execCfg,
 		l.txn,
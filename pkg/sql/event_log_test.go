@@ -12,14 +12,18 @@ package sql_test
 
 import (
 	"context"
+	gosql "database/sql"
 	"encoding/json"
+	"fmt"
 	"math"
+	"net/url"
 	"reflect"
 	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -636,6 +640,26 @@ func TestPerfLogging(t *testing.T) {
 			logExpected: false,
 			channel:     channel.SQL_INTERNAL_PERF,
 		},
+		{
+			// The statement and the error text are redacted according to the
+			// statement redaction level.
+			setup:       `SET CLUSTER SETTING sql.log.statement_redaction_level = 'constants_replaced'`,
+			cleanup:     `SET CLUSTER SETTING sql.log.statement_redaction_level = DEFAULT`,
+			query:       `INSERT INTO t VALUES (1, pg_sleep(0.256), 'x')`,
+			errRe:       `duplicate key`,
+			logRe:       `"EventType":"slow_query","Statement":"INSERT INTO .*‹t› VALUES \(_, pg_sleep\(_\), _\)","Tag":"INSERT","User":"root".*"ErrorText":"(?:[^"‹\\]|\\.|‹×›)*"`,
+			logExpected: true,
+			channel:     channel.SQL_PERF,
+		},
+		{
+			setup:       `SET CLUSTER SETTING sql.log.statement_redaction_level = 'redacted'`,
+			cleanup:     `SET CLUSTER SETTING sql.log.statement_redaction_level = DEFAULT`,
+			query:       `SELECT * FROM t WHERE i IN (6, 7, 8)`,
+			errRe:       ``,
+			logRe:       `"EventType":"txn_rows_read_limit","Statement":"‹×›","Tag":"SELECT","User":"root","TxnID":.*,"SessionID":.*,"NumRows":3`,
+			logExpected: true,
+			channel:     channel.SQL_PERF,
+		},
 		{
 			// Disable the relevant cluster settings and reset the session
 			// variables to the values of the cluster settings just set.
@@ -754,3 +778,123 @@ func TestPerfLogging(t *testing.T) {
 		}
 	}
 }
+
+// Test the audit policies of tables, and the events emitted on the
+// SENSITIVE_ACCESS logging channel.
+func TestSensitiveTableAccessLogging(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sc := log.ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+	log.TestingResetActive()
+	cfg := logconfig.DefaultConfig()
+	auditable := true
+	cfg.Sinks.FileGroups = map[string]*logconfig.FileSinkConfig{
+		"sql-audit": {
+			FileDefaults: logconfig.FileDefaults{
+				CommonSinkConfig: logconfig.CommonSinkConfig{Auditable: &auditable},
+			},
+			Channels: logconfig.SelectChannels(channel.SENSITIVE_ACCESS),
+		},
+	}
+	dir := sc.GetDirectory()
+	require.NoError(t, cfg.Validate(&dir))
+	cleanup, err := log.ApplyConfig(cfg)
+	require.NoError(t, err)
+	defer cleanup()
+
+	ctx := context.Background()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	db := sqlutils.MakeSQLRunner(sqlDB)
+
+	db.Exec(t, `CREATE TABLE audit_r (x INT)`)
+	db.Exec(t, `CREATE TABLE audit_w (x INT)`)
+	db.Exec(t, `CREATE TABLE audit_rw (x INT)`)
+	db.Exec(t, `ALTER TABLE audit_r EXPERIMENTAL_AUDIT SET READ`)
+	db.Exec(t, `ALTER TABLE audit_w EXPERIMENTAL_AUDIT SET WRITE`)
+	db.Exec(t, `ALTER TABLE audit_rw EXPERIMENTAL_AUDIT SET READ WRITE`)
+	db.Exec(t, `CREATE ROLE auditors`)
+	db.Exec(t, fmt.Sprintf(`CREATE USER %s`, username.TestUser))
+	db.Exec(t, fmt.Sprintf(`GRANT auditors TO %s`, username.TestUser))
+	db.Exec(t, fmt.Sprintf(`GRANT SELECT ON audit_rw TO %s`, username.TestUser))
+
+	var rID, wID, rwID uint32
+	db.QueryRow(t, `SELECT 'audit_r'::REGCLASS::OID`).Scan(&rID)
+	db.QueryRow(t, `SELECT 'audit_w'::REGCLASS::OID`).Scan(&wID)
+	db.QueryRow(t, `SELECT 'audit_rw'::REGCLASS::OID`).Scan(&rwID)
+
+	// accessEvents runs the given statements and returns the table
+	// access events they emitted, by table ID.
+	accessEvents := func(
+		db *sqlutils.SQLRunner, stmts ...string,
+	) map[uint32]eventpb.SensitiveTableAccess {
+		start := timeutil.Now().UnixNano()
+		for _, stmt := range stmts {
+			db.Exec(t, stmt)
+		}
+		log.Flush()
+		entries, err := log.FetchEntriesFromFiles(start, math.MaxInt64, 1000,
+			regexp.MustCompile(`"EventType":"sensitive_table_access"`), log.WithMarkedSensitiveData)
+		require.NoError(t, err)
+		res := make(map[uint32]eventpb.SensitiveTableAccess)
+		for _, e := range entries {
+			require.Equal(t, channel.SENSITIVE_ACCESS, e.Channel)
+			var ev eventpb.SensitiveTableAccess
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(e.Message, "=")), &ev))
+			res[ev.DescriptorID] = ev
+		}
+		return res
+	}
+
+	// The audit mode of the table selects the type of access which is
+	// logged.
+	evs := accessEvents(db,
+		`SELECT * FROM audit_r`, `INSERT INTO audit_r VALUES (1)`,
+		`SELECT * FROM audit_w`, `INSERT INTO audit_w VALUES (1)`,
+	)
+	require.Len(t, evs, 2)
+	require.Equal(t, "r", evs[rID].AccessMode)
+	require.Equal(t, "READ", evs[rID].AuditMode)
+	require.Equal(t, "rw", evs[wID].AccessMode)
+	require.Equal(t, "WRITE", evs[wID].AuditMode)
+	require.Empty(t, evs[wID].AuditRoles)
+
+	// When roles are selected, only the accesses by their members are
+	// logged.
+	db.Exec(t, `SET CLUSTER SETTING sql.log.audit.roles = 'auditors, other'`)
+	require.Empty(t, accessEvents(db, `SELECT * FROM audit_rw`))
+
+	pgURL, cleanupDB := sqlutils.PGUrl(
+		t, s.ServingSQLAddr(), "TestSensitiveTableAccessLogging", url.User(username.TestUser))
+	defer cleanupDB()
+	userDB, err := gosql.Open("postgres", pgURL.String())
+	require.NoError(t, err)
+	defer userDB.Close()
+	evs = accessEvents(sqlutils.MakeSQLRunner(userDB), `SELECT * FROM audit_rw`)
+	require.Len(t, evs, 1)
+	require.Equal(t, "READWRITE", evs[rwID].AuditMode)
+	require.Equal(t, []string{string(redact.Sprint("auditors"))}, evs[rwID].AuditRoles)
+
+	// The audit policies of a role select the accesses by its members,
+	// even when the table has no audit mode of its own.
+	db.Exec(t, `CREATE TABLE audit_policy (x INT)`)
+	db.Exec(t, `ALTER TABLE audit_policy EXPERIMENTAL_AUDIT SET WRITE FOR ROLE auditors`)
+	db.Exec(t, fmt.Sprintf(`GRANT SELECT, INSERT ON audit_policy TO %s`, username.TestUser))
+	var policyID uint32
+	db.QueryRow(t, `SELECT 'audit_policy'::REGCLASS::OID`).Scan(&policyID)
+	require.Empty(t, accessEvents(db, `INSERT INTO audit_policy VALUES (1)`))
+	evs = accessEvents(sqlutils.MakeSQLRunner(userDB),
+		`SELECT * FROM audit_policy`, `INSERT INTO audit_policy VALUES (1)`)
+	require.Len(t, evs, 1)
+	require.Equal(t, "rw", evs[policyID].AccessMode)
+	require.Equal(t, "WRITE", evs[policyID].AuditMode)
+	require.Equal(t, []string{string(redact.Sprint("auditors"))}, evs[policyID].AuditRoles)
+
+	// The statement is redacted according to the statement redaction level.
+	db.Exec(t, `SET CLUSTER SETTING sql.log.statement_redaction_level = 'redacted'`)
+	defer db.Exec(t, `SET CLUSTER SETTING sql.log.statement_redaction_level = DEFAULT`)
+	evs = accessEvents(sqlutils.MakeSQLRunner(userDB), `SELECT * FROM audit_rw WHERE x = 1`)
+	require.Len(t, evs, 1)
+	require.Equal(t, redact.RedactableString(redact.RedactedMarker()), evs[rwID].Statement)
+}
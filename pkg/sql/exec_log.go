@@ -14,9 +14,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
@@ -117,6 +119,15 @@ var adminAuditLogEnabled = settings.RegisterBoolSetting(
 	false,
 )
 
+var auditRoles = settings.RegisterStringSetting(
+	settings.TenantWritable,
+	"sql.log.audit.roles",
+	"comma-separated list of roles whose members are subject to the audit "+
+		"policies of tables (ALTER TABLE ... EXPERIMENTAL_AUDIT); when empty, "+
+		"accesses to audited tables are logged for all users",
+	"",
+).WithPublic()
+
 var telemetryLoggingEnabled = settings.RegisterBoolSetting(
 	settings.TenantWritable,
 	"sql.telemetry.query_sampling.enabled",
@@ -130,6 +141,45 @@ var telemetryLoggingEnabled = settings.RegisterBoolSetting(
 	envutil.EnvOrDefaultBool("COCKROACH_SQL_TELEMETRY_QUERY_SAMPLING_ENABLED", false),
 ).WithPublic()
 
+// statementRedactionLevel describes how the text of SQL statements
+// appears in the structured events emitted to the SQL_EXEC and TELEMETRY
+// logging channels.
+type statementRedactionLevel int64
+
+const (
+	// statementRedactionFull includes the full text of the statements, with
+	// the sensitive data enclosed within redaction markers.
+	statementRedactionFull statementRedactionLevel = iota
+	// statementRedactionConstantsReplaced replaces the constants in the
+	// statements by placeholders, and omits the placeholder values.
+	statementRedactionConstantsReplaced
+	// statementRedactionRedacted omits the text of the statements entirely.
+	statementRedactionRedacted
+)
+
+var statementRedactionLevelSetting = settings.RegisterEnumSetting(
+	settings.TenantWritable,
+	"sql.log.statement_redaction_level",
+	"how SQL statements appear in the structured SQL events, including the "+
+		"SQL_EXEC, SQL_PERF, SENSITIVE_ACCESS and TELEMETRY logs: "+
+		"full (the sensitive data is enclosed within redaction markers), "+
+		"constants_replaced (constants, placeholder values and the sensitive data "+
+		"of error messages are removed) or redacted (the statement text is omitted "+
+		"entirely, and the sensitive data of error messages is removed)",
+	"full",
+	map[int64]string{
+		int64(statementRedactionFull):              "full",
+		int64(statementRedactionConstantsReplaced): "constants_replaced",
+		int64(statementRedactionRedacted):          "redacted",
+	},
+).WithPublic()
+
+// getStatementRedactionLevel returns the redaction level of the SQL
+// statements in the structured SQL events.
+func getStatementRedactionLevel(sv *settings.Values) statementRedactionLevel {
+	return statementRedactionLevel(statementRedactionLevelSetting.Get(sv))
+}
+
 type executorType int
 
 const (
@@ -191,6 +241,7 @@ func (p *planner) maybeLogStatementInternal(
 	slowInternalQueryLogEnabled := slowInternalQueryLogEnabled.Get(&p.execCfg.Settings.SV)
 	auditEventsDetected := len(p.curPlan.auditEvents) != 0
 	maxEventFrequency := telemetryMaxEventFrequency.Get(&p.execCfg.Settings.SV)
+	stmtRedaction := getStatementRedactionLevel(&p.execCfg.Settings.SV)
 
 	// We only consider non-internal SQL statements for telemetry logging.
 	telemetryLoggingEnabled := telemetryLoggingEnabled.Get(&p.execCfg.Settings.SV) && execType != executorTypeInternal
@@ -221,6 +272,10 @@ func (p *planner) maybeLogStatementInternal(
 	var execErrStr redact.RedactableString
 	if err != nil {
 		execErrStr = redact.Sprint(err)
+		// The error message may include the constants of the statement.
+		if stmtRedaction != statementRedactionFull {
+			execErrStr = execErrStr.Redact()
+		}
 	}
 	// The type of execution context (execute/prepare).
 	lbl := execType.logLabel()
@@ -341,6 +396,8 @@ func (p *planner) maybeLogStatementInternal(
 				CommonSQLExecDetails: execDetails,
 				TableName:            tableName,
 				AccessMode:           mode,
+				AuditMode:            ev.mode.String(),
+				AuditRoles:           ev.roles,
 			}
 		}
 		p.logEventsOnlyExternally(ctx, entries...)
@@ -471,7 +528,11 @@ func (p *planner) logOperationalEventsOnlyExternally(
 }
 
 // maybeAudit marks the current plan being constructed as flagged
-// for auditing if the table being touched has an auditing mode set.
+// for auditing if the table being touched has an auditing mode set
+// which selects the type of access (read or write), and if the user
+// is subject to auditing as per the sql.log.audit.roles setting, or
+// if the user is a member of a role whose audit policy on the table
+// selects the type of access.
 // This is later picked up by maybeLogStatement() above.
 //
 // It is crucial that this gets checked reliably -- we don't want to
@@ -481,17 +542,118 @@ func (p *planner) logOperationalEventsOnlyExternally(
 // call to this method elsewhere must find a way to ensure that
 // contributors who later add features do not have to remember to call
 // this to get it right.
-func (p *planner) maybeAudit(privilegeObject catalog.PrivilegeObject, priv privilege.Kind) {
+func (p *planner) maybeAudit(
+	ctx context.Context,
+	privilegeObject catalog.PrivilegeObject,
+	priv privilege.Kind,
+	user username.SQLUsername,
+) {
 	tableDesc, ok := privilegeObject.(catalog.TableDescriptor)
-	if !ok || tableDesc.GetAuditMode() == descpb.TableDescriptor_DISABLED {
+	if !ok {
 		return
 	}
+	writing := false
 	switch priv {
 	case privilege.INSERT, privilege.DELETE, privilege.UPDATE:
-		p.curPlan.auditEvents = append(p.curPlan.auditEvents, auditEvent{desc: tableDesc, writing: true})
+		writing = true
+	}
+	var roles []string
+	shouldAudit := false
+	mode := tableDesc.GetAuditMode()
+	if auditModeSelects(mode, writing) {
+		roles, shouldAudit = p.auditedRoles(ctx, user)
+	}
+	if !shouldAudit {
+		roles, mode = p.auditedPolicyRoles(ctx, user, tableDesc.GetAuditPolicies(), writing)
+		shouldAudit = len(roles) > 0
+	}
+	if !shouldAudit {
+		return
+	}
+	p.curPlan.auditEvents = append(p.curPlan.auditEvents,
+		auditEvent{desc: tableDesc, writing: writing, mode: mode, roles: roles})
+}
+
+// auditModeSelects returns whether the given audit mode selects the
+// reads, or the writes if writing is true.
+func auditModeSelects(mode descpb.TableDescriptor_AuditMode, writing bool) bool {
+	switch mode {
+	case descpb.TableDescriptor_READWRITE:
+		return true
+	case descpb.TableDescriptor_READ:
+		return !writing
+	case descpb.TableDescriptor_WRITE:
+		return writing
 	default:
-		p.curPlan.auditEvents = append(p.curPlan.auditEvents, auditEvent{desc: tableDesc, writing: false})
+		return false
+	}
+}
+
+// auditedRoles returns whether the accesses to audited tables by the
+// given user must be logged, as per the sql.log.audit.roles setting,
+// along with the roles selected by the setting that the user is a
+// member of.
+func (p *planner) auditedRoles(
+	ctx context.Context, user username.SQLUsername,
+) (roles []string, shouldAudit bool) {
+	roleList := strings.TrimSpace(auditRoles.Get(&p.execCfg.Settings.SV))
+	if roleList == "" {
+		return nil, true
+	}
+	memberOf, err := p.MemberOfWithAdminOption(ctx, user)
+	if err != nil {
+		// We don't want to miss any statement: if the role memberships
+		// cannot be determined, err on the side of auditing.
+		log.Warningf(ctx, "unable to determine the roles of %s for auditing: %v", user, err)
+		return nil, true
+	}
+	for _, r := range strings.Split(roleList, ",") {
+		role, err := username.MakeSQLUsernameFromUserInput(strings.TrimSpace(r), username.PurposeValidation)
+		if err != nil || role.Undefined() {
+			continue
+		}
+		if _, isMember := memberOf[role]; isMember || role == user {
+			roles = append(roles, role.Normalized())
+		}
+	}
+	return roles, len(roles) > 0
+}
+
+// auditedPolicyRoles returns the roles that the given user is a member
+// of whose audit policies select the type of access, along with the
+// audit mode of the first such policy.
+func (p *planner) auditedPolicyRoles(
+	ctx context.Context,
+	user username.SQLUsername,
+	policies []descpb.TableDescriptor_AuditPolicy,
+	writing bool,
+) (roles []string, mode descpb.TableDescriptor_AuditMode) {
+	var memberOf map[username.SQLUsername]bool
+	var memberOfErr error
+	for _, policy := range policies {
+		if !auditModeSelects(policy.Mode, writing) {
+			continue
+		}
+		role := username.MakeSQLUsernameFromPreNormalizedString(policy.Role)
+		if role != user {
+			if memberOf == nil && memberOfErr == nil {
+				memberOf, memberOfErr = p.MemberOfWithAdminOption(ctx, user)
+				if memberOfErr != nil {
+					log.Warningf(ctx, "unable to determine the roles of %s for auditing: %v", user, memberOfErr)
+				}
+			}
+			// We don't want to miss any statement: if the role memberships
+			// cannot be determined, err on the side of auditing.
+			if _, isMember := memberOf[role]; !isMember && memberOfErr == nil {
+				continue
+			}
+		}
+		if len(roles) == 0 {
+			mode = policy.Mode
+		}
+		roles = append(roles, policy.Role)
 	}
+	return roles, mode
 }
 
 func (p *planner) slowQueryLogReason(
@@ -521,4 +683,9 @@ type auditEvent struct {
 	desc catalog.TableDescriptor
 	// Whether the event was for INSERT/DELETE/UPDATE.
 	writing bool
+	// The audit mode which selected the access.
+	mode descpb.TableDescriptor_AuditMode
+	// The roles selected by sql.log.audit.roles, or by the audit policies
+	// of the table, that the user is a member of, if any.
+	roles []string
 }
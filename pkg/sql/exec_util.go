@@ -1278,6 +1278,10 @@ type ExecutorConfig struct {
 	// compaction concurrency.
 	CompactionConcurrencyFunc eval.SetCompactionConcurrencyFunc
 
+	// CleanupStoreScratchFunc is used to remove the orphaned files of the
+	// auxiliary directories of the stores of a node.
+	CleanupStoreScratchFunc eval.CleanupStoreScratchFunc
+
 	// TraceCollector is used to contact all live nodes in the cluster, and
 	// collect trace spans from their inflight node registries.
 	TraceCollector *collector.TraceCollector
@@ -1317,7 +1321,8 @@ type ExecutorConfig struct {
 	InternalExecutorFactory sqlutil.InternalExecutorFactory
 
 	// ConsistencyChecker is to generate the results in calls to
-	// crdb_internal.check_consistency.
+	// crdb_internal.check_consistency and to start the jobs created by
+	// crdb_internal.start_consistency_check_job.
 	ConsistencyChecker eval.ConsistencyCheckRunner
 
 	// RangeProber is used in calls to crdb_internal.probe_ranges.
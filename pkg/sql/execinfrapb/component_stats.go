@@ -145,6 +145,14 @@ func (s *ComponentStats) formatStats(fn func(suffix string, value interface{}))
 	if s.KV.ContentionTime.HasValue() {
 		fn("KV contention time", humanizeutil.Duration(s.KV.ContentionTime.Value()))
 	}
+	// The wait times are only shown when non-zero, since most KV requests are
+	// neither queued nor throttled.
+	if s.KV.AdmissionWaitTime.HasValue() && s.KV.AdmissionWaitTime.Value() != 0 {
+		fn("KV admission wait time", humanizeutil.Duration(s.KV.AdmissionWaitTime.Value()))
+	}
+	if s.KV.IngestWaitTime.HasValue() && s.KV.IngestWaitTime.Value() != 0 {
+		fn("KV ingest wait time", humanizeutil.Duration(s.KV.IngestWaitTime.Value()))
+	}
 	if s.KV.TuplesRead.HasValue() {
 		fn("KV rows read", humanizeutil.Count(s.KV.TuplesRead.Value()))
 	}
@@ -168,6 +176,14 @@ func (s *ComponentStats) formatStats(fn func(suffix string, value interface{}))
 				humanizeutil.Count(s.KV.NumInternalSeeks.Value())),
 		)
 	}
+	// The block bytes are only shown when some storage blocks were loaded.
+	if s.KV.BlockBytes.HasValue() && s.KV.BlockBytes.Value() != 0 {
+		fn("storage block bytes (total/cached)",
+			fmt.Sprintf("%s/%s",
+				humanize.IBytes(s.KV.BlockBytes.Value()),
+				humanize.IBytes(s.KV.BlockBytesInCache.Value())),
+		)
+	}
 
 	// Exec stats.
 	if s.Exec.ExecTime.HasValue() {
@@ -246,6 +262,18 @@ func (s *ComponentStats) Union(other *ComponentStats) *ComponentStats {
 	if !result.KV.NumInternalSeeks.HasValue() {
 		result.KV.NumInternalSeeks = other.KV.NumInternalSeeks
 	}
+	if !result.KV.BlockBytes.HasValue() {
+		result.KV.BlockBytes = other.KV.BlockBytes
+	}
+	if !result.KV.BlockBytesInCache.HasValue() {
+		result.KV.BlockBytesInCache = other.KV.BlockBytesInCache
+	}
+	if !result.KV.AdmissionWaitTime.HasValue() {
+		result.KV.AdmissionWaitTime = other.KV.AdmissionWaitTime
+	}
+	if !result.KV.IngestWaitTime.HasValue() {
+		result.KV.IngestWaitTime = other.KV.IngestWaitTime
+	}
 	if !result.KV.TuplesRead.HasValue() {
 		result.KV.TuplesRead = other.KV.TuplesRead
 	}
@@ -342,6 +370,10 @@ func (s *ComponentStats) MakeDeterministic() {
 	resetUint(&s.KV.NumInternalSteps)
 	resetUint(&s.KV.NumInterfaceSeeks)
 	resetUint(&s.KV.NumInternalSeeks)
+	resetUint(&s.KV.BlockBytes)
+	resetUint(&s.KV.BlockBytesInCache)
+	timeVal(&s.KV.AdmissionWaitTime)
+	timeVal(&s.KV.IngestWaitTime)
 	if s.KV.BytesRead.HasValue() {
 		// BytesRead is overridden to a useful value for tests.
 		s.KV.BytesRead.Set(8 * s.KV.TuplesRead.Value())
@@ -55,8 +55,9 @@ func GetCumulativeContentionTime(ctx context.Context, recording tracingpb.Record
 }
 
 // ScanStats contains statistics on the internal MVCC operators used to satisfy
-// a scan. See storage/engine.go for a more thorough discussion of the meaning
-// of each stat.
+// a scan, as well as on the time the KV requests of the scan spent queued
+// before being evaluated. See storage/engine.go for a more thorough discussion
+// of the meaning of each MVCC stat.
 // TODO(sql-observability): include other fields that are in roachpb.ScanStats,
 // here and in execinfrapb.KVStats.
 type ScanStats struct {
@@ -72,6 +73,18 @@ type ScanStats struct {
 	// NumInternalSeeks is the number of times that MVCC seek was invoked
 	// internally, including to step over internal, uncompacted Pebble versions.
 	NumInternalSeeks uint64
+	// BlockBytes is the number of bytes in the storage blocks loaded to satisfy
+	// the scan.
+	BlockBytes uint64
+	// BlockBytesInCache is the subset of BlockBytes that were already in the
+	// block cache.
+	BlockBytesInCache uint64
+	// AdmissionWaitTime is the cumulative time the KV requests spent waiting in
+	// admission control queues.
+	AdmissionWaitTime time.Duration
+	// IngestWaitTime is the cumulative time the KV requests spent throttled
+	// before ingesting SSTs.
+	IngestWaitTime time.Duration
 }
 
 // PopulateKVMVCCStats adds data from the input ScanStats to the input KVStats.
@@ -80,6 +93,10 @@ func PopulateKVMVCCStats(kvStats *execinfrapb.KVStats, ss *ScanStats) {
 	kvStats.NumInternalSteps = optional.MakeUint(ss.NumInternalSteps)
 	kvStats.NumInterfaceSeeks = optional.MakeUint(ss.NumInterfaceSeeks)
 	kvStats.NumInternalSeeks = optional.MakeUint(ss.NumInternalSeeks)
+	kvStats.BlockBytes = optional.MakeUint(ss.BlockBytes)
+	kvStats.BlockBytesInCache = optional.MakeUint(ss.BlockBytesInCache)
+	kvStats.AdmissionWaitTime = optional.MakeTimeValue(ss.AdmissionWaitTime)
+	kvStats.IngestWaitTime = optional.MakeTimeValue(ss.IngestWaitTime)
 }
 
 // GetScanStats is a helper function to calculate scan stats from the given
@@ -90,19 +107,34 @@ func GetScanStats(ctx context.Context, recording tracingpb.Recording) (ss ScanSt
 		recording = tracing.SpanFromContext(ctx).GetConfiguredRecording()
 	}
 	var ev roachpb.ScanStats
+	var admissionEv roachpb.AdmissionWaitEvent
+	var ingestEv roachpb.IngestWaitEvent
 	for i := range recording {
 		recording[i].Structured(func(any *pbtypes.Any, _ time.Time) {
-			if !pbtypes.Is(any, &ev) {
-				return
-			}
-			if err := pbtypes.UnmarshalAny(any, &ev); err != nil {
-				return
-			}
+			switch {
+			case pbtypes.Is(any, &ev):
+				if err := pbtypes.UnmarshalAny(any, &ev); err != nil {
+					return
+				}
+				ss.NumInterfaceSteps += ev.NumInterfaceSteps
+				ss.NumInternalSteps += ev.NumInternalSteps
+				ss.NumInterfaceSeeks += ev.NumInterfaceSeeks
+				ss.NumInternalSeeks += ev.NumInternalSeeks
+				ss.BlockBytes += ev.BlockBytes
+				ss.BlockBytesInCache += ev.BlockBytesInCache
 
-			ss.NumInterfaceSteps += ev.NumInterfaceSteps
-			ss.NumInternalSteps += ev.NumInternalSteps
-			ss.NumInterfaceSeeks += ev.NumInterfaceSeeks
-			ss.NumInternalSeeks += ev.NumInternalSeeks
+			case pbtypes.Is(any, &admissionEv):
+				if err := pbtypes.UnmarshalAny(any, &admissionEv); err != nil {
+					return
+				}
+				ss.AdmissionWaitTime += admissionEv.WaitDuration
+
+			case pbtypes.Is(any, &ingestEv):
+				if err := pbtypes.UnmarshalAny(any, &ingestEv); err != nil {
+					return
+				}
+				ss.IngestWaitTime += ingestEv.WaitDuration
+			}
 		})
 	}
 	return ss
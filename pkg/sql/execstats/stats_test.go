@@ -0,0 +1,74 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package execstats_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execstats"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetScanStats verifies that the scan stats and the KV wait times recorded
+// in a trace are aggregated and propagated to the KVStats.
+func TestGetScanStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tr := tracing.NewTracer()
+	sp := tr.StartSpan("test", tracing.WithRecording(tracingpb.RecordingStructured))
+	defer sp.Finish()
+	for i := 0; i < 2; i++ {
+		sp.RecordStructured(&roachpb.ScanStats{
+			NumInterfaceSteps: 1,
+			NumInternalSteps:  2,
+			NumInterfaceSeeks: 3,
+			NumInternalSeeks:  4,
+			BlockBytes:        100,
+			BlockBytesInCache: 60,
+		})
+		sp.RecordStructured(&roachpb.AdmissionWaitEvent{
+			WorkKind:     "kv",
+			WaitDuration: time.Second,
+		})
+	}
+	sp.RecordStructured(&roachpb.IngestWaitEvent{
+		WaitDuration:       3 * time.Second,
+		EngineWaitDuration: time.Second,
+	})
+
+	ss := execstats.GetScanStats(context.Background(), sp.GetConfiguredRecording())
+	require.Equal(t, execstats.ScanStats{
+		NumInterfaceSteps: 2,
+		NumInternalSteps:  4,
+		NumInterfaceSeeks: 6,
+		NumInternalSeeks:  8,
+		BlockBytes:        200,
+		BlockBytesInCache: 120,
+		AdmissionWaitTime: 2 * time.Second,
+		IngestWaitTime:    3 * time.Second,
+	}, ss)
+
+	var kvStats execinfrapb.KVStats
+	execstats.PopulateKVMVCCStats(&kvStats, &ss)
+	require.Equal(t, uint64(200), kvStats.BlockBytes.Value())
+	require.Equal(t, uint64(120), kvStats.BlockBytesInCache.Value())
+	require.Equal(t, 2*time.Second, kvStats.AdmissionWaitTime.Value())
+	require.Equal(t, 3*time.Second, kvStats.IngestWaitTime.Value())
+}
@@ -107,6 +107,8 @@ type NodeLevelStats struct {
 	KVTimeGroupedByNode                map[base.SQLInstanceID]time.Duration
 	NetworkMessagesGroupedByNode       map[base.SQLInstanceID]int64
 	ContentionTimeGroupedByNode        map[base.SQLInstanceID]time.Duration
+	AdmissionWaitTimeGroupedByNode     map[base.SQLInstanceID]time.Duration
+	IngestWaitTimeGroupedByNode        map[base.SQLInstanceID]time.Duration
 }
 
 // QueryLevelStats returns all the query level stats that correspond to the
@@ -122,6 +124,8 @@ type QueryLevelStats struct {
 	KVTime                time.Duration
 	NetworkMessages       int64
 	ContentionTime        time.Duration
+	AdmissionWaitTime     time.Duration
+	IngestWaitTime        time.Duration
 }
 
 // QueryLevelStatsWithErr is the same as QueryLevelStats, but also tracks
@@ -155,6 +159,8 @@ func (s *QueryLevelStats) Accumulate(other QueryLevelStats) {
 	s.KVTime += other.KVTime
 	s.NetworkMessages += other.NetworkMessages
 	s.ContentionTime += other.ContentionTime
+	s.AdmissionWaitTime += other.AdmissionWaitTime
+	s.IngestWaitTime += other.IngestWaitTime
 }
 
 // TraceAnalyzer is a struct that helps calculate top-level statistics from a
@@ -230,6 +236,8 @@ func (a *TraceAnalyzer) ProcessStats() error {
 		KVTimeGroupedByNode:                make(map[base.SQLInstanceID]time.Duration),
 		NetworkMessagesGroupedByNode:       make(map[base.SQLInstanceID]int64),
 		ContentionTimeGroupedByNode:        make(map[base.SQLInstanceID]time.Duration),
+		AdmissionWaitTimeGroupedByNode:     make(map[base.SQLInstanceID]time.Duration),
+		IngestWaitTimeGroupedByNode:        make(map[base.SQLInstanceID]time.Duration),
 	}
 	var errs error
 
@@ -244,6 +252,8 @@ func (a *TraceAnalyzer) ProcessStats() error {
 		a.nodeLevelStats.KVBatchRequestsIssuedGroupedByNode[instanceID] += int64(stats.KV.BatchRequestsIssued.Value())
 		a.nodeLevelStats.KVTimeGroupedByNode[instanceID] += stats.KV.KVTime.Value()
 		a.nodeLevelStats.ContentionTimeGroupedByNode[instanceID] += stats.KV.ContentionTime.Value()
+		a.nodeLevelStats.AdmissionWaitTimeGroupedByNode[instanceID] += stats.KV.AdmissionWaitTime.Value()
+		a.nodeLevelStats.IngestWaitTimeGroupedByNode[instanceID] += stats.KV.IngestWaitTime.Value()
 	}
 
 	// Process streamStats.
@@ -353,6 +363,14 @@ func (a *TraceAnalyzer) ProcessStats() error {
 	for _, contentionTime := range a.nodeLevelStats.ContentionTimeGroupedByNode {
 		a.queryLevelStats.ContentionTime += contentionTime
 	}
+
+	for _, admissionWaitTime := range a.nodeLevelStats.AdmissionWaitTimeGroupedByNode {
+		a.queryLevelStats.AdmissionWaitTime += admissionWaitTime
+	}
+
+	for _, ingestWaitTime := range a.nodeLevelStats.IngestWaitTimeGroupedByNode {
+		a.queryLevelStats.IngestWaitTime += ingestWaitTime
+	}
 	return errs
 }
 
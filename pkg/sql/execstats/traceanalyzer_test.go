@@ -190,12 +190,15 @@ func TestTraceAnalyzer(t *testing.T) {
 
 func TestTraceAnalyzerProcessStats(t *testing.T) {
 	const (
-		node1KVTime              = 1 * time.Second
-		node1ContentionTime      = 2 * time.Second
-		node2KVTime              = 3 * time.Second
-		node2ContentionTime      = 4 * time.Second
-		cumulativeKVTime         = node1KVTime + node2KVTime
-		cumulativeContentionTime = node1ContentionTime + node2ContentionTime
+		node1KVTime                 = 1 * time.Second
+		node1ContentionTime         = 2 * time.Second
+		node2KVTime                 = 3 * time.Second
+		node2ContentionTime         = 4 * time.Second
+		cumulativeKVTime            = node1KVTime + node2KVTime
+		cumulativeContentionTime    = node1ContentionTime + node2ContentionTime
+		node1AdmissionWaitTime      = 5 * time.Second
+		node2AdmissionWaitTime      = 6 * time.Second
+		cumulativeAdmissionWaitTime = node1AdmissionWaitTime + node2AdmissionWaitTime
 	)
 	a := &execstats.TraceAnalyzer{FlowsMetadata: &execstats.FlowsMetadata{}}
 	n1 := base.SQLInstanceID(1)
@@ -208,8 +211,9 @@ func TestTraceAnalyzerProcessStats(t *testing.T) {
 				1, /* processorID */
 			),
 			KV: execinfrapb.KVStats{
-				KVTime:         optional.MakeTimeValue(node1KVTime),
-				ContentionTime: optional.MakeTimeValue(node1ContentionTime),
+				KVTime:            optional.MakeTimeValue(node1KVTime),
+				ContentionTime:    optional.MakeTimeValue(node1ContentionTime),
+				AdmissionWaitTime: optional.MakeTimeValue(node1AdmissionWaitTime),
 			},
 		},
 	)
@@ -222,15 +226,17 @@ func TestTraceAnalyzerProcessStats(t *testing.T) {
 				2, /* processorID */
 			),
 			KV: execinfrapb.KVStats{
-				KVTime:         optional.MakeTimeValue(node2KVTime),
-				ContentionTime: optional.MakeTimeValue(node2ContentionTime),
+				KVTime:            optional.MakeTimeValue(node2KVTime),
+				ContentionTime:    optional.MakeTimeValue(node2ContentionTime),
+				AdmissionWaitTime: optional.MakeTimeValue(node2AdmissionWaitTime),
 			},
 		},
 	)
 
 	expected := execstats.QueryLevelStats{
-		KVTime:         cumulativeKVTime,
-		ContentionTime: cumulativeContentionTime,
+		KVTime:            cumulativeKVTime,
+		ContentionTime:    cumulativeContentionTime,
+		AdmissionWaitTime: cumulativeAdmissionWaitTime,
 	}
 
 	assert.NoError(t, a.ProcessStats())
@@ -250,6 +256,8 @@ func TestQueryLevelStatsAccumulate(t *testing.T) {
 		NetworkMessages:       6,
 		ContentionTime:        7 * time.Second,
 		MaxDiskUsage:          8,
+		AdmissionWaitTime:     9 * time.Second,
+		IngestWaitTime:        10 * time.Second,
 	}
 	b := execstats.QueryLevelStats{
 		NetworkBytesSent:      8,
@@ -261,6 +269,8 @@ func TestQueryLevelStatsAccumulate(t *testing.T) {
 		NetworkMessages:       13,
 		ContentionTime:        14 * time.Second,
 		MaxDiskUsage:          15,
+		AdmissionWaitTime:     16 * time.Second,
+		IngestWaitTime:        17 * time.Second,
 	}
 	expected := execstats.QueryLevelStats{
 		NetworkBytesSent:      9,
@@ -272,6 +282,8 @@ func TestQueryLevelStatsAccumulate(t *testing.T) {
 		NetworkMessages:       19,
 		ContentionTime:        21 * time.Second,
 		MaxDiskUsage:          15,
+		AdmissionWaitTime:     25 * time.Second,
+		IngestWaitTime:        27 * time.Second,
 	}
 
 	aCopy := a
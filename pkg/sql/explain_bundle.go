@@ -19,6 +19,8 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
@@ -27,14 +29,49 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/cockroach/pkg/util/memzipper"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
 	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
 )
 
+// bundleLogsEnabled controls whether statement diagnostics bundles include the
+// log entries emitted during the execution of the statement.
+var bundleLogsEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.stmt_diagnostics.bundle_logs.enabled",
+	"if set, statement diagnostics bundles include the redacted log entries emitted "+
+		"during the statement's execution on the gateway and on the nodes which served "+
+		"its KV requests",
+	false,
+)
+
+// maxBundleLogEntries is the maximum number of log entries fetched from each
+// node when including logs in a statement diagnostics bundle.
+const maxBundleLogEntries = 1000
+
+// bundleLogChannels are the logging channels whose entries are included in
+// statement diagnostics bundles. The channels for audit and administrative
+// events are left out since they are unrelated to the execution of a
+// statement.
+var bundleLogChannels = map[logpb.Channel]bool{
+	channel.DEV:               true,
+	channel.OPS:               true,
+	channel.HEALTH:            true,
+	channel.STORAGE:           true,
+	channel.SQL_EXEC:          true,
+	channel.SQL_PERF:          true,
+	channel.SQL_INTERNAL_PERF: true,
+	channel.KV_DISTRIBUTION:   true,
+}
+
 // setExplainBundleResult sets the result of an EXPLAIN ANALYZE (DEBUG)
 // statement. warnings will be printed out as is in the CLI.
 //
@@ -119,6 +156,12 @@ type diagnosticsBundle struct {
 // buildStatementBundle collects metadata related to the planning and execution
 // of the statement. It generates a bundle for storage in
 // system.statement_diagnostics.
+//
+// If redacted is set, literals, placeholder values and sampled rows are
+// scrubbed from the bundle while the plans and the schema are kept intact.
+//
+// If ss is non-nil, the redacted log entries emitted during the execution of
+// the statement are included as well.
 func buildStatementBundle(
 	ctx context.Context,
 	db *kv.DB,
@@ -127,19 +170,35 @@ func buildStatementBundle(
 	planString string,
 	trace tracingpb.Recording,
 	placeholders *tree.PlaceholderInfo,
+	redacted bool,
+	ss serverpb.NodesStatusServer,
+	gatewayNodeID roachpb.NodeID,
 ) diagnosticsBundle {
 	if plan == nil {
 		return diagnosticsBundle{collectionErr: errors.AssertionFailedf("execution terminated early")}
 	}
-	b := makeStmtBundleBuilder(db, ie, plan, trace, placeholders)
+	bundleTrace := trace
+	if redacted {
+		bundleTrace = redactRecording(trace)
+	}
+	b := makeStmtBundleBuilder(db, ie, plan, bundleTrace, placeholders, redacted)
 
 	b.addStatement()
 	b.addOptPlans()
 	b.addExecPlan(planString)
-	b.addDistSQLDiagrams()
+	// The DistSQL diagrams include spans and expressions with the values from
+	// the query, so they are omitted from redacted bundles.
+	if !redacted {
+		b.addDistSQLDiagrams()
+	}
 	b.addExplainVec()
 	b.addTrace()
 	b.addEnv(ctx)
+	if ss != nil {
+		// The unredacted trace is needed to find the nodes which served the
+		// statement.
+		b.addLogs(ctx, ss, gatewayNodeID, trace)
+	}
 
 	buf, err := b.finalize()
 	if err != nil {
@@ -159,13 +218,18 @@ func (bundle *diagnosticsBundle) insert(
 	ast tree.Statement,
 	stmtDiagRecorder *stmtdiagnostics.Registry,
 	diagRequestID stmtdiagnostics.RequestID,
+	redacted bool,
 ) {
+	stmt := tree.AsString(ast)
+	if redacted {
+		stmt = redactedStatement(ast)
+	}
 	var err error
 	bundle.diagID, err = stmtDiagRecorder.InsertStatementDiagnostics(
 		ctx,
 		diagRequestID,
 		fingerprint,
-		tree.AsString(ast),
+		stmt,
 		bundle.zip,
 		bundle.collectionErr,
 	)
@@ -185,6 +249,8 @@ type stmtBundleBuilder struct {
 	plan         *planTop
 	trace        tracingpb.Recording
 	placeholders *tree.PlaceholderInfo
+	// redacted is set when user data should be scrubbed from the bundle.
+	redacted bool
 
 	z memzipper.Zipper
 }
@@ -195,14 +261,49 @@ func makeStmtBundleBuilder(
 	plan *planTop,
 	trace tracingpb.Recording,
 	placeholders *tree.PlaceholderInfo,
+	redacted bool,
 ) stmtBundleBuilder {
-	b := stmtBundleBuilder{db: db, ie: ie, plan: plan, trace: trace, placeholders: placeholders}
+	b := stmtBundleBuilder{
+		db: db, ie: ie, plan: plan, trace: trace, placeholders: placeholders, redacted: redacted,
+	}
 	b.z.Init()
 	return b
 }
 
-// addStatement adds the pretty-printed statement as file statement.txt.
-func (b *stmtBundleBuilder) addStatement() {
+// redactedStatement formats the statement with its literals and placeholder
+// values replaced by redaction markers. Names are left as is so that the
+// statement can still be matched against the schema.
+func redactedStatement(ast tree.Statement) string {
+	f := tree.NewFmtCtx(tree.FmtMarkRedactionNode | tree.FmtOmitNameRedaction)
+	f.FormatNode(ast)
+	return string(redact.RedactableString(f.CloseAndGetString()).Redact())
+}
+
+// redactRecording returns a copy of the trace where the log messages have
+// their unsafe parts replaced by redaction markers. Span tags are dropped
+// since they aren't redactable.
+func redactRecording(trace tracingpb.Recording) tracingpb.Recording {
+	res := make(tracingpb.Recording, len(trace))
+	for i := range trace {
+		sp := trace[i]
+		sp.Tags = nil
+		sp.TagGroups = nil
+		sp.Logs = make([]tracingpb.LogRecord, len(trace[i].Logs))
+		for j, l := range trace[i].Logs {
+			l.Message = l.Msg().Redact()
+			sp.Logs[j] = l
+		}
+		res[i] = sp
+	}
+	return res
+}
+
+// prettyStatement pretty-prints the statement, or formats it with redaction
+// markers if the bundle is redacted.
+func (b *stmtBundleBuilder) prettyStatement() string {
+	if b.redacted {
+		return redactedStatement(b.plan.stmt.AST)
+	}
 	cfg := tree.DefaultPrettyCfg()
 	cfg.UseTabs = false
 	cfg.LineWidth = 100
@@ -210,6 +311,11 @@ func (b *stmtBundleBuilder) addStatement() {
 	cfg.Simplify = true
 	cfg.Align = tree.PrettyNoAlign
 	cfg.JSONFmt = true
+	return cfg.Pretty(b.plan.stmt.AST)
+}
+
+// addStatement adds the pretty-printed statement as file statement.txt.
+func (b *stmtBundleBuilder) addStatement() {
 	var output string
 	// If we hit an early error, stmt or stmt.AST might not be initialized yet.
 	switch {
@@ -218,7 +324,7 @@ func (b *stmtBundleBuilder) addStatement() {
 	case b.plan.stmt.AST == nil:
 		output = "-- No AST."
 	default:
-		output = cfg.Pretty(b.plan.stmt.AST)
+		output = b.prettyStatement()
 	}
 
 	if b.placeholders != nil && len(b.placeholders.Values) != 0 {
@@ -226,7 +332,11 @@ func (b *stmtBundleBuilder) addStatement() {
 		buf.WriteString(output)
 		buf.WriteString("\n\n-- Arguments:\n")
 		for i, v := range b.placeholders.Values {
-			fmt.Fprintf(&buf, "--  %s: %v\n", tree.PlaceholderIdx(i), v)
+			if b.redacted {
+				fmt.Fprintf(&buf, "--  %s: %s\n", tree.PlaceholderIdx(i), redact.RedactedMarker())
+			} else {
+				fmt.Fprintf(&buf, "--  %s: %v\n", tree.PlaceholderIdx(i), v)
+			}
 		}
 		output = buf.String()
 	}
@@ -243,6 +353,11 @@ func (b *stmtBundleBuilder) addOptPlans() {
 	}
 
 	formatOptPlan := func(flags memo.ExprFmtFlags) string {
+		if b.redacted {
+			// Scalar expressions, constraints and histograms can contain values
+			// from the query or the table.
+			flags |= memo.ExprFmtHideScalars | memo.ExprFmtHideConstraints | memo.ExprFmtHideHistograms
+		}
 		f := memo.MakeExprFmtCtx(flags, b.plan.mem, b.plan.catalog)
 		f.FormatExpr(b.plan.mem.RootExpr())
 		return f.Buffer.String()
@@ -312,14 +427,7 @@ func (b *stmtBundleBuilder) addTrace() {
 		b.z.AddFile("trace.json", traceJSONStr)
 	}
 
-	cfg := tree.DefaultPrettyCfg()
-	cfg.UseTabs = false
-	cfg.LineWidth = 100
-	cfg.TabWidth = 2
-	cfg.Simplify = true
-	cfg.Align = tree.PrettyNoAlign
-	cfg.JSONFmt = true
-	stmt := cfg.Pretty(b.plan.stmt.AST)
+	stmt := b.prettyStatement()
 
 	// The JSON is not very human-readable, so we include another format too.
 	b.z.AddFile("trace.txt", fmt.Sprintf("%s\n\n\n\n%s", stmt, b.trace.String()))
@@ -414,13 +522,97 @@ func (b *stmtBundleBuilder) addEnv(ctx context.Context) {
 	b.z.AddFile("schema.sql", buf.String())
 	for i := range tables {
 		buf.Reset()
-		if err := c.PrintTableStats(&buf, &tables[i], false /* hideHistograms */); err != nil {
+		// The histograms contain sampled values from the table.
+		if err := c.PrintTableStats(&buf, &tables[i], b.redacted /* hideHistograms */); err != nil {
 			fmt.Fprintf(&buf, "-- error getting statistics for table %s: %v\n", tables[i].String(), err)
 		}
 		b.z.AddFile(fmt.Sprintf("stats-%s.sql", tables[i].String()), buf.String())
 	}
 }
 
+// addLogs adds the redacted log entries emitted on the gateway and on the
+// nodes which served the statement's KV requests during the execution of the
+// statement as file logs.txt. Entries emitted by a goroutine while it was
+// executing one of the spans of the trace are annotated with the trace and span
+// IDs of that span.
+func (b *stmtBundleBuilder) addLogs(
+	ctx context.Context,
+	ss serverpb.NodesStatusServer,
+	gatewayNodeID roachpb.NodeID,
+	trace tracingpb.Recording,
+) {
+	if len(trace) == 0 {
+		return
+	}
+	start, end := trace[0].StartTime, timeutil.Now()
+	var nodes util.FastIntSet
+	nodes.Add(int(gatewayNodeID))
+	for i := range trace {
+		if trace[i].StartTime.Before(start) {
+			start = trace[i].StartTime
+		}
+		if nodeID, err := strconv.Atoi(trace[i].Tags["node"]); err == nil {
+			nodes.Add(nodeID)
+		}
+	}
+
+	var buf bytes.Buffer
+	nodes.ForEach(func(nodeID int) {
+		resp, err := ss.Logs(ctx, &serverpb.LogsRequest{
+			NodeId:    strconv.Itoa(nodeID),
+			StartTime: strconv.FormatInt(start.UnixNano(), 10),
+			EndTime:   strconv.FormatInt(end.UnixNano(), 10),
+			Max:       strconv.Itoa(maxBundleLogEntries),
+			Redact:    true,
+		})
+		if err != nil {
+			fmt.Fprintf(&buf, "-- error getting logs from n%d: %v\n", nodeID, err)
+			return
+		}
+		for _, e := range resp.Entries {
+			if !bundleLogChannels[e.Channel] {
+				continue
+			}
+			if sp := findSpanForLogEntry(trace, roachpb.NodeID(nodeID), &e); sp != nil {
+				if e.Tags != "" {
+					e.Tags += ","
+				}
+				e.Tags += fmt.Sprintf("trace=%d,span=%d", sp.TraceID, sp.SpanID)
+			}
+			if err := log.FormatLegacyEntry(e, &buf); err != nil {
+				fmt.Fprintf(&buf, "-- error formatting log entry from n%d: %v\n", nodeID, err)
+			}
+		}
+	})
+	if buf.Len() > 0 {
+		b.z.AddFile("logs.txt", buf.String())
+	}
+}
+
+// findSpanForLogEntry returns the span of the trace which was being executed on
+// the given node by the goroutine that emitted the log entry when the entry was
+// emitted, or nil if there is no such span. If the spans are nested, the
+// innermost one is returned.
+func findSpanForLogEntry(
+	trace tracingpb.Recording, nodeID roachpb.NodeID, e *logpb.Entry,
+) *tracingpb.RecordedSpan {
+	var res *tracingpb.RecordedSpan
+	for i := range trace {
+		sp := &trace[i]
+		if sp.GoroutineID != uint64(e.Goroutine) ||
+			sp.Tags["node"] != strconv.Itoa(int(nodeID)) {
+			continue
+		}
+		if e.Time < sp.StartTime.UnixNano() || e.Time > sp.StartTime.Add(sp.Duration).UnixNano() {
+			continue
+		}
+		if res == nil || sp.StartTime.After(res.StartTime) {
+			res = sp
+		}
+	}
+	return res
+}
+
 // finalize generates the zipped bundle and returns it as a buffer.
 func (b *stmtBundleBuilder) finalize() (*bytes.Buffer, error) {
 	return b.z.Finalize()
@@ -151,6 +151,22 @@ CREATE TABLE users(id UUID DEFAULT gen_random_uuid() PRIMARY KEY, promo_id INT R
 		)
 	})
 
+	t.Run("redacted", func(t *testing.T) {
+		rows := r.QueryStr(t, "EXPLAIN ANALYZE (DEBUG, REDACT) SELECT * FROM abc WHERE c=123456789")
+		checkBundle(
+			t, fmt.Sprint(rows), "public.abc", func(name, contents string) error {
+				if strings.Contains(contents, "123456789") {
+					return errors.Newf("found unredacted literal in %s:\n%s", name, contents)
+				}
+				if name == "statement.sql" && !strings.Contains(contents, "‹×›") {
+					return errors.Newf("expected redaction marker in statement.sql:\n%s", contents)
+				}
+				return nil
+			},
+			base, plans, "stats-defaultdb.public.abc.sql", "vec.txt vec-v.txt",
+		)
+	})
+
 	t.Run("session-settings", func(t *testing.T) {
 		testcases := []struct {
 			sessionVar, value string
@@ -243,6 +259,59 @@ CREATE TABLE users(id UUID DEFAULT gen_random_uuid() PRIMARY KEY, promo_id INT R
 	})
 }
 
+// TestExplainAnalyzeDebugWithLogs verifies that the log entries emitted during
+// the execution of a statement are included in its bundle when the
+// sql.stmt_diagnostics.bundle_logs.enabled cluster setting is set.
+func TestExplainAnalyzeDebugWithLogs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const logMessage = "executing the statement of the bundle logs test"
+	ctx := context.Background()
+	srv, godb, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Insecure: true,
+		Knobs: base.TestingKnobs{
+			SQLExecutor: &ExecutorTestingKnobs{
+				BeforeExecute: func(ctx context.Context, stmt string) {
+					if strings.Contains(stmt, "bundle_logs") {
+						log.Infof(ctx, logMessage)
+					}
+				},
+			},
+		},
+	})
+	defer srv.Stopper().Stop(ctx)
+	r := sqlutils.MakeSQLRunner(godb)
+	r.Exec(t, `CREATE TABLE bundle_logs (a INT PRIMARY KEY)`)
+	r.Exec(t, `SET CLUSTER SETTING sql.stmt_diagnostics.bundle_logs.enabled = true`)
+
+	rows := r.QueryStr(t, "EXPLAIN ANALYZE (DEBUG) SELECT * FROM bundle_logs")
+	var logsFound bool
+	checkBundle(
+		t, fmt.Sprint(rows), "public.bundle_logs", func(name, contents string) error {
+			if name != "logs.txt" {
+				return nil
+			}
+			logsFound = true
+			for _, line := range strings.Split(contents, "\n") {
+				if strings.Contains(line, logMessage) {
+					if !strings.Contains(line, "trace=") || !strings.Contains(line, "span=") {
+						return errors.Newf("expected the log entry to be correlated with the trace: %s", line)
+					}
+					return nil
+				}
+			}
+			return errors.Newf("expected to find the log entry in logs.txt:\n%s", contents)
+		},
+		"statement.sql trace.json trace.txt trace-jaeger.json env.sql",
+		"schema.sql opt.txt opt-v.txt opt-vv.txt plan.txt",
+		"stats-defaultdb.public.bundle_logs.sql distsql.html vec.txt vec-v.txt logs.txt",
+	)
+	if !logsFound {
+		t.Fatal("expected logs.txt in the bundle")
+	}
+}
+
 // checkBundle searches text strings for a bundle URL and then verifies that the
 // bundle contains the expected files. The expected files are passed as an
 // arbitrary number of strings; each string contains one or more filenames
@@ -255,7 +255,7 @@ func (sp *csvWriter) Run(ctx context.Context) {
 			if err != nil {
 				return err
 			}
-			es, err := sp.flowCtx.Cfg.ExternalStorage(ctx, conf)
+			es, err := sp.flowCtx.Cfg.ExternalStorage(ctx, conf, cloud.WithIOConsumer(cloud.ExportIOConsumer))
 			if err != nil {
 				return err
 			}
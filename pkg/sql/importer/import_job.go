@@ -1298,7 +1298,7 @@ func ingestWithRetry(
 		}
 
 		if errors.HasType(err, &roachpb.InsufficientSpaceError{}) {
-			return res, jobs.MarkPauseRequestError(errors.UnwrapAll(err))
+			return res, jobs.MarkDiskPressurePauseError(ctx, job.ID(), errors.UnwrapAll(err))
 		}
 
 		if joberror.IsPermanentBulkJobError(err) {
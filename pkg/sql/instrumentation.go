@@ -19,6 +19,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
@@ -39,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/buildutil"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
@@ -89,6 +91,11 @@ type instrumentationHelper struct {
 	// statement; it triggers saving of extra information like the plan string.
 	collectBundle bool
 
+	// redactBundle is set when the collected bundle should have its user data
+	// scrubbed, either because of EXPLAIN ANALYZE (DEBUG, REDACT) or because
+	// the diagnostics request asked for a redacted bundle.
+	redactBundle bool
+
 	// collectExecStats is set when we are collecting execution statistics for a
 	// statement.
 	collectExecStats bool
@@ -250,6 +257,7 @@ func (ih *instrumentationHelper) Setup(
 	default:
 		ih.collectBundle, ih.diagRequestID, ih.diagRequest =
 			stmtDiagnosticsRecorder.ShouldCollectDiagnostics(ctx, fingerprint)
+		ih.redactBundle = ih.diagRequest.IsRedacted()
 	}
 
 	ih.stmtDiagnosticsRecorder = stmtDiagnosticsRecorder
@@ -367,16 +375,31 @@ func (ih *instrumentationHelper) Finish(
 			ih.diagRequestID, ih.diagRequest, phaseTimes.GetServiceLatencyNoOverhead(),
 		) {
 			placeholders := p.extendedEvalCtx.Placeholders
+			planFlags := explain.Flags{Verbose: true, ShowTypes: true}
+			if ih.redactBundle {
+				// HideValues can't be combined with Verbose.
+				planFlags = explain.Flags{HideValues: true}
+			}
 			ob := ih.emitExplainAnalyzePlanToOutputBuilder(
-				explain.Flags{Verbose: true, ShowTypes: true},
+				planFlags,
 				phaseTimes,
 				queryLevelStats,
 			)
 			warnings = ob.GetWarnings()
+			var ss serverpb.NodesStatusServer
+			if bundleLogsEnabled.Get(&cfg.Settings.SV) {
+				// The logs are only available to the system tenant.
+				ss, _ = cfg.NodesStatusServer.OptionalNodesStatusServer(
+					errorutil.FeatureNotAvailableToNonSystemTenantsIssue)
+			}
+			gatewayNodeID, _ := cfg.NodeInfo.NodeID.OptionalNodeID()
 			bundle = buildStatementBundle(
 				ih.origCtx, cfg.DB, ie.(*InternalExecutor), &p.curPlan, ob.BuildString(), trace, placeholders,
+				ih.redactBundle, ss, gatewayNodeID,
+			)
+			bundle.insert(
+				ctx, ih.fingerprint, ast, cfg.StmtDiagnosticsRecorder, ih.diagRequestID, ih.redactBundle,
 			)
-			bundle.insert(ctx, ih.fingerprint, ast, cfg.StmtDiagnosticsRecorder, ih.diagRequestID)
 			ih.stmtDiagnosticsRecorder.RemoveOngoing(ih.diagRequestID, ih.diagRequest)
 			telemetry.Inc(sqltelemetry.StatementDiagnosticsCollectedCounter)
 		}
@@ -514,6 +537,12 @@ func (ih *instrumentationHelper) emitExplainAnalyzePlanToOutputBuilder(
 		if queryStats.ContentionTime != 0 {
 			ob.AddContentionTime(queryStats.ContentionTime)
 		}
+		if queryStats.AdmissionWaitTime != 0 {
+			ob.AddAdmissionWaitTime(queryStats.AdmissionWaitTime)
+		}
+		if queryStats.IngestWaitTime != 0 {
+			ob.AddIngestWaitTime(queryStats.IngestWaitTime)
+		}
 
 		ob.AddMaxMemUsage(queryStats.MaxMemUsage)
 		ob.AddNetworkStats(queryStats.NetworkMessages, queryStats.NetworkBytesSent)
@@ -649,6 +678,10 @@ func (m execNodeTraceMetadata) annotateExplain(
 				nodeStats.InternalStepCount.MaybeAdd(stats.KV.NumInternalSteps)
 				nodeStats.SeekCount.MaybeAdd(stats.KV.NumInterfaceSeeks)
 				nodeStats.InternalSeekCount.MaybeAdd(stats.KV.NumInternalSeeks)
+				nodeStats.BlockBytes.MaybeAdd(stats.KV.BlockBytes)
+				nodeStats.BlockBytesInCache.MaybeAdd(stats.KV.BlockBytesInCache)
+				nodeStats.KVAdmissionWaitTime.MaybeAdd(stats.KV.AdmissionWaitTime)
+				nodeStats.KVIngestWaitTime.MaybeAdd(stats.KV.IngestWaitTime)
 				nodeStats.VectorizedBatchCount.MaybeAdd(stats.Output.NumBatches)
 				nodeStats.MaxAllocatedMem.MaybeAdd(stats.Exec.MaxAllocatedMem)
 				nodeStats.MaxAllocatedDisk.MaybeAdd(stats.Exec.MaxAllocatedDisk)
@@ -263,6 +263,7 @@ func (b *Builder) buildAlterRangeRelocate(relocate *memo.AlterRangeRelocateExpr)
 		relocate.SubjectReplicas,
 		toStoreID,
 		fromStoreID,
+		relocate.Priority,
 	)
 	if err != nil {
 		return execPlan{}, err
@@ -408,6 +408,14 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 		if s.KVContentionTime.HasValue() {
 			e.ob.AddField("KV contention time", string(humanizeutil.Duration(s.KVContentionTime.Value())))
 		}
+		// The wait times are only shown when non-zero, since most KV requests are
+		// neither queued nor throttled.
+		if s.KVAdmissionWaitTime.HasValue() && s.KVAdmissionWaitTime.Value() != 0 {
+			e.ob.AddField("KV admission wait time", string(humanizeutil.Duration(s.KVAdmissionWaitTime.Value())))
+		}
+		if s.KVIngestWaitTime.HasValue() && s.KVIngestWaitTime.Value() != 0 {
+			e.ob.AddField("KV ingest wait time", string(humanizeutil.Duration(s.KVIngestWaitTime.Value())))
+		}
 		if s.KVRowsRead.HasValue() {
 			e.ob.AddField("KV rows read", string(humanizeutil.Count(s.KVRowsRead.Value())))
 		}
@@ -434,6 +442,12 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 					humanizeutil.Count(s.SeekCount.Value()), humanizeutil.Count(s.InternalSeekCount.Value()),
 				))
 			}
+			// The block bytes are only shown when some storage blocks were loaded.
+			if s.BlockBytes.HasValue() && s.BlockBytes.Value() != 0 {
+				e.ob.AddField("storage block bytes (total/cached)", fmt.Sprintf("%s/%s",
+					humanize.IBytes(s.BlockBytes.Value()), humanize.IBytes(s.BlockBytesInCache.Value()),
+				))
+			}
 		}
 	}
 
@@ -931,6 +945,9 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 		if a.subjectReplicas != tree.RelocateLease {
 			ob.Expr("from", a.fromStoreID, nil /* columns */)
 		}
+		if a.priority != tree.UnspecifiedUserPriority {
+			ob.Attr("priority", a.priority)
+		}
 
 	case simpleProjectOp,
 		serializingProjectOp,
@@ -332,6 +332,26 @@ func (ob *OutputBuilder) AddContentionTime(contentionTime time.Duration) {
 	)
 }
 
+// AddAdmissionWaitTime adds a top-level field for the cumulative time spent
+// waiting in admission control queues.
+func (ob *OutputBuilder) AddAdmissionWaitTime(waitTime time.Duration) {
+	ob.AddRedactableTopLevelField(
+		RedactVolatile,
+		"cumulative time spent in admission queues",
+		string(humanizeutil.Duration(waitTime)),
+	)
+}
+
+// AddIngestWaitTime adds a top-level field for the cumulative time SST
+// ingestions were throttled for.
+func (ob *OutputBuilder) AddIngestWaitTime(waitTime time.Duration) {
+	ob.AddRedactableTopLevelField(
+		RedactVolatile,
+		"cumulative time spent waiting to ingest",
+		string(humanizeutil.Duration(waitTime)),
+	)
+}
+
 // AddMaxMemUsage adds a top-level field for the memory used by the query.
 func (ob *OutputBuilder) AddMaxMemUsage(bytes int64) {
 	ob.AddRedactableTopLevelField(
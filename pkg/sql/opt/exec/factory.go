@@ -341,6 +341,12 @@ type ExecutionStats struct {
 	SeekCount         optional.Uint
 	InternalSeekCount optional.Uint
 
+	BlockBytes        optional.Uint
+	BlockBytesInCache optional.Uint
+
+	KVAdmissionWaitTime optional.Duration
+	KVIngestWaitTime    optional.Duration
+
 	MaxAllocatedMem  optional.Uint
 	MaxAllocatedDisk optional.Uint
 
@@ -573,6 +573,10 @@ func (h *hasher) HashRelocateSubject(val tree.RelocateSubject) {
 	h.HashUint64(uint64(val))
 }
 
+func (h *hasher) HashUserPriority(val tree.UserPriority) {
+	h.HashInt(int(val))
+}
+
 func (h *hasher) HashIndexOrdinals(val cat.IndexOrdinals) {
 	hash := h.hash
 	for _, ord := range val {
@@ -1003,6 +1007,10 @@ func (h *hasher) IsRelocateSubjectEqual(l, r tree.RelocateSubject) bool {
 	return l == r
 }
 
+func (h *hasher) IsUserPriorityEqual(l, r tree.UserPriority) bool {
+	return l == r
+}
+
 func (h *hasher) IsIndexOrdinalsEqual(l, r cat.IndexOrdinals) bool {
 	if len(l) != len(r) {
 		return false
@@ -68,6 +68,7 @@ func (b *Builder) buildAlterRangeRelocate(
 		fromStoreID,
 		&memo.AlterRangeRelocatePrivate{
 			SubjectReplicas: relocate.SubjectReplicas,
+			Priority:        relocate.Priority,
 			Columns:         colsToColList(outScope.cols),
 			Props:           physical.MinRequired,
 		},
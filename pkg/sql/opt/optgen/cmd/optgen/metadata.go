@@ -242,6 +242,7 @@ func newMetadata(compiled *lang.CompiledExpr, pkg string) *metadata {
 		"IndexOrdinals":       {fullName: "cat.IndexOrdinals", passByVal: true},
 		"RelocateSubject":     {fullName: "tree.RelocateSubject", passByVal: true},
 		"UniqueOrdinals":      {fullName: "cat.UniqueOrdinals", passByVal: true},
+		"UserPriority":        {fullName: "tree.UserPriority", passByVal: true},
 		"SchemaDeps":          {fullName: "opt.SchemaDeps", passByVal: true},
 		"SchemaTypeDeps":      {fullName: "opt.SchemaTypeDeps", passByVal: true},
 		"Locking":             {fullName: "opt.Locking", passByVal: true},
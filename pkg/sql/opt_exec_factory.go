@@ -2033,6 +2033,7 @@ func (ef *execFactory) ConstructAlterRangeRelocate(
 	relocateSubject tree.RelocateSubject,
 	toStoreID tree.TypedExpr,
 	fromStoreID tree.TypedExpr,
+	priority tree.UserPriority,
 ) (exec.Node, error) {
 	if !ef.planner.ExecCfg().Codec.ForSystemTenant() {
 		return nil, errorutil.UnsupportedWithMultiTenancy(54250)
@@ -2043,6 +2044,7 @@ func (ef *execFactory) ConstructAlterRangeRelocate(
 		subjectReplicas: relocateSubject,
 		toStoreID:       toStoreID,
 		fromStoreID:     fromStoreID,
+		priority:        priority,
 	}, nil
 }
 
@@ -1332,6 +1332,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 
 %type <tree.IsolationLevel> iso_level
 %type <tree.UserPriority> user_priority
+%type <tree.UserPriority> opt_relocate_priority
 
 %type <tree.TableDefs> opt_table_elem_list table_elem_list create_as_opt_col_list create_as_table_defs
 %type <[]tree.LikeTableOption> like_table_option_list
@@ -2090,8 +2091,8 @@ alter_database_set_zone_config_extension_stmt:
 //
 // Commands:
 //   ALTER RANGE ... CONFIGURE ZONE <zoneconfig>
-//   ALTER RANGE   RELOCATE { VOTERS | NONVOTERS } FROM <store_id> TO <store_id> FOR <selectclause>
-//   ALTER RANGE r RELOCATE { VOTERS | NONVOTERS } FROM <store_id> TO <store_id>
+//   ALTER RANGE   RELOCATE { VOTERS | NONVOTERS } FROM <store_id> TO <store_id> [WITH PRIORITY <priority>] FOR <selectclause>
+//   ALTER RANGE r RELOCATE { VOTERS | NONVOTERS } FROM <store_id> TO <store_id> [WITH PRIORITY <priority>]
 //   ALTER RANGE   RELOCATE LEASE                                  TO <store_id> FOR <selectclause>
 //   ALTER RANGE r RELOCATE LEASE                                  TO <store_id>
 //
@@ -2101,6 +2102,9 @@ alter_database_set_zone_config_extension_stmt:
 //   USING <var> = COPY FROM PARENT [, ...]
 //   { TO | = } <expr>
 //
+// Relocation priorities:
+//   LOW | NORMAL | HIGH
+//
 // %SeeAlso: ALTER TABLE
 alter_range_stmt:
   alter_zone_range_stmt
@@ -2340,16 +2344,17 @@ alter_range_relocate_stmt:
         SubjectReplicas: tree.RelocateLease,
       }
     }
-| ALTER RANGE relocate_kw relocate_subject_nonlease FROM a_expr TO a_expr FOR select_stmt
+| ALTER RANGE relocate_kw relocate_subject_nonlease FROM a_expr TO a_expr opt_relocate_priority FOR select_stmt
   {
     $$.val = &tree.RelocateRange{
-      Rows: $10.slct(),
+      Rows: $11.slct(),
       FromStoreID: $6.expr(),
       ToStoreID: $8.expr(),
       SubjectReplicas: $4.relocateSubject(),
+      Priority: $9.userPriority(),
     }
   }
-| ALTER RANGE a_expr relocate_kw relocate_subject_nonlease FROM a_expr TO a_expr
+| ALTER RANGE a_expr relocate_kw relocate_subject_nonlease FROM a_expr TO a_expr opt_relocate_priority
   {
     $$.val = &tree.RelocateRange{
       Rows: &tree.Select{
@@ -2358,9 +2363,20 @@ alter_range_relocate_stmt:
       FromStoreID: $7.expr(),
       ToStoreID: $9.expr(),
       SubjectReplicas: $5.relocateSubject(),
+      Priority: $10.userPriority(),
     }
   }
 
+opt_relocate_priority:
+  WITH PRIORITY user_priority
+  {
+    $$.val = $3.userPriority()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.UnspecifiedUserPriority
+  }
+
 set_zone_config:
   CONFIGURE ZONE to_or_eq a_expr
   {
@@ -2692,6 +2708,11 @@ alter_table_cmd:
   {
     $$.val = &tree.AlterTableSetAudit{Mode: $3.auditMode()}
   }
+  // ALTER TABLE <name> EXPERIMENTAL_AUDIT SET <mode> FOR ROLE <role>
+| EXPERIMENTAL_AUDIT SET audit_mode FOR ROLE role_spec
+  {
+    $$.val = &tree.AlterTableSetAudit{Mode: $3.auditMode(), Role: $6.roleSpec()}
+  }
   // ALTER TABLE <name> PARTITION BY ...
 | partition_by_table
   {
@@ -2722,6 +2743,8 @@ alter_table_cmd:
 
 audit_mode:
   READ WRITE { $$.val = tree.AuditModeReadWrite }
+| READ       { $$.val = tree.AuditModeRead }
+| WRITE      { $$.val = tree.AuditModeWrite }
 | OFF        { $$.val = tree.AuditModeDisable }
 
 alter_index_cmds:
@@ -58,6 +58,8 @@ const (
 	BACKUP                   Kind = 23
 	RESTORE                  Kind = 24
 	EXTERNALIOIMPLICITACCESS Kind = 25
+	VIEWLOGS                 Kind = 26
+	VIEWLOGSREDACTED         Kind = 27
 )
 
 // Privilege represents a privilege parsed from an Access Privilege Inquiry
@@ -125,7 +127,7 @@ var (
 	// certain privileges unavailable after upgrade migration.
 	// Note that "CREATE, INSERT, DELETE, ZONECONFIG" are no-op privileges on sequences.
 	SequencePrivileges           = List{ALL, USAGE, SELECT, UPDATE, CREATE, DROP, INSERT, DELETE, ZONECONFIG}
-	GlobalPrivileges             = List{ALL, BACKUP, RESTORE, MODIFYCLUSTERSETTING, EXTERNALCONNECTION, VIEWACTIVITY, VIEWACTIVITYREDACTED, VIEWCLUSTERSETTING, CANCELQUERY, NOSQLLOGIN, VIEWCLUSTERMETADATA, VIEWDEBUG, EXTERNALIOIMPLICITACCESS}
+	GlobalPrivileges             = List{ALL, BACKUP, RESTORE, MODIFYCLUSTERSETTING, EXTERNALCONNECTION, VIEWACTIVITY, VIEWACTIVITYREDACTED, VIEWCLUSTERSETTING, CANCELQUERY, NOSQLLOGIN, VIEWCLUSTERMETADATA, VIEWDEBUG, EXTERNALIOIMPLICITACCESS, VIEWLOGS, VIEWLOGSREDACTED}
 	VirtualTablePrivileges       = List{ALL, SELECT}
 	ExternalConnectionPrivileges = List{ALL, USAGE, DROP}
 )
@@ -173,6 +175,8 @@ var ByName = map[string]Kind{
 	"BACKUP":                   BACKUP,
 	"RESTORE":                  RESTORE,
 	"EXTERNALIOIMPLICITACCESS": EXTERNALIOIMPLICITACCESS,
+	"VIEWLOGS":                 VIEWLOGS,
+	"VIEWLOGSREDACTED":         VIEWLOGSREDACTED,
 }
 
 // List is a list of privileges.
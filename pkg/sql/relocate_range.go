@@ -12,15 +12,27 @@ package sql
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/paramparse"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
 
+// relocateProgressInterval is the interval at which the progress of the
+// snapshots sent to the target store of a relocation is reported.
+var relocateProgressInterval = 5 * time.Second
+
 type relocateRange struct {
 	optColumnsSlot
 
@@ -28,7 +40,10 @@ type relocateRange struct {
 	subjectReplicas tree.RelocateSubject
 	toStoreID       tree.TypedExpr
 	fromStoreID     tree.TypedExpr
-	run             relocateRunState
+	// priority is the priority of the snapshots sent to the target store, if
+	// specified.
+	priority tree.UserPriority
+	run      relocateRunState
 }
 
 // relocateRunState contains the run-time state of
@@ -52,6 +67,7 @@ type relocateRequest struct {
 	subjectReplicas tree.RelocateSubject
 	toStoreDesc     *roachpb.StoreDescriptor
 	fromStoreDesc   *roachpb.StoreDescriptor
+	priority        tree.UserPriority
 }
 
 func (n *relocateRange) startExec(params runParams) error {
@@ -104,6 +120,7 @@ func (n *relocateRange) Next(params runParams) (bool, error) {
 		subjectReplicas: n.subjectReplicas,
 		fromStoreDesc:   n.run.fromStoreDesc,
 		toStoreDesc:     n.run.toStoreDesc,
+		priority:        n.priority,
 	})
 
 	// record the results of the relocation run, so we can output it.
@@ -119,6 +136,11 @@ func (n *relocateRange) Values() tree.Datums {
 	result := "ok"
 	if n.run.results.err != nil {
 		result = n.run.results.err.Error()
+		// Surface the hints of the snapshot subsystem, which indicate whether
+		// and when the relocation can be retried.
+		if hints := errors.FlattenHints(n.run.results.err); hints != "" {
+			result += "\nHINT: " + hints
+		}
 	}
 	pretty := ""
 	if n.run.results.rangeDesc != nil {
@@ -148,21 +170,24 @@ func relocate(params runParams, req relocateRequest) (*roachpb.RangeDescriptor,
 
 	toTarget := roachpb.ReplicationTarget{NodeID: req.toStoreDesc.Node.NodeID, StoreID: req.toStoreDesc.StoreID}
 	fromTarget := roachpb.ReplicationTarget{NodeID: req.fromStoreDesc.Node.NodeID, StoreID: req.fromStoreDesc.StoreID}
+	chgs := []roachpb.ReplicationChange{
+		{ChangeType: roachpb.ADD_VOTER, Target: toTarget},
+		{ChangeType: roachpb.REMOVE_VOTER, Target: fromTarget},
+	}
 	if req.subjectReplicas == tree.RelocateNonVoters {
-		_, err := params.p.ExecCfg().DB.AdminChangeReplicas(
-			params.ctx, rangeDesc.StartKey, *rangeDesc, []roachpb.ReplicationChange{
-				{ChangeType: roachpb.ADD_NON_VOTER, Target: toTarget},
-				{ChangeType: roachpb.REMOVE_NON_VOTER, Target: fromTarget},
-			},
-		)
-		return rangeDesc, err
+		chgs = []roachpb.ReplicationChange{
+			{ChangeType: roachpb.ADD_NON_VOTER, Target: toTarget},
+			{ChangeType: roachpb.REMOVE_NON_VOTER, Target: fromTarget},
+		}
 	}
-	_, err = params.p.ExecCfg().DB.AdminChangeReplicas(
-		params.ctx, rangeDesc.StartKey, *rangeDesc, []roachpb.ReplicationChange{
-			{ChangeType: roachpb.ADD_VOTER, Target: toTarget},
-			{ChangeType: roachpb.REMOVE_VOTER, Target: fromTarget},
-		},
-	)
+	// An unspecified priority maps to the normal priority.
+	snapshotPriority := txnPriorityToProto(req.priority)
+	err = relocateWithProgress(params, rangeDesc.RangeID, req.toStoreDesc, func(ctx context.Context) error {
+		_, err := params.p.ExecCfg().DB.AdminChangeReplicasWithPriority(
+			ctx, rangeDesc.StartKey, *rangeDesc, chgs, snapshotPriority,
+		)
+		return err
+	})
 	// TODO(aayush): If the `AdminChangeReplicas`call failed because it found that
 	// the range was already in the process of being rebalanced, we currently fail
 	// the statement. We should consider instead force-removing these learners
@@ -170,6 +195,125 @@ func relocate(params runParams, req relocateRequest) (*roachpb.RangeDescriptor,
 	return rangeDesc, err
 }
 
+// relocateWithProgress runs the given relocation of a range and, while it is
+// running, periodically reports the progress of the snapshot received by the
+// target store to the client, as notices sent right away, and to the log.
+func relocateWithProgress(
+	params runParams,
+	rangeID roachpb.RangeID,
+	toStoreDesc *roachpb.StoreDescriptor,
+	fn func(ctx context.Context) error,
+) error {
+	ss, err := params.p.ExecCfg().NodesStatusServer.OptionalNodesStatusServer(
+		errorutil.FeatureNotAvailableToNonSystemTenantsIssue)
+	if err != nil {
+		// The progress can't be reported without the status server.
+		return fn(params.ctx)
+	}
+
+	ctx := params.ctx
+	errCh := make(chan error, 1)
+	if err := params.p.ExecCfg().RPCContext.Stopper.RunAsyncTask(ctx, "relocate-range", func(ctx context.Context) {
+		errCh <- fn(ctx)
+	}); err != nil {
+		return err
+	}
+
+	var timer timeutil.Timer
+	defer timer.Stop()
+	timer.Reset(relocateProgressInterval)
+	for {
+		select {
+		case err := <-errCh:
+			return err
+		case <-timer.C:
+			timer.Read = true
+			// The notices are sent by this goroutine, the one that owns the
+			// planner, while the relocation is running in the async task. They
+			// are sent right away, rather than when the statement completes, so
+			// that the client can follow the progress of the relocation.
+			progress, ok, err := relocateSnapshotProgress(ctx, ss, rangeID, toStoreDesc)
+			if err != nil {
+				log.Warningf(ctx, "unable to retrieve the progress of the relocation of r%d: %v", rangeID, err)
+			} else if ok {
+				log.Infof(ctx, "%s", progress)
+				if err := params.p.SendClientNotice(ctx, pgnotice.Newf("%s", progress)); err != nil {
+					log.Warningf(ctx, "unable to report the progress of the relocation of r%d: %v", rangeID, err)
+				}
+			}
+			timer.Reset(relocateProgressInterval)
+		}
+	}
+}
+
+// relocateSnapshotProgress returns the progress of the snapshot of the given
+// range in flight to the target store, if any.
+func relocateSnapshotProgress(
+	ctx context.Context,
+	ss serverpb.NodesStatusServer,
+	rangeID roachpb.RangeID,
+	toStoreDesc *roachpb.StoreDescriptor,
+) (_ relocateProgress, ok bool, _ error) {
+	response, err := ss.StoreSnapshots(ctx, &serverpb.StoreSnapshotsRequest{
+		NodeID: toStoreDesc.Node.NodeID.String(),
+	})
+	if err != nil {
+		return relocateProgress{}, false, err
+	}
+	for _, snap := range response.Snapshots {
+		if snap.StoreID != toStoreDesc.StoreID || snap.RangeID != rangeID ||
+			snap.Direction != "receive" /* kvserver.SnapshotReceived */ || !snap.InFlight {
+			continue
+		}
+		return relocateProgress{
+			rangeID:       rangeID,
+			toStoreID:     toStoreDesc.StoreID,
+			bytes:         snap.Bytes,
+			expectedBytes: snap.ExpectedBytes,
+			elapsed:       snap.Duration,
+		}, true, nil
+	}
+	return relocateProgress{}, false, nil
+}
+
+// relocateProgress describes the progress of the snapshot sent to the target
+// store of a relocation.
+type relocateProgress struct {
+	rangeID   roachpb.RangeID
+	toStoreID roachpb.StoreID
+	// bytes is the number of bytes sent so far, out of approximately
+	// expectedBytes, in elapsed time.
+	bytes         int64
+	expectedBytes int64
+	elapsed       time.Duration
+}
+
+// eta returns the estimated time until the snapshot is fully sent, based on
+// the rate at which it has been sent so far. It returns false if the time
+// can't be estimated.
+func (p relocateProgress) eta() (time.Duration, bool) {
+	if p.bytes <= 0 || p.expectedBytes <= 0 || p.elapsed <= 0 {
+		return 0, false
+	}
+	remaining := p.expectedBytes - p.bytes
+	if remaining <= 0 {
+		// The size of the range is only an estimate.
+		return 0, true
+	}
+	return time.Duration(float64(p.elapsed) * float64(remaining) / float64(p.bytes)), true
+}
+
+func (p relocateProgress) String() string {
+	s := fmt.Sprintf("relocating r%d: sent %s to s%d", p.rangeID, humanizeutil.IBytes(p.bytes), p.toStoreID)
+	if p.expectedBytes > 0 {
+		s += fmt.Sprintf(" out of ~%s", humanizeutil.IBytes(p.expectedBytes))
+	}
+	if eta, ok := p.eta(); ok {
+		s += fmt.Sprintf(", ETA %s", eta.Round(time.Second))
+	}
+	return s
+}
+
 func lookupRangeDescriptorByRangeID(
 	ctx context.Context, db *kv.DB, rangeID roachpb.RangeID,
 ) (*roachpb.RangeDescriptor, error) {
@@ -0,0 +1,56 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelocateProgress(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		progress relocateProgress
+		expected string
+	}{
+		{
+			progress: relocateProgress{rangeID: 5, toStoreID: 2},
+			expected: "relocating r5: sent 0 B to s2",
+		},
+		{
+			progress: relocateProgress{
+				rangeID: 5, toStoreID: 2, bytes: 1 << 20, elapsed: 10 * time.Second,
+			},
+			expected: "relocating r5: sent 1.0 MiB to s2",
+		},
+		{
+			progress: relocateProgress{
+				rangeID: 5, toStoreID: 2, bytes: 1 << 20, expectedBytes: 4 << 20, elapsed: 10 * time.Second,
+			},
+			expected: "relocating r5: sent 1.0 MiB to s2 out of ~4.0 MiB, ETA 30s",
+		},
+		{
+			// The size of the range is only an estimate, which can be exceeded.
+			progress: relocateProgress{
+				rangeID: 5, toStoreID: 2, bytes: 5 << 20, expectedBytes: 4 << 20, elapsed: 10 * time.Second,
+			},
+			expected: "relocating r5: sent 5.0 MiB to s2 out of ~4.0 MiB, ETA 0s",
+		},
+	} {
+		require.Equal(t, tc.expected, tc.progress.String())
+	}
+}
@@ -517,6 +517,20 @@ func (b *builderState) ComputedColumnExpression(tbl *scpb.Table, d *tree.ColumnT
 	return parsedExpr
 }
 
+// ValidateTTLExpirationExpression implements the scbuildstmt.TableHelpers
+// interface.
+func (b *builderState) ValidateTTLExpirationExpression(tbl *scpb.Table, ttl *catpb.RowLevelTTL) {
+	_, _, ns := scpb.FindNamespace(b.QueryByID(tbl.TableID))
+	tn := tree.MakeTableNameFromPrefix(b.NamePrefix(tbl), tree.Name(ns.Name))
+	b.ensureDescriptor(tbl.TableID)
+	desc := b.descCache[tbl.TableID].desc.(catalog.TableDescriptor)
+	mut := tabledesc.NewBuilder(desc.TableDesc()).BuildExistingMutableTable()
+	mut.RowLevelTTL = ttl
+	if err := schemaexpr.ValidateTTLExpirationExpression(b.ctx, mut, b.semaCtx, &tn); err != nil {
+		panic(err)
+	}
+}
+
 var _ scbuildstmt.ElementReferences = (*builderState)(nil)
 
 // ForwardReferences implements the scbuildstmt.ElementReferences interface.
@@ -56,6 +56,10 @@ var supportedAlterTableStatements = map[reflect.Type]supportedAlterTableCommand{
 		d, ok := t.ConstraintDef.(*tree.UniqueConstraintTableDef)
 		return ok && d.PrimaryKey && t.ValidationBehavior == tree.ValidationDefault
 	}, minSupportedClusterVersion: clusterversion.Start22_2},
+	reflect.TypeOf((*tree.AlterTableSetStorageParams)(nil)): {fn: alterTableSetStorageParams, on: true,
+		extraChecks: alterTableSetStorageParamsIsSupported, minSupportedClusterVersion: clusterversion.DeclarativeRowLevelTTL},
+	reflect.TypeOf((*tree.AlterTableResetStorageParams)(nil)): {fn: alterTableResetStorageParams, on: true,
+		extraChecks: alterTableResetStorageParamsIsSupported, minSupportedClusterVersion: clusterversion.DeclarativeRowLevelTTL},
 }
 
 func init() {
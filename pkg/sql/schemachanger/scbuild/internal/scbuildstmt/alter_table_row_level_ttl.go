@@ -0,0 +1,214 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package scbuildstmt
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/storageparam"
+	"github.com/cockroachdb/cockroach/pkg/sql/storageparam/tablestorageparam"
+	"github.com/cockroachdb/cockroach/pkg/sql/ttl/ttlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+// isRowLevelTTLStorageParam returns true if the storage parameter is one which
+// is handled by the declarative schema changer. The deprecated
+// ttl_automatic_column parameter only emits a notice and is left to the
+// legacy schema changer.
+func isRowLevelTTLStorageParam(key string) bool {
+	return key == "ttl" || (strings.HasPrefix(key, "ttl_") && key != "ttl_automatic_column")
+}
+
+func alterTableSetStorageParamsIsSupported(t *tree.AlterTableSetStorageParams) bool {
+	for _, sp := range t.StorageParams {
+		if !isRowLevelTTLStorageParam(string(sp.Key)) {
+			return false
+		}
+	}
+	return true
+}
+
+func alterTableResetStorageParamsIsSupported(t *tree.AlterTableResetStorageParams) bool {
+	for _, p := range t.Params {
+		if !isRowLevelTTLStorageParam(string(p)) {
+			return false
+		}
+	}
+	return true
+}
+
+func alterTableSetStorageParams(
+	b BuildCtx, tn *tree.TableName, tbl *scpb.Table, t *tree.AlterTableSetStorageParams,
+) {
+	if !alterTableSetStorageParamsIsSupported(t) {
+		panic(scerrors.NotImplementedErrorf(t, "only row-level TTL storage parameters are supported"))
+	}
+	alterTableRowLevelTTL(b, tn, tbl, func(setter storageparam.Setter) error {
+		return storageparam.Set(b.SemaCtx(), b.EvalCtx(), t.StorageParams, setter)
+	})
+}
+
+func alterTableResetStorageParams(
+	b BuildCtx, tn *tree.TableName, tbl *scpb.Table, t *tree.AlterTableResetStorageParams,
+) {
+	if !alterTableResetStorageParamsIsSupported(t) {
+		panic(scerrors.NotImplementedErrorf(t, "only row-level TTL storage parameters are supported"))
+	}
+	alterTableRowLevelTTL(b, tn, tbl, func(setter storageparam.Setter) error {
+		return storageparam.Reset(b.EvalCtx(), t.Params, setter)
+	})
+}
+
+// alterTableRowLevelTTL applies the storage parameter changes in apply to the
+// table's current row-level TTL and adds or drops the corresponding elements.
+// Setting ttl_expire_after adds the crdb_internal_expiration column and
+// resetting it drops that column. Modifying an existing TTL configuration
+// replaces its element, which deletes and recreates the scheduled job and
+// updates the expression of the crdb_internal_expiration column if
+// ttl_expire_after changed.
+func alterTableRowLevelTTL(
+	b BuildCtx,
+	tn *tree.TableName,
+	tbl *scpb.Table,
+	apply func(setter storageparam.Setter) error,
+) {
+	// Changes to the row-level TTL can't be combined with other schema changes
+	// on the same table, as is the case in the legacy schema changer.
+	b.QueryByID(tbl.TableID).ForEachElementStatus(func(
+		current scpb.Status, target scpb.TargetStatus, _ scpb.Element,
+	) {
+		if current != target.Status() {
+			panic(pgerror.Newf(
+				pgcode.FeatureNotSupported,
+				"cannot modify TTL settings while another schema change on the table is being processed",
+			))
+		}
+	})
+	var before *scpb.RowLevelTTL
+	publicTargets := b.QueryByID(tbl.TableID).Filter(publicTargetFilter)
+	scpb.ForEachRowLevelTTL(publicTargets, func(
+		_ scpb.Status, _ scpb.TargetStatus, e *scpb.RowLevelTTL,
+	) {
+		before = e
+	})
+
+	// Apply the storage parameters to a scratch descriptor which only carries
+	// the row-level TTL, so that the validation logic is shared with the
+	// legacy schema changer.
+	scratch := tabledesc.NewBuilder(&descpb.TableDescriptor{
+		ID: tbl.TableID,
+	}).BuildCreatedMutableTable()
+	if before != nil {
+		scratch.RowLevelTTL = protoutil.Clone(&before.RowLevelTTL).(*catpb.RowLevelTTL)
+	}
+	if err := apply(tablestorageparam.NewSetter(scratch)); err != nil {
+		panic(err)
+	}
+	after := scratch.GetRowLevelTTL()
+
+	if before == nil && after == nil {
+		return
+	}
+	if before != nil && after != nil && before.RowLevelTTL.Equal(after) {
+		return
+	}
+	addsColumn := after != nil && after.HasDurationExpr() && (before == nil || !before.HasDurationExpr())
+	dropsColumn := before != nil && before.HasDurationExpr() && (after == nil || !after.HasDurationExpr())
+
+	if addsColumn {
+		if elts := b.ResolveColumn(tbl.TableID, colinfo.TTLDefaultExpirationColumnName, ResolveParams{
+			IsExistenceOptional: true,
+			RequiredPrivilege:   privilege.CREATE,
+		}); elts != nil {
+			panic(pgerror.Newf(
+				pgcode.InvalidTableDefinition,
+				"cannot add TTL to table with the %s column already defined",
+				colinfo.TTLDefaultExpirationColumnName,
+			))
+		}
+		def, err := ttlbase.AutomaticColumnDef(after)
+		if err != nil {
+			panic(err)
+		}
+		alterTableAddColumn(b, tn, tbl, &tree.AlterTableAddColumn{ColumnDef: def})
+	}
+	if after != nil && after.HasExpirationExpr() {
+		b.ValidateTTLExpirationExpression(tbl, after)
+	}
+
+	switch {
+	case before == nil:
+		telemetry.Inc(sqltelemetry.RowLevelTTLCreated)
+		b.Add(&scpb.RowLevelTTL{
+			TableID:     tbl.TableID,
+			RowLevelTTL: *after,
+		})
+	case after == nil:
+		telemetry.Inc(sqltelemetry.RowLevelTTLDropped)
+		b.Drop(before)
+	default:
+		// The modified configuration replaces the existing one, along with its
+		// scheduled job, which is recreated with the new deletion cron.
+		after.ScheduleID = 0
+		replacement := &scpb.RowLevelTTL{
+			TableID:     tbl.TableID,
+			RowLevelTTL: *after,
+			SeqNum:      before.SeqNum + 1,
+		}
+		if before.HasDurationExpr() && after.HasDurationExpr() &&
+			before.DurationExpr != after.DurationExpr {
+			replacement.AutomaticColumnExpr = automaticColumnExpression(b, tbl, after)
+		}
+		b.Drop(before)
+		b.Add(replacement)
+	}
+
+	if dropsColumn {
+		dropCol := &tree.AlterTableDropColumn{
+			Column:       colinfo.TTLDefaultExpirationColumnName,
+			IfExists:     true,
+			DropBehavior: tree.DropRestrict,
+		}
+		col, elts, done := resolveColumnForDropColumn(b, tn, tbl, dropCol)
+		if done {
+			return
+		}
+		dropColumn(b, tn, tbl, dropCol, col, elts, dropCol.DropBehavior)
+	}
+}
+
+// automaticColumnExpression returns the typed DEFAULT and ON UPDATE
+// expression of the crdb_internal_expiration column for the given TTL.
+func automaticColumnExpression(
+	b BuildCtx, tbl *scpb.Table, ttl *catpb.RowLevelTTL,
+) *scpb.Expression {
+	def, err := ttlbase.AutomaticColumnDef(ttl)
+	if err != nil {
+		panic(err)
+	}
+	cdd, err := tabledesc.MakeColumnDefDescs(b, def, b.SemaCtx(), b.EvalCtx())
+	if err != nil {
+		panic(err)
+	}
+	return b.WrapExpression(tbl.TableID, cdd.DefaultExpr)
+}
@@ -224,6 +224,10 @@ type TableHelpers interface {
 	// TODO(postamar): make this more low-level instead of consuming an AST
 	ComputedColumnExpression(tbl *scpb.Table, d *tree.ColumnTableDef) tree.Expr
 
+	// ValidateTTLExpirationExpression panics if the ttl_expiration_expression
+	// of the given row-level TTL configuration is not valid for the table.
+	ValidateTTLExpirationExpression(tbl *scpb.Table, ttl *catpb.RowLevelTTL)
+
 	// IsTableEmpty returns if the table is empty or not.
 	IsTableEmpty(tbl *scpb.Table) bool
 }
@@ -12,8 +12,10 @@ package scbuildstmt
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
@@ -61,9 +63,68 @@ func isFullySupported(
 	return false
 }
 
+// forceDeclarativeStatements overrides, statement by statement, whether the
+// declarative schema changer is enabled for the statements it implements.
+var forceDeclarativeStatements = settings.RegisterValidatedStringSetting(
+	settings.TenantWritable,
+	"sql.schema.force_declarative_statements",
+	"comma-separated list of statement tags, e.g. 'CREATE INDEX, !DROP TABLE', for which "+
+		"the declarative schema changer is enabled, or disabled if the tag is prefixed with !, "+
+		"regardless of the default; only applies when use_declarative_schema_changer is 'on'",
+	"", /* defaultValue */
+	func(_ *settings.Values, s string) error {
+		_, err := parseForceDeclarativeStatements(s)
+		return err
+	},
+).WithPublic()
+
+// parseForceDeclarativeStatements parses the value of the
+// sql.schema.force_declarative_statements setting into a map from statement
+// tags to whether the statement is enabled in the declarative schema changer.
+func parseForceDeclarativeStatements(s string) (map[string]bool, error) {
+	forced := make(map[string]bool)
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.Join(strings.Fields(strings.ToUpper(tag)), " ")
+		if tag == "" {
+			continue
+		}
+		enabled := true
+		if strings.HasPrefix(tag, "!") {
+			enabled = false
+			tag = strings.TrimSpace(tag[1:])
+		}
+		if !supportedStatementTags[tag] {
+			return nil, errors.Newf(
+				"statement %q is not implemented by the declarative schema changer", tag)
+		}
+		if prev, ok := forced[tag]; ok && prev != enabled {
+			return nil, errors.Newf("statement %q is both enabled and disabled", tag)
+		}
+		forced[tag] = enabled
+	}
+	return forced, nil
+}
+
+// isOnByDefault returns whether the statement is enabled in the declarative
+// schema changer when it's on, taking into account the overrides of the
+// sql.schema.force_declarative_statements setting.
+func isOnByDefault(sv *settings.Values, n tree.Statement, on bool) bool {
+	forced, err := parseForceDeclarativeStatements(forceDeclarativeStatements.Get(sv))
+	if err != nil {
+		// The setting is validated, this can only happen if the statement
+		// stopped being implemented.
+		return on
+	}
+	if enabled, ok := forced[n.StatementTag()]; ok {
+		return enabled
+	}
+	return on
+}
+
 // Tracks operations which are fully supported when the declarative schema
 // changer is enabled. Operations marked as non-fully supported can only be
-// with the use_declarative_schema_changer session variable.
+// with the use_declarative_schema_changer session variable, or when enabled by
+// the sql.schema.force_declarative_statements cluster setting.
 var supportedStatements = map[reflect.Type]supportedStatement{
 	// Alter table will have commands individually whitelisted via the
 	// supportedAlterTableStatements list, so wwe will consider it fully supported
@@ -87,9 +148,15 @@ var supportedStatements = map[reflect.Type]supportedStatement{
 	reflect.TypeOf((*tree.DropIndex)(nil)): {fn: DropIndex, on: false, minSupportedClusterVersion: clusterversion.Start22_2},
 }
 
+// supportedStatementTags is the set of the tags of the statements in
+// supportedStatements.
+var supportedStatementTags = make(map[string]bool)
+
 func init() {
 	// Check function signatures inside the supportedStatements map.
 	for statementType, statementEntry := range supportedStatements {
+		stmt := reflect.New(statementType.Elem()).Interface().(tree.Statement)
+		supportedStatementTags[stmt.StatementTag()] = true
 		// Validate main callback functions.
 		callBackFnType := reflect.TypeOf(statementEntry.fn)
 		if callBackFnType.Kind() != reflect.Func {
@@ -147,8 +214,9 @@ func Process(b BuildCtx, n tree.Statement) {
 	// Check if partially supported operations are allowed next. If an
 	// operation is not fully supported will not allow it to be run in
 	// the declarative schema changer until its fully supported.
+	on := isOnByDefault(&b.EvalCtx().Settings.SV, n, info.on)
 	if !isFullySupported(
-		n, info.on, info.extraChecks, b.EvalCtx().SessionData().NewSchemaChangerMode,
+		n, on, info.extraChecks, b.EvalCtx().SessionData().NewSchemaChangerMode,
 	) {
 		panic(scerrors.NotImplementedError(n))
 	}
@@ -14,10 +14,12 @@ import (
 	"context"
 	"math"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
@@ -26,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
@@ -34,6 +37,7 @@ import (
 func NewJobRunDependencies(
 	collectionFactory *descs.CollectionFactory,
 	db *kv.DB,
+	internalExecutor sqlutil.InternalExecutor,
 	backfiller scexec.Backfiller,
 	merger scexec.Merger,
 	rangeCounter backfiller.RangeCounter,
@@ -53,6 +57,7 @@ func NewJobRunDependencies(
 	return &jobExecutionDeps{
 		collectionFactory:     collectionFactory,
 		db:                    db,
+		internalExecutor:      internalExecutor,
 		backfiller:            backfiller,
 		merger:                merger,
 		rangeCounter:          rangeCounter,
@@ -74,6 +79,7 @@ func NewJobRunDependencies(
 type jobExecutionDeps struct {
 	collectionFactory     *descs.CollectionFactory
 	db                    *kv.DB
+	internalExecutor      sqlutil.InternalExecutor
 	eventLoggerFactory    func(txn *kv.Txn) scexec.EventLogger
 	statsRefresher        scexec.StatsRefresher
 	backfiller            scexec.Backfiller
@@ -170,3 +176,36 @@ func (d *jobExecutionDeps) WithTxnInJob(ctx context.Context, fn scrun.JobTxnFunc
 	}
 	return nil
 }
+
+// RecordStageExecution implements the scrun.JobRunDependencies interface.
+func (d *jobExecutionDeps) RecordStageExecution(
+	ctx context.Context, exec scrun.StageExecution,
+) error {
+	// The history table may not exist yet in a mixed-version cluster.
+	if !d.settings.Version.IsActive(ctx, clusterversion.SchemaChangeStageHistoryTable) {
+		return nil
+	}
+	var errStr interface{}
+	if exec.Err != nil {
+		errStr = exec.Err.Error()
+	}
+	_, err := d.internalExecutor.ExecEx(
+		ctx,
+		"record-schema-change-stage",
+		nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: username.RootUserName()},
+		`INSERT INTO system.schema_change_stage_history (
+	timestamp, job_id, description, phase, stage_ordinal, stages_in_phase, started, attempts, error
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		exec.Finished,
+		d.job.ID(),
+		d.job.Payload().Description,
+		exec.Phase.String(),
+		exec.Ordinal,
+		exec.StagesInPhase,
+		exec.Started,
+		exec.Attempts,
+		errStr,
+	)
+	return err
+}
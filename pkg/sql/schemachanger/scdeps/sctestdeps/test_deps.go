@@ -949,6 +949,11 @@ func (s *TestState) WithTxnInJob(ctx context.Context, fn scrun.JobTxnFunc) (err
 	return err
 }
 
+// RecordStageExecution implements the scrun.JobRunDependencies interface.
+func (s *TestState) RecordStageExecution(context.Context, scrun.StageExecution) error {
+	return nil
+}
+
 // ValidateForwardIndexes implements the index validator interface.
 func (s *TestState) ValidateForwardIndexes(
 	_ context.Context,
@@ -1070,6 +1075,16 @@ func (s *TestState) DeleteSchedule(ctx context.Context, id int64) error {
 	return nil
 }
 
+// CreateRowLevelTTLSchedule implements scexec.DescriptorMetadataUpdater
+func (s *TestState) CreateRowLevelTTLSchedule(
+	ctx context.Context, owner username.SQLUsername, tableID descpb.ID, ttl *catpb.RowLevelTTL,
+) (int64, error) {
+	s.scheduleCounter++
+	s.LogSideEffectf("create row-level TTL scheduleId: %d for table #%d owned by %s with cron %q",
+		s.scheduleCounter, tableID, owner, ttl.DeletionCronOrDefault())
+	return s.scheduleCounter, nil
+}
+
 // DeleteZoneConfig implements scexec.DescriptorMetadataUpdater.
 func (s *TestState) DeleteZoneConfig(
 	ctx context.Context, id descpb.ID,
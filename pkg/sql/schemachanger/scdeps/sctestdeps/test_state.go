@@ -69,6 +69,7 @@ type TestState struct {
 	jobs                    []jobs.Record
 	createdJobsInCurrentTxn []jobspb.JobID
 	jobCounter              int
+	scheduleCounter         int64
 	txnCounter              int
 	sideEffectLogBuffer     strings.Builder
 
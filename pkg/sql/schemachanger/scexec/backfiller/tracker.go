@@ -59,6 +59,19 @@ type Tracker struct {
 
 var _ scexec.BackfillerTracker = (*Tracker)(nil)
 
+// The progress of a schema change job is reported in two stages: the
+// backfills of the indexes, followed by the merges of the temporary indexes
+// into them. Merges usually involve a lot less data than backfills.
+const (
+	backfillProgressStage = "backfilling indexes"
+	mergeProgressStage    = "merging indexes"
+)
+
+var progressStages = []jobs.ProgressStage{
+	{Name: backfillProgressStage, Weight: 0.8},
+	{Name: mergeProgressStage, Weight: 0.2},
+}
+
 // NewTracker constructs a new Tracker.
 func NewTracker(
 	codec keys.SQLCodec,
@@ -98,11 +111,12 @@ func newTracker(
 }
 
 func newTrackerConfig(codec keys.SQLCodec, rc RangeCounter, job *jobs.Job) trackerConfig {
+	progress := jobs.NewStagedProgress(job, progressStages...)
 	return trackerConfig{
 		numRangesInSpanContainedBy: rc.NumRangesInSpanContainedBy,
-		writeProgressFraction: func(ctx context.Context, fractionProgressed float32) error {
-			if err := job.FractionProgressed(
-				ctx, nil /* txn */, jobs.FractionUpdater(fractionProgressed),
+		writeProgressFraction: func(ctx context.Context, stage string, fractionProgressed float32) error {
+			if err := progress.SetStageFraction(
+				ctx, stage, fractionProgressed, jobs.ProgressUpdateOnly,
 			); err != nil {
 				return jobs.SimplifyInvalidStatusError(err)
 			}
@@ -143,8 +157,9 @@ type trackerConfig struct {
 		context.Context, roachpb.Span, []roachpb.Span,
 	) (total, contained int, _ error)
 
-	// writeProgressFraction writes the backfillProgress fraction for presentation.
-	writeProgressFraction func(_ context.Context, fractionProgressed float32) error
+	// writeProgressFraction writes the fraction completed of the given
+	// progress stage for presentation.
+	writeProgressFraction func(_ context.Context, stage string, fractionProgressed float32) error
 
 	// writeCheckpoint write the checkpoint the underlying store.
 	writeCheckpoint func(context.Context, []scexec.BackfillProgress, []scexec.MergeProgress) error
@@ -220,11 +235,11 @@ func (b *Tracker) SetMergeProgress(ctx context.Context, progress scexec.MergePro
 
 // FlushFractionCompleted is part of the scexec.BackfillerProgressFlusher interface.
 func (b *Tracker) FlushFractionCompleted(ctx context.Context) error {
-	updated, fractionRangesFinished, err := b.getFractionRangesFinished(ctx)
+	updated, stage, fractionRangesFinished, err := b.getFractionRangesFinished(ctx)
 	if err != nil || !updated {
 		return err
 	}
-	return b.writeProgressFraction(ctx, fractionRangesFinished)
+	return b.writeProgressFraction(ctx, stage, fractionRangesFinished)
 }
 
 // FlushCheckpoint is part of the scexec.BackfillerProgressFlusher interface.
@@ -322,35 +337,35 @@ func (b *Tracker) getTableIndexMergeProgressLocked(m scexec.Merge) (*mergeProgre
 
 // getFractionRangesFinished will compute the fraction of ranges finished
 // relative to the set of ranges in each backfill or merge being tracked since
-// the tracker was constructed. It is not adjusted to deal with
-// origFractionCompleted. If updated is false, no usable fraction is
-// returned.
+// the tracker was constructed, for the current progress stage: the merge
+// stage if any merge is being tracked, the backfill stage otherwise. If
+// updated is false, no usable fraction is returned.
 //
 // The computation of the fraction works by seeing how many ranges remain
-// for each backfill and for each merge  and comparing that to the initial
-// calculation of the number of ranges for the backfill and merges as computed
+// for each backfill or for each merge and comparing that to the initial
+// calculation of the number of ranges for the backfill or merges as computed
 // by this function.
 func (b *Tracker) getFractionRangesFinished(
 	ctx context.Context,
-) (updated bool, _ float32, _ error) {
-	needsFlush, progresses := b.collectFractionProgressSpansForFlush()
+) (updated bool, stage string, _ float32, _ error) {
+	needsFlush, stage, progresses := b.collectFractionProgressSpansForFlush()
 	if !needsFlush {
-		return false, 0, nil
+		return false, "", 0, nil
 	}
 	var totalRanges int
 	var completedRanges int
 	for _, p := range progresses {
 		total, completed, err := b.numRangesInSpanContainedBy(ctx, p.total, p.completed)
 		if err != nil {
-			return false, 0, err
+			return false, "", 0, err
 		}
 		totalRanges += total
 		completedRanges += completed
 	}
 	if totalRanges == 0 {
-		return true, 0, nil
+		return true, stage, 0, nil
 	}
-	return true, float32(completedRanges) / float32(totalRanges), nil
+	return true, stage, float32(completedRanges) / float32(totalRanges), nil
 }
 
 type fractionProgressSpans struct {
@@ -360,6 +375,7 @@ type fractionProgressSpans struct {
 
 func (b *Tracker) collectFractionProgressSpansForFlush() (
 	needsFlush bool,
+	stage string,
 	progress []fractionProgressSpans,
 ) {
 	b.mu.Lock()
@@ -371,26 +387,34 @@ func (b *Tracker) collectFractionProgressSpansForFlush() (
 		needsFlush = needsFlush || p.needsFractionFlush
 	}
 	if !needsFlush {
-		return false, nil
+		return false, "", nil
 	}
-	progress = make([]fractionProgressSpans, 0, len(b.mu.backfillProgress)+len(b.mu.mergeProgress))
 	for _, p := range b.mu.backfillProgress {
 		p.needsFractionFlush = false
-		progress = append(progress, fractionProgressSpans{
-			total:     p.totalSpan,
-			completed: p.CompletedSpans,
-		})
 	}
 	for _, p := range b.mu.mergeProgress {
 		p.needsFractionFlush = false
-		for i, s := range p.totalSpans {
-			progress = append(progress, fractionProgressSpans{
-				total:     s,
-				completed: p.CompletedSpans[i],
-			})
+	}
+	// The merges only start once the backfills are done.
+	if len(b.mu.mergeProgress) > 0 {
+		for _, p := range b.mu.mergeProgress {
+			for i, s := range p.totalSpans {
+				progress = append(progress, fractionProgressSpans{
+					total:     s,
+					completed: p.CompletedSpans[i],
+				})
+			}
 		}
+		return true, mergeProgressStage, progress
+	}
+	progress = make([]fractionProgressSpans, 0, len(b.mu.backfillProgress))
+	for _, p := range b.mu.backfillProgress {
+		progress = append(progress, fractionProgressSpans{
+			total:     p.totalSpan,
+			completed: p.CompletedSpans,
+		})
 	}
-	return true, progress
+	return true, backfillProgressStage, progress
 }
 
 func (b *Tracker) collectProgressForCheckpointFlush() (
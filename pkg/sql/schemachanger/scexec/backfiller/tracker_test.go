@@ -177,6 +177,7 @@ func TestBackfillerTracker(t *testing.T) {
 			require.NoError(t, tr.FlushFractionCompleted(ctx))
 			// No we see that the denominator has changed to 3/15
 			require.EqualValues(t, float32(.2), bts.getFraction())
+			require.Equal(t, backfillProgressStage, bts.getStage())
 			require.EqualValues(t, 1, bts.getFractionUpdatedCalls())
 		})
 		updatedProgress2 := scexec.BackfillProgress{
@@ -300,6 +301,7 @@ func TestBackfillerTracker(t *testing.T) {
 			require.NoError(t, tr.FlushFractionCompleted(ctx))
 			// Now we see that the denominator has changed.
 			require.EqualValues(t, float32(.15), bts.getFraction())
+			require.Equal(t, mergeProgressStage, bts.getStage())
 			require.EqualValues(t, 1, bts.getFractionUpdatedCalls())
 		})
 		updatedProgress2 := scexec.MergeProgress{
@@ -338,6 +340,7 @@ type backfillerTrackerTestState struct {
 	mu struct {
 		syncutil.Mutex
 		rangeSpans             []roachpb.Span
+		stage                  string
 		fraction               float32
 		fractionUpdatedCalls   int
 		backfillCheckpoint     []scexec.BackfillProgress
@@ -373,10 +376,11 @@ func (bts *backfillerTrackerTestState) numRangesInSpans(
 }
 
 func (bts *backfillerTrackerTestState) writeProgressFraction(
-	_ context.Context, fractionProgressed float32,
+	_ context.Context, stage string, fractionProgressed float32,
 ) error {
 	bts.mu.Lock()
 	defer bts.mu.Unlock()
+	bts.mu.stage = stage
 	bts.mu.fraction = fractionProgressed
 	bts.mu.fractionUpdatedCalls++
 	return nil
@@ -411,6 +415,12 @@ func (bts *backfillerTrackerTestState) getFraction() float32 {
 	return bts.mu.fraction
 }
 
+func (bts *backfillerTrackerTestState) getStage() string {
+	bts.mu.Lock()
+	defer bts.mu.Unlock()
+	return bts.mu.stage
+}
+
 func (bts *backfillerTrackerTestState) getFractionUpdatedCalls() int {
 	bts.mu.Lock()
 	defer bts.mu.Unlock()
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scexec/scmutationexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
@@ -358,6 +359,12 @@ type DescriptorMetadataUpdater interface {
 	// DeleteSchedule deletes the given schedule.
 	DeleteSchedule(ctx context.Context, id int64) error
 
+	// CreateRowLevelTTLSchedule creates the scheduled job which deletes the
+	// expired rows of the given table, and returns its ID.
+	CreateRowLevelTTLSchedule(
+		ctx context.Context, owner username.SQLUsername, tableID descpb.ID, ttl *catpb.RowLevelTTL,
+	) (scheduleID int64, err error)
+
 	// UpsertZoneConfig sets the zone config for a given descriptor. If necessary,
 	// the subzone spans will be recomputed as part of this call.
 	UpsertZoneConfig(
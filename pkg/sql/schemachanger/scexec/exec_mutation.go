@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/nstree"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scexec/scmutationexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scop"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
@@ -45,6 +46,14 @@ func executeDescriptorMutationOps(ctx context.Context, deps Dependencies, ops []
 		}
 	}
 
+	// The IDs of newly-created row-level TTL schedules are stored in the table
+	// descriptors, so these need to be created before the catalog writes.
+	if err := createRowLevelTTLSchedules(
+		ctx, mvs, deps.DescriptorMetadataUpdater(ctx), deps.User(),
+	); err != nil {
+		return err
+	}
+
 	// Note that we perform the catalog writes first in order to acquire locks
 	// on the descriptors in question as early as possible. If a restart is
 	// encountered, these locks will be retained in subsequent epochs (assuming
@@ -250,6 +259,32 @@ func eventLogEntriesForStatement(statementEvents []eventPayload) (logEntries []e
 	return logEntries
 }
 
+// createRowLevelTTLSchedules creates the scheduled jobs of the tables which
+// had row-level TTL added and records their IDs in the table descriptors.
+// The schedules are owned by the user executing the schema change, as they
+// are in the legacy schema changer.
+func createRowLevelTTLSchedules(
+	ctx context.Context,
+	mvs *mutationVisitorState,
+	m DescriptorMetadataUpdater,
+	user username.SQLUsername,
+) error {
+	for _, id := range mvs.ttlSchedulesToCreate.Ordered() {
+		tbl, ok := mvs.modifiedDescriptors.Get(id).(*tabledesc.Mutable)
+		if !ok || tbl.RowLevelTTL == nil {
+			return errors.AssertionFailedf("expected table %d to have row-level TTL", id)
+		}
+		scheduleID, err := m.CreateRowLevelTTLSchedule(
+			ctx, user, tbl.GetID(), tbl.RowLevelTTL,
+		)
+		if err != nil {
+			return err
+		}
+		tbl.RowLevelTTL.ScheduleID = scheduleID
+	}
+	return nil
+}
+
 // updateDescriptorMetadata performs the portions of the side effects of the
 // operations delegated to the DescriptorMetadataUpdater.
 func updateDescriptorMetadata(
@@ -400,6 +435,7 @@ type mutationVisitorState struct {
 	schemaChangerJobUpdates      map[jobspb.JobID]schemaChangerJobUpdate
 	eventsByStatement            map[uint32][]eventPayload
 	scheduleIDsToDelete          []int64
+	ttlSchedulesToCreate         catalog.DescriptorIDSet
 	statsToRefresh               map[descpb.ID]struct{}
 	gcJobs
 }
@@ -550,6 +586,10 @@ func (mvs *mutationVisitorState) DeleteSchedule(scheduleID int64) {
 	mvs.scheduleIDsToDelete = append(mvs.scheduleIDsToDelete, scheduleID)
 }
 
+func (mvs *mutationVisitorState) CreateRowLevelTTLSchedule(tableID descpb.ID) {
+	mvs.ttlSchedulesToCreate.Add(tableID)
+}
+
 func (mvs *mutationVisitorState) RefreshStats(descriptorID descpb.ID) {
 	mvs.statsToRefresh[descriptorID] = struct{}{}
 }
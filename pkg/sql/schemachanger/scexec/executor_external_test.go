@@ -554,6 +554,13 @@ func (noopMetadataUpdater) DeleteSchedule(ctx context.Context, scheduleID int64)
 	return nil
 }
 
+// CreateRowLevelTTLSchedule implements scexec.DescriptorMetadataUpdater
+func (noopMetadataUpdater) CreateRowLevelTTLSchedule(
+	ctx context.Context, owner username.SQLUsername, tableID descpb.ID, ttl *catpb.RowLevelTTL,
+) (int64, error) {
+	return 0, nil
+}
+
 // DeleteZoneConfig implements scexec.DescriptorMetadataUpdater
 func (noopMetadataUpdater) DeleteZoneConfig(
 	ctx context.Context, id descpb.ID,
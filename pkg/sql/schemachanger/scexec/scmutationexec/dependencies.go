@@ -128,6 +128,10 @@ type MutationVisitorStateUpdater interface {
 	// DeleteSchedule deletes a scheduled job.
 	DeleteSchedule(scheduleID int64)
 
+	// CreateRowLevelTTLSchedule creates the row-level TTL scheduled job of the
+	// given table and records its ID in the table descriptor.
+	CreateRowLevelTTLSchedule(tableID descpb.ID)
+
 	// RefreshStats refresh stats for a given descriptor.
 	RefreshStats(id descpb.ID)
 }
@@ -168,10 +168,3 @@ func (m *visitor) RemoveUserPrivileges(ctx context.Context, op scop.RemoveUserPr
 	desc.GetPrivileges().RemoveUser(user)
 	return nil
 }
-
-func (m *visitor) DeleteSchedule(_ context.Context, op scop.DeleteSchedule) error {
-	if op.ScheduleID != 0 {
-		m.s.DeleteSchedule(op.ScheduleID)
-	}
-	return nil
-}
@@ -0,0 +1,71 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package scmutationexec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scop"
+	"github.com/cockroachdb/errors"
+)
+
+func (m *visitor) UpsertRowLevelTTL(ctx context.Context, op scop.UpsertRowLevelTTL) error {
+	tbl, err := m.checkOutTable(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	ttl := op.RowLevelTTL
+	if tbl.RowLevelTTL != nil && ttl.ScheduleID == 0 {
+		// Retain the scheduled job of the existing configuration.
+		ttl.ScheduleID = tbl.RowLevelTTL.ScheduleID
+	}
+	tbl.RowLevelTTL = &ttl
+	if op.AutomaticColumnExpr != "" {
+		col, err := tbl.FindColumnWithName(colinfo.TTLDefaultExpirationColumnName)
+		if err != nil {
+			return err
+		}
+		defaultExpr := string(op.AutomaticColumnExpr)
+		onUpdateExpr := string(op.AutomaticColumnExpr)
+		col.ColumnDesc().DefaultExpr = &defaultExpr
+		col.ColumnDesc().OnUpdateExpr = &onUpdateExpr
+	}
+	return nil
+}
+
+func (m *visitor) CreateRowLevelTTLSchedule(
+	ctx context.Context, op scop.CreateRowLevelTTLSchedule,
+) error {
+	tbl, err := m.checkOutTable(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	if tbl.RowLevelTTL == nil {
+		return errors.AssertionFailedf("table %q (%d) has no row-level TTL", tbl.GetName(), tbl.GetID())
+	}
+	if tbl.RowLevelTTL.ScheduleID == 0 {
+		m.s.CreateRowLevelTTLSchedule(tbl.GetID())
+	}
+	return nil
+}
+
+func (m *visitor) RemoveRowLevelTTL(ctx context.Context, op scop.RemoveRowLevelTTL) error {
+	tbl, err := m.checkOutTable(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	if ttl := tbl.RowLevelTTL; ttl != nil && ttl.ScheduleID != 0 {
+		m.s.DeleteSchedule(ttl.ScheduleID)
+	}
+	tbl.RowLevelTTL = nil
+	return nil
+}
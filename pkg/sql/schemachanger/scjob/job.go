@@ -70,6 +70,7 @@ func (n *newSchemaChangeResumer) run(ctx context.Context, execCtxI interface{})
 	deps := scdeps.NewJobRunDependencies(
 		execCfg.CollectionFactory,
 		execCfg.DB,
+		execCfg.InternalExecutor,
 		execCfg.IndexBackfiller,
 		execCfg.IndexMerger,
 		NewRangeCounter(execCfg.DB, execCfg.DistSQLPlanner),
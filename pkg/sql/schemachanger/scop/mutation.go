@@ -567,10 +567,28 @@ type RemoveUserPrivileges struct {
 	User   string
 }
 
-// DeleteSchedule is used to delete a schedule ID from the database.
-type DeleteSchedule struct {
+// UpsertRowLevelTTL is used to set the row-level TTL configuration of a
+// table. If AutomaticColumnExpr is set, it also replaces the DEFAULT and
+// ON UPDATE expressions of the crdb_internal_expiration column.
+type UpsertRowLevelTTL struct {
 	mutationOp
-	ScheduleID int64
+	TableID             descpb.ID
+	RowLevelTTL         catpb.RowLevelTTL
+	AutomaticColumnExpr catpb.Expression
+}
+
+// CreateRowLevelTTLSchedule is used to create the scheduled job which deletes
+// expired rows from a table with row-level TTL, if it doesn't have one yet.
+type CreateRowLevelTTLSchedule struct {
+	mutationOp
+	TableID descpb.ID
+}
+
+// RemoveRowLevelTTL is used to remove the row-level TTL configuration of a
+// table as well as its scheduled job.
+type RemoveRowLevelTTL struct {
+	mutationOp
+	TableID descpb.ID
 }
 
 // RefreshStats is used to queue a table for stats refresh.
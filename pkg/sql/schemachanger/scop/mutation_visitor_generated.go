@@ -91,7 +91,9 @@ type MutationVisitor interface {
 	RemoveConstraintComment(context.Context, RemoveConstraintComment) error
 	RemoveDatabaseRoleSettings(context.Context, RemoveDatabaseRoleSettings) error
 	RemoveUserPrivileges(context.Context, RemoveUserPrivileges) error
-	DeleteSchedule(context.Context, DeleteSchedule) error
+	UpsertRowLevelTTL(context.Context, UpsertRowLevelTTL) error
+	CreateRowLevelTTLSchedule(context.Context, CreateRowLevelTTLSchedule) error
+	RemoveRowLevelTTL(context.Context, RemoveRowLevelTTL) error
 	RefreshStats(context.Context, RefreshStats) error
 	AddColumnToIndex(context.Context, AddColumnToIndex) error
 	RemoveColumnFromIndex(context.Context, RemoveColumnFromIndex) error
@@ -443,8 +445,18 @@ func (op RemoveUserPrivileges) Visit(ctx context.Context, v MutationVisitor) err
 }
 
 // Visit is part of the MutationOp interface.
-func (op DeleteSchedule) Visit(ctx context.Context, v MutationVisitor) error {
-	return v.DeleteSchedule(ctx, op)
+func (op UpsertRowLevelTTL) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.UpsertRowLevelTTL(ctx, op)
+}
+
+// Visit is part of the MutationOp interface.
+func (op CreateRowLevelTTLSchedule) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.CreateRowLevelTTLSchedule(ctx, op)
+}
+
+// Visit is part of the MutationOp interface.
+func (op RemoveRowLevelTTL) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.RemoveRowLevelTTL(ctx, op)
 }
 
 // Visit is part of the MutationOp interface.
@@ -13,6 +13,7 @@ package scpb
 import (
 	"sort"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/protoreflect"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
 )
@@ -82,7 +83,7 @@ type Element interface {
 	element()
 }
 
-//go:generate go run element_generator.go --in elements.proto --out elements_generated.go
+//go:generate go run ../../../gen/protooneof --in elements.proto --out elements_generated.go --message ElementProto --package scpb --iface Element --marker element --iterator ElementStatusIterator --for-each ForEachElementStatus --iterator-args "current Status, target TargetStatus"
 //go:generate go run element_uml_generator.go --out uml/table.puml
 
 // Element returns an Element from its wrapper for serialization.
@@ -201,3 +202,8 @@ func (s stmtsAndRanks) Swap(i, j int) {
 }
 
 var _ sort.Interface = (*stmtsAndRanks)(nil)
+
+func init() {
+	protoreflect.RegisterShorthands((*TargetState)(nil), "target_state", "scpb_target_state")
+	protoreflect.RegisterShorthands((*DescriptorState)(nil), "descriptor_state", "scpb_descriptor_state")
+}
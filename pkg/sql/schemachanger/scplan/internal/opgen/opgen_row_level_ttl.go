@@ -20,8 +20,20 @@ func init() {
 		toPublic(
 			scpb.Status_ABSENT,
 			to(scpb.Status_PUBLIC,
-				emit(func(this *scpb.RowLevelTTL) *scop.NotImplemented {
-					return notImplemented(this)
+				emit(func(this *scpb.RowLevelTTL) *scop.UpsertRowLevelTTL {
+					op := &scop.UpsertRowLevelTTL{
+						TableID:     this.TableID,
+						RowLevelTTL: this.RowLevelTTL,
+					}
+					if this.AutomaticColumnExpr != nil {
+						op.AutomaticColumnExpr = this.AutomaticColumnExpr.Expr
+					}
+					return op
+				}),
+				emit(func(this *scpb.RowLevelTTL) *scop.CreateRowLevelTTLSchedule {
+					return &scop.CreateRowLevelTTLSchedule{
+						TableID: this.TableID,
+					}
 				}),
 			),
 		),
@@ -30,9 +42,9 @@ func init() {
 			to(scpb.Status_ABSENT,
 				// TODO(postamar): remove revertibility constraint when possible
 				revertible(false),
-				emit(func(this *scpb.RowLevelTTL) *scop.DeleteSchedule {
-					return &scop.DeleteSchedule{
-						ScheduleID: this.RowLevelTTL.ScheduleID,
+				emit(func(this *scpb.RowLevelTTL) *scop.RemoveRowLevelTTL {
+					return &scop.RemoveRowLevelTTL{
+						TableID: this.TableID,
 					}
 				}),
 			),
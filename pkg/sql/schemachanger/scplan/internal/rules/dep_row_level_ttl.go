@@ -0,0 +1,72 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rules
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/rel"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scplan/internal/scgraph"
+)
+
+// These rules ensure that the row-level TTL job of a table only ever sees
+// public columns: the automatic expiration column and any column referenced
+// by the expiration expression.
+func init() {
+
+	registerDepRule(
+		"row-level TTL set after columns are public",
+		scgraph.Precedence,
+		"column", "row-level-ttl",
+		func(from, to nodeVars) rel.Clauses {
+			return rel.Clauses{
+				from.Type((*scpb.Column)(nil)),
+				to.Type((*scpb.RowLevelTTL)(nil)),
+				joinOnDescID(from, to, "table-id"),
+				statusesToPublicOrTransient(from, scpb.Status_PUBLIC, to, scpb.Status_PUBLIC),
+			}
+		},
+	)
+
+	registerDepRule(
+		"row-level TTL removed before columns are dropped",
+		scgraph.Precedence,
+		"row-level-ttl", "column",
+		func(from, to nodeVars) rel.Clauses {
+			return rel.Clauses{
+				from.Type((*scpb.RowLevelTTL)(nil)),
+				to.Type((*scpb.Column)(nil)),
+				joinOnDescID(from, to, "table-id"),
+				statusesToAbsent(from, scpb.Status_ABSENT, to, scpb.Status_DELETE_ONLY),
+			}
+		},
+	)
+
+	// When the row-level TTL of a table is modified, the new configuration
+	// takes the place of the old one in the same transaction, so that the
+	// table is never left without a TTL and its scheduled job is replaced
+	// atomically.
+	registerDepRule(
+		"row-level TTL replaced in the same stage",
+		scgraph.SameStagePrecedence,
+		"old-row-level-ttl", "new-row-level-ttl",
+		func(from, to nodeVars) rel.Clauses {
+			return rel.Clauses{
+				from.Type((*scpb.RowLevelTTL)(nil)),
+				to.Type((*scpb.RowLevelTTL)(nil)),
+				joinOnDescID(from, to, "table-id"),
+				from.targetStatus(scpb.ToAbsent),
+				from.currentStatus(scpb.Status_ABSENT),
+				to.targetStatus(scpb.ToPublic),
+				to.currentStatus(scpb.Status_PUBLIC),
+			}
+		},
+	)
+}
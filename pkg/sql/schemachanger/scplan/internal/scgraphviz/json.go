@@ -0,0 +1,170 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package scgraphviz
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scop"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scplan/internal/scgraph"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scplan/internal/scstage"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/screl"
+)
+
+// planJSON is the JSON representation of a plan, as produced by EncodeJSON.
+// Nodes of the graph are identified by their target index and status, in the
+// same way as in the graphviz renderings.
+type planJSON struct {
+	Statements []string      `json:"statements"`
+	Targets    []targetJSON  `json:"targets"`
+	DepEdges   []depEdgeJSON `json:"dep_edges"`
+	OpEdges    []opEdgeJSON  `json:"op_edges"`
+	Stages     []stageJSON   `json:"stages"`
+}
+
+type targetJSON struct {
+	Index         int         `json:"index"`
+	Element       string      `json:"element"`
+	Attributes    interface{} `json:"attributes"`
+	CurrentStatus string      `json:"current_status"`
+	TargetStatus  string      `json:"target_status"`
+}
+
+type depEdgeJSON struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kind  string   `json:"kind"`
+	Rules []string `json:"rules"`
+}
+
+type opEdgeJSON struct {
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Ops        []opJSON `json:"ops"`
+	Revertible bool     `json:"revertible"`
+	NoOp       bool     `json:"no_op"`
+}
+
+type opJSON struct {
+	Type   string      `json:"type"`
+	Fields interface{} `json:"fields,omitempty"`
+}
+
+type stageJSON struct {
+	Stage       string           `json:"stage"`
+	Transitions []transitionJSON `json:"transitions"`
+	Ops         []opJSON         `json:"ops"`
+}
+
+type transitionJSON struct {
+	Target int    `json:"target"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// EncodeJSON returns a JSON document describing the elements, the dependency
+// and op edges of the graph used to build the plan, and the assignment of
+// the op edges to stages.
+func EncodeJSON(cs scpb.CurrentState, g *scgraph.Graph, stages []scstage.Stage) ([]byte, error) {
+	var p planJSON
+	for _, stmt := range cs.TargetState.Statements {
+		p.Statements = append(p.Statements, stmt.Statement)
+	}
+	targetIdxMap := make(map[*scpb.Target]int, len(cs.TargetState.Targets))
+	for i := range cs.TargetState.Targets {
+		t := &cs.TargetState.Targets[i]
+		targetIdxMap[t] = i
+		attrs, err := ToMap(t.Element())
+		if err != nil {
+			return nil, err
+		}
+		p.Targets = append(p.Targets, targetJSON{
+			Index:         i,
+			Element:       screl.ElementString(t.Element()),
+			Attributes:    attrs,
+			CurrentStatus: cs.Current[i].String(),
+			TargetStatus:  t.TargetStatus.String(),
+		})
+	}
+	nodeID := func(n *screl.Node) string {
+		return targetStatusID(targetIdxMap[n.Target], n.CurrentStatus)
+	}
+
+	if g != nil {
+		if err := g.ForEachEdge(func(e scgraph.Edge) error {
+			switch e := e.(type) {
+			case *scgraph.OpEdge:
+				ops, err := opsJSON(e.Op())
+				if err != nil {
+					return err
+				}
+				p.OpEdges = append(p.OpEdges, opEdgeJSON{
+					From:       nodeID(e.From()),
+					To:         nodeID(e.To()),
+					Ops:        ops,
+					Revertible: e.Revertible(),
+					NoOp:       g.IsNoOp(e),
+				})
+			case *scgraph.DepEdge:
+				de := depEdgeJSON{
+					From: nodeID(e.From()),
+					To:   nodeID(e.To()),
+					Kind: e.Kind().String(),
+				}
+				for _, r := range e.RuleNames() {
+					de.Rules = append(de.Rules, string(r))
+				}
+				p.DepEdges = append(p.DepEdges, de)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range stages {
+		ops, err := opsJSON(s.Ops())
+		if err != nil {
+			return nil, err
+		}
+		sj := stageJSON{Stage: s.String(), Ops: ops}
+		for i := range s.After {
+			if s.Before[i] == s.After[i] {
+				continue
+			}
+			sj.Transitions = append(sj.Transitions, transitionJSON{
+				Target: i,
+				Before: s.Before[i].String(),
+				After:  s.After[i].String(),
+			})
+		}
+		p.Stages = append(p.Stages, sj)
+	}
+	return json.MarshalIndent(&p, "", "  ")
+}
+
+func opsJSON(ops []scop.Op) ([]opJSON, error) {
+	ret := make([]opJSON, 0, len(ops))
+	for _, op := range ops {
+		fields, err := ToMap(op)
+		if err != nil {
+			return nil, err
+		}
+		t := reflect.TypeOf(op)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		ret = append(ret, opJSON{Type: t.Name(), Fields: fields})
+	}
+	return ret, nil
+}
@@ -76,6 +76,23 @@ func (p Plan) StagesURL() (string, error) {
 	return scgraphviz.StagesURL(p.CurrentState, p.Graph, p.Stages)
 }
 
+// DependenciesDOT returns a graphviz rendering of the dependency graph in the
+// Plan.
+func (p Plan) DependenciesDOT() (string, error) {
+	return scgraphviz.DrawDependencies(p.CurrentState, p.Graph)
+}
+
+// StagesDOT returns a graphviz rendering of the stages in the Plan.
+func (p Plan) StagesDOT() (string, error) {
+	return scgraphviz.DrawStages(p.CurrentState, p.Graph, p.Stages)
+}
+
+// GraphJSON returns a JSON document describing the elements, dependency and
+// op edges of the graph in the Plan, and the assignment of ops to stages.
+func (p Plan) GraphJSON() ([]byte, error) {
+	return scgraphviz.EncodeJSON(p.CurrentState, p.Graph, p.Stages)
+}
+
 // ExplainViz returns graphviz renderings for EXPLAIN (DDL, VIZ) statements.
 func (p Plan) ExplainViz() (stagesURL, depsURL string, err error) {
 	stagesURL, err = p.StagesURL()
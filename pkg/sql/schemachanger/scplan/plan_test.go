@@ -13,6 +13,7 @@ package scplan_test
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -125,6 +126,11 @@ func TestPlanDataDriven(t *testing.T) {
 // represents the plan that actually gets executed in the various execution
 // phases.
 func validatePlan(t *testing.T, plan *scplan.Plan) {
+	// The plan can be dumped as JSON, as done in debug zips.
+	j, err := plan.GraphJSON()
+	require.NoError(t, err)
+	require.True(t, json.Valid(j))
+
 	stages := plan.Stages
 	for i, stage := range stages {
 		expected := make([]scstage.Stage, len(stages[i:]))
@@ -68,6 +68,9 @@ const (
 	// SourceIndexID is the index ID of the source index for a newly created
 	// index.
 	SourceIndexID
+	// SeqNum is the sequence number of an element which replaces another
+	// element of the same type with otherwise identical attributes.
+	SeqNum
 
 	// TargetStatus is the target status of an element.
 	TargetStatus
@@ -169,6 +172,7 @@ var elementSchemaOptions = []rel.SchemaOption{
 	),
 	rel.EntityMapping(t((*scpb.RowLevelTTL)(nil)),
 		rel.EntityAttr(DescID, "TableID"),
+		rel.EntityAttr(SeqNum, "SeqNum"),
 	),
 	// Multi-region elements.
 	rel.EntityMapping(t((*scpb.TableLocalityGlobal)(nil)),
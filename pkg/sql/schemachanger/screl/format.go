@@ -68,6 +68,10 @@ func FormatElement(w redact.SafeWriter, e scpb.Element) (err error) {
 			if value == descpb.IndexID(0) {
 				return nil
 			}
+		case SeqNum:
+			if value == uint32(0) {
+				return nil
+			}
 		}
 		if written > 0 {
 			w.SafeString(", ")
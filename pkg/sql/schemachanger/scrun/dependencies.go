@@ -12,8 +12,10 @@ package scrun
 
 import (
 	"context"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scop"
 )
 
 // JobTxnFunc is used to run a transactional stage of a schema change on
@@ -27,4 +29,24 @@ type JobRunDependencies interface {
 	// the execution of the callback. After committing the transaction, the job
 	// registry should be notified to adopt jobs.
 	WithTxnInJob(ctx context.Context, fn JobTxnFunc) error
+
+	// RecordStageExecution persists the outcome of the execution of a
+	// post-commit stage of the schema change, for later inspection.
+	RecordStageExecution(ctx context.Context, exec StageExecution) error
+}
+
+// StageExecution describes the execution of a post-commit stage by a
+// declarative schema change job.
+type StageExecution struct {
+	// Phase, Ordinal and StagesInPhase identify the stage within the plan.
+	Phase         scop.Phase
+	Ordinal       int
+	StagesInPhase int
+	// Started and Finished bound the execution of the stage, including
+	// all its attempts.
+	Started, Finished time.Time
+	// Attempts is the number of times the stage's transaction was run.
+	Attempts int
+	// Err is the error with which the stage failed, if any.
+	Err error
 }
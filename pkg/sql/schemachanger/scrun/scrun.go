@@ -125,14 +125,24 @@ func RunSchemaChangesInJob(
 
 	for i := range sc.Stages {
 		// Execute each stage in its own transaction.
-		if err := deps.WithTxnInJob(ctx, func(ctx context.Context, td scexec.Dependencies) error {
+		exec := StageExecution{
+			Phase:         sc.Stages[i].Phase,
+			Ordinal:       sc.Stages[i].Ordinal,
+			StagesInPhase: sc.Stages[i].StagesInPhase,
+			Started:       timeutil.Now(),
+		}
+		err := deps.WithTxnInJob(ctx, func(ctx context.Context, td scexec.Dependencies) error {
+			exec.Attempts++
 			if err := td.TransactionalJobRegistry().CheckPausepoint(
 				pausepointName(state, i),
 			); err != nil {
 				return err
 			}
 			return executeStage(ctx, knobs, td, sc, i, sc.Stages[i])
-		}); err != nil {
+		})
+		exec.Finished, exec.Err = timeutil.Now(), err
+		recordStageExecution(ctx, deps, exec)
+		if err != nil {
 			if knobs != nil && knobs.OnPostCommitError != nil {
 				return knobs.OnPostCommitError(sc, i, err)
 			}
@@ -142,6 +152,16 @@ func RunSchemaChangesInJob(
 	return nil
 }
 
+// recordStageExecution records the stage execution in the stage history of
+// the job. This is done on a best-effort basis: failing to record the history
+// must not fail the schema change.
+func recordStageExecution(ctx context.Context, deps JobRunDependencies, exec StageExecution) {
+	if err := deps.RecordStageExecution(ctx, exec); err != nil {
+		log.Warningf(ctx, "failed to record execution of stage %d of %d in %s: %v",
+			exec.Ordinal, exec.StagesInPhase, exec.Phase, err)
+	}
+}
+
 // pausepointName construct a name for the job execution phase pausepoint.
 func pausepointName(state scpb.CurrentState, i int) string {
 	return fmt.Sprintf(
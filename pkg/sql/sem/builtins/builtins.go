@@ -6820,6 +6820,51 @@ table's zone configuration this will return NULL.`,
 		},
 	),
 
+	"crdb_internal.start_consistency_check_job": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         builtinconstants.CategorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{Name: "stats_only", Typ: types.Bool},
+				{Name: "start_key", Typ: types.Bytes},
+				{Name: "end_key", Typ: types.Bytes},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				ctx := evalCtx.Ctx()
+				isAdmin, err := evalCtx.SessionAccessor.HasAdminRole(ctx)
+				if err != nil {
+					return nil, err
+				}
+				if !isAdmin {
+					return nil, errInsufficientPriv
+				}
+				keyFrom, keyTo, mode, err := checkConsistencyArgs(evalCtx, args)
+				if err != nil {
+					return nil, err
+				}
+				id, err := evalCtx.ConsistencyChecker.StartConsistencyCheckJob(
+					ctx, keyFrom, keyTo, mode, evalCtx.SessionData().User(),
+				)
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDInt(tree.DInt(id)), nil
+			},
+			Info: "Starts a job which runs a consistency check on each of the ranges touching " +
+				"the specified key range, one range at a time, and returns the ID of the job. " +
+				"The arguments are the same as for crdb_internal.check_consistency. The pace " +
+				"of the job is controlled by the server.consistency_check.job_range_delay " +
+				"cluster setting, and the job can be paused and resumed. The result of the " +
+				"check of each range is stored in system.consistency_check_results.\n\n" +
+				"Example usage:\n" +
+				"SELECT crdb_internal.start_consistency_check_job(true, '\\x02', '\\xff\\xff')",
+			Volatility: volatility.Volatile,
+		},
+	),
+
 	"crdb_internal.revalidate_unique_constraints_in_all_tables": makeBuiltin(
 		tree.FunctionProperties{
 			Category: builtinconstants.CategorySystemInfo,
@@ -7172,49 +7217,7 @@ specified store on the node it's run from. One of 'mvccGC', 'merge', 'split',
 			},
 			ReturnType: tree.FixedReturnType(types.Bool),
 			Fn: func(evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
-				hasViewActivity, err := evalCtx.SessionAccessor.HasRoleOption(
-					evalCtx.Ctx(), roleoption.VIEWACTIVITY)
-				if err != nil {
-					return nil, err
-				}
-
-				if !hasViewActivity {
-					return nil, errors.New("requesting statement bundle requires " +
-						"VIEWACTIVITY or ADMIN role option")
-				}
-
-				isAdmin, err := evalCtx.SessionAccessor.HasAdminRole(evalCtx.Ctx())
-				if err != nil {
-					return nil, err
-				}
-
-				hasViewActivityRedacted, err := evalCtx.SessionAccessor.HasRoleOption(
-					evalCtx.Ctx(), roleoption.VIEWACTIVITYREDACTED)
-				if err != nil {
-					return nil, err
-				}
-
-				if !isAdmin && hasViewActivityRedacted {
-					return nil, errors.New("VIEWACTIVITYREDACTED role option cannot request " +
-						"statement bundle")
-				}
-
-				stmtFingerprint := string(tree.MustBeDString(args[0]))
-				samplingProbability := float64(tree.MustBeDFloat(args[1]))
-				minExecutionLatency := time.Duration(tree.MustBeDInterval(args[2]).Nanos())
-				expiresAfter := time.Duration(tree.MustBeDInterval(args[3]).Nanos())
-
-				if err := evalCtx.StmtDiagnosticsRequestInserter(
-					evalCtx.Ctx(),
-					stmtFingerprint,
-					samplingProbability,
-					minExecutionLatency,
-					expiresAfter,
-				); err != nil {
-					return nil, err
-				}
-
-				return tree.DBoolTrue, nil
+				return requestStatementBundle(evalCtx, args, false /* redacted */)
 			},
 			Volatility: volatility.Volatile,
 			Info: `Used to request statement bundle for a given statement fingerprint
@@ -7222,6 +7225,25 @@ that has execution latency greater than the 'minExecutionLatency'. If the
 'expiresAfter' argument is empty, then the statement bundle request never
 expires until the statement bundle is collected`,
 		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"stmtFingerprint", types.String},
+				{"samplingProbability", types.Float},
+				{"minExecutionLatency", types.Interval},
+				{"expiresAfter", types.Interval},
+				{"redacted", types.Bool},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return requestStatementBundle(evalCtx, args, bool(tree.MustBeDBool(args[4])))
+			},
+			Volatility: volatility.Volatile,
+			Info: `Used to request statement bundle for a given statement fingerprint
+that has execution latency greater than the 'minExecutionLatency'. If the
+'expiresAfter' argument is empty, then the statement bundle request never
+expires until the statement bundle is collected. If 'redacted' is true, then
+literals, placeholder values and sampled rows are scrubbed from the bundle.`,
+		},
 	),
 
 	"crdb_internal.set_compaction_concurrency": makeBuiltin(
@@ -9968,3 +9990,53 @@ func prettyStatement(p tree.PrettyCfg, stmt string) (string, error) {
 	}
 	return formattedStmt.String(), nil
 }
+
+// requestStatementBundle implements crdb_internal.request_statement_bundle.
+func requestStatementBundle(
+	evalCtx *eval.Context, args tree.Datums, redacted bool,
+) (tree.Datum, error) {
+	hasViewActivity, err := evalCtx.SessionAccessor.HasRoleOption(
+		evalCtx.Ctx(), roleoption.VIEWACTIVITY)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasViewActivity {
+		return nil, errors.New("requesting statement bundle requires " +
+			"VIEWACTIVITY or ADMIN role option")
+	}
+
+	isAdmin, err := evalCtx.SessionAccessor.HasAdminRole(evalCtx.Ctx())
+	if err != nil {
+		return nil, err
+	}
+
+	hasViewActivityRedacted, err := evalCtx.SessionAccessor.HasRoleOption(
+		evalCtx.Ctx(), roleoption.VIEWACTIVITYREDACTED)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAdmin && hasViewActivityRedacted {
+		return nil, errors.New("VIEWACTIVITYREDACTED role option cannot request " +
+			"statement bundle")
+	}
+
+	stmtFingerprint := string(tree.MustBeDString(args[0]))
+	samplingProbability := float64(tree.MustBeDFloat(args[1]))
+	minExecutionLatency := time.Duration(tree.MustBeDInterval(args[2]).Nanos())
+	expiresAfter := time.Duration(tree.MustBeDInterval(args[3]).Nanos())
+
+	if err := evalCtx.StmtDiagnosticsRequestInserter(
+		evalCtx.Ctx(),
+		stmtFingerprint,
+		samplingProbability,
+		minExecutionLatency,
+		expiresAfter,
+		redacted,
+	); err != nil {
+		return nil, err
+	}
+
+	return tree.DBoolTrue, nil
+}
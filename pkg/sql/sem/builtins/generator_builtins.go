@@ -13,6 +13,7 @@ package builtins
 import (
 	"bytes"
 	"context"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -409,13 +411,39 @@ var generators = map[string]builtinDefinition{
 				"respectively. stats_only should only be set to false when targeting a "+
 				"small number of ranges to avoid overloading the cluster. Each returned row "+
 				"contains the range ID, the status (a roachpb.CheckConsistencyResponse_Status), "+
-				"and verbose detail.\n\n"+
+				"and verbose detail. The check is run by a consistency check job, whose results "+
+				"are also persisted in system.consistency_check_results. To check a large number "+
+				"of ranges without waiting for the results, use "+
+				"crdb_internal.start_consistency_check_job instead.\n\n"+
 				"Example usage:\n"+
 				"SELECT * FROM crdb_internal.check_consistency(true, '\\x02', '\\x04')",
 			volatility.Volatile,
 		),
 	),
 
+	"crdb_internal.cleanup_store_scratch": makeBuiltin(
+		tree.FunctionProperties{
+			Class:            tree.GeneratorClass,
+			Category:         builtinconstants.CategorySystemRepair,
+			DistsqlBlocklist: true,
+			Undocumented:     true,
+		},
+		makeGeneratorOverload(
+			tree.ArgTypes{
+				{Name: "node_id", Typ: types.Int},
+			},
+			cleanupStoreScratchGeneratorType,
+			makeCleanupStoreScratchGenerator,
+			"This function is used only by CockroachDB's developers for restoring store health. "+
+				"It removes the orphaned files of the auxiliary directories of the stores of the given "+
+				"node, that is the staging files of snapshots that are no longer in flight and the "+
+				"sideloaded SSTs of ranges that no longer have a replica on the store, as is done when "+
+				"the node starts. Each returned row contains a store ID and the number of bytes reclaimed "+
+				"on that store.",
+			volatility.Volatile,
+		),
+	),
+
 	"crdb_internal.list_sql_keys_in_range": makeBuiltin(
 		tree.FunctionProperties{
 			Class:    tree.GeneratorClass,
@@ -1830,6 +1858,7 @@ type checkConsistencyGenerator struct {
 	consistencyChecker eval.ConsistencyCheckRunner
 	from, to           roachpb.Key
 	mode               roachpb.ChecksumMode
+	user               username.SQLUsername
 	// remainingRows is populated by Start(). Each Next() call peels of the first
 	// row and moves it to curRow.
 	remainingRows []roachpb.CheckConsistencyResponse_Result
@@ -1841,13 +1870,33 @@ var _ eval.ValueGenerator = &checkConsistencyGenerator{}
 func makeCheckConsistencyGenerator(
 	ctx *eval.Context, args tree.Datums,
 ) (eval.ValueGenerator, error) {
+	keyFrom, keyTo, mode, err := checkConsistencyArgs(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return &checkConsistencyGenerator{
+		consistencyChecker: ctx.ConsistencyChecker,
+		from:               keyFrom,
+		to:                 keyTo,
+		mode:               mode,
+		user:               ctx.SessionData().User(),
+	}, nil
+}
+
+// checkConsistencyArgs validates the (stats_only, start_key, end_key)
+// arguments of crdb_internal.check_consistency and
+// crdb_internal.start_consistency_check_job and returns the span and mode of
+// the check.
+func checkConsistencyArgs(
+	ctx *eval.Context, args tree.Datums,
+) (keyFrom, keyTo roachpb.Key, mode roachpb.ChecksumMode, _ error) {
 	if !ctx.Codec.ForSystemTenant() {
-		return nil, errorutil.UnsupportedWithMultiTenancy(
+		return nil, nil, 0, errorutil.UnsupportedWithMultiTenancy(
 			errorutil.FeatureNotAvailableToNonSystemTenantsIssue)
 	}
 
-	keyFrom := roachpb.Key(*args[1].(*tree.DBytes))
-	keyTo := roachpb.Key(*args[2].(*tree.DBytes))
+	keyFrom = roachpb.Key(*args[1].(*tree.DBytes))
+	keyTo = roachpb.Key(*args[2].(*tree.DBytes))
 
 	if len(keyFrom) == 0 {
 		keyFrom = keys.LocalMax
@@ -1857,26 +1906,20 @@ func makeCheckConsistencyGenerator(
 	}
 
 	if bytes.Compare(keyFrom, keys.LocalMax) < 0 {
-		return nil, errors.Errorf("start key must be >= %q", []byte(keys.LocalMax))
+		return nil, nil, 0, errors.Errorf("start key must be >= %q", []byte(keys.LocalMax))
 	}
 	if bytes.Compare(keyTo, roachpb.KeyMax) > 0 {
-		return nil, errors.Errorf("end key must be < %q", []byte(roachpb.KeyMax))
+		return nil, nil, 0, errors.Errorf("end key must be < %q", []byte(roachpb.KeyMax))
 	}
 	if bytes.Compare(keyFrom, keyTo) >= 0 {
-		return nil, errors.New("start key must be less than end key")
+		return nil, nil, 0, errors.New("start key must be less than end key")
 	}
 
-	mode := roachpb.ChecksumMode_CHECK_FULL
+	mode = roachpb.ChecksumMode_CHECK_FULL
 	if statsOnly := bool(*args[0].(*tree.DBool)); statsOnly {
 		mode = roachpb.ChecksumMode_CHECK_STATS
 	}
-
-	return &checkConsistencyGenerator{
-		consistencyChecker: ctx.ConsistencyChecker,
-		from:               keyFrom,
-		to:                 keyTo,
-		mode:               mode,
-	}, nil
+	return keyFrom, keyTo, mode, nil
 }
 
 var checkConsistencyGeneratorType = types.MakeLabeledTuple(
@@ -1891,11 +1934,11 @@ func (*checkConsistencyGenerator) ResolvedType() *types.T {
 
 // Start is part of the tree.ValueGenerator interface.
 func (c *checkConsistencyGenerator) Start(ctx context.Context, _ *kv.Txn) error {
-	resp, err := c.consistencyChecker.CheckConsistency(ctx, c.from, c.to, c.mode)
+	results, err := c.consistencyChecker.RunConsistencyCheck(ctx, c.from, c.to, c.mode, c.user)
 	if err != nil {
 		return err
 	}
-	c.remainingRows = resp.Result
+	c.remainingRows = results
 	return nil
 }
 
@@ -1923,6 +1966,87 @@ func (c *checkConsistencyGenerator) Values() (tree.Datums, error) {
 // Close is part of the tree.ValueGenerator interface.
 func (c *checkConsistencyGenerator) Close(_ context.Context) {}
 
+// cleanupStoreScratchGenerator is a value generator that removes the orphaned
+// auxiliary files of the stores of a node, and returns the number of bytes
+// reclaimed for each store.
+type cleanupStoreScratchGenerator struct {
+	cleanupStoreScratch eval.CleanupStoreScratchFunc
+	nodeID              int32
+	// storeIDs is populated by Start(), sorted. Each Next() call peels off the
+	// first store.
+	storeIDs  []int32
+	reclaimed map[int32]int64
+	curStore  int32
+}
+
+var _ eval.ValueGenerator = &cleanupStoreScratchGenerator{}
+
+func makeCleanupStoreScratchGenerator(
+	ctx *eval.Context, args tree.Datums,
+) (eval.ValueGenerator, error) {
+	isAdmin, err := ctx.SessionAccessor.HasAdminRole(ctx.Context)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, errInsufficientPriv
+	}
+	if !ctx.Codec.ForSystemTenant() {
+		return nil, errorutil.UnsupportedWithMultiTenancy(
+			errorutil.FeatureNotAvailableToNonSystemTenantsIssue)
+	}
+	return &cleanupStoreScratchGenerator{
+		cleanupStoreScratch: ctx.CleanupStoreScratch,
+		nodeID:              int32(tree.MustBeDInt(args[0])),
+	}, nil
+}
+
+var cleanupStoreScratchGeneratorType = types.MakeLabeledTuple(
+	[]*types.T{types.Int, types.Int},
+	[]string{"store_id", "bytes_reclaimed"},
+)
+
+// ResolvedType is part of the tree.ValueGenerator interface.
+func (*cleanupStoreScratchGenerator) ResolvedType() *types.T {
+	return cleanupStoreScratchGeneratorType
+}
+
+// Start is part of the tree.ValueGenerator interface.
+func (c *cleanupStoreScratchGenerator) Start(ctx context.Context, _ *kv.Txn) error {
+	reclaimed, err := c.cleanupStoreScratch(ctx, c.nodeID)
+	if err != nil {
+		return err
+	}
+	c.reclaimed = reclaimed
+	c.storeIDs = make([]int32, 0, len(reclaimed))
+	for storeID := range reclaimed {
+		c.storeIDs = append(c.storeIDs, storeID)
+	}
+	sort.Slice(c.storeIDs, func(i, j int) bool { return c.storeIDs[i] < c.storeIDs[j] })
+	return nil
+}
+
+// Next is part of the tree.ValueGenerator interface.
+func (c *cleanupStoreScratchGenerator) Next(_ context.Context) (bool, error) {
+	if len(c.storeIDs) == 0 {
+		return false, nil
+	}
+	c.curStore = c.storeIDs[0]
+	c.storeIDs = c.storeIDs[1:]
+	return true, nil
+}
+
+// Values is part of the tree.ValueGenerator interface.
+func (c *cleanupStoreScratchGenerator) Values() (tree.Datums, error) {
+	return tree.Datums{
+		tree.NewDInt(tree.DInt(c.curStore)),
+		tree.NewDInt(tree.DInt(c.reclaimed[c.curStore])),
+	}, nil
+}
+
+// Close is part of the tree.ValueGenerator interface.
+func (c *cleanupStoreScratchGenerator) Close(_ context.Context) {}
+
 // spanKeyIteratorChunkKeys is the number of K/V pairs that the
 // keyIterator requests at a time. If this changes, make sure
 // to update the test in sql_keys.
@@ -83,6 +83,8 @@ const (
 	SpanCountTableName                     SystemTableName = "span_count"
 	SystemPrivilegeTableName               SystemTableName = "privileges"
 	SystemExternalConnectionsTableName     SystemTableName = "external_connections"
+	SchemaChangeStageHistoryTableName      SystemTableName = "schema_change_stage_history"
+	ConsistencyCheckResultsTableName       SystemTableName = "consistency_check_results"
 	RoleIDSequenceName                     SystemTableName = "role_id_seq"
 )
 
@@ -161,6 +163,10 @@ const (
 	CrdbInternalTenantUsageDetailsViewID
 	CrdbInternalPgCatalogTableIsImplementedTableID
 	CrdbInternalSuperRegions
+	CrdbInternalKVStoreSnapshotsTableID
+	CrdbInternalNodeRecentLogMessagesTableID
+	CrdbInternalSchemaChangeStageHistoryTableID
+	CrdbInternalLoggingConfigTableID
 	InformationSchemaID
 	InformationSchemaAdministrableRoleAuthorizationsID
 	InformationSchemaApplicableRolesID
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -202,12 +203,17 @@ type Context struct {
 	// a store.
 	SetCompactionConcurrency SetCompactionConcurrencyFunc
 
+	// CleanupStoreScratch is used to remove the orphaned files of the
+	// auxiliary directories of the stores of a node.
+	CleanupStoreScratch CleanupStoreScratchFunc
+
 	// KVStoresIterator is used by various crdb_internal builtins to directly
 	// access stores on this node.
 	KVStoresIterator kvserverbase.StoresIterator
 
 	// ConsistencyChecker is to generate the results in calls to
-	// crdb_internal.check_consistency.
+	// crdb_internal.check_consistency and to start the jobs created by
+	// crdb_internal.start_consistency_check_job.
 	ConsistencyChecker ConsistencyCheckRunner
 
 	// RangeProber is used in calls to crdb_internal.probe_ranges.
@@ -250,11 +256,26 @@ type RangeStatsFetcher interface {
 var _ tree.ParseTimeContext = &Context{}
 
 // ConsistencyCheckRunner is an interface embedded in eval.Context used by
-// crdb_internal.check_consistency.
+// crdb_internal.check_consistency and
+// crdb_internal.start_consistency_check_job.
 type ConsistencyCheckRunner interface {
+	// CheckConsistency checks the consistency of the ranges touching the
+	// specified key range in a single request.
 	CheckConsistency(
 		ctx context.Context, from, to roachpb.Key, mode roachpb.ChecksumMode,
 	) (*roachpb.CheckConsistencyResponse, error)
+	// RunConsistencyCheck checks the consistency of the ranges touching the
+	// specified key range with a consistency check job, waits for it to
+	// complete and returns the results it persisted.
+	RunConsistencyCheck(
+		ctx context.Context, from, to roachpb.Key, mode roachpb.ChecksumMode, user username.SQLUsername,
+	) ([]roachpb.CheckConsistencyResponse_Result, error)
+	// StartConsistencyCheckJob creates a job which checks the consistency of
+	// the ranges touching the specified key range, one range at a time, and
+	// returns its ID.
+	StartConsistencyCheckJob(
+		ctx context.Context, from, to roachpb.Key, mode roachpb.ChecksumMode, user username.SQLUsername,
+	) (int64, error)
 }
 
 // RangeProber is an interface embedded in eval.Context used by
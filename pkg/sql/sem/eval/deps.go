@@ -398,6 +398,11 @@ type SetCompactionConcurrencyFunc func(
 	ctx context.Context, nodeID, storeID int32, compactionConcurrency uint64,
 ) error
 
+// CleanupStoreScratchFunc is used to remove the orphaned files of the
+// auxiliary directories of the stores of the given node. It returns the number
+// of bytes reclaimed, by store ID.
+type CleanupStoreScratchFunc func(ctx context.Context, nodeID int32) (map[int32]int64, error)
+
 // SessionAccessor is a limited interface to access session variables.
 type SessionAccessor interface {
 	// SetSessionVar sets a session variable to a new value. If isLocal is true,
@@ -603,6 +608,7 @@ type StmtDiagnosticsRequestInsertFunc func(
 	samplingProbability float64,
 	minExecutionLatency time.Duration,
 	expiresAfter time.Duration,
+	redacted bool,
 ) error
 
 // AsOfSystemTime represents the result from the evaluation of AS OF SYSTEM TIME
@@ -19,6 +19,9 @@ type RelocateRange struct {
 	ToStoreID       Expr
 	FromStoreID     Expr
 	SubjectReplicas RelocateSubject
+	// Priority is the priority hint for the snapshots sent to relocate the
+	// replicas. It is unspecified when relocating leases.
+	Priority UserPriority
 }
 
 // RelocateSubject indicates what replicas of a range should be relocated.
@@ -62,6 +65,10 @@ func (n *RelocateRange) Format(ctx *FmtCtx) {
 	}
 	ctx.WriteString(" TO ")
 	ctx.FormatNode(n.ToStoreID)
+	if n.Priority != UnspecifiedUserPriority {
+		ctx.WriteString(" WITH PRIORITY ")
+		ctx.WriteString(n.Priority.String())
+	}
 	ctx.WriteString(" FOR ")
 	ctx.FormatNode(n.Rows)
 }
@@ -561,11 +561,17 @@ const (
 	AuditModeDisable AuditMode = iota
 	// AuditModeReadWrite enables audit on read or write statements.
 	AuditModeReadWrite
+	// AuditModeRead enables audit on read statements only.
+	AuditModeRead
+	// AuditModeWrite enables audit on write statements only.
+	AuditModeWrite
 )
 
 var auditModeName = [...]string{
 	AuditModeDisable:   "OFF",
 	AuditModeReadWrite: "READ WRITE",
+	AuditModeRead:      "READ",
+	AuditModeWrite:     "WRITE",
 }
 
 func (m AuditMode) String() string {
@@ -581,6 +587,8 @@ func (m AuditMode) TelemetryName() string {
 // AlterTableSetAudit represents an ALTER TABLE AUDIT SET statement.
 type AlterTableSetAudit struct {
 	Mode AuditMode
+	// Role, if defined, restricts the audit mode to the members of the role.
+	Role RoleSpec
 }
 
 // TelemetryName implements the AlterTableCmd interface.
@@ -592,6 +600,10 @@ func (node *AlterTableSetAudit) TelemetryName() string {
 func (node *AlterTableSetAudit) Format(ctx *FmtCtx) {
 	ctx.WriteString(" EXPERIMENTAL_AUDIT SET ")
 	ctx.WriteString(node.Mode.String())
+	if !node.Role.Undefined() {
+		ctx.WriteString(" FOR ROLE ")
+		ctx.FormatNode(&node.Role)
+	}
 }
 
 // AlterTableInjectStats represents an ALTER TABLE INJECT STATISTICS statement.
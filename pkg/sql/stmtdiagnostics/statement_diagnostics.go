@@ -89,6 +89,7 @@ type Request struct {
 	samplingProbability float64
 	minExecutionLatency time.Duration
 	expiresAt           time.Time
+	redacted            bool
 }
 
 func (r *Request) isExpired(now time.Time) bool {
@@ -99,6 +100,12 @@ func (r *Request) isConditional() bool {
 	return r.minExecutionLatency != 0
 }
 
+// IsRedacted returns whether the bundle collected for this request should have
+// its user data (literals, placeholder values and sampled rows) scrubbed.
+func (r *Request) IsRedacted() bool {
+	return r.redacted
+}
+
 // NewRegistry constructs a new Registry.
 func NewRegistry(ie sqlutil.InternalExecutor, db *kv.DB, st *cluster.Settings) *Registry {
 	r := &Registry{
@@ -189,6 +196,7 @@ func (r *Registry) addRequestInternalLocked(
 	samplingProbability float64,
 	minExecutionLatency time.Duration,
 	expiresAt time.Time,
+	redacted bool,
 ) {
 	if r.findRequestLocked(id) {
 		// Request already exists.
@@ -202,6 +210,7 @@ func (r *Registry) addRequestInternalLocked(
 		samplingProbability: samplingProbability,
 		minExecutionLatency: minExecutionLatency,
 		expiresAt:           expiresAt,
+		redacted:            redacted,
 	}
 }
 
@@ -237,8 +246,9 @@ func (r *Registry) InsertRequest(
 	samplingProbability float64,
 	minExecutionLatency time.Duration,
 	expiresAfter time.Duration,
+	redacted bool,
 ) error {
-	_, err := r.insertRequestInternal(ctx, stmtFingerprint, samplingProbability, minExecutionLatency, expiresAfter)
+	_, err := r.insertRequestInternal(ctx, stmtFingerprint, samplingProbability, minExecutionLatency, expiresAfter, redacted)
 	return err
 }
 
@@ -248,6 +258,7 @@ func (r *Registry) insertRequestInternal(
 	samplingProbability float64,
 	minExecutionLatency time.Duration,
 	expiresAfter time.Duration,
+	redacted bool,
 ) (RequestID, error) {
 	isSamplingProbabilitySupported := r.st.Version.IsActive(ctx, clusterversion.SampledStmtDiagReqs)
 	if !isSamplingProbabilitySupported && samplingProbability != 0 {
@@ -255,6 +266,11 @@ func (r *Registry) insertRequestInternal(
 			"sampling probability only supported after 22.2 version migrations have completed",
 		)
 	}
+	if redacted && !r.st.Version.IsActive(ctx, clusterversion.RedactedStmtDiagReqs) {
+		return 0, errors.New(
+			"redacted bundles only supported after 22.2 version migrations have completed",
+		)
+	}
 	if samplingProbability < 0 || samplingProbability > 1 {
 		return 0, errors.AssertionFailedf(
 			"malformed input: expected sampling probability in range [0.0, 1.0], got %f",
@@ -296,7 +312,7 @@ func (r *Registry) insertRequestInternal(
 
 		now := timeutil.Now()
 		insertColumns := "statement_fingerprint, requested_at"
-		qargs := make([]interface{}, 2, 5)
+		qargs := make([]interface{}, 2, 6)
 		qargs[0] = stmtFingerprint // statement_fingerprint
 		qargs[1] = now             // requested_at
 		if samplingProbability != 0 {
@@ -312,6 +328,10 @@ func (r *Registry) insertRequestInternal(
 			expiresAt = now.Add(expiresAfter)
 			qargs = append(qargs, expiresAt) // expires_at
 		}
+		if redacted {
+			insertColumns += ", redacted"
+			qargs = append(qargs, redacted) // redacted
+		}
 		valuesClause := "$1, $2"
 		for i := range qargs[2:] {
 			valuesClause += fmt.Sprintf(", $%d", i+3)
@@ -343,7 +363,7 @@ func (r *Registry) insertRequestInternal(
 		r.mu.Lock()
 		defer r.mu.Unlock()
 		r.mu.epoch++
-		r.addRequestInternalLocked(ctx, reqID, stmtFingerprint, samplingProbability, minExecutionLatency, expiresAt)
+		r.addRequestInternalLocked(ctx, reqID, stmtFingerprint, samplingProbability, minExecutionLatency, expiresAt, redacted)
 	}()
 
 	return reqID, nil
@@ -591,6 +611,7 @@ func (r *Registry) InsertStatementDiagnostics(
 func (r *Registry) pollRequests(ctx context.Context) error {
 	var rows []tree.Datums
 	isSamplingProbabilitySupported := r.st.Version.IsActive(ctx, clusterversion.SampledStmtDiagReqs)
+	isRedactedSupported := r.st.Version.IsActive(ctx, clusterversion.RedactedStmtDiagReqs)
 
 	// Loop until we run the query without straddling an epoch increment.
 	for {
@@ -602,6 +623,9 @@ func (r *Registry) pollRequests(ctx context.Context) error {
 		if isSamplingProbabilitySupported {
 			extraColumns = ", sampling_probability"
 		}
+		if isRedactedSupported {
+			extraColumns += ", redacted"
+		}
 		it, err := r.ie.QueryIteratorEx(ctx, "stmt-diag-poll", nil, /* txn */
 			sessiondata.InternalExecutorOverride{
 				User: username.RootUserName(),
@@ -642,6 +666,7 @@ func (r *Registry) pollRequests(ctx context.Context) error {
 		var minExecutionLatency time.Duration
 		var expiresAt time.Time
 		var samplingProbability float64
+		var redacted bool
 
 		if minExecLatency, ok := row[2].(*tree.DInterval); ok {
 			minExecutionLatency = time.Duration(minExecLatency.Nanos())
@@ -654,8 +679,11 @@ func (r *Registry) pollRequests(ctx context.Context) error {
 				samplingProbability = float64(*prob)
 			}
 		}
+		if isRedactedSupported {
+			redacted = bool(*row[len(row)-1].(*tree.DBool))
+		}
 		ids.Add(int(id))
-		r.addRequestInternalLocked(ctx, id, stmtFingerprint, samplingProbability, minExecutionLatency, expiresAt)
+		r.addRequestInternalLocked(ctx, id, stmtFingerprint, samplingProbability, minExecutionLatency, expiresAt, redacted)
 	}
 
 	// Remove all other requests.
@@ -24,7 +24,9 @@ func (r *Registry) InsertRequestInternal(
 	minExecutionLatency time.Duration,
 	expiresAfter time.Duration,
 ) (int64, error) {
-	id, err := r.insertRequestInternal(ctx, fprint, samplingProbability, minExecutionLatency, expiresAfter)
+	id, err := r.insertRequestInternal(
+		ctx, fprint, samplingProbability, minExecutionLatency, expiresAfter, false, /* redacted */
+	)
 	return int64(id), err
 }
 
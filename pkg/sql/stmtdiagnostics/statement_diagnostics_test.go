@@ -127,6 +127,36 @@ func TestDiagnosticsRequest(t *testing.T) {
 		checkCompleted(id)
 	})
 
+	// Verify that a redacted request is persisted and that the statement of the
+	// collected bundle has its literals scrubbed.
+	t.Run("redacted", func(t *testing.T) {
+		require.NoError(t, registry.InsertRequest(
+			ctx, "SELECT x FROM test WHERE x = _", samplingProbability, minExecutionLatency,
+			expiresAfter, true, /* redacted */
+		))
+		var reqID int64
+		var redacted bool
+		require.NoError(t, db.QueryRow(
+			`SELECT id, redacted FROM system.statement_diagnostics_requests
+			 WHERE statement_fingerprint = 'SELECT x FROM test WHERE x = _'`,
+		).Scan(&reqID, &redacted))
+		require.True(t, redacted)
+		checkNotCompleted(reqID)
+
+		_, err = db.Exec("SELECT x FROM test WHERE x = 123456789")
+		require.NoError(t, err)
+		checkCompleted(reqID)
+
+		var stmt string
+		require.NoError(t, db.QueryRow(
+			`SELECT statement FROM system.statement_diagnostics d
+			 JOIN system.statement_diagnostics_requests r ON d.id = r.statement_diagnostics_id
+			 WHERE r.id = $1`, reqID,
+		).Scan(&stmt))
+		require.NotContains(t, stmt, "123456789")
+		require.Contains(t, stmt, "‹×›")
+	})
+
 	// Verify that the bundle for a conditional request is only created when the
 	// condition is satisfied.
 	t.Run("conditional", func(t *testing.T) {
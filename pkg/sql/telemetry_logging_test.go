@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/redact"
+	"github.com/stretchr/testify/require"
 )
 
 type stubTime struct {
@@ -856,3 +857,89 @@ func TestTelemetryLogJoinTypesAndAlgorithms(t *testing.T) {
 		}
 	}
 }
+
+// TestTelemetryLogStatementRedactionLevel verifies that the statements in the
+// telemetry events are redacted according to the
+// sql.log.statement_redaction_level cluster setting.
+func TestTelemetryLogStatementRedactionLevel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := log.ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	cleanup := logtestutils.InstallTelemetryLogFileSink(sc, t)
+	defer cleanup()
+
+	st := stubTime{}
+
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			TelemetryLoggingKnobs: &TelemetryLoggingTestingKnobs{
+				getTimeNow: st.TimeNow,
+			},
+		},
+	})
+	db := sqlutils.MakeSQLRunner(sqlDB)
+	defer s.Stopper().Stop(context.Background())
+
+	db.Exec(t, `SET CLUSTER SETTING sql.telemetry.query_sampling.enabled = true;`)
+	db.Exec(t, "CREATE TABLE t();")
+
+	stubMaxEventFrequency := int64(1)
+	telemetryMaxEventFrequency.Override(context.Background(), &s.ClusterSettings().SV, stubMaxEventFrequency)
+
+	testData := []struct {
+		level                string
+		query                string
+		expectedLogStatement string
+	}{
+		{
+			"full",
+			"SELECT * FROM t LIMIT 1;",
+			`"Statement":"SELECT * FROM \"\".\"\".t LIMIT ‹1›"`,
+		},
+		{
+			"constants_replaced",
+			"SELECT * FROM t LIMIT 2;",
+			`"Statement":"SELECT * FROM \"\".\"\".t LIMIT _"`,
+		},
+		{
+			"redacted",
+			"SELECT * FROM t LIMIT 3;",
+			`"Statement":"‹×›"`,
+		},
+	}
+
+	for idx, tc := range testData {
+		statementRedactionLevelSetting.Override(context.Background(), &s.ClusterSettings().SV,
+			int64(idx))
+		require.Equal(t, tc.level, statementRedactionLevelSetting.String(&s.ClusterSettings().SV))
+		// Advance time 1 second from the previous query, for this query to be
+		// sampled.
+		st.setTime(timeutil.FromUnixMicros(int64((idx + 1) * 1e6)))
+		db.Exec(t, tc.query)
+	}
+
+	log.Flush()
+
+	entries, err := log.FetchEntriesFromFiles(
+		0,
+		math.MaxInt64,
+		10000,
+		regexp.MustCompile(`"EventType":"sampled_query"`),
+		log.WithMarkedSensitiveData,
+	)
+	require.NoError(t, err)
+
+	for _, tc := range testData {
+		found := false
+		for _, e := range entries {
+			if strings.Contains(e.Message, tc.expectedLogStatement) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("%s: no sampled query event found with %s", tc.level, tc.expectedLogStatement)
+		}
+	}
+}
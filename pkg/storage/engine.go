@@ -861,6 +861,12 @@ type Engine interface {
 	//
 	// Not thread safe.
 	GetAuxiliaryDir() string
+	// GetSnapshotScratchDir returns the directory in which the incoming
+	// snapshots are staged, if it was configured to be outside of the
+	// auxiliary directory, or "" otherwise. Like the auxiliary directory, it
+	// is accessed through the engine's filesystem, and data can be ingested
+	// from it.
+	GetSnapshotScratchDir() string
 	// NewBatch returns a new instance of a batched engine which wraps
 	// this engine. Batched engines accumulate all mutations and apply
 	// them atomically on a call to Commit().
@@ -933,6 +939,10 @@ type Engine interface {
 	RegisterFlushCompletedCallback(cb func())
 	// Filesystem functionality.
 	fs.FS
+	// CreateEncrypted implements the fs.EncryptedFS interface, creating the
+	// file through the encrypted filesystem of the store if encryption at rest
+	// is enabled.
+	CreateEncrypted(name string, bytesPerSync int) (fs.File, error)
 	// ReadFile reads the content from the file with the given filename int this RocksDB's env.
 	ReadFile(filename string) ([]byte, error)
 	// WriteFile writes data to a file in this RocksDB's env.
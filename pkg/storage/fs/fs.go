@@ -13,6 +13,9 @@ package fs
 import (
 	"io"
 	"os"
+	"sort"
+
+	"github.com/cockroachdb/pebble/vfs"
 )
 
 // File and FS are a partial attempt at offering the Pebble vfs.FS interface. Given the constraints
@@ -70,6 +73,17 @@ type FS interface {
 	Stat(name string) (os.FileInfo, error)
 }
 
+// EncryptedFS is an FS which may encrypt the files it creates, according to
+// the encryption-at-rest configuration of the store it belongs to.
+type EncryptedFS interface {
+	FS
+
+	// CreateEncrypted is similar to CreateWithSync, or to Create if
+	// bytesPerSync is 0, but returns an error instead of the file if
+	// encryption at rest is active and the file was created in plaintext.
+	CreateEncrypted(name string, bytesPerSync int) (File, error)
+}
+
 // WriteFile writes data to a file named by filename.
 func WriteFile(fs FS, filename string, data []byte) error {
 	f, err := fs.Create(filename)
@@ -82,3 +96,78 @@ func WriteFile(fs FS, filename string, data []byte) error {
 	}
 	return err
 }
+
+// WrapVFS returns an FS backed by the given Pebble filesystem, e.g.
+// vfs.Default to access the operating system's filesystem directly rather
+// than through a storage engine.
+func WrapVFS(fs vfs.FS) FS {
+	return vfsFS{fs: fs}
+}
+
+// vfsFS implements FS on top of a Pebble vfs.FS.
+type vfsFS struct {
+	fs vfs.FS
+}
+
+var _ FS = vfsFS{}
+
+// Create implements the FS interface.
+func (v vfsFS) Create(name string) (File, error) {
+	return v.fs.Create(name)
+}
+
+// CreateWithSync implements the FS interface.
+func (v vfsFS) CreateWithSync(name string, bytesPerSync int) (File, error) {
+	f, err := v.fs.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return vfs.NewSyncingFile(f, vfs.SyncingFileOptions{BytesPerSync: bytesPerSync}), nil
+}
+
+// Link implements the FS interface.
+func (v vfsFS) Link(oldname, newname string) error {
+	return v.fs.Link(oldname, newname)
+}
+
+// Open implements the FS interface.
+func (v vfsFS) Open(name string) (File, error) {
+	return v.fs.Open(name)
+}
+
+// OpenDir implements the FS interface.
+func (v vfsFS) OpenDir(name string) (File, error) {
+	return v.fs.OpenDir(name)
+}
+
+// Remove implements the FS interface.
+func (v vfsFS) Remove(name string) error {
+	return v.fs.Remove(name)
+}
+
+// Rename implements the FS interface.
+func (v vfsFS) Rename(oldname, newname string) error {
+	return v.fs.Rename(oldname, newname)
+}
+
+// MkdirAll implements the FS interface.
+func (v vfsFS) MkdirAll(name string) error {
+	return v.fs.MkdirAll(name, 0755)
+}
+
+// RemoveAll implements the FS interface.
+func (v vfsFS) RemoveAll(dir string) error {
+	return v.fs.RemoveAll(dir)
+}
+
+// List implements the FS interface.
+func (v vfsFS) List(name string) ([]string, error) {
+	dirents, err := v.fs.List(name)
+	sort.Strings(dirents)
+	return dirents, err
+}
+
+// Stat implements the FS interface.
+func (v vfsFS) Stat(name string) (os.FileInfo, error) {
+	return v.fs.Stat(name)
+}
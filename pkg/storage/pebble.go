@@ -662,6 +662,7 @@ type Pebble struct {
 	readOnly    bool
 	path        string
 	auxDir      string
+	scratchDir  string
 	ballastPath string
 	ballastSize int64
 	maxSize     int64
@@ -722,6 +723,7 @@ type EncryptionEnv struct {
 }
 
 var _ Engine = &Pebble{}
+var _ fs.EncryptedFS = &Pebble{}
 
 // NewEncryptedEnvFunc creates an encrypted environment and returns the vfs.FS to use for reading
 // and writing data. This should be initialized by calling engineccl.Init() before calling
@@ -885,6 +887,7 @@ func NewPebble(ctx context.Context, cfg PebbleConfig) (p *Pebble, err error) {
 		readOnly:         cfg.Opts.ReadOnly,
 		path:             cfg.Dir,
 		auxDir:           auxDir,
+		scratchDir:       cfg.SnapshotScratchDir,
 		ballastPath:      ballastPath,
 		ballastSize:      cfg.BallastSize,
 		maxSize:          cfg.MaxSize,
@@ -1639,6 +1642,11 @@ func (p *Pebble) GetAuxiliaryDir() string {
 	return p.auxDir
 }
 
+// GetSnapshotScratchDir implements the Engine interface.
+func (p *Pebble) GetSnapshotScratchDir() string {
+	return p.scratchDir
+}
+
 // NewBatch implements the Engine interface.
 func (p *Pebble) NewBatch() Batch {
 	return newPebbleBatch(p.db, p.db.NewIndexedBatch(), false /* writeOnly */, p.settings)
@@ -1772,6 +1780,50 @@ func (p *Pebble) CreateWithSync(name string, bytesPerSync int) (fs.File, error)
 	return vfs.NewSyncingFile(f, vfs.SyncingFileOptions{BytesPerSync: bytesPerSync}), nil
 }
 
+// CreateEncrypted implements the fs.EncryptedFS interface.
+func (p *Pebble) CreateEncrypted(name string, bytesPerSync int) (fs.File, error) {
+	var f fs.File
+	var err error
+	if bytesPerSync > 0 {
+		f, err = p.CreateWithSync(name, bytesPerSync)
+	} else {
+		f, err = p.Create(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkFileEncrypted(name); err != nil {
+		_ = f.Close()
+		_ = p.fs.Remove(name)
+		return nil, err
+	}
+	return f, nil
+}
+
+// checkFileEncrypted returns an assertion failure if encryption at rest is
+// active on the store, but the named file, which was just created, is not
+// encrypted. The encrypted filesystem registers every encrypted file in the
+// file registry, while the files missing from the registry are plaintext.
+func (p *Pebble) checkFileEncrypted(name string) error {
+	if p.encryption == nil || p.fileRegistry == nil {
+		return nil
+	}
+	activeKeyID, err := p.encryption.StatsHandler.GetActiveDataKeyID()
+	if err != nil {
+		return err
+	}
+	// The encryption may be active with a plaintext data key, after the store
+	// was switched to plaintext.
+	if activeKeyID == "plain" {
+		return nil
+	}
+	if p.fileRegistry.GetFileEntry(name) == nil {
+		return errors.AssertionFailedf(
+			"file %s created in plaintext on a store with encryption at rest", name)
+	}
+	return nil
+}
+
 // Open implements the FS interface.
 func (p *Pebble) Open(name string) (fs.File, error) {
 	return p.fs.Open(name)
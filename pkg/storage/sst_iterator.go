@@ -40,6 +40,19 @@ func NewPebbleSSTIterator(
 	return newPebbleSSTIterator(files, opts, forwardOnly)
 }
 
+// NewSSTEngineIterator is like NewPebbleSSTIterator, but returns an
+// `EngineIterator`, which also surfaces the keys that are not MVCC keys, such
+// as the lock table keys.
+func NewSSTEngineIterator(
+	files [][]sstable.ReadableFile, opts IterOptions, forwardOnly bool,
+) (EngineIterator, error) {
+	iter, err := newPebbleSSTIterator(files, opts, forwardOnly)
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
 // NewPebbleMemSSTIterator returns an `MVCCIterator` for the provided SST data,
 // similarly to NewPebbleSSTIterator().
 func NewPebbleMemSSTIterator(sst []byte, verify bool, opts IterOptions) (MVCCIterator, error) {
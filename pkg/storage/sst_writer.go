@@ -104,8 +104,22 @@ func MakeBackupSSTWriter(ctx context.Context, cs *cluster.Settings, f io.Writer)
 // format set to RocksDBv2.
 func MakeIngestionSSTWriter(
 	ctx context.Context, cs *cluster.Settings, f writeCloseSyncer,
+) SSTWriter {
+	return makeIngestionSSTWriter(f, MakeIngestionWriterOptions(ctx, cs))
+}
+
+// MakeIngestionSSTWriterWithCompression is like MakeIngestionSSTWriter, except
+// that the blocks of the SST are compressed with the given algorithm, e.g. to
+// trade CPU for disk space.
+func MakeIngestionSSTWriterWithCompression(
+	ctx context.Context, cs *cluster.Settings, f writeCloseSyncer, compression sstable.Compression,
 ) SSTWriter {
 	opts := MakeIngestionWriterOptions(ctx, cs)
+	opts.Compression = compression
+	return makeIngestionSSTWriter(f, opts)
+}
+
+func makeIngestionSSTWriter(f writeCloseSyncer, opts sstable.WriterOptions) SSTWriter {
 	return SSTWriter{
 		fw:                sstable.NewWriter(f, opts),
 		f:                 f,
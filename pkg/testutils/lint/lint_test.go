@@ -731,6 +731,7 @@ func TestLint(t *testing.T) {
 			":!**/embedded.go",
 			":!util/timeutil/time.go",
 			":!util/timeutil/zoneinfo.go",
+			":!util/syncutil/instrumented_mutex.go",
 			":!util/tracing/span.go",
 			":!util/tracing/crdbspan.go",
 			":!util/tracing/tracer.go",
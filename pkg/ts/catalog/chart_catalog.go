@@ -622,6 +622,39 @@ var charts = []sectionDescription{
 					"range.snapshots.applied-non-voter",
 				},
 			},
+			{
+				Title: "Snapshot Checksum Mismatches",
+				Metrics: []string{
+					"range.snapshots.checksum-mismatches",
+				},
+			},
+			{
+				Title: "Snapshots Applied As Batch",
+				Metrics: []string{
+					"range.snapshots.applied-as-batch",
+				},
+			},
+			{
+				Title: "Snapshot Ingestion Bytes",
+				Metrics: []string{
+					"range.snapshots.ingest.logical-bytes",
+					"range.snapshots.ingest.physical-bytes",
+					"range.snapshots.ingest.compaction-bytes",
+				},
+			},
+			{
+				Title: "Snapshot Ingestion Write Amplification",
+				Metrics: []string{
+					"range.snapshots.ingest.write-amplification",
+				},
+			},
+			{
+				Title: "Applied Snapshot Verifications",
+				Metrics: []string{
+					"range.snapshots.applied-verified",
+					"range.snapshots.applied-verification-failures",
+				},
+			},
 			{
 				Title: "Snapshot Queues",
 				Metrics: []string{
@@ -646,6 +679,47 @@ var charts = []sectionDescription{
 					"range.snapshots.unknown.sent-bytes",
 				},
 			},
+			{
+				Title: "Snapshot Scratch Activity",
+				Metrics: []string{
+					"range.snapshots.scratch.bytes-written",
+					"range.snapshots.scratch.files-created",
+					"range.snapshots.scratch.syncs",
+				},
+			},
+			{
+				Title: "Snapshot Scratch Latency",
+				Metrics: []string{
+					"range.snapshots.scratch.write-latency",
+					"range.snapshots.scratch.sync-latency",
+					"range.snapshots.scratch.create-latency",
+				},
+			},
+			{
+				Title: "Snapshot Scratch Write Budget Wait",
+				Metrics: []string{
+					"range.snapshots.scratch.bulk-io-write-wait",
+				},
+			},
+			{
+				Title: "Snapshot Scratch Space",
+				Metrics: []string{
+					"range.snapshots.scratch.active",
+					"range.snapshots.scratch.used-bytes",
+				},
+			},
+			{
+				Title: "Snapshot Scratch Probe Failures",
+				Metrics: []string{
+					"range.snapshots.scratch.probe-failures",
+				},
+			},
+			{
+				Title: "Snapshot Scratch Space by Tenant",
+				Metrics: []string{
+					"range.snapshots.scratch.tenant-used-bytes",
+				},
+			},
 		},
 	},
 	{
@@ -2445,6 +2519,29 @@ var charts = []sectionDescription{
 			},
 		},
 	},
+	{
+		Organization: [][]string{{SQLLayer, "Consistency Checks"}},
+		Charts: []chartDescription{
+			{
+				Title: "Jobs Running",
+				Metrics: []string{
+					"jobs.consistency_check.currently_running",
+					"jobs.consistency_check.currently_idle",
+				},
+			},
+			{
+				Title: "Jobs Statistics",
+				Metrics: []string{
+					"jobs.consistency_check.fail_or_cancel_completed",
+					"jobs.consistency_check.fail_or_cancel_failed",
+					"jobs.consistency_check.fail_or_cancel_retry_error",
+					"jobs.consistency_check.resume_completed",
+					"jobs.consistency_check.resume_failed",
+					"jobs.consistency_check.resume_retry_error",
+				},
+			},
+		},
+	},
 	{
 		Organization: [][]string{{SQLLayer, "SQL Memory", "Internal"}},
 		Charts: []chartDescription{
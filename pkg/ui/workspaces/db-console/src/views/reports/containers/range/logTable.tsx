@@ -42,6 +42,15 @@ function printLogEventType(
     case protos.cockroach.kv.kvserver.storagepb.RangeLogEventType
       .unsafe_quorum_recovery:
       return "Unsafe Quorum Recovery";
+    case protos.cockroach.kv.kvserver.storagepb.RangeLogEventType
+      .snapshot_scratch_created:
+      return "Snapshot Scratch Created";
+    case protos.cockroach.kv.kvserver.storagepb.RangeLogEventType
+      .snapshot_scratch_completed:
+      return "Snapshot Scratch Completed";
+    case protos.cockroach.kv.kvserver.storagepb.RangeLogEventType
+      .snapshot_scratch_aborted:
+      return "Snapshot Scratch Aborted";
     default:
       return "Unknown";
   }
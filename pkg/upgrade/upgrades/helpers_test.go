@@ -28,11 +28,12 @@ import (
 )
 
 var (
-	HasColumn         = hasColumn
-	HasIndex          = hasIndex
-	DoesNotHaveIndex  = doesNotHaveIndex
-	HasColumnFamily   = hasColumnFamily
-	CreateSystemTable = createSystemTable
+	HasColumn                  = hasColumn
+	HasIndex                   = hasIndex
+	DoesNotHaveIndex           = doesNotHaveIndex
+	HasColumnFamily            = hasColumnFamily
+	CreateSystemTable          = createSystemTable
+	CompletedIdxStoresRedacted = completedIdxStoresRedacted
 )
 
 type Schema struct {
@@ -0,0 +1,90 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package upgrades
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/upgrade"
+)
+
+// Target schema changes in the system.statement_diagnostics_requests table,
+// adding a column and recreating the secondary index to store it.
+const (
+	addRedactedColToStmtDiagReqs = `
+ALTER TABLE system.statement_diagnostics_requests
+  ADD COLUMN redacted BOOL NOT NULL DEFAULT FALSE FAMILY "primary"`
+
+	dropCompletedIdxV3 = `DROP INDEX IF EXISTS system.statement_diagnostics_requests@completed_idx`
+
+	createCompletedIdxV4 = `
+CREATE INDEX completed_idx ON system.statement_diagnostics_requests (completed, ID)
+  STORING (statement_fingerprint, min_execution_latency, expires_at, sampling_probability, redacted)`
+)
+
+// redactedStmtDiagReqsMigration adds the redacted column to the
+// system.statement_diagnostics_requests table, which records whether the
+// requested bundle should have its user data scrubbed, and stores it in the
+// index used by the polling query.
+func redactedStmtDiagReqsMigration(
+	ctx context.Context, cs clusterversion.ClusterVersion, d upgrade.TenantDeps, _ *jobs.Job,
+) error {
+	for _, op := range []operation{
+		{
+			name:           "add-stmt-diag-reqs-redacted-column",
+			schemaList:     []string{"redacted"},
+			query:          addRedactedColToStmtDiagReqs,
+			schemaExistsFn: hasColumn,
+		},
+		{
+			name:           "drop-stmt-diag-reqs-v3-index",
+			schemaList:     []string{"completed_idx"},
+			query:          dropCompletedIdxV3,
+			schemaExistsFn: completedIdxStoresRedacted,
+		},
+		{
+			name:           "create-stmt-diag-reqs-v4-index",
+			schemaList:     []string{"completed_idx"},
+			query:          createCompletedIdxV4,
+			schemaExistsFn: hasIndex,
+		},
+	} {
+		if err := migrateTable(ctx, cs, d, op, keys.StatementDiagnosticsRequestsTableID,
+			systemschema.StatementDiagnosticsRequestsTable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completedIdxStoresRedacted returns true if the stored index named indexName
+// doesn't need to be dropped, either because it doesn't exist or because it
+// already stores the redacted column. hasIndex can't be used for this, as it
+// ignores the stored columns.
+func completedIdxStoresRedacted(
+	storedTable, _ catalog.TableDescriptor, indexName string,
+) (bool, error) {
+	idx, _ := storedTable.FindIndexWithName(indexName)
+	if idx == nil {
+		return true, nil
+	}
+	for i := 0; i < idx.NumSecondaryStoredColumns(); i++ {
+		if idx.GetStoredColumnName(i) == "redacted" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -159,6 +159,24 @@ var upgrades = []upgrade.Upgrade{
 		NoPrecondition,
 		updateInvalidColumnIDsInSequenceBackReferences,
 	),
+	upgrade.NewTenantUpgrade(
+		"add the system.schema_change_stage_history table",
+		toCV(clusterversion.SchemaChangeStageHistoryTable),
+		NoPrecondition,
+		systemSchemaChangeStageHistoryTableMigration,
+	),
+	upgrade.NewTenantUpgrade(
+		"add column redacted to table system.statement_diagnostics_requests",
+		toCV(clusterversion.RedactedStmtDiagReqs),
+		NoPrecondition,
+		redactedStmtDiagReqsMigration,
+	),
+	upgrade.NewTenantUpgrade(
+		"add the system.consistency_check_results table",
+		toCV(clusterversion.ConsistencyCheckJobs),
+		NoPrecondition,
+		systemConsistencyCheckResultsTableMigration,
+	),
 }
 
 func init() {
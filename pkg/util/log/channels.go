@@ -139,5 +139,26 @@ func LoggingToStderr(s Severity) bool {
 	return s >= logging.stderrSinkInfoTemplate.threshold.get(channel.DEV)
 }
 
+// HasDurableSink returns true if events of the given severity sent to
+// the given channel reach at least one sink which is guaranteed to
+// persist them before the logging call returns, and whose failures
+// cause the process to terminate. This is only the case of unbuffered
+// file sinks configured as "auditable".
+func HasDurableSink(ch Channel, s Severity) bool {
+	l := logging.getLogger(ch)
+	for _, si := range l.sinkInfos {
+		if !si.criticality || s < si.threshold.get(ch) {
+			continue
+		}
+		// Network sinks are not considered durable: a logging call only
+		// guarantees that the event was handed over to the collector, not
+		// that it was persisted.
+		if fs, ok := si.sink.(*fileSink); ok && !fs.bufferedWrites {
+			return true
+		}
+	}
+	return false
+}
+
 // MaybeSendCrashReport is injected by package logcrash
 var MaybeSendCrashReport func(ctx context.Context, err error)
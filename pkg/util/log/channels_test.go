@@ -20,6 +20,9 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/cli/exit"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/stretchr/testify/require"
@@ -205,3 +208,56 @@ func TestRepro81025(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, files, 3)
 }
+
+func TestHasDurableSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	apply := func(config logconfig.Config) (cleanupFn func()) {
+		require.NoError(t, config.Validate(&s.logDir))
+		TestingResetActive()
+		cleanupFn, err := ApplyConfig(config)
+		require.NoError(t, err)
+		return cleanupFn
+	}
+
+	// The default file sink uses buffered writes.
+	cleanupFn := apply(logconfig.DefaultConfig())
+	require.False(t, HasDurableSink(channel.SENSITIVE_ACCESS, severity.INFO))
+	cleanupFn()
+
+	bt := true
+
+	// Network sinks are never durable, even when auditable.
+	config := logconfig.DefaultConfig()
+	addr := "http://localhost:0"
+	config.Sinks.HTTPServers = map[string]*logconfig.HTTPSinkConfig{
+		"audit": {
+			HTTPDefaults: logconfig.HTTPDefaults{
+				Address:          &addr,
+				CommonSinkConfig: logconfig.CommonSinkConfig{Auditable: &bt},
+			},
+			Channels: logconfig.SelectChannels(channel.SENSITIVE_ACCESS),
+		},
+	}
+	cleanupFn = apply(config)
+	require.False(t, HasDurableSink(channel.SENSITIVE_ACCESS, severity.INFO))
+	cleanupFn()
+
+	// An auditable file sink is durable, but only for the channels it
+	// is connected to.
+	config = logconfig.DefaultConfig()
+	if config.Sinks.FileGroups == nil {
+		config.Sinks.FileGroups = make(map[string]*logconfig.FileSinkConfig)
+	}
+	config.Sinks.FileGroups["audit"] = &logconfig.FileSinkConfig{
+		FileDefaults: logconfig.FileDefaults{
+			CommonSinkConfig: logconfig.CommonSinkConfig{Auditable: &bt},
+		},
+		Channels: logconfig.SelectChannels(channel.SENSITIVE_ACCESS),
+	}
+	defer apply(config)()
+	require.True(t, HasDurableSink(channel.SENSITIVE_ACCESS, severity.INFO))
+	require.False(t, HasDurableSink(channel.OPS, severity.INFO))
+}
@@ -45,6 +45,9 @@ type loggingT struct {
 	// interceptor contains the configured InterceptorFn callbacks, if any.
 	interceptor interceptorSink
 
+	// recentEntries retains the most recent log entries in memory.
+	recentEntries recentEntriesSink
+
 	// vmoduleConfig maintains the configuration for the log.V and vmodule
 	// facilities.
 	vmoduleConfig vmoduleConfig
@@ -72,6 +75,16 @@ type loggingT struct {
 		currentStderrSinkInfo *sinkInfo
 	}
 
+	// overrides holds the state needed by ApplyOverrides.
+	overrides struct {
+		syncutil.Mutex
+		// sinks are the sinks created by ApplyConfig.
+		sinks []overridableSink
+		// prefix are the sinks which ApplyConfig connects to all the
+		// channels ahead of the others.
+		prefix []*sinkInfo
+	}
+
 	// testingFd2CaptureLogger remembers the logger that was last set up
 	// to capture fd2 writes. Used by unit tests in this package.
 	testingFd2CaptureLogger *loggerT
@@ -135,6 +148,10 @@ type sinkInfo struct {
 	// entries.
 	msgCount uint64
 
+	// outputErrCount counts the entries which the sink failed to
+	// write, for DescribeSinks.
+	outputErrCount uint64
+
 	// criticality indicates whether a failure to output some log
 	// entries should incur the process to terminate.
 	criticality bool
@@ -334,6 +351,7 @@ func (l *loggerT) outputLogEntry(entry logEntry) {
 				continue
 			}
 			if err := s.sink.output(bufs.b[i].Bytes(), sinkOutputOptions{extraFlush: extraFlush, forceSync: isFatal}); err != nil {
+				atomic.AddUint64(&s.outputErrCount, 1)
 				if !s.criticality {
 					// An error on this sink is not critical. Just report
 					// the error and move on.
@@ -107,6 +107,7 @@ func ApplyConfig(config logconfig.Config) (logShutdownFn func(), err error) {
 		// Reset the logging channels to default.
 		si := logging.stderrSinkInfoTemplate
 		logging.setChannelLoggers(make(map[Channel]*loggerT), &si)
+		logging.setOverridableSinks(nil, nil)
 		fd2CaptureCleanupFn()
 		secLoggersCancel()
 		if err := closer.Close(defaultCloserTimeout); err != nil {
@@ -262,9 +263,24 @@ func ApplyConfig(config logconfig.Config) (logShutdownFn func(), err error) {
 		l.sinkInfos = append(l.sinkInfos, &stderrSinkInfo)
 	}
 
-	attachSinkInfo := func(si *sinkInfo, chs *logconfig.ChannelFilters) {
+	// overridableSinks collects the sinks which can be changed afterwards
+	// by ApplyOverrides.
+	overridableSinks := []overridableSink{{
+		sinkType: logconfig.StderrSinkType,
+		name:     logconfig.StderrSinkType,
+		base:     &stderrSinkInfo,
+		channels: config.Sinks.Stderr.Channels.AllChannels.Channels,
+	}}
+
+	attachSinkInfo := func(si *sinkInfo, chs *logconfig.ChannelFilters, sinkType, name string) {
 		sinkInfos = append(sinkInfos, si)
 		logging.allSinkInfos.put(si)
+		overridableSinks = append(overridableSinks, overridableSink{
+			sinkType: sinkType,
+			name:     name,
+			base:     si,
+			channels: chs.AllChannels.Channels,
+		})
 
 		// Connect the channels for this sink.
 		for _, ch := range chs.AllChannels.Channels {
@@ -274,10 +290,11 @@ func ApplyConfig(config logconfig.Config) (logShutdownFn func(), err error) {
 	}
 
 	// Create the file sinks.
-	for fileGroupName, fc := range config.Sinks.FileGroups {
+	for configName, fc := range config.Sinks.FileGroups {
 		if fc.Filter == severity.NONE || fc.Dir == nil {
 			continue
 		}
+		fileGroupName := configName
 		if fileGroupName == "default" {
 			fileGroupName = ""
 		}
@@ -286,7 +303,9 @@ func ApplyConfig(config logconfig.Config) (logShutdownFn func(), err error) {
 			return nil, err
 		}
 		attachBufferWrapper(fileSinkInfo, fc.CommonSinkConfig.Buffering, closer)
-		attachSinkInfo(fileSinkInfo, &fc.Channels)
+		attachSinkInfo(fileSinkInfo, &fc.Channels, logconfig.FileSinkType, configName)
+		overridableSinks[len(overridableSinks)-1].fileSink = fileSink
+		overridableSinks[len(overridableSinks)-1].maxGroupSize = fileSink.logFilesCombinedMaxSize
 
 		// Start the GC process. This ensures that old capture files get
 		// erased as new files get created.
@@ -294,7 +313,7 @@ func ApplyConfig(config logconfig.Config) (logShutdownFn func(), err error) {
 	}
 
 	// Create the fluent sinks.
-	for _, fc := range config.Sinks.FluentServers {
+	for name, fc := range config.Sinks.FluentServers {
 		if fc.Filter == severity.NONE {
 			continue
 		}
@@ -303,11 +322,11 @@ func ApplyConfig(config logconfig.Config) (logShutdownFn func(), err error) {
 			return nil, err
 		}
 		attachBufferWrapper(fluentSinkInfo, fc.CommonSinkConfig.Buffering, closer)
-		attachSinkInfo(fluentSinkInfo, &fc.Channels)
+		attachSinkInfo(fluentSinkInfo, &fc.Channels, logconfig.FluentSinkType, name)
 	}
 
 	// Create the HTTP sinks.
-	for _, fc := range config.Sinks.HTTPServers {
+	for name, fc := range config.Sinks.HTTPServers {
 		if fc.Filter == severity.NONE {
 			continue
 		}
@@ -316,18 +335,21 @@ func ApplyConfig(config logconfig.Config) (logShutdownFn func(), err error) {
 			return nil, err
 		}
 		attachBufferWrapper(httpSinkInfo, fc.CommonSinkConfig.Buffering, closer)
-		attachSinkInfo(httpSinkInfo, &fc.Channels)
+		attachSinkInfo(httpSinkInfo, &fc.Channels, logconfig.HTTPSinkType, name)
 	}
 
-	// Prepend the interceptor sink to all channels.
-	// We prepend it because we want the interceptors
+	// Prepend the interceptor and recent entries sinks to all channels.
+	// We prepend them because we want the interceptors
 	// to see every event before they make their way to disk/network.
 	interceptorSinkInfo := logging.newInterceptorSinkInfo()
+	recentEntriesSinkInfo := logging.newRecentEntriesSinkInfo()
 	for _, l := range chans {
-		l.sinkInfos = append([]*sinkInfo{interceptorSinkInfo}, l.sinkInfos...)
+		l.sinkInfos = append([]*sinkInfo{interceptorSinkInfo, recentEntriesSinkInfo}, l.sinkInfos...)
 	}
 
 	logging.setChannelLoggers(chans, &stderrSinkInfo)
+	logging.setOverridableSinks(overridableSinks,
+		[]*sinkInfo{interceptorSinkInfo, recentEntriesSinkInfo})
 	setActive()
 
 	return logShutdownFn, nil
@@ -0,0 +1,366 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// siemDialect identifies one of the record formats understood by
+// Security Information and Event Management (SIEM) systems.
+type siemDialect int
+
+const (
+	// dialectCEF is the ArcSight Common Event Format.
+	dialectCEF siemDialect = iota
+	// dialectLEEF is the IBM QRadar Log Event Extended Format, version 2.0.
+	dialectLEEF
+)
+
+const (
+	siemVendor  = "Cockroach Labs"
+	siemProduct = "CockroachDB"
+
+	// leefTimeFormat is the format of the devTime attribute, and
+	// leefTimeFormatSpec its description in the notation expected
+	// by the devTimeFormat attribute.
+	leefTimeFormat     = "2006-01-02T15:04:05.000-0700"
+	leefTimeFormatSpec = "yyyy-MM-dd'T'HH:mm:ss.SSSZ"
+)
+
+// siemFieldMappings maps the fields of the structured event payloads
+// to the standard CEF keys and LEEF attributes with the same meaning.
+// Fields not listed here are reported under a custom key built by
+// prefixing the field name with "crdb".
+var siemFieldMappings = map[string][2]string{
+	"User":       {"suser", "usrName"},
+	"ErrorText":  {"reason", "reason"},
+	"AccessMode": {"act", "action"},
+}
+
+// siemSkippedFields are the fields of the structured event payloads which
+// are already reported in the record header or common attributes.
+var siemSkippedFields = map[string]bool{
+	"EventType": true,
+	"Timestamp": true,
+}
+
+type formatCEF struct{}
+
+func (formatCEF) formatterName() string { return "cef" }
+
+func (formatCEF) formatEntry(entry logEntry) *buffer { return formatSIEM(entry, dialectCEF) }
+
+func (formatCEF) doc() string { return formatSIEMDoc(dialectCEF) }
+
+func (formatCEF) contentType() string { return "text/plain" }
+
+type formatLEEF struct{}
+
+func (formatLEEF) formatterName() string { return "leef" }
+
+func (formatLEEF) formatEntry(entry logEntry) *buffer { return formatSIEM(entry, dialectLEEF) }
+
+func (formatLEEF) doc() string { return formatSIEMDoc(dialectLEEF) }
+
+func (formatLEEF) contentType() string { return "text/plain" }
+
+func formatSIEMDoc(dialect siemDialect) string {
+	var buf strings.Builder
+	if dialect == dialectCEF {
+		buf.WriteString(`This format emits log entries as ArcSight Common Event Format
+(CEF) records, for consumption by SIEM systems. Each entry is
+emitted on a single line, of the form:
+
+    CEF:0|Cockroach Labs|CockroachDB|<version>|<event type>|<name>|<severity>|<extensions>
+
+The extensions are space-separated ` + "`key=value`" + ` pairs, with the
+timestamp of the event in milliseconds since the Unix epoch
+reported as ` + "`rt`" + `.
+`)
+	} else {
+		buf.WriteString(`This format emits log entries as IBM QRadar Log Event Extended
+Format (LEEF) 2.0 records, for consumption by SIEM systems. Each entry
+is emitted on a single line, of the form:
+
+    LEEF:2.0|Cockroach Labs|CockroachDB|<version>|<event type>|x09|<attributes>
+
+The attributes are tab-separated ` + "`key=value`" + ` pairs, with the
+timestamp of the event reported as ` + "`devTime`" + ` and the
+severity as ` + "`sev`" + `.
+`)
+	}
+	buf.WriteString(`
+The event type is the ` + "`EventType`" + ` of structured events, ` + "`log_message`" + `
+for unstructured entries and ` + "`log_header`" + ` for the log file headers.
+The severity is reported on the 0-10 scale used by SIEM systems: 3 for
+INFO, 6 for WARNING, 8 for ERROR and 10 for FATAL.
+
+The following attributes are reported when available:
+
+| Attribute | Description |
+|-----------|-------------|
+| ` + "`cat`" + ` | The logging channel. |
+| ` + "`crdbClusterID`" + ` | The cluster ID. |
+| ` + "`crdbNodeID`" + ` | The node ID. |
+| ` + "`crdbTenantID`" + ` | The tenant ID. |
+| ` + "`crdbInstanceID`" + ` | The SQL instance ID. |
+| ` + "`crdbFile`" + ` | The source file and line which emitted the entry. |
+| ` + "`crdbRedactable`" + ` | Whether the payload is redactable: 1 or 0. |
+| ` + "`crdbTags`" + ` | The logging context tags, as comma-separated ` + "`key=value`" + ` pairs. |
+| ` + "`msg`" + ` | The message of unstructured entries. |
+| ` + "`crdbStacks`" + ` | The goroutine stacks, for fatal events. |
+
+The fields of structured events are reported as separate attributes.
+The fields with a standard counterpart are reported under the standard
+name:
+
+| Event field | CEF key | LEEF attribute |
+|-------------|---------|----------------|
+| ` + "`User`" + ` | ` + "`suser`" + ` | ` + "`usrName`" + ` |
+| ` + "`ErrorText`" + ` | ` + "`reason`" + ` | ` + "`reason`" + ` |
+| ` + "`AccessMode`" + ` | ` + "`act`" + ` | ` + "`action`" + ` |
+
+The other fields are reported under their name prefixed by ` + "`crdb`" + `,
+for example ` + "`crdbStatement`" + `. String values are reported unquoted;
+other values, including nested objects, are reported as JSON.
+
+When the entry is marked as redactable, the values may contain
+redaction markers, as with the other formats.
+
+This format cannot be parsed back by ` + "`cockroach debug merge-logs`" + `.
+It is intended for network sinks and for log files collected by SIEM
+agents.
+`)
+	return buf.String()
+}
+
+// siemSeverity converts a logging severity to the 0-10 scale used by
+// CEF and LEEF.
+func siemSeverity(sev Severity) int {
+	switch sev {
+	case severity.INFO:
+		return 3
+	case severity.WARNING:
+		return 6
+	case severity.ERROR:
+		return 8
+	case severity.FATAL:
+		return 10
+	default:
+		return 0
+	}
+}
+
+// siemWriter emits the parts of a SIEM record with the escaping
+// required by the dialect.
+type siemWriter struct {
+	buf     *buffer
+	dialect siemDialect
+	// first is set until the first attribute has been written.
+	first bool
+}
+
+// header writes a field of the record header, followed by the
+// header separator.
+func (w *siemWriter) header(s string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', '|':
+			if w.dialect == dialectCEF {
+				w.buf.WriteByte('\\')
+			}
+			w.buf.WriteByte(c)
+		case '\n', '\r', '\t':
+			w.buf.WriteByte(' ')
+		default:
+			w.buf.WriteByte(c)
+		}
+	}
+	w.buf.WriteByte('|')
+}
+
+// attr writes one key=value attribute.
+func (w *siemWriter) attr(key, val string) {
+	if !w.first {
+		if w.dialect == dialectCEF {
+			w.buf.WriteByte(' ')
+		} else {
+			w.buf.WriteByte('\t')
+		}
+	}
+	w.first = false
+	w.buf.WriteString(key)
+	w.buf.WriteByte('=')
+	for i := 0; i < len(val); i++ {
+		switch c := val[i]; c {
+		case '\\':
+			w.buf.WriteString(`\\`)
+		case '=':
+			if w.dialect == dialectCEF {
+				w.buf.WriteByte('\\')
+			}
+			w.buf.WriteByte('=')
+		case '\n':
+			w.buf.WriteString(`\n`)
+		case '\r':
+			w.buf.WriteString(`\r`)
+		case '\t':
+			if w.dialect == dialectLEEF {
+				w.buf.WriteString(`\t`)
+			} else {
+				w.buf.WriteByte(c)
+			}
+		default:
+			w.buf.WriteByte(c)
+		}
+	}
+}
+
+// intAttr writes one attribute with an integer value.
+func (w *siemWriter) intAttr(key string, val int) {
+	n := w.buf.someDigits(0, val)
+	w.attr(key, string(w.buf.tmp[:n]))
+}
+
+func formatSIEM(entry logEntry, dialect siemDialect) *buffer {
+	buf := getBuffer()
+	w := siemWriter{buf: buf, dialect: dialect, first: true}
+
+	eventType := "log_message"
+	if entry.header {
+		eventType = "log_header"
+	} else if entry.structured {
+		eventType = siemEventType(entry.payload.message)
+	}
+	sev := siemSeverity(entry.sev)
+
+	if dialect == dialectCEF {
+		buf.WriteString("CEF:0|")
+	} else {
+		buf.WriteString("LEEF:2.0|")
+	}
+	w.header(siemVendor)
+	w.header(siemProduct)
+	w.header(entry.version)
+	w.header(eventType)
+	if dialect == dialectCEF {
+		// The event name.
+		w.header(eventType)
+		n := buf.someDigits(0, sev)
+		buf.Write(buf.tmp[:n])
+		buf.WriteByte('|')
+		w.intAttr("rt", int(entry.ts/1000000))
+	} else {
+		// The attribute delimiter.
+		w.header("x09")
+		w.attr("devTime", timeutil.Unix(0, entry.ts).Format(leefTimeFormat))
+		w.attr("devTimeFormat", leefTimeFormatSpec)
+		w.intAttr("sev", sev)
+	}
+
+	if !entry.header {
+		w.attr("cat", entry.ch.String())
+	}
+	if entry.clusterID != "" {
+		w.attr("crdbClusterID", entry.clusterID)
+	}
+	if entry.nodeID != "" {
+		w.attr("crdbNodeID", entry.nodeID)
+	}
+	if entry.tenantID != "" {
+		w.attr("crdbTenantID", entry.tenantID)
+	}
+	if entry.sqlInstanceID != "" {
+		w.attr("crdbInstanceID", entry.sqlInstanceID)
+	}
+	if entry.file != "" {
+		n := buf.someDigits(0, entry.line)
+		w.attr("crdbFile", entry.file+":"+string(buf.tmp[:n]))
+	}
+	if entry.payload.redactable {
+		w.attr("crdbRedactable", "1")
+	} else {
+		w.attr("crdbRedactable", "0")
+	}
+	if entry.payload.tags != nil {
+		tbuf := getBuffer()
+		entry.payload.tags.formatToBuffer(tbuf)
+		w.attr("crdbTags", tbuf.String())
+		putBuffer(tbuf)
+	}
+
+	if entry.structured {
+		formatSIEMEvent(&w, entry.payload.message)
+	} else {
+		w.attr("msg", entry.payload.message)
+	}
+
+	if len(entry.stacks) > 0 {
+		w.attr("crdbStacks", string(entry.stacks))
+	}
+	buf.WriteByte('\n')
+	return buf
+}
+
+// siemEventType extracts the event type from the JSON payload of a
+// structured entry.
+func siemEventType(payload string) string {
+	var e struct{ EventType string }
+	if err := json.Unmarshal([]byte("{"+payload+"}"), &e); err != nil || e.EventType == "" {
+		return "unknown"
+	}
+	return e.EventType
+}
+
+// formatSIEMEvent emits the fields of the JSON payload of a structured
+// entry as separate attributes, in the order of the payload.
+func formatSIEMEvent(w *siemWriter, payload string) {
+	d := json.NewDecoder(strings.NewReader("{" + payload + "}"))
+	if _, err := d.Token(); err != nil {
+		w.attr("msg", payload)
+		return
+	}
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return
+		}
+		key, ok := t.(string)
+		if !ok {
+			return
+		}
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			return
+		}
+		if siemSkippedFields[key] {
+			continue
+		}
+		val := string(raw)
+		if len(raw) > 0 && raw[0] == '"' {
+			var s string
+			if err := json.Unmarshal(raw, &s); err == nil {
+				val = s
+			}
+		}
+		if m, ok := siemFieldMappings[key]; ok {
+			key = m[w.dialect]
+		} else {
+			key = "crdb" + key
+		}
+		w.attr(key, val)
+	}
+}
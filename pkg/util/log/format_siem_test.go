@@ -0,0 +1,101 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/datadriven"
+	"github.com/cockroachdb/logtags"
+)
+
+func TestSIEMFormats(t *testing.T) {
+	defer build.TestingOverrideTag("v999.0.0")()
+
+	tm, err := time.Parse(MessageTimeFormat, "060102 15:04:05.654321")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	ctx = logtags.AddTag(ctx, "noval", nil)
+	ctx = logtags.AddTag(ctx, "s", "1")
+	ctx = logtags.AddTag(ctx, "long", "2")
+
+	testCases := []logEntry{
+		// Header entry.
+		func() logEntry {
+			e := makeUnstructuredEntry(ctx, 0, 0, 0, true, "hello %s", "world")
+			e.header = true
+			return e
+		}(),
+		// Normal (non-header) entries.
+		{},
+		{idPayload: idPayload{clusterID: "abc", nodeID: "123"}},
+		{idPayload: idPayload{tenantID: "456", sqlInstanceID: "123"}},
+		makeStructuredEntry(ctx, severity.INFO, channel.DEV, 0, &logpb.TestingStructuredLogEvent{
+			CommonEventDetails: logpb.CommonEventDetails{
+				Timestamp: 123,
+				EventType: "rename_database",
+			},
+			Channel: logpb.Channel_SQL_SCHEMA,
+			Event:   "rename from `hello` to `world`",
+		}),
+		// A structured entry with mapped fields and nested values.
+		{
+			sev:        severity.INFO,
+			ch:         channel.SENSITIVE_ACCESS,
+			structured: true,
+			payload: entryPayload{
+				message: `"Timestamp":123,"EventType":"sensitive_table_access",` +
+					`"User":"‹root›","AccessMode":"r","Statement":"SELECT * FROM t WHERE a = 'x|y'",` +
+					`"Tag":"SELECT","PlaceholderValues":["1","2"]`,
+			},
+		},
+		makeUnstructuredEntry(ctx, severity.WARNING, channel.OPS, 0, false, "hello %s", "world"),
+		makeUnstructuredEntry(ctx, severity.ERROR, channel.HEALTH, 0, true, "hello %s", "world"),
+		// Special characters need escaping.
+		makeUnstructuredEntry(ctx, severity.FATAL, channel.OPS, 0, false, "a=b\\c\n\td"),
+	}
+
+	formats := []logFormatter{
+		formatCEF{},
+		formatLEEF{},
+	}
+
+	// We only use the datadriven framework for the ability to rewrite the output.
+	datadriven.RunTest(t, "testdata/siem", func(t *testing.T, _ *datadriven.TestData) string {
+		var buf bytes.Buffer
+		for _, tc := range testCases {
+			// override non-deterministic fields to stabilize the expected output.
+			tc.ts = tm.UnixNano()
+			tc.line = 123
+			tc.gid = 11
+
+			buf.WriteString("#\n")
+			for _, f := range formats {
+				b := f.formatEntry(tc)
+				fmt.Fprintf(&buf, "%4s: %s", f.formatterName(), b.String())
+				putBuffer(b)
+			}
+		}
+
+		return buf.String()
+	})
+}
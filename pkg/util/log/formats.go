@@ -41,6 +41,7 @@ var formatters = func() map[string]logFormatter {
 	r := func(f logFormatter) {
 		m[f.formatterName()] = f
 	}
+	r(formatCEF{})
 	r(formatCrdbV1{})
 	r(formatCrdbV1WithCounter{})
 	r(formatCrdbV1TTY{})
@@ -51,6 +52,7 @@ var formatters = func() map[string]logFormatter {
 	r(formatFluentJSONFull{})
 	r(formatJSONCompact{})
 	r(formatJSONFull{})
+	r(formatLEEF{})
 	return m
 }()
 
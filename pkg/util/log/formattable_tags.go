@@ -140,6 +140,26 @@ func (f formattableTags) redactTagValues(preserveMarkers bool) (res formattableT
 	return res
 }
 
+// hashTagValues replaces the values by a hash of their contents. This
+// is used when converting a redactable=false entry to hashed output.
+func (f formattableTags) hashTagValues(preserveMarkers bool) (res formattableTags) {
+	res = make([]byte, 0, len(f))
+	fi := formattableTagsIterator{tags: []byte(f)}
+	for {
+		key, val, done := fi.next()
+		if done {
+			break
+		}
+		res = append(res, key...)
+		res = append(res, 0)
+		if len(val) > 0 {
+			res = append(res, hashedMarker(val, preserveMarkers)...)
+		}
+		res = append(res, 0)
+	}
+	return res
+}
+
 // formatToSafeWriter emits the tags to a safe writer, which means preserve
 // redaction markers that were there to start with, if any, but be careful
 // not to introduce imbalanced redaction markers.
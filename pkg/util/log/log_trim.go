@@ -0,0 +1,120 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+)
+
+// trimContextEntries is the number of log entries retained before and after
+// an entry that reports a panic or a fatal error, when log entries are
+// trimmed to fit a size budget.
+const trimContextEntries = 20
+
+// trimEntryOverhead is an estimate of the number of bytes added to the
+// message and tags of a log entry when it is formatted, for the header
+// containing the severity, timestamp, goroutine, file, line and counter.
+const trimEntryOverhead = 64
+
+// logEntrySize estimates the size of the given log entry once formatted.
+func logEntrySize(e *logpb.Entry) int64 {
+	return int64(len(e.Message) + len(e.Tags) + len(e.File) + trimEntryOverhead)
+}
+
+// isCrashLogEntry returns true if the given log entry reports a fatal error
+// or a panic.
+func isCrashLogEntry(e *logpb.Entry) bool {
+	return e.Severity == severity.FATAL || strings.Contains(e.Message, "panic:")
+}
+
+// TrimEntries reduces the given log entries, sorted from oldest to newest,
+// to fit within the given budget in bytes. The entries surrounding panics and
+// fatal errors are retained in priority, followed by the newest entries. Each
+// sequence of omitted entries is replaced by a single entry reporting how many
+// entries were omitted. The second return value is the total number of omitted
+// entries.
+func TrimEntries(entries []logpb.Entry, budget int64) ([]logpb.Entry, int) {
+	var total int64
+	for i := range entries {
+		total += logEntrySize(&entries[i])
+	}
+	if budget <= 0 || total <= budget {
+		return entries, 0
+	}
+
+	keep := make([]bool, len(entries))
+	remaining := budget
+	// keepRange retains the entries in [start, end), newest first, as long as
+	// the budget allows.
+	keepRange := func(start, end int) {
+		for i := end - 1; i >= start && remaining > 0; i-- {
+			if keep[i] {
+				continue
+			}
+			if sz := logEntrySize(&entries[i]); sz <= remaining {
+				keep[i] = true
+				remaining -= sz
+			} else {
+				remaining = 0
+			}
+		}
+	}
+	// First the context around the most recent crashes...
+	for i := len(entries) - 1; i >= 0 && remaining > 0; i-- {
+		if !isCrashLogEntry(&entries[i]) {
+			continue
+		}
+		start, end := i-trimContextEntries, i+trimContextEntries+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(entries) {
+			end = len(entries)
+		}
+		// The crash itself is retained before its surroundings.
+		keepRange(i, i+1)
+		keepRange(i+1, end)
+		keepRange(start, i)
+	}
+	// ... then the newest entries.
+	keepRange(0, len(entries))
+
+	res := make([]logpb.Entry, 0, len(entries))
+	omitted, gap := 0, 0
+	flushGap := func(at int) {
+		if gap == 0 {
+			return
+		}
+		res = append(res, logpb.Entry{
+			Severity:   severity.INFO,
+			Time:       entries[at-gap].Time,
+			File:       "log",
+			Message:    fmt.Sprintf("%d log entries omitted to fit the size budget", gap),
+			Redactable: true,
+		})
+		omitted += gap
+		gap = 0
+	}
+	for i := range entries {
+		if !keep[i] {
+			gap++
+			continue
+		}
+		flushGap(i)
+		res = append(res, entries[i])
+	}
+	flushGap(len(entries))
+	return res, omitted
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrimEntries(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var entries []logpb.Entry
+	for i := 0; i < 200; i++ {
+		e := logpb.Entry{Severity: severity.INFO, Time: int64(i), Message: fmt.Sprintf("entry %d", i)}
+		if i == 50 {
+			e.Severity = severity.FATAL
+			e.Message = "boom"
+		}
+		entries = append(entries, e)
+	}
+	size := func(es []logpb.Entry) (n int64) {
+		for i := range es {
+			n += logEntrySize(&es[i])
+		}
+		return n
+	}
+
+	// Entries that fit are left untouched.
+	res, omitted := TrimEntries(entries, size(entries))
+	require.Equal(t, entries, res)
+	require.Zero(t, omitted)
+
+	// Room for the context around the crash and 30 more entries.
+	budget := size(entries[50-trimContextEntries:51+trimContextEntries]) + size(entries[170:])
+	res, omitted = TrimEntries(entries, budget)
+	var msgs []string
+	for _, e := range res {
+		msgs = append(msgs, e.Message)
+	}
+	require.Equal(t, []string{
+		"30 log entries omitted to fit the size budget",
+		"entry 30",
+	}, msgs[:2])
+	require.Contains(t, msgs, "boom")
+	require.Contains(t, msgs, "entry 70")
+	require.Contains(t, msgs, "99 log entries omitted to fit the size budget")
+	require.Equal(t, "entry 199", msgs[len(msgs)-1])
+	require.Equal(t, 129, omitted)
+	for _, e := range res {
+		if strings.Contains(e.Message, "omitted") {
+			require.True(t, e.Redactable)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package logconfig
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Sink types, as listed in OverrideLimits.
+const (
+	FileSinkType   = "file"
+	FluentSinkType = "fluent"
+	HTTPSinkType   = "http"
+	StderrSinkType = "stderr"
+)
+
+// Overrides describes changes to the configuration of the sinks of a
+// running process, on top of the configuration it was started with.
+// Secondary tenants use them to configure the logging of their SQL
+// servers through SQL.
+//
+// Overrides can only refer to the sinks defined by the startup
+// configuration. They can change which channels are routed to a sink
+// and at which severity, whether the sink redacts sensitive
+// information, and lower the maximum size of a file group.
+//
+// For example:
+//
+//	file-groups:
+//	  default:
+//	    channels: {WARNING: [DEV], INFO: [OPS, HEALTH]}
+//	  sql-audit:
+//	    channels: [SENSITIVE_ACCESS, SQL_EXEC]
+//	    redact: true
+//	    max-group-size: 10MiB
+//	stderr:
+//	  channels: {ERROR: all}
+type Overrides struct {
+	FileGroups    map[string]*SinkOverride `yaml:"file-groups,omitempty"`
+	FluentServers map[string]*SinkOverride `yaml:"fluent-servers,omitempty"`
+	HTTPServers   map[string]*SinkOverride `yaml:"http-servers,omitempty"`
+	Stderr        *SinkOverride            `yaml:",omitempty"`
+}
+
+// SinkOverride describes the changes to the configuration of a sink.
+// The fields left unspecified keep the value from the startup
+// configuration.
+type SinkOverride struct {
+	// Channels replaces the channels routed to the sink. A channel
+	// listed without a severity is routed at severity INFO.
+	Channels *ChannelFilters `yaml:",omitempty,flow"`
+
+	// Redact replaces the redact parameter of the sink.
+	Redact *bool `yaml:",omitempty"`
+
+	// MaxGroupSize lowers the maximum combined size of the files of a
+	// file group. It cannot raise it above the value from the startup
+	// configuration. Only valid for file groups.
+	MaxGroupSize *ByteSize `yaml:"max-group-size,omitempty"`
+}
+
+// OverrideLimits restricts the Overrides which can be applied.
+type OverrideLimits struct {
+	// AllowedSinkTypes lists the types of the sinks which can be
+	// overridden.
+	AllowedSinkTypes []string
+
+	// MaxGroupSize, if non-zero, is the upper bound on the
+	// max-group-size of the overridden file groups.
+	MaxGroupSize ByteSize
+}
+
+// ParseOverrides parses and validates overrides expressed in YAML.
+func ParseOverrides(s string) (Overrides, error) {
+	var o Overrides
+	if err := yaml.UnmarshalStrict([]byte(s), &o); err != nil {
+		return Overrides{}, err
+	}
+	if err := o.Validate(); err != nil {
+		return Overrides{}, err
+	}
+	return o, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (o Overrides) String() string {
+	b, err := yaml.Marshal(&o)
+	if err != nil {
+		return "<INVALID OVERRIDES: " + err.Error() + ">"
+	}
+	return string(b)
+}
+
+// Validate checks the overrides and propagates the channel filters
+// into their accessor fields.
+func (o *Overrides) Validate() error {
+	return o.ForEachSink(func(sinkType, name string, so *SinkOverride) error {
+		if so == nil {
+			return errors.Newf("%s sink %q: no override specified", sinkType, name)
+		}
+		if so.MaxGroupSize != nil && sinkType != FileSinkType {
+			return errors.Newf("%s sink %q: max-group-size is only valid for file groups", sinkType, name)
+		}
+		if so.Channels != nil {
+			if err := so.Channels.Validate(logpb.Severity_INFO); err != nil {
+				return errors.Wrapf(err, "%s sink %q", sinkType, name)
+			}
+		}
+		return nil
+	})
+}
+
+// CheckLimits verifies that the overrides are within the given limits.
+func (o *Overrides) CheckLimits(l OverrideLimits) error {
+	allowed := make(map[string]struct{}, len(l.AllowedSinkTypes))
+	for _, t := range l.AllowedSinkTypes {
+		allowed[strings.ToLower(strings.TrimSpace(t))] = struct{}{}
+	}
+	return o.ForEachSink(func(sinkType, name string, so *SinkOverride) error {
+		if _, ok := allowed[sinkType]; !ok {
+			return errors.Newf("%s sink %q: %s sinks cannot be configured", sinkType, name, sinkType)
+		}
+		if l.MaxGroupSize != 0 && so.MaxGroupSize != nil && *so.MaxGroupSize > l.MaxGroupSize {
+			return errors.Newf("%s sink %q: max-group-size cannot exceed %s",
+				sinkType, name, l.MaxGroupSize)
+		}
+		return nil
+	})
+}
+
+// ForEachSink calls fn for every overridden sink, in a deterministic
+// order, and stops at the first error encountered. The stderr sink is
+// named "stderr".
+func (o *Overrides) ForEachSink(fn func(sinkType, name string, so *SinkOverride) error) error {
+	for _, group := range []struct {
+		sinkType string
+		sinks    map[string]*SinkOverride
+	}{
+		{FileSinkType, o.FileGroups},
+		{FluentSinkType, o.FluentServers},
+		{HTTPSinkType, o.HTTPServers},
+	} {
+		names := make([]string, 0, len(group.sinks))
+		for name := range group.sinks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := fn(group.sinkType, name, group.sinks[name]); err != nil {
+				return err
+			}
+		}
+	}
+	if o.Stderr != nil {
+		return fn(StderrSinkType, StderrSinkType, o.Stderr)
+	}
+	return nil
+}
+
+// IsEmpty returns true if no sink is overridden.
+func (o *Overrides) IsEmpty() bool {
+	return len(o.FileGroups) == 0 && len(o.FluentServers) == 0 &&
+		len(o.HTTPServers) == 0 && o.Stderr == nil
+}
+
+// Lookup returns the override for the given sink, or nil if the sink
+// isn't overridden.
+func (o *Overrides) Lookup(sinkType, name string) *SinkOverride {
+	switch sinkType {
+	case FileSinkType:
+		return o.FileGroups[name]
+	case FluentSinkType:
+		return o.FluentServers[name]
+	case HTTPSinkType:
+		return o.HTTPServers[name]
+	case StderrSinkType:
+		return o.Stderr
+	default:
+		return nil
+	}
+}
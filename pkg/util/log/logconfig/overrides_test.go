@@ -0,0 +1,126 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package logconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseOverrides(t *testing.T) {
+	limits := OverrideLimits{
+		AllowedSinkTypes: []string{"file", " STDERR"},
+		MaxGroupSize:     10 << 20,
+	}
+	testData := []struct {
+		input       string
+		parseErr    string
+		limitsErr   string
+		overridden  []string
+		channelsOf  string
+		numChannels int
+	}{
+		{input: ``},
+		{
+			input:       `file-groups: {default: {channels: [OPS, HEALTH]}}`,
+			overridden:  []string{"file/default"},
+			channelsOf:  "default",
+			numChannels: 2,
+		},
+		{
+			input:       `file-groups: {audit: {channels: {WARNING: [DEV], INFO: SESSIONS}, redact: true, max-group-size: 1MiB}}`,
+			overridden:  []string{"file/audit"},
+			channelsOf:  "audit",
+			numChannels: 2,
+		},
+		{
+			input:      `{stderr: {redact: true}, file-groups: {b: {redact: false}, a: {redact: true}}}`,
+			overridden: []string{"file/a", "file/b", "stderr/stderr"},
+		},
+		{
+			input:    `file-groups: {default: {unknown: true}}`,
+			parseErr: `field unknown not found`,
+		},
+		{
+			input:    `file-groups: {default: }`,
+			parseErr: `file sink "default": no override specified`,
+		},
+		{
+			input:    `stderr: {max-group-size: 1MiB}`,
+			parseErr: `max-group-size is only valid for file groups`,
+		},
+		{
+			input:    `file-groups: {default: {channels: [UNKNOWN_CHANNEL]}}`,
+			parseErr: `unknown channel`,
+		},
+		{
+			input:      `http-servers: {collector: {redact: true}}`,
+			overridden: []string{"http/collector"},
+			limitsErr:  `http sink "collector": http sinks cannot be configured`,
+		},
+		{
+			input:      `file-groups: {default: {max-group-size: 1GiB}}`,
+			overridden: []string{"file/default"},
+			limitsErr:  `file sink "default": max-group-size cannot exceed 10MiB`,
+		},
+	}
+
+	for _, tc := range testData {
+		t.Run(tc.input, func(t *testing.T) {
+			o, err := ParseOverrides(tc.input)
+			if tc.parseErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.parseErr) {
+					t.Fatalf("expected error %q, got %v", tc.parseErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var overridden []string
+			if err := o.ForEachSink(func(sinkType, name string, so *SinkOverride) error {
+				overridden = append(overridden, sinkType+"/"+name)
+				if so != o.Lookup(sinkType, name) {
+					t.Errorf("lookup of %s sink %q does not return its override", sinkType, name)
+				}
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(overridden, ",") != strings.Join(tc.overridden, ",") {
+				t.Errorf("expected overridden sinks %v, got %v", tc.overridden, overridden)
+			}
+			if tc.channelsOf != "" {
+				if n := len(o.FileGroups[tc.channelsOf].Channels.AllChannels.Channels); n != tc.numChannels {
+					t.Errorf("expected %d channels, got %d", tc.numChannels, n)
+				}
+			}
+
+			// The overrides must survive a round-trip through their
+			// string representation.
+			if _, err := ParseOverrides(o.String()); err != nil {
+				t.Fatalf("could not parse %q: %v", o.String(), err)
+			}
+
+			err = o.CheckLimits(limits)
+			if tc.limitsErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.limitsErr) {
+					t.Fatalf("expected error %q, got %v", tc.limitsErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/errors"
+)
+
+// overridableSink is a sink created by ApplyConfig, whose configuration
+// can be changed afterwards by ApplyOverrides.
+type overridableSink struct {
+	sinkType, name string
+
+	// base is the sinkInfo created by ApplyConfig, and channels are the
+	// channels it connected to it.
+	base     *sinkInfo
+	channels []Channel
+
+	// active is the sinkInfo currently connected to the channels in
+	// lieu of base, if overrides are applied to the sink.
+	active *sinkInfo
+
+	// fileSink and maxGroupSize are the underlying sink and its
+	// configured maximum group size, for file sinks.
+	fileSink     *fileSink
+	maxGroupSize int64
+}
+
+func (l *loggingT) setOverridableSinks(sinks []overridableSink, prefix []*sinkInfo) {
+	l.overrides.Lock()
+	defer l.overrides.Unlock()
+	l.overrides.sinks = sinks
+	l.overrides.prefix = prefix
+}
+
+// ApplyOverrides changes the configuration of the sinks created by the
+// last call to ApplyConfig as specified by the overrides, which replace
+// any overrides applied previously. In particular, applying empty
+// overrides restores the configuration set up by ApplyConfig.
+//
+// The overrides must have been validated.
+func ApplyOverrides(o logconfig.Overrides) error {
+	logging.overrides.Lock()
+	defer logging.overrides.Unlock()
+	sinks := logging.overrides.sinks
+	if len(sinks) == 0 {
+		return errors.New("logging is not configured")
+	}
+
+	// Check that all the overridden sinks exist before changing anything.
+	if err := o.ForEachSink(func(sinkType, name string, _ *logconfig.SinkOverride) error {
+		for i := range sinks {
+			if sinks[i].sinkType == sinkType && sinks[i].name == name {
+				return nil
+			}
+		}
+		return errors.Newf("%s sink %q is not defined in the logging configuration", sinkType, name)
+	}); err != nil {
+		return err
+	}
+
+	chans := make(map[Channel]*loggerT, len(logpb.Channel_name)-1)
+	for chi := range logpb.Channel_name {
+		ch := Channel(chi)
+		if ch == logpb.Channel_CHANNEL_MAX {
+			continue
+		}
+		chans[ch] = &loggerT{sinkInfos: append([]*sinkInfo(nil), logging.overrides.prefix...)}
+	}
+
+	var stderrSinkInfo *sinkInfo
+	for i := range sinks {
+		s := &sinks[i]
+		info, channels := s.base, s.channels
+		maxGroupSize := s.maxGroupSize
+		if so := o.Lookup(s.sinkType, s.name); so != nil {
+			info = s.derive()
+			if so.Channels != nil {
+				info.threshold.setAll(severity.NONE)
+				info.applyFilters(*so.Channels)
+				channels = so.Channels.AllChannels.Channels
+			}
+			if so.Redact != nil {
+				info.redact = *so.Redact
+				info.editor = getEditor(SelectEditMode(info.redact, info.redactable))
+			}
+			if so.MaxGroupSize != nil && int64(*so.MaxGroupSize) < maxGroupSize {
+				maxGroupSize = int64(*so.MaxGroupSize)
+			}
+			s.active = info
+		} else if s.active != nil {
+			// Revert to the base sinkInfo, carrying the counters over.
+			atomic.StoreUint64(&s.base.msgCount, atomic.LoadUint64(&s.active.msgCount))
+			atomic.StoreUint64(&s.base.outputErrCount, atomic.LoadUint64(&s.active.outputErrCount))
+			s.active = nil
+		}
+		if s.fileSink != nil {
+			atomic.StoreInt64(&s.fileSink.logFilesCombinedMaxSize, maxGroupSize)
+		}
+		if s.sinkType == logconfig.StderrSinkType {
+			stderrSinkInfo = info
+		}
+		for _, ch := range channels {
+			l := chans[ch]
+			l.sinkInfos = append(l.sinkInfos, info)
+		}
+	}
+
+	debugLog = chans[channel.DEV]
+	logging.setChannelLoggers(chans, stderrSinkInfo)
+	return nil
+}
+
+// derive returns a copy of the sink's base sinkInfo, whose counters
+// continue from the ones currently in use so that the numbering of the
+// entries of auditable sinks isn't reset.
+func (s *overridableSink) derive() *sinkInfo {
+	current := s.base
+	if s.active != nil {
+		current = s.active
+	}
+	return &sinkInfo{
+		sink:           s.base.sink,
+		threshold:      s.base.threshold,
+		editor:         s.base.editor,
+		formatter:      s.base.formatter,
+		msgCount:       atomic.LoadUint64(&current.msgCount),
+		outputErrCount: atomic.LoadUint64(&current.outputErrCount),
+		criticality:    s.base.criticality,
+		redact:         s.base.redact,
+		redactable:     s.base.redactable,
+	}
+}
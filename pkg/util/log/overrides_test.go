@@ -0,0 +1,97 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	h := logconfig.Holder{Config: logconfig.DefaultConfig()}
+	require.NoError(t, h.Set(`
+file-defaults: {max-group-size: 100MiB}
+sinks:
+  file-groups:
+    audit: {channels: SESSIONS}
+  stderr: {filter: NONE}
+`))
+	require.NoError(t, h.Config.Validate(&sc.logDir))
+
+	TestingResetActive()
+	cleanup, err := ApplyConfig(h.Config)
+	require.NoError(t, err)
+	defer cleanup()
+
+	// findSink returns the sinkInfo of the audit file group connected
+	// to the given channel, or nil if there is none.
+	findSink := func(ch Channel) *sinkInfo {
+		for _, si := range logging.getLogger(ch).sinkInfos {
+			if fs, ok := si.sink.(*fileSink); ok && fs.groupName == "audit" {
+				return si
+			}
+		}
+		return nil
+	}
+	base := findSink(channel.SESSIONS)
+	require.NotNil(t, base)
+	require.Nil(t, findSink(channel.OPS))
+	auditSink := base.sink.(*fileSink)
+	require.Equal(t, int64(100<<20), atomic.LoadInt64(&auditSink.logFilesCombinedMaxSize))
+
+	apply := func(s string) error {
+		o, err := logconfig.ParseOverrides(s)
+		require.NoError(t, err)
+		return ApplyOverrides(o)
+	}
+
+	// Route OPS and SESSIONS to the audit sink, redact it, and shrink it.
+	require.NoError(t, apply(`
+file-groups:
+  audit: {channels: {WARNING: OPS, INFO: SESSIONS}, redact: true, max-group-size: 10MiB}
+`))
+	overridden := findSink(channel.OPS)
+	require.NotNil(t, overridden)
+	require.Equal(t, overridden, findSink(channel.SESSIONS))
+	require.NotEqual(t, base, overridden)
+	require.True(t, overridden.redact)
+	require.Equal(t, severity.WARNING, overridden.threshold.get(channel.OPS))
+	require.Equal(t, severity.INFO, overridden.threshold.get(channel.SESSIONS))
+	require.Equal(t, int64(10<<20), atomic.LoadInt64(&auditSink.logFilesCombinedMaxSize))
+
+	// The interceptor and recent entries sinks remain connected ahead
+	// of the others.
+	require.Equal(t, logging.overrides.prefix, logging.getLogger(channel.OPS).sinkInfos[:2])
+
+	// The maximum group size cannot be raised above the configured one.
+	require.NoError(t, apply(`file-groups: {audit: {max-group-size: 1GiB}}`))
+	require.Equal(t, int64(100<<20), atomic.LoadInt64(&auditSink.logFilesCombinedMaxSize))
+
+	// Overrides cannot refer to sinks which are not configured.
+	require.Error(t, apply(`file-groups: {unknown: {redact: true}}`))
+
+	// Applying empty overrides restores the startup configuration.
+	require.NoError(t, apply(``))
+	require.Equal(t, base, findSink(channel.SESSIONS))
+	require.Nil(t, findSink(channel.OPS))
+	require.False(t, base.redact)
+	require.Equal(t, int64(100<<20), atomic.LoadInt64(&auditSink.logFilesCombinedMaxSize))
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
+)
+
+// recentEntriesMaxBytes is the maximum size of the encoded log entries
+// retained in memory by the recent entries sink.
+const recentEntriesMaxBytes = 1 << 20 // 1 MiB
+
+// SetRecentEntriesRetention enables or disables the retention of the most
+// recent log entries in memory. Retention is disabled by default, since it
+// requires encoding every log entry emitted by the process. Disabling it
+// releases the entries retained so far.
+func SetRecentEntriesRetention(enabled bool) {
+	logging.recentEntries.setEnabled(enabled)
+}
+
+// RecentEntries returns the log entries most recently emitted by this
+// process, across all channels, ordered from oldest to newest. The entries
+// are retained in memory regardless of the logging configuration, up to a
+// fixed size budget, while retention is enabled (see
+// SetRecentEntriesRetention).
+//
+// If include is non-nil, only the entries for which it returns true are
+// returned. If redactSensitiveData is true, the sensitive data in the message
+// and tags of the entries is redacted. Otherwise, it is kept, enclosed within
+// redaction markers.
+func RecentEntries(
+	include func(*logpb.Entry) bool, redactSensitiveData bool,
+) ([]logpb.Entry, error) {
+	return logging.recentEntries.entries(include, redactSensitiveData)
+}
+
+func (l *loggingT) newRecentEntriesSinkInfo() *sinkInfo {
+	si := &sinkInfo{
+		sink:       &l.recentEntries,
+		editor:     getEditor(WithMarkedSensitiveData),
+		formatter:  formatRecentEntry{},
+		redact:     false, // redaction is applied when the entries are retrieved
+		redactable: true,  // keep redaction markers
+	}
+	// Ensure all events are collected across all channels.
+	si.threshold.setAll(severity.INFO)
+	return si
+}
+
+// formatRecentEntry encodes the logpb.Entry in protobuf format, so that it
+// can be retained compactly and decoded when the recent entries are
+// retrieved.
+type formatRecentEntry struct{}
+
+func (formatRecentEntry) formatterName() string { return "proto-recent" }
+func (formatRecentEntry) doc() string           { return "internal only" }
+func (formatRecentEntry) contentType() string   { return "application/octet-stream" }
+func (formatRecentEntry) formatEntry(entry logEntry) *buffer {
+	pEntry := entry.convertToLegacy()
+	buf := getBuffer()
+	// An entry that cannot be encoded results in an empty buffer, which the
+	// sink ignores.
+	if b, err := pEntry.Marshal(); err == nil {
+		buf.Write(b)
+	}
+	return buf
+}
+
+// recentEntriesSink retains the most recent log entries in memory, in
+// encoded form, up to recentEntriesMaxBytes.
+type recentEntriesSink struct {
+	// enabled is set when the entries are to be retained. The sink is
+	// inactive otherwise, so that the entries are not even encoded.
+	enabled syncutil.AtomicBool
+
+	mu struct {
+		syncutil.Mutex
+		// buf holds the encoded entries, from oldest to newest.
+		buf [][]byte
+		// size is the total size of the entries in buf.
+		size int
+	}
+}
+
+func (r *recentEntriesSink) active() bool                { return r.enabled.Get() }
+func (r *recentEntriesSink) attachHints(b []byte) []byte { return b }
+func (r *recentEntriesSink) exitCode() exit.Code         { return exit.UnspecifiedError() }
+
+func (r *recentEntriesSink) output(b []byte, _ sinkOutputOptions) error {
+	// NB: a single huge entry, for example one carrying the stack traces of
+	// all the goroutines, is not retained so that it doesn't evict all the
+	// others.
+	if len(b) == 0 || len(b) > recentEntriesMaxBytes {
+		return nil
+	}
+	// The argument buffer is reused after the call: copy it.
+	entry := append([]byte(nil), b...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.buf = append(r.mu.buf, entry)
+	r.mu.size += len(entry)
+	for r.mu.size > recentEntriesMaxBytes {
+		r.mu.size -= len(r.mu.buf[0])
+		r.mu.buf[0] = nil
+		r.mu.buf = r.mu.buf[1:]
+	}
+	return nil
+}
+
+func (r *recentEntriesSink) setEnabled(enabled bool) {
+	r.enabled.Set(enabled)
+	if !enabled {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.mu.buf = nil
+		r.mu.size = 0
+	}
+}
+
+func (r *recentEntriesSink) entries(
+	include func(*logpb.Entry) bool, redactSensitiveData bool,
+) ([]logpb.Entry, error) {
+	r.mu.Lock()
+	encoded := make([][]byte, len(r.mu.buf))
+	copy(encoded, r.mu.buf)
+	r.mu.Unlock()
+
+	res := make([]logpb.Entry, 0, len(encoded))
+	for _, b := range encoded {
+		var e logpb.Entry
+		if err := e.Unmarshal(b); err != nil {
+			return nil, errors.Wrap(err, "decoding recent log entry")
+		}
+		if include != nil && !include(&e) {
+			continue
+		}
+		if redactSensitiveData {
+			e.Message = string(redact.RedactableString(e.Message).Redact())
+			e.Tags = string(redact.RedactableString(e.Tags).Redact())
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
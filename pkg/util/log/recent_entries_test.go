@@ -0,0 +1,127 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentEntries(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	SetRecentEntriesRetention(true)
+	defer SetRecentEntriesRetention(false)
+
+	ctx := context.Background()
+	Ops.Warningf(ctx, "recent entry with %s", "some secret")
+	Dev.Infof(ctx, "recent entry on another channel")
+
+	find := func(entries []logpb.Entry) *logpb.Entry {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if strings.HasPrefix(entries[i].Message, "recent entry with") {
+				return &entries[i]
+			}
+		}
+		return nil
+	}
+
+	entries, err := RecentEntries(nil /* include */, false /* redactSensitiveData */)
+	require.NoError(t, err)
+	e := find(entries)
+	require.NotNil(t, e)
+	require.Equal(t, channel.OPS, e.Channel)
+	require.Equal(t, severity.WARNING, e.Severity)
+	require.True(t, e.Redactable)
+	require.Equal(t, "recent entry with ‹some secret›", e.Message)
+
+	entries, err = RecentEntries(nil /* include */, true /* redactSensitiveData */)
+	require.NoError(t, err)
+	e = find(entries)
+	require.NotNil(t, e)
+	require.Equal(t, "recent entry with ‹×›", e.Message)
+
+	// The entries can be filtered.
+	entries, err = RecentEntries(func(e *logpb.Entry) bool {
+		return e.Channel == channel.OPS
+	}, false /* redactSensitiveData */)
+	require.NoError(t, err)
+	require.NotNil(t, find(entries))
+	for _, e := range entries {
+		require.Equal(t, channel.OPS, e.Channel)
+	}
+
+	// Disabling the retention releases the entries and stops collecting new
+	// ones.
+	SetRecentEntriesRetention(false)
+	Ops.Warningf(ctx, "recent entry with %s", "retention disabled")
+	entries, err = RecentEntries(nil /* include */, false /* redactSensitiveData */)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestRecentEntriesSinkEviction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var r recentEntriesSink
+	entry := func(msg string) []byte {
+		b, err := (&logpb.Entry{Message: msg}).Marshal()
+		require.NoError(t, err)
+		return b
+	}
+	first := entry("first")
+	require.NoError(t, r.output(first, sinkOutputOptions{}))
+	// An entry larger than the budget is not retained.
+	require.NoError(t, r.output(make([]byte, recentEntriesMaxBytes+1), sinkOutputOptions{}))
+	entries, err := r.entries(nil /* include */, false /* redactSensitiveData */)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "first", entries[0].Message)
+
+	// Filling the budget evicts the oldest entries.
+	filler := entry(strings.Repeat("x", 1000))
+	maxFillers := recentEntriesMaxBytes / len(filler)
+	for i := 0; i <= maxFillers; i++ {
+		require.NoError(t, r.output(filler, sinkOutputOptions{}))
+	}
+	entries, err = r.entries(nil /* include */, false /* redactSensitiveData */)
+	require.NoError(t, err)
+	require.Len(t, entries, maxFillers)
+	for _, e := range entries {
+		require.NotEqual(t, "first", e.Message)
+	}
+}
+
+// BenchmarkRecentEntries measures the overhead of the retention of the recent
+// log entries on the logging hot path.
+func BenchmarkRecentEntries(b *testing.B) {
+	defer Scope(b).Close(b)
+	ctx := context.Background()
+	for _, enabled := range []bool{false, true} {
+		b.Run(fmt.Sprintf("enabled=%t", enabled), func(b *testing.B) {
+			SetRecentEntriesRetention(enabled)
+			defer SetRecentEntriesRetention(false)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				Dev.Infof(ctx, "benchmark entry %d with %s", i, "some data")
+			}
+		})
+	}
+}
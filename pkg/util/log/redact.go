@@ -11,8 +11,11 @@
 package log
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"reflect"
+	"regexp"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/util/encoding/encodingtype"
@@ -31,6 +34,7 @@ const (
 	confValid       = 1
 	withKeepMarkers = 2
 	withRedaction   = 4
+	withHashing     = 8
 
 	// WithFlattenedSensitiveData is the log including sensitive data,
 	// but markers stripped.
@@ -43,6 +47,15 @@ const (
 	// WithoutSensitiveData is the log with the sensitive data redacted,
 	// but markers included.
 	WithoutSensitiveData EditSensitiveData = confValid | withKeepMarkers | withRedaction
+	// WithHashedSensitiveData is the log with the sensitive data
+	// replaced by a deterministic hash of its value, and markers
+	// included. Repeated occurrences of the same sensitive value map to
+	// the same hash, so that they can still be correlated.
+	WithHashedSensitiveData EditSensitiveData = confValid | withKeepMarkers | withRedaction | withHashing
+	// WithHashedSensitiveDataNorMarkers is the log with the sensitive
+	// data replaced by a deterministic hash of its value, and markers
+	// stripped.
+	WithHashedSensitiveDataNorMarkers EditSensitiveData = confValid | withRedaction | withHashing
 )
 
 // KeepRedactable can be used as an argument to SelectEditMode to indicate that
@@ -67,6 +80,13 @@ func SelectEditMode(redact, keepRedactable bool) EditSensitiveData {
 	return editMode
 }
 
+// SelectHashedEditMode is like SelectEditMode with redaction enabled,
+// except that the sensitive data is replaced by a deterministic hash of
+// its value instead of the redaction marker.
+func SelectHashedEditMode(keepRedactable bool) EditSensitiveData {
+	return SelectEditMode(true /* redact */, keepRedactable) | withHashing
+}
+
 type redactEditor func(redactablePackage) redactablePackage
 
 func getEditor(editMode EditSensitiveData) redactEditor {
@@ -113,6 +133,30 @@ func getEditor(editMode EditSensitiveData) redactEditor {
 			}
 			return r
 		}
+	case WithHashedSensitiveData:
+		return func(r redactablePackage) redactablePackage {
+			if r.redactable {
+				r.msg = hashSensitiveData(r.msg, true /* preserveMarkers */)
+				r.tags = formattableTags(hashSensitiveData(r.tags, true /* preserveMarkers */))
+			} else {
+				r.msg = hashedMarker(r.msg, true /* preserveMarkers */)
+				r.tags = r.tags.hashTagValues(true /* preserveMarkers */)
+				r.redactable = true
+			}
+			return r
+		}
+	case WithHashedSensitiveDataNorMarkers:
+		return func(r redactablePackage) redactablePackage {
+			if r.redactable {
+				r.msg = hashSensitiveData(r.msg, false /* preserveMarkers */)
+				r.tags = formattableTags(hashSensitiveData(r.tags, false /* preserveMarkers */))
+				r.redactable = false
+			} else {
+				r.msg = hashedMarker(r.msg, false /* preserveMarkers */)
+				r.tags = r.tags.hashTagValues(false /* preserveMarkers */)
+			}
+			return r
+		}
 	default:
 		panic(errors.AssertionFailedf("unrecognized mode: %v", editMode))
 	}
@@ -121,6 +165,49 @@ func getEditor(editMode EditSensitiveData) redactEditor {
 var redactedMarker = redact.RedactedMarker()
 var strippedMarker = redact.RedactableBytes(redactedMarker).StripMarkers()
 
+// reSensitiveData matches the sensitive parts of a redactable string,
+// including the enclosing markers.
+var reSensitiveData = regexp.MustCompile(
+	string(redact.StartMarker()) + "[^" + string(redact.StartMarker()) + string(redact.EndMarker()) + "]*" +
+		string(redact.EndMarker()))
+
+// hashedMarkerPrefix distinguishes a hashed value from the regular
+// redacted marker in the output.
+const hashedMarkerPrefix = "#"
+
+// hashSensitiveData replaces every sensitive part of the given
+// redactable byte slice by the hash of its value. See hashedMarker.
+func hashSensitiveData(b []byte, preserveMarkers bool) []byte {
+	res := reSensitiveData.ReplaceAllFunc(b, func(m []byte) []byte {
+		v := m[len(redact.StartMarker()) : len(m)-len(redact.EndMarker())]
+		return hashedMarker(v, preserveMarkers)
+	})
+	if !preserveMarkers {
+		// Strip any stray markers left outside of the sensitive parts.
+		res = redact.RedactableBytes(res).StripMarkers()
+	}
+	return res
+}
+
+// hashedMarker returns the replacement for the given sensitive value:
+// a short, deterministic hash of the value, which makes it possible to
+// correlate repeated occurrences of the same value across log entries
+// (and log files) without revealing it. The hash is not keyed;
+// low-entropy values remain subject to dictionary attacks.
+func hashedMarker(v []byte, preserveMarkers bool) []byte {
+	h := sha256.Sum256(v)
+	var res []byte
+	if preserveMarkers {
+		res = append(res, redact.StartMarker()...)
+	}
+	res = append(res, hashedMarkerPrefix...)
+	res = append(res, hex.EncodeToString(h[:4])...)
+	if preserveMarkers {
+		res = append(res, redact.EndMarker()...)
+	}
+	return res
+}
+
 // maybeRedactEntry transforms a logpb.Entry to either strip
 // sensitive data or keep it, or strip the redaction markers or keep them,
 // or a combination of both. The specific behavior is selected
@@ -102,6 +102,8 @@ func TestRedactedDecodeFile(t *testing.T) {
 		{WithFlattenedSensitiveData, false, "marker: this is safe, stray marks ??, this is not safe"},
 		{WithoutSensitiveData, true, "marker: this is safe, stray marks ??, ‹×›"},
 		{WithoutSensitiveDataNorMarkers, false, "marker: this is safe, stray marks ??, ×"},
+		{WithHashedSensitiveData, true, "marker: this is safe, stray marks ??, ‹#6773f10e›"},
+		{WithHashedSensitiveDataNorMarkers, false, "marker: this is safe, stray marks ??, #6773f10e"},
 	}
 
 	for _, tc := range testData {
@@ -161,6 +163,21 @@ func TestRedactedDecodeFile(t *testing.T) {
 	}
 }
 
+func TestHashSensitiveData(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	in := []byte("a ‹foo› b ‹bar› c ‹foo›")
+	assert.Equal(t, "a ‹#2c26b46b› b ‹#fcde2b2e› c ‹#2c26b46b›",
+		string(hashSensitiveData(in, true /* preserveMarkers */)))
+	assert.Equal(t, "a #2c26b46b b #fcde2b2e c #2c26b46b",
+		string(hashSensitiveData(in, false /* preserveMarkers */)))
+
+	// A non-redactable tag value is hashed as a whole.
+	tags := formattableTags("n\x001\x00client\x00foo\x00")
+	assert.Equal(t, "n\x00‹#6b86b273›\x00client\x00‹#2c26b46b›\x00",
+		string(tags.hashTagValues(true /* preserveMarkers */)))
+}
+
 // TestDefaultRedactable checks that redaction markers are enabled by
 // default.
 func TestDefaultRedactable(t *testing.T) {
@@ -0,0 +1,149 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+)
+
+// SinkDescription describes the connection of a sink to a logging
+// channel, as currently in effect.
+type SinkDescription struct {
+	// SinkType is one of file, fluent, http and stderr.
+	SinkType string
+	// Name identifies the sink among the sinks of its type: it is the
+	// key of the sink in the logging configuration, by which the sink is
+	// referred to in server.logging.overrides.
+	Name string
+	// Address is the server address of fluent and HTTP sinks, and is
+	// empty for the other sinks.
+	Address string
+	// Channel is the channel connected to the sink, and Threshold the
+	// minimum severity of the entries of that channel which are written
+	// to the sink.
+	Channel   Channel
+	Threshold Severity
+
+	Format             string
+	Redact, Redactable bool
+	// ExitOnError is true if a failure to write to the sink
+	// terminates the process.
+	ExitOnError bool
+	// Buffering describes the buffering of the sink, or is "NONE" if
+	// entries are written synchronously.
+	Buffering string
+
+	// Active is false if the sink currently does not accept entries,
+	// e.g. a file sink without a directory.
+	Active bool
+	// OutputErrors counts the entries which the sink failed to write.
+	OutputErrors uint64
+}
+
+// DescribeSinks describes the connections between the logging channels
+// and the sinks which are currently in effect, including the changes
+// made by ApplyOverrides, ordered by sink type, name and channel.
+// Channels connected to a sink at severity NONE are omitted.
+func DescribeSinks() []SinkDescription {
+	// The sinks configured by the operator are the ones created by
+	// ApplyConfig, or derived from them by ApplyOverrides.
+	type configuredSink struct{ sinkType, name string }
+	configured := make(map[*sinkInfo]configuredSink)
+	logging.overrides.Lock()
+	for _, s := range logging.overrides.sinks {
+		configured[s.base] = configuredSink{s.sinkType, s.name}
+		if s.active != nil {
+			configured[s.active] = configuredSink{s.sinkType, s.name}
+		}
+	}
+	logging.overrides.Unlock()
+
+	logging.rmu.RLock()
+	chans := logging.rmu.channels
+	logging.rmu.RUnlock()
+
+	var res []SinkDescription
+	for ch, l := range chans {
+		for _, s := range l.sinkInfos {
+			cs, ok := configured[s]
+			if !ok {
+				// Not a sink configured by the operator, e.g. the
+				// interceptor sink.
+				continue
+			}
+			threshold := s.threshold.get(ch)
+			if threshold == severity.NONE {
+				continue
+			}
+			res = append(res, SinkDescription{
+				SinkType:     cs.sinkType,
+				Name:         cs.name,
+				Address:      s.sinkAddress(),
+				Channel:      ch,
+				Threshold:    threshold,
+				Format:       s.formatter.formatterName(),
+				Redact:       s.redact,
+				Redactable:   s.redactable,
+				ExitOnError:  s.criticality,
+				Buffering:    s.describeBuffering(),
+				Active:       s.sink.active(),
+				OutputErrors: atomic.LoadUint64(&s.outputErrCount),
+			})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].SinkType != res[j].SinkType {
+			return res[i].SinkType < res[j].SinkType
+		}
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].Channel < res[j].Channel
+	})
+	return res
+}
+
+// sinkAddress returns the server address of the sink if it is a
+// network sink, and otherwise the empty string.
+func (l *sinkInfo) sinkAddress() string {
+	sink := l.sink
+	if bs, isBuffered := sink.(*bufferedSink); isBuffered {
+		sink = bs.child
+	}
+	switch s := sink.(type) {
+	case *fluentSink:
+		return s.network + "://" + s.addr
+	case *httpSink:
+		return s.address
+	default:
+		return ""
+	}
+}
+
+// describeBuffering describes the buffering configuration of the sink.
+func (l *sinkInfo) describeBuffering() string {
+	bs, ok := l.sink.(*bufferedSink)
+	if !ok {
+		return "NONE"
+	}
+	bs.mu.Lock()
+	maxBufferSize := bs.mu.buf.maxSizeBytes
+	bs.mu.Unlock()
+	return fmt.Sprintf("max-staleness: %s, flush-trigger-size: %s, max-buffer-size: %s",
+		bs.maxStaleness,
+		logconfig.ByteSize(bs.triggerSize),
+		logconfig.ByteSize(maxBufferSize))
+}
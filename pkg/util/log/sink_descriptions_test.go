@@ -0,0 +1,85 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeSinks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	h := logconfig.Holder{Config: logconfig.DefaultConfig()}
+	require.NoError(t, h.Set(`
+sinks:
+  file-groups:
+    default: {channels: {WARNING: DEV}}
+    audit: {channels: [SESSIONS, SENSITIVE_ACCESS], redact: true, buffered-writes: false}
+  fluent-servers:
+    collector: {address: "127.0.0.1:5170", channels: {ERROR: HEALTH}, exit-on-error: false}
+  stderr: {channels: {ERROR: OPS}}
+`))
+	require.NoError(t, h.Config.Validate(&sc.logDir))
+
+	TestingResetActive()
+	cleanup, err := ApplyConfig(h.Config)
+	require.NoError(t, err)
+	defer cleanup()
+
+	describe := func() map[string]SinkDescription {
+		res := make(map[string]SinkDescription)
+		for _, d := range DescribeSinks() {
+			res[fmt.Sprintf("%s/%s/%s", d.SinkType, d.Name, d.Channel)] = d
+		}
+		return res
+	}
+	descs := describe()
+
+	audit := descs["file/audit/SESSIONS"]
+	require.Equal(t, severity.INFO, audit.Threshold)
+	require.True(t, audit.Redact)
+	require.True(t, audit.Active)
+	require.Equal(t, "NONE", audit.Buffering)
+	require.Equal(t, logconfig.DefaultFileFormat, audit.Format)
+	require.Contains(t, descs, "file/audit/SENSITIVE_ACCESS")
+	require.NotContains(t, descs, "file/audit/DEV")
+
+	require.Equal(t, severity.WARNING, descs["file/default/DEV"].Threshold)
+	require.False(t, descs["file/default/DEV"].Redact)
+	require.Equal(t, severity.ERROR, descs["stderr/stderr/OPS"].Threshold)
+
+	// Network sinks are named by their key in the configuration, which
+	// the overrides refer to, and report their address separately.
+	fluent := descs["fluent/collector/HEALTH"]
+	require.Equal(t, severity.ERROR, fluent.Threshold)
+	require.Equal(t, "tcp://127.0.0.1:5170", fluent.Address)
+	require.Empty(t, descs["file/audit/SESSIONS"].Address)
+
+	// Overrides are reflected in the description.
+	o, err := logconfig.ParseOverrides(`
+file-groups: {default: {channels: {ERROR: DEV}}}
+fluent-servers: {collector: {channels: {WARNING: HEALTH}}}
+`)
+	require.NoError(t, err)
+	require.NoError(t, ApplyOverrides(o))
+	descs = describe()
+	require.Equal(t, severity.ERROR, descs["file/default/DEV"].Threshold)
+	require.Equal(t, severity.WARNING, descs["fluent/collector/HEALTH"].Threshold)
+	require.Equal(t, "tcp://127.0.0.1:5170", descs["fluent/collector/HEALTH"].Address)
+}
@@ -0,0 +1,296 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package quotapool
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// hierarchicalActivityWindow is how long after its last acquisition a child
+// of a HierarchicalRateLimiter is still considered active, and thus keeps its
+// share of the budget reserved.
+const hierarchicalActivityWindow = time.Second
+
+// hierarchicalMaxRetryWait bounds how long a child waits before re-evaluating
+// an acquisition which was blocked on the shares reserved for other children.
+// Those children may go idle in the meantime, releasing their share.
+const hierarchicalMaxRetryWait = 100 * time.Millisecond
+
+// HierarchicalRateLimiter is a token-bucket rate limiter whose budget is
+// shared by a set of weighted children (see NewChild).
+//
+// Every acquisition by a child is taken from the parent's token bucket, so
+// that the children together never exceed the parent's rate. In addition,
+// each child is assured a share of the parent's rate proportional to its
+// weight: an acquisition cannot dip into the tokens the other active children
+// are entitled to. The share of idle children is not reserved, so that it can
+// be used by the active ones; a child is idle once it has neither waiting nor
+// recent acquisitions.
+type HierarchicalRateLimiter struct {
+	name       string
+	options    []Option
+	timeSource timeutil.TimeSource
+	isInf      syncutil.AtomicBool
+
+	mu struct {
+		syncutil.Mutex
+		parent      TokenBucket
+		children    []*ChildRateLimiter
+		totalWeight float64
+	}
+}
+
+// NewHierarchicalRateLimiter creates a new HierarchicalRateLimiter with the
+// given rate and burst. The options are used to configure the quota pools of
+// the children.
+func NewHierarchicalRateLimiter(
+	name string, rate Limit, burst int64, options ...Option,
+) *HierarchicalRateLimiter {
+	var cfg config
+	initializeConfig(&cfg, options...)
+	h := &HierarchicalRateLimiter{
+		name:       name,
+		options:    options,
+		timeSource: cfg.timeSource,
+	}
+	h.mu.parent.Init(TokensPerSecond(rate), Tokens(burst), h.timeSource)
+	h.isInf.Set(math.IsInf(float64(rate), 1))
+	return h
+}
+
+// NewChild adds a child with the given weight to the limiter. The weight
+// determines the share of the limiter's rate and burst the child is assured
+// of while it is active.
+func (h *HierarchicalRateLimiter) NewChild(name string, weight float64) *ChildRateLimiter {
+	if weight <= 0 {
+		panic("child weight must be positive")
+	}
+	c := &ChildRateLimiter{
+		parent: h,
+		weight: weight,
+	}
+	c.qp = New(h.name+"/"+name, c, h.options...)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mu.children = append(h.mu.children, c)
+	h.mu.totalWeight += weight
+	c.mu.assured.Init(0, 0, h.timeSource)
+	h.updateChildrenLocked()
+	return c
+}
+
+// UpdateLimit updates the rate and burst of the limiter, and of the shares of
+// its children accordingly. See RateLimiter.UpdateLimit for the effect on the
+// currently available quota.
+func (h *HierarchicalRateLimiter) UpdateLimit(rate Limit, burst int64) {
+	h.mu.Lock()
+	h.isInf.Set(math.IsInf(float64(rate), 1))
+	h.mu.parent.UpdateConfig(TokensPerSecond(rate), Tokens(burst))
+	h.updateChildrenLocked()
+	children := append([]*ChildRateLimiter(nil), h.mu.children...)
+	h.mu.Unlock()
+
+	// Wake up the waiters, whose wait may have become shorter.
+	for _, c := range children {
+		c.qp.Update(func(Resource) (shouldNotify bool) { return true })
+	}
+}
+
+// updateChildrenLocked recomputes the shares of the children after the
+// parent's configuration or the set of children changed.
+func (h *HierarchicalRateLimiter) updateChildrenLocked() {
+	for _, c := range h.mu.children {
+		share := c.weight / h.mu.totalWeight
+		c.mu.assured.UpdateConfig(
+			TokensPerSecond(float64(h.mu.parent.rate)*share),
+			Tokens(float64(h.mu.parent.burst)*share),
+		)
+	}
+}
+
+// tryAcquireLocked takes n tokens on behalf of c if this does not eat into
+// the tokens reserved for the other active children. Otherwise, it returns an
+// estimate of how long it will take for the tokens to become available.
+func (h *HierarchicalRateLimiter) tryAcquireLocked(
+	c *ChildRateLimiter, n int64,
+) (fulfilled bool, tryAgainAfter time.Duration) {
+	if h.isInf.Get() {
+		return true, 0
+	}
+	now := h.timeSource.Now()
+	p := &h.mu.parent
+	p.update()
+
+	var reserved Tokens
+	var reservedRate TokensPerSecond
+	for _, o := range h.mu.children {
+		if o == c || !o.activeLocked(now) {
+			continue
+		}
+		o.mu.assured.update()
+		if o.mu.assured.current > 0 {
+			reserved += o.mu.assured.current
+		}
+		reservedRate += o.mu.assured.rate
+	}
+
+	// As with TokenBucket, an acquisition larger than what can be available is
+	// let through once the parent's bucket is full, putting the limiter into
+	// debt.
+	want := Tokens(n)
+	if limit := p.burst - reserved; want > limit {
+		want = limit
+	}
+	if deficit := want + reserved - p.current; deficit > 0 {
+		// The deficit shrinks at least at the rate of c's own share: the
+		// parent's tokens accumulate at the full rate, while the reserved
+		// tokens accumulate at most at the rate of the other shares.
+		gain := p.rate - reservedRate
+		if gain < c.mu.assured.rate {
+			gain = c.mu.assured.rate
+		}
+		if gain <= 0 {
+			return false, hierarchicalMaxRetryWait
+		}
+		tryAgainAfter = time.Duration(float64(deficit) * float64(time.Second) / float64(gain))
+		if reserved > 0 && tryAgainAfter > hierarchicalMaxRetryWait {
+			tryAgainAfter = hierarchicalMaxRetryWait
+		}
+		if tryAgainAfter < time.Nanosecond {
+			tryAgainAfter = time.Nanosecond
+		}
+		return false, tryAgainAfter
+	}
+
+	p.current -= Tokens(n)
+	// Consuming beyond its share puts the child into debt, which it must repay
+	// before its share is reserved again. The debt is bounded so that a child
+	// which borrowed heavily while the others were idle is not locked out of
+	// its share for long.
+	a := &c.mu.assured
+	a.update()
+	a.current -= Tokens(n)
+	if a.current < -a.burst {
+		a.current = -a.burst
+	}
+	c.mu.lastAcquired = now
+	return true, 0
+}
+
+// ChildRateLimiter is a consumer of the budget of a HierarchicalRateLimiter.
+// Concurrent acquisitions of the same child are served in FIFO order, while
+// different children wait independently of each other.
+type ChildRateLimiter struct {
+	parent *HierarchicalRateLimiter
+	qp     *AbstractPool
+	// weight is protected by parent.mu.
+	weight float64
+
+	// mu groups the fields protected by parent.mu.
+	mu struct {
+		// assured tracks the tokens the child is entitled to from its share of
+		// the parent's rate.
+		assured TokenBucket
+		// waiting is the number of goroutines waiting in WaitN.
+		waiting int
+		// lastAcquired is the time of the last acquisition.
+		lastAcquired time.Time
+	}
+}
+
+// UpdateWeight updates the weight of the child, and the shares of the children
+// of its limiter accordingly.
+func (c *ChildRateLimiter) UpdateWeight(weight float64) {
+	if weight <= 0 {
+		panic("child weight must be positive")
+	}
+	h := c.parent
+	h.mu.Lock()
+	h.mu.totalWeight += weight - c.weight
+	c.weight = weight
+	h.updateChildrenLocked()
+	children := append([]*ChildRateLimiter(nil), h.mu.children...)
+	h.mu.Unlock()
+
+	// Wake up the waiters, whose wait may have become shorter.
+	for _, child := range children {
+		child.qp.Update(func(Resource) (shouldNotify bool) { return true })
+	}
+}
+
+// Limit returns the rate of the limiter, which the acquisitions of the child
+// can reach while the other children are idle.
+func (c *ChildRateLimiter) Limit() Limit {
+	h := c.parent
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return Limit(h.mu.parent.rate)
+}
+
+// activeLocked returns whether the child currently has its share reserved.
+func (c *ChildRateLimiter) activeLocked(now time.Time) bool {
+	return c.mu.waiting > 0 ||
+		(!c.mu.lastAcquired.IsZero() && now.Sub(c.mu.lastAcquired) < hierarchicalActivityWindow)
+}
+
+// WaitN acquires n quota on behalf of the child, blocking until it is
+// available or the context is canceled. The acquisition cannot be released.
+// Unlike RateLimiter.WaitN, it fails if the context is already canceled even
+// when no waiting is needed.
+func (c *ChildRateLimiter) WaitN(ctx context.Context, n int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if n == 0 || c.parent.isInf.Get() {
+		return nil
+	}
+	h := c.parent
+	h.mu.Lock()
+	c.mu.waiting++
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		c.mu.waiting--
+		h.mu.Unlock()
+	}()
+
+	r := childRateRequest{want: n}
+	return c.qp.Acquire(ctx, &r)
+}
+
+// childRateRequest is the Request used by ChildRateLimiter. The Resource of a
+// child's quota pool is the child itself.
+type childRateRequest struct {
+	want int64
+}
+
+var _ Request = (*childRateRequest)(nil)
+
+// Acquire is part of the Request interface.
+func (r *childRateRequest) Acquire(
+	ctx context.Context, res Resource,
+) (fulfilled bool, tryAgainAfter time.Duration) {
+	c := res.(*ChildRateLimiter)
+	c.parent.mu.Lock()
+	defer c.parent.mu.Unlock()
+	return c.parent.tryAcquireLocked(c, r.want)
+}
+
+// ShouldWait is part of the Request interface.
+func (r *childRateRequest) ShouldWait() bool {
+	return true
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package quotapool_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHierarchicalRateLimiter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	t0 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	mt := timeutil.NewManualTime(t0)
+	h := quotapool.NewHierarchicalRateLimiter("test", 10, 20, quotapool.WithTimeSource(mt))
+	a := h.NewChild("a", 1)
+	b := h.NewChild("b", 3)
+
+	done := make(chan error, 1)
+	waitN := func(c *quotapool.ChildRateLimiter, n int64) {
+		go func() { done <- c.WaitN(ctx, n) }()
+	}
+	ensureBlocked := func() {
+		testutils.SucceedsSoon(t, func() error {
+			if len(mt.Timers()) == 0 {
+				return errors.New("no timers found")
+			}
+			return nil
+		})
+		select {
+		case err := <-done:
+			t.Fatalf("expected to be blocked, got %v", err)
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	// While b is idle, a can use the whole budget.
+	require.NoError(t, a.WaitN(ctx, 20))
+	waitN(a, 5)
+	ensureBlocked()
+	mt.Advance(500 * time.Millisecond)
+	require.NoError(t, <-done)
+
+	// Once b is idle for long enough and the bucket refilled, a can again use
+	// b's share.
+	mt.Advance(2 * time.Second)
+	require.NoError(t, a.WaitN(ctx, 18))
+
+	// When both are active, a cannot eat into b's share: b keeps its remaining
+	// 14 tokens reserved.
+	mt.Advance(2 * time.Second)
+	require.NoError(t, b.WaitN(ctx, 1))
+	require.NoError(t, a.WaitN(ctx, 5))
+	waitN(a, 1)
+	ensureBlocked()
+	// b can use its share right away.
+	require.NoError(t, b.WaitN(ctx, 14))
+	mt.Advance(time.Second)
+	require.NoError(t, <-done)
+
+	// Acquisitions larger than the burst go through once the bucket is full.
+	mt.Advance(5 * time.Second)
+	require.NoError(t, b.WaitN(ctx, 100))
+}
+
+func TestHierarchicalRateLimiterUpdateLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	t0 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	mt := timeutil.NewManualTime(t0)
+	h := quotapool.NewHierarchicalRateLimiter("test", 1, 1, quotapool.WithTimeSource(mt))
+	c := h.NewChild("c", 1)
+
+	require.NoError(t, c.WaitN(ctx, 1))
+	done := make(chan error, 1)
+	go func() { done <- c.WaitN(ctx, 100) }()
+	testutils.SucceedsSoon(t, func() error {
+		if len(mt.Timers()) == 0 {
+			return errors.New("no timers found")
+		}
+		return nil
+	})
+
+	// Lifting the limit unblocks the waiter.
+	require.Equal(t, quotapool.Limit(1), c.Limit())
+	h.UpdateLimit(quotapool.Limit(math.Inf(1)), 0)
+	require.NoError(t, <-done)
+	require.True(t, math.IsInf(float64(c.Limit()), 1))
+	require.NoError(t, c.WaitN(ctx, 1<<40))
+
+	// Acquisitions fail once the context is canceled, even when they would not
+	// need to wait.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, c.WaitN(cancelCtx, 10), context.Canceled)
+	h.UpdateLimit(1, 1)
+	require.ErrorIs(t, c.WaitN(cancelCtx, 10), context.Canceled)
+}
+
+func TestHierarchicalRateLimiterUpdateWeight(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	t0 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	mt := timeutil.NewManualTime(t0)
+	h := quotapool.NewHierarchicalRateLimiter("test", 10, 20, quotapool.WithTimeSource(mt))
+	a := h.NewChild("a", 1)
+	b := h.NewChild("b", 1)
+
+	// b keeps 9 of its 10 tokens reserved, until a's weight is raised and
+	// b's share shrinks to 5 tokens, of which 4 remain.
+	require.NoError(t, b.WaitN(ctx, 1))
+	a.UpdateWeight(3)
+	require.NoError(t, a.WaitN(ctx, 15))
+
+	done := make(chan error, 1)
+	go func() { done <- a.WaitN(ctx, 1) }()
+	testutils.SucceedsSoon(t, func() error {
+		if len(mt.Timers()) == 0 {
+			return errors.New("no timers found")
+		}
+		return nil
+	})
+	// b can use what remains of its share right away.
+	require.NoError(t, b.WaitN(ctx, 4))
+	mt.Advance(time.Second)
+	require.NoError(t, <-done)
+}
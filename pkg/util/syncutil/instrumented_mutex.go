@@ -0,0 +1,262 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package syncutil
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// mutexInstrumentationEnabled gates the collection of wait and hold
+// durations by InstrumentedMutex and InstrumentedRWMutex. When it is
+// disabled (the default), the instrumented mutexes only pay for an atomic
+// load on top of the regular locking cost.
+var mutexInstrumentationEnabled int32
+
+// EnableMutexInstrumentation turns the collection of contention statistics
+// for instrumented mutexes on or off. Statistics collected so far are
+// retained when instrumentation is turned off.
+func EnableMutexInstrumentation(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&mutexInstrumentationEnabled, v)
+}
+
+// MutexInstrumentationEnabled returns whether contention statistics are
+// currently being collected for instrumented mutexes.
+func MutexInstrumentationEnabled() bool {
+	return atomic.LoadInt32(&mutexInstrumentationEnabled) == 1
+}
+
+// contendedWaitThreshold is the wait duration above which an acquisition is
+// counted as contended. Uncontended acquisitions typically complete in tens
+// of nanoseconds.
+const contendedWaitThreshold = time.Microsecond
+
+// processStart anchors the monotonic clock readings used by the instrumented
+// mutexes. syncutil cannot depend on timeutil, which itself uses syncutil.
+var processStart = time.Now()
+
+// monotonicNanos returns the (strictly positive) number of nanoseconds
+// elapsed since processStart.
+func monotonicNanos() int64 {
+	return int64(time.Since(processStart)) + 1
+}
+
+// mutexStats accumulates contention statistics for all the instrumented
+// mutexes registered under the same name. All fields are accessed
+// atomically.
+type mutexStats struct {
+	name string
+
+	acquisitions int64
+	contended    int64
+	waitNanos    int64
+	maxWaitNanos int64
+	holdNanos    int64
+	maxHoldNanos int64
+}
+
+func (s *mutexStats) recordWait(wait int64) {
+	atomic.AddInt64(&s.acquisitions, 1)
+	if wait < int64(contendedWaitThreshold) {
+		return
+	}
+	atomic.AddInt64(&s.contended, 1)
+	atomic.AddInt64(&s.waitNanos, wait)
+	storeMaxInt64(&s.maxWaitNanos, wait)
+}
+
+func (s *mutexStats) recordHold(hold int64) {
+	atomic.AddInt64(&s.holdNanos, hold)
+	storeMaxInt64(&s.maxHoldNanos, hold)
+}
+
+func storeMaxInt64(addr *int64, v int64) {
+	for {
+		cur := atomic.LoadInt64(addr)
+		if v <= cur || atomic.CompareAndSwapInt64(addr, cur, v) {
+			return
+		}
+	}
+}
+
+// MutexContention is a snapshot of the contention statistics collected for
+// the instrumented mutexes registered under a given name.
+type MutexContention struct {
+	// Name is the name the mutexes were registered under.
+	Name string
+	// Acquisitions is the number of instrumented exclusive or shared
+	// acquisitions.
+	Acquisitions int64
+	// Contended is the number of acquisitions that had to wait.
+	Contended int64
+	// WaitTime is the total time spent waiting for contended acquisitions.
+	WaitTime time.Duration
+	// MaxWaitTime is the longest single wait.
+	MaxWaitTime time.Duration
+	// HoldTime is the total time the mutexes were held exclusively.
+	HoldTime time.Duration
+	// MaxHoldTime is the longest single exclusive hold.
+	MaxHoldTime time.Duration
+}
+
+var mutexRegistry struct {
+	Mutex
+	stats map[string]*mutexStats
+}
+
+// registerMutex returns the statistics for the given name, creating them on
+// first use. Mutexes sharing a name (e.g. the mutex of every Store on a
+// node) share their statistics.
+func registerMutex(name string) *mutexStats {
+	mutexRegistry.Lock()
+	defer mutexRegistry.Unlock()
+	if mutexRegistry.stats == nil {
+		mutexRegistry.stats = make(map[string]*mutexStats)
+	}
+	s, ok := mutexRegistry.stats[name]
+	if !ok {
+		s = &mutexStats{name: name}
+		mutexRegistry.stats[name] = s
+	}
+	return s
+}
+
+// TopContendedMutexes returns the contention statistics of up to n
+// registered mutex names, ordered by decreasing total wait time. If n is not
+// positive, all the registered names are returned.
+func TopContendedMutexes(n int) []MutexContention {
+	mutexRegistry.Lock()
+	res := make([]MutexContention, 0, len(mutexRegistry.stats))
+	for _, s := range mutexRegistry.stats {
+		res = append(res, MutexContention{
+			Name:         s.name,
+			Acquisitions: atomic.LoadInt64(&s.acquisitions),
+			Contended:    atomic.LoadInt64(&s.contended),
+			WaitTime:     time.Duration(atomic.LoadInt64(&s.waitNanos)),
+			MaxWaitTime:  time.Duration(atomic.LoadInt64(&s.maxWaitNanos)),
+			HoldTime:     time.Duration(atomic.LoadInt64(&s.holdNanos)),
+			MaxHoldTime:  time.Duration(atomic.LoadInt64(&s.maxHoldNanos)),
+		})
+	}
+	mutexRegistry.Unlock()
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].WaitTime != res[j].WaitTime {
+			return res[i].WaitTime > res[j].WaitTime
+		}
+		return res[i].Name < res[j].Name
+	})
+	if n > 0 && len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+// An InstrumentedMutex is a Mutex that, once named with Init and while
+// instrumentation is enabled, records how long callers wait to acquire it
+// and how long it is held. The statistics are reported by
+// TopContendedMutexes. The zero value is an uninstrumented, unlocked mutex.
+type InstrumentedMutex struct {
+	Mutex
+	stats *mutexStats
+	// lockedAt is the monotonic time at which the mutex was acquired, or zero
+	// if the acquisition was not instrumented. Protected by the mutex.
+	lockedAt int64
+}
+
+// Init registers the mutex under the given name. It must be called before
+// the mutex is first used.
+func (m *InstrumentedMutex) Init(name string) {
+	m.stats = registerMutex(name)
+}
+
+// Lock locks m.
+func (m *InstrumentedMutex) Lock() {
+	if m.stats == nil || !MutexInstrumentationEnabled() {
+		m.Mutex.Lock()
+		m.lockedAt = 0
+		return
+	}
+	start := monotonicNanos()
+	m.Mutex.Lock()
+	now := monotonicNanos()
+	m.stats.recordWait(now - start)
+	m.lockedAt = now
+}
+
+// Unlock unlocks m.
+func (m *InstrumentedMutex) Unlock() {
+	if m.lockedAt != 0 {
+		m.stats.recordHold(monotonicNanos() - m.lockedAt)
+		m.lockedAt = 0
+	}
+	m.Mutex.Unlock()
+}
+
+// An InstrumentedRWMutex is the RWMutex counterpart of InstrumentedMutex.
+// Waits are recorded for both exclusive and shared acquisitions; hold times
+// only for exclusive ones, since shared holds overlap.
+type InstrumentedRWMutex struct {
+	RWMutex
+	stats *mutexStats
+	// lockedAt is the monotonic time at which the mutex was acquired
+	// exclusively, or zero if the acquisition was not instrumented. Protected
+	// by the exclusive lock.
+	lockedAt int64
+}
+
+// Init registers the mutex under the given name. It must be called before
+// the mutex is first used.
+func (rw *InstrumentedRWMutex) Init(name string) {
+	rw.stats = registerMutex(name)
+}
+
+// Lock locks rw for writing.
+func (rw *InstrumentedRWMutex) Lock() {
+	if rw.stats == nil || !MutexInstrumentationEnabled() {
+		rw.RWMutex.Lock()
+		rw.lockedAt = 0
+		return
+	}
+	start := monotonicNanos()
+	rw.RWMutex.Lock()
+	now := monotonicNanos()
+	rw.stats.recordWait(now - start)
+	rw.lockedAt = now
+}
+
+// Unlock unlocks rw for writing.
+func (rw *InstrumentedRWMutex) Unlock() {
+	if rw.lockedAt != 0 {
+		rw.stats.recordHold(monotonicNanos() - rw.lockedAt)
+		rw.lockedAt = 0
+	}
+	rw.RWMutex.Unlock()
+}
+
+// RLock locks rw for reading.
+func (rw *InstrumentedRWMutex) RLock() {
+	if rw.stats == nil || !MutexInstrumentationEnabled() {
+		rw.RWMutex.RLock()
+		return
+	}
+	start := monotonicNanos()
+	rw.RWMutex.RLock()
+	rw.stats.recordWait(monotonicNanos() - start)
+}
+
+var _ sync.Locker = &InstrumentedMutex{}
+var _ sync.Locker = &InstrumentedRWMutex{}
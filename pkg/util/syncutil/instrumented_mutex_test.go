@@ -0,0 +1,102 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package syncutil
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func findContention(t *testing.T, name string) MutexContention {
+	for _, c := range TopContendedMutexes(0) {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("mutex %q not registered", name)
+	return MutexContention{}
+}
+
+func TestInstrumentedMutex(t *testing.T) {
+	defer EnableMutexInstrumentation(MutexInstrumentationEnabled())
+
+	var m InstrumentedMutex
+	m.Init("test.instrumented_mutex")
+
+	// Nothing is recorded while instrumentation is disabled.
+	EnableMutexInstrumentation(false)
+	m.Lock()
+	m.Unlock()
+	require.Equal(t, int64(0), findContention(t, "test.instrumented_mutex").Acquisitions)
+
+	EnableMutexInstrumentation(true)
+	m.Lock()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.Lock()
+		m.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	m.Unlock()
+	wg.Wait()
+
+	c := findContention(t, "test.instrumented_mutex")
+	require.Equal(t, int64(2), c.Acquisitions)
+	require.Equal(t, int64(1), c.Contended)
+	require.GreaterOrEqual(t, int64(c.WaitTime), int64(5*time.Millisecond))
+	require.Equal(t, c.WaitTime, c.MaxWaitTime)
+	require.GreaterOrEqual(t, int64(c.MaxHoldTime), int64(10*time.Millisecond))
+}
+
+func TestInstrumentedRWMutex(t *testing.T) {
+	defer EnableMutexInstrumentation(MutexInstrumentationEnabled())
+	EnableMutexInstrumentation(true)
+
+	var rw InstrumentedRWMutex
+	rw.Init("test.instrumented_rwmutex")
+
+	rw.RLock()
+	rw.RLock()
+	rw.RUnlock()
+	rw.RUnlock()
+	rw.Lock()
+	time.Sleep(time.Millisecond)
+	rw.Unlock()
+
+	c := findContention(t, "test.instrumented_rwmutex")
+	require.Equal(t, int64(3), c.Acquisitions)
+	require.Equal(t, int64(0), c.Contended)
+	require.GreaterOrEqual(t, int64(c.HoldTime), int64(time.Millisecond))
+
+	// An uninitialized instrumented mutex behaves like a regular one.
+	var plain InstrumentedRWMutex
+	plain.Lock()
+	plain.Unlock()
+	plain.RLock()
+	plain.RUnlock()
+}
+
+func TestTopContendedMutexes(t *testing.T) {
+	registerMutex("test.top.a").recordWait(int64(time.Second))
+	registerMutex("test.top.b").recordWait(int64(2 * time.Second))
+
+	top := TopContendedMutexes(2)
+	require.Len(t, top, 2)
+	require.Equal(t, "test.top.b", top[0].Name)
+	require.Equal(t, "test.top.a", top[1].Name)
+	// Registering the same name again shares the statistics.
+	require.Equal(t, registerMutex("test.top.a"), registerMutex("test.top.a"))
+}
@@ -0,0 +1,30 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+//go:build linux
+// +build linux
+
+package sysutil
+
+import (
+	"github.com/cockroachdb/errors"
+	"golang.org/x/sys/unix"
+)
+
+// DropPageCache advises the kernel to evict the cached pages of the given
+// range of the open file with the given descriptor. A length of 0 means up to
+// the end of the file. Only clean pages are evicted, so the file must have
+// been synced for its recently written pages to be dropped.
+//
+// On Linux, it uses fadvise(POSIX_FADV_DONTNEED). On other platforms, it is a
+// no-op.
+func DropPageCache(fd uintptr, offset, length int64) error {
+	return errors.Wrap(unix.Fadvise(int(fd), offset, length, unix.FADV_DONTNEED), "fadvise")
+}
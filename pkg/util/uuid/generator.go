@@ -64,6 +64,12 @@ func NewV5(ns UUID, name string) UUID {
 	return DefaultGenerator.NewV5(ns, name)
 }
 
+// NewV7 returns a time-ordered UUID based on the current Unix timestamp
+// and random bits.
+func NewV7() (UUID, error) {
+	return DefaultGenerator.NewV7()
+}
+
 // Generator provides an interface for generating UUIDs.
 type Generator interface {
 	NewV1() (UUID, error)
@@ -71,6 +77,7 @@ type Generator interface {
 	NewV3(ns UUID, name string) UUID
 	NewV4() (UUID, error)
 	NewV5(ns UUID, name string) UUID
+	NewV7() (UUID, error)
 }
 
 // Gen is a reference UUID generator based on the specifications laid out in
@@ -96,6 +103,10 @@ type Gen struct {
 	lastTime      uint64
 	clockSequence uint16
 	hardwareAddr  [6]byte
+
+	// lastV7Time is the timestamp of the last V7 UUID, in units of 1/4096
+	// of a millisecond since the Unix epoch.
+	lastV7Time uint64
 }
 
 // interface check -- build will fail if *Gen doesn't satisfy Generator
@@ -200,6 +211,44 @@ func (g *Gen) NewV5(ns UUID, name string) UUID {
 	return u
 }
 
+// NewV7 returns a time-ordered UUID, as specified by RFC 9562. The first 48
+// bits are the Unix timestamp in milliseconds, and the next 12 bits (after
+// the version) are the fraction of the millisecond, so that the UUIDs
+// generated by a Gen sort in the order they were generated. The remaining 62
+// bits (after the variant) are random.
+func (g *Gen) NewV7() (UUID, error) {
+	u := UUID{}
+	if _, err := io.ReadFull(g.rand, u[8:]); err != nil {
+		return Nil, err
+	}
+
+	ts := g.getV7Time()
+	binary.BigEndian.PutUint32(u[0:], uint32(ts>>(12+16)))
+	binary.BigEndian.PutUint16(u[4:], uint16(ts>>12))
+	binary.BigEndian.PutUint16(u[6:], uint16(ts&0xfff))
+
+	u.SetVersion(V7)
+	u.SetVariant(VariantRFC4122)
+
+	return u, nil
+}
+
+// getV7Time returns the timestamp of a new V7 UUID, in units of 1/4096 of a
+// millisecond since the Unix epoch. The timestamp is larger than the one of
+// the previous V7 UUID, even if the clock didn't advance or moved backwards.
+func (g *Gen) getV7Time() uint64 {
+	nanos := uint64(g.epochFunc().UnixNano())
+	ts := (nanos/1e6)<<12 | (nanos%1e6)*4096/1e6
+
+	g.storageMutex.Lock()
+	defer g.storageMutex.Unlock()
+	if ts <= g.lastV7Time {
+		ts = g.lastV7Time + 1
+	}
+	g.lastV7Time = ts
+	return ts
+}
+
 // Returns the epoch and clock sequence.
 func (g *Gen) getClockSequence() (uint64, uint16, error) {
 	var err error
@@ -32,6 +32,7 @@ func TestGenerator(t *testing.T) {
 	t.Run("NewV3", testNewV3)
 	t.Run("NewV4", testNewV4)
 	t.Run("NewV5", testNewV5)
+	t.Run("NewV7", testNewV7)
 }
 
 func testNewV1(t *testing.T) {
@@ -387,3 +388,79 @@ func (r *faultyReader) Read(dest []byte) (int, error) {
 	}
 	return rand.Read(dest)
 }
+
+func testNewV7(t *testing.T) {
+	t.Run("Basic", testNewV7Basic)
+	t.Run("Ordered", testNewV7Ordered)
+	t.Run("ClockBackwards", testNewV7ClockBackwards)
+	t.Run("FaultyRand", testNewV7FaultyRand)
+}
+
+func testNewV7Basic(t *testing.T) {
+	now := timeutil.Unix(1645557742, 123456789)
+	g := NewGen()
+	g.epochFunc = func() time.Time { return now }
+	u, err := g.NewV7()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := u.Version(), V7; got != want {
+		t.Errorf("got version %d, want %d", got, want)
+	}
+	if got, want := u.Variant(), VariantRFC4122; got != want {
+		t.Errorf("got variant %d, want %d", got, want)
+	}
+	ts, err := TimeFromV7(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Truncate(time.Millisecond); !ts.Equal(want) {
+		t.Errorf("got time %s, want %s", ts, want)
+	}
+	if _, err := TimeFromV7(Must(NewV4())); err == nil {
+		t.Error("expected an error for a V4 UUID")
+	}
+}
+
+func testNewV7Ordered(t *testing.T) {
+	// UUIDs generated within the same millisecond, or even with the same
+	// clock reading, are ordered.
+	g := NewGen()
+	prev := Must(g.NewV7())
+	for i := 0; i < 10000; i++ {
+		u := Must(g.NewV7())
+		if bytes.Compare(prev[:], u[:]) >= 0 {
+			t.Fatalf("%s generated after %s", u, prev)
+		}
+		if got := u.Version(); got != V7 {
+			t.Fatalf("got version %d, want %d", got, V7)
+		}
+		prev = u
+	}
+}
+
+func testNewV7ClockBackwards(t *testing.T) {
+	now := timeutil.Unix(1645557742, 0)
+	g := NewGen()
+	g.epochFunc = func() time.Time { return now }
+	u1 := Must(g.NewV7())
+	now = now.Add(-time.Second)
+	u2 := Must(g.NewV7())
+	if bytes.Compare(u1[:], u2[:]) >= 0 {
+		t.Errorf("%s generated after %s", u2, u1)
+	}
+}
+
+func testNewV7FaultyRand(t *testing.T) {
+	g := &Gen{
+		epochFunc:  time.Now,
+		hwAddrFunc: defaultHWAddrFunc,
+		rand: &faultyReader{
+			readToFail: 0, // fail immediately
+		},
+	}
+	u, err := g.NewV7()
+	if err == nil {
+		t.Errorf("got %v, nil error", u)
+	}
+}
@@ -54,6 +54,8 @@ const (
 	V3      // Version 3 (namespace name-based)
 	V4      // Version 4 (random)
 	V5      // Version 5 (namespace name-based)
+	_       // Version 6 (reordered date-time and MAC address)
+	V7      // Version 7 (Unix epoch time-based)
 )
 
 // UUID layout variants.
@@ -91,6 +93,16 @@ func TimestampFromV1(u UUID) (Timestamp, error) {
 	return Timestamp(uint64(low) + (uint64(mid) << 32) + (uint64(hi) << 48)), nil
 }
 
+// TimeFromV7 returns the time, with a millisecond precision, embedded within
+// a V7 UUID. Returns an error if the UUID is any version other than 7.
+func TimeFromV7(u UUID) (time.Time, error) {
+	if u.Version() != V7 {
+		return time.Time{}, fmt.Errorf("uuid: %s is version %d, not version 7", u, u.Version())
+	}
+	millis := uint64(binary.BigEndian.Uint32(u[0:4]))<<16 | uint64(binary.BigEndian.Uint16(u[4:6]))
+	return timeutil.Unix(0, int64(millis)*int64(time.Millisecond)), nil
+}
+
 // String parse helpers.
 var urnPrefix = []byte("urn:uuid:")
 
@@ -99,6 +99,11 @@ func MakeV4() UUID {
 	return Must(NewV4())
 }
 
+// MakeV7 calls Must(NewV7)
+func MakeV7() UUID {
+	return Must(NewV7())
+}
+
 // FastMakeV4 generates a UUID using a fast but not cryptographically secure
 // source of randomness.
 func FastMakeV4() UUID {